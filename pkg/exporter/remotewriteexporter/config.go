@@ -0,0 +1,104 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+const (
+	defaultTimeout           = 5 * time.Second
+	defaultMaxBatchSizeBytes = 3 * 1024 * 1024
+	defaultWALFlushInterval  = time.Second
+	defaultWALMaxSizeMiB     = 512
+)
+
+// Config defines configuration for the remote write exporter.
+type Config struct {
+	config.ExporterSettings       `mapstructure:",squash"`
+	confighttp.HTTPClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	exporterhelper.QueueSettings  `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings  `mapstructure:"retry_on_failure"`
+
+	// Namespace is prepended to all metric names, separated with an underscore.
+	Namespace string `mapstructure:"namespace"`
+
+	// ExternalLabels are added to every exported series.
+	// Labels of the series take precedence over external labels.
+	ExternalLabels map[string]string `mapstructure:"external_labels"`
+
+	// MaxBatchSizeBytes limits the size of a single remote write request before compression.
+	MaxBatchSizeBytes int `mapstructure:"max_batch_size_bytes"`
+
+	// WAL configures the write-ahead log used to buffer requests on disk.
+	// When it's not set, requests are buffered in memory using the sending queue.
+	WAL *WALConfig `mapstructure:"wal"`
+}
+
+// WALConfig defines configuration for the write-ahead log.
+type WALConfig struct {
+	// Directory in which the write-ahead log is stored.
+	Directory string `mapstructure:"directory"`
+
+	// FlushInterval is the time to wait before retrying a request
+	// when the remote write endpoint is unavailable. Defaults to 1s.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// MaxSizeMiB is the maximum size of the write-ahead log on disk.
+	// New requests are rejected when the limit is reached, until the buffered requests are sent.
+	// Defaults to 512.
+	MaxSizeMiB int64 `mapstructure:"max_size_mib"`
+}
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ExporterSettings.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint has to be set")
+	}
+
+	if cfg.MaxBatchSizeBytes <= 0 {
+		return fmt.Errorf("max_batch_size_bytes has to be positive, got %d", cfg.MaxBatchSizeBytes)
+	}
+
+	for name := range cfg.ExternalLabels {
+		if sanitizeLabelName(name) != name {
+			return fmt.Errorf("invalid external label name: %q", name)
+		}
+	}
+
+	if cfg.WAL != nil {
+		if cfg.WAL.Directory == "" {
+			return errors.New("wal directory has to be set")
+		}
+		if cfg.WAL.FlushInterval < 0 {
+			return fmt.Errorf("wal flush_interval cannot be negative, got %v", cfg.WAL.FlushInterval)
+		}
+		if cfg.WAL.MaxSizeMiB < 0 {
+			return fmt.Errorf("wal max_size_mib cannot be negative, got %d", cfg.WAL.MaxSizeMiB)
+		}
+	}
+
+	return nil
+}
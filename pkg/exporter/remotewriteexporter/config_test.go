@@ -0,0 +1,103 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.Endpoint = "http://localhost:9009/api/v1/push"
+	assert.Equal(t, e0, defaultCfg)
+
+	qs := exporterhelper.NewDefaultQueueSettings()
+	qs.Enabled = false
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, e1,
+		&Config{
+			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			HTTPClientSettings: confighttp.HTTPClientSettings{
+				Endpoint: "http://thanos-receive:19291/api/v1/receive",
+				Timeout:  10 * time.Second,
+			},
+			QueueSettings:     qs,
+			RetrySettings:     exporterhelper.NewDefaultRetrySettings(),
+			Namespace:         "otel",
+			ExternalLabels:    map[string]string{"cluster": "production"},
+			MaxBatchSizeBytes: 1048576,
+			WAL: &WALConfig{
+				Directory:     "/var/lib/otelcol/remote_write",
+				FlushInterval: 5 * time.Second,
+				MaxSizeMiB:    128,
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no endpoint", modify: func(cfg *Config) { cfg.Endpoint = "" }},
+		{name: "non positive batch size", modify: func(cfg *Config) { cfg.MaxBatchSizeBytes = 0 }},
+		{name: "invalid external label", modify: func(cfg *Config) {
+			cfg.ExternalLabels = map[string]string{"k8s.cluster": "production"}
+		}},
+		{name: "reserved external label", modify: func(cfg *Config) {
+			cfg.ExternalLabels = map[string]string{"__name__": "metric"}
+		}},
+		{name: "wal without directory", modify: func(cfg *Config) { cfg.WAL = &WALConfig{} }},
+		{name: "negative wal flush interval", modify: func(cfg *Config) {
+			cfg.WAL = &WALConfig{Directory: "/tmp", FlushInterval: -time.Second}
+		}},
+		{name: "negative wal size", modify: func(cfg *Config) {
+			cfg.WAL = &WALConfig{Directory: "/tmp", MaxSizeMiB: -1}
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = "http://localhost:9009/api/v1/push"
+			require.NoError(t, cfg.Validate())
+
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
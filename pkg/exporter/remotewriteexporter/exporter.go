@@ -0,0 +1,246 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+	// maxErrorBodySize limits how much of the response body is included in error messages.
+	maxErrorBodySize = 256
+)
+
+type remoteWriteExporter struct {
+	config  *Config
+	logger  *zap.Logger
+	builder seriesBuilder
+	client  *http.Client
+
+	userAgent string
+
+	// wal is set only when the write-ahead log is enabled.
+	wal    *wal
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newMetricsExporter(cfg *Config, params component.ExporterCreateSettings) (component.MetricsExporter, error) {
+	e := &remoteWriteExporter{
+		config: cfg,
+		logger: params.Logger,
+		builder: seriesBuilder{
+			namespace:      cfg.Namespace,
+			externalLabels: cfg.ExternalLabels,
+		},
+		userAgent: fmt.Sprintf("%s/%s", params.BuildInfo.Command, params.BuildInfo.Version),
+	}
+
+	return exporterhelper.NewMetricsExporter(
+		cfg,
+		params,
+		e.pushMetricsData,
+		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithRetry(cfg.RetrySettings),
+		exporterhelper.WithQueue(cfg.QueueSettings),
+		exporterhelper.WithStart(e.start),
+		exporterhelper.WithShutdown(e.shutdown),
+	)
+}
+
+func (e *remoteWriteExporter) start(ctx context.Context, host component.Host) error {
+	client, err := e.config.HTTPClientSettings.ToClient(host.GetExtensions(), component.TelemetrySettings{})
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP Client: %w", err)
+	}
+	e.client = client
+
+	if e.config.WAL != nil {
+		maxSizeMiB := e.config.WAL.MaxSizeMiB
+		if maxSizeMiB == 0 {
+			maxSizeMiB = defaultWALMaxSizeMiB
+		}
+		w, err := openWAL(e.config.WAL.Directory, maxSizeMiB*1024*1024)
+		if err != nil {
+			return err
+		}
+		e.wal = w
+
+		var walCtx context.Context
+		walCtx, e.cancel = context.WithCancel(context.Background())
+		e.wg.Add(1)
+		go e.drainWAL(walCtx)
+	}
+
+	return nil
+}
+
+func (e *remoteWriteExporter) shutdown(context.Context) error {
+	if e.wal == nil {
+		return nil
+	}
+
+	e.cancel()
+	e.wg.Wait()
+	return e.wal.close()
+}
+
+// pushMetricsData translates metrics into time series and sends them in batches.
+// When the write-ahead log is enabled, the batches are persisted in it and sent in the background.
+func (e *remoteWriteExporter) pushMetricsData(ctx context.Context, md pmetric.Metrics) error {
+	series, dropped := e.builder.build(md)
+	if dropped > 0 {
+		e.logger.Debug("Dropped data points which cannot be exported with remote write", zap.Int("count", dropped))
+	}
+
+	for _, batch := range batchSeries(series, e.config.MaxBatchSizeBytes) {
+		request := &prompb.WriteRequest{Timeseries: batch}
+		data, err := request.Marshal()
+		if err != nil {
+			return consumererror.NewPermanent(fmt.Errorf("failed to marshal remote write request: %w", err))
+		}
+		body := snappy.Encode(nil, data)
+
+		if e.wal != nil {
+			err = e.wal.append(body)
+		} else {
+			err = e.send(ctx, body)
+		}
+		if err != nil {
+			// Batches sent before the failure will be sent again on retry,
+			// which is safe, because remote write endpoints deduplicate identical samples.
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drainWAL sends records from the write-ahead log in order until the context is canceled.
+func (e *remoteWriteExporter) drainWAL(ctx context.Context) {
+	defer e.wg.Done()
+
+	flushInterval := e.config.WAL.FlushInterval
+	if flushInterval == 0 {
+		flushInterval = defaultWALFlushInterval
+	}
+
+	for {
+		record, next, err := e.wal.peek()
+		if err != nil {
+			e.logger.Error("Failed to read from write-ahead log", zap.Error(err))
+			record = nil
+		}
+
+		if record == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.wal.notify:
+				continue
+			case <-time.After(flushInterval):
+				continue
+			}
+		}
+
+		if err := e.send(ctx, record); err != nil {
+			if !consumererror.IsPermanent(err) {
+				e.logger.Warn("Failed to send request from write-ahead log, will retry", zap.Error(err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(flushInterval):
+					continue
+				}
+			}
+			e.logger.Error("Dropping request from write-ahead log", zap.Error(err))
+		}
+
+		if err := e.wal.commit(next); err != nil {
+			e.logger.Error("Failed to commit write-ahead log offset", zap.Error(err))
+		}
+	}
+}
+
+// send posts the snappy compressed remote write request.
+// Client errors are permanent, so the request is not retried.
+func (e *remoteWriteExporter) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", e.userAgent)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	err = fmt.Errorf("remote write request failed with status %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return consumererror.NewPermanent(err)
+	}
+	return err
+}
+
+// batchSeries splits series into batches which marshaled size doesn't exceed maxSize.
+// A single series larger than maxSize is sent in its own batch.
+func batchSeries(series []prompb.TimeSeries, maxSize int) [][]prompb.TimeSeries {
+	var (
+		batches   [][]prompb.TimeSeries
+		batch     []prompb.TimeSeries
+		batchSize int
+	)
+
+	for _, s := range series {
+		size := s.Size()
+		if len(batch) > 0 && batchSize+size > maxSize {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, s)
+		batchSize += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+type remoteWriteServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []prompb.WriteRequest
+	status   int
+}
+
+func newRemoteWriteServer(t *testing.T) *remoteWriteServer {
+	s := &remoteWriteServer{status: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, remoteWriteVersion, r.Header.Get("X-Prometheus-Remote-Write-Version"))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.status != http.StatusNoContent {
+			w.WriteHeader(s.status)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, req.Unmarshal(data))
+		s.requests = append(s.requests, req)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *remoteWriteServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *remoteWriteServer) seriesCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, req := range s.requests {
+		count += len(req.Timeseries)
+	}
+	return count
+}
+
+func gaugeMetrics(count int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for i := 0; i < count; i++ {
+		m := metrics.AppendEmpty()
+		m.SetName("gauge")
+		m.SetDataType(pmetric.MetricDataTypeGauge)
+		m.Gauge().DataPoints().AppendEmpty().SetIntVal(int64(i))
+	}
+	return md
+}
+
+func createTestExporter(t *testing.T, cfg *Config) component.MetricsExporter {
+	exp, err := newMetricsExporter(cfg, componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, exp.Shutdown(context.Background()))
+	})
+	return exp
+}
+
+func TestPushMetrics(t *testing.T) {
+	server := newRemoteWriteServer(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.QueueSettings.Enabled = false
+
+	exp := createTestExporter(t, cfg)
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), gaugeMetrics(3)))
+
+	assert.Equal(t, 3, server.seriesCount())
+	require.Len(t, server.requests, 1)
+}
+
+func TestPushMetricsInBatches(t *testing.T) {
+	server := newRemoteWriteServer(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.QueueSettings.Enabled = false
+	cfg.MaxBatchSizeBytes = 1
+
+	exp := createTestExporter(t, cfg)
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), gaugeMetrics(3)))
+
+	assert.Equal(t, 3, server.seriesCount())
+	assert.Len(t, server.requests, 3)
+}
+
+func TestSendErrors(t *testing.T) {
+	server := newRemoteWriteServer(t)
+	e := &remoteWriteExporter{
+		config: &Config{},
+		client: server.Client(),
+	}
+	e.config.Endpoint = server.URL
+
+	server.setStatus(http.StatusBadRequest)
+	err := e.send(context.Background(), []byte{})
+	assert.True(t, consumererror.IsPermanent(err))
+
+	server.setStatus(http.StatusTooManyRequests)
+	err = e.send(context.Background(), []byte{})
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+
+	server.setStatus(http.StatusServiceUnavailable)
+	err = e.send(context.Background(), []byte{})
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+}
+
+func TestPushMetricsWithWAL(t *testing.T) {
+	server := newRemoteWriteServer(t)
+	server.setStatus(http.StatusServiceUnavailable)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.QueueSettings.Enabled = false
+	cfg.WAL = &WALConfig{
+		Directory:     t.TempDir(),
+		FlushInterval: 10 * time.Millisecond,
+	}
+
+	exp := createTestExporter(t, cfg)
+	// Data is accepted while the endpoint is unavailable and sent once it recovers.
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), gaugeMetrics(2)))
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), gaugeMetrics(1)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, server.seriesCount())
+
+	server.setStatus(http.StatusNoContent)
+	assert.Eventually(t, func() bool {
+		return server.seriesCount() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
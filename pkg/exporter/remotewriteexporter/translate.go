@@ -0,0 +1,272 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	metricNameLabel = "__name__"
+	bucketLabel     = "le"
+	quantileLabel   = "quantile"
+
+	bucketSuffix = "_bucket"
+	sumSuffix    = "_sum"
+	countSuffix  = "_count"
+)
+
+// sanitizeLabelName replaces characters which are not allowed in Prometheus label names with underscores.
+// Names starting with a digit or with the reserved double underscore prefix are prefixed with "key".
+func sanitizeLabelName(name string) string {
+	return sanitize(name, false)
+}
+
+// sanitizeMetricName replaces characters which are not allowed in Prometheus metric names with underscores.
+func sanitizeMetricName(name string) string {
+	return sanitize(name, true)
+}
+
+func sanitize(name string, allowColon bool) string {
+	if name == "" {
+		return name
+	}
+
+	sanitized := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || (allowColon && r == ':')) {
+			return r
+		}
+		return '_'
+	}, name)
+
+	if unicode.IsDigit(rune(sanitized[0])) {
+		return "key_" + sanitized
+	}
+	if !allowColon && strings.HasPrefix(sanitized, "__") {
+		return "key" + sanitized
+	}
+	return sanitized
+}
+
+// seriesBuilder translates OTLP metrics into Prometheus remote write time series.
+type seriesBuilder struct {
+	namespace      string
+	externalLabels map[string]string
+}
+
+// build translates metrics into time series. It returns the series and the number of data points
+// which couldn't be translated, i.e. data points of delta temporality metrics.
+func (b seriesBuilder) build(md pmetric.Metrics) ([]prompb.TimeSeries, int) {
+	var (
+		series  []prompb.TimeSeries
+		dropped int
+	)
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceAttributes := rm.Resource().Attributes()
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				var d int
+				series, d = b.appendMetric(series, ms.At(k), resourceAttributes)
+				dropped += d
+			}
+		}
+	}
+
+	return series, dropped
+}
+
+func (b seriesBuilder) appendMetric(series []prompb.TimeSeries, m pmetric.Metric, resourceAttributes pcommon.Map) ([]prompb.TimeSeries, int) {
+	name := b.metricName(m.Name())
+
+	switch m.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		series = b.appendNumberDataPoints(series, name, m.Gauge().DataPoints(), resourceAttributes)
+	case pmetric.MetricDataTypeSum:
+		if m.Sum().AggregationTemporality() != pmetric.MetricAggregationTemporalityCumulative {
+			return series, m.Sum().DataPoints().Len()
+		}
+		series = b.appendNumberDataPoints(series, name, m.Sum().DataPoints(), resourceAttributes)
+	case pmetric.MetricDataTypeHistogram:
+		if m.Histogram().AggregationTemporality() != pmetric.MetricAggregationTemporalityCumulative {
+			return series, m.Histogram().DataPoints().Len()
+		}
+		series = b.appendHistogramDataPoints(series, name, m.Histogram().DataPoints(), resourceAttributes)
+	case pmetric.MetricDataTypeSummary:
+		series = b.appendSummaryDataPoints(series, name, m.Summary().DataPoints(), resourceAttributes)
+	case pmetric.MetricDataTypeExponentialHistogram:
+		return series, m.ExponentialHistogram().DataPoints().Len()
+	}
+
+	return series, 0
+}
+
+func (b seriesBuilder) metricName(name string) string {
+	if b.namespace != "" {
+		name = b.namespace + "_" + name
+	}
+	return sanitizeMetricName(name)
+}
+
+func (b seriesBuilder) appendNumberDataPoints(series []prompb.TimeSeries, name string, dps pmetric.NumberDataPointSlice, resourceAttributes pcommon.Map) []prompb.TimeSeries {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+
+		var v float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			v = float64(dp.IntVal())
+		case pmetric.NumberDataPointValueTypeDouble:
+			v = dp.DoubleVal()
+		}
+		if dp.Flags().HasFlag(pmetric.MetricDataPointFlagNoRecordedValue) {
+			v = math.Float64frombits(value.StaleNaN)
+		}
+
+		labels := b.labels(resourceAttributes, dp.Attributes())
+		series = append(series, newSeries(labels, name, v, dp.Timestamp()))
+	}
+	return series
+}
+
+func (b seriesBuilder) appendHistogramDataPoints(series []prompb.TimeSeries, name string, dps pmetric.HistogramDataPointSlice, resourceAttributes pcommon.Map) []prompb.TimeSeries {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		stale := dp.Flags().HasFlag(pmetric.MetricDataPointFlagNoRecordedValue)
+		valueOf := func(v float64) float64 {
+			if stale {
+				return math.Float64frombits(value.StaleNaN)
+			}
+			return v
+		}
+
+		labels := b.labels(resourceAttributes, dp.Attributes())
+		ts := dp.Timestamp()
+
+		bounds := dp.ExplicitBounds()
+		counts := dp.BucketCounts()
+		var cumulative uint64
+		for j := 0; j < bounds.Len() && j < counts.Len(); j++ {
+			cumulative += counts.At(j)
+			bucketLabels := withLabel(labels, bucketLabel, strconv.FormatFloat(bounds.At(j), 'f', -1, 64))
+			series = append(series, newSeries(bucketLabels, name+bucketSuffix, valueOf(float64(cumulative)), ts))
+		}
+		infLabels := withLabel(labels, bucketLabel, "+Inf")
+		series = append(series,
+			newSeries(infLabels, name+bucketSuffix, valueOf(float64(dp.Count())), ts),
+			newSeries(labels, name+sumSuffix, valueOf(dp.Sum()), ts),
+			newSeries(labels, name+countSuffix, valueOf(float64(dp.Count())), ts),
+		)
+	}
+	return series
+}
+
+func (b seriesBuilder) appendSummaryDataPoints(series []prompb.TimeSeries, name string, dps pmetric.SummaryDataPointSlice, resourceAttributes pcommon.Map) []prompb.TimeSeries {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		labels := b.labels(resourceAttributes, dp.Attributes())
+		ts := dp.Timestamp()
+
+		quantiles := dp.QuantileValues()
+		for j := 0; j < quantiles.Len(); j++ {
+			q := quantiles.At(j)
+			quantileLabels := withLabel(labels, quantileLabel, strconv.FormatFloat(q.Quantile(), 'f', -1, 64))
+			series = append(series, newSeries(quantileLabels, name, q.Value(), ts))
+		}
+		series = append(series,
+			newSeries(labels, name+sumSuffix, dp.Sum(), ts),
+			newSeries(labels, name+countSuffix, float64(dp.Count()), ts),
+		)
+	}
+	return series
+}
+
+// labels builds the label set of a series from external labels, resource attributes and data point attributes,
+// in increasing order of precedence. Attributes whose names collide after sanitization are joined with a semicolon.
+func (b seriesBuilder) labels(resourceAttributes pcommon.Map, attributes pcommon.Map) map[string]string {
+	labels := make(map[string]string, len(b.externalLabels)+resourceAttributes.Len()+attributes.Len())
+	for name, v := range b.externalLabels {
+		labels[name] = v
+	}
+
+	for _, attrs := range []pcommon.Map{resourceAttributes, attributes} {
+		sanitized := map[string][]string{}
+		keys := make([]string, 0, attrs.Len())
+		attrs.Range(func(k string, _ pcommon.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			name := sanitizeLabelName(k)
+			if name == "" {
+				continue
+			}
+			v, _ := attrs.Get(k)
+			sanitized[name] = append(sanitized[name], v.AsString())
+		}
+		for name, values := range sanitized {
+			labels[name] = strings.Join(values, ";")
+		}
+	}
+
+	return labels
+}
+
+func withLabel(labels map[string]string, name string, labelValue string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = labelValue
+	return result
+}
+
+// newSeries creates a time series with a single sample. Labels are sorted by name as required by remote write.
+func newSeries(labels map[string]string, name string, v float64, ts pcommon.Timestamp) prompb.TimeSeries {
+	promLabels := make([]prompb.Label, 0, len(labels)+1)
+	for k, lv := range labels {
+		promLabels = append(promLabels, prompb.Label{Name: k, Value: lv})
+	}
+	promLabels = append(promLabels, prompb.Label{Name: metricNameLabel, Value: name})
+	sort.Slice(promLabels, func(i, j int) bool {
+		return promLabels[i].Name < promLabels[j].Name
+	})
+
+	return prompb.TimeSeries{
+		Labels: promLabels,
+		Samples: []prompb.Sample{
+			{
+				Value:     v,
+				Timestamp: ts.AsTime().UnixNano() / int64(1e6),
+			},
+		},
+	}
+}
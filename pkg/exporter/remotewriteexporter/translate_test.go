@@ -0,0 +1,172 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSanitize(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expectedLabel  string
+		expectedMetric string
+	}{
+		{name: "valid_name", expectedLabel: "valid_name", expectedMetric: "valid_name"},
+		{name: "k8s.pod.name", expectedLabel: "k8s_pod_name", expectedMetric: "k8s_pod_name"},
+		{name: "http:requests", expectedLabel: "http_requests", expectedMetric: "http:requests"},
+		{name: "0day", expectedLabel: "key_0day", expectedMetric: "key_0day"},
+		{name: "__name__", expectedLabel: "key__name__", expectedMetric: "__name__"},
+		{name: "zażółć", expectedLabel: "za____", expectedMetric: "za____"},
+		{name: "", expectedLabel: "", expectedMetric: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedLabel, sanitizeLabelName(tc.name))
+			assert.Equal(t, tc.expectedMetric, sanitizeMetricName(tc.name))
+		})
+	}
+}
+
+func labelsOf(series prompb.TimeSeries) map[string]string {
+	labels := map[string]string{}
+	for _, l := range series.Labels {
+		labels[l.Name] = l.Value
+	}
+	return labels
+}
+
+func TestBuildNumberSeries(t *testing.T) {
+	ts := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().UpsertString("k8s.pod.name", "pod-1")
+	rm.Resource().Attributes().UpsertString("cluster", "resource")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("memory.usage")
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	dp := gauge.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(42)
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.Attributes().UpsertString("http.method", "GET")
+	dp.Attributes().UpsertString("http_method", "POST")
+
+	sum := metrics.AppendEmpty()
+	sum.SetName("requests")
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+	dp = sum.Sum().DataPoints().AppendEmpty()
+	dp.SetDoubleVal(1.5)
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetFlags(pmetric.NewMetricDataPointFlags(pmetric.MetricDataPointFlagNoRecordedValue))
+
+	delta := metrics.AppendEmpty()
+	delta.SetName("delta")
+	delta.SetDataType(pmetric.MetricDataTypeSum)
+	delta.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+	delta.Sum().DataPoints().AppendEmpty().SetIntVal(1)
+
+	builder := seriesBuilder{
+		namespace:      "otel",
+		externalLabels: map[string]string{"cluster": "production", "region": "eu"},
+	}
+	series, dropped := builder.build(md)
+	assert.Equal(t, 1, dropped)
+	require.Len(t, series, 2)
+
+	assert.Equal(t, map[string]string{
+		"__name__":     "otel_memory_usage",
+		"cluster":      "resource",
+		"region":       "eu",
+		"k8s_pod_name": "pod-1",
+		"http_method":  "GET;POST",
+	}, labelsOf(series[0]))
+	assert.Equal(t, []prompb.Sample{{Value: 42, Timestamp: ts.UnixMilli()}}, series[0].Samples)
+	for i := 1; i < len(series[0].Labels); i++ {
+		assert.Less(t, series[0].Labels[i-1].Name, series[0].Labels[i].Name)
+	}
+
+	assert.Equal(t, "otel_requests", labelsOf(series[1])["__name__"])
+	assert.True(t, value.IsStaleNaN(series[1].Samples[0].Value))
+}
+
+func TestBuildHistogramSeries(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	metric.Histogram().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+	dp := metric.Histogram().DataPoints().AppendEmpty()
+	dp.SetExplicitBounds(pcommon.NewImmutableFloat64Slice([]float64{0.1, 1}))
+	dp.SetBucketCounts(pcommon.NewImmutableUInt64Slice([]uint64{2, 3, 1}))
+	dp.SetCount(6)
+	dp.SetSum(4.2)
+
+	series, dropped := seriesBuilder{}.build(md)
+	assert.Equal(t, 0, dropped)
+	require.Len(t, series, 5)
+
+	expected := []struct {
+		name  string
+		le    string
+		value float64
+	}{
+		{name: "latency_bucket", le: "0.1", value: 2},
+		{name: "latency_bucket", le: "1", value: 5},
+		{name: "latency_bucket", le: "+Inf", value: 6},
+		{name: "latency_sum", value: 4.2},
+		{name: "latency_count", value: 6},
+	}
+	for i, e := range expected {
+		labels := labelsOf(series[i])
+		assert.Equal(t, e.name, labels["__name__"])
+		assert.Equal(t, e.le, labels["le"])
+		assert.Equal(t, e.value, series[i].Samples[0].Value)
+	}
+}
+
+func TestBuildSummarySeries(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	metric.SetDataType(pmetric.MetricDataTypeSummary)
+	dp := metric.Summary().DataPoints().AppendEmpty()
+	q := dp.QuantileValues().AppendEmpty()
+	q.SetQuantile(0.99)
+	q.SetValue(0.5)
+	dp.SetCount(10)
+	dp.SetSum(2)
+
+	series, _ := seriesBuilder{}.build(md)
+	require.Len(t, series, 3)
+	assert.Equal(t, map[string]string{"__name__": "latency", "quantile": "0.99"}, labelsOf(series[0]))
+	assert.Equal(t, 0.5, series[0].Samples[0].Value)
+	assert.Equal(t, "latency_sum", labelsOf(series[1])["__name__"])
+	assert.Equal(t, "latency_count", labelsOf(series[2])["__name__"])
+	assert.False(t, math.IsNaN(series[2].Samples[0].Value))
+}
@@ -0,0 +1,215 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	walSegmentFileName = "segment"
+	walOffsetFileName  = "offset"
+
+	// walFrameHeaderLength is the length of the frame header: record length followed by its crc32 checksum.
+	walFrameHeaderLength = 8
+)
+
+var errWALFull = errors.New("write-ahead log is full")
+
+// wal is a write-ahead log which persists remote write requests on disk until they are sent.
+//
+// Records are appended to a single segment file as length-prefixed, checksummed frames.
+// The offset of the first unsent record is persisted in a separate file, so sent records
+// are not sent again after a restart. The segment is truncated once all of its records are sent.
+type wal struct {
+	mu         sync.Mutex
+	dir        string
+	segment    *os.File
+	size       int64
+	readOffset int64
+	maxSize    int64
+
+	// notify receives a value when a record is appended.
+	notify chan struct{}
+}
+
+func openWAL(dir string, maxSize int64) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create wal directory: %w", err)
+	}
+
+	segment, err := os.OpenFile(filepath.Join(dir, walSegmentFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wal segment: %w", err)
+	}
+
+	w := &wal{
+		dir:     dir,
+		segment: segment,
+		maxSize: maxSize,
+		notify:  make(chan struct{}, 1),
+	}
+
+	if err := w.recover(); err != nil {
+		segment.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// recover restores the read offset and truncates the segment after the last complete record,
+// dropping a record which was partially written before a crash.
+func (w *wal) recover() error {
+	offset, err := os.ReadFile(filepath.Join(w.dir, walOffsetFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read wal offset: %w", err)
+	case len(offset) == 8:
+		w.readOffset = int64(binary.BigEndian.Uint64(offset))
+	default:
+		return fmt.Errorf("invalid wal offset file length: %d", len(offset))
+	}
+
+	info, err := w.segment.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat wal segment: %w", err)
+	}
+	if w.readOffset > info.Size() {
+		w.readOffset = info.Size()
+	}
+
+	end := w.readOffset
+	for {
+		_, next, err := w.readAt(end)
+		if err != nil {
+			break
+		}
+		end = next
+	}
+
+	if err := w.segment.Truncate(end); err != nil {
+		return fmt.Errorf("failed to truncate wal segment: %w", err)
+	}
+	w.size = end
+	if w.size > w.readOffset {
+		w.notify <- struct{}{}
+	}
+	return nil
+}
+
+// append persists the record at the end of the log.
+func (w *wal) append(record []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	frameLength := int64(walFrameHeaderLength + len(record))
+	if w.size+frameLength > w.maxSize {
+		return errWALFull
+	}
+
+	frame := make([]byte, frameLength)
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(record)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(record))
+	copy(frame[walFrameHeaderLength:], record)
+
+	if _, err := w.segment.WriteAt(frame, w.size); err != nil {
+		// Drop whatever was partially written, so the next append starts at a frame boundary.
+		w.segment.Truncate(w.size)
+		return fmt.Errorf("failed to write to wal segment: %w", err)
+	}
+	if err := w.segment.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal segment: %w", err)
+	}
+	w.size += frameLength
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// peek returns the first unsent record and the offset of the record following it.
+// It returns a nil record when there are no unsent records.
+func (w *wal) peek() ([]byte, int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.readOffset >= w.size {
+		return nil, w.readOffset, nil
+	}
+	return w.readAt(w.readOffset)
+}
+
+// commit marks all records before the offset as sent.
+func (w *wal) commit(offset int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if offset >= w.size {
+		// All records are sent, start over with an empty segment.
+		if err := w.segment.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate wal segment: %w", err)
+		}
+		w.size = 0
+		offset = 0
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(offset))
+	tmp := filepath.Join(w.dir, walOffsetFileName+".tmp")
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return fmt.Errorf("failed to write wal offset: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, walOffsetFileName)); err != nil {
+		return fmt.Errorf("failed to write wal offset: %w", err)
+	}
+	w.readOffset = offset
+	return nil
+}
+
+func (w *wal) readAt(offset int64) ([]byte, int64, error) {
+	header := make([]byte, walFrameHeaderLength)
+	if _, err := w.segment.ReadAt(header, offset); err != nil {
+		return nil, offset, err
+	}
+
+	record := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := w.segment.ReadAt(record, offset+walFrameHeaderLength); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, offset, io.ErrUnexpectedEOF
+		}
+		return nil, offset, err
+	}
+	if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, offset, fmt.Errorf("wal record at offset %d is corrupted", offset)
+	}
+
+	return record, offset + int64(len(record)) + walFrameHeaderLength, nil
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.segment.Close()
+}
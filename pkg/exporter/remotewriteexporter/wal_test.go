@@ -0,0 +1,112 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewriteexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWALAppendPeekCommit(t *testing.T) {
+	w, err := openWAL(t.TempDir(), 1024)
+	require.NoError(t, err)
+	defer w.close()
+
+	record, _, err := w.peek()
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	require.NoError(t, w.append([]byte("first")))
+	require.NoError(t, w.append([]byte("second")))
+
+	record, next, err := w.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), record)
+	require.NoError(t, w.commit(next))
+
+	record, next, err = w.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), record)
+	require.NoError(t, w.commit(next))
+
+	record, _, err = w.peek()
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Equal(t, int64(0), w.size)
+}
+
+func TestWALFull(t *testing.T) {
+	w, err := openWAL(t.TempDir(), 2*walFrameHeaderLength+8)
+	require.NoError(t, err)
+	defer w.close()
+
+	require.NoError(t, w.append([]byte("1234")))
+	require.NoError(t, w.append([]byte("1234")))
+	assert.ErrorIs(t, w.append([]byte("1")), errWALFull)
+
+	// Space is reclaimed only once all records are sent.
+	_, next, err := w.peek()
+	require.NoError(t, err)
+	require.NoError(t, w.commit(next))
+	assert.ErrorIs(t, w.append([]byte("1")), errWALFull)
+
+	_, next, err = w.peek()
+	require.NoError(t, err)
+	require.NoError(t, w.commit(next))
+	assert.NoError(t, w.append([]byte("1")))
+}
+
+func TestWALRecoversAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openWAL(dir, 1024)
+	require.NoError(t, err)
+
+	require.NoError(t, w.append([]byte("first")))
+	require.NoError(t, w.append([]byte("second")))
+	_, next, err := w.peek()
+	require.NoError(t, err)
+	require.NoError(t, w.commit(next))
+	require.NoError(t, w.close())
+
+	// Simulate a partially written record.
+	segment, err := os.OpenFile(filepath.Join(dir, walSegmentFileName), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = segment.Write([]byte{0, 0, 0, 10, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, segment.Close())
+
+	w, err = openWAL(dir, 1024)
+	require.NoError(t, err)
+	defer w.close()
+
+	select {
+	case <-w.notify:
+	default:
+		t.Fatal("expected notification about unsent records")
+	}
+
+	record, next, err := w.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), record)
+	require.NoError(t, w.commit(next))
+
+	record, _, err = w.peek()
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
@@ -0,0 +1,339 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// errBufferFull is returned when an item is rejected because the buffer is full
+// and the eviction policy is drop_newest.
+var errBufferFull = errors.New("buffer is full")
+
+// bufferItem is a single buffered request.
+type bufferItem struct {
+	seq      uint64
+	enqueued time.Time
+	data     interface{}
+}
+
+// spilledItem is an item written to the spillover directory, its data is read only
+// when it's moved back to memory.
+type spilledItem struct {
+	seq      uint64
+	enqueued time.Time
+}
+
+// bufferCodec converts buffered data to bytes and back for the spillover to disk.
+type bufferCodec struct {
+	marshal   func(data interface{}) ([]byte, error)
+	unmarshal func(buf []byte) (interface{}, error)
+}
+
+// buffer is a FIFO queue of requests waiting to be sent. It holds up to max_items requests in memory,
+// the following ones are spilled over to disk if a spillover directory is configured.
+// Items in memory are always older than the spilled ones, so the order is kept.
+type buffer struct {
+	cfg    BufferSettings
+	dir    string
+	codec  bufferCodec
+	logger *zap.Logger
+
+	mutex    sync.Mutex
+	memory   []*bufferItem
+	spilled  []spilledItem
+	nextSeq  uint64
+	evicting bool
+	notify   chan struct{}
+
+	now func() time.Time
+}
+
+// newBuffer creates the buffer, loading items spilled over to dir before a restart.
+// Spillover is disabled if dir is empty.
+func newBuffer(cfg BufferSettings, dir string, codec bufferCodec, logger *zap.Logger) (*buffer, error) {
+	b := &buffer{
+		cfg:    cfg,
+		dir:    dir,
+		codec:  codec,
+		logger: logger,
+		notify: make(chan struct{}, 1),
+		now:    time.Now,
+	}
+	if dir == "" {
+		return b, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the spillover directory: %w", err)
+	}
+	if err := b.loadSpilled(); err != nil {
+		return nil, err
+	}
+	b.fill()
+	if len(b.memory) > 0 {
+		b.logger.Info("Loaded spilled over requests", zap.Int("requests", len(b.memory)+len(b.spilled)))
+	}
+	return b, nil
+}
+
+// push adds the data at the end of the buffer. If the buffer is full, either the oldest item
+// is evicted, or errBufferFull is returned, depending on the eviction policy.
+func (b *buffer) push(data interface{}) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.dropExpired()
+	if b.full() {
+		if !b.evicting {
+			b.logger.Warn("Buffer is full, evicting requests", zap.String("eviction_policy", string(b.cfg.EvictionPolicy)))
+			b.evicting = true
+		}
+		if b.cfg.EvictionPolicy == DropNewestEvictionPolicy {
+			return errBufferFull
+		}
+		b.dropHead()
+	} else {
+		b.evicting = false
+	}
+
+	item := &bufferItem{seq: b.nextSeq, enqueued: b.now(), data: data}
+	b.nextSeq++
+	if len(b.spilled) == 0 && len(b.memory) < b.cfg.MaxItems {
+		b.memory = append(b.memory, item)
+	} else if err := b.spill(item); err != nil {
+		return err
+	}
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// peek returns the oldest item which hasn't expired, or nil if the buffer is empty.
+func (b *buffer) peek() *bufferItem {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.dropExpired()
+	if len(b.memory) == 0 {
+		return nil
+	}
+	return b.memory[0]
+}
+
+// remove removes the item after it's been sent. It's a no-op if the item has already been evicted.
+func (b *buffer) remove(item *bufferItem) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.memory) > 0 && b.memory[0] == item {
+		b.dropHead()
+	}
+}
+
+// retain replaces the data of the item with the part of it which failed to be sent.
+func (b *buffer) retain(item *bufferItem, data interface{}) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	item.data = data
+}
+
+// ready returns a channel which receives a value when an item is pushed.
+func (b *buffer) ready() <-chan struct{} {
+	return b.notify
+}
+
+// len returns the number of buffered items.
+func (b *buffer) len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.memory) + len(b.spilled)
+}
+
+// close writes the items kept in memory to the spillover directory, so they're sent after a restart.
+// Without spillover, they're dropped.
+func (b *buffer) close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.dir == "" {
+		if len(b.memory) > 0 {
+			b.logger.Warn("Dropping buffered requests on shutdown", zap.Int("requests", len(b.memory)))
+		}
+		b.memory = nil
+		return nil
+	}
+
+	var errs []error
+	for _, item := range b.memory {
+		if err := b.writeItem(item); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	b.memory = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to spill over %d buffered requests on shutdown: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func (b *buffer) full() bool {
+	if len(b.memory) < b.cfg.MaxItems {
+		return false
+	}
+	return b.dir == "" || len(b.spilled) >= b.cfg.Spillover.MaxItems
+}
+
+// dropHead removes the oldest item and moves spilled items to memory in its place.
+func (b *buffer) dropHead() {
+	b.memory[0] = nil
+	b.memory = b.memory[1:]
+	b.fill()
+}
+
+func (b *buffer) dropExpired() {
+	if b.cfg.MaxAge <= 0 {
+		return
+	}
+
+	dropped := 0
+	for len(b.memory) > 0 && b.now().Sub(b.memory[0].enqueued) > b.cfg.MaxAge {
+		b.dropHead()
+		dropped++
+	}
+	if dropped > 0 {
+		b.logger.Debug("Dropped expired requests", zap.Int("requests", dropped), zap.Duration("max_age", b.cfg.MaxAge))
+	}
+}
+
+// fill moves the oldest spilled items to memory until it's full.
+func (b *buffer) fill() {
+	for len(b.memory) < b.cfg.MaxItems && len(b.spilled) > 0 {
+		spilled := b.spilled[0]
+		b.spilled = b.spilled[1:]
+
+		item, err := b.readItem(spilled)
+		if err != nil {
+			b.logger.Warn("Dropping spilled over request which cannot be read", zap.Uint64("seq", spilled.seq), zap.Error(err))
+			continue
+		}
+		b.memory = append(b.memory, item)
+	}
+}
+
+func (b *buffer) spill(item *bufferItem) error {
+	if err := b.writeItem(item); err != nil {
+		return fmt.Errorf("failed to spill over request: %w", err)
+	}
+	b.spilled = append(b.spilled, spilledItem{seq: item.seq, enqueued: item.enqueued})
+	return nil
+}
+
+// A spilled item is a file named after the item's sequence number,
+// with the enqueue time as unix nanoseconds in the first 8 bytes, followed by the data.
+func (b *buffer) itemPath(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d", seq))
+}
+
+func (b *buffer) writeItem(item *bufferItem) error {
+	data, err := b.codec.marshal(item.data)
+	if err != nil {
+		return err
+	}
+	content := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(content, uint64(item.enqueued.UnixNano()))
+	content = append(content, data...)
+	return os.WriteFile(b.itemPath(item.seq), content, 0600)
+}
+
+func (b *buffer) readItem(spilled spilledItem) (*bufferItem, error) {
+	path := b.itemPath(spilled.seq)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	if len(content) < 8 {
+		return nil, errors.New("spilled over request is truncated")
+	}
+
+	data, err := b.codec.unmarshal(content[8:])
+	if err != nil {
+		return nil, err
+	}
+	return &bufferItem{seq: spilled.seq, enqueued: spilled.enqueued, data: data}, nil
+}
+
+// loadSpilled indexes the items spilled over before a restart, reading only their enqueue times.
+func (b *buffer) loadSpilled() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the spillover directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		seq, err := strconv.ParseUint(entry.Name(), 10, 64)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+
+		enqueued, err := b.readEnqueueTime(seq)
+		if err != nil {
+			b.logger.Warn("Skipping spilled over request which cannot be read", zap.String("file", entry.Name()), zap.Error(err))
+			continue
+		}
+		b.spilled = append(b.spilled, spilledItem{seq: seq, enqueued: enqueued})
+		if seq >= b.nextSeq {
+			b.nextSeq = seq + 1
+		}
+	}
+
+	sort.Slice(b.spilled, func(i, j int) bool {
+		return b.spilled[i].seq < b.spilled[j].seq
+	})
+	return nil
+}
+
+func (b *buffer) readEnqueueTime(seq uint64) (time.Time, error) {
+	f, err := os.Open(b.itemPath(seq))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(header))), nil
+}
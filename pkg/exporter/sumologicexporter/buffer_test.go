@@ -0,0 +1,208 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var stringBufferCodec = bufferCodec{
+	marshal: func(data interface{}) ([]byte, error) {
+		return []byte(data.(string)), nil
+	},
+	unmarshal: func(buf []byte) (interface{}, error) {
+		return string(buf), nil
+	},
+}
+
+func newTestBuffer(t *testing.T, cfg BufferSettings, dir string) *buffer {
+	cfg.Enabled = true
+	if cfg.EvictionPolicy == "" {
+		cfg.EvictionPolicy = DropOldestEvictionPolicy
+	}
+	b, err := newBuffer(cfg, dir, stringBufferCodec, zap.NewNop())
+	require.NoError(t, err)
+	return b
+}
+
+// drain removes all items from the buffer and returns their data.
+func drain(b *buffer) []string {
+	var result []string
+	for item := b.peek(); item != nil; item = b.peek() {
+		result = append(result, item.data.(string))
+		b.remove(item)
+	}
+	return result
+}
+
+func pushAll(t *testing.T, b *buffer, data ...string) {
+	for _, d := range data {
+		require.NoError(t, b.push(d))
+	}
+}
+
+func TestBufferOrder(t *testing.T) {
+	b := newTestBuffer(t, BufferSettings{MaxItems: 10}, "")
+	pushAll(t, b, "a", "b", "c")
+
+	assert.Equal(t, 3, b.len())
+	assert.Equal(t, []string{"a", "b", "c"}, drain(b))
+	assert.Equal(t, 0, b.len())
+	assert.Nil(t, b.peek())
+}
+
+func TestBufferEvictionPolicies(t *testing.T) {
+	t.Run("drop_oldest", func(t *testing.T) {
+		b := newTestBuffer(t, BufferSettings{MaxItems: 2, EvictionPolicy: DropOldestEvictionPolicy}, "")
+		pushAll(t, b, "a", "b", "c")
+		assert.Equal(t, []string{"b", "c"}, drain(b))
+	})
+
+	t.Run("drop_newest", func(t *testing.T) {
+		b := newTestBuffer(t, BufferSettings{MaxItems: 2, EvictionPolicy: DropNewestEvictionPolicy}, "")
+		pushAll(t, b, "a", "b")
+		assert.ErrorIs(t, b.push("c"), errBufferFull)
+		assert.Equal(t, []string{"a", "b"}, drain(b))
+	})
+}
+
+func TestBufferMaxAge(t *testing.T) {
+	now := time.Now()
+	b := newTestBuffer(t, BufferSettings{MaxItems: 10, MaxAge: time.Minute}, "")
+	b.now = func() time.Time { return now }
+
+	pushAll(t, b, "a")
+	now = now.Add(30 * time.Second)
+	pushAll(t, b, "b")
+	now = now.Add(45 * time.Second)
+
+	assert.Equal(t, []string{"b"}, drain(b))
+}
+
+func TestBufferExpiredItemsMakeRoom(t *testing.T) {
+	now := time.Now()
+	b := newTestBuffer(t, BufferSettings{MaxItems: 1, MaxAge: time.Minute, EvictionPolicy: DropNewestEvictionPolicy}, "")
+	b.now = func() time.Time { return now }
+
+	pushAll(t, b, "a")
+	now = now.Add(2 * time.Minute)
+	pushAll(t, b, "b")
+
+	assert.Equal(t, []string{"b"}, drain(b))
+}
+
+func TestBufferRemoveEvictedItem(t *testing.T) {
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2}, "")
+	pushAll(t, b, "a", "b")
+
+	// the item being sent is evicted by a new one
+	item := b.peek()
+	pushAll(t, b, "c")
+	b.remove(item)
+
+	assert.Equal(t, []string{"b", "c"}, drain(b))
+}
+
+func TestBufferRetain(t *testing.T) {
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2}, "")
+	pushAll(t, b, "ab", "c")
+
+	b.retain(b.peek(), "b")
+	assert.Equal(t, []string{"b", "c"}, drain(b))
+}
+
+func TestBufferSpillover(t *testing.T) {
+	dir := t.TempDir()
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2, Spillover: SpilloverSettings{Directory: dir, MaxItems: 2}}, dir)
+	pushAll(t, b, "a", "b", "c", "d")
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, 4, b.len())
+
+	// the buffer is full, the oldest item is evicted
+	pushAll(t, b, "e")
+	assert.Equal(t, []string{"b", "c", "d", "e"}, drain(b))
+
+	files, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestBufferSpilloverKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2, Spillover: SpilloverSettings{Directory: dir, MaxItems: 10}}, dir)
+	pushAll(t, b, "a", "b", "c")
+
+	// sending an item moves the oldest spilled one to memory
+	item := b.peek()
+	assert.Equal(t, "a", item.data)
+	b.remove(item)
+	pushAll(t, b, "d")
+
+	assert.Equal(t, []string{"b", "c", "d"}, drain(b))
+}
+
+func TestBufferCloseAndReload(t *testing.T) {
+	dir := t.TempDir()
+	cfg := BufferSettings{MaxItems: 2, MaxAge: time.Hour, Spillover: SpilloverSettings{Directory: dir, MaxItems: 10}}
+	b := newTestBuffer(t, cfg, dir)
+	pushAll(t, b, "a", "b", "c")
+	require.NoError(t, b.close())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	// a corrupted file is skipped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000100"), []byte("x"), 0600))
+	// files not created by the buffer are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("x"), 0600))
+
+	b = newTestBuffer(t, cfg, dir)
+	pushAll(t, b, "d")
+	assert.Equal(t, []string{"a", "b", "c", "d"}, drain(b))
+}
+
+func TestBufferCloseWithoutSpillover(t *testing.T) {
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2}, "")
+	pushAll(t, b, "a")
+	require.NoError(t, b.close())
+	assert.Equal(t, 0, b.len())
+}
+
+func TestBufferReady(t *testing.T) {
+	b := newTestBuffer(t, BufferSettings{MaxItems: 2}, "")
+	select {
+	case <-b.ready():
+		t.Fatal("buffer is empty")
+	default:
+	}
+
+	pushAll(t, b, "a", "b")
+	select {
+	case <-b.ready():
+	default:
+		t.Fatal("buffer is not ready after push")
+	}
+}
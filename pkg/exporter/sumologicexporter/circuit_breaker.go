@@ -0,0 +1,158 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/exporter/sumologicexporter/internal/observability"
+)
+
+// errCircuitOpen is returned for requests which are not sent, because the circuit breaker
+// of their endpoint is open. The requests are retried like other failed requests.
+var errCircuitOpen = errors.New("circuit breaker is open, the request was not sent")
+
+// circuitBreakers keeps a circuit breaker for every endpoint the data is sent to.
+type circuitBreakers struct {
+	logger   *zap.Logger
+	settings CircuitBreakerSettings
+	exporter string
+	now      func() time.Time
+
+	mutex    sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newCircuitBreakers(logger *zap.Logger, settings CircuitBreakerSettings, exporter string) *circuitBreakers {
+	return &circuitBreakers{
+		logger:   logger,
+		settings: settings,
+		exporter: exporter,
+		now:      time.Now,
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+// get returns the circuit breaker of the endpoint, it returns nil if circuit breakers are disabled.
+func (cbs *circuitBreakers) get(endpoint string) *circuitBreaker {
+	if cbs == nil {
+		return nil
+	}
+
+	cbs.mutex.Lock()
+	defer cbs.mutex.Unlock()
+	cb, ok := cbs.breakers[endpoint]
+	if !ok {
+		cb = &circuitBreaker{
+			endpoint: endpoint,
+			logger:   cbs.logger.With(zap.String("endpoint", endpoint)),
+			settings: cbs.settings,
+			exporter: cbs.exporter,
+			now:      cbs.now,
+		}
+		cbs.breakers[endpoint] = cb
+	}
+	return cb
+}
+
+// circuitBreaker stops sending requests to an endpoint after consecutive failures.
+// While it's open, requests fail fast without being sent, and a single probe request
+// is let through every probe interval. The breaker closes once a probe succeeds.
+type circuitBreaker struct {
+	endpoint string
+	logger   *zap.Logger
+	settings CircuitBreakerSettings
+	exporter string
+	now      func() time.Time
+
+	mutex    sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request can be sent to the endpoint.
+// The result of every allowed request has to be recorded with done.
+func (cb *circuitBreaker) allow() bool {
+	if cb == nil {
+		return true
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if !cb.open {
+		return true
+	}
+	if cb.probing || cb.now().Sub(cb.openedAt) < cb.settings.ProbeInterval {
+		return false
+	}
+	cb.probing = true
+	cb.logger.Info("Sending a probe request, circuit breaker is open")
+	return true
+}
+
+// done records the result of a sent request, resp is nil if the request failed without a response.
+// Server errors and throttling are failures of the endpoint, other responses mean it's available.
+func (cb *circuitBreaker) done(resp *http.Response) {
+	if cb == nil {
+		return
+	}
+
+	failed := resp == nil ||
+		resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	wasProbe := cb.probing
+	cb.probing = false
+
+	if !failed {
+		cb.failures = 0
+		if cb.open {
+			cb.open = false
+			cb.logger.Info("Circuit breaker closed, the endpoint recovered")
+			cb.recordState()
+		}
+		return
+	}
+
+	cb.failures++
+	switch {
+	case cb.open && wasProbe:
+		// the probe failed, wait for another probe interval
+		cb.openedAt = cb.now()
+	case !cb.open && cb.failures >= cb.settings.FailureThreshold:
+		cb.open = true
+		cb.openedAt = cb.now()
+		cb.logger.Warn("Circuit breaker opened, requests to the endpoint fail fast until a probe request succeeds",
+			zap.Int("consecutive_failures", cb.failures),
+			zap.Duration("probe_interval", cb.settings.ProbeInterval),
+		)
+		cb.recordState()
+	}
+}
+
+func (cb *circuitBreaker) recordState() {
+	if err := observability.RecordCircuitBreakerOpen(cb.open, cb.endpoint, cb.exporter); err != nil {
+		cb.logger.Debug("error for recording metric for circuit breaker state", zap.Error(err))
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func newTestCircuitBreakers(now *time.Time) *circuitBreakers {
+	cbs := newCircuitBreakers(zap.NewNop(), CircuitBreakerSettings{
+		Enabled:          true,
+		FailureThreshold: 3,
+		ProbeInterval:    10 * time.Second,
+	}, "sumologic")
+	cbs.now = func() time.Time { return *now }
+	return cbs
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	cb := newTestCircuitBreakers(&now).get("https://endpoint/logs")
+
+	// failures below the threshold don't open the circuit breaker and a success resets them
+	for i := 0; i < 2; i++ {
+		require.True(t, cb.allow())
+		cb.done(&http.Response{StatusCode: 503})
+	}
+	require.True(t, cb.allow())
+	cb.done(&http.Response{StatusCode: 200})
+	for i := 0; i < 2; i++ {
+		require.True(t, cb.allow())
+		cb.done(nil)
+	}
+	require.True(t, cb.allow())
+
+	// client errors don't count as failures of the endpoint
+	cb.done(&http.Response{StatusCode: 400})
+	for i := 0; i < 2; i++ {
+		require.True(t, cb.allow())
+		cb.done(&http.Response{StatusCode: 429})
+	}
+	require.True(t, cb.allow())
+	cb.done(&http.Response{StatusCode: 500})
+
+	// the circuit breaker is open, requests fail fast until the probe interval passes
+	assert.False(t, cb.allow())
+	now = now.Add(9 * time.Second)
+	assert.False(t, cb.allow())
+
+	// a single probe is let through
+	now = now.Add(time.Second)
+	require.True(t, cb.allow())
+	assert.False(t, cb.allow())
+
+	// a failed probe waits for another probe interval
+	cb.done(nil)
+	assert.False(t, cb.allow())
+	now = now.Add(10 * time.Second)
+	require.True(t, cb.allow())
+
+	// a successful probe closes the circuit breaker
+	cb.done(&http.Response{StatusCode: 204})
+	assert.True(t, cb.allow())
+	assert.True(t, cb.allow())
+}
+
+func TestCircuitBreakersPerEndpoint(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	cbs := newTestCircuitBreakers(&now)
+
+	logs := cbs.get("https://endpoint/logs")
+	assert.Same(t, logs, cbs.get("https://endpoint/logs"))
+	for i := 0; i < 3; i++ {
+		require.True(t, logs.allow())
+		logs.done(nil)
+	}
+	assert.False(t, logs.allow())
+	assert.True(t, cbs.get("https://endpoint/metrics").allow())
+}
+
+func TestCircuitBreakersDisabled(t *testing.T) {
+	var cbs *circuitBreakers
+	cb := cbs.get("https://endpoint/logs")
+	assert.Nil(t, cb)
+	assert.True(t, cb.allow())
+	cb.done(nil)
+}
+
+func TestSendFailsFastWithOpenCircuitBreaker(t *testing.T) {
+	failure := func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(503)
+	}
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+		failure, failure, failure,
+		func(w http.ResponseWriter, req *http.Request) {},
+	})
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	test.s.circuitBreakers = newTestCircuitBreakers(&now)
+
+	send := func() error {
+		rls := plog.NewResourceLogs()
+		rls.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("Example log")
+		_, err := test.s.sendNonOTLPLogs(context.Background(), rls, newFields(pcommon.NewMap()))
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, send())
+	}
+	assert.EqualValues(t, 3, *test.reqCounter)
+
+	// the request isn't sent while the circuit breaker is open
+	assert.ErrorIs(t, send(), errCircuitOpen)
+	assert.EqualValues(t, 3, *test.reqCounter)
+
+	// the probe is sent after the probe interval and closes the circuit breaker
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, send())
+	assert.EqualValues(t, 4, *test.reqCounter)
+}
@@ -39,6 +39,12 @@ type Config struct {
 	// Max HTTP request body size in bytes before compression (if applied).
 	// By default 1MB is recommended.
 	MaxRequestBodySize int `mapstructure:"max_request_body_size"`
+	// ProxySocket is a path to a unix domain socket (or a named pipe, e.g.
+	// `\\.\pipe\sumo-proxy`, on Windows) of a local forwarding proxy.
+	// When set, all connections are dialed through this socket while
+	// requests are still addressed to the configured endpoint.
+	// By default this is empty and connections are made directly.
+	ProxySocket string `mapstructure:"proxy_socket"`
 
 	// Logs related configuration
 	// Format to post logs into Sumo. (default json)
@@ -96,6 +102,28 @@ type Config struct {
 	ClearLogsTimestamp bool `mapstructure:"clear_logs_timestamp"`
 
 	JSONLogs `mapstructure:"json_logs"`
+
+	TraceContext TraceContextFields `mapstructure:"trace_context"`
+
+	// Buffer configures buffering of requests during outages of the Sumo Logic backend,
+	// as an alternative to sending_queue with explicit eviction policies.
+	Buffer BufferSettings `mapstructure:"buffer"`
+
+	// DebugTap is the id of the debug tap extension, the exported data is published to it
+	// so that it can be tapped for live debugging.
+	DebugTap *config.ComponentID `mapstructure:"debug_tap"`
+
+	// ContentHash configures sending a hash of the content of each request,
+	// so that retried requests can be deduplicated downstream.
+	ContentHash ContentHashSettings `mapstructure:"content_hash"`
+
+	// CircuitBreaker configures failing fast for endpoints which keep failing,
+	// so that retries don't saturate the network during backend incidents.
+	CircuitBreaker CircuitBreakerSettings `mapstructure:"circuit_breaker"`
+
+	// DryRun configures writing the requests to files instead of sending them,
+	// so that pipeline changes can be validated against expected payloads.
+	DryRun DryRunSettings `mapstructure:"dry_run"`
 }
 
 type JSONLogs struct {
@@ -118,6 +146,128 @@ type JSONLogs struct {
 	FlattenBody bool `mapstructure:"flatten_body"`
 }
 
+// TraceContextFields configures promotion of log records' trace context to Sumo fields.
+type TraceContextFields struct {
+	// Enabled defines whether trace ID and span ID of log records should be
+	// sent as fields, so that logs can be correlated with traces.
+	// This option affects JSON and text log formats only, as OTLP logs carry
+	// the trace context natively.
+	// By default this is false.
+	Enabled bool `mapstructure:"enabled"`
+	// TraceIDKey defines the name of the field with the hex encoded trace ID.
+	// By default this is "trace_id".
+	TraceIDKey string `mapstructure:"trace_id_key"`
+	// SpanIDKey defines the name of the field with the hex encoded span ID.
+	// By default this is "span_id".
+	SpanIDKey string `mapstructure:"span_id_key"`
+}
+
+// ContentHashSettings configures sending a hash of the content of each request.
+type ContentHashSettings struct {
+	// Enabled defines whether the hex encoded SHA-256 hash of the request content
+	// and fields is sent in the header. The hash is the same when a request is retried.
+	// By default this is false.
+	Enabled bool `mapstructure:"enabled"`
+	// Header defines the name of the header with the hash.
+	// By default this is "X-Content-Hash".
+	Header string `mapstructure:"header"`
+	// Field defines the name of the field with the hash, so that the hash can be
+	// used for deduplication in searches. This option affects JSON and text log formats only.
+	// By default the hash is not sent as a field.
+	Field string `mapstructure:"field"`
+}
+
+// CircuitBreakerSettings configures the circuit breaker of every endpoint the data is sent to.
+type CircuitBreakerSettings struct {
+	// Enabled defines whether requests to an endpoint fail fast after consecutive failures,
+	// i.e. server errors, throttling or connection errors, until a probe request succeeds.
+	// By default this is false.
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold defines the number of consecutive failures which open the circuit breaker.
+	// By default this is 5.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// ProbeInterval defines how often a probe request is sent while the circuit breaker is open.
+	// By default this is 30 seconds.
+	ProbeInterval time.Duration `mapstructure:"probe_interval"`
+}
+
+// DryRunSettings configures writing the requests of chosen signal types to files instead of sending them.
+type DryRunSettings struct {
+	// Directory is where the requests are written, each exporter and signal type uses its own subdirectory.
+	// Every request is written to two files, with the body as it would be sent and with its headers.
+	Directory string `mapstructure:"directory"`
+	// Logs defines whether logs requests are written to files instead of being sent.
+	// By default this is false.
+	Logs bool `mapstructure:"logs"`
+	// Metrics defines whether metrics requests are written to files instead of being sent.
+	// By default this is false.
+	Metrics bool `mapstructure:"metrics"`
+	// Traces defines whether traces requests are written to files instead of being sent.
+	// By default this is false.
+	Traces bool `mapstructure:"traces"`
+}
+
+func (drs *DryRunSettings) isEnabled() bool {
+	return drs.Logs || drs.Metrics || drs.Traces
+}
+
+// BufferSettings configures the buffer of requests waiting to be sent.
+type BufferSettings struct {
+	// Enabled defines whether requests are buffered and sent in the background,
+	// retrying them until they're sent or evicted.
+	// It cannot be used together with sending_queue.
+	// By default this is false.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxItems is the maximum number of requests kept in memory.
+	// Requests above it are spilled over to disk, if spillover is enabled.
+	// By default this is 1000.
+	MaxItems int `mapstructure:"max_items"`
+	// MaxAge is the maximum time a request can be buffered, older requests are evicted.
+	// By default this is 0, which means requests don't expire.
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// EvictionPolicy defines which requests are evicted when the buffer is full:
+	//   * drop_oldest - the oldest request is evicted to make room for the new one, preferring fresh data.
+	//   * drop_newest - the new request is rejected, preferring complete data from the start of the outage.
+	// By default this is drop_oldest.
+	EvictionPolicy EvictionPolicyType `mapstructure:"eviction_policy"`
+	// Spillover configures writing requests which don't fit in memory to disk.
+	Spillover SpilloverSettings `mapstructure:"spillover"`
+}
+
+// SpilloverSettings configures spilling over buffered requests to disk.
+type SpilloverSettings struct {
+	// Directory is where the spilled over requests are stored, each exporter
+	// and signal type uses its own subdirectory. Requests left in memory on shutdown are
+	// written there as well, and sent after a restart.
+	// By default this is empty, which means spillover is disabled.
+	Directory string `mapstructure:"directory"`
+	// MaxItems is the maximum number of requests stored on disk.
+	// By default this is 10000.
+	MaxItems int `mapstructure:"max_items"`
+}
+
+func (bs *BufferSettings) Validate() error {
+	if !bs.Enabled {
+		return nil
+	}
+	if bs.MaxItems <= 0 {
+		return errors.New("max_items must be positive")
+	}
+	if bs.MaxAge < 0 {
+		return errors.New("max_age cannot be negative")
+	}
+	switch bs.EvictionPolicy {
+	case DropOldestEvictionPolicy:
+	case DropNewestEvictionPolicy:
+	default:
+		return fmt.Errorf("unexpected eviction policy: %s", bs.EvictionPolicy)
+	}
+	if bs.Spillover.Directory != "" && bs.Spillover.MaxItems <= 0 {
+		return errors.New("spillover max_items must be positive")
+	}
+	return nil
+}
+
 // CreateDefaultHTTPClientSettings returns default http client settings
 func CreateDefaultHTTPClientSettings() confighttp.HTTPClientSettings {
 	return confighttp.HTTPClientSettings{
@@ -175,10 +325,38 @@ Please consult the changelog at https://github.com/SumoLogic/sumologic-otel-coll
 		)
 	}
 
+	if cfg.TraceContext.Enabled && (cfg.TraceContext.TraceIDKey == "" || cfg.TraceContext.SpanIDKey == "") {
+		return errors.New("trace_id_key and span_id_key cannot be empty when trace_context is enabled")
+	}
+
 	if err := cfg.QueueSettings.Validate(); err != nil {
 		return fmt.Errorf("queue settings has invalid configuration: %w", err)
 	}
 
+	if err := cfg.Buffer.Validate(); err != nil {
+		return fmt.Errorf("buffer settings has invalid configuration: %w", err)
+	}
+	if cfg.Buffer.Enabled && cfg.QueueSettings.Enabled {
+		return errors.New("sending_queue and buffer cannot be enabled at the same time")
+	}
+
+	if cfg.ContentHash.Enabled && cfg.ContentHash.Header == "" {
+		return errors.New("content_hash header cannot be empty when content_hash is enabled")
+	}
+
+	if cfg.DryRun.isEnabled() && cfg.DryRun.Directory == "" {
+		return errors.New("dry_run directory cannot be empty when dry run is enabled for any signal type")
+	}
+
+	if cfg.CircuitBreaker.Enabled {
+		if cfg.CircuitBreaker.FailureThreshold <= 0 {
+			return errors.New("circuit_breaker failure_threshold must be positive when circuit_breaker is enabled")
+		}
+		if cfg.CircuitBreaker.ProbeInterval <= 0 {
+			return errors.New("circuit_breaker probe_interval must be positive when circuit_breaker is enabled")
+		}
+	}
+
 	return nil
 }
 
@@ -194,6 +372,9 @@ type TraceFormatType string
 // PipelineType represents type of the pipeline
 type PipelineType string
 
+// EvictionPolicyType represents buffer.eviction_policy
+type EvictionPolicyType string
+
 // CompressEncodingType represents type of the pipeline
 type CompressEncodingType string
 
@@ -225,6 +406,10 @@ const (
 	PrometheusFormat MetricFormatType = "prometheus"
 	// OTLPMetricFormat represents metric_format: otlp
 	OTLPMetricFormat MetricFormatType = "otlp"
+	// DropOldestEvictionPolicy represents buffer.eviction_policy: drop_oldest
+	DropOldestEvictionPolicy EvictionPolicyType = "drop_oldest"
+	// DropNewestEvictionPolicy represents buffer.eviction_policy: drop_newest
+	DropNewestEvictionPolicy EvictionPolicyType = "drop_newest"
 	// OTLPTraceFormat represents trace_format: otlp
 	OTLPTraceFormat TraceFormatType = "otlp"
 	// GZIPCompression represents compress_encoding: gzip
@@ -273,6 +458,22 @@ const (
 	DefaultTimestampKey string = "timestamp"
 	// DefaultFlattenBody defines default FlattenBody value
 	DefaultFlattenBody bool = false
+	// DefaultTraceIDKey defines default TraceContext.TraceIDKey value
+	DefaultTraceIDKey string = "trace_id"
+	// DefaultSpanIDKey defines default TraceContext.SpanIDKey value
+	DefaultSpanIDKey string = "span_id"
 	// DefaultDropRoutingAttribute defines default DropRoutingAttribute
 	DefaultDropRoutingAttribute string = ""
+	// DefaultBufferMaxItems defines default Buffer.MaxItems
+	DefaultBufferMaxItems int = 1000
+	// DefaultEvictionPolicy defines default Buffer.EvictionPolicy
+	DefaultEvictionPolicy EvictionPolicyType = DropOldestEvictionPolicy
+	// DefaultSpilloverMaxItems defines default Buffer.Spillover.MaxItems
+	DefaultSpilloverMaxItems int = 10000
+	// DefaultContentHashHeader defines default ContentHash.Header
+	DefaultContentHashHeader string = "X-Content-Hash"
+	// DefaultCircuitBreakerFailureThreshold defines default CircuitBreaker.FailureThreshold
+	DefaultCircuitBreakerFailureThreshold int = 5
+	// DefaultCircuitBreakerProbeInterval defines default CircuitBreaker.ProbeInterval
+	DefaultCircuitBreakerProbeInterval time.Duration = 30 * time.Second
 )
@@ -117,6 +117,75 @@ Please consult the changelog at https://github.com/SumoLogic/sumologic-otel-coll
 				MetadataAttributes: []string{"some_attribute"},
 			},
 		},
+		{
+			name:          "unexpected buffer eviction policy",
+			expectedError: errors.New("buffer settings has invalid configuration: unexpected eviction policy: drop_random"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Buffer.Enabled = true
+				cfg.Buffer.EvictionPolicy = "drop_random"
+				return cfg
+			}(),
+		},
+		{
+			name:          "spillover without max items",
+			expectedError: errors.New("buffer settings has invalid configuration: spillover max_items must be positive"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Buffer.Enabled = true
+				cfg.Buffer.Spillover = SpilloverSettings{Directory: "/var/lib/otelcol/buffer"}
+				return cfg
+			}(),
+		},
+		{
+			name:          "buffer and sending_queue enabled",
+			expectedError: errors.New("sending_queue and buffer cannot be enabled at the same time"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Buffer.Enabled = true
+				cfg.QueueSettings.Enabled = true
+				return cfg
+			}(),
+		},
+		{
+			name:          "content hash without header",
+			expectedError: errors.New("content_hash header cannot be empty when content_hash is enabled"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ContentHash.Enabled = true
+				cfg.ContentHash.Header = ""
+				return cfg
+			}(),
+		},
+		{
+			name:          "circuit breaker without failure threshold",
+			expectedError: errors.New("circuit_breaker failure_threshold must be positive when circuit_breaker is enabled"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.CircuitBreaker.Enabled = true
+				cfg.CircuitBreaker.FailureThreshold = 0
+				return cfg
+			}(),
+		},
+		{
+			name:          "circuit breaker without probe interval",
+			expectedError: errors.New("circuit_breaker probe_interval must be positive when circuit_breaker is enabled"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.CircuitBreaker.Enabled = true
+				cfg.CircuitBreaker.ProbeInterval = 0
+				return cfg
+			}(),
+		},
+		{
+			name:          "dry run without directory",
+			expectedError: errors.New("dry_run directory cannot be empty when dry run is enabled for any signal type"),
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.DryRun.Logs = true
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range testcases {
@@ -0,0 +1,116 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	dryRunBodyExtension    = ".body"
+	dryRunHeadersExtension = ".headers"
+)
+
+// dryRun writes the requests to files instead of sending them,
+// so the payloads can be compared with the expected ones.
+type dryRun struct {
+	settings DryRunSettings
+	dir      string
+
+	mutex     sync.Mutex
+	sequences map[PipelineType]uint64
+}
+
+func newDryRun(settings DryRunSettings, exporter string) *dryRun {
+	return &dryRun{
+		settings:  settings,
+		dir:       filepath.Join(settings.Directory, strings.ReplaceAll(exporter, "/", "_")),
+		sequences: map[PipelineType]uint64{},
+	}
+}
+
+// enabled returns whether the requests of the pipeline are written to files, it's false if dry run is disabled.
+func (dr *dryRun) enabled(pipeline PipelineType) bool {
+	if dr == nil {
+		return false
+	}
+	switch pipeline {
+	case LogsPipeline:
+		return dr.settings.Logs
+	case MetricsPipeline:
+		return dr.settings.Metrics
+	case TracesPipeline:
+		return dr.settings.Traces
+	default:
+		return false
+	}
+}
+
+// write writes the body of the request and its method, URL and headers to two files
+// named with the sequence number of the request within the pipeline, e.g. logs/00000000000000000001.body
+// and logs/00000000000000000001.headers. The body is written as it would be sent, i.e. compressed.
+func (dr *dryRun) write(pipeline PipelineType, req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+	}
+
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	dir := filepath.Join(dr.dir, string(pipeline))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create the dry run directory: %w", err)
+	}
+
+	dr.sequences[pipeline]++
+	path := filepath.Join(dir, fmt.Sprintf("%020d", dr.sequences[pipeline]))
+
+	if err := os.WriteFile(path+dryRunHeadersExtension, []byte(dumpRequestHeaders(req)), 0600); err != nil {
+		return fmt.Errorf("failed to write the dry run request headers: %w", err)
+	}
+	if err := os.WriteFile(path+dryRunBodyExtension, body, 0600); err != nil {
+		return fmt.Errorf("failed to write the dry run request body: %w", err)
+	}
+	return nil
+}
+
+// dumpRequestHeaders returns the request line followed by the headers sorted by name, one per line.
+func dumpRequestHeaders(req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", req.Method, req.URL.String())
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	return sb.String()
+}
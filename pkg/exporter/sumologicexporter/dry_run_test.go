@@ -0,0 +1,86 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestDryRunEnabled(t *testing.T) {
+	var dr *dryRun
+	assert.False(t, dr.enabled(LogsPipeline))
+
+	dr = newDryRun(DryRunSettings{Directory: t.TempDir(), Metrics: true}, "sumologic")
+	assert.False(t, dr.enabled(LogsPipeline))
+	assert.True(t, dr.enabled(MetricsPipeline))
+	assert.False(t, dr.enabled(TracesPipeline))
+}
+
+func TestSendLogsDryRun(t *testing.T) {
+	dir := t.TempDir()
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){})
+	test.s.dryRun = newDryRun(DryRunSettings{Directory: dir, Logs: true}, "sumologic/dry")
+
+	send := func(lines ...string) {
+		rls := plog.NewResourceLogs()
+		logRecords := rls.ScopeLogs().AppendEmpty().LogRecords()
+		for _, line := range lines {
+			logRecords.AppendEmpty().Body().SetStringVal(line)
+		}
+		_, err := test.s.sendNonOTLPLogs(context.Background(),
+			rls,
+			fieldsFromMap(map[string]string{"key1": "value", "key2": "value2"}),
+		)
+		require.NoError(t, err)
+	}
+
+	send("Example log", "Another example log")
+	send("Example log")
+	assert.EqualValues(t, 0, *test.reqCounter)
+
+	logsDir := filepath.Join(dir, "sumologic_dry", "logs")
+	entries, err := os.ReadDir(logsDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	body, err := os.ReadFile(filepath.Join(logsDir, "00000000000000000001.body"))
+	require.NoError(t, err)
+	assert.Equal(t, "Example log\nAnother example log", string(body))
+
+	headers, err := os.ReadFile(filepath.Join(logsDir, "00000000000000000001.headers"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"POST "+test.srv.URL+"\n"+
+			"Content-Type: application/x-www-form-urlencoded\n"+
+			"X-Sumo-Category: source_category\n"+
+			"X-Sumo-Client: otelcol\n"+
+			"X-Sumo-Fields: key1=value, key2=value2\n"+
+			"X-Sumo-Host: source_host\n"+
+			"X-Sumo-Name: source_name\n",
+		string(headers),
+	)
+
+	body, err = os.ReadFile(filepath.Join(logsDir, "00000000000000000002.body"))
+	require.NoError(t, err)
+	assert.Equal(t, "Example log", string(body))
+}
@@ -20,9 +20,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -32,6 +36,7 @@ import (
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/debugtapextension"
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension"
 )
 
@@ -39,6 +44,9 @@ const (
 	logsDataUrl    = "/api/v1/collector/logs"
 	metricsDataUrl = "/api/v1/collector/metrics"
 	tracesDataUrl  = "/api/v1/collector/traces"
+
+	// minBufferRetryInterval is used to retry buffered requests if retry_on_failure doesn't set an interval.
+	minBufferRetryInterval = time.Second
 )
 
 type sumologicexporter struct {
@@ -49,6 +57,8 @@ type sumologicexporter struct {
 
 	clientLock sync.RWMutex
 	client     *http.Client
+	// exportReporter is set if sumologicextension is used, the requests are reported to it.
+	exportReporter exportReporter
 
 	compressorPool sync.Pool
 
@@ -60,6 +70,29 @@ type sumologicexporter struct {
 	dataUrlMetrics string
 	dataUrlLogs    string
 	dataUrlTraces  string
+
+	// bufferedSignal is set if buffering is enabled, the buffer is created on start.
+	bufferedSignal *bufferedSignal
+	buffer         *buffer
+	stopBuffer     context.CancelFunc
+	bufferDone     chan struct{}
+
+	// tapPoint is set if the debug tap is configured, the data is published to it before sending.
+	tapPoint debugtapextension.TapPoint
+
+	// circuitBreakers is set if the circuit breaker is enabled, it's shared by all requests.
+	circuitBreakers *circuitBreakers
+	// dryRun is set if dry run is enabled, it's shared by all requests.
+	dryRun *dryRun
+}
+
+// bufferedSignal adapts sending of a signal type to the buffer.
+type bufferedSignal struct {
+	dataType config.DataType
+	codec    bufferCodec
+	push     func(ctx context.Context, data interface{}) error
+	// failed returns the data which failed to be sent, if the error is a partial failure.
+	failed func(err error) (interface{}, bool)
 }
 
 func initExporter(cfg *Config, createSettings component.ExporterCreateSettings) (*sumologicexporter, error) {
@@ -95,6 +128,19 @@ func initExporter(cfg *Config, createSettings component.ExporterCreateSettings)
 		prometheusFormatter: pf,
 	}
 
+	if cfg.CircuitBreaker.Enabled {
+		se.circuitBreakers = newCircuitBreakers(createSettings.Logger, cfg.CircuitBreaker, cfg.ID().String())
+	}
+	if cfg.DryRun.isEnabled() {
+		se.dryRun = newDryRun(cfg.DryRun, cfg.ID().String())
+		se.logger.Warn("Dry run is enabled, requests are written to files instead of being sent",
+			zap.String("directory", cfg.DryRun.Directory),
+			zap.Bool("logs", cfg.DryRun.Logs),
+			zap.Bool("metrics", cfg.DryRun.Metrics),
+			zap.Bool("traces", cfg.DryRun.Traces),
+		)
+	}
+
 	se.logger.Info(
 		"Sumo Logic Exporter configured",
 		zap.String("log_format", string(cfg.LogFormat)),
@@ -114,10 +160,38 @@ func newLogsExporter(
 		return nil, fmt.Errorf("failed to initialize the logs exporter: %w", err)
 	}
 
+	pushLogsData := se.pushLogsData
+	if cfg.Buffer.Enabled {
+		se.bufferedSignal = &bufferedSignal{
+			dataType: config.LogsDataType,
+			codec: bufferCodec{
+				marshal: func(data interface{}) ([]byte, error) {
+					return plog.NewProtoMarshaler().MarshalLogs(data.(plog.Logs))
+				},
+				unmarshal: func(buf []byte) (interface{}, error) {
+					return plog.NewProtoUnmarshaler().UnmarshalLogs(buf)
+				},
+			},
+			push: func(ctx context.Context, data interface{}) error {
+				return se.pushLogsData(ctx, data.(plog.Logs))
+			},
+			failed: func(err error) (interface{}, bool) {
+				var logsErr consumererror.Logs
+				if errors.As(err, &logsErr) {
+					return logsErr.GetLogs(), true
+				}
+				return nil, false
+			},
+		}
+		pushLogsData = func(_ context.Context, ld plog.Logs) error {
+			return se.bufferRequest(ld.Clone())
+		}
+	}
+
 	return exporterhelper.NewLogsExporter(
 		cfg,
 		params,
-		se.pushLogsData,
+		pushLogsData,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -137,10 +211,38 @@ func newMetricsExporter(
 		return nil, err
 	}
 
+	pushMetricsData := se.pushMetricsData
+	if cfg.Buffer.Enabled {
+		se.bufferedSignal = &bufferedSignal{
+			dataType: config.MetricsDataType,
+			codec: bufferCodec{
+				marshal: func(data interface{}) ([]byte, error) {
+					return pmetric.NewProtoMarshaler().MarshalMetrics(data.(pmetric.Metrics))
+				},
+				unmarshal: func(buf []byte) (interface{}, error) {
+					return pmetric.NewProtoUnmarshaler().UnmarshalMetrics(buf)
+				},
+			},
+			push: func(ctx context.Context, data interface{}) error {
+				return se.pushMetricsData(ctx, data.(pmetric.Metrics))
+			},
+			failed: func(err error) (interface{}, bool) {
+				var metricsErr consumererror.Metrics
+				if errors.As(err, &metricsErr) {
+					return metricsErr.GetMetrics(), true
+				}
+				return nil, false
+			},
+		}
+		pushMetricsData = func(_ context.Context, md pmetric.Metrics) error {
+			return se.bufferRequest(md.Clone())
+		}
+	}
+
 	return exporterhelper.NewMetricsExporter(
 		cfg,
 		params,
-		se.pushMetricsData,
+		pushMetricsData,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -160,10 +262,38 @@ func newTracesExporter(
 		return nil, err
 	}
 
+	pushTracesData := se.pushTracesData
+	if cfg.Buffer.Enabled {
+		se.bufferedSignal = &bufferedSignal{
+			dataType: config.TracesDataType,
+			codec: bufferCodec{
+				marshal: func(data interface{}) ([]byte, error) {
+					return ptrace.NewProtoMarshaler().MarshalTraces(data.(ptrace.Traces))
+				},
+				unmarshal: func(buf []byte) (interface{}, error) {
+					return ptrace.NewProtoUnmarshaler().UnmarshalTraces(buf)
+				},
+			},
+			push: func(ctx context.Context, data interface{}) error {
+				return se.pushTracesData(ctx, data.(ptrace.Traces))
+			},
+			failed: func(err error) (interface{}, bool) {
+				var tracesErr consumererror.Traces
+				if errors.As(err, &tracesErr) {
+					return tracesErr.GetTraces(), true
+				}
+				return nil, false
+			},
+		}
+		pushTracesData = func(_ context.Context, td ptrace.Traces) error {
+			return se.bufferRequest(td.Clone())
+		}
+	}
+
 	return exporterhelper.NewTracesExporter(
 		cfg,
 		params,
-		se.pushTracesData,
+		pushTracesData,
 		// Disable exporterhelper Timeout, since we are using a custom mechanism
 		// within exporter itself
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
@@ -178,6 +308,10 @@ func newTracesExporter(
 // It returns the number of unsent logs and an error which contains a list of dropped records
 // so they can be handled by OTC retry mechanism
 func (se *sumologicexporter) pushLogsData(ctx context.Context, ld plog.Logs) error {
+	if se.tapPoint != nil {
+		se.tapPoint.PublishLogs(ld)
+	}
+
 	compr, err := se.getCompressor()
 	if err != nil {
 		return consumererror.NewLogs(err, ld)
@@ -196,6 +330,9 @@ func (se *sumologicexporter) pushLogsData(ctx context.Context, ld plog.Logs) err
 		logsUrl,
 		tracesUrl,
 	)
+	sdr.exportReporter = se.getExportReporter()
+	sdr.circuitBreakers = se.circuitBreakers
+	sdr.dryRun = se.dryRun
 
 	// Follow different execution path for OTLP format
 	if sdr.config.LogFormat == OTLPLogFormat {
@@ -264,6 +401,10 @@ func (se *sumologicexporter) pushLogsData(ctx context.Context, ld plog.Logs) err
 // it returns number of unsent metrics and error which contains list of dropped records
 // so they can be handle by the OTC retry mechanism
 func (se *sumologicexporter) pushMetricsData(ctx context.Context, md pmetric.Metrics) error {
+	if se.tapPoint != nil {
+		se.tapPoint.PublishMetrics(md)
+	}
+
 	compr, err := se.getCompressor()
 	if err != nil {
 		return consumererror.NewMetrics(err, md)
@@ -282,6 +423,9 @@ func (se *sumologicexporter) pushMetricsData(ctx context.Context, md pmetric.Met
 		logsUrl,
 		tracesUrl,
 	)
+	sdr.exportReporter = se.getExportReporter()
+	sdr.circuitBreakers = se.circuitBreakers
+	sdr.dryRun = se.dryRun
 
 	// Follow different execution path for OTLP format
 	if sdr.config.MetricFormat == OTLPMetricFormat {
@@ -350,6 +494,10 @@ func (se *sumologicexporter) handleUnauthorizedErrors(ctx context.Context, errs
 }
 
 func (se *sumologicexporter) pushTracesData(ctx context.Context, td ptrace.Traces) error {
+	if se.tapPoint != nil {
+		se.tapPoint.PublishTraces(td)
+	}
+
 	compr, err := se.getCompressor()
 	if err != nil {
 		return consumererror.NewTraces(err, td)
@@ -368,6 +516,9 @@ func (se *sumologicexporter) pushTracesData(ctx context.Context, td ptrace.Trace
 		logsUrl,
 		tracesUrl,
 	)
+	sdr.exportReporter = se.getExportReporter()
+	sdr.circuitBreakers = se.circuitBreakers
+	sdr.dryRun = se.dryRun
 
 	// Drop routing attribute from ResourceSpans
 	rss := td.ResourceSpans()
@@ -393,7 +544,17 @@ func (se *sumologicexporter) getCompressor() (compressor, error) {
 
 func (se *sumologicexporter) start(ctx context.Context, host component.Host) error {
 	se.host = host
-	return se.configure(ctx)
+	if se.config.DebugTap != nil {
+		tap, err := debugtapextension.GetDebugTap(host, *se.config.DebugTap)
+		if err != nil {
+			return fmt.Errorf("error when getting debug tap: %w", err)
+		}
+		se.tapPoint = tap.TapPoint(se.config.ID().String())
+	}
+	if err := se.configure(ctx); err != nil {
+		return err
+	}
+	return se.startBuffer()
 }
 
 func (se *sumologicexporter) configure(ctx context.Context) error {
@@ -455,12 +616,23 @@ func (se *sumologicexporter) configure(ctx context.Context) error {
 		return fmt.Errorf("no auth extension and no endpoint specified")
 	}
 
-	client, err := httpSettings.ToClient(se.host.GetExtensions(), component.TelemetrySettings{})
+	var (
+		client *http.Client
+		err    error
+	)
+	if se.config.ProxySocket != "" {
+		client, err = newProxySocketClient(httpSettings, se.config.ProxySocket, se.host.GetExtensions())
+	} else {
+		client, err = httpSettings.ToClient(se.host.GetExtensions(), component.TelemetrySettings{})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP Client: %w", err)
 	}
 
 	se.setHTTPClient(client)
+	if foundSumoExt {
+		se.setExportReporter(ext)
+	}
 	return nil
 }
 
@@ -476,6 +648,18 @@ func (se *sumologicexporter) getHTTPClient() *http.Client {
 	return se.client
 }
 
+func (se *sumologicexporter) setExportReporter(reporter exportReporter) {
+	se.clientLock.Lock()
+	se.exportReporter = reporter
+	se.clientLock.Unlock()
+}
+
+func (se *sumologicexporter) getExportReporter() exportReporter {
+	se.clientLock.RLock()
+	defer se.clientLock.RUnlock()
+	return se.exportReporter
+}
+
 func (se *sumologicexporter) setDataURLs(logs, metrics, traces string) {
 	se.dataUrlsLock.Lock()
 	se.dataUrlLogs, se.dataUrlMetrics, se.dataUrlTraces = logs, metrics, traces
@@ -489,9 +673,101 @@ func (se *sumologicexporter) getDataURLs() (logs, metrics, traces string) {
 }
 
 func (se *sumologicexporter) shutdown(context.Context) error {
+	if se.buffer == nil {
+		return nil
+	}
+	se.stopBuffer()
+	<-se.bufferDone
+	return se.buffer.close()
+}
+
+// startBuffer creates the buffer and starts sending buffered requests in the background.
+func (se *sumologicexporter) startBuffer() error {
+	if se.bufferedSignal == nil {
+		return nil
+	}
+
+	var dir string
+	if se.config.Buffer.Spillover.Directory != "" {
+		dir = filepath.Join(
+			se.config.Buffer.Spillover.Directory,
+			strings.ReplaceAll(se.config.ID().String(), "/", "_"),
+			string(se.bufferedSignal.dataType),
+		)
+	}
+	buf, err := newBuffer(se.config.Buffer, dir, se.bufferedSignal.codec, se.logger)
+	if err != nil {
+		return err
+	}
+	se.buffer = buf
+
+	ctx, cancel := context.WithCancel(context.Background())
+	se.stopBuffer = cancel
+	se.bufferDone = make(chan struct{})
+	go se.sendBuffered(ctx)
+	return nil
+}
+
+// bufferRequest adds the data to the buffer. Data rejected by a full buffer is dropped.
+func (se *sumologicexporter) bufferRequest(data interface{}) error {
+	if err := se.buffer.push(data); err != nil {
+		return consumererror.NewPermanent(err)
+	}
 	return nil
 }
 
+// sendBuffered sends the buffered requests in order until the context is cancelled.
+// Requests which fail to be sent are retried with exponential backoff, until they're sent
+// or evicted from the buffer. Permanent errors aren't retried.
+func (se *sumologicexporter) sendBuffered(ctx context.Context) {
+	defer close(se.bufferDone)
+
+	retry := se.config.RetrySettings
+	if retry.InitialInterval <= 0 {
+		retry.InitialInterval = minBufferRetryInterval
+	}
+	interval := retry.InitialInterval
+	for {
+		item := se.buffer.peek()
+		if item == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-se.buffer.ready():
+				continue
+			}
+		}
+
+		err := se.bufferedSignal.push(ctx, item.data)
+		if err == nil || consumererror.IsPermanent(err) {
+			if err != nil {
+				se.logger.Error("Dropping buffered request which cannot be sent", zap.Error(err))
+			}
+			se.buffer.remove(item)
+			interval = retry.InitialInterval
+			continue
+		}
+
+		if failed, ok := se.bufferedSignal.failed(err); ok {
+			se.buffer.retain(item, failed)
+		}
+		se.logger.Debug("Failed to send buffered request, retrying",
+			zap.Error(err),
+			zap.Duration("interval", interval),
+			zap.Int("buffered_requests", se.buffer.len()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; retry.MaxInterval > 0 && interval > retry.MaxInterval {
+			interval = retry.MaxInterval
+		}
+	}
+}
+
 func (se *sumologicexporter) dropRoutingAttribute(attr pcommon.Map) {
 	attr.Remove(se.config.DropRoutingAttribute)
 }
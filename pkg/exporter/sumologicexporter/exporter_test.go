@@ -18,8 +18,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -30,13 +33,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/debugtapextension"
 )
 
 func LogRecordsToLogs(records []plog.LogRecord) plog.Logs {
@@ -1498,6 +1505,150 @@ func TestTracesWithDroppedAttribute(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func prepareBufferedLogsExporter(t *testing.T, handler http.HandlerFunc, cfgOpts ...func(*Config)) component.LogsExporter {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = srv.URL
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.Buffer.Enabled = true
+	cfg.RetrySettings.InitialInterval = 10 * time.Millisecond
+	for _, cfgOpt := range cfgOpts {
+		cfgOpt(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	exp, err := newLogsExporter(cfg, componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	return exp
+}
+
+func TestBufferedLogsRetried(t *testing.T) {
+	var requests int32
+	exp := prepareBufferedLogsExporter(t, func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "Example log", string(body))
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	// the request is buffered, so the pipeline doesn't see the failure
+	require.NoError(t, exp.ConsumeLogs(context.Background(), LogRecordsToLogs(exampleLog())))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.Shutdown(context.Background()))
+}
+
+func TestBufferedLogsDropNewest(t *testing.T) {
+	block := make(chan struct{})
+	exp := prepareBufferedLogsExporter(t, func(w http.ResponseWriter, req *http.Request) {
+		<-block
+	}, func(cfg *Config) {
+		cfg.Buffer.MaxItems = 1
+		cfg.Buffer.EvictionPolicy = DropNewestEvictionPolicy
+	})
+
+	require.NoError(t, exp.ConsumeLogs(context.Background(), LogRecordsToLogs(exampleLog())))
+	err := exp.ConsumeLogs(context.Background(), LogRecordsToLogs(exampleLog()))
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.ErrorIs(t, err, errBufferFull)
+
+	close(block)
+	require.NoError(t, exp.Shutdown(context.Background()))
+}
+
+func TestBufferedLogsSpilledOverOnShutdown(t *testing.T) {
+	dir := t.TempDir()
+	var requests int32
+	exp := prepareBufferedLogsExporter(t, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, func(cfg *Config) {
+		cfg.Buffer.Spillover.Directory = dir
+	})
+
+	require.NoError(t, exp.ConsumeLogs(context.Background(), LogRecordsToLogs(exampleLog())))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	files, err := os.ReadDir(filepath.Join(dir, "sumologic", "logs"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+type fakeTapPoint struct {
+	logs    []plog.Logs
+	metrics []pmetric.Metrics
+	traces  []ptrace.Traces
+}
+
+func (tp *fakeTapPoint) PublishLogs(ld plog.Logs)          { tp.logs = append(tp.logs, ld) }
+func (tp *fakeTapPoint) PublishMetrics(md pmetric.Metrics) { tp.metrics = append(tp.metrics, md) }
+func (tp *fakeTapPoint) PublishTraces(td ptrace.Traces)    { tp.traces = append(tp.traces, td) }
+
+type fakeDebugTap struct {
+	component.Extension
+	tapPoints map[string]*fakeTapPoint
+}
+
+func (t *fakeDebugTap) TapPoint(name string) debugtapextension.TapPoint {
+	tp := &fakeTapPoint{}
+	t.tapPoints[name] = tp
+	return tp
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestDebugTap(t *testing.T) {
+	tap := &fakeDebugTap{tapPoints: map[string]*fakeTapPoint{}}
+	tapID := config.NewComponentID("debug_tap")
+	host := &extensionsHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{tapID: tap},
+	}
+
+	cfg := createTestConfig()
+	test := prepareExporterTest(t, cfg, []func(w http.ResponseWriter, req *http.Request){
+		func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "Example log", extractBody(t, req))
+		},
+	})
+	cfg.DebugTap = &tapID
+	require.NoError(t, test.exp.start(context.Background(), host))
+
+	logs := LogRecordsToLogs(exampleLog())
+	require.NoError(t, test.exp.pushLogsData(context.Background(), logs))
+
+	require.Contains(t, tap.tapPoints, "sumologic")
+	assert.Equal(t, []plog.Logs{logs}, tap.tapPoints["sumologic"].logs)
+}
+
+func TestDebugTapNotFound(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = "http://localhost"
+	cfg.HTTPClientSettings.Auth = nil
+	tapID := config.NewComponentID("debug_tap")
+	cfg.DebugTap = &tapID
+
+	exp, err := initExporter(cfg, createExporterCreateSettings())
+	require.NoError(t, err)
+	assert.Error(t, exp.start(context.Background(), componenttest.NewNopHost()))
+}
+
 func Benchmark_ExporterPushLogs(b *testing.B) {
 	createConfig := func() *Config {
 		config := createDefaultConfig().(*Config)
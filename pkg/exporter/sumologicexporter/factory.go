@@ -63,7 +63,25 @@ func createDefaultConfig() config.Exporter {
 			TimestampKey: DefaultTimestampKey,
 			FlattenBody:  DefaultFlattenBody,
 		},
+		TraceContext: TraceContextFields{
+			TraceIDKey: DefaultTraceIDKey,
+			SpanIDKey:  DefaultSpanIDKey,
+		},
 		TraceFormat: OTLPTraceFormat,
+		Buffer: BufferSettings{
+			MaxItems:       DefaultBufferMaxItems,
+			EvictionPolicy: DefaultEvictionPolicy,
+			Spillover: SpilloverSettings{
+				MaxItems: DefaultSpilloverMaxItems,
+			},
+		},
+		ContentHash: ContentHashSettings{
+			Header: DefaultContentHashHeader,
+		},
+		CircuitBreaker: CircuitBreakerSettings{
+			FailureThreshold: DefaultCircuitBreakerFailureThreshold,
+			ProbeInterval:    DefaultCircuitBreakerProbeInterval,
+		},
 
 		HTTPClientSettings:   CreateDefaultHTTPClientSettings(),
 		RetrySettings:        exporterhelper.NewDefaultRetrySettings(),
@@ -53,9 +53,27 @@ func TestCreateDefaultConfig(t *testing.T) {
 			AddTimestamp: true,
 			TimestampKey: "timestamp",
 		},
+		TraceContext: TraceContextFields{
+			TraceIDKey: "trace_id",
+			SpanIDKey:  "span_id",
+		},
 		TranslateAttributes:      true,
 		TranslateTelegrafMetrics: true,
 		TraceFormat:              "otlp",
+		Buffer: BufferSettings{
+			MaxItems:       1000,
+			EvictionPolicy: "drop_oldest",
+			Spillover: SpilloverSettings{
+				MaxItems: 10000,
+			},
+		},
+		ContentHash: ContentHashSettings{
+			Header: "X-Content-Hash",
+		},
+		CircuitBreaker: CircuitBreakerSettings{
+			FailureThreshold: 5,
+			ProbeInterval:    30 * time.Second,
+		},
 
 		HTTPClientSettings: confighttp.HTTPClientSettings{
 			Timeout: 5 * time.Second,
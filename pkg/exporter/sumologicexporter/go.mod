@@ -3,6 +3,7 @@ module github.com/SumoLogic/sumologic-otel-collector/pkg/exporter/sumologicexpor
 go 1.18
 
 require (
+	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/debugtapextension v0.0.54-beta.0
 	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension v0.0.54-beta.0
 	github.com/google/go-cmp v0.5.7
 	github.com/klauspost/compress v1.15.6
@@ -53,3 +54,5 @@ require (
 )
 
 replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension => ./../../extension/sumologicextension
+
+replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/debugtapextension => ./../../extension/debugtapextension
@@ -30,6 +30,7 @@ func init() {
 		viewRequestsDuration,
 		viewRequestsBytes,
 		viewRequestsRecords,
+		viewCircuitBreakerOpen,
 	)
 	if err != nil {
 		fmt.Printf("Failed to register sumologic exporter's views: %v\n", err)
@@ -42,6 +43,8 @@ var (
 	mRequestsBytes    = stats.Int64("exporter/requests/bytes", "Total size of requests (in bytes)", "0")
 	mRequestsRecords  = stats.Int64("exporter/requests/records", "Total size of requests (in number of records)", "0")
 
+	mCircuitBreakerOpen = stats.Int64("exporter/circuit_breaker/open", "Whether the circuit breaker of the endpoint is open (1) or closed (0)", "1")
+
 	statusKey, _   = tag.NewKey("status_code")
 	endpointKey, _ = tag.NewKey("endpoint")
 	pipelineKey, _ = tag.NewKey("pipeline")
@@ -80,6 +83,14 @@ var viewRequestsRecords = &view.View{
 	Aggregation: view.Sum(),
 }
 
+var viewCircuitBreakerOpen = &view.View{
+	Name:        mCircuitBreakerOpen.Name(),
+	Description: mCircuitBreakerOpen.Description(),
+	Measure:     mCircuitBreakerOpen,
+	TagKeys:     []tag.Key{endpointKey, exporterKey},
+	Aggregation: view.LastValue(),
+}
+
 // RecordRequestsSent increments the metric that records sent requests
 func RecordRequestsSent(statusCode int, endpoint string, pipeline string, exporter string) error {
 	return stats.RecordWithTags(
@@ -135,3 +146,19 @@ func RecordRequestsRecords(records int64, statusCode int, endpoint string, pipel
 		mRequestsRecords.M(records),
 	)
 }
+
+// RecordCircuitBreakerOpen update metric which records the state of the circuit breaker of the endpoint
+func RecordCircuitBreakerOpen(open bool, endpoint string, exporter string) error {
+	var value int64
+	if open {
+		value = 1
+	}
+	return stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Insert(endpointKey, endpoint),
+			tag.Insert(exporterKey, exporter),
+		},
+		mCircuitBreakerOpen.M(value),
+	)
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// newProxySocketClient creates an HTTP client which dials all connections
+// through the local proxy socket instead of connecting to the endpoint directly.
+//
+// confighttp doesn't allow to replace the dialer of the transport it creates,
+// so the transport is built here following HTTPClientSettings.ToClient and
+// the configured authenticator is applied on top of it.
+func newProxySocketClient(
+	hcs confighttp.HTTPClientSettings,
+	socket string,
+	ext map[config.ComponentID]component.Extension,
+) (*http.Client, error) {
+	tlsCfg, err := hcs.TLSSetting.LoadTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// The local proxy is responsible for the egress, environment proxy
+	// settings must not redirect the connections elsewhere.
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		conn, err := dialProxySocket(ctx, socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to proxy socket %q: %w", socket, err)
+		}
+		return conn, nil
+	}
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
+	}
+	if hcs.ReadBufferSize > 0 {
+		transport.ReadBufferSize = hcs.ReadBufferSize
+	}
+	if hcs.WriteBufferSize > 0 {
+		transport.WriteBufferSize = hcs.WriteBufferSize
+	}
+	if hcs.MaxIdleConns != nil {
+		transport.MaxIdleConns = *hcs.MaxIdleConns
+	}
+	if hcs.MaxIdleConnsPerHost != nil {
+		transport.MaxIdleConnsPerHost = *hcs.MaxIdleConnsPerHost
+	}
+	if hcs.MaxConnsPerHost != nil {
+		transport.MaxConnsPerHost = *hcs.MaxConnsPerHost
+	}
+	if hcs.IdleConnTimeout != nil {
+		transport.IdleConnTimeout = *hcs.IdleConnTimeout
+	}
+
+	clientTransport := (http.RoundTripper)(transport)
+	if len(hcs.Headers) > 0 {
+		clientTransport = &headersRoundTripper{
+			transport: transport,
+			headers:   hcs.Headers,
+		}
+	}
+
+	if hcs.Auth != nil {
+		if ext == nil {
+			return nil, errors.New("extensions configuration not found")
+		}
+
+		authenticator, err := hcs.Auth.GetClientAuthenticator(ext)
+		if err != nil {
+			return nil, err
+		}
+
+		clientTransport, err = authenticator.RoundTripper(clientTransport)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hcs.CustomRoundTripper != nil {
+		clientTransport, err = hcs.CustomRoundTripper(clientTransport)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &http.Client{
+		Transport: clientTransport,
+		Timeout:   hcs.Timeout,
+	}, nil
+}
+
+// headersRoundTripper sets the configured headers on every request.
+type headersRoundTripper struct {
+	transport http.RoundTripper
+	headers   map[string]string
+}
+
+func (rt *headersRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range rt.headers {
+		req.Header.Set(k, v)
+	}
+	return rt.transport.RoundTrip(req)
+}
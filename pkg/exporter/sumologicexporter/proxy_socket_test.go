@@ -0,0 +1,89 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package sumologicexporter
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+// startProxySocketServer starts an HTTP server listening on a unix domain socket
+// and returns the socket path.
+func startProxySocketServer(t *testing.T, handler http.HandlerFunc) string {
+	// unix socket paths are limited in length so t.TempDir() can't be used here
+	dir, err := os.MkdirTemp("", "sumo")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "proxy.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = listener
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return socket
+}
+
+func TestProxySocket(t *testing.T) {
+	var reqCounter int32
+	socket := startProxySocketServer(t, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&reqCounter, 1)
+		assert.Equal(t, "sumo.invalid", req.Host)
+		assert.Equal(t, "/receiver/v1/http/token", req.URL.Path)
+		assert.Equal(t, "Example log", extractBody(t, req))
+	})
+
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = "http://sumo.invalid/receiver/v1/http/token"
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.ProxySocket = socket
+
+	exp, err := initExporter(cfg, createExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, exp.pushLogsData(context.Background(), LogRecordsToLogs(exampleLog())))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&reqCounter))
+}
+
+func TestProxySocketUnavailable(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.HTTPClientSettings.Endpoint = "http://sumo.invalid/receiver/v1/http/token"
+	cfg.HTTPClientSettings.Auth = nil
+	cfg.ProxySocket = filepath.Join(t.TempDir(), "missing.sock")
+
+	exp, err := initExporter(cfg, createExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+
+	err = exp.pushLogsData(context.Background(), LogRecordsToLogs(exampleLog()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to proxy socket")
+}
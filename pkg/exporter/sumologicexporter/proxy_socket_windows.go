@@ -0,0 +1,59 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package sumologicexporter
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+)
+
+const namedPipePrefix = `\\.\pipe\`
+
+// dialProxySocket connects to the named pipe of the local proxy when the path
+// points to one, otherwise to the unix domain socket.
+func dialProxySocket(ctx context.Context, socket string) (net.Conn, error) {
+	if !strings.HasPrefix(strings.ToLower(socket), namedPipePrefix) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(socket, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeConn{File: f, addr: pipeAddr(socket)}, nil
+}
+
+// pipeConn adapts an opened named pipe to net.Conn.
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.addr }
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
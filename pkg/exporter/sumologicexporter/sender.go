@@ -17,6 +17,8 @@ package sumologicexporter
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -140,6 +142,17 @@ type sender struct {
 	dataUrlMetrics      string
 	dataUrlLogs         string
 	dataUrlTraces       string
+	// exportReporter is optional, the requests are reported to it if it's set.
+	exportReporter exportReporter
+	// circuitBreakers is set if the circuit breaker is enabled.
+	circuitBreakers *circuitBreakers
+	// dryRun is set if dry run is enabled, the requests of its pipelines are written to files instead of being sent.
+	dryRun *dryRun
+}
+
+// exportReporter receives the feedback about sent requests, it's implemented by sumologicextension.
+type exportReporter interface {
+	ReportExport(latency time.Duration, throttled bool)
 }
 
 const (
@@ -195,6 +208,14 @@ var errUnauthorized = errors.New("unauthorized")
 
 // send sends data to sumologic
 func (s *sender) send(ctx context.Context, pipeline PipelineType, reader *countingReader, flds fields) error {
+	var hash string
+	if s.config.ContentHash.Enabled {
+		var err error
+		if hash, err = contentHash(reader, flds); err != nil {
+			return err
+		}
+	}
+
 	data, err := s.compressor.compress(reader.reader)
 	if err != nil {
 		return err
@@ -208,25 +229,52 @@ func (s *sender) send(ctx context.Context, pipeline PipelineType, reader *counti
 	if err := s.addRequestHeaders(req, pipeline, flds); err != nil {
 		return err
 	}
+	if hash != "" {
+		s.addContentHashHeaders(req, pipeline, hash)
+	}
 
 	s.logger.Debug("Sending data",
 		zap.String("pipeline", string(pipeline)),
 		zap.Any("headers", req.Header),
 	)
 
+	if s.dryRun.enabled(pipeline) {
+		return s.dryRun.write(pipeline, req)
+	}
+
+	breaker := s.circuitBreakers.get(req.URL.String())
+	if !breaker.allow() {
+		return errCircuitOpen
+	}
+
 	start := time.Now()
 	resp, err := s.client.Do(req)
 	if err != nil {
 		s.recordMetrics(time.Since(start), reader.counter, req, nil, pipeline)
+		s.reportExport(time.Since(start), nil)
+		breaker.done(nil)
 		return err
 	}
 	defer resp.Body.Close()
 
 	s.recordMetrics(time.Since(start), reader.counter, req, resp, pipeline)
+	s.reportExport(time.Since(start), resp)
+	breaker.done(resp)
 
 	return s.handleReceiverResponse(resp)
 }
 
+// reportExport reports the request to the export reporter, the request is considered throttled
+// if the backend responded with 429 Too Many Requests or 503 Service Unavailable.
+func (s *sender) reportExport(latency time.Duration, resp *http.Response) {
+	if s.exportReporter == nil {
+		return
+	}
+	throttled := resp != nil &&
+		(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)
+	s.exportReporter.ReportExport(latency, throttled)
+}
+
 func (s *sender) handleReceiverResponse(resp *http.Response) error {
 	// API responds with a 200 or 204 with ConentLength set to 0 when all data
 	// has been successfully ingested.
@@ -341,53 +389,97 @@ func (s *sender) createRequest(ctx context.Context, pipeline PipelineType, data
 	return req, err
 }
 
-// logToText converts LogRecord to a plain text line, returns it and error eventually
-func (s *sender) logToText(record plog.LogRecord) string {
-	return record.Body().AsString()
+// logToText converts LogRecord to a plain text line, returns it and error eventually.
+// Map and slice bodies are sent as JSON, encoded the same way as in the json format.
+func (s *sender) logToText(record plog.LogRecord) (string, error) {
+	body := record.Body()
+	switch body.Type() {
+	case pcommon.ValueTypeMap, pcommon.ValueTypeSlice:
+		return marshalJSON(valueAsRaw(body))
+	default:
+		return body.AsString(), nil
+	}
 }
 
-// logToJSON converts LogRecord to a json line, returns it and error eventually
+// logToJSON converts LogRecord to a json line, returns it and error eventually.
+// The record is not modified, as it can be shared with other exporters in the pipeline.
 func (s *sender) logToJSON(record plog.LogRecord) (string, error) {
+	line := record.Attributes().AsRaw()
 	if s.jsonLogsConfig.AddTimestamp {
-		addJSONTimestamp(record.Attributes(), s.jsonLogsConfig.TimestampKey, record.Timestamp())
+		addJSONTimestamp(line, s.jsonLogsConfig.TimestampKey, record.Timestamp())
 	}
 
 	// Only append the body when it's not empty to prevent sending 'null' log.
 	if body := record.Body(); !isEmptyAttributeValue(body) {
 		if s.jsonLogsConfig.FlattenBody && body.Type() == pcommon.ValueTypeMap {
-			// Cannot use CopyTo, as it overrides data.orig's values
-			body.MapVal().Range(func(k string, v pcommon.Value) bool {
-				record.Attributes().Insert(k, v)
-				return true
-			})
+			// Attributes take precedence over the body's fields with the same keys
+			for k, v := range body.MapVal().AsRaw() {
+				if _, ok := line[k]; !ok {
+					line[k] = v
+				}
+			}
 		} else {
-			record.Attributes().Upsert(s.jsonLogsConfig.LogKey, body)
+			line[s.jsonLogsConfig.LogKey] = valueAsRaw(body)
 		}
 	}
 
-	nextLine, err := json.Marshal(record.Attributes().AsRaw())
-	if err != nil {
-		return "", err
-	}
-
-	return bytes.NewBuffer(nextLine).String(), nil
+	return marshalJSON(line)
 }
 
 var timeZeroUTC = time.Unix(0, 0).UTC()
 
-// addJSONTimestamp adds a timestamp field to record attribtues before sending
-// out the logs as JSON.
+// addJSONTimestamp adds a timestamp field to the JSON log line before sending
+// it out, unless the record has an attribute with the same key.
 // If the attached timestamp is equal to 0 (millisecond based UNIX timestamp)
 // then send out current time formatted as milliseconds since January 1, 1970.
-func addJSONTimestamp(attrs pcommon.Map, timestampKey string, pt pcommon.Timestamp) {
+func addJSONTimestamp(line map[string]interface{}, timestampKey string, pt pcommon.Timestamp) {
+	if _, ok := line[timestampKey]; ok {
+		return
+	}
 	t := pt.AsTime()
 	if t == timeZeroUTC {
-		attrs.InsertInt(timestampKey, time.Now().UnixMilli())
+		line[timestampKey] = time.Now().UnixMilli()
 	} else {
-		attrs.InsertInt(timestampKey, t.UnixMilli())
+		line[timestampKey] = t.UnixMilli()
 	}
 }
 
+// valueAsRaw converts the value to its Go equivalent, keeping the structure of maps and slices,
+// so they are encoded as JSON objects and arrays instead of strings.
+func valueAsRaw(v pcommon.Value) interface{} {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		return v.MapVal().AsRaw()
+	case pcommon.ValueTypeSlice:
+		return v.SliceVal().AsRaw()
+	case pcommon.ValueTypeInt:
+		return v.IntVal()
+	case pcommon.ValueTypeDouble:
+		return v.DoubleVal()
+	case pcommon.ValueTypeBool:
+		return v.BoolVal()
+	case pcommon.ValueTypeBytes:
+		return v.BytesVal().AsRaw()
+	case pcommon.ValueTypeEmpty:
+		return nil
+	default:
+		return v.AsString()
+	}
+}
+
+// marshalJSON encodes the value as JSON without escaping HTML characters,
+// so e.g. '<' and '&' are sent as they are, like in text logs.
+func marshalJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	// Encode terminates the value with a newline
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
 func isEmptyAttributeValue(att pcommon.Value) bool {
 	t := att.Type()
 	return !(t == pcommon.ValueTypeString && len(att.StringVal()) > 0 ||
@@ -409,6 +501,11 @@ func (s *sender) sendNonOTLPLogs(ctx context.Context, rl plog.ResourceLogs, flds
 		errs           []error
 		droppedRecords []plog.LogRecord
 		currentRecords []plog.LogRecord
+
+		// records with different trace context have different fields, so they are sent
+		// in separate requests
+		currentTraceContext traceContext
+		currentFlds         = flds
 	)
 
 	slgs := rl.ScopeLogs()
@@ -423,7 +520,20 @@ func (s *sender) sendNonOTLPLogs(ctx context.Context, rl plog.ResourceLogs, flds
 				continue
 			}
 
-			sent, err := s.appendAndMaybeSend(ctx, []string{formattedLine}, LogsPipeline, &body, flds)
+			if recordTraceContext := s.getTraceContext(lr); recordTraceContext != currentTraceContext {
+				if body.Len() > 0 {
+					if err := s.send(ctx, LogsPipeline, body.toCountingReader(), currentFlds); err != nil {
+						errs = append(errs, err)
+						droppedRecords = append(droppedRecords, currentRecords...)
+					}
+					body.Reset()
+					currentRecords = currentRecords[:0]
+				}
+				currentTraceContext = recordTraceContext
+				currentFlds = s.addTraceContextFields(flds, currentTraceContext)
+			}
+
+			sent, err := s.appendAndMaybeSend(ctx, []string{formattedLine}, LogsPipeline, &body, currentFlds)
 			if err != nil {
 				errs = append(errs, err)
 				droppedRecords = append(droppedRecords, currentRecords...)
@@ -439,7 +549,7 @@ func (s *sender) sendNonOTLPLogs(ctx context.Context, rl plog.ResourceLogs, flds
 	}
 
 	if body.Len() > 0 {
-		if err := s.send(ctx, LogsPipeline, body.toCountingReader(), flds); err != nil {
+		if err := s.send(ctx, LogsPipeline, body.toCountingReader(), currentFlds); err != nil {
 			errs = append(errs, err)
 			droppedRecords = append(droppedRecords, currentRecords...)
 		}
@@ -448,13 +558,52 @@ func (s *sender) sendNonOTLPLogs(ctx context.Context, rl plog.ResourceLogs, flds
 	return droppedRecords, multierr.Combine(errs...)
 }
 
+// traceContext holds hex encoded trace ID and span ID of a log record
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// getTraceContext returns the trace context of the log record if it should be sent as fields
+func (s *sender) getTraceContext(lr plog.LogRecord) traceContext {
+	if !s.config.TraceContext.Enabled {
+		return traceContext{}
+	}
+
+	return traceContext{
+		traceID: lr.TraceID().HexString(),
+		spanID:  lr.SpanID().HexString(),
+	}
+}
+
+// addTraceContextFields returns fields extended with trace ID and span ID fields.
+// The provided fields are not modified.
+func (s *sender) addTraceContextFields(flds fields, tc traceContext) fields {
+	if tc.traceID == "" && tc.spanID == "" {
+		return flds
+	}
+
+	attrs := pcommon.NewMap()
+	if flds.isInitialized() {
+		flds.orig.CopyTo(attrs)
+	}
+	if tc.traceID != "" {
+		attrs.UpsertString(s.config.TraceContext.TraceIDKey, tc.traceID)
+	}
+	if tc.spanID != "" {
+		attrs.UpsertString(s.config.TraceContext.SpanIDKey, tc.spanID)
+	}
+
+	return newFields(attrs)
+}
+
 func (s *sender) formatLogLine(lr plog.LogRecord) (string, error) {
 	var formattedLine string
 	var err error
 
 	switch s.config.LogFormat {
 	case TextFormat:
-		formattedLine = s.logToText(lr)
+		formattedLine, err = s.logToText(lr)
 	case JSONFormat:
 		formattedLine, err = s.logToJSON(lr)
 	default:
@@ -781,6 +930,36 @@ func (s *sender) addRequestHeaders(req *http.Request, pipeline PipelineType, fld
 	return nil
 }
 
+// contentHash returns the hex encoded SHA-256 hash of the fields and the content of the reader,
+// the reader is reset to read the same content again.
+func contentHash(reader *countingReader, flds fields) (string, error) {
+	content, err := io.ReadAll(reader.reader)
+	if err != nil {
+		return "", err
+	}
+	reader.withBytes(content)
+
+	hash := sha256.New()
+	hash.Write([]byte(flds.string())) // Write can't actually return an error
+	hash.Write([]byte{'\n'})
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// addContentHashHeaders adds the content hash header and, for logs sent with fields, the content hash field.
+func (s *sender) addContentHashHeaders(req *http.Request, pipeline PipelineType, hash string) {
+	req.Header.Set(s.config.ContentHash.Header, hash)
+
+	if pipeline != LogsPipeline || s.config.LogFormat == OTLPLogFormat || s.config.ContentHash.Field == "" {
+		return
+	}
+	field := s.config.ContentHash.Field + "=" + hash
+	if fieldsStr := req.Header.Get(headerFields); fieldsStr != "" {
+		field = fieldsStr + ", " + field
+	}
+	req.Header.Set(headerFields, field)
+}
+
 // addSourceResourceAttributes adds source related attributes:
 // * source category
 // * source host
@@ -18,6 +18,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -26,6 +28,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -245,6 +248,99 @@ func TestSendLogs(t *testing.T) {
 	assert.EqualValues(t, 1, *test.reqCounter)
 }
 
+type fakeExportReporter struct {
+	throttled []bool
+}
+
+func (r *fakeExportReporter) ReportExport(_ time.Duration, throttled bool) {
+	r.throttled = append(r.throttled, throttled)
+}
+
+func TestSendReportsExports(t *testing.T) {
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+		func(w http.ResponseWriter, req *http.Request) {},
+		func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(429)
+		},
+		func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(503)
+		},
+		func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(500)
+		},
+	})
+	reporter := &fakeExportReporter{}
+	test.s.exportReporter = reporter
+
+	for i := 0; i < 4; i++ {
+		rls := plog.NewResourceLogs()
+		rls.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("Example log")
+		_, _ = test.s.sendNonOTLPLogs(context.Background(), rls, newFields(pcommon.NewMap()))
+	}
+
+	assert.EqualValues(t, 4, *test.reqCounter)
+	assert.Equal(t, []bool{false, true, true, false}, reporter.throttled)
+}
+
+func TestSendLogsContentHash(t *testing.T) {
+	hash := sha256.Sum256([]byte("key1=value, key2=value2\nExample log\nAnother example log"))
+	expectedHash := hex.EncodeToString(hash[:])
+
+	var hashes []string
+	callback := func(w http.ResponseWriter, req *http.Request) {
+		hashes = append(hashes, req.Header.Get("X-Content-Hash"))
+		assert.Equal(t, "key1=value, key2=value2, content_hash="+req.Header.Get("X-Content-Hash"), req.Header.Get("X-Sumo-Fields"))
+	}
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){callback, callback, callback}, func(cfg *Config) {
+		cfg.ContentHash.Enabled = true
+		cfg.ContentHash.Field = "content_hash"
+	})
+
+	send := func(lines ...string) {
+		rls := plog.NewResourceLogs()
+		logRecords := rls.ScopeLogs().AppendEmpty().LogRecords()
+		for _, line := range lines {
+			logRecords.AppendEmpty().Body().SetStringVal(line)
+		}
+		_, err := test.s.sendNonOTLPLogs(context.Background(),
+			rls,
+			fieldsFromMap(map[string]string{"key1": "value", "key2": "value2"}),
+		)
+		assert.NoError(t, err)
+	}
+
+	// a retried request has the same hash
+	send("Example log", "Another example log")
+	send("Example log", "Another example log")
+	send("Example log")
+	assert.EqualValues(t, 3, *test.reqCounter)
+	require.Len(t, hashes, 3)
+	assert.Equal(t, expectedHash, hashes[0])
+	assert.Equal(t, expectedHash, hashes[1])
+	assert.NotEqual(t, expectedHash, hashes[2])
+}
+
+func TestSendLogsContentHashCompressed(t *testing.T) {
+	hash := sha256.Sum256([]byte("\nExample log"))
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+		func(w http.ResponseWriter, req *http.Request) {
+			// the hash is calculated from the uncompressed content
+			assert.Equal(t, hex.EncodeToString(hash[:]), req.Header.Get("X-Dedup-Key"))
+			assert.Empty(t, req.Header.Get("X-Sumo-Fields"))
+		},
+	}, func(cfg *Config) {
+		cfg.CompressEncoding = GZIPCompression
+		cfg.ContentHash.Enabled = true
+		cfg.ContentHash.Header = "X-Dedup-Key"
+	})
+
+	rls := plog.NewResourceLogs()
+	rls.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("Example log")
+	_, err := test.s.sendNonOTLPLogs(context.Background(), rls, newFields(pcommon.NewMap()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, *test.reqCounter)
+}
+
 func TestSendLogsWithEmptyField(t *testing.T) {
 	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
 		func(w http.ResponseWriter, req *http.Request) {
@@ -340,6 +436,55 @@ func TestSendLogsSplit(t *testing.T) {
 	assert.EqualValues(t, 2, *test.reqCounter)
 }
 
+func TestSendLogsTraceContext(t *testing.T) {
+	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+		func(w http.ResponseWriter, req *http.Request) {
+			body := extractBody(t, req)
+			assert.Equal(t, "Example log\nAnother example log", body)
+			assert.Equal(t, "key1=value, span_id=0102030405060708, trace_id=0102030405060708090a0b0c0d0e0f10", req.Header.Get("X-Sumo-Fields"))
+		},
+		func(w http.ResponseWriter, req *http.Request) {
+			body := extractBody(t, req)
+			assert.Equal(t, "Log without trace context", body)
+			assert.Equal(t, "key1=value", req.Header.Get("X-Sumo-Fields"))
+		},
+		func(w http.ResponseWriter, req *http.Request) {
+			body := extractBody(t, req)
+			assert.Equal(t, "Log from another trace", body)
+			assert.Equal(t, "key1=value, span_id=1112131415161718, trace_id=1112131415161718191a1b1c1d1e1f20", req.Header.Get("X-Sumo-Fields"))
+		},
+	}, func(c *Config) {
+		c.TraceContext.Enabled = true
+	})
+
+	traceID := pcommon.NewTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	spanID := pcommon.NewSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	rls := plog.NewResourceLogs()
+	logRecords := rls.ScopeLogs().AppendEmpty().LogRecords()
+	lr := logRecords.AppendEmpty()
+	lr.Body().SetStringVal("Example log")
+	lr.SetTraceID(traceID)
+	lr.SetSpanID(spanID)
+	lr = logRecords.AppendEmpty()
+	lr.Body().SetStringVal("Another example log")
+	lr.SetTraceID(traceID)
+	lr.SetSpanID(spanID)
+	logRecords.AppendEmpty().Body().SetStringVal("Log without trace context")
+	lr = logRecords.AppendEmpty()
+	lr.Body().SetStringVal("Log from another trace")
+	lr.SetTraceID(pcommon.NewTraceID([16]byte{17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}))
+	lr.SetSpanID(pcommon.NewSpanID([8]byte{17, 18, 19, 20, 21, 22, 23, 24}))
+
+	flds := fieldsFromMap(map[string]string{"key1": "value"})
+	_, err := test.s.sendNonOTLPLogs(context.Background(), rls, flds)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, *test.reqCounter)
+
+	// the original fields must not be modified
+	assert.Equal(t, "key1=value", flds.string())
+}
+
 func TestSendLogsSplitFailedOne(t *testing.T) {
 	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
 		func(w http.ResponseWriter, req *http.Request) {
@@ -595,6 +740,150 @@ func TestSendLogsJsonConfig(t *testing.T) {
 	}
 }
 
+// structuredBodyLogs returns logs with bodies of every type, as produced by receivers and processors
+// parsing the logs, e.g. maps with nested slices and maps, slices, and strings which have to be escaped.
+func structuredBodyLogs() plog.ResourceLogs {
+	rls := plog.NewResourceLogs()
+	slgs := rls.ScopeLogs().AppendEmpty()
+
+	log := slgs.LogRecords().AppendEmpty()
+	pcommon.NewValueMap().CopyTo(log.Body())
+	pcommon.NewMapFromRaw(map[string]interface{}{
+		"bytes":  []byte{1, 2},
+		"html":   `<b> & "q"`,
+		"list":   []interface{}{1, "two", map[string]interface{}{"three": 3}},
+		"nested": map[string]interface{}{"ok": true, "ratio": 0.5},
+	}).CopyTo(log.Body().MapVal())
+	log.Attributes().InsertString("key", "value")
+
+	log = slgs.LogRecords().AppendEmpty()
+	slice, _ := pcommon.NewMapFromRaw(map[string]interface{}{
+		"slice": []interface{}{"a", map[string]interface{}{"b": "c"}, []interface{}{1, 2}},
+	}).Get("slice")
+	slice.CopyTo(log.Body())
+	log.Attributes().InsertString("key", "value")
+
+	log = slgs.LogRecords().AppendEmpty()
+	log.Body().SetStringVal(`{"a":1}`)
+	log.Attributes().InsertString("key", "value")
+
+	log = slgs.LogRecords().AppendEmpty()
+	log.Body().SetStringVal(`<b> & "q"`)
+	log.Attributes().InsertString("key", "value")
+
+	return rls
+}
+
+func TestSendLogsStructuredBody(t *testing.T) {
+	testcases := []struct {
+		name         string
+		configOpts   []func(*Config)
+		expectedBody string
+	}{
+		{
+			name: "text",
+			configOpts: []func(*Config){
+				func(c *Config) {
+					c.LogFormat = TextFormat
+				},
+			},
+			expectedBody: `{"bytes":"AQI=","html":"<b> & \"q\"","list":[1,"two",{"three":3}],"nested":{"ok":true,"ratio":0.5}}` + "\n" +
+				`["a",{"b":"c"},[1,2]]` + "\n" +
+				`{"a":1}` + "\n" +
+				`<b> & "q"`,
+		},
+		{
+			name: "json",
+			configOpts: []func(*Config){
+				func(c *Config) {
+					c.LogFormat = JSONFormat
+					c.JSONLogs = JSONLogs{
+						LogKey: DefaultLogKey,
+					}
+				},
+			},
+			expectedBody: `{"key":"value","log":{"bytes":"AQI=","html":"<b> & \"q\"","list":[1,"two",{"three":3}],"nested":{"ok":true,"ratio":0.5}}}` + "\n" +
+				`{"key":"value","log":["a",{"b":"c"},[1,2]]}` + "\n" +
+				`{"key":"value","log":"{\"a\":1}"}` + "\n" +
+				`{"key":"value","log":"<b> & \"q\""}`,
+		},
+		{
+			name: "json with flattened body",
+			configOpts: []func(*Config){
+				func(c *Config) {
+					c.LogFormat = JSONFormat
+					c.JSONLogs = JSONLogs{
+						LogKey:      DefaultLogKey,
+						FlattenBody: true,
+					}
+				},
+			},
+			expectedBody: `{"bytes":"AQI=","html":"<b> & \"q\"","key":"value","list":[1,"two",{"three":3}],"nested":{"ok":true,"ratio":0.5}}` + "\n" +
+				`{"key":"value","log":["a",{"b":"c"},[1,2]]}` + "\n" +
+				`{"key":"value","log":"{\"a\":1}"}` + "\n" +
+				`{"key":"value","log":"<b> & \"q\""}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+				func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(t, tc.expectedBody, extractBody(t, req))
+				},
+			}, tc.configOpts...)
+
+			rls := structuredBodyLogs()
+			expected := plog.NewResourceLogs()
+			rls.CopyTo(expected)
+
+			_, err := test.s.sendNonOTLPLogs(context.Background(), rls, fields{})
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, *test.reqCounter)
+			// the records can be shared with other exporters, so they must not be modified
+			assert.Equal(t, expected, rls)
+		})
+	}
+
+	t.Run("otlp", func(t *testing.T) {
+		expected := plog.NewLogs()
+		structuredBodyLogs().CopyTo(expected.ResourceLogs().AppendEmpty())
+
+		test := prepareOTLPSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
+			func(w http.ResponseWriter, req *http.Request) {
+				b, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				l, err := otlp.NewProtobufLogsUnmarshaler().UnmarshalLogs(b)
+				require.NoError(t, err)
+
+				expectedRecords := expected.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+				records := l.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+				require.Equal(t, expectedRecords.Len(), records.Len())
+				for i := 0; i < records.Len(); i++ {
+					assert.Equal(t, rawBody(expectedRecords.At(i).Body()), rawBody(records.At(i).Body()))
+				}
+			},
+		})
+
+		l := plog.NewLogs()
+		structuredBodyLogs().CopyTo(l.ResourceLogs().AppendEmpty())
+		assert.NoError(t, test.s.sendOTLPLogs(context.Background(), l))
+		assert.EqualValues(t, 1, *test.reqCounter)
+	})
+}
+
+// rawBody returns the body as a raw value, so that the order of the map keys doesn't matter
+func rawBody(body pcommon.Value) interface{} {
+	switch body.Type() {
+	case pcommon.ValueTypeMap:
+		return body.MapVal().AsRaw()
+	case pcommon.ValueTypeSlice:
+		return body.SliceVal().AsRaw()
+	default:
+		return body.AsString()
+	}
+}
+
 func TestSendLogsJson(t *testing.T) {
 	test := prepareSenderTest(t, []func(w http.ResponseWriter, req *http.Request){
 		func(w http.ResponseWriter, req *http.Request) {
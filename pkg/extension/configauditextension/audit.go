@@ -0,0 +1,183 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedPrefix starts values of redacted keys, followed by a short hash
+// of the value so that its changes are still visible in the audit trail.
+const redactedPrefix = "<redacted:"
+
+// configAudit is a redacted snapshot of the collector configuration.
+type configAudit struct {
+	// Config is the redacted configuration by file path.
+	Config map[string]interface{} `json:"config"`
+	// Hash is a hash of the redacted configuration.
+	Hash string `json:"hash"`
+	// Values are the flattened redacted configuration values by the file path
+	// and their path in the file, e.g. "config.yaml:exporters.sumologic.endpoint",
+	// they're used to compute the diff.
+	Values map[string]string `json:"values"`
+}
+
+// newConfigAudit reads the configuration files and creates their redacted snapshot.
+// Environment variables are expanded like in the collector configuration.
+func newConfigAudit(paths []string, redactKeys []string) (*configAudit, error) {
+	cfg := make(map[string]interface{}, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration file: %w", err)
+		}
+
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(content))), &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+		}
+		cfg[path] = redact(parsed, redactKeys)
+	}
+
+	// json.Marshal sorts map keys, so the same configuration always has the same hash.
+	marshaled, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	sum := sha256.Sum256(marshaled)
+
+	values := map[string]string{}
+	for path, redacted := range cfg {
+		fileValues := map[string]string{}
+		flatten("", redacted, fileValues)
+		for key, value := range fileValues {
+			values[path+":"+key] = value
+		}
+	}
+
+	return &configAudit{
+		Config: cfg,
+		Hash:   hex.EncodeToString(sum[:]),
+		Values: values,
+	}, nil
+}
+
+// redact replaces values of keys containing any of the redact keys.
+func redact(value interface{}, redactKeys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if isRedacted(key, redactKeys) && value != nil {
+				ret[key] = redactValue(value)
+			} else {
+				ret[key] = redact(value, redactKeys)
+			}
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, value := range v {
+			ret[i] = redact(value, redactKeys)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+func isRedacted(key string, redactKeys []string) bool {
+	key = strings.ToLower(key)
+	for _, redactKey := range redactKeys {
+		if strings.Contains(key, strings.ToLower(redactKey)) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactValue(value interface{}) string {
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		marshaled = []byte(fmt.Sprint(value))
+	}
+	sum := sha256.Sum256(marshaled)
+	return redactedPrefix + hex.EncodeToString(sum[:4]) + ">"
+}
+
+// flatten stores scalar values of the configuration by their dotted path.
+// List items are identified by their index.
+func flatten(prefix string, value interface{}, out map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = "{}"
+		}
+		for key, value := range v {
+			flatten(join(key), value, out)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			out[prefix] = "[]"
+		}
+		for i, value := range v {
+			flatten(join(fmt.Sprint(i)), value, out)
+		}
+	case nil:
+		out[prefix] = "null"
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+// diff returns the sorted paths of values which were added (prefixed with +),
+// removed (prefixed with -) or changed (prefixed with ~) between the audits.
+func diff(previous, current *configAudit) []string {
+	changes := []string{}
+	for path, value := range current.Values {
+		previousValue, ok := previous.Values[path]
+		switch {
+		case !ok:
+			changes = append(changes, "+"+path)
+		case previousValue != value:
+			changes = append(changes, "~"+path)
+		}
+	}
+	for path := range previous.Values {
+		if _, ok := current.Values[path]; !ok {
+			changes = append(changes, "-"+path)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i][1:] < changes[j][1:]
+	})
+	return changes
+}
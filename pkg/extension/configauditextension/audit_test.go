@@ -0,0 +1,152 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestNewConfigAudit(t *testing.T) {
+	t.Setenv("AUDIT_TEST_INSTALL_TOKEN", "very-secret-token")
+	path := filepath.Join("testdata", "collector.yaml")
+
+	audit, err := newConfigAudit([]string{path}, defaultRedactKeys)
+	require.NoError(t, err)
+
+	marshaled, err := json.Marshal(audit.Config)
+	require.NoError(t, err)
+	assert.NotContains(t, string(marshaled), "very-secret-token")
+	assert.NotContains(t, string(marshaled), "abc")
+
+	token := audit.Values[path+":extensions.sumologic.install_token"]
+	assert.True(t, strings.HasPrefix(token, redactedPrefix), token)
+	assert.True(t, strings.HasPrefix(audit.Values[path+":exporters.sumologic.headers.X-Api-Key"], redactedPrefix))
+	assert.Equal(t, "my-collector", audit.Values[path+":extensions.sumologic.collector_name"])
+	assert.Equal(t, "/var/log/syslog", audit.Values[path+":receivers.filelog.include.0"])
+	assert.Len(t, audit.Hash, 64)
+
+	// the same configuration has the same hash
+	again, err := newConfigAudit([]string{path}, defaultRedactKeys)
+	require.NoError(t, err)
+	assert.Equal(t, audit.Hash, again.Hash)
+
+	// a change of a redacted value changes the hash
+	t.Setenv("AUDIT_TEST_INSTALL_TOKEN", "other-token")
+	changed, err := newConfigAudit([]string{path}, defaultRedactKeys)
+	require.NoError(t, err)
+	assert.NotEqual(t, audit.Hash, changed.Hash)
+	assert.NotEqual(t, token, changed.Values[path+":extensions.sumologic.install_token"])
+}
+
+func TestNewConfigAuditErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := newConfigAudit([]string{filepath.Join(dir, "missing.yaml")}, defaultRedactKeys)
+	assert.Error(t, err)
+
+	invalid := writeConfig(t, dir, "invalid.yaml", "receivers: [")
+	_, err = newConfigAudit([]string{invalid}, defaultRedactKeys)
+	assert.Error(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	value := map[string]interface{}{
+		"Password": "secret",
+		"nested": map[string]interface{}{
+			"client_secret": map[string]interface{}{"value": "secret"},
+			"endpoint":      "http://localhost",
+		},
+		"list":  []interface{}{map[string]interface{}{"token": "secret"}, "plain"},
+		"token": nil,
+	}
+
+	redacted := redact(value, []string{"password", "secret", "token"}).(map[string]interface{})
+	assert.Equal(t, redactValue("secret"), redacted["Password"])
+	nested := redacted["nested"].(map[string]interface{})
+	assert.Equal(t, redactValue(map[string]interface{}{"value": "secret"}), nested["client_secret"])
+	assert.Equal(t, "http://localhost", nested["endpoint"])
+	list := redacted["list"].([]interface{})
+	assert.Equal(t, redactValue("secret"), list[0].(map[string]interface{})["token"])
+	assert.Equal(t, "plain", list[1])
+	// unset values are not redacted
+	assert.Nil(t, redacted["token"])
+
+	// the original value is not modified
+	assert.Equal(t, "secret", value["Password"])
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config.yaml", `
+receivers:
+  filelog:
+    include: [/var/log/a.log, /var/log/b.log]
+exporters:
+  sumologic:
+    endpoint: http://a
+`)
+	previous, err := newConfigAudit([]string{path}, defaultRedactKeys)
+	require.NoError(t, err)
+
+	writeConfig(t, dir, "config.yaml", `
+receivers:
+  filelog:
+    include: [/var/log/a.log]
+exporters:
+  sumologic:
+    endpoint: http://b
+    compress_encoding: gzip
+`)
+	current, err := newConfigAudit([]string{path}, defaultRedactKeys)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"+" + path + ":exporters.sumologic.compress_encoding",
+		"~" + path + ":exporters.sumologic.endpoint",
+		"-" + path + ":receivers.filelog.include.1",
+	}, diff(previous, current))
+	assert.Empty(t, diff(current, current))
+}
+
+func TestFlatten(t *testing.T) {
+	values := map[string]string{}
+	flatten("", map[string]interface{}{
+		"a":     map[string]interface{}{"b": 1, "c": true},
+		"empty": map[string]interface{}{},
+		"list":  []interface{}{},
+		"null":  nil,
+	}, values)
+
+	assert.Equal(t, map[string]string{
+		"a.b":   "1",
+		"a.c":   "true",
+		"empty": "{}",
+		"list":  "[]",
+		"null":  "null",
+	}, values)
+}
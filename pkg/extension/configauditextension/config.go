@@ -0,0 +1,74 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the config audit extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// ConfigFiles are the paths of the audited configuration files.
+	// By default the files passed to the collector with --config are audited.
+	ConfigFiles []string `mapstructure:"config_files"`
+	// RedactKeys are the parts of configuration keys whose values are redacted
+	// in addition to the default ones, matched case insensitively.
+	RedactKeys []string `mapstructure:"redact_keys"`
+	// LogsExporter is the logs exporter the audit log record is sent to.
+	// The record is not sent when it is not set.
+	LogsExporter *config.ComponentID `mapstructure:"logs_exporter"`
+	// HashField is the name of the resource attribute of the audit log record
+	// set to the configuration hash, it is sent as a field by the Sumo Logic exporter.
+	HashField string `mapstructure:"hash_field"`
+}
+
+const defaultHashField = "config_hash"
+
+// defaultRedactKeys are always redacted, the list covers the common names of secrets.
+var defaultRedactKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"api-key",
+	"apikey",
+	"access_key",
+	"private_key",
+	"credentials",
+	"authorization",
+}
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	for _, key := range cfg.RedactKeys {
+		if key == "" {
+			return errors.New("redact_keys cannot contain empty keys")
+		}
+	}
+	for _, path := range cfg.ConfigFiles {
+		if path == "" {
+			return errors.New("config_files cannot contain empty paths")
+		}
+	}
+	if cfg.LogsExporter != nil && cfg.HashField == "" {
+		return errors.New("hash_field cannot be empty")
+	}
+	return nil
+}
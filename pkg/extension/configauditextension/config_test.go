@@ -0,0 +1,76 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t, e0, factory.CreateDefaultConfig())
+
+	exporterID := config.NewComponentID("nop")
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, e1,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			ConfigFiles:       []string{"/etc/otelcol-sumo/sumologic.yaml", "/etc/otelcol-sumo/conf.d/common.yaml"},
+			RedactKeys:        []string{"password", "install_token"},
+			LogsExporter:      &exporterID,
+			HashField:         "collector_config_hash",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "empty redact key", modify: func(cfg *Config) { cfg.RedactKeys = append(cfg.RedactKeys, "") }},
+		{name: "empty config file", modify: func(cfg *Config) { cfg.ConfigFiles = []string{""} }},
+		{name: "empty hash field", modify: func(cfg *Config) {
+			exporterID := config.NewComponentID("nop")
+			cfg.LogsExporter = &exporterID
+			cfg.HashField = ""
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, cfg.Validate())
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	// lastAuditStorageKey is the storage key of the audit of the previously run configuration.
+	lastAuditStorageKey = "lastConfigAudit"
+
+	previousHashAttribute = "config.previous_hash"
+	changesAttribute      = "config.changes"
+)
+
+type configAuditExtension struct {
+	cfg    *Config
+	id     config.ComponentID
+	logger *zap.Logger
+
+	host    component.Host
+	storage storage.Client
+	audit   *configAudit
+	// previous is the audit of the previously run configuration, nil if it's unknown.
+	previous *configAudit
+	changes  []string
+
+	// args are the collector command line arguments, they're a field to make the extension testable.
+	args []string
+	now  func() time.Time
+}
+
+var _ component.PipelineWatcher = (*configAuditExtension)(nil)
+
+func newConfigAuditExtension(cfg *Config, logger *zap.Logger) *configAuditExtension {
+	return &configAuditExtension{
+		cfg:    cfg,
+		id:     cfg.ID(),
+		logger: logger,
+		args:   os.Args[1:],
+		now:    time.Now,
+	}
+}
+
+// Start audits the configuration. The collector reloads the configuration by restarting
+// the service, so it's audited on every reload as well.
+func (e *configAuditExtension) Start(ctx context.Context, host component.Host) error {
+	e.host = host
+
+	paths := e.cfg.ConfigFiles
+	if len(paths) == 0 {
+		paths = configFilesFromArgs(e.args)
+	}
+	if len(paths) == 0 {
+		e.logger.Warn("No configuration files to audit, the configuration is not loaded from files")
+		return nil
+	}
+
+	audit, err := newConfigAudit(paths, e.redactKeys())
+	if err != nil {
+		return err
+	}
+	e.audit = audit
+
+	e.storage, err = e.getStorage(ctx, host)
+	if err != nil {
+		return err
+	}
+	if err := e.loadPreviousAudit(ctx); err != nil {
+		e.logger.Warn("Failed to load the audit of the previous configuration", zap.Error(err))
+	}
+	if e.previous != nil {
+		e.changes = diff(e.previous, e.audit)
+	}
+	if err := e.saveAudit(ctx); err != nil {
+		e.logger.Warn("Failed to save the configuration audit", zap.Error(err))
+	}
+
+	fields := []zap.Field{
+		zap.String("hash", e.audit.Hash),
+		zap.Strings("files", paths),
+	}
+	if e.previous != nil {
+		fields = append(fields,
+			zap.String("previous_hash", e.previous.Hash),
+			zap.Strings("changes", e.changes),
+		)
+	}
+	e.logger.Info("Collector configuration audited", fields...)
+	return nil
+}
+
+// Shutdown is invoked during service shutdown.
+func (e *configAuditExtension) Shutdown(ctx context.Context) error {
+	if e.storage != nil {
+		return e.storage.Close(ctx)
+	}
+	return nil
+}
+
+// Ready sends the audit log record once the pipelines, including the logs exporter, are started.
+func (e *configAuditExtension) Ready() error {
+	if e.audit == nil || e.cfg.LogsExporter == nil {
+		return nil
+	}
+
+	exporter, ok := e.host.GetExporters()[config.LogsDataType][*e.cfg.LogsExporter]
+	if !ok {
+		e.logger.Error("Logs exporter not found, it has to be used in a logs pipeline",
+			zap.Stringer("exporter", e.cfg.LogsExporter))
+		return nil
+	}
+	logsConsumer, ok := exporter.(consumer.Logs)
+	if !ok {
+		e.logger.Error("Exporter is not a logs exporter", zap.Stringer("exporter", e.cfg.LogsExporter))
+		return nil
+	}
+
+	logs, err := e.auditLogs()
+	if err != nil {
+		e.logger.Error("Failed to create the configuration audit log record", zap.Error(err))
+		return nil
+	}
+	if err := logsConsumer.ConsumeLogs(context.Background(), logs); err != nil {
+		e.logger.Error("Failed to send the configuration audit log record", zap.Error(err))
+	}
+	return nil
+}
+
+// NotReady is a no-op, the audit doesn't depend on the pipelines state.
+func (e *configAuditExtension) NotReady() error {
+	return nil
+}
+
+// auditLogs creates a log record with the redacted configuration as the body.
+func (e *configAuditExtension) auditLogs() (plog.Logs, error) {
+	body, err := json.Marshal(e.audit.Config)
+	if err != nil {
+		return plog.Logs{}, err
+	}
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().UpsertString(e.cfg.HashField, e.audit.Hash)
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(typeStr)
+
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(e.now()))
+	lr.SetSeverityNumber(plog.SeverityNumberINFO)
+	lr.SetSeverityText("INFO")
+	lr.Body().SetStringVal(string(body))
+
+	if e.previous != nil {
+		lr.Attributes().UpsertString(previousHashAttribute, e.previous.Hash)
+		changes := pcommon.NewValueSlice()
+		for _, change := range e.changes {
+			changes.SliceVal().AppendEmpty().SetStringVal(change)
+		}
+		lr.Attributes().Upsert(changesAttribute, changes)
+	}
+	return logs, nil
+}
+
+func (e *configAuditExtension) redactKeys() []string {
+	keys := make([]string, 0, len(defaultRedactKeys)+len(e.cfg.RedactKeys))
+	keys = append(keys, defaultRedactKeys...)
+	return append(keys, e.cfg.RedactKeys...)
+}
+
+func (e *configAuditExtension) getStorage(ctx context.Context, host component.Host) (storage.Client, error) {
+	var storageExtension storage.Extension
+	var storageExtensionID config.ComponentID
+	for extensionID, extension := range host.GetExtensions() {
+		if se, ok := extension.(storage.Extension); ok {
+			if storageExtension != nil {
+				return nil, fmt.Errorf("multiple storage extensions found: '%s', '%s'", storageExtensionID, extensionID)
+			}
+			storageExtension = se
+			storageExtensionID = extensionID
+		}
+	}
+
+	if storageExtension == nil {
+		e.logger.Debug("Storage not initialized: no storage extension found, configuration changes are not tracked")
+		return nil, nil
+	}
+
+	client, err := storageExtension.GetClient(ctx, component.KindExtension, e.id, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage client for extension '%s': %w", storageExtensionID, err)
+	}
+	return client, nil
+}
+
+func (e *configAuditExtension) loadPreviousAudit(ctx context.Context) error {
+	if e.storage == nil {
+		return nil
+	}
+
+	data, err := e.storage.Get(ctx, lastAuditStorageKey)
+	if err != nil || data == nil {
+		return err
+	}
+
+	var previous configAudit
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return err
+	}
+	e.previous = &previous
+	return nil
+}
+
+func (e *configAuditExtension) saveAudit(ctx context.Context) error {
+	if e.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(e.audit)
+	if err != nil {
+		return err
+	}
+	return e.storage.Set(ctx, lastAuditStorageKey, data)
+}
+
+// configFilesFromArgs returns the paths of configuration files passed with --config.
+// Configuration coming from other sources, e.g. env:VAR, is not audited.
+func configFilesFromArgs(args []string) []string {
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		if len(arg) == len(args[i]) {
+			continue
+		}
+
+		var value string
+		switch {
+		case strings.HasPrefix(arg, "config="):
+			value = strings.TrimPrefix(arg, "config=")
+		case arg == "config" && i+1 < len(args):
+			i++
+			value = args[i]
+		default:
+			continue
+		}
+
+		if path, ok := configFilePath(value); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func configFilePath(value string) (string, bool) {
+	scheme, path, found := strings.Cut(value, ":")
+	// single letter schemes are Windows drive letters
+	if !found || len(scheme) == 1 || strings.IndexFunc(scheme, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) >= 0 {
+		return value, true
+	}
+	if scheme == "file" {
+		return path, true
+	}
+	return "", false
+}
@@ -0,0 +1,253 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configauditextension
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+var testExporterID = config.NewComponentID("sink")
+
+// memoryStorage is a storage extension keeping the data in memory.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return s, nil
+}
+
+func (s *memoryStorage) Get(_ context.Context, key string) ([]byte, error) {
+	return s.data[key], nil
+}
+
+func (s *memoryStorage) Set(_ context.Context, key string, value []byte) error {
+	s.data[key] = value
+	return nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string) error {
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStorage) Batch(context.Context, ...storage.Operation) error {
+	panic("not implemented")
+}
+
+func (s *memoryStorage) Close(context.Context) error {
+	return nil
+}
+
+// sinkExporter is a logs exporter storing received logs.
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.LogsSink
+}
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+	exporters  map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h *testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func (h *testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func newTestHost(storage *memoryStorage, sink *consumertest.LogsSink) component.Host {
+	return &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID("file_storage"): storage,
+		},
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.LogsDataType: {testExporterID: &sinkExporter{LogsSink: sink}},
+		},
+	}
+}
+
+func newTestExtension(t *testing.T, modify func(*Config)) *configAuditExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogsExporter = &testExporterID
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	ext := newConfigAuditExtension(cfg, zap.NewNop())
+	ext.now = func() time.Time { return testNow }
+	return ext
+}
+
+func TestAuditOnStartAndReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config.yaml", "exporters:\n  sumologic:\n    endpoint: http://a\n")
+	storage := &memoryStorage{data: map[string][]byte{}}
+	sink := new(consumertest.LogsSink)
+
+	// first start, there's no previous configuration
+	ext := newTestExtension(t, func(cfg *Config) { cfg.ConfigFiles = []string{path} })
+	require.NoError(t, ext.Start(context.Background(), newTestHost(storage, sink)))
+	require.NoError(t, ext.Ready())
+	require.NoError(t, ext.NotReady())
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	require.Len(t, sink.AllLogs(), 1)
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	hash, ok := rl.Resource().Attributes().Get(defaultHashField)
+	require.True(t, ok)
+	assert.Equal(t, ext.audit.Hash, hash.StringVal())
+
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	assert.JSONEq(t, `{"`+path+`": {"exporters": {"sumologic": {"endpoint": "http://a"}}}}`, lr.Body().StringVal())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testNow), lr.ObservedTimestamp())
+	assert.Equal(t, 0, lr.Attributes().Len())
+
+	// the configuration is changed and reloaded
+	writeConfig(t, dir, "config.yaml", "exporters:\n  sumologic:\n    endpoint: http://b\n")
+	ext = newTestExtension(t, func(cfg *Config) { cfg.ConfigFiles = []string{path} })
+	require.NoError(t, ext.Start(context.Background(), newTestHost(storage, sink)))
+	require.NoError(t, ext.Ready())
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	require.Len(t, sink.AllLogs(), 2)
+	lr = sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	previousHash, ok := lr.Attributes().Get(previousHashAttribute)
+	require.True(t, ok)
+	assert.Equal(t, hash.StringVal(), previousHash.StringVal())
+	assert.NotEqual(t, hash.StringVal(), ext.audit.Hash)
+
+	changes, ok := lr.Attributes().Get(changesAttribute)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"~" + path + ":exporters.sumologic.endpoint"}, changes.SliceVal().AsRaw())
+}
+
+func TestAuditWithoutStorageAndExporter(t *testing.T) {
+	ext := newTestExtension(t, func(cfg *Config) {
+		cfg.ConfigFiles = []string{filepath.Join("testdata", "collector.yaml")}
+		cfg.LogsExporter = nil
+	})
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, ext.Ready())
+	require.NoError(t, ext.Shutdown(context.Background()))
+	assert.NotNil(t, ext.audit)
+	assert.Nil(t, ext.previous)
+}
+
+func TestAuditCustomRedactKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config.yaml", "exporters:\n  sumologic:\n    endpoint: http://a\n    collector_name: my-collector\n")
+	ext := newTestExtension(t, func(cfg *Config) {
+		cfg.ConfigFiles = []string{path}
+		cfg.RedactKeys = []string{"endpoint"}
+	})
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, redactValue("http://a"), ext.audit.Values[path+":exporters.sumologic.endpoint"])
+	assert.Equal(t, "my-collector", ext.audit.Values[path+":exporters.sumologic.collector_name"])
+}
+
+func TestAuditConfigFilesFromArgs(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	ext := newTestExtension(t, nil)
+	ext.args = []string{"--config", filepath.Join("testdata", "collector.yaml"), "--config=env:OTELCOL_CONFIG"}
+
+	require.NoError(t, ext.Start(context.Background(), newTestHost(&memoryStorage{data: map[string][]byte{}}, sink)))
+	require.NoError(t, ext.Ready())
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	require.NotNil(t, ext.audit)
+	assert.Contains(t, ext.audit.Config, filepath.Join("testdata", "collector.yaml"))
+	assert.Equal(t, 1, sink.LogRecordCount())
+}
+
+func TestAuditNoConfigFiles(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	ext := newTestExtension(t, nil)
+	ext.args = []string{"--config=env:OTELCOL_CONFIG"}
+
+	require.NoError(t, ext.Start(context.Background(), newTestHost(&memoryStorage{data: map[string][]byte{}}, sink)))
+	require.NoError(t, ext.Ready())
+	assert.Equal(t, 0, sink.LogRecordCount())
+}
+
+func TestAuditInvalidConfigFile(t *testing.T) {
+	ext := newTestExtension(t, func(cfg *Config) {
+		cfg.ConfigFiles = []string{filepath.Join(t.TempDir(), "missing.yaml")}
+	})
+	assert.Error(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestConfigFilesFromArgs(t *testing.T) {
+	testcases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "separate value",
+			args:     []string{"--config", "/etc/otelcol/config.yaml"},
+			expected: []string{"/etc/otelcol/config.yaml"},
+		},
+		{
+			name:     "multiple files",
+			args:     []string{"--config=/etc/otelcol/a.yaml", "-config", "file:/etc/otelcol/b.yaml"},
+			expected: []string{"/etc/otelcol/a.yaml", "/etc/otelcol/b.yaml"},
+		},
+		{
+			name:     "windows path",
+			args:     []string{"--config", `C:\otelcol\config.yaml`},
+			expected: []string{`C:\otelcol\config.yaml`},
+		},
+		{
+			name: "other sources",
+			args: []string{"--config=env:CONFIG", "--config", "yaml:exporters::sumologic::endpoint: http://a"},
+		},
+		{
+			name: "other flags",
+			args: []string{"--feature-gates", "config", "--set=processors.batch.timeout=2s", "--config"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, configFilesFromArgs(tc.args))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the debug tap extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// Endpoint is the address the tap API listens on.
+	Endpoint string `mapstructure:"endpoint"`
+	// MaxRecords is the maximum number of records a single tap can stream.
+	MaxRecords int `mapstructure:"max_records"`
+	// MaxRate is the maximum number of records per second a single tap can stream.
+	MaxRate float64 `mapstructure:"max_rate"`
+	// DefaultTTL is the time after which a tap expires, when the request doesn't set it.
+	DefaultTTL time.Duration `mapstructure:"default_ttl"`
+	// MaxTTL is the maximum time a tap can be open for.
+	MaxTTL time.Duration `mapstructure:"max_ttl"`
+	// RedactKeys are the parts of attribute keys whose values are redacted
+	// in addition to the default ones, matched case insensitively.
+	RedactKeys []string `mapstructure:"redact_keys"`
+}
+
+const (
+	defaultEndpoint   = "localhost:55690"
+	defaultMaxRecords = 100
+	defaultMaxRate    = 10
+	defaultTTL        = time.Minute
+	defaultMaxTTL     = 10 * time.Minute
+)
+
+// defaultRedactKeys are always redacted, the list covers the common names of secrets.
+var defaultRedactKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"api-key",
+	"apikey",
+	"access_key",
+	"private_key",
+	"credentials",
+	"authorization",
+	"cookie",
+}
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if _, _, err := net.SplitHostPort(cfg.Endpoint); err != nil {
+		return errors.New("endpoint must be in the host:port format")
+	}
+	if cfg.MaxRecords <= 0 {
+		return errors.New("max_records must be positive")
+	}
+	if cfg.MaxRate <= 0 {
+		return errors.New("max_rate must be positive")
+	}
+	if cfg.DefaultTTL <= 0 || cfg.MaxTTL <= 0 {
+		return errors.New("default_ttl and max_ttl must be positive")
+	}
+	if cfg.DefaultTTL > cfg.MaxTTL {
+		return errors.New("default_ttl cannot be greater than max_ttl")
+	}
+	for _, key := range cfg.RedactKeys {
+		if key == "" {
+			return errors.New("redact_keys cannot contain empty keys")
+		}
+	}
+	return nil
+}
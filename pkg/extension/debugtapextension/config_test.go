@@ -0,0 +1,77 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t, e0, factory.CreateDefaultConfig())
+
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, e1,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			Endpoint:          "0.0.0.0:55691",
+			MaxRecords:        20,
+			MaxRate:           1.5,
+			DefaultTTL:        30 * time.Second,
+			MaxTTL:            5 * time.Minute,
+			RedactKeys:        []string{"ssn", "card_number"},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "endpoint without port", modify: func(cfg *Config) { cfg.Endpoint = "localhost" }},
+		{name: "zero max records", modify: func(cfg *Config) { cfg.MaxRecords = 0 }},
+		{name: "zero max rate", modify: func(cfg *Config) { cfg.MaxRate = 0 }},
+		{name: "zero default ttl", modify: func(cfg *Config) { cfg.DefaultTTL = 0 }},
+		{name: "default ttl above max", modify: func(cfg *Config) { cfg.DefaultTTL = cfg.MaxTTL + time.Second }},
+		{name: "empty redact key", modify: func(cfg *Config) { cfg.RedactKeys = append(cfg.RedactKeys, "") }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, cfg.Validate())
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+const tapsPath = "/taps/"
+
+// DebugTap is implemented by the debug tap extension.
+// Components look it up in the host's extensions and publish the data passing through them
+// to their tap point, which can then be tapped through the extension's API.
+type DebugTap interface {
+	component.Extension
+
+	// TapPoint returns the tap point with the given name, creating it if it doesn't exist.
+	TapPoint(name string) TapPoint
+}
+
+type debugTapExtension struct {
+	cfg        *Config
+	logger     *zap.Logger
+	redactKeys []string
+
+	mutex     sync.Mutex
+	tapPoints map[string]*tapPoint
+
+	server *http.Server
+	// done is closed on shutdown to end the open taps
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ DebugTap = (*debugTapExtension)(nil)
+
+func newDebugTapExtension(cfg *Config, logger *zap.Logger) *debugTapExtension {
+	return &debugTapExtension{
+		cfg:        cfg,
+		logger:     logger,
+		redactKeys: append(append([]string{}, defaultRedactKeys...), cfg.RedactKeys...),
+		tapPoints:  map[string]*tapPoint{},
+		done:       make(chan struct{}),
+	}
+}
+
+func (e *debugTapExtension) Start(_ context.Context, host component.Host) error {
+	listener, err := net.Listen("tcp", e.cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", e.cfg.Endpoint, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/taps", e.handleList)
+	mux.HandleFunc(tapsPath, e.handleTap)
+	e.server = &http.Server{Handler: mux}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			host.ReportFatalError(err)
+		}
+	}()
+	e.logger.Info("Debug tap API started", zap.String("endpoint", listener.Addr().String()))
+	return nil
+}
+
+func (e *debugTapExtension) Shutdown(ctx context.Context) error {
+	if e.server == nil {
+		return nil
+	}
+	close(e.done)
+	err := e.server.Shutdown(ctx)
+	e.wg.Wait()
+	return err
+}
+
+func (e *debugTapExtension) TapPoint(name string) TapPoint {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	tp, ok := e.tapPoints[name]
+	if !ok {
+		tp = newTapPoint(name, e.redactKeys)
+		e.tapPoints[name] = tp
+	}
+	return tp
+}
+
+func (e *debugTapExtension) getTapPoint(name string) (*tapPoint, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	tp, ok := e.tapPoints[name]
+	return tp, ok
+}
+
+type tapPointStatus struct {
+	Name string `json:"name"`
+	Taps int    `json:"taps"`
+}
+
+// handleList lists the tap points with the number of their open taps.
+func (e *debugTapExtension) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	e.mutex.Lock()
+	statuses := make([]tapPointStatus, 0, len(e.tapPoints))
+	for name, tp := range e.tapPoints {
+		statuses = append(statuses, tapPointStatus{Name: name, Taps: int(atomic.LoadInt32(&tp.sessionCount))})
+	}
+	e.mutex.Unlock()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		e.logger.Debug("Failed to write tap points", zap.Error(err))
+	}
+}
+
+// handleTap streams sampled records of the tap point as newline delimited JSON
+// until the limit of records is reached, the tap expires or the client disconnects.
+func (e *debugTapExtension) handleTap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, tapsPath)
+	tp, ok := e.getTapPoint(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("tap point %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	limit, rate, ttl, err := e.parseTapParameters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s := tp.subscribe(limit, rate)
+	defer tp.unsubscribe(s)
+	e.logger.Info("Tap opened",
+		zap.String("tap_point", name), zap.Int("limit", limit), zap.Float64("rate", rate), zap.Duration("ttl", ttl))
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	expired := time.NewTimer(ttl)
+	defer expired.Stop()
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case rec, ok := <-s.records:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(rec); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-expired.C:
+			return
+		case <-r.Context().Done():
+			return
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// parseTapParameters reads the limit, rate and ttl query parameters, capping them at the configured maximums.
+func (e *debugTapExtension) parseTapParameters(r *http.Request) (int, float64, time.Duration, error) {
+	query := r.URL.Query()
+
+	limit := e.cfg.MaxRecords
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid limit %q", value)
+		}
+		if parsed < limit {
+			limit = parsed
+		}
+	}
+
+	rate := e.cfg.MaxRate
+	if value := query.Get("rate"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid rate %q", value)
+		}
+		if parsed < rate {
+			rate = parsed
+		}
+	}
+
+	ttl := e.cfg.DefaultTTL
+	if value := query.Get("ttl"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid ttl %q", value)
+		}
+		ttl = parsed
+	}
+	if ttl > e.cfg.MaxTTL {
+		ttl = e.cfg.MaxTTL
+	}
+
+	return limit, rate, ttl, nil
+}
+
+// GetDebugTap returns the debug tap extension with the given id from the host.
+func GetDebugTap(host component.Host, id config.ComponentID) (DebugTap, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	tap, ok := ext.(DebugTap)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a debug tap", id)
+	}
+	return tap, nil
+}
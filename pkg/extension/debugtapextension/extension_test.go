@@ -0,0 +1,216 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+func newTestServer(t *testing.T, cfg *Config) (*debugTapExtension, *httptest.Server) {
+	e := newDebugTapExtension(cfg, zap.NewNop())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/taps", e.handleList)
+	mux.HandleFunc(tapsPath, e.handleTap)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return e, server
+}
+
+func TestTapStreamsRecords(t *testing.T) {
+	e, server := newTestServer(t, createDefaultConfig().(*Config))
+	tp := e.TapPoint("sumologic/logs")
+
+	resp, err := http.Get(server.URL + "/taps/sumologic/logs?limit=2&rate=1000")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				tp.PublishLogs(testLogs())
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var records []map[string]interface{}
+	for scanner.Scan() {
+		var r map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "user logged in", records[0]["body"])
+	assert.Equal(t, map[string]interface{}{"user": "jane", "Session-Token": "<redacted>"}, records[0]["attributes"])
+}
+
+func TestTapExpires(t *testing.T) {
+	e, server := newTestServer(t, createDefaultConfig().(*Config))
+	tp := e.TapPoint("sumologic/logs").(*tapPoint)
+
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/taps/sumologic/logs?ttl=50ms")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Eventually(t, func() bool { return !tp.active() }, time.Second, 10*time.Millisecond)
+}
+
+func TestTapErrors(t *testing.T) {
+	e, server := newTestServer(t, createDefaultConfig().(*Config))
+	e.TapPoint("sumologic/logs")
+
+	testcases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "unknown tap point", path: "/taps/otlp", status: http.StatusNotFound},
+		{name: "invalid limit", path: "/taps/sumologic/logs?limit=-1", status: http.StatusBadRequest},
+		{name: "invalid rate", path: "/taps/sumologic/logs?rate=fast", status: http.StatusBadRequest},
+		{name: "invalid ttl", path: "/taps/sumologic/logs?ttl=10", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tc.path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestTapParametersAreCapped(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	e := newDebugTapExtension(cfg, zap.NewNop())
+
+	limit, rate, ttl, err := e.parseTapParameters(httptest.NewRequest(http.MethodGet, "/taps/a?limit=1000&rate=1000&ttl=24h", nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.MaxRecords, limit)
+	assert.Equal(t, cfg.MaxRate, rate)
+	assert.Equal(t, cfg.MaxTTL, ttl)
+
+	limit, rate, ttl, err = e.parseTapParameters(httptest.NewRequest(http.MethodGet, "/taps/a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.MaxRecords, limit)
+	assert.Equal(t, cfg.MaxRate, rate)
+	assert.Equal(t, cfg.DefaultTTL, ttl)
+
+	limit, rate, ttl, err = e.parseTapParameters(httptest.NewRequest(http.MethodGet, "/taps/a?limit=5&rate=0.5&ttl=10s", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 5, limit)
+	assert.Equal(t, 0.5, rate)
+	assert.Equal(t, 10*time.Second, ttl)
+}
+
+func TestListTapPoints(t *testing.T) {
+	e, server := newTestServer(t, createDefaultConfig().(*Config))
+	e.TapPoint("sumologic/metrics")
+	e.TapPoint("sumologic/logs").(*tapPoint).subscribe(1, 1)
+
+	resp, err := http.Get(server.URL + "/taps")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var statuses []tapPointStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&statuses))
+	assert.Equal(t, []tapPointStatus{
+		{Name: "sumologic/logs", Taps: 1},
+		{Name: "sumologic/metrics", Taps: 0},
+	}, statuses)
+}
+
+func TestShutdownEndsTaps(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	e := newDebugTapExtension(cfg, zap.NewNop())
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	tp := e.TapPoint("sumologic/logs").(*tapPoint)
+
+	server := httptest.NewServer(http.HandlerFunc(e.handleTap))
+	defer server.Close()
+	go func() {
+		resp, err := http.Get(server.URL + "/taps/sumologic/logs")
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	require.Eventually(t, tp.active, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Eventually(t, func() bool { return !tp.active() }, time.Second, 10*time.Millisecond)
+}
+
+type otherExtension struct {
+	component.Extension
+}
+
+func TestGetDebugTap(t *testing.T) {
+	tap := newDebugTapExtension(createDefaultConfig().(*Config), zap.NewNop())
+	host := &extensionsHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID(typeStr): tap,
+			config.NewComponentID("other"): otherExtension{},
+		},
+	}
+
+	found, err := GetDebugTap(host, config.NewComponentID(typeStr))
+	require.NoError(t, err)
+	assert.Equal(t, tap, found)
+
+	_, err = GetDebugTap(host, config.NewComponentID("other"))
+	assert.Error(t, err)
+
+	_, err = GetDebugTap(host, config.NewComponentID("missing"))
+	assert.Error(t, err)
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
@@ -0,0 +1,325 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// redactedValue replaces the values of redacted attributes.
+const redactedValue = "<redacted>"
+
+// TapPoint is a place in a pipeline which can be tapped, e.g. an exporter.
+// The component owning it publishes all the data passing through it, the publish calls
+// return immediately while nobody is tapping and never block on the taps.
+type TapPoint interface {
+	PublishLogs(ld plog.Logs)
+	PublishMetrics(md pmetric.Metrics)
+	PublishTraces(td ptrace.Traces)
+}
+
+// record is a single log record, metric or span streamed to the taps.
+type record map[string]interface{}
+
+type tapPoint struct {
+	name       string
+	redactKeys []string
+	// sessionCount is the number of sessions, checked without locking on every publish
+	sessionCount int32
+
+	mutex    sync.Mutex
+	sessions map[*session]struct{}
+	now      func() time.Time
+}
+
+var _ TapPoint = (*tapPoint)(nil)
+
+// session is a single tap streaming records to a client.
+type session struct {
+	records chan record
+	// remaining is the number of records the session still accepts
+	remaining int
+	// interval is the minimum time between records, which limits the rate of the session
+	interval time.Duration
+	next     time.Time
+}
+
+func newTapPoint(name string, redactKeys []string) *tapPoint {
+	return &tapPoint{
+		name:       name,
+		redactKeys: redactKeys,
+		sessions:   map[*session]struct{}{},
+		now:        time.Now,
+	}
+}
+
+// subscribe opens a session streaming up to limit records, at most rate records per second.
+// The records channel of the session is closed once the limit is reached.
+func (t *tapPoint) subscribe(limit int, rate float64) *session {
+	s := &session{
+		records:   make(chan record, limit),
+		remaining: limit,
+		interval:  time.Duration(float64(time.Second) / rate),
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sessions[s] = struct{}{}
+	atomic.AddInt32(&t.sessionCount, 1)
+	return s
+}
+
+// unsubscribe closes the session, it's safe to call it for sessions which reached their limit.
+func (t *tapPoint) unsubscribe(s *session) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.removeSession(s)
+}
+
+// removeSession must be called with the mutex held.
+func (t *tapPoint) removeSession(s *session) {
+	if _, ok := t.sessions[s]; !ok {
+		return
+	}
+	delete(t.sessions, s)
+	atomic.AddInt32(&t.sessionCount, -1)
+	close(s.records)
+}
+
+func (t *tapPoint) active() bool {
+	return atomic.LoadInt32(&t.sessionCount) > 0
+}
+
+// publish samples a single record out of count records for the sessions which are due to get one.
+// The record is created only when it's needed, so publishing is cheap for rate limited sessions.
+func (t *tapPoint) publish(count int, getRecord func(i int) record) {
+	if count == 0 || !t.active() {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	var sampled record
+	for s := range t.sessions {
+		if now.Before(s.next) {
+			continue
+		}
+		if sampled == nil {
+			if sampled = getRecord(rand.Intn(count)); sampled == nil {
+				return
+			}
+			redactRecord(sampled, t.redactKeys)
+		}
+		// the channel has room for all the remaining records, so this never blocks
+		s.records <- sampled
+		s.remaining--
+		s.next = now.Add(s.interval)
+		if s.remaining == 0 {
+			t.removeSession(s)
+		}
+	}
+}
+
+func (t *tapPoint) PublishLogs(ld plog.Logs) {
+	if !t.active() {
+		return
+	}
+	t.publish(ld.LogRecordCount(), func(i int) record {
+		rls := ld.ResourceLogs()
+		for j := 0; j < rls.Len(); j++ {
+			sls := rls.At(j).ScopeLogs()
+			for k := 0; k < sls.Len(); k++ {
+				lrs := sls.At(k).LogRecords()
+				if i >= lrs.Len() {
+					i -= lrs.Len()
+					continue
+				}
+				lr := lrs.At(i)
+				return record{
+					"signal":     "logs",
+					"resource":   rls.At(j).Resource().Attributes().AsRaw(),
+					"scope":      sls.At(k).Scope().Name(),
+					"timestamp":  formatTimestamp(lr.Timestamp()),
+					"severity":   lr.SeverityText(),
+					"body":       valueAsRaw(lr.Body()),
+					"attributes": lr.Attributes().AsRaw(),
+					"trace_id":   lr.TraceID().HexString(),
+					"span_id":    lr.SpanID().HexString(),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (t *tapPoint) PublishMetrics(md pmetric.Metrics) {
+	if !t.active() {
+		return
+	}
+	t.publish(md.MetricCount(), func(i int) record {
+		rms := md.ResourceMetrics()
+		for j := 0; j < rms.Len(); j++ {
+			sms := rms.At(j).ScopeMetrics()
+			for k := 0; k < sms.Len(); k++ {
+				ms := sms.At(k).Metrics()
+				if i >= ms.Len() {
+					i -= ms.Len()
+					continue
+				}
+				m := ms.At(i)
+				return record{
+					"signal":      "metrics",
+					"resource":    rms.At(j).Resource().Attributes().AsRaw(),
+					"scope":       sms.At(k).Scope().Name(),
+					"name":        m.Name(),
+					"description": m.Description(),
+					"unit":        m.Unit(),
+					"type":        m.DataType().String(),
+					"data_points": int64(dataPointCount(m)),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (t *tapPoint) PublishTraces(td ptrace.Traces) {
+	if !t.active() {
+		return
+	}
+	t.publish(td.SpanCount(), func(i int) record {
+		rss := td.ResourceSpans()
+		for j := 0; j < rss.Len(); j++ {
+			sss := rss.At(j).ScopeSpans()
+			for k := 0; k < sss.Len(); k++ {
+				spans := sss.At(k).Spans()
+				if i >= spans.Len() {
+					i -= spans.Len()
+					continue
+				}
+				span := spans.At(i)
+				return record{
+					"signal":         "traces",
+					"resource":       rss.At(j).Resource().Attributes().AsRaw(),
+					"scope":          sss.At(k).Scope().Name(),
+					"name":           span.Name(),
+					"kind":           span.Kind().String(),
+					"trace_id":       span.TraceID().HexString(),
+					"span_id":        span.SpanID().HexString(),
+					"parent_span_id": span.ParentSpanID().HexString(),
+					"start":          formatTimestamp(span.StartTimestamp()),
+					"end":            formatTimestamp(span.EndTimestamp()),
+					"status":         span.Status().Code().String(),
+					"attributes":     span.Attributes().AsRaw(),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func dataPointCount(m pmetric.Metric) int {
+	switch m.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		return m.Gauge().DataPoints().Len()
+	case pmetric.MetricDataTypeSum:
+		return m.Sum().DataPoints().Len()
+	case pmetric.MetricDataTypeHistogram:
+		return m.Histogram().DataPoints().Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		return m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricDataTypeSummary:
+		return m.Summary().DataPoints().Len()
+	default:
+		return 0
+	}
+}
+
+func formatTimestamp(ts pcommon.Timestamp) string {
+	if ts == 0 {
+		return ""
+	}
+	return ts.AsTime().UTC().Format(time.RFC3339Nano)
+}
+
+// valueAsRaw converts the value to a type which can be marshaled to JSON.
+func valueAsRaw(v pcommon.Value) interface{} {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		return v.MapVal().AsRaw()
+	case pcommon.ValueTypeSlice:
+		return v.SliceVal().AsRaw()
+	case pcommon.ValueTypeInt:
+		return v.IntVal()
+	case pcommon.ValueTypeDouble:
+		return v.DoubleVal()
+	case pcommon.ValueTypeBool:
+		return v.BoolVal()
+	case pcommon.ValueTypeEmpty:
+		return nil
+	default:
+		return v.AsString()
+	}
+}
+
+// redactRecord replaces the values of redacted keys in the attributes and map bodies of the record.
+func redactRecord(r record, redactKeys []string) {
+	for _, key := range []string{"resource", "attributes", "body"} {
+		r[key] = redact(r[key], redactKeys)
+	}
+}
+
+// redact replaces values of keys containing any of the redact keys.
+func redact(value interface{}, redactKeys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isRedacted(key, redactKeys) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redact(value, redactKeys)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redact(value, redactKeys)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isRedacted(key string, redactKeys []string) bool {
+	key = strings.ToLower(key)
+	for _, redactKey := range redactKeys {
+		if strings.Contains(key, strings.ToLower(redactKey)) {
+			return true
+		}
+	}
+	return false
+}
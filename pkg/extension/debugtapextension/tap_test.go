@@ -0,0 +1,174 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugtapextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func testLogs() plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("host.name", "node-1")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)))
+	lr.SetSeverityText("INFO")
+	lr.Body().SetStringVal("user logged in")
+	lr.Attributes().InsertString("user", "jane")
+	lr.Attributes().InsertString("Session-Token", "abcdef")
+	return logs
+}
+
+func TestTapPointIsRateLimited(t *testing.T) {
+	tp := newTapPoint("exporter", defaultRedactKeys)
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	tp.now = func() time.Time { return now }
+
+	s := tp.subscribe(3, 1)
+	tp.PublishLogs(testLogs())
+	// the next record is due only after a second
+	tp.PublishLogs(testLogs())
+	now = now.Add(time.Second)
+	tp.PublishLogs(testLogs())
+	now = now.Add(time.Second)
+	tp.PublishLogs(testLogs())
+
+	// the session is closed once the limit is reached
+	tp.PublishLogs(testLogs())
+	assert.False(t, tp.active())
+
+	var records []record
+	for r := range s.records {
+		records = append(records, r)
+	}
+	require.Len(t, records, 3)
+	assert.Equal(t, record{
+		"signal":     "logs",
+		"resource":   map[string]interface{}{"host.name": "node-1"},
+		"scope":      "",
+		"timestamp":  "2022-08-01T10:00:00Z",
+		"severity":   "INFO",
+		"body":       "user logged in",
+		"attributes": map[string]interface{}{"user": "jane", "Session-Token": "<redacted>"},
+		"trace_id":   "",
+		"span_id":    "",
+	}, records[0])
+
+	// unsubscribing a finished session is a no-op
+	tp.unsubscribe(s)
+}
+
+func TestTapPointSamplesAllRecords(t *testing.T) {
+	tp := newTapPoint("exporter", defaultRedactKeys)
+	now := time.Now()
+	tp.now = func() time.Time { return now }
+
+	logs := plog.NewLogs()
+	for i := 0; i < 3; i++ {
+		lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		for j := 0; j < 2; j++ {
+			lrs.AppendEmpty().Body().SetIntVal(int64(i*2 + j))
+		}
+	}
+
+	s := tp.subscribe(100, 1)
+	seen := map[interface{}]bool{}
+	for i := 0; i < 100; i++ {
+		tp.PublishLogs(logs)
+		seen[(<-s.records)["body"]] = true
+		now = now.Add(time.Second)
+	}
+	assert.Len(t, seen, 6)
+}
+
+func TestTapPointRedactsMapBody(t *testing.T) {
+	tp := newTapPoint("exporter", append(defaultRedactKeys, "ssn"))
+	s := tp.subscribe(1, 1)
+
+	logs := plog.NewLogs()
+	body := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body()
+	pcommon.NewValueMap().CopyTo(body)
+	pcommon.NewMapFromRaw(map[string]interface{}{
+		"user": map[string]interface{}{"name": "jane", "ssn": "123-45-6789"},
+		"auth": []interface{}{map[string]interface{}{"password": "hunter2"}},
+	}).CopyTo(body.MapVal())
+	tp.PublishLogs(logs)
+
+	r := <-s.records
+	assert.Equal(t, map[string]interface{}{
+		"user": map[string]interface{}{"name": "jane", "ssn": "<redacted>"},
+		"auth": []interface{}{map[string]interface{}{"password": "<redacted>"}},
+	}, r["body"])
+}
+
+func TestTapPointMetricsAndTraces(t *testing.T) {
+	tp := newTapPoint("exporter", defaultRedactKeys)
+	now := time.Now()
+	tp.now = func() time.Time { return now }
+	s := tp.subscribe(2, 1)
+
+	metrics := pmetric.NewMetrics()
+	m := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("requests")
+	m.SetUnit("1")
+	m.SetDataType(pmetric.MetricDataTypeSum)
+	m.Sum().DataPoints().AppendEmpty().SetIntVal(10)
+	tp.PublishMetrics(metrics)
+
+	now = now.Add(time.Second)
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET /users")
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetTraceID(pcommon.NewTraceID([16]byte{1}))
+	span.SetSpanID(pcommon.NewSpanID([8]byte{2}))
+	span.Attributes().InsertString("http.request.header.authorization", "Bearer abc")
+	tp.PublishTraces(traces)
+
+	metric := <-s.records
+	assert.Equal(t, "metrics", metric["signal"])
+	assert.Equal(t, "requests", metric["name"])
+	assert.Equal(t, "Sum", metric["type"])
+	assert.Equal(t, int64(1), metric["data_points"])
+
+	trace := <-s.records
+	assert.Equal(t, "traces", trace["signal"])
+	assert.Equal(t, "GET /users", trace["name"])
+	assert.Equal(t, "01000000000000000000000000000000", trace["trace_id"])
+	assert.Equal(t, "0200000000000000", trace["span_id"])
+	assert.Equal(t, map[string]interface{}{"http.request.header.authorization": "<redacted>"}, trace["attributes"])
+}
+
+func TestTapPointInactive(t *testing.T) {
+	tp := newTapPoint("exporter", defaultRedactKeys)
+	assert.False(t, tp.active())
+	// publishing without sessions is a no-op
+	tp.PublishLogs(testLogs())
+
+	s := tp.subscribe(1, 1)
+	assert.True(t, tp.active())
+	tp.unsubscribe(s)
+	assert.False(t, tp.active())
+	_, ok := <-s.records
+	assert.False(t, ok)
+}
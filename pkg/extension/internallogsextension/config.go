@@ -0,0 +1,120 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config has the configuration for the internal logs extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// LogsExporter is the logs exporter the collector logs are sent to, it has to be used in a logs pipeline.
+	LogsExporter *config.ComponentID `mapstructure:"logs_exporter"`
+	// Level is the lowest level of forwarded logs. Logs below the level of the collector logger
+	// are never forwarded, as they are not written at all.
+	Level string `mapstructure:"level"`
+	// Sampling limits the number of forwarded logs with the same message.
+	Sampling SamplingConfig `mapstructure:"sampling"`
+	// Components override the level and sampling of logs of components, by component ID, e.g. filelog/containers.
+	Components map[string]ComponentConfig `mapstructure:"components"`
+
+	// FlushInterval is the maximal time logs wait to be sent.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// MaxBatchSize is the maximal number of log records sent at once.
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+}
+
+// SamplingConfig defines how logs are sampled. In every tick, the first Initial logs with
+// the same message are forwarded, and then every Thereafter-th one. Sampling is disabled when Initial is 0.
+type SamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Tick       time.Duration `mapstructure:"tick"`
+}
+
+// ComponentConfig overrides the settings for logs of a component. Unset fields fall back to the global settings.
+type ComponentConfig struct {
+	Level    string          `mapstructure:"level"`
+	Sampling *SamplingConfig `mapstructure:"sampling"`
+}
+
+const (
+	defaultLevel              = "info"
+	defaultSamplingInitial    = 100
+	defaultSamplingThereafter = 100
+	defaultSamplingTick       = time.Second
+	defaultFlushInterval      = time.Second
+	defaultMaxBatchSize       = 1000
+)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.LogsExporter == nil {
+		return errors.New("logs_exporter has to be set")
+	}
+	if _, err := parseLevel(cfg.Level); err != nil {
+		return err
+	}
+	if err := cfg.Sampling.validate(); err != nil {
+		return err
+	}
+	for id, component := range cfg.Components {
+		if _, err := config.NewComponentIDFromString(id); err != nil {
+			return fmt.Errorf("invalid component %q: %w", id, err)
+		}
+		if component.Level != "" {
+			if _, err := parseLevel(component.Level); err != nil {
+				return fmt.Errorf("component %q: %w", id, err)
+			}
+		}
+		if component.Sampling != nil {
+			if err := component.Sampling.validate(); err != nil {
+				return fmt.Errorf("component %q: %w", id, err)
+			}
+		}
+	}
+	if cfg.FlushInterval <= 0 {
+		return errors.New("flush_interval must be positive")
+	}
+	if cfg.MaxBatchSize <= 0 {
+		return errors.New("max_batch_size must be positive")
+	}
+	return nil
+}
+
+func (cfg *SamplingConfig) validate() error {
+	if cfg.Initial < 0 || cfg.Thereafter < 0 {
+		return errors.New("sampling initial and thereafter must not be negative")
+	}
+	if cfg.Initial > 0 && cfg.Tick <= 0 {
+		return errors.New("sampling tick must be positive")
+	}
+	return nil
+}
+
+func parseLevel(text string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("invalid level %q", text)
+	}
+	return level, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	nopID := config.NewComponentID("nop")
+	expected.LogsExporter = &nopID
+	assert.Equal(t, e0, expected)
+
+	exporterID := config.NewComponentIDWithName("nop", "collector")
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, e1,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			LogsExporter:      &exporterID,
+			Level:             "warn",
+			Sampling: SamplingConfig{
+				Initial:    10,
+				Thereafter: 0,
+				Tick:       10 * time.Second,
+			},
+			Components: map[string]ComponentConfig{
+				"filelog/containers": {Level: "error"},
+				"sumologic":          {Sampling: &SamplingConfig{Initial: 0}},
+			},
+			FlushInterval: 5 * time.Second,
+			MaxBatchSize:  100,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no exporter", modify: func(cfg *Config) { cfg.LogsExporter = nil }},
+		{name: "invalid level", modify: func(cfg *Config) { cfg.Level = "verbose" }},
+		{name: "negative sampling initial", modify: func(cfg *Config) { cfg.Sampling.Initial = -1 }},
+		{name: "zero sampling tick", modify: func(cfg *Config) { cfg.Sampling.Tick = 0 }},
+		{name: "invalid component", modify: func(cfg *Config) {
+			cfg.Components = map[string]ComponentConfig{"filelog/": {Level: "warn"}}
+		}},
+		{name: "invalid component level", modify: func(cfg *Config) {
+			cfg.Components = map[string]ComponentConfig{"filelog": {Level: "loud"}}
+		}},
+		{name: "invalid component sampling", modify: func(cfg *Config) {
+			cfg.Components = map[string]ComponentConfig{"filelog": {Sampling: &SamplingConfig{Thereafter: -1}}}
+		}},
+		{name: "zero flush interval", modify: func(cfg *Config) { cfg.FlushInterval = 0 }},
+		{name: "zero max batch size", modify: func(cfg *Config) { cfg.MaxBatchSize = 0 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			exporterID := config.NewComponentID("nop")
+			cfg.LogsExporter = &exporterID
+			require.NoError(t, cfg.Validate())
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
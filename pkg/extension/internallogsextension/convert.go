@@ -0,0 +1,234 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	componentKindAttribute = "otelcol.component.kind"
+	componentIDAttribute   = "otelcol.component.id"
+	dataTypeAttribute      = "otelcol.signal"
+)
+
+// Keys of the fields of the JSON encoded collector logs, as set by the collector's encoder config
+// and its component loggers.
+const (
+	levelKey     = "level"
+	timestampKey = "ts"
+	messageKey   = "msg"
+	kindKey      = "kind"
+	nameKey      = "name"
+	dataTypeKey  = "data_type"
+)
+
+// traceIDKeys and spanIDKeys are the fields trace context is taken from, components use different names.
+var (
+	traceIDKeys = []string{"trace_id", "traceID", "traceid"}
+	spanIDKeys  = []string{"span_id", "spanID", "spanid"}
+)
+
+// logEntry is a collector log entry decoded from the sink.
+type logEntry struct {
+	level     zapcore.Level
+	timestamp time.Time
+	message   string
+	// kind and componentID are set for logs of components, e.g. receiver and filelog/containers.
+	kind        string
+	componentID string
+	dataType    string
+	// fields are the remaining fields of the entry, including caller and stacktrace.
+	fields map[string]interface{}
+}
+
+// parseEntry decodes a JSON encoded log entry. Entries in other encodings are kept whole as the message,
+// with the info level.
+func parseEntry(line []byte) logEntry {
+	line = bytes.TrimSpace(line)
+
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return logEntry{level: zapcore.InfoLevel, message: string(line)}
+	}
+
+	entry := logEntry{level: zapcore.InfoLevel, fields: fields}
+	if level, ok := takeString(fields, levelKey); ok {
+		_ = entry.level.UnmarshalText([]byte(level))
+	}
+	entry.timestamp = parseTimestamp(fields[timestampKey])
+	delete(fields, timestampKey)
+	entry.message, _ = takeString(fields, messageKey)
+	entry.kind, _ = takeString(fields, kindKey)
+	entry.componentID, _ = takeString(fields, nameKey)
+	entry.dataType, _ = takeString(fields, dataTypeKey)
+	return entry
+}
+
+func takeString(fields map[string]interface{}, key string) (string, bool) {
+	value, ok := fields[key].(string)
+	if ok {
+		delete(fields, key)
+	}
+	return value, ok
+}
+
+// parseTimestamp parses the epoch timestamp of the JSON encoder, or an ISO8601 one set by other encoder configs.
+func parseTimestamp(value interface{}) time.Time {
+	switch ts := value.(type) {
+	case json.Number:
+		seconds, err := ts.Float64()
+		if err != nil {
+			return time.Time{}
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)).UTC()
+	case string:
+		for _, layout := range []string{"2006-01-02T15:04:05.000Z0700", time.RFC3339Nano} {
+			if t, err := time.Parse(layout, ts); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// appendRecord appends the entry to the log records.
+func appendRecord(lrs plog.LogRecordSlice, entry logEntry, now time.Time) {
+	lr := lrs.AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(now))
+	if !entry.timestamp.IsZero() {
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(entry.timestamp))
+	}
+	lr.SetSeverityNumber(severityNumber(entry.level))
+	lr.SetSeverityText(entry.level.CapitalString())
+	lr.Body().SetStringVal(entry.message)
+
+	fields := make(map[string]interface{}, len(entry.fields))
+	for key, value := range entry.fields {
+		fields[key] = rawValue(value)
+	}
+	if traceID, ok := takeTraceID(fields); ok {
+		lr.SetTraceID(traceID)
+	}
+	if spanID, ok := takeSpanID(fields); ok {
+		lr.SetSpanID(spanID)
+	}
+
+	attrs := lr.Attributes()
+	pcommon.NewMapFromRaw(fields).CopyTo(attrs)
+	if entry.kind != "" {
+		attrs.UpsertString(componentKindAttribute, entry.kind)
+	}
+	if entry.componentID != "" {
+		attrs.UpsertString(componentIDAttribute, entry.componentID)
+	}
+	if entry.dataType != "" {
+		attrs.UpsertString(dataTypeAttribute, entry.dataType)
+	}
+}
+
+func severityNumber(level zapcore.Level) plog.SeverityNumber {
+	switch level {
+	case zapcore.DebugLevel:
+		return plog.SeverityNumberDEBUG
+	case zapcore.InfoLevel:
+		return plog.SeverityNumberINFO
+	case zapcore.WarnLevel:
+		return plog.SeverityNumberWARN
+	case zapcore.ErrorLevel:
+		return plog.SeverityNumberERROR
+	case zapcore.DPanicLevel:
+		return plog.SeverityNumberFATAL
+	case zapcore.PanicLevel:
+		return plog.SeverityNumberFATAL2
+	case zapcore.FatalLevel:
+		return plog.SeverityNumberFATAL3
+	default:
+		return plog.SeverityNumberUNDEFINED
+	}
+}
+
+// rawValue converts the decoded JSON value to a value accepted by pcommon.NewMapFromRaw.
+func rawValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = rawValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = rawValue(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func takeTraceID(fields map[string]interface{}) (pcommon.TraceID, bool) {
+	var id [16]byte
+	if !takeID(fields, traceIDKeys, id[:]) {
+		return pcommon.NewTraceID(id), false
+	}
+	return pcommon.NewTraceID(id), true
+}
+
+func takeSpanID(fields map[string]interface{}) (pcommon.SpanID, bool) {
+	var id [8]byte
+	if !takeID(fields, spanIDKeys, id[:]) {
+		return pcommon.NewSpanID(id), false
+	}
+	return pcommon.NewSpanID(id), true
+}
+
+// takeID decodes the first of the hex encoded IDs of the right length into id and removes it from the fields.
+// Invalid IDs are left in the fields.
+func takeID(fields map[string]interface{}, keys []string, id []byte) bool {
+	for _, key := range keys {
+		value, ok := fields[key].(string)
+		if !ok || len(value) != hex.EncodedLen(len(id)) {
+			continue
+		}
+		if _, err := hex.Decode(id, []byte(strings.ToLower(value))); err != nil {
+			continue
+		}
+		delete(fields, key)
+		return true
+	}
+	return false
+}
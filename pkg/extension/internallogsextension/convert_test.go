@@ -0,0 +1,87 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zapcore"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParseEntry(t *testing.T) {
+	entry := parseEntry([]byte(`{"level":"warn","ts":1656676800.5,"caller":"fileconsumer/file.go:62","msg":"Failed to open file",` +
+		`"kind":"receiver","name":"filelog/containers","data_type":"logs","path":"/var/log/pods/a.log","attempt":3}` + "\n"))
+
+	assert.Equal(t, zapcore.WarnLevel, entry.level)
+	assert.Equal(t, testNow.Add(500*time.Millisecond), entry.timestamp)
+	assert.Equal(t, "Failed to open file", entry.message)
+	assert.Equal(t, "receiver", entry.kind)
+	assert.Equal(t, "filelog/containers", entry.componentID)
+	assert.Equal(t, "logs", entry.dataType)
+	assert.Len(t, entry.fields, 3)
+}
+
+func TestParseEntryConsoleEncoding(t *testing.T) {
+	line := "2022-07-01T12:00:00.000Z\tinfo\tservice/collector.go:215\tEverything is ready.\n"
+	entry := parseEntry([]byte(line))
+
+	assert.Equal(t, zapcore.InfoLevel, entry.level)
+	assert.True(t, entry.timestamp.IsZero())
+	assert.Equal(t, "2022-07-01T12:00:00.000Z\tinfo\tservice/collector.go:215\tEverything is ready.", entry.message)
+}
+
+func TestParseTimestamp(t *testing.T) {
+	assert.Equal(t, testNow, parseTimestamp("2022-07-01T12:00:00.000Z"))
+	assert.Equal(t, testNow, parseTimestamp("2022-07-01T12:00:00Z"))
+	assert.True(t, parseTimestamp("yesterday").IsZero())
+	assert.True(t, parseTimestamp(nil).IsZero())
+}
+
+func TestAppendRecord(t *testing.T) {
+	entry := parseEntry([]byte(`{"level":"error","ts":1656676800,"msg":"Exporting failed","kind":"exporter","name":"otlphttp",` +
+		`"data_type":"traces","trace_id":"5B8EFFF798038103D269B633813FC60C","spanID":"eee19b7ec3c1b174","span_id":"invalid",` +
+		`"error":"connection refused","dropped_items":12,"ratio":0.5,"retry":{"enabled":true,"delays":[1,2]}}`))
+
+	lrs := plog.NewLogRecordSlice()
+	appendRecord(lrs, entry, testNow.Add(time.Second))
+	require.Equal(t, 1, lrs.Len())
+	lr := lrs.At(0)
+
+	assert.Equal(t, pcommon.NewTimestampFromTime(testNow), lr.Timestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testNow.Add(time.Second)), lr.ObservedTimestamp())
+	assert.Equal(t, plog.SeverityNumberERROR, lr.SeverityNumber())
+	assert.Equal(t, "ERROR", lr.SeverityText())
+	assert.Equal(t, "Exporting failed", lr.Body().StringVal())
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", lr.TraceID().HexString())
+	assert.Equal(t, "eee19b7ec3c1b174", lr.SpanID().HexString())
+	assert.Equal(t, map[string]interface{}{
+		componentKindAttribute: "exporter",
+		componentIDAttribute:   "otlphttp",
+		dataTypeAttribute:      "traces",
+		// invalid IDs are kept as they are
+		"span_id":       "invalid",
+		"error":         "connection refused",
+		"dropped_items": int64(12),
+		"ratio":         0.5,
+		"retry":         map[string]interface{}{"enabled": true, "delays": []interface{}{int64(1), int64(2)}},
+	}, lr.Attributes().AsRaw())
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	serviceNameAttribute    = "service.name"
+	serviceVersionAttribute = "service.version"
+)
+
+type internalLogsExtension struct {
+	id            config.ComponentID
+	exporterID    config.ComponentID
+	flushInterval time.Duration
+	maxBatchSize  int
+	buildInfo     component.BuildInfo
+	logger        *zap.Logger
+
+	sink    *logSink
+	entries <-chan []byte
+
+	// defaultFilter applies to the logs of components without overrides and to the logs of the service.
+	defaultFilter    *filter
+	componentFilters map[string]*filter
+
+	consumer   consumer.Logs
+	shutdownCh chan struct{}
+	wg         sync.WaitGroup
+	// running is set between Ready and NotReady, when the logs are forwarded.
+	running bool
+
+	now func() time.Time
+}
+
+var _ component.PipelineWatcher = (*internalLogsExtension)(nil)
+
+func newInternalLogsExtension(cfg *Config, sink *logSink, params component.ExtensionCreateSettings) (*internalLogsExtension, error) {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &internalLogsExtension{
+		id:               cfg.ID(),
+		exporterID:       *cfg.LogsExporter,
+		flushInterval:    cfg.FlushInterval,
+		maxBatchSize:     cfg.MaxBatchSize,
+		buildInfo:        params.BuildInfo,
+		logger:           params.Logger,
+		sink:             sink,
+		defaultFilter:    &filter{level: level, sampler: newSampler(cfg.Sampling)},
+		componentFilters: map[string]*filter{},
+		now:              time.Now,
+	}
+
+	for id, override := range cfg.Components {
+		f := &filter{level: level, sampler: e.defaultFilter.sampler}
+		if override.Level != "" {
+			if f.level, err = parseLevel(override.Level); err != nil {
+				return nil, err
+			}
+		}
+		if override.Sampling != nil {
+			f.sampler = newSampler(*override.Sampling)
+		}
+		e.componentFilters[id] = f
+	}
+	return e, nil
+}
+
+func (e *internalLogsExtension) Start(_ context.Context, host component.Host) error {
+	exporter, ok := host.GetExporters()[config.LogsDataType][e.exporterID]
+	if !ok {
+		return fmt.Errorf("logs exporter %q not found, it has to be used in a logs pipeline", e.exporterID)
+	}
+	logsConsumer, ok := exporter.(consumer.Logs)
+	if !ok {
+		return fmt.Errorf("exporter %q is not a logs exporter", e.exporterID)
+	}
+	e.consumer = logsConsumer
+
+	entries, err := e.sink.attach()
+	if err != nil {
+		return err
+	}
+	e.entries = entries
+	return nil
+}
+
+// Ready starts forwarding the logs, once the exporter is started. The logs written
+// before, e.g. while the collector was starting, are buffered by the sink.
+func (e *internalLogsExtension) Ready() error {
+	if e.running {
+		return nil
+	}
+	e.running = true
+	e.shutdownCh = make(chan struct{})
+	e.wg.Add(1)
+	go e.forwardLoop()
+	return nil
+}
+
+// NotReady stops forwarding the logs before the exporter is shut down.
+func (e *internalLogsExtension) NotReady() error {
+	if !e.running {
+		return nil
+	}
+	e.running = false
+	close(e.shutdownCh)
+	e.wg.Wait()
+	return nil
+}
+
+func (e *internalLogsExtension) Shutdown(_ context.Context) error {
+	if err := e.NotReady(); err != nil {
+		return err
+	}
+	if e.entries != nil {
+		e.sink.detach()
+		e.entries = nil
+	}
+	return nil
+}
+
+func (e *internalLogsExtension) forwardLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	logs := e.newLogs()
+	for {
+		select {
+		case line := <-e.entries:
+			e.append(logs, line)
+			if logs.LogRecordCount() >= e.maxBatchSize {
+				e.flush(logs)
+				logs = e.newLogs()
+			}
+		case <-ticker.C:
+			if dropped := e.sink.takeDropped(); dropped > 0 {
+				e.logger.Warn("Collector logs were dropped, as the buffer was full", zap.Int64("dropped", dropped))
+			}
+			e.flush(logs)
+			logs = e.newLogs()
+		case <-e.shutdownCh:
+			e.flush(logs)
+			return
+		}
+	}
+}
+
+func (e *internalLogsExtension) newLogs() plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	attrs := rl.Resource().Attributes()
+	if e.buildInfo.Command != "" {
+		attrs.UpsertString(serviceNameAttribute, e.buildInfo.Command)
+	}
+	if e.buildInfo.Version != "" {
+		attrs.UpsertString(serviceVersionAttribute, e.buildInfo.Version)
+	}
+	rl.ScopeLogs().AppendEmpty().Scope().SetName(typeStr)
+	return logs
+}
+
+func (e *internalLogsExtension) append(logs plog.Logs, line []byte) {
+	entry := parseEntry(line)
+	if e.isOwnLog(entry) {
+		return
+	}
+
+	now := e.now()
+	f, ok := e.componentFilters[entry.componentID]
+	if !ok {
+		f = e.defaultFilter
+	}
+	if !f.accept(entry, now) {
+		return
+	}
+	appendRecord(logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords(), entry, now)
+}
+
+// isOwnLog tells whether the entry was logged by the extension or the exporter.
+// They are not forwarded, as failures to forward the logs would be forwarded again.
+func (e *internalLogsExtension) isOwnLog(entry logEntry) bool {
+	switch entry.kind {
+	case "extension":
+		return entry.componentID == e.id.String()
+	case "exporter":
+		return entry.componentID == e.exporterID.String()
+	default:
+		return false
+	}
+}
+
+func (e *internalLogsExtension) flush(logs plog.Logs) {
+	if logs.LogRecordCount() == 0 {
+		return
+	}
+	if err := e.consumer.ConsumeLogs(context.Background(), logs); err != nil {
+		e.logger.Warn("Failed to send collector logs", zap.Error(err), zap.Int("records", logs.LogRecordCount()))
+	}
+}
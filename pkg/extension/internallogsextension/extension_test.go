@@ -0,0 +1,182 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+var testExporterID = config.NewComponentID("sink")
+
+// sinkExporter is a logs exporter storing received logs.
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.LogsSink
+}
+
+type testHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h *testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func newTestHost(sink *consumertest.LogsSink) component.Host {
+	return &testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.LogsDataType: {testExporterID: &sinkExporter{LogsSink: sink}},
+		},
+	}
+}
+
+func newTestExtension(t *testing.T, sink *logSink, modify func(*Config)) *internalLogsExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogsExporter = &testExporterID
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	params := componenttest.NewNopExtensionCreateSettings()
+	params.BuildInfo = component.BuildInfo{Command: "otelcol-sumo", Version: "v0.54.0-sumo-0"}
+	e, err := newInternalLogsExtension(cfg, sink, params)
+	require.NoError(t, err)
+	e.now = func() time.Time { return testNow }
+	return e
+}
+
+func records(logs []plog.Logs) []plog.LogRecord {
+	var lrs []plog.LogRecord
+	for _, ld := range logs {
+		records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			lrs = append(lrs, records.At(i))
+		}
+	}
+	return lrs
+}
+
+func TestForwardCollectorLogs(t *testing.T) {
+	// the logger is built with the sink registered by the package, as the collector logger is
+	zapCfg := zap.NewProductionConfig()
+	zapCfg.OutputPaths = []string{sinkScheme + ":"}
+	logger, err := zapCfg.Build()
+	require.NoError(t, err)
+
+	e := newTestExtension(t, processSink, func(cfg *Config) {
+		cfg.FlushInterval = 10 * time.Millisecond
+		cfg.Components = map[string]ComponentConfig{"filelog": {Level: "warn"}}
+	})
+	exporterSink := new(consumertest.LogsSink)
+	require.NoError(t, e.Start(context.Background(), newTestHost(exporterSink)))
+
+	// logs written before the pipelines are ready are buffered
+	logger.Info("Starting extensions...")
+	receiverLogger := logger.With(zap.String("kind", "receiver"), zap.String("name", "filelog"), zap.String("data_type", "logs"))
+	receiverLogger.Info("Started watching file")
+	receiverLogger.Warn("Failed to open file", zap.String("trace_id", "5b8efff798038103d269b633813fc60c"))
+	// logs of the extension and the exporter are not forwarded
+	logger.With(zap.String("kind", "extension"), zap.String("name", typeStr)).Warn("Failed to send collector logs")
+	logger.With(zap.String("kind", "exporter"), zap.String("name", "sink")).Warn("Exporting failed")
+
+	require.NoError(t, e.Ready())
+	assert.Eventually(t, func() bool { return exporterSink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.NotReady())
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	resource := exporterSink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().AsRaw()
+	assert.Equal(t, map[string]interface{}{serviceNameAttribute: "otelcol-sumo", serviceVersionAttribute: "v0.54.0-sumo-0"}, resource)
+
+	lrs := records(exporterSink.AllLogs())
+	require.Len(t, lrs, 2)
+	assert.Equal(t, "Starting extensions...", lrs[0].Body().StringVal())
+	assert.Equal(t, plog.SeverityNumberINFO, lrs[0].SeverityNumber())
+	assert.Equal(t, "Failed to open file", lrs[1].Body().StringVal())
+	assert.Equal(t, plog.SeverityNumberWARN, lrs[1].SeverityNumber())
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", lrs[1].TraceID().HexString())
+	componentID, ok := lrs[1].Attributes().Get(componentIDAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "filelog", componentID.StringVal())
+}
+
+func TestForwardBatches(t *testing.T) {
+	sink := newLogSink(100)
+	e := newTestExtension(t, sink, func(cfg *Config) {
+		cfg.MaxBatchSize = 3
+		cfg.FlushInterval = time.Hour
+		cfg.Sampling = SamplingConfig{Initial: 2, Thereafter: 0, Tick: time.Minute}
+	})
+	exporterSink := new(consumertest.LogsSink)
+	require.NoError(t, e.Start(context.Background(), newTestHost(exporterSink)))
+
+	for i := 0; i < 5; i++ {
+		_, _ = sink.Write([]byte(`{"level":"info","msg":"repeated"}`))
+		_, _ = sink.Write([]byte(`{"level":"info","msg":"unique ` + string(rune('a'+i)) + `"}`))
+	}
+
+	require.NoError(t, e.Ready())
+	// full batches are sent right away
+	assert.Eventually(t, func() bool { return len(exporterSink.AllLogs()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	// the rest is sent on shutdown
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	logs := exporterSink.AllLogs()
+	require.Len(t, logs, 3)
+	assert.Equal(t, 3, logs[0].LogRecordCount())
+	assert.Equal(t, 3, logs[1].LogRecordCount())
+	// the repeated message is sampled
+	assert.Equal(t, 1, logs[2].LogRecordCount())
+}
+
+func TestSinkAttachedOnce(t *testing.T) {
+	sink := newLogSink(1)
+	e1 := newTestExtension(t, sink, nil)
+	e2 := newTestExtension(t, sink, nil)
+
+	require.NoError(t, e1.Start(context.Background(), newTestHost(new(consumertest.LogsSink))))
+	assert.Error(t, e2.Start(context.Background(), newTestHost(new(consumertest.LogsSink))))
+	require.NoError(t, e1.Shutdown(context.Background()))
+
+	// the sink is released on shutdown, e.g. for the extension created after a config reload
+	require.NoError(t, e2.Start(context.Background(), newTestHost(new(consumertest.LogsSink))))
+	require.NoError(t, e2.Shutdown(context.Background()))
+}
+
+func TestSinkDropsEntriesWhenFull(t *testing.T) {
+	sink := newLogSink(2)
+	for i := 0; i < 5; i++ {
+		n, err := sink.Write([]byte("entry"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+	}
+	assert.Equal(t, int64(3), sink.takeDropped())
+	assert.Equal(t, int64(0), sink.takeDropped())
+	assert.Len(t, sink.entries, 2)
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sampler limits the number of entries with the same key, similarly to the zap sampler.
+type sampler struct {
+	cfg       SamplingConfig
+	tickStart time.Time
+	counts    map[string]int
+}
+
+func newSampler(cfg SamplingConfig) *sampler {
+	return &sampler{
+		cfg:    cfg,
+		counts: map[string]int{},
+	}
+}
+
+// sample tells whether the entry with the key is forwarded. Counts are reset every tick.
+func (s *sampler) sample(key string, now time.Time) bool {
+	if s.cfg.Initial == 0 {
+		return true
+	}
+	if now.Sub(s.tickStart) >= s.cfg.Tick {
+		s.tickStart = now
+		s.counts = map[string]int{}
+	}
+
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.cfg.Initial {
+		return true
+	}
+	if s.cfg.Thereafter == 0 {
+		return false
+	}
+	return (n-s.cfg.Initial)%s.cfg.Thereafter == 0
+}
+
+// filter decides which entries of a component are forwarded.
+type filter struct {
+	level   zapcore.Level
+	sampler *sampler
+}
+
+func (f *filter) accept(entry logEntry, now time.Time) bool {
+	if entry.level < f.level {
+		return false
+	}
+	return f.sampler.sample(entry.componentID+"\x00"+entry.message, now)
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSampler(t *testing.T) {
+	s := newSampler(SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Second})
+
+	var sampled []bool
+	for i := 0; i < 8; i++ {
+		sampled = append(sampled, s.sample("a", testNow))
+	}
+	assert.Equal(t, []bool{true, true, false, false, true, false, false, true}, sampled)
+	// keys are counted separately
+	assert.True(t, s.sample("b", testNow))
+
+	// counts are reset in the next tick
+	assert.True(t, s.sample("a", testNow.Add(time.Second)))
+}
+
+func TestSamplerThereafterZero(t *testing.T) {
+	s := newSampler(SamplingConfig{Initial: 1, Tick: time.Second})
+	assert.True(t, s.sample("a", testNow))
+	assert.False(t, s.sample("a", testNow))
+	assert.False(t, s.sample("a", testNow))
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	s := newSampler(SamplingConfig{})
+	for i := 0; i < 1000; i++ {
+		assert.True(t, s.sample("a", testNow))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := &filter{level: zapcore.WarnLevel, sampler: newSampler(SamplingConfig{})}
+	assert.False(t, f.accept(logEntry{level: zapcore.InfoLevel}, testNow))
+	assert.True(t, f.accept(logEntry{level: zapcore.WarnLevel}, testNow))
+	assert.True(t, f.accept(logEntry{level: zapcore.ErrorLevel}, testNow))
+}
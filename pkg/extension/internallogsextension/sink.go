@@ -0,0 +1,106 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internallogsextension
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// sinkScheme is the scheme of the zap sink the collector logs are written to
+	// when `internallogs:` is one of the output paths of the collector logs.
+	sinkScheme = "internallogs"
+	// sinkBufferSize is the number of log entries buffered in the sink, newer entries are dropped
+	// when the extension doesn't keep up or doesn't run.
+	sinkBufferSize = 10000
+)
+
+// processSink receives the collector logs written in this process. zap opens the sink when the collector
+// logger is built, before the extension is created, so the sink can't live in the extension.
+var processSink = newLogSink(sinkBufferSize)
+
+func init() {
+	// the collector logger is built from the telemetry config, so the sink can only be plugged in by its scheme
+	if err := zap.RegisterSink(sinkScheme, func(*url.URL) (zap.Sink, error) { return processSink, nil }); err != nil {
+		panic(err)
+	}
+}
+
+// logSink is a zap sink buffering the encoded log entries for the extension.
+type logSink struct {
+	entries chan []byte
+	dropped int64
+
+	mutex    sync.Mutex
+	attached bool
+}
+
+func newLogSink(size int) *logSink {
+	return &logSink{
+		entries: make(chan []byte, size),
+	}
+}
+
+// Write buffers a log entry, zap writes a single encoded entry at once.
+// It never blocks, so the logging components are not slowed down by the extension.
+func (s *logSink) Write(p []byte) (int, error) {
+	// zap reuses the buffer of the entry
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	select {
+	case s.entries <- entry:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+	return len(p), nil
+}
+
+func (s *logSink) Sync() error {
+	return nil
+}
+
+// Close is a no-op, the sink is shared by all loggers of the process and lives as long as the process.
+func (s *logSink) Close() error {
+	return nil
+}
+
+// attach returns the buffered entries to the extension. Only a single extension can read them at once.
+func (s *logSink) attach() (<-chan []byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.attached {
+		return nil, errors.New("the collector logs are already read by another internal_logs extension")
+	}
+	s.attached = true
+	return s.entries, nil
+}
+
+func (s *logSink) detach() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attached = false
+}
+
+// takeDropped returns the number of entries dropped since the last call.
+func (s *logSink) takeDropped() int64 {
+	return atomic.SwapInt64(&s.dropped, 0)
+}
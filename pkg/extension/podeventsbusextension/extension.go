@@ -0,0 +1,125 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podeventsbusextension
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// PodEvent is a Kubernetes event about a pod, e.g. the pod being scheduled, started or killed.
+type PodEvent struct {
+	Namespace string
+	Name      string
+	UID       string
+	// Reason is the reason of the Kubernetes event, e.g. Scheduled or Killing.
+	Reason string
+}
+
+// PodEventSubscriber is called with every pod event published to the bus.
+type PodEventSubscriber func(PodEvent)
+
+// PodEventsBus is implemented by the pod events bus extension.
+// Components which watch Kubernetes events publish pod events to it, and components
+// which cache pod metadata subscribe to them to update their caches without waiting for their own watches.
+// Both look it up in the host's extensions.
+type PodEventsBus interface {
+	component.Extension
+
+	// Publish sends the event to all subscribers.
+	Publish(event PodEvent)
+
+	// Subscribe registers the subscriber for all pod events.
+	// The returned function removes the subscription.
+	Subscribe(subscriber PodEventSubscriber) (unsubscribe func())
+}
+
+type podEventsBusExtension struct {
+	logger *zap.Logger
+
+	mutex       sync.RWMutex
+	subscribers map[int]PodEventSubscriber
+	nextID      int
+}
+
+var _ PodEventsBus = (*podEventsBusExtension)(nil)
+
+func newPodEventsBusExtension(logger *zap.Logger) *podEventsBusExtension {
+	return &podEventsBusExtension{
+		logger:      logger,
+		subscribers: map[int]PodEventSubscriber{},
+	}
+}
+
+func (e *podEventsBusExtension) Start(_ context.Context, _ component.Host) error {
+	return nil
+}
+
+func (e *podEventsBusExtension) Shutdown(_ context.Context) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.subscribers = map[int]PodEventSubscriber{}
+	return nil
+}
+
+// Publish calls the subscribers sequentially from the publisher's goroutine,
+// so they shouldn't block.
+func (e *podEventsBusExtension) Publish(event PodEvent) {
+	e.mutex.RLock()
+	subscribers := make([]PodEventSubscriber, 0, len(e.subscribers))
+	for _, subscriber := range e.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	e.mutex.RUnlock()
+
+	// subscribers are called without holding the lock, so they can (un)subscribe
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
+
+func (e *podEventsBusExtension) Subscribe(subscriber PodEventSubscriber) func() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	id := e.nextID
+	e.nextID++
+	e.subscribers[id] = subscriber
+	e.logger.Debug("Pod events subscriber added", zap.Int("subscribers", len(e.subscribers)))
+
+	return func() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		delete(e.subscribers, id)
+	}
+}
+
+// GetPodEventsBus returns the pod events bus extension with the given id from the host.
+func GetPodEventsBus(host component.Host, id config.ComponentID) (PodEventsBus, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	bus, ok := ext.(PodEventsBus)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a pod events bus", id)
+	}
+	return bus, nil
+}
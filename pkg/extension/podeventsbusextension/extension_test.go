@@ -0,0 +1,98 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podeventsbusextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+// otherExtension is an extension which isn't a pod events bus.
+type otherExtension struct {
+	component.Extension
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	bus := newPodEventsBusExtension(zap.NewNop())
+	event := PodEvent{Namespace: "default", Name: "nginx-1", UID: "1234", Reason: "Scheduled"}
+
+	// publishing without subscribers is a no-op
+	bus.Publish(event)
+
+	var first, second []PodEvent
+	unsubscribeFirst := bus.Subscribe(func(e PodEvent) { first = append(first, e) })
+	bus.Subscribe(func(e PodEvent) { second = append(second, e) })
+
+	bus.Publish(event)
+	assert.Equal(t, []PodEvent{event}, first)
+	assert.Equal(t, []PodEvent{event}, second)
+
+	unsubscribeFirst()
+	bus.Publish(event)
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 2)
+}
+
+func TestSubscriberUnsubscribesItself(t *testing.T) {
+	bus := newPodEventsBusExtension(zap.NewNop())
+
+	var calls int
+	var unsubscribe func()
+	unsubscribe = bus.Subscribe(func(PodEvent) {
+		calls++
+		unsubscribe()
+	})
+
+	bus.Publish(PodEvent{Name: "nginx-1"})
+	bus.Publish(PodEvent{Name: "nginx-1"})
+	assert.Equal(t, 1, calls)
+}
+
+func TestGetPodEventsBus(t *testing.T) {
+	id := config.NewComponentID(typeStr)
+	bus := newPodEventsBusExtension(zap.NewNop())
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			id:                         bus,
+			config.NewComponentID("x"): otherExtension{},
+		},
+	}
+
+	got, err := GetPodEventsBus(host, id)
+	require.NoError(t, err)
+	assert.Equal(t, bus, got)
+
+	_, err = GetPodEventsBus(host, config.NewComponentIDWithName(typeStr, "missing"))
+	assert.Error(t, err)
+
+	_, err = GetPodEventsBus(host, config.NewComponentID("x"))
+	assert.Error(t, err)
+}
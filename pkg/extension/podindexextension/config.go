@@ -0,0 +1,26 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podindexextension
+
+import (
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the pod events bus extension.
+// The bus doesn't have any settings, the components publishing and consuming
+// pod events refer to it by its id.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podindexextension
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// Pod is the metadata of a pod with a given IP address.
+type Pod struct {
+	Namespace string
+	Name      string
+	UID       string
+	// Address is the IP address of the pod.
+	Address string
+	// Attributes are the resource attributes the provider associates with the pod,
+	// e.g. k8s.deployment.name or k8s.node.name.
+	Attributes map[string]string
+}
+
+// PodProvider resolves IP addresses to pods, e.g. from a cache of pod metadata.
+type PodProvider interface {
+	// PodByIP returns the pod with the given IP address, if the provider knows it.
+	PodByIP(ip string) (Pod, bool)
+}
+
+// PodIndex is implemented by the pod index extension.
+// Components which cache pod metadata register as providers, and components which
+// need to resolve IP addresses to workloads look pods up in it, instead of running
+// their own Kubernetes informers. Both look it up in the host's extensions.
+type PodIndex interface {
+	component.Extension
+	PodProvider
+
+	// Register adds the provider to the index.
+	// The returned function removes the provider.
+	Register(provider PodProvider) (unregister func())
+}
+
+type podIndexExtension struct {
+	logger *zap.Logger
+
+	mutex     sync.RWMutex
+	providers map[int]PodProvider
+	nextID    int
+}
+
+var _ PodIndex = (*podIndexExtension)(nil)
+
+func newPodIndexExtension(logger *zap.Logger) *podIndexExtension {
+	return &podIndexExtension{
+		logger:    logger,
+		providers: map[int]PodProvider{},
+	}
+}
+
+func (e *podIndexExtension) Start(_ context.Context, _ component.Host) error {
+	return nil
+}
+
+func (e *podIndexExtension) Shutdown(_ context.Context) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.providers = map[int]PodProvider{}
+	return nil
+}
+
+// PodByIP asks the providers in the order of their registration and returns
+// the pod from the first one which knows the IP address.
+func (e *podIndexExtension) PodByIP(ip string) (Pod, bool) {
+	e.mutex.RLock()
+	ids := make([]int, 0, len(e.providers))
+	for id := range e.providers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	providers := make([]PodProvider, 0, len(ids))
+	for _, id := range ids {
+		providers = append(providers, e.providers[id])
+	}
+	e.mutex.RUnlock()
+
+	// providers are called without holding the lock, so they can (un)register
+	for _, provider := range providers {
+		if pod, ok := provider.PodByIP(ip); ok {
+			return pod, true
+		}
+	}
+	return Pod{}, false
+}
+
+func (e *podIndexExtension) Register(provider PodProvider) func() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	id := e.nextID
+	e.nextID++
+	e.providers[id] = provider
+	e.logger.Debug("Pod provider registered", zap.Int("providers", len(e.providers)))
+
+	return func() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		delete(e.providers, id)
+	}
+}
+
+// GetPodIndex returns the pod index extension with the given id from the host.
+func GetPodIndex(host component.Host, id config.ComponentID) (PodIndex, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	index, ok := ext.(PodIndex)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a pod index", id)
+	}
+	return index, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podindexextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+// otherExtension is an extension which isn't a pod index.
+type otherExtension struct {
+	component.Extension
+}
+
+// mapProvider is a pod provider with a fixed set of pods.
+type mapProvider map[string]Pod
+
+func (p mapProvider) PodByIP(ip string) (Pod, bool) {
+	pod, ok := p[ip]
+	return pod, ok
+}
+
+func TestPodByIP(t *testing.T) {
+	index := newPodIndexExtension(zap.NewNop())
+	nginx := Pod{Namespace: "default", Name: "nginx-1", UID: "1234", Address: "10.0.0.1",
+		Attributes: map[string]string{"k8s.deployment.name": "nginx"}}
+	redis := Pod{Namespace: "default", Name: "redis-1", UID: "5678", Address: "10.0.0.2"}
+
+	// looking up without providers doesn't find anything
+	_, ok := index.PodByIP("10.0.0.1")
+	assert.False(t, ok)
+
+	unregisterFirst := index.Register(mapProvider{"10.0.0.1": nginx})
+	index.Register(mapProvider{"10.0.0.1": redis, "10.0.0.2": redis})
+
+	// the first registered provider which knows the address wins
+	pod, ok := index.PodByIP("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, nginx, pod)
+
+	pod, ok = index.PodByIP("10.0.0.2")
+	require.True(t, ok)
+	assert.Equal(t, redis, pod)
+
+	_, ok = index.PodByIP("10.0.0.3")
+	assert.False(t, ok)
+
+	unregisterFirst()
+	pod, ok = index.PodByIP("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, redis, pod)
+}
+
+func TestGetPodIndex(t *testing.T) {
+	id := config.NewComponentID(typeStr)
+	index := newPodIndexExtension(zap.NewNop())
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			id:                         index,
+			config.NewComponentID("x"): otherExtension{},
+		},
+	}
+
+	got, err := GetPodIndex(host, id)
+	require.NoError(t, err)
+	assert.Equal(t, index, got)
+
+	_, err = GetPodIndex(host, config.NewComponentIDWithName(typeStr, "missing"))
+	assert.Error(t, err)
+
+	_, err = GetPodIndex(host, config.NewComponentID("x"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloadorchestratorextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the reload orchestrator extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// HandoffTimeout is the maximum time a new instance of a component waits
+	// for the previous instance to hand off its state.
+	HandoffTimeout time.Duration `mapstructure:"handoff_timeout"`
+	// StateTTL is the time after which a handed off state which wasn't taken over is discarded.
+	StateTTL time.Duration `mapstructure:"state_ttl"`
+}
+
+const (
+	defaultHandoffTimeout = 30 * time.Second
+	defaultStateTTL       = 5 * time.Minute
+)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.HandoffTimeout <= 0 {
+		return errors.New("handoff_timeout must be positive")
+	}
+	if cfg.StateTTL <= 0 {
+		return errors.New("state_ttl must be positive")
+	}
+	return nil
+}
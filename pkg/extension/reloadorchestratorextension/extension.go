@@ -0,0 +1,100 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloadorchestratorextension
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+type ReloadOrchestrator interface {
+	component.Extension
+
+	// Acquire returns the lease of the component instance. It waits until the previous instance
+	// of the component released its lease, at most for the configured handoff timeout,
+	// so the state of the lease is the one the previous instance ended with.
+	Acquire(ctx context.Context, kind component.Kind, id config.ComponentID) (*Lease, error)
+}
+
+type reloadOrchestratorExtension struct {
+	cfg    *Config
+	store  *handoffStore
+	logger *zap.Logger
+}
+
+var _ ReloadOrchestrator = (*reloadOrchestratorExtension)(nil)
+
+func newReloadOrchestratorExtension(cfg *Config, store *handoffStore, logger *zap.Logger) *reloadOrchestratorExtension {
+	return &reloadOrchestratorExtension{
+		cfg:    cfg,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (e *reloadOrchestratorExtension) Start(_ context.Context, _ component.Host) error {
+	return nil
+}
+
+// Shutdown doesn't drop the handed off states, they are taken over by the components created after the reload.
+func (e *reloadOrchestratorExtension) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func (e *reloadOrchestratorExtension) Acquire(ctx context.Context, kind component.Kind, id config.ComponentID) (*Lease, error) {
+	key := handoffKey{kind: kind, id: id}
+
+	if previous := e.store.current(key); previous != nil && !previous.isReleased() {
+		e.logger.Debug("Waiting for the previous instance to hand off its state", zap.Stringer("component", id))
+
+		timer := time.NewTimer(e.cfg.HandoffTimeout)
+		defer timer.Stop()
+		select {
+		case <-previous.released:
+		case <-timer.C:
+			// the previous instance is stuck or failed to shut down, starting cold beats not starting at all
+			e.logger.Warn(
+				"The previous instance didn't hand off its state in time, starting without it",
+				zap.Stringer("component", id),
+				zap.Duration("handoff_timeout", e.cfg.HandoffTimeout),
+			)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	lease := e.store.takeOver(key, e.cfg.StateTTL, time.Now())
+	if lease.State() != nil {
+		e.logger.Info("Taking over the state handed off by the previous instance", zap.Stringer("component", id))
+	}
+	return lease, nil
+}
+
+func GetReloadOrchestrator(host component.Host, id config.ComponentID) (ReloadOrchestrator, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	orchestrator, ok := ext.(ReloadOrchestrator)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a reload orchestrator", id)
+	}
+	return orchestrator, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloadorchestratorextension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h testHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+type otherExtension struct {
+	component.Extension
+}
+
+func newTestExtension(store *handoffStore) *reloadOrchestratorExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HandoffTimeout = 100 * time.Millisecond
+	return newReloadOrchestratorExtension(cfg, store, zap.NewNop())
+}
+
+func TestHandoffAcrossReload(t *testing.T) {
+	store := newHandoffStore()
+	id := config.NewComponentIDWithName("postgresql_cdc", "orders")
+
+	// first start of the collector
+	before := newTestExtension(store)
+	lease, err := before.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+	assert.Nil(t, lease.State())
+
+	// the reload shuts down the receiver and the extension and creates them again
+	lease.Release([]byte("0/16B3748"))
+	require.NoError(t, before.Shutdown(context.Background()))
+
+	after := newTestExtension(store)
+	lease, err = after.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("0/16B3748"), lease.State())
+
+	// other components and kinds don't share the state
+	other, err := after.Acquire(context.Background(), component.KindExporter, id)
+	require.NoError(t, err)
+	assert.Nil(t, other.State())
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	store := newHandoffStore()
+	ext := newTestExtension(store)
+	ext.cfg.HandoffTimeout = 10 * time.Second
+	id := config.NewComponentID("raw_k8s_events")
+
+	previous, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		previous.Release([]byte("1234"))
+	}()
+
+	lease, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1234"), lease.State())
+}
+
+func TestAcquireTimesOut(t *testing.T) {
+	store := newHandoffStore()
+	ext := newTestExtension(store)
+	id := config.NewComponentID("raw_k8s_events")
+
+	stuck, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+
+	lease, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+	assert.Nil(t, lease.State())
+
+	// the stuck instance releasing its lease late doesn't overwrite the state of the new one
+	stuck.Release([]byte("stale"))
+	lease.Release([]byte("1234"))
+
+	next, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1234"), next.State())
+}
+
+func TestAcquireCanceled(t *testing.T) {
+	store := newHandoffStore()
+	ext := newTestExtension(store)
+	ext.cfg.HandoffTimeout = 10 * time.Second
+	id := config.NewComponentID("raw_k8s_events")
+
+	_, err := ext.Acquire(context.Background(), component.KindReceiver, id)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ext.Acquire(ctx, component.KindReceiver, id)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExpiredStateIsDiscarded(t *testing.T) {
+	store := newHandoffStore()
+	key := handoffKey{kind: component.KindReceiver, id: config.NewComponentID("mysqlrecords")}
+	otherKey := handoffKey{kind: component.KindReceiver, id: config.NewComponentID("postgresql_cdc")}
+	now := time.Now()
+
+	store.takeOver(key, time.Minute, now).Release([]byte("1"))
+	store.takeOver(otherKey, time.Minute, now).Release([]byte("2"))
+
+	lease := store.takeOver(key, time.Minute, now.Add(2*time.Minute))
+	assert.Nil(t, lease.State())
+	// the expired states of the other components are removed as well
+	assert.Nil(t, store.current(otherKey))
+}
+
+func TestReleaseTwice(t *testing.T) {
+	store := newHandoffStore()
+	key := handoffKey{kind: component.KindReceiver, id: config.NewComponentID("mysqlrecords")}
+
+	lease := store.takeOver(key, time.Minute, time.Now())
+	lease.Release([]byte("1"))
+	lease.Release([]byte("2"))
+
+	assert.Equal(t, []byte("1"), store.takeOver(key, time.Minute, time.Now()).State())
+}
+
+func TestGetReloadOrchestrator(t *testing.T) {
+	id := config.NewComponentID(typeStr)
+	orchestrator := newTestExtension(newHandoffStore())
+	host := testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			id:                         orchestrator,
+			config.NewComponentID("x"): otherExtension{},
+		},
+	}
+
+	got, err := GetReloadOrchestrator(host, id)
+	require.NoError(t, err)
+	assert.Equal(t, orchestrator, got)
+
+	_, err = GetReloadOrchestrator(host, config.NewComponentIDWithName(typeStr, "missing"))
+	assert.Error(t, err)
+
+	_, err = GetReloadOrchestrator(host, config.NewComponentID("x"))
+	assert.Error(t, err)
+}
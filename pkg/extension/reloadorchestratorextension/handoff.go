@@ -0,0 +1,123 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloadorchestratorextension
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+// processHandoffs holds the states handed off in this process. On config reload the collector shuts down
+// all components, the extension included, and creates them again, so the states can't live in the extension.
+var processHandoffs = newHandoffStore()
+
+type handoffKey struct {
+	kind component.Kind
+	id   config.ComponentID
+}
+
+// handoffStore holds the lease of the running instance of every component, or the state it handed off.
+type handoffStore struct {
+	mutex  sync.Mutex
+	leases map[handoffKey]*Lease
+}
+
+func newHandoffStore() *handoffStore {
+	return &handoffStore{
+		leases: map[handoffKey]*Lease{},
+	}
+}
+
+// Lease is held by the running instance of a component. The instance hands off its state
+// to the next instance of the component by releasing the lease on shutdown.
+type Lease struct {
+	key   handoffKey
+	store *handoffStore
+	// previous is the state handed off by the previous instance of the component, nil if there's none.
+	previous []byte
+
+	// released is closed once the lease is released.
+	released chan struct{}
+	// state and releasedAt are set on release, they are guarded by the store's mutex.
+	state      []byte
+	releasedAt time.Time
+}
+
+// State returns the state handed off by the previous instance of the component,
+// or nil if there's none, e.g. on the first start of the collector.
+func (l *Lease) State() []byte {
+	return l.previous
+}
+
+// Release hands off the state to the next instance of the component.
+// Components should release the lease at the end of their shutdown, once they don't change their state anymore.
+// A nil state tells the next instance there's nothing to take over. Calling Release more than once is a no-op.
+func (l *Lease) Release(state []byte) {
+	l.store.mutex.Lock()
+	defer l.store.mutex.Unlock()
+
+	select {
+	case <-l.released:
+		return
+	default:
+	}
+	l.state = state
+	l.releasedAt = time.Now()
+	close(l.released)
+}
+
+func (l *Lease) isReleased() bool {
+	select {
+	case <-l.released:
+		return true
+	default:
+		return false
+	}
+}
+
+// current returns the lease of the component, or nil if there's none.
+func (s *handoffStore) current(key handoffKey) *Lease {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.leases[key]
+}
+
+// takeOver replaces the lease of the component with a new one. The state of the previous lease
+// is passed to the new one, if it was released within the ttl.
+// States released by other components more than ttl ago are removed.
+func (s *handoffStore) takeOver(key handoffKey, ttl time.Duration, now time.Time) *Lease {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lease := &Lease{
+		key:      key,
+		store:    s,
+		released: make(chan struct{}),
+	}
+	if previous, ok := s.leases[key]; ok && previous.isReleased() && now.Sub(previous.releasedAt) <= ttl {
+		lease.previous = previous.state
+	}
+
+	for k, l := range s.leases {
+		if l.isReleased() && now.Sub(l.releasedAt) > ttl {
+			delete(s.leases, k)
+		}
+	}
+	s.leases[key] = lease
+	return lease
+}
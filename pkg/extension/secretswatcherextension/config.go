@@ -0,0 +1,77 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretswatcherextension
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	defaultPollInterval = 10 * time.Second
+)
+
+// Config has the configuration for the secrets watcher extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// Secrets is the list of secret files to watch.
+	Secrets []SecretConfig `mapstructure:"secrets"`
+
+	// PollInterval is the interval in which the secret files are checked for changes.
+	// Files are polled rather than watched with inotify, because mounted secrets
+	// (e.g. in Kubernetes) are replaced with a symlink swap which isn't reported
+	// for the file itself.
+	// By default this is 10 seconds.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+// SecretConfig defines a single watched secret file.
+type SecretConfig struct {
+	// Name identifies the secret for subscribers.
+	Name string `mapstructure:"name"`
+	// Path is the path to the file containing the secret.
+	Path string `mapstructure:"path"`
+}
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Secrets) == 0 {
+		return errors.New("at least one secret has to be configured")
+	}
+
+	names := map[string]struct{}{}
+	for _, secret := range cfg.Secrets {
+		if secret.Name == "" {
+			return errors.New("secret name cannot be empty")
+		}
+		if secret.Path == "" {
+			return fmt.Errorf("path of secret %q cannot be empty", secret.Name)
+		}
+		if _, ok := names[secret.Name]; ok {
+			return fmt.Errorf("secret %q is configured more than once", secret.Name)
+		}
+		names[secret.Name] = struct{}{}
+	}
+
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval has to be positive, got: %s", cfg.PollInterval)
+	}
+
+	return nil
+}
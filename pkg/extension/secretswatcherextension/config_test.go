@@ -0,0 +1,81 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretswatcherextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t, e0,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+			Secrets:           []SecretConfig{{Name: "db_password", Path: "/etc/secrets/db/password"}},
+			PollInterval:      defaultPollInterval,
+		})
+
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, e1,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			Secrets: []SecretConfig{
+				{Name: "db_password", Path: "/etc/secrets/db/password"},
+				{Name: "tls_cert", Path: "/etc/secrets/tls/tls.crt"},
+			},
+			PollInterval: time.Minute,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no secrets", modify: func(cfg *Config) { cfg.Secrets = nil }},
+		{name: "empty name", modify: func(cfg *Config) { cfg.Secrets[0].Name = "" }},
+		{name: "empty path", modify: func(cfg *Config) { cfg.Secrets[0].Path = "" }},
+		{name: "duplicated name", modify: func(cfg *Config) { cfg.Secrets = append(cfg.Secrets, cfg.Secrets[0]) }},
+		{name: "non-positive poll interval", modify: func(cfg *Config) { cfg.PollInterval = 0 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Secrets = []SecretConfig{{Name: "db_password", Path: "/etc/secrets/db/password"}}
+			require.NoError(t, cfg.Validate())
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretswatcherextension
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// SecretsWatcher is implemented by the secrets watcher extension.
+// Components which read credentials from files look it up in the host's extensions
+// and subscribe to changes of their secrets to reload them without a collector restart.
+type SecretsWatcher interface {
+	component.Extension
+
+	// Subscribe registers the subscriber for changes of the named secret.
+	// The returned function removes the subscription.
+	Subscribe(name string, subscriber SecretSubscriber) (unsubscribe func(), err error)
+
+	// Secret returns the current content of the named secret.
+	Secret(name string) ([]byte, bool)
+}
+
+type secretsWatcherExtension struct {
+	watcher *watcher
+}
+
+var _ SecretsWatcher = (*secretsWatcherExtension)(nil)
+
+func newSecretsWatcherExtension(cfg *Config, logger *zap.Logger) *secretsWatcherExtension {
+	return &secretsWatcherExtension{
+		watcher: newWatcher(cfg.Secrets, cfg.PollInterval, logger),
+	}
+}
+
+func (e *secretsWatcherExtension) Start(_ context.Context, _ component.Host) error {
+	return e.watcher.start()
+}
+
+func (e *secretsWatcherExtension) Shutdown(_ context.Context) error {
+	e.watcher.stop()
+	return nil
+}
+
+func (e *secretsWatcherExtension) Subscribe(name string, subscriber SecretSubscriber) (func(), error) {
+	return e.watcher.subscribe(name, subscriber)
+}
+
+func (e *secretsWatcherExtension) Secret(name string) ([]byte, bool) {
+	return e.watcher.secret(name)
+}
+
+// GetSecretsWatcher returns the secrets watcher extension with the given id from the host.
+func GetSecretsWatcher(host component.Host, id config.ComponentID) (SecretsWatcher, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	watcher, ok := ext.(SecretsWatcher)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a secrets watcher", id)
+	}
+	return watcher, nil
+}
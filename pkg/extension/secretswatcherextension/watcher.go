@@ -0,0 +1,185 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretswatcherextension
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SecretChange is broadcast to subscribers when the content of a watched secret file changes.
+type SecretChange struct {
+	// Name of the secret as configured in the extension.
+	Name string
+	// Path of the secret file.
+	Path string
+	// Content is the new content of the secret file.
+	Content []byte
+}
+
+// SecretSubscriber is called with every change of the secret it subscribed to.
+// Subscribers are called sequentially from the watcher's goroutine, so they shouldn't block.
+type SecretSubscriber func(SecretChange)
+
+type watchedSecret struct {
+	path        string
+	content     []byte
+	subscribers map[int]SecretSubscriber
+}
+
+// watcher polls secret files and notifies subscribers about changes of their content.
+type watcher struct {
+	pollInterval time.Duration
+	logger       *zap.Logger
+
+	mutex   sync.Mutex
+	secrets map[string]*watchedSecret
+	nextID  int
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWatcher(secrets []SecretConfig, pollInterval time.Duration, logger *zap.Logger) *watcher {
+	w := &watcher{
+		pollInterval: pollInterval,
+		logger:       logger,
+		secrets:      make(map[string]*watchedSecret, len(secrets)),
+		stopCh:       make(chan struct{}),
+	}
+	for _, secret := range secrets {
+		w.secrets[secret.Name] = &watchedSecret{
+			path:        secret.Path,
+			subscribers: map[int]SecretSubscriber{},
+		}
+	}
+	return w
+}
+
+// start reads the initial content of all secret files and starts polling them for changes.
+// It fails when any of the files cannot be read, so a misconfigured path is reported on startup.
+func (w *watcher) start() error {
+	w.mutex.Lock()
+	for name, secret := range w.secrets {
+		content, err := os.ReadFile(secret.path)
+		if err != nil {
+			w.mutex.Unlock()
+			return fmt.Errorf("failed to read secret %q: %w", name, err)
+		}
+		secret.content = content
+	}
+	w.mutex.Unlock()
+
+	w.wg.Add(1)
+	go w.poll()
+	return nil
+}
+
+func (w *watcher) stop() {
+	close(w.stopCh)
+	w.wg.Wait()
+}
+
+func (w *watcher) poll() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.checkSecrets()
+		}
+	}
+}
+
+// checkSecrets reads all secret files and notifies subscribers of the ones which changed.
+// A file which cannot be read, e.g. in the middle of a rotation, keeps its previous content.
+func (w *watcher) checkSecrets() {
+	type notification struct {
+		change      SecretChange
+		subscribers []SecretSubscriber
+	}
+	var notifications []notification
+
+	w.mutex.Lock()
+	for name, secret := range w.secrets {
+		content, err := os.ReadFile(secret.path)
+		if err != nil {
+			w.logger.Warn("Failed to read secret, keeping previous content", zap.String("secret", name), zap.Error(err))
+			continue
+		}
+		if bytes.Equal(content, secret.content) {
+			continue
+		}
+		secret.content = content
+
+		n := notification{change: SecretChange{Name: name, Path: secret.path, Content: content}}
+		for _, subscriber := range secret.subscribers {
+			n.subscribers = append(n.subscribers, subscriber)
+		}
+		notifications = append(notifications, n)
+		w.logger.Info("Secret changed", zap.String("secret", name), zap.Int("subscribers", len(n.subscribers)))
+	}
+	w.mutex.Unlock()
+
+	// subscribers are called without holding the lock, so they can (un)subscribe or read other secrets
+	for _, n := range notifications {
+		for _, subscriber := range n.subscribers {
+			subscriber(n.change)
+		}
+	}
+}
+
+// subscribe registers the subscriber for changes of the named secret.
+// The returned function removes the subscription.
+func (w *watcher) subscribe(name string, subscriber SecretSubscriber) (func(), error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	secret, ok := w.secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %q is not watched", name)
+	}
+	id := w.nextID
+	w.nextID++
+	secret.subscribers[id] = subscriber
+
+	return func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		delete(secret.subscribers, id)
+	}, nil
+}
+
+// secret returns the current content of the named secret.
+func (w *watcher) secret(name string) ([]byte, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	secret, ok := w.secrets[name]
+	if !ok || secret.content == nil {
+		return nil, false
+	}
+	return secret.content, true
+}
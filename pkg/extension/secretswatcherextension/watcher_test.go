@@ -0,0 +1,102 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretswatcherextension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWatcherNotifiesSubscribers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+	w := newWatcher([]SecretConfig{{Name: "db", Path: path}}, time.Millisecond, zap.NewNop())
+	require.NoError(t, w.start())
+	defer w.stop()
+
+	content, ok := w.secret("db")
+	require.True(t, ok)
+	assert.Equal(t, []byte("old"), content)
+
+	changes := make(chan SecretChange, 10)
+	unsubscribe, err := w.subscribe("db", func(change SecretChange) {
+		changes <- change
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("new"), 0600))
+	select {
+	case change := <-changes:
+		assert.Equal(t, SecretChange{Name: "db", Path: path, Content: []byte("new")}, change)
+	case <-time.After(5 * time.Second):
+		t.Fatal("secret change wasn't broadcast")
+	}
+
+	content, ok = w.secret("db")
+	require.True(t, ok)
+	assert.Equal(t, []byte("new"), content)
+
+	unsubscribe()
+	require.NoError(t, os.WriteFile(path, []byte("newer"), 0600))
+	assert.Eventually(t, func() bool {
+		content, _ := w.secret("db")
+		return string(content) == "newer"
+	}, 5*time.Second, time.Millisecond)
+	assert.Empty(t, changes)
+}
+
+func TestWatcherKeepsContentOfUnreadableFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(path, []byte("secret"), 0600))
+
+	w := newWatcher([]SecretConfig{{Name: "db", Path: path}}, time.Hour, zap.NewNop())
+	require.NoError(t, w.start())
+	defer w.stop()
+
+	notified := false
+	_, err := w.subscribe("db", func(SecretChange) { notified = true })
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(path))
+	w.checkSecrets()
+
+	content, ok := w.secret("db")
+	require.True(t, ok)
+	assert.Equal(t, []byte("secret"), content)
+	assert.False(t, notified)
+}
+
+func TestWatcherStartFailsOnMissingFile(t *testing.T) {
+	w := newWatcher([]SecretConfig{{Name: "db", Path: filepath.Join(t.TempDir(), "missing")}}, time.Hour, zap.NewNop())
+	assert.Error(t, w.start())
+}
+
+func TestWatcherSubscribeUnknownSecret(t *testing.T) {
+	w := newWatcher([]SecretConfig{{Name: "db", Path: "/dev/null"}}, time.Hour, zap.NewNop())
+	_, err := w.subscribe("unknown", func(SecretChange) {})
+	assert.Error(t, err)
+
+	_, ok := w.secret("unknown")
+	assert.False(t, ok)
+}
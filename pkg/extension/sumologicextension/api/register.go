@@ -23,6 +23,7 @@ type OpenRegisterRequestPayload struct {
 	TimeZone      string                 `json:"timeZone,omitempty"`
 	Clobber       bool                   `json:"clobber,omitempty"`
 	Fields        map[string]interface{} `json:"fields,omitempty"`
+	CSEForwarding bool                   `json:"cseForwarding,omitempty"`
 }
 
 type OpenRegisterResponsePayload struct {
@@ -30,4 +31,13 @@ type OpenRegisterResponsePayload struct {
 	CollectorCredentialKey string `json:"collectorCredentialKey"`
 	CollectorId            string `json:"collectorId"`
 	CollectorName          string `json:"collectorName"`
+	// CSEForwarding is the state of Cloud SIEM forwarding of the collector,
+	// it's not set when the API doesn't support it.
+	CSEForwarding *bool `json:"cseForwarding,omitempty"`
+}
+
+// AssignmentRequestPayload assigns the collector to a folder and an ingest budget.
+type AssignmentRequestPayload struct {
+	Folder         string `json:"folder,omitempty"`
+	IngestBudgetId string `json:"ingestBudgetId,omitempty"`
 }
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/api"
+)
+
+const assignmentUrl = "/api/v1/collector/assignment"
+
+// applyAssignment assigns the collector to the configured folder and ingest budget
+// with the collector credentials. The assignment is applied on every start and after
+// re-registration, so a changed configuration moves the collector, and a failed
+// assignment is retried by the heartbeat loop until it succeeds.
+func (se *SumologicExtension) applyAssignment(ctx context.Context) {
+	if !se.conf.Assignment.isSet() || se.assignmentApplied {
+		return
+	}
+
+	if err := se.sendAssignment(ctx, se.httpClient); err != nil {
+		se.logger.Error("Collector assignment failed, it will be retried",
+			zap.String("folder", se.conf.Assignment.Folder),
+			zap.String("ingest_budget_id", se.conf.Assignment.IngestBudgetID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	se.assignmentApplied = true
+	se.logger.Info("Collector assigned",
+		zap.String("folder", se.conf.Assignment.Folder),
+		zap.String("ingest_budget_id", se.conf.Assignment.IngestBudgetID),
+	)
+}
+
+func (se *SumologicExtension) sendAssignment(ctx context.Context, httpClient *http.Client) error {
+	u, err := url.Parse(se.BaseUrl() + assignmentUrl)
+	if err != nil {
+		return fmt.Errorf("unable to parse assignment URL %w", err)
+	}
+
+	var buff bytes.Buffer
+	if err = json.NewEncoder(&buff).Encode(api.AssignmentRequestPayload{
+		Folder:         se.conf.Assignment.Folder,
+		IngestBudgetId: se.conf.Assignment.IngestBudgetID,
+	}); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), &buff)
+	if err != nil {
+		return fmt.Errorf("unable to create HTTP request %w", err)
+	}
+
+	addJSONHeaders(req)
+	res, cancel, err := se.sendRequest(httpClient, req)
+	if err != nil {
+		return fmt.Errorf("unable to send HTTP request: %w", err)
+	}
+	defer cancel()
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var errResponse api.ErrorResponsePayload
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to read the collector assignment response body, status code: %d, err: %w",
+			res.StatusCode, err,
+		)
+	}
+	if err := json.Unmarshal(body, &errResponse); err == nil && len(errResponse.Errors) > 0 {
+		se.logger.Warn("Collector assignment rejected",
+			zap.Int("status_code", res.StatusCode),
+			zap.String("error_id", errResponse.ID),
+			zap.Any("errors", errResponse.Errors),
+		)
+	}
+
+	return fmt.Errorf("collector assignment request failed: %w",
+		ErrorAPI{
+			status: res.StatusCode,
+			body:   string(body),
+		},
+	)
+}
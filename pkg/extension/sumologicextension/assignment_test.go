@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/api"
+)
+
+func TestAssignmentAppliedAfterRegistration(t *testing.T) {
+	t.Parallel()
+
+	var assignments int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case registerUrl:
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "mycredentialID",
+				"collectorCredentialKey": "mycredentialKey",
+				"collectorId": "0000000001231231",
+				"collectorName": "otc-test-123456123123"
+			}`))
+			assert.NoError(t, err)
+		case assignmentUrl:
+			assert.Equal(t, http.MethodPut, req.Method)
+			user, pass, ok := req.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "mycredentialID", user)
+			assert.Equal(t, "mycredentialKey", pass)
+
+			var reqPayload api.AssignmentRequestPayload
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&reqPayload))
+			assert.Equal(t, api.AssignmentRequestPayload{Folder: "/fleet/production", IngestBudgetId: "0000000000000ABC"}, reqPayload)
+			atomic.AddInt32(&assignments, 1)
+			w.WriteHeader(http.StatusNoContent)
+		case heartbeatUrl:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	dir, err := os.MkdirTemp("", "otelcol-sumo-assignment-test-*")
+	t.Cleanup(func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "otc-test-123456123123"
+	cfg.ExtensionSettings = config.ExtensionSettings{}
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = dir
+	cfg.HeartBeatInterval = 10 * time.Millisecond
+	cfg.Assignment = AssignmentConfig{Folder: "/fleet/production", IngestBudgetID: "0000000000000ABC"}
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&assignments))
+
+	// the assignment isn't sent again once it's applied
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, se.Shutdown(context.Background()))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&assignments))
+}
+
+func TestAssignmentRetriedAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	var assignments int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case registerUrl:
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "mycredentialID",
+				"collectorCredentialKey": "mycredentialKey",
+				"collectorId": "0000000001231231",
+				"collectorName": "otc-test-123456123123"
+			}`))
+			assert.NoError(t, err)
+		case assignmentUrl:
+			if atomic.AddInt32(&assignments, 1) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				_, err := w.Write([]byte(`{"id":"XXXXX-XXXXX-XXXXX","errors":[{"code":"budget:not_found","message":"Ingest budget not found"}]}`))
+				assert.NoError(t, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case heartbeatUrl:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	dir, err := os.MkdirTemp("", "otelcol-sumo-assignment-retry-test-*")
+	t.Cleanup(func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "otc-test-123456123123"
+	cfg.ExtensionSettings = config.ExtensionSettings{}
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = dir
+	cfg.HeartBeatInterval = 10 * time.Millisecond
+	cfg.Assignment = AssignmentConfig{IngestBudgetID: "0000000000000ABC"}
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	// a failed assignment doesn't prevent the start
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&assignments) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, se.Shutdown(context.Background()))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&assignments))
+}
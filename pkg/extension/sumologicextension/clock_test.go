@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock which only moves forward when advanced by the test.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ clock = (*fakeClock)(nil)
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) timer {
+	return c.newTimer(d, nil)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) timer {
+	return c.newTimer(d, f)
+}
+
+func (c *fakeClock) newTimer(d time.Duration, f func()) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{
+		clock:  c,
+		c:      make(chan time.Time, 1),
+		f:      f,
+		when:   c.now.Add(d),
+		active: true,
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward and fires the timers which expired.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		if !t.active || t.when.After(c.now) {
+			continue
+		}
+		t.active = false
+		if t.f != nil {
+			go t.f()
+			continue
+		}
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+}
+
+// activeTimers returns the durations left until the active timers fire.
+// Functions scheduled with AfterFunc, i.e. the connect timeouts of in-flight requests, are skipped.
+func (c *fakeClock) activeTimers() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var durations []time.Duration
+	for _, t := range c.timers {
+		if t.active && t.f == nil {
+			durations = append(durations, t.when.Sub(c.now))
+		}
+	}
+	return durations
+}
+
+// waitForTimer waits until exactly one timer is active and returns the duration left until it fires.
+func (c *fakeClock) waitForTimer(t *testing.T) time.Duration {
+	var durations []time.Duration
+	require.Eventually(t, func() bool {
+		durations = c.activeTimers()
+		return len(durations) == 1
+	}, 5*time.Second, time.Millisecond, "expected a single active timer")
+	return durations[0]
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	c      chan time.Time
+	f      func()
+	when   time.Time
+	active bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.active
+	t.active = false
+	return wasActive
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.active
+	t.when = t.clock.now.Add(d)
+	t.active = true
+	return wasActive
+}
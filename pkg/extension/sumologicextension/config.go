@@ -15,6 +15,10 @@
 package sumologicextension
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -47,15 +51,37 @@ type Config struct {
 	// https://help.sumologic.com/Manage/Fields
 	CollectorFields map[string]interface{} `mapstructure:"collector_fields"`
 
+	// Deployment is the name of the Sumo Logic deployment, e.g. us2 or eu,
+	// which sets the API base URL. By default this is us1.
+	Deployment string `mapstructure:"deployment"`
+	// ApiBaseUrl is the base URL of the API. When set it takes precedence
+	// over the URL of the deployment.
 	ApiBaseUrl string `mapstructure:"api_base_url"`
 
 	HeartBeatInterval time.Duration `mapstructure:"heartbeat_interval"`
 
+	// ConnectTimeout is the maximum time for establishing a connection to the API,
+	// including DNS resolution and TLS handshake.
+	// By default this is 10 seconds.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+
+	// RequestTimeout is the maximum time for a single API request, i.e. collector
+	// registration or heartbeat, including reading the response.
+	// By default this is 30 seconds.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+
 	// CollectorCredentialsDirectory is the directory where state files
 	// with collector credentials will be stored after successful collector
 	// registration. Default value is $HOME/.sumologic-otel-collector
 	CollectorCredentialsDirectory string `mapstructure:"collector_credentials_directory"`
 
+	// LegacyCollectorCredentialsFile is the path of the credentials file of
+	// a legacy installed collector. When the file is present and there are no
+	// locally stored credentials, the identity of the legacy collector is adopted
+	// instead of registering a new collector, preserving its sources history.
+	// By default no legacy credentials are migrated.
+	LegacyCollectorCredentialsFile string `mapstructure:"legacy_collector_credentials_file"`
+
 	// Clobber defines whether to delete any existing collector with the same
 	// name and create a new one upon registration.
 	// By default this is false.
@@ -82,10 +108,54 @@ type Config struct {
 	// https://en.wikipedia.org/wiki/List_of_tz_database_time_zones#List.
 	TimeZone string `mapstructure:"time_zone"`
 
+	// CSEForwarding defines whether the collector is registered with
+	// Cloud SIEM Enterprise forwarding enabled, so the data it sends is
+	// forwarded to Cloud SIEM without enabling it in the UI.
+	// By default this is false.
+	CSEForwarding bool `mapstructure:"cse_forwarding"`
+
+	// Assignment defines the folder and the ingest budget the collector is
+	// assigned to via the management API after registration.
+	Assignment AssignmentConfig `mapstructure:"assignment"`
+
 	// BackOff defines configuration of collector registration backoff algorithm
 	// Exponential algorithm is being used.
 	// Please see following link for details: https://github.com/cenkalti/backoff
 	BackOff backOffConfig `mapstructure:"backoff"`
+
+	// DNS defines how the hostnames of the API endpoints are resolved.
+	DNS DNSConfig `mapstructure:"dns"`
+}
+
+// DNSConfig defines how the hostnames of the API endpoints are resolved.
+type DNSConfig struct {
+	// IPFamily restricts the resolved addresses to a single IP family,
+	// either "ipv4" or "ipv6".
+	// By default both IPv4 and IPv6 addresses are used.
+	IPFamily string `mapstructure:"ip_family"`
+
+	// Servers is a list of DNS servers, e.g. "10.0.0.2" or "10.0.0.2:53",
+	// used instead of the system resolver configuration.
+	Servers []string `mapstructure:"servers"`
+
+	// CacheTTL is the time for which the resolved addresses are cached.
+	// By default the addresses are not cached.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+type AssignmentConfig struct {
+	// Folder is the path of the folder the collector is moved to,
+	// e.g. "/fleet/production".
+	// By default the collector isn't moved.
+	Folder string `mapstructure:"folder"`
+
+	// IngestBudgetID is the id of the ingest budget the collector is assigned to.
+	// By default the ingest budget of the collector isn't changed.
+	IngestBudgetID string `mapstructure:"ingest_budget_id"`
+}
+
+func (cfg AssignmentConfig) isSet() bool {
+	return cfg.Folder != "" || cfg.IngestBudgetID != ""
 }
 
 type accessCredentials struct {
@@ -99,3 +169,41 @@ type backOffConfig struct {
 	MaxInterval     time.Duration `mapstructure:"max_interval"`
 	MaxElapsedTime  time.Duration `mapstructure:"max_elapsed_time"`
 }
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Deployment != "" {
+		if _, ok := deployments[strings.ToLower(cfg.Deployment)]; !ok {
+			return fmt.Errorf("invalid deployment %q, expected one of: %s", cfg.Deployment, strings.Join(deploymentNames(), ", "))
+		}
+	}
+
+	switch cfg.DNS.IPFamily {
+	case "", ipFamilyIPv4, ipFamilyIPv6:
+	default:
+		return fmt.Errorf("invalid dns ip_family %q, expected one of: %q, %q", cfg.DNS.IPFamily, ipFamilyIPv4, ipFamilyIPv6)
+	}
+
+	for _, server := range cfg.DNS.Servers {
+		host, _, err := net.SplitHostPort(dnsServerAddress(server))
+		if err != nil || net.ParseIP(host) == nil {
+			return fmt.Errorf("invalid dns server %q, expected an IP address with an optional port", server)
+		}
+	}
+
+	if cfg.Assignment.Folder != "" && (!strings.HasPrefix(cfg.Assignment.Folder, "/") || strings.Contains(cfg.Assignment.Folder, "//")) {
+		return fmt.Errorf("invalid assignment folder %q, expected an absolute path, e.g. /fleet/production", cfg.Assignment.Folder)
+	}
+
+	if cfg.DNS.CacheTTL < 0 {
+		return errors.New("dns cache_ttl cannot be negative")
+	}
+
+	// The resolution is set up on the HTTP transport, which isn't accessible
+	// when it's wrapped to add the headers or compress the requests.
+	if newDialer(cfg.DNS, realClock{}) != nil && (len(cfg.Headers) > 0 || cfg.Compression != "") {
+		return errors.New("dns options cannot be used together with headers or compression")
+	}
+
+	return nil
+}
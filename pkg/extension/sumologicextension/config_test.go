@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidateDNS(t *testing.T) {
+	testcases := []struct {
+		name          string
+		modify        func(cfg *Config)
+		expectedError string
+	}{
+		{
+			name:   "default",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "valid",
+			modify: func(cfg *Config) {
+				cfg.DNS = DNSConfig{IPFamily: "ipv4", Servers: []string{"10.0.0.2", "10.0.0.3:5353", "::1"}, CacheTTL: time.Minute}
+			},
+		},
+		{
+			name: "invalid ip family",
+			modify: func(cfg *Config) {
+				cfg.DNS.IPFamily = "ipv5"
+			},
+			expectedError: `invalid dns ip_family "ipv5"`,
+		},
+		{
+			name: "invalid server",
+			modify: func(cfg *Config) {
+				cfg.DNS.Servers = []string{"dns.example.com"}
+			},
+			expectedError: `invalid dns server "dns.example.com"`,
+		},
+		{
+			name: "negative cache ttl",
+			modify: func(cfg *Config) {
+				cfg.DNS.CacheTTL = -time.Second
+			},
+			expectedError: "dns cache_ttl cannot be negative",
+		},
+		{
+			name: "with headers",
+			modify: func(cfg *Config) {
+				cfg.DNS.IPFamily = "ipv4"
+				cfg.Headers = map[string]string{"X-Custom": "value"}
+			},
+			expectedError: "dns options cannot be used together with headers or compression",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfigValidateDeployment(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+
+	cfg.Deployment = "EU"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Deployment = "us3"
+	assert.ErrorContains(t, cfg.Validate(), `invalid deployment "us3", expected one of: au, ca, de, eu, fed, in, jp, long, nite, us1, us2`)
+}
+
+func TestConfigApiBaseUrl(t *testing.T) {
+	testcases := []struct {
+		name        string
+		deployment  string
+		apiBaseUrl  string
+		expectedUrl string
+	}{
+		{
+			name:        "default",
+			expectedUrl: "https://open-collectors.sumologic.com",
+		},
+		{
+			name:        "deployment",
+			deployment:  "us2",
+			expectedUrl: "https://open-collectors.us2.sumologic.com",
+		},
+		{
+			name:        "deployment is case insensitive",
+			deployment:  "FED",
+			expectedUrl: "https://open-collectors.fed.sumologic.com",
+		},
+		{
+			name:        "api_base_url overrides deployment",
+			deployment:  "us2",
+			apiBaseUrl:  "https://collectors.example.com/",
+			expectedUrl: "https://collectors.example.com",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Deployment = tc.deployment
+			cfg.ApiBaseUrl = tc.apiBaseUrl
+			assert.Equal(t, tc.expectedUrl, cfg.apiBaseUrl())
+		})
+	}
+}
+
+func TestConfigValidateAssignment(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+
+	cfg.Assignment = AssignmentConfig{Folder: "/fleet/production", IngestBudgetID: "0000000000000ABC"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Assignment = AssignmentConfig{IngestBudgetID: "0000000000000ABC"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Assignment.Folder = "fleet/production"
+	assert.ErrorContains(t, cfg.Validate(), `invalid assignment folder "fleet/production"`)
+
+	cfg.Assignment.Folder = "/fleet//production"
+	assert.ErrorContains(t, cfg.Validate(), `invalid assignment folder "/fleet//production"`)
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"sort"
+	"strings"
+)
+
+// deployments maps the names of Sumo Logic deployments to their API base URLs.
+// The exporters send the data to the URLs returned on registration, which
+// are on the same host, so the deployment sets the ingest URLs as well.
+var deployments = map[string]string{
+	"us1":  DefaultApiBaseUrl,
+	"us2":  "https://open-collectors.us2.sumologic.com",
+	"au":   "https://open-collectors.au.sumologic.com",
+	"ca":   "https://open-collectors.ca.sumologic.com",
+	"de":   "https://open-collectors.de.sumologic.com",
+	"eu":   "https://open-collectors.eu.sumologic.com",
+	"fed":  "https://open-collectors.fed.sumologic.com",
+	"in":   "https://open-collectors.in.sumologic.com",
+	"jp":   "https://open-collectors.jp.sumologic.com",
+	"long": "https://long-open-events.sumologic.net",
+	"nite": "https://nite-open-events.sumologic.net",
+}
+
+func deploymentNames() []string {
+	names := make([]string, 0, len(deployments))
+	for name := range deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apiBaseUrl returns the API base URL the collector registers against.
+// An explicitly configured api_base_url takes precedence over the deployment.
+func (cfg *Config) apiBaseUrl() string {
+	if cfg.ApiBaseUrl != "" {
+		return strings.TrimSuffix(cfg.ApiBaseUrl, "/")
+	}
+	if url, ok := deployments[strings.ToLower(cfg.Deployment)]; ok {
+		return url
+	}
+	return DefaultApiBaseUrl
+}
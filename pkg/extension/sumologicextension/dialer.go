@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ipFamilyIPv4 = "ipv4"
+	ipFamilyIPv6 = "ipv6"
+
+	defaultDNSPort = "53"
+)
+
+// dialer establishes connections to the API resolving the hostnames according
+// to the DNS configuration: only addresses of the configured IP family are
+// looked up, the configured DNS servers are queried instead of the system ones
+// and the resolved addresses are cached for the configured TTL.
+type dialer struct {
+	network  string
+	resolver *net.Resolver
+	lookupIP func(ctx context.Context, network string, host string) ([]net.IP, error)
+	netDial  func(ctx context.Context, network string, address string) (net.Conn, error)
+	cacheTTL time.Duration
+	clock    clock
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedAddresses
+}
+
+type cachedAddresses struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+// newDialer creates a dialer for the DNS configuration, it returns nil when
+// the default dual-stack resolution using the system resolver is configured.
+func newDialer(cfg DNSConfig, clock clock) *dialer {
+	if cfg.IPFamily == "" && len(cfg.Servers) == 0 && cfg.CacheTTL == 0 {
+		return nil
+	}
+
+	netDialer := &net.Dialer{}
+	resolver := &net.Resolver{}
+	if len(cfg.Servers) > 0 {
+		servers := make([]string, 0, len(cfg.Servers))
+		for _, server := range cfg.Servers {
+			servers = append(servers, dnsServerAddress(server))
+		}
+		var next uint32
+		resolver = &net.Resolver{
+			PreferGo: true,
+			// The Go resolver dials the nameservers from the system configuration,
+			// the configured servers are used in turn instead.
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				server := servers[int(atomic.AddUint32(&next, 1)-1)%len(servers)]
+				return netDialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	network := "tcp"
+	switch cfg.IPFamily {
+	case ipFamilyIPv4:
+		network = "tcp4"
+	case ipFamilyIPv6:
+		network = "tcp6"
+	}
+
+	return &dialer{
+		network:  network,
+		resolver: resolver,
+		lookupIP: resolver.LookupIP,
+		netDial:  netDialer.DialContext,
+		cacheTTL: cfg.CacheTTL,
+		clock:    clock,
+		cache:    map[string]cachedAddresses{},
+	}
+}
+
+// dnsServerAddress adds the default DNS port to the server address if it's missing.
+func dnsServerAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, defaultDNSPort)
+}
+
+// DialContext connects to the address trying the resolved addresses in order
+// until a connection is established.
+func (d *dialer) DialContext(ctx context.Context, _ string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return d.netDial(ctx, d.network, address)
+	}
+
+	ips, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := d.netDial(ctx, d.network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, dialErr
+}
+
+// resolve looks up the addresses of the configured IP family for the host,
+// using the cached addresses if they haven't expired yet.
+func (d *dialer) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if d.cacheTTL > 0 {
+		d.cacheMu.Lock()
+		cached, ok := d.cache[host]
+		d.cacheMu.Unlock()
+		if ok && d.clock.Now().Before(cached.expires) {
+			return cached.ips, nil
+		}
+	}
+
+	ipNetwork := "ip"
+	switch d.network {
+	case "tcp4":
+		ipNetwork = "ip4"
+	case "tcp6":
+		ipNetwork = "ip6"
+	}
+	ips, err := d.lookupIP(ctx, ipNetwork, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("failed to resolve %s: no addresses found", host)
+	}
+
+	if d.cacheTTL > 0 {
+		d.cacheMu.Lock()
+		d.cache[host] = cachedAddresses{ips: ips, expires: d.clock.Now().Add(d.cacheTTL)}
+		d.cacheMu.Unlock()
+	}
+	return ips, nil
+}
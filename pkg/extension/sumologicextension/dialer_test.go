@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves hostnames using a static map and records the lookups.
+type fakeResolver struct {
+	addresses map[string][]net.IP
+
+	mu      sync.Mutex
+	lookups []string
+}
+
+func (r *fakeResolver) LookupIP(_ context.Context, network string, host string) ([]net.IP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups = append(r.lookups, network+" "+host)
+	ips, ok := r.addresses[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return ips, nil
+}
+
+func (r *fakeResolver) Lookups() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.lookups...)
+}
+
+func TestNewDialerDefaults(t *testing.T) {
+	assert.Nil(t, newDialer(DNSConfig{}, realClock{}))
+}
+
+func TestDialerIPFamily(t *testing.T) {
+	testcases := []struct {
+		ipFamily        string
+		expectedNetwork string
+		expectedLookup  string
+	}{
+		{ipFamily: "", expectedNetwork: "tcp", expectedLookup: "ip collectors.sumologic.test"},
+		{ipFamily: ipFamilyIPv4, expectedNetwork: "tcp4", expectedLookup: "ip4 collectors.sumologic.test"},
+		{ipFamily: ipFamilyIPv6, expectedNetwork: "tcp6", expectedLookup: "ip6 collectors.sumologic.test"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.ipFamily, func(t *testing.T) {
+			resolver := &fakeResolver{addresses: map[string][]net.IP{
+				"collectors.sumologic.test": {net.ParseIP("10.0.0.1")},
+			}}
+			d := newDialer(DNSConfig{IPFamily: tc.ipFamily, Servers: []string{"127.0.0.1"}}, realClock{})
+			require.NotNil(t, d)
+			d.lookupIP = resolver.LookupIP
+			var dialed []string
+			d.netDial = func(_ context.Context, network string, address string) (net.Conn, error) {
+				dialed = append(dialed, network+" "+address)
+				return nil, errors.New("connection refused")
+			}
+
+			_, err := d.DialContext(context.Background(), "tcp", "collectors.sumologic.test:443")
+			assert.Error(t, err)
+			assert.Equal(t, []string{tc.expectedLookup}, resolver.Lookups())
+			assert.Equal(t, []string{tc.expectedNetwork + " 10.0.0.1:443"}, dialed)
+		})
+	}
+}
+
+func TestDialerTriesAllAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	resolver := &fakeResolver{addresses: map[string][]net.IP{
+		// nothing listens on the first address
+		"collectors.sumologic.test": {net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")},
+	}}
+	d := newDialer(DNSConfig{IPFamily: ipFamilyIPv4}, realClock{})
+	d.lookupIP = resolver.LookupIP
+	d.netDial = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if address != listener.Addr().String() {
+			return nil, errors.New("connection refused")
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}
+
+	conn, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("collectors.sumologic.test", port))
+	require.NoError(t, err)
+	assert.Equal(t, listener.Addr().String(), conn.RemoteAddr().String())
+	require.NoError(t, conn.Close())
+
+	_, err = d.DialContext(context.Background(), "tcp", "unknown.sumologic.test:443")
+	assert.ErrorContains(t, err, "failed to resolve unknown.sumologic.test")
+}
+
+func TestDialerCache(t *testing.T) {
+	clock := newFakeClock()
+	resolver := &fakeResolver{addresses: map[string][]net.IP{
+		"collectors.sumologic.test": {net.ParseIP("10.0.0.1")},
+	}}
+	d := newDialer(DNSConfig{CacheTTL: time.Minute}, clock)
+	d.lookupIP = resolver.LookupIP
+	d.netDial = func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	dial := func() {
+		_, err := d.DialContext(context.Background(), "tcp", "collectors.sumologic.test:443")
+		require.Error(t, err)
+	}
+
+	dial()
+	dial()
+	assert.Len(t, resolver.Lookups(), 1)
+
+	clock.Advance(time.Minute)
+	dial()
+	assert.Len(t, resolver.Lookups(), 2)
+}
+
+func TestDialerCustomServers(t *testing.T) {
+	first, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { first.Close() })
+	second, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { second.Close() })
+
+	d := newDialer(DNSConfig{Servers: []string{first.LocalAddr().String(), second.LocalAddr().String()}}, realClock{})
+	require.NotNil(t, d)
+	require.NotNil(t, d.resolver.Dial)
+
+	// the configured servers are queried in turn instead of the system ones
+	for _, server := range []net.PacketConn{first, second, first} {
+		conn, err := d.resolver.Dial(context.Background(), "udp", "192.0.2.1:53")
+		require.NoError(t, err)
+		assert.Equal(t, server.LocalAddr().String(), conn.RemoteAddr().String())
+		require.NoError(t, conn.Close())
+	}
+}
+
+func TestDNSServerAddress(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:53", dnsServerAddress("127.0.0.1"))
+	assert.Equal(t, "[::1]:53", dnsServerAddress("::1"))
+	assert.Equal(t, "127.0.0.1:5353", dnsServerAddress("127.0.0.1:5353"))
+}
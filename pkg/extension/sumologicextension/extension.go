@@ -23,10 +23,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -57,11 +59,23 @@ type SumologicExtension struct {
 	credentialsStore credentials.Store
 	hashKey          string
 	httpClient       *http.Client
+	dialer           *dialer
 	registrationInfo api.OpenRegisterResponsePayload
 
-	closeChan chan struct{}
-	closeOnce sync.Once
-	backOff   *backoff.ExponentialBackOff
+	// ctx is canceled on shutdown, which cancels all in-flight API requests.
+	ctx         context.Context
+	cancel      context.CancelFunc
+	heartbeatWg sync.WaitGroup
+	backOff     *backoff.ExponentialBackOff
+	clock       clock
+
+	// assignmentApplied is set once the configured assignment was applied
+	// to the registered collector, it's only accessed by the heartbeat loop
+	// after the start.
+	assignmentApplied bool
+
+	// throttleHook shares the feedback about requests sent by the exporters.
+	throttleHook throttleHook
 }
 
 const (
@@ -75,6 +89,8 @@ const (
 
 const (
 	DefaultHeartbeatInterval = 15 * time.Second
+	DefaultConnectTimeout    = 10 * time.Second
+	DefaultRequestTimeout    = 30 * time.Second
 )
 
 var errGRPCNotSupported = fmt.Errorf("gRPC is not supported by sumologicextension")
@@ -119,6 +135,12 @@ func newSumologicExtension(conf *Config, logger *zap.Logger) (*SumologicExtensio
 	if conf.HeartBeatInterval <= 0 {
 		conf.HeartBeatInterval = DefaultHeartbeatInterval
 	}
+	if conf.ConnectTimeout <= 0 {
+		conf.ConnectTimeout = DefaultConnectTimeout
+	}
+	if conf.RequestTimeout <= 0 {
+		conf.RequestTimeout = DefaultRequestTimeout
+	}
 
 	// Prepare ExponentialBackoff
 	backOff := backoff.NewExponentialBackOff()
@@ -126,16 +148,21 @@ func newSumologicExtension(conf *Config, logger *zap.Logger) (*SumologicExtensio
 	backOff.MaxElapsedTime = conf.BackOff.MaxElapsedTime
 	backOff.MaxInterval = conf.BackOff.MaxInterval
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &SumologicExtension{
 		collectorName:    collectorName,
-		baseUrl:          strings.TrimSuffix(conf.ApiBaseUrl, "/"),
+		baseUrl:          conf.apiBaseUrl(),
 		conf:             conf,
 		origLogger:       logger,
 		logger:           logger,
 		hashKey:          hashKey,
 		credentialsStore: credentialsStore,
-		closeChan:        make(chan struct{}),
+		ctx:              ctx,
+		cancel:           cancel,
 		backOff:          backOff,
+		clock:            realClock{},
+		dialer:           newDialer(conf.DNS, realClock{}),
 	}, nil
 }
 
@@ -143,7 +170,7 @@ func createHashKey(conf *Config) string {
 	return fmt.Sprintf("%s%s%s",
 		conf.CollectorName,
 		conf.Credentials.InstallToken,
-		strings.TrimSuffix(conf.ApiBaseUrl, "/"),
+		conf.apiBaseUrl(),
 	)
 }
 
@@ -165,18 +192,47 @@ func (se *SumologicExtension) Start(ctx context.Context, host component.Host) er
 		zap.String(collectorIdField, colCreds.Credentials.CollectorId),
 	)
 
+	if se.conf.CSEForwarding {
+		se.logCSEForwardingState()
+	}
+
+	se.applyAssignment(ctx)
+
+	se.heartbeatWg.Add(1)
 	go se.heartbeatLoop()
 
 	return nil
 }
 
+// logCSEForwardingState reports whether Cloud SIEM forwarding requested in the
+// configuration was enabled for the collector.
+func (se *SumologicExtension) logCSEForwardingState() {
+	switch state := se.registrationInfo.CSEForwarding; {
+	case state == nil:
+		se.logger.Warn("Cloud SIEM forwarding state not returned by the API, " +
+			"it might not be supported by the deployment or the credentials were stored before it was enabled")
+	case !*state:
+		se.logger.Warn("Cloud SIEM forwarding was requested but is not enabled for the collector")
+	default:
+		se.logger.Info("Cloud SIEM forwarding enabled")
+	}
+}
+
 // Shutdown is invoked during service shutdown.
+// It cancels in-flight API requests and waits for the heartbeat loop to finish.
 func (se *SumologicExtension) Shutdown(ctx context.Context) error {
-	se.closeOnce.Do(func() { close(se.closeChan) })
+	se.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		se.heartbeatWg.Wait()
+		close(done)
+	}()
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	default:
+	case <-done:
 		return nil
 	}
 }
@@ -219,6 +275,10 @@ func (se *SumologicExtension) getHTTPClient(
 	httpClientSettings confighttp.HTTPClientSettings,
 	regInfo api.OpenRegisterResponsePayload,
 ) (*http.Client, error) {
+	if se.dialer != nil {
+		httpClientSettings.CustomRoundTripper = se.setDialer
+	}
+
 	httpClient, err := httpClientSettings.ToClient(
 		se.host.GetExtensions(),
 		component.TelemetrySettings{},
@@ -237,6 +297,17 @@ func (se *SumologicExtension) getHTTPClient(
 	return httpClient, nil
 }
 
+// setDialer makes the transport establish connections using the dialer
+// configured with the DNS options.
+func (se *SumologicExtension) setDialer(next http.RoundTripper) (http.RoundTripper, error) {
+	transport, ok := next.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("dns options are not supported with transport %T", next)
+	}
+	transport.DialContext = se.dialer.DialContext
+	return transport, nil
+}
+
 // getCredentials retrieves the credentials for the collector.
 // It does so by checking the local credentials store and by validating those credentials.
 // In case they are invalid or are not available through local credentials store
@@ -274,7 +345,22 @@ func (se *SumologicExtension) getCredentials(ctx context.Context) (credentials.C
 				zap.Error(errV),
 			)
 		} else {
-			se.logger.Info("Locally stored credentials not found, registering the collector")
+			se.logger.Info("Locally stored credentials not found")
+		}
+
+		if se.legacyCredentialsPresent() {
+			colCreds, err = se.getLegacyCredentials(ctx)
+			if err == nil {
+				se.logger.Info("Adopted legacy installed collector credentials, skipping registration",
+					zap.String(collectorNameField, colCreds.Credentials.CollectorName),
+					zap.String(collectorIdField, colCreds.Credentials.CollectorId),
+				)
+				return colCreds, nil
+			}
+
+			se.logger.Warn("Unable to adopt legacy installed collector credentials, registering the collector",
+				zap.Error(err),
+			)
 		}
 	}
 
@@ -350,6 +436,7 @@ func (se *SumologicExtension) registerCollector(ctx context.Context, collectorNa
 		Ephemeral:     se.conf.Ephemeral,
 		Clobber:       se.conf.Clobber,
 		TimeZone:      se.conf.TimeZone,
+		CSEForwarding: se.conf.CSEForwarding,
 	}); err != nil {
 		return credentials.CollectorCredentials{}, err
 	}
@@ -367,15 +454,22 @@ func (se *SumologicExtension) registerCollector(ctx context.Context, collectorNa
 	se.logger.Info("Calling register API", zap.String("URL", u.String()))
 
 	client := *http.DefaultClient
+	if se.dialer != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = se.dialer.DialContext
+		defer transport.CloseIdleConnections()
+		client.Transport = transport
+	}
 	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
 	}
-	res, err := client.Do(req)
+	res, cancel, err := se.sendRequest(&client, req)
 	if err != nil {
 		se.logger.Warn("Collector registration HTTP request failed", zap.Error(err))
 		return credentials.CollectorCredentials{}, fmt.Errorf("failed to register the collector: %w", err)
 	}
 
+	defer cancel()
 	defer res.Body.Close()
 
 	if res.StatusCode < 200 || res.StatusCode >= 400 {
@@ -442,6 +536,7 @@ func (se *SumologicExtension) handleRegistrationError(res *http.Response) (crede
 // callRegisterWithBackoff calls registration using exponential backoff algorithm
 // this loosely base on backoff.Retry function
 func (se *SumologicExtension) registerCollectorWithBackoff(ctx context.Context, collectorName string) (credentials.CollectorCredentials, error) {
+	se.backOff.Clock = se.clock
 	se.backOff.Reset()
 	for {
 		creds, err := se.registerCollector(ctx, collectorName)
@@ -461,37 +556,36 @@ func (se *SumologicExtension) registerCollectorWithBackoff(ctx context.Context,
 			return credentials.CollectorCredentials{}, fmt.Errorf("collector registration failed: %w", err)
 		}
 
-		t := time.NewTimer(nbo)
-		defer t.Stop()
+		t := se.clock.NewTimer(nbo)
 
 		select {
-		case <-t.C:
+		case <-t.C():
 		case <-ctx.Done():
+			t.Stop()
 			return credentials.CollectorCredentials{}, fmt.Errorf("collector registration cancelled: %w", ctx.Err())
 		}
 	}
 }
 
 func (se *SumologicExtension) heartbeatLoop() {
+	defer se.heartbeatWg.Done()
+
 	if se.registrationInfo.CollectorCredentialId == "" || se.registrationInfo.CollectorCredentialKey == "" {
 		se.logger.Error("Collector not registered, cannot send heartbeat")
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	go func() {
-		// When the close channel is closed ...
-		<-se.closeChan
-		// ... cancel the ongoing heartbeat request.
-		cancel()
-	}()
+	// The extension's context is canceled on shutdown, which also cancels the ongoing heartbeat request.
+	ctx := se.ctx
 
 	se.logger.Info("Heartbeat loop initialized. Starting to send hearbeat requests")
-	timer := time.NewTimer(se.conf.HeartBeatInterval)
+	// The interval is measured from the start of the previous heartbeat,
+	// so slow heartbeat requests don't delay the following ones.
+	heartbeatTimer := se.clock.NewTimer(se.conf.HeartBeatInterval)
+	defer heartbeatTimer.Stop()
 	for {
 		select {
-		case <-se.closeChan:
+		case <-ctx.Done():
 			se.logger.Info("Heartbeat sender turned off")
 			return
 
@@ -501,24 +595,9 @@ func (se *SumologicExtension) heartbeatLoop() {
 			if err != nil {
 				if errors.Is(err, errUnauthorizedHeartbeat) {
 					se.logger.Warn("Heartbeat request unauthorized, re-registering the collector")
-					colCreds, err := se.getCredentialsByRegistering(ctx)
-					if err != nil {
-						se.logger.Error("Heartbeat error, cannot register the collector", zap.Error(err))
-						continue
-					}
-
-					// Inject newly received credentials into extension's configuration.
-					if err = se.injectCredentials(colCreds); err != nil {
-						se.logger.Error("Heartbeat error, cannot inject new collector credentials", zap.Error(err))
-						continue
-					}
-
-					// Overwrite old logger fields with new collector name and ID.
-					se.logger = se.origLogger.With(
-						zap.String(collectorNameField, colCreds.Credentials.CollectorName),
-						zap.String(collectorIdField, colCreds.Credentials.CollectorId),
-					)
-
+					// A failed re-registration is retried after the heartbeat interval,
+					// so the API isn't flooded with registration requests.
+					se.reregister(ctx)
 				} else {
 					se.logger.Error("Heartbeat error", zap.Error(err))
 				}
@@ -526,17 +605,42 @@ func (se *SumologicExtension) heartbeatLoop() {
 				se.logger.Debug("Heartbeat sent")
 			}
 
+			se.applyAssignment(ctx)
+
 			select {
-			case <-timer.C:
-				timer.Stop()
-				timer.Reset(se.conf.HeartBeatInterval)
-			case <-se.closeChan:
+			case <-heartbeatTimer.C():
+				heartbeatTimer.Reset(se.conf.HeartBeatInterval)
+			case <-ctx.Done():
 			}
 
 		}
 	}
 }
 
+// reregister registers the collector again after its credentials were rejected by the API.
+func (se *SumologicExtension) reregister(ctx context.Context) {
+	colCreds, err := se.getCredentialsByRegistering(ctx)
+	if err != nil {
+		se.logger.Error("Heartbeat error, cannot register the collector", zap.Error(err))
+		return
+	}
+
+	// Inject newly received credentials into extension's configuration.
+	if err = se.injectCredentials(colCreds); err != nil {
+		se.logger.Error("Heartbeat error, cannot inject new collector credentials", zap.Error(err))
+		return
+	}
+
+	// Overwrite old logger fields with new collector name and ID.
+	se.logger = se.origLogger.With(
+		zap.String(collectorNameField, colCreds.Credentials.CollectorName),
+		zap.String(collectorIdField, colCreds.Credentials.CollectorId),
+	)
+
+	// The collector might have been registered anew, so it has to be assigned again.
+	se.assignmentApplied = false
+}
+
 var errUnauthorizedHeartbeat = errors.New("heartbeat unauthorized")
 
 type ErrorAPI struct {
@@ -559,10 +663,11 @@ func (se *SumologicExtension) sendHeartbeatWithHTTPClient(ctx context.Context, h
 	}
 
 	addJSONHeaders(req)
-	res, err := httpClient.Do(req)
+	res, cancel, err := se.sendRequest(httpClient, req)
 	if err != nil {
 		return fmt.Errorf("unable to send HTTP request: %w", err)
 	}
+	defer cancel()
 	defer res.Body.Close()
 
 	switch res.StatusCode {
@@ -591,6 +696,52 @@ func (se *SumologicExtension) sendHeartbeatWithHTTPClient(ctx context.Context, h
 	return nil
 }
 
+// sendRequest sends the API request enforcing the configured timeouts: the whole request
+// is canceled after the request timeout and when establishing a connection (including
+// DNS resolution and TLS handshake) takes longer than the connect timeout.
+// The returned cancel function has to be called once the response body is read.
+func (se *SumologicExtension) sendRequest(httpClient *http.Client, req *http.Request) (*http.Response, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), se.conf.RequestTimeout)
+
+	var (
+		connectTimedOut int32
+		connectTimer    timer
+		connectTimerMu  sync.Mutex
+	)
+	stopConnectTimer := func() {
+		connectTimerMu.Lock()
+		defer connectTimerMu.Unlock()
+		if connectTimer != nil {
+			connectTimer.Stop()
+		}
+	}
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			connectTimerMu.Lock()
+			defer connectTimerMu.Unlock()
+			connectTimer = se.clock.AfterFunc(se.conf.ConnectTimeout, func() {
+				atomic.StoreInt32(&connectTimedOut, 1)
+				cancel()
+			})
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			stopConnectTimer()
+		},
+	}
+
+	res, err := httpClient.Do(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+	stopConnectTimer()
+	if err != nil {
+		cancel()
+		if atomic.LoadInt32(&connectTimedOut) == 1 {
+			return nil, nil, fmt.Errorf("connecting to %s timed out after %s: %w", req.URL.Host, se.conf.ConnectTimeout, err)
+		}
+		return nil, nil, err
+	}
+
+	return res, cancel, nil
+}
+
 func (se *SumologicExtension) ComponentID() config.ComponentID {
 	return se.conf.ExtensionSettings.ID()
 }
@@ -599,6 +750,12 @@ func (se *SumologicExtension) CollectorID() string {
 	return se.registrationInfo.CollectorId
 }
 
+// CSEForwardingEnabled returns whether Cloud SIEM forwarding is enabled for the
+// registered collector.
+func (se *SumologicExtension) CSEForwardingEnabled() bool {
+	return se.registrationInfo.CSEForwarding != nil && *se.registrationInfo.CSEForwarding
+}
+
 func (se *SumologicExtension) BaseUrl() string {
 	se.baseUrlLock.RLock()
 	defer se.baseUrlLock.RUnlock()
@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -1223,6 +1224,7 @@ func TestRegistrationRequestPayload(t *testing.T) {
 				reqPayload.Fields,
 			)
 			require.Equal(t, "PST", reqPayload.TimeZone)
+			require.True(t, reqPayload.CSEForwarding)
 
 			authHeader := req.Header.Get("Authorization")
 			assert.Equal(t, "Bearer dummy_install_token", authHeader,
@@ -1261,6 +1263,7 @@ func TestRegistrationRequestPayload(t *testing.T) {
 		"field2": "value2",
 	}
 	cfg.TimeZone = "PST"
+	cfg.CSEForwarding = true
 
 	se, err := newSumologicExtension(cfg, zap.NewNop())
 	require.NoError(t, err)
@@ -1272,3 +1275,243 @@ func TestRegistrationRequestPayload(t *testing.T) {
 
 	require.NoError(t, se.Shutdown(context.Background()))
 }
+
+func TestRegistrationCSEForwarding(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		state    string
+		expected bool
+	}{
+		{name: "enabled", state: `, "cseForwarding": true`, expected: true},
+		{name: "disabled", state: `, "cseForwarding": false`, expected: false},
+		{name: "not supported", state: ``, expected: false},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case registerUrl:
+					_, err := w.Write([]byte(`{
+						"collectorCredentialId": "mycredentialID",
+						"collectorCredentialKey": "mycredentialKey",
+						"collectorId": "0000000001231231",
+						"collectorName": "otc-test-123456123123"` + tc.state + `
+					}`))
+					assert.NoError(t, err)
+				case heartbeatUrl:
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+
+			dir, err := os.MkdirTemp("", "otelcol-sumo-cse-forwarding-test-*")
+			t.Cleanup(func() {
+				srv.Close()
+				os.RemoveAll(dir)
+			})
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig().(*Config)
+			cfg.CollectorName = "otc-test-123456123123"
+			cfg.ExtensionSettings = config.ExtensionSettings{}
+			cfg.ApiBaseUrl = srv.URL
+			cfg.Credentials.InstallToken = "dummy_install_token"
+			cfg.CollectorCredentialsDirectory = dir
+			cfg.CSEForwarding = true
+
+			se, err := newSumologicExtension(cfg, zap.NewNop())
+			require.NoError(t, err)
+			require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+			assert.Equal(t, tc.expected, se.CSEForwardingEnabled())
+			require.NoError(t, se.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestRegistrationRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Never respond to mimic a hung backend.
+		<-unblock
+	}))
+	t.Cleanup(func() { srv.Close() })
+	t.Cleanup(func() { close(unblock) })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector_name"
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.RequestTimeout = 100 * time.Millisecond
+	cfg.BackOff.InitialInterval = time.Millisecond
+	cfg.BackOff.MaxInterval = time.Millisecond
+	cfg.BackOff.MaxElapsedTime = 50 * time.Millisecond
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = se.Start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRegistrationConnectTimeout(t *testing.T) {
+	t.Parallel()
+
+	// Accept connections, but never complete the TLS handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector_name"
+	cfg.ApiBaseUrl = "https://" + listener.Addr().String()
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.ConnectTimeout = 100 * time.Millisecond
+	cfg.RequestTimeout = time.Minute
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = se.registerCollector(context.Background(), "collector_name")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestShutdownCancelsInFlightHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	var reqCount int32
+	heartbeatReceived := make(chan struct{})
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch atomic.AddInt32(&reqCount, 1) {
+		// register
+		case 1:
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "collectorId",
+				"collectorCredentialKey": "collectorKey",
+				"collectorId": "id"
+			}`))
+			require.NoError(t, err)
+
+		// heartbeat, never respond to mimic a hung backend
+		case 2:
+			close(heartbeatReceived)
+			<-unblock
+		}
+	}))
+	t.Cleanup(func() { srv.Close() })
+	t.Cleanup(func() { close(unblock) })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector_name"
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.RequestTimeout = time.Minute
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+
+	select {
+	case <-heartbeatReceived:
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat request wasn't sent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, se.Shutdown(ctx))
+}
+
+func TestStartWithDNSOptions(t *testing.T) {
+	t.Parallel()
+
+	var heartbeats int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case registerUrl:
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "mycredentialID",
+				"collectorCredentialKey": "mycredentialKey",
+				"collectorId": "0000000001231231",
+				"collectorName": "otc-test-123456123123"
+			}`))
+			assert.NoError(t, err)
+		case heartbeatUrl:
+			atomic.AddInt32(&heartbeats, 1)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "otc-test-123456123123"
+	cfg.ExtensionSettings = config.ExtensionSettings{}
+	cfg.ApiBaseUrl = "http://" + net.JoinHostPort("collectors.sumologic.test", port)
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.DNS = DNSConfig{IPFamily: ipFamilyIPv4, CacheTTL: time.Hour}
+	require.NoError(t, cfg.Validate())
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	resolver := &fakeResolver{addresses: map[string][]net.IP{
+		"collectors.sumologic.test": {net.ParseIP("127.0.0.1")},
+	}}
+	se.dialer.lookupIP = resolver.LookupIP
+
+	// both the registration and the heartbeat requests use the resolved address
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&heartbeats) > 0 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, se.Shutdown(context.Background()))
+	assert.Equal(t, []string{"ip4 collectors.sumologic.test"}, resolver.Lookups())
+}
+
+func TestHashKeyIsStableForDeployment(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector"
+	cfg.Credentials.InstallToken = "token"
+	defaultKey := createHashKey(cfg)
+
+	cfg.ApiBaseUrl = "https://open-collectors.sumologic.com/"
+	assert.Equal(t, defaultKey, createHashKey(cfg), "credentials stored with the default URL should be reused")
+
+	cfg.ApiBaseUrl = ""
+	cfg.Deployment = "us1"
+	assert.Equal(t, defaultKey, createHashKey(cfg))
+
+	cfg.Deployment = "eu"
+	assert.NotEqual(t, defaultKey, createHashKey(cfg))
+}
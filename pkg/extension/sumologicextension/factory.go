@@ -47,8 +47,9 @@ func createDefaultConfig() config.Extension {
 
 	return &Config{
 		ExtensionSettings:             config.NewExtensionSettings(config.NewComponentID(typeStr)),
-		ApiBaseUrl:                    DefaultApiBaseUrl,
 		HeartBeatInterval:             DefaultHeartbeatInterval,
+		ConnectTimeout:                DefaultConnectTimeout,
+		RequestTimeout:                DefaultRequestTimeout,
 		CollectorCredentialsDirectory: defaultCredsPath,
 		Clobber:                       false,
 		ForceRegistration:             false,
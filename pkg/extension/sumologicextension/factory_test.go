@@ -38,7 +38,8 @@ func TestFactory_CreateDefaultConfig(t *testing.T) {
 	assert.Equal(t, &Config{
 		ExtensionSettings:             config.NewExtensionSettings(config.NewComponentID(typeStr)),
 		HeartBeatInterval:             DefaultHeartbeatInterval,
-		ApiBaseUrl:                    DefaultApiBaseUrl,
+		ConnectTimeout:                DefaultConnectTimeout,
+		RequestTimeout:                DefaultRequestTimeout,
 		CollectorCredentialsDirectory: defaultCredsPath,
 		BackOff: backOffConfig{
 			InitialInterval: backoff.DefaultInitialInterval,
@@ -0,0 +1,374 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+const testHeartbeatInterval = 15 * time.Second
+
+// testAPI is a fake Sumo Logic API counting registration and heartbeat requests.
+type testAPI struct {
+	registrations int32
+	heartbeats    int32
+
+	// registrationStatus returns the status code of the registration request with the given number, starting from 1.
+	registrationStatus func(n int32) int
+	// heartbeatStatus returns the status code of the heartbeat request with the given number, starting from 1.
+	heartbeatStatus func(n int32) int
+	// heartbeatBlock, if set, blocks heartbeat requests until it's closed.
+	heartbeatBlock chan struct{}
+}
+
+func (a *testAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case registerUrl:
+		n := atomic.AddInt32(&a.registrations, 1)
+		if a.registrationStatus != nil {
+			if status := a.registrationStatus(n); status != http.StatusOK {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{"id":"ERROR","errors":[{"code":"error","message":"error"}]}`))
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{
+			"collectorCredentialId": "collectorId",
+			"collectorCredentialKey": "collectorKey",
+			"collectorId": "id"
+		}`))
+
+	case heartbeatUrl:
+		n := atomic.AddInt32(&a.heartbeats, 1)
+		if a.heartbeatBlock != nil {
+			<-a.heartbeatBlock
+		}
+		status := http.StatusNoContent
+		if a.heartbeatStatus != nil {
+			status = a.heartbeatStatus(n)
+		}
+		w.WriteHeader(status)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (a *testAPI) registrationCount() int32 {
+	return atomic.LoadInt32(&a.registrations)
+}
+
+func (a *testAPI) heartbeatCount() int32 {
+	return atomic.LoadInt32(&a.heartbeats)
+}
+
+func (a *testAPI) waitForHeartbeats(t *testing.T, n int32) {
+	require.Eventually(t, func() bool { return a.heartbeatCount() == n }, 5*time.Second, time.Millisecond,
+		"expected %d heartbeats, got %d", n, a.heartbeatCount())
+}
+
+// newTestExtensionWithClock creates the extension using the fake API and a fake clock.
+func newTestExtensionWithClock(t *testing.T, api *testAPI) (*SumologicExtension, *fakeClock) {
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "collector_name"
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.HeartBeatInterval = testHeartbeatInterval
+	cfg.BackOff.InitialInterval = time.Second
+	cfg.BackOff.MaxInterval = 10 * time.Second
+	cfg.BackOff.MaxElapsedTime = time.Minute
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+	se.clock = clock
+	se.backOff.RandomizationFactor = 0
+	return se, clock
+}
+
+func shutdownExtension(t *testing.T, se *SumologicExtension) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, se.Shutdown(ctx))
+}
+
+func TestHeartbeatInterval(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	defer shutdownExtension(t, se)
+
+	// the first heartbeat is sent right after the start
+	api.waitForHeartbeats(t, 1)
+	assert.Equal(t, testHeartbeatInterval, clock.waitForTimer(t))
+
+	clock.Advance(testHeartbeatInterval - time.Second)
+	assert.Equal(t, time.Second, clock.waitForTimer(t))
+	assert.Equal(t, int32(1), api.heartbeatCount())
+
+	for i := int32(2); i <= 5; i++ {
+		clock.Advance(clock.waitForTimer(t))
+		api.waitForHeartbeats(t, i)
+	}
+	assert.Equal(t, int32(1), api.registrationCount())
+}
+
+func TestHeartbeatIntervalFromHeartbeatStart(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{heartbeatBlock: make(chan struct{})}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	defer shutdownExtension(t, se)
+
+	// the heartbeat takes longer than the interval
+	api.waitForHeartbeats(t, 1)
+	clock.Advance(testHeartbeatInterval + time.Second)
+	close(api.heartbeatBlock)
+
+	// so the next one is sent right after it finishes
+	api.waitForHeartbeats(t, 2)
+	assert.Equal(t, testHeartbeatInterval, clock.waitForTimer(t))
+}
+
+func TestHeartbeatErrorWaitsForInterval(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{heartbeatStatus: func(int32) int { return http.StatusInternalServerError }}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	defer shutdownExtension(t, se)
+
+	api.waitForHeartbeats(t, 1)
+	assert.Equal(t, testHeartbeatInterval, clock.waitForTimer(t))
+	assert.Equal(t, int32(1), api.heartbeatCount())
+
+	clock.Advance(testHeartbeatInterval)
+	api.waitForHeartbeats(t, 2)
+}
+
+func TestHeartbeatUnauthorizedReregisters(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{heartbeatStatus: func(n int32) int {
+		if n == 2 {
+			return http.StatusUnauthorized
+		}
+		return http.StatusNoContent
+	}}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	defer shutdownExtension(t, se)
+
+	api.waitForHeartbeats(t, 1)
+	clock.Advance(clock.waitForTimer(t))
+	api.waitForHeartbeats(t, 2)
+
+	// the collector is registered again and the heartbeats continue on schedule
+	require.Eventually(t, func() bool { return api.registrationCount() == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, testHeartbeatInterval, clock.waitForTimer(t))
+	clock.Advance(testHeartbeatInterval)
+	api.waitForHeartbeats(t, 3)
+}
+
+func TestHeartbeatFailedReregistrationWaitsForInterval(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{
+		// registration fails permanently once the collector is removed
+		registrationStatus: func(n int32) int {
+			if n > 1 {
+				return http.StatusForbidden
+			}
+			return http.StatusOK
+		},
+		heartbeatStatus: func(n int32) int {
+			if n > 1 {
+				return http.StatusUnauthorized
+			}
+			return http.StatusNoContent
+		},
+	}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	defer shutdownExtension(t, se)
+
+	api.waitForHeartbeats(t, 1)
+	for i := int32(2); i <= 4; i++ {
+		clock.Advance(clock.waitForTimer(t))
+		api.waitForHeartbeats(t, i)
+		require.Eventually(t, func() bool { return api.registrationCount() == i }, 5*time.Second, time.Millisecond)
+		assert.Equal(t, testHeartbeatInterval, clock.waitForTimer(t))
+	}
+	// a single registration attempt is made for each rejected heartbeat
+	assert.Equal(t, int32(4), api.registrationCount())
+}
+
+func TestRegistrationBackoffSchedule(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{registrationStatus: func(n int32) int {
+		if n <= 3 {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	}}
+	se, clock := newTestExtensionWithClock(t, api)
+
+	started := make(chan error, 1)
+	go func() {
+		started <- se.Start(context.Background(), componenttest.NewNopHost())
+	}()
+	defer shutdownExtension(t, se)
+
+	// the intervals grow with the default multiplier of 1.5
+	for i, expected := range []time.Duration{time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond} {
+		assert.Equal(t, expected, clock.waitForTimer(t))
+		assert.Equal(t, int32(i+1), api.registrationCount())
+		clock.Advance(expected)
+	}
+
+	select {
+	case err := <-started:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("extension didn't start")
+	}
+	assert.Equal(t, int32(4), api.registrationCount())
+	api.waitForHeartbeats(t, 1)
+}
+
+func TestRegistrationBackoffMaxElapsedTime(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{registrationStatus: func(int32) int { return http.StatusTooManyRequests }}
+	se, clock := newTestExtensionWithClock(t, api)
+
+	started := make(chan error, 1)
+	go func() {
+		started <- se.Start(context.Background(), componenttest.NewNopHost())
+	}()
+
+	start := clock.Now()
+	var err error
+	require.Eventually(t, func() bool {
+		select {
+		case err = <-started:
+			return true
+		default:
+		}
+		if timers := clock.activeTimers(); len(timers) == 1 {
+			clock.Advance(timers[0])
+		}
+		return false
+	}, 5*time.Second, time.Millisecond, "registration wasn't given up")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collector registration failed")
+	// the retries stop once the next one would exceed the maximum elapsed time of a minute:
+	// 1s, 1.5s, 2.25s, 3.375s, 5.0625s, 7.59375s and 10s (the maximum interval) three times
+	assert.Equal(t, 10, int(api.registrationCount()))
+	assert.Equal(t, 50781250*time.Microsecond, clock.Now().Sub(start))
+	assert.Equal(t, int32(0), api.heartbeatCount())
+}
+
+func TestShutdownWhileWaitingForHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+
+	api.waitForHeartbeats(t, 1)
+	clock.waitForTimer(t)
+	shutdownExtension(t, se)
+
+	// no heartbeats are sent after the shutdown
+	clock.Advance(10 * testHeartbeatInterval)
+	assert.Empty(t, clock.activeTimers())
+	assert.Equal(t, int32(1), api.heartbeatCount())
+}
+
+func TestShutdownDuringReregistrationBackoff(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{
+		registrationStatus: func(n int32) int {
+			if n > 1 {
+				return http.StatusTooManyRequests
+			}
+			return http.StatusOK
+		},
+		heartbeatStatus: func(int32) int { return http.StatusUnauthorized },
+	}
+	se, clock := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+
+	// the heartbeat loop waits for the backoff of the second registration attempt
+	api.waitForHeartbeats(t, 1)
+	require.Eventually(t, func() bool {
+		timers := clock.activeTimers()
+		return api.registrationCount() == 2 && len(timers) == 2 && (timers[0] == time.Second || timers[1] == time.Second)
+	}, 5*time.Second, time.Millisecond)
+
+	shutdownExtension(t, se)
+	clock.Advance(time.Hour)
+	assert.Equal(t, int32(2), api.registrationCount())
+	assert.Equal(t, int32(1), api.heartbeatCount())
+}
+
+func TestConcurrentShutdown(t *testing.T) {
+	t.Parallel()
+
+	api := &testAPI{}
+	se, _ := newTestExtensionWithClock(t, api)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	api.waitForHeartbeats(t, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shutdownExtension(t, se)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestShutdownWithoutStart(t *testing.T) {
+	t.Parallel()
+
+	se, _ := newTestExtensionWithClock(t, &testAPI{})
+	shutdownExtension(t, se)
+}
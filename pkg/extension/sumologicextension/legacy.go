@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/api"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/credentials"
+)
+
+// Keys of the legacy installed collector credentials file.
+const (
+	legacyCollectorIdKey               = "collectorId"
+	legacyCollectorNameKey             = "collectorName"
+	legacyCollectorCredentialIdKey     = "collectorCredentialId"
+	legacyCollectorCredentialKeyKey    = "collectorCredentialKey"
+	legacyCollectorApiBaseUrlKey       = "apiBaseUrl"
+	legacyCollectorPropertiesSeparator = "="
+)
+
+// readLegacyCredentials reads the collector identity from the credentials file
+// of a legacy installed collector. The file is in the Java properties format,
+// i.e. a key=value pair on each line, with lines starting with # or ! being comments.
+func readLegacyCredentials(path string) (credentials.CollectorCredentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return credentials.CollectorCredentials{}, err
+	}
+	defer f.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		key, value, found := strings.Cut(line, legacyCollectorPropertiesSeparator)
+		if !found {
+			return credentials.CollectorCredentials{}, fmt.Errorf("invalid line in legacy collector credentials file: %q", line)
+		}
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return credentials.CollectorCredentials{}, err
+	}
+
+	for _, key := range []string{legacyCollectorIdKey, legacyCollectorCredentialIdKey, legacyCollectorCredentialKeyKey} {
+		if properties[key] == "" {
+			return credentials.CollectorCredentials{}, fmt.Errorf("legacy collector credentials file is missing %q", key)
+		}
+	}
+
+	return credentials.CollectorCredentials{
+		CollectorName: properties[legacyCollectorNameKey],
+		Credentials: api.OpenRegisterResponsePayload{
+			CollectorCredentialId:  properties[legacyCollectorCredentialIdKey],
+			CollectorCredentialKey: properties[legacyCollectorCredentialKeyKey],
+			CollectorId:            properties[legacyCollectorIdKey],
+			CollectorName:          properties[legacyCollectorNameKey],
+		},
+		ApiBaseUrl: strings.TrimSuffix(properties[legacyCollectorApiBaseUrlKey], "/"),
+	}, nil
+}
+
+// getLegacyCredentials adopts the identity of a legacy installed collector,
+// so the collector keeps its sources history after the migration instead of
+// registering as a new collector.
+// The credentials are validated and stored in the local credentials store,
+// so they're used from there on the next collector start.
+func (se *SumologicExtension) getLegacyCredentials(ctx context.Context) (credentials.CollectorCredentials, error) {
+	path := se.conf.LegacyCollectorCredentialsFile
+	colCreds, err := readLegacyCredentials(path)
+	if err != nil {
+		return credentials.CollectorCredentials{}, fmt.Errorf("problem reading legacy collector credentials (path: %s): %w", path, err)
+	}
+
+	if colCreds.CollectorName == "" {
+		colCreds.CollectorName = se.collectorName
+		colCreds.Credentials.CollectorName = se.collectorName
+	}
+	if colCreds.ApiBaseUrl == "" {
+		colCreds.ApiBaseUrl = se.BaseUrl()
+	}
+
+	// Validate against the deployment of the legacy collector, restoring
+	// the configured one when the credentials are not valid.
+	baseUrl := se.BaseUrl()
+	se.SetBaseUrl(colCreds.ApiBaseUrl)
+	if err := se.validateCredentials(ctx, colCreds); err != nil {
+		se.SetBaseUrl(baseUrl)
+		return credentials.CollectorCredentials{}, fmt.Errorf("legacy collector credentials invalid: %w", err)
+	}
+
+	se.collectorName = colCreds.CollectorName
+	if err := se.credentialsStore.Store(se.hashKey, colCreds); err != nil {
+		se.logger.Error(
+			"Unable to store adopted legacy collector credentials, they will be used now but won't be re-used on next run",
+			zap.Error(err),
+		)
+	}
+
+	return colCreds, nil
+}
+
+// legacyCredentialsPresent reports whether the legacy collector credentials file
+// is configured and present on the host.
+func (se *SumologicExtension) legacyCredentialsPresent() bool {
+	if se.conf.LegacyCollectorCredentialsFile == "" {
+		return false
+	}
+	_, err := os.Stat(se.conf.LegacyCollectorCredentialsFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		se.logger.Warn("Unable to check legacy collector credentials file", zap.Error(err))
+	}
+	return err == nil
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/api"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/sumologicextension/credentials"
+)
+
+func writeLegacyCredentials(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "legacy.properties")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestReadLegacyCredentials(t *testing.T) {
+	path := writeLegacyCredentials(t, `# migrated from the installed collector
+collectorId = 000000000FFFFFFF
+collectorName=legacy-collector
+collectorCredentialId=legacy-credential-id
+collectorCredentialKey=legacy-credential-key==
+! the deployment of the collector
+apiBaseUrl=https://open-collectors.eu.sumologic.com/
+`)
+
+	colCreds, err := readLegacyCredentials(path)
+	require.NoError(t, err)
+	assert.Equal(t, credentials.CollectorCredentials{
+		CollectorName: "legacy-collector",
+		Credentials: api.OpenRegisterResponsePayload{
+			CollectorCredentialId:  "legacy-credential-id",
+			CollectorCredentialKey: "legacy-credential-key==",
+			CollectorId:            "000000000FFFFFFF",
+			CollectorName:          "legacy-collector",
+		},
+		ApiBaseUrl: "https://open-collectors.eu.sumologic.com",
+	}, colCreds)
+}
+
+func TestReadLegacyCredentialsInvalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "missing credential key",
+			content: "collectorId=000000000FFFFFFF\ncollectorCredentialId=legacy-credential-id\n",
+		},
+		{
+			name:    "invalid line",
+			content: "collectorId 000000000FFFFFFF\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := readLegacyCredentials(writeLegacyCredentials(t, tc.content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := readLegacyCredentials(filepath.Join(t.TempDir(), "missing.properties"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLegacyCredentialsAreAdopted(t *testing.T) {
+	var reqCount int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&reqCount, 1)
+
+		require.NotEqual(t, registerUrl, req.URL.Path,
+			"collector shouldn't call the register API when legacy credentials are adopted")
+		token := base64.StdEncoding.EncodeToString([]byte("legacy-credential-id:legacy-credential-key"))
+		assert.Equal(t, "Basic "+token, req.Header.Get("Authorization"))
+		w.WriteHeader(204)
+	}))
+	t.Cleanup(func() { srv.Close() })
+
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "test-name"
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = dir
+	cfg.LegacyCollectorCredentialsFile = writeLegacyCredentials(t,
+		"collectorId=000000000FFFFFFF\ncollectorName=legacy-collector\n"+
+			"collectorCredentialId=legacy-credential-id\ncollectorCredentialKey=legacy-credential-key\n",
+	)
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, se.Shutdown(context.Background())) })
+
+	assert.Equal(t, "000000000FFFFFFF", se.CollectorID())
+	assert.Equal(t, "legacy-collector", se.collectorName)
+
+	// the adopted credentials are used from the local store on the next start
+	cStore, err := credentials.NewLocalFsStore(
+		credentials.WithCredentialsDirectory(dir),
+		credentials.WithLogger(zap.NewNop()),
+	)
+	require.NoError(t, err)
+	colCreds, err := cStore.Get(createHashKey(cfg))
+	require.NoError(t, err)
+	assert.Equal(t, "000000000FFFFFFF", colCreds.Credentials.CollectorId)
+	assert.Equal(t, srv.URL, colCreds.ApiBaseUrl)
+}
+
+func TestInvalidLegacyCredentialsFallBackToRegistration(t *testing.T) {
+	var registered int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case registerUrl:
+			atomic.AddInt32(&registered, 1)
+			_, err := w.Write([]byte(`{
+				"collectorCredentialId": "aaaaaaaaaaaaaaaaaaaa",
+				"collectorCredentialKey": "xxxxxxxxxxxxxxxxxxxx",
+				"collectorId": "000000000AAAAAAA"
+			}`))
+			require.NoError(t, err)
+		case heartbeatUrl:
+			token := base64.StdEncoding.EncodeToString([]byte("aaaaaaaaaaaaaaaaaaaa:xxxxxxxxxxxxxxxxxxxx"))
+			if req.Header.Get("Authorization") != "Basic "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(204)
+		}
+	}))
+	t.Cleanup(func() { srv.Close() })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectorName = "test-name"
+	cfg.ApiBaseUrl = srv.URL
+	cfg.Credentials.InstallToken = "dummy_install_token"
+	cfg.CollectorCredentialsDirectory = t.TempDir()
+	cfg.LegacyCollectorCredentialsFile = writeLegacyCredentials(t,
+		"collectorId=000000000FFFFFFF\ncollectorCredentialId=revoked-id\ncollectorCredentialKey=revoked-key\n",
+	)
+
+	se, err := newSumologicExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, se.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, se.Shutdown(context.Background())) })
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&registered))
+	assert.Equal(t, "000000000AAAAAAA", se.CollectorID())
+}
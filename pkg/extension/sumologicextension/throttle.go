@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"sync"
+	"time"
+)
+
+// throttleHook shares the feedback about requests sent to Sumo Logic between components:
+// the exporters report their requests and other components, e.g. processors adapting
+// to the backend, subscribe to the reports. The zero value is ready to use.
+type throttleHook struct {
+	mutex       sync.RWMutex
+	subscribers map[int]func(latency time.Duration, throttled bool)
+	nextID      int
+}
+
+func (h *throttleHook) report(latency time.Duration, throttled bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for _, callback := range h.subscribers {
+		callback(latency, throttled)
+	}
+}
+
+func (h *throttleHook) subscribe(callback func(latency time.Duration, throttled bool)) func() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.subscribers == nil {
+		h.subscribers = map[int]func(latency time.Duration, throttled bool){}
+	}
+	id := h.nextID
+	h.nextID++
+	h.subscribers[id] = callback
+
+	return func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		delete(h.subscribers, id)
+	}
+}
+
+// ReportExport reports a request sent to Sumo Logic to the subscribers.
+// The subscribers are called synchronously, so they must not block.
+func (se *SumologicExtension) ReportExport(latency time.Duration, throttled bool) {
+	se.throttleHook.report(latency, throttled)
+}
+
+// SubscribeExports registers the callback called for every reported request, throttled is set
+// if the request was rejected by the backend because of throttling.
+// The returned function cancels the subscription.
+func (se *SumologicExtension) SubscribeExports(callback func(latency time.Duration, throttled bool)) (unsubscribe func()) {
+	return se.throttleHook.subscribe(callback)
+}
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumologicextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleHook(t *testing.T) {
+	se := &SumologicExtension{}
+	// reporting without subscribers is a no-op
+	se.ReportExport(time.Second, false)
+
+	type report struct {
+		latency   time.Duration
+		throttled bool
+	}
+	var first, second []report
+	unsubscribeFirst := se.SubscribeExports(func(latency time.Duration, throttled bool) {
+		first = append(first, report{latency, throttled})
+	})
+	se.SubscribeExports(func(latency time.Duration, throttled bool) {
+		second = append(second, report{latency, throttled})
+	})
+
+	se.ReportExport(100*time.Millisecond, false)
+	unsubscribeFirst()
+	se.ReportExport(2*time.Second, true)
+
+	assert.Equal(t, []report{{100 * time.Millisecond, false}}, first)
+	assert.Equal(t, []report{{100 * time.Millisecond, false}, {2 * time.Second, true}}, second)
+}
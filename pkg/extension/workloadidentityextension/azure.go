@@ -0,0 +1,113 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	azureTenantIDEnv           = "AZURE_TENANT_ID"
+	azureClientIDEnv           = "AZURE_CLIENT_ID"
+	azureFederatedTokenFileEnv = "AZURE_FEDERATED_TOKEN_FILE"
+	azureAuthorityHostEnv      = "AZURE_AUTHORITY_HOST"
+)
+
+// azureSettings returns the settings with the empty ones taken from the environment.
+func (e *workloadIdentityExtension) azureSettings() AzureSettings {
+	s := e.cfg.Azure
+	fromEnv := func(value *string, key string) {
+		if *value == "" {
+			*value = e.getenv(key)
+		}
+	}
+	fromEnv(&s.TenantID, azureTenantIDEnv)
+	fromEnv(&s.ClientID, azureClientIDEnv)
+	fromEnv(&s.FederatedTokenFile, azureFederatedTokenFileEnv)
+	fromEnv(&s.AuthorityHost, azureAuthorityHostEnv)
+	if s.AuthorityHost == "" {
+		s.AuthorityHost = defaultAzureAuthorityHost
+	}
+	return s
+}
+
+// AzureToken uses workload identity federation if there's a federated token file
+// and the managed identity otherwise.
+func (e *workloadIdentityExtension) AzureToken(ctx context.Context, scope string) (Token, error) {
+	if scope == "" {
+		return Token{}, errors.New("scope cannot be empty")
+	}
+	return e.tokens.get(ctx, "azure/"+scope, func(ctx context.Context) (Token, error) {
+		s := e.azureSettings()
+		if s.FederatedTokenFile != "" {
+			return e.azureFederatedToken(ctx, s, scope)
+		}
+		return e.azureManagedIdentityToken(ctx, s, scope)
+	})
+}
+
+// azureFederatedToken exchanges the federated token for an access token of the client.
+// The file is read on every exchange, because it's rotated by Kubernetes.
+// Details: https://learn.microsoft.com/en-us/azure/active-directory/develop/workload-identity-federation
+func (e *workloadIdentityExtension) azureFederatedToken(ctx context.Context, s AzureSettings, scope string) (Token, error) {
+	if s.TenantID == "" || s.ClientID == "" {
+		return Token{}, errors.New("azure tenant_id and client_id are required with a federated token file")
+	}
+	assertion, err := os.ReadFile(s.FederatedTokenFile)
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to read the federated token file: %w", err)
+	}
+
+	form := url.Values{
+		"client_id":             {s.ClientID},
+		"scope":                 {scope},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	u := strings.TrimSuffix(s.AuthorityHost, "/") + "/" + url.PathEscape(s.TenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doTokenRequest(e.client, req, e.tokens.now())
+}
+
+// azureManagedIdentityToken fetches the token from the instance metadata service,
+// which takes a resource instead of a scope.
+// Details: https://learn.microsoft.com/en-us/azure/active-directory/managed-identities-azure-resources/how-to-use-vm-token
+func (e *workloadIdentityExtension) azureManagedIdentityToken(ctx context.Context, s AzureSettings, scope string) (Token, error) {
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {strings.TrimSuffix(scope, "/.default")},
+	}
+	if s.ClientID != "" {
+		query.Set("client_id", s.ClientID)
+	}
+	u := strings.TrimSuffix(s.IMDSEndpoint, "/") + "/metadata/identity/oauth2/token?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Metadata", "true")
+	return doTokenRequest(e.client, req, e.tokens.now())
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAzureFederatedToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/tenant-id/oauth2/v2.0/token", req.URL.Path)
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, "client-id", req.PostForm.Get("client_id"))
+		assert.Equal(t, "https://storage.azure.com/.default", req.PostForm.Get("scope"))
+		assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+		assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", req.PostForm.Get("client_assertion_type"))
+		assert.Equal(t, "federated-token", req.PostForm.Get("client_assertion"))
+		_, _ = w.Write([]byte(`{"access_token":"azure-token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated-token\n"), 0600))
+
+	// the settings are taken from the environment variables set by AKS workload identity
+	env := map[string]string{
+		azureTenantIDEnv:           "tenant-id",
+		azureClientIDEnv:           "client-id",
+		azureFederatedTokenFileEnv: tokenFile,
+		azureAuthorityHostEnv:      srv.URL + "/",
+	}
+	e := newWorkloadIdentityExtension(createDefaultConfig().(*Config), zap.NewNop())
+	e.getenv = func(key string) string { return env[key] }
+
+	token, err := e.AzureToken(context.Background(), "https://storage.azure.com/.default")
+	require.NoError(t, err)
+	assert.Equal(t, "azure-token", token.AccessToken)
+}
+
+func TestAzureFederatedTokenRequiresClient(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Azure.FederatedTokenFile = "/var/run/secrets/azure/tokens/azure-identity-token"
+	e := newWorkloadIdentityExtension(cfg, zap.NewNop())
+	e.getenv = func(string) string { return "" }
+
+	_, err := e.AzureToken(context.Background(), "https://storage.azure.com/.default")
+	assert.EqualError(t, err, "azure tenant_id and client_id are required with a federated token file")
+}
+
+func TestAzureManagedIdentityToken(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "true", req.Header.Get("Metadata"))
+		assert.Equal(t, "/metadata/identity/oauth2/token", req.URL.Path)
+		assert.Equal(t, "2018-02-01", req.URL.Query().Get("api-version"))
+		assert.Equal(t, "user-assigned-client-id", req.URL.Query().Get("client_id"))
+		resource := req.URL.Query().Get("resource")
+		_, _ = w.Write([]byte(`{"access_token":"` + resource + `","expires_in":"86399","resource":"` + resource + `"}`))
+	}))
+	defer srv.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Azure.IMDSEndpoint = srv.URL
+	cfg.Azure.ClientID = "user-assigned-client-id"
+	e := newWorkloadIdentityExtension(cfg, zap.NewNop())
+	e.getenv = func(string) string { return "" }
+
+	token, err := e.AzureToken(context.Background(), "https://storage.azure.com/.default")
+	require.NoError(t, err)
+	assert.Equal(t, "https://storage.azure.com", token.AccessToken)
+
+	// tokens are cached per scope
+	token, err = e.AzureToken(context.Background(), "https://vault.azure.net")
+	require.NoError(t, err)
+	assert.Equal(t, "https://vault.azure.net", token.AccessToken)
+	_, err = e.AzureToken(context.Background(), "https://storage.azure.com/.default")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	_, err = e.AzureToken(context.Background(), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the workload identity extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	AWS   AWSSettings   `mapstructure:"aws"`
+	GCP   GCPSettings   `mapstructure:"gcp"`
+	Azure AzureSettings `mapstructure:"azure"`
+	// RefreshMargin is the time before the expiry of a cached GCP or Azure token
+	// after which the token is refreshed.
+	RefreshMargin time.Duration `mapstructure:"refresh_margin"`
+}
+
+// AWSSettings configure the default AWS credential chain, which covers IAM roles
+// for service accounts (IRSA), ECS task roles and EC2 instance profiles.
+type AWSSettings struct {
+	// Region overrides the region from the environment and the shared config.
+	Region string `mapstructure:"region"`
+	// Profile is the name of the shared config profile.
+	Profile string `mapstructure:"profile"`
+}
+
+// GCPSettings configure fetching tokens from the GCP metadata server, which serves the tokens
+// of the workload identity on GKE and of the attached service account on GCE.
+type GCPSettings struct {
+	MetadataEndpoint string `mapstructure:"metadata_endpoint"`
+	ServiceAccount   string `mapstructure:"service_account"`
+	// Scopes limit the scopes of the tokens, the default scopes of the service account are used if it's empty.
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// AzureSettings configure acquiring Azure tokens. With a federated token file, which is set up
+// by AKS workload identity, the file's token is exchanged for an access token of the client.
+// Otherwise a managed identity token is fetched from the instance metadata service.
+// The empty settings are taken from the environment variables set by AKS workload identity.
+type AzureSettings struct {
+	// TenantID defaults to AZURE_TENANT_ID.
+	TenantID string `mapstructure:"tenant_id"`
+	// ClientID defaults to AZURE_CLIENT_ID, it also selects the user-assigned managed identity.
+	ClientID string `mapstructure:"client_id"`
+	// FederatedTokenFile defaults to AZURE_FEDERATED_TOKEN_FILE.
+	FederatedTokenFile string `mapstructure:"federated_token_file"`
+	// AuthorityHost defaults to AZURE_AUTHORITY_HOST or to the Azure public cloud.
+	AuthorityHost string `mapstructure:"authority_host"`
+	IMDSEndpoint  string `mapstructure:"imds_endpoint"`
+}
+
+const (
+	defaultGCPMetadataEndpoint = "http://metadata.google.internal"
+	defaultGCPServiceAccount   = "default"
+	defaultAzureAuthorityHost  = "https://login.microsoftonline.com/"
+	defaultAzureIMDSEndpoint   = "http://169.254.169.254"
+	defaultRefreshMargin       = 5 * time.Minute
+)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.RefreshMargin < 0 {
+		return errors.New("refresh_margin cannot be negative")
+	}
+	if cfg.GCP.ServiceAccount == "" {
+		return errors.New("gcp service_account cannot be empty")
+	}
+	if err := validateEndpoint("gcp metadata_endpoint", cfg.GCP.MetadataEndpoint); err != nil {
+		return err
+	}
+	if err := validateEndpoint("azure imds_endpoint", cfg.Azure.IMDSEndpoint); err != nil {
+		return err
+	}
+	// the authority host can be set with the environment variable
+	if cfg.Azure.AuthorityHost != "" {
+		if err := validateEndpoint("azure authority_host", cfg.Azure.AuthorityHost); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEndpoint(name string, endpoint string) error {
+	if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute URL", name)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg.Extensions[config.NewComponentID(typeStr)])
+
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			AWS: AWSSettings{
+				Region:  "us-east-2",
+				Profile: "collector",
+			},
+			GCP: GCPSettings{
+				MetadataEndpoint: defaultGCPMetadataEndpoint,
+				ServiceAccount:   "collector@project.iam.gserviceaccount.com",
+				Scopes:           []string{"https://www.googleapis.com/auth/cloud-platform"},
+			},
+			Azure: AzureSettings{
+				TenantID:           "tenant-id",
+				ClientID:           "client-id",
+				FederatedTokenFile: "/var/run/secrets/azure/tokens/azure-identity-token",
+				IMDSEndpoint:       defaultAzureIMDSEndpoint,
+			},
+			RefreshMargin: time.Minute,
+		},
+		cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")],
+	)
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name          string
+		modify        func(*Config)
+		expectedError string
+	}{
+		{
+			name:          "negative refresh margin",
+			modify:        func(cfg *Config) { cfg.RefreshMargin = -time.Second },
+			expectedError: "refresh_margin cannot be negative",
+		},
+		{
+			name:          "empty service account",
+			modify:        func(cfg *Config) { cfg.GCP.ServiceAccount = "" },
+			expectedError: "gcp service_account cannot be empty",
+		},
+		{
+			name:          "relative metadata endpoint",
+			modify:        func(cfg *Config) { cfg.GCP.MetadataEndpoint = "metadata.google.internal" },
+			expectedError: "gcp metadata_endpoint must be an absolute URL",
+		},
+		{
+			name:          "empty imds endpoint",
+			modify:        func(cfg *Config) { cfg.Azure.IMDSEndpoint = "" },
+			expectedError: "azure imds_endpoint must be an absolute URL",
+		},
+		{
+			name:          "invalid authority host",
+			modify:        func(cfg *Config) { cfg.Azure.AuthorityHost = "login.microsoftonline.com" },
+			expectedError: "azure authority_host must be an absolute URL",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.EqualError(t, cfg.Validate(), tc.expectedError)
+		})
+	}
+}
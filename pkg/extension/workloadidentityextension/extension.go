@@ -0,0 +1,129 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// CredentialsProvider is implemented by the workload identity extension.
+// Components which access cloud APIs look it up in the host's extensions,
+// so that the credentials are acquired the same way, and only once, in the whole collector.
+type CredentialsProvider interface {
+	component.Extension
+
+	// AWSConfig returns the AWS config with the credentials of the default credential chain.
+	// The credentials are cached and refreshed by the AWS SDK.
+	AWSConfig(ctx context.Context) (aws.Config, error)
+
+	// GCPToken returns an access token of the GCP workload identity or service account.
+	GCPToken(ctx context.Context) (Token, error)
+
+	// AzureToken returns an access token of the Azure workload identity or managed identity
+	// for the scope, e.g. https://storage.azure.com/.default.
+	AzureToken(ctx context.Context, scope string) (Token, error)
+}
+
+// Token is an OAuth 2.0 access token.
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+type workloadIdentityExtension struct {
+	cfg    *Config
+	logger *zap.Logger
+	client *http.Client
+	tokens *tokenCache
+
+	awsMutex  sync.Mutex
+	awsConfig *aws.Config
+	// loadAWSConfig is awsconfig.LoadDefaultConfig, it's replaced in tests.
+	loadAWSConfig func(ctx context.Context, optFns ...func(*awsconfig.LoadOptions) error) (aws.Config, error)
+	// getenv is os.Getenv, it's replaced in tests.
+	getenv func(key string) string
+}
+
+var _ CredentialsProvider = (*workloadIdentityExtension)(nil)
+
+func newWorkloadIdentityExtension(cfg *Config, logger *zap.Logger) *workloadIdentityExtension {
+	return &workloadIdentityExtension{
+		cfg:    cfg,
+		logger: logger,
+		client: &http.Client{Timeout: 30 * time.Second},
+		tokens: newTokenCache(cfg.RefreshMargin),
+
+		loadAWSConfig: awsconfig.LoadDefaultConfig,
+		getenv:        os.Getenv,
+	}
+}
+
+func (e *workloadIdentityExtension) Start(_ context.Context, _ component.Host) error {
+	return nil
+}
+
+func (e *workloadIdentityExtension) Shutdown(_ context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// AWSConfig loads the config on the first call, failed loads are retried on the next calls.
+func (e *workloadIdentityExtension) AWSConfig(ctx context.Context) (aws.Config, error) {
+	e.awsMutex.Lock()
+	defer e.awsMutex.Unlock()
+
+	if e.awsConfig != nil {
+		return *e.awsConfig, nil
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if e.cfg.AWS.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(e.cfg.AWS.Region))
+	}
+	if e.cfg.AWS.Profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(e.cfg.AWS.Profile))
+	}
+	cfg, err := e.loadAWSConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	e.logger.Debug("AWS config loaded", zap.String("region", cfg.Region))
+	e.awsConfig = &cfg
+	return cfg, nil
+}
+
+// GetCredentialsProvider returns the workload identity extension with the given id from the host.
+func GetCredentialsProvider(host component.Host, id config.ComponentID) (CredentialsProvider, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found", id)
+	}
+	provider, ok := ext.(CredentialsProvider)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a credentials provider", id)
+	}
+	return provider, nil
+}
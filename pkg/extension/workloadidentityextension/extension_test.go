@@ -0,0 +1,91 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+func TestAWSConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AWS.Region = "eu-central-1"
+	cfg.AWS.Profile = "collector"
+	e := newWorkloadIdentityExtension(cfg, zap.NewNop())
+
+	loads := 0
+	e.loadAWSConfig = func(ctx context.Context, optFns ...func(*awsconfig.LoadOptions) error) (aws.Config, error) {
+		loads++
+		if loads == 1 {
+			return aws.Config{}, errors.New("no credentials")
+		}
+		var opts awsconfig.LoadOptions
+		for _, fn := range optFns {
+			require.NoError(t, fn(&opts))
+		}
+		assert.Equal(t, "collector", opts.SharedConfigProfile)
+		return aws.Config{Region: opts.Region}, nil
+	}
+
+	_, err := e.AWSConfig(context.Background())
+	assert.EqualError(t, err, "failed to load AWS config: no credentials")
+
+	for i := 0; i < 2; i++ {
+		awsCfg, err := e.AWSConfig(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "eu-central-1", awsCfg.Region)
+	}
+	assert.Equal(t, 2, loads)
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h hostWithExtensions) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestGetCredentialsProvider(t *testing.T) {
+	e := newWorkloadIdentityExtension(createDefaultConfig().(*Config), zap.NewNop())
+	host := hostWithExtensions{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID(typeStr): e,
+			config.NewComponentID("other"): &struct{ component.Extension }{},
+		},
+	}
+
+	provider, err := GetCredentialsProvider(host, config.NewComponentID(typeStr))
+	require.NoError(t, err)
+	assert.Equal(t, e, provider)
+
+	_, err = GetCredentialsProvider(host, config.NewComponentID("other"))
+	assert.EqualError(t, err, `extension "other" is not a credentials provider`)
+
+	_, err = GetCredentialsProvider(host, config.NewComponentID("missing"))
+	assert.EqualError(t, err, `extension "missing" not found`)
+}
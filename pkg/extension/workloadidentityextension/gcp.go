@@ -0,0 +1,41 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GCPToken fetches the token from the metadata server.
+// Details: https://cloud.google.com/compute/docs/access/authenticate-workloads#applications
+func (e *workloadIdentityExtension) GCPToken(ctx context.Context) (Token, error) {
+	return e.tokens.get(ctx, "gcp", func(ctx context.Context) (Token, error) {
+		u := strings.TrimSuffix(e.cfg.GCP.MetadataEndpoint, "/") +
+			"/computeMetadata/v1/instance/service-accounts/" + url.PathEscape(e.cfg.GCP.ServiceAccount) + "/token"
+		if len(e.cfg.GCP.Scopes) > 0 {
+			u += "?" + url.Values{"scopes": {strings.Join(e.cfg.GCP.Scopes, ",")}}.Encode()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return Token{}, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		return doTokenRequest(e.client, req, e.tokens.now())
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGCPToken(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "Google", req.Header.Get("Metadata-Flavor"))
+		assert.Equal(t, "/computeMetadata/v1/instance/service-accounts/collector@project.iam.gserviceaccount.com/token", req.URL.Path)
+		assert.Equal(t, "https://www.googleapis.com/auth/logging.read,https://www.googleapis.com/auth/pubsub", req.URL.Query().Get("scopes"))
+		_, _ = w.Write([]byte(`{"access_token":"gcp-token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer srv.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.GCP.MetadataEndpoint = srv.URL + "/"
+	cfg.GCP.ServiceAccount = "collector@project.iam.gserviceaccount.com"
+	cfg.GCP.Scopes = []string{"https://www.googleapis.com/auth/logging.read", "https://www.googleapis.com/auth/pubsub"}
+	e := newWorkloadIdentityExtension(cfg, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		token, err := e.GCPToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "gcp-token", token.AccessToken)
+	}
+	assert.Equal(t, 1, requests)
+}
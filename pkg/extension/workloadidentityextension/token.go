@@ -0,0 +1,100 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenCache keeps the tokens until the refresh margin before their expiry.
+type tokenCache struct {
+	margin time.Duration
+
+	// mutex is held while fetching, so that concurrent callers don't fetch the same token
+	mutex  sync.Mutex
+	tokens map[string]Token
+	now    func() time.Time
+}
+
+func newTokenCache(margin time.Duration) *tokenCache {
+	return &tokenCache{
+		margin: margin,
+		tokens: map[string]Token{},
+		now:    time.Now,
+	}
+}
+
+// get returns the cached token for the key, or fetches a new one if it's missing or about to expire.
+func (c *tokenCache) get(ctx context.Context, key string, fetch func(ctx context.Context) (Token, error)) (Token, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if token, ok := c.tokens[key]; ok && c.now().Add(c.margin).Before(token.ExpiresAt) {
+		return token, nil
+	}
+
+	token, err := fetch(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	c.tokens[key] = token
+	return token, nil
+}
+
+// tokenResponse is the OAuth 2.0 token response. Azure IMDS sends expires_in as a string,
+// while the other endpoints send it as a number.
+type tokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+}
+
+// doTokenRequest sends the request and converts the token response to a token.
+func doTokenRequest(client *http.Client, req *http.Request, now time.Time) (Token, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Token{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("token request to %s failed with status %s: %s", req.URL.Host, resp.Status, body)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return Token{}, fmt.Errorf("failed to decode the token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return Token{}, fmt.Errorf("token response from %s doesn't contain an access token", req.URL.Host)
+	}
+	expiresIn, err := tr.ExpiresIn.Int64()
+	if err != nil {
+		return Token{}, fmt.Errorf("invalid expires_in in the token response: %w", err)
+	}
+	return Token{
+		AccessToken: tr.AccessToken,
+		ExpiresAt:   now.Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
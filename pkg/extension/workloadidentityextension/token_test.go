@@ -0,0 +1,131 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workloadidentityextension
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	cache := newTokenCache(5 * time.Minute)
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func(context.Context) (Token, error) {
+		fetches++
+		return Token{AccessToken: "token", ExpiresAt: now.Add(time.Hour)}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := cache.get(context.Background(), "key", fetch)
+		require.NoError(t, err)
+		assert.Equal(t, "token", token.AccessToken)
+	}
+	assert.Equal(t, 1, fetches)
+
+	// other keys are cached separately
+	_, err := cache.get(context.Background(), "other", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// the token is refreshed within the margin before its expiry
+	now = now.Add(56 * time.Minute)
+	_, err = cache.get(context.Background(), "key", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+
+	// errors aren't cached
+	now = now.Add(2 * time.Hour)
+	_, err = cache.get(context.Background(), "key", func(context.Context) (Token, error) {
+		return Token{}, errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+	_, err = cache.get(context.Background(), "key", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 4, fetches)
+}
+
+func TestDoTokenRequest(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		name          string
+		status        int
+		body          string
+		expectedToken Token
+		expectedError string
+	}{
+		{
+			name:          "numeric expires_in",
+			status:        200,
+			body:          `{"access_token":"abc","expires_in":3599,"token_type":"Bearer"}`,
+			expectedToken: Token{AccessToken: "abc", ExpiresAt: now.Add(3599 * time.Second)},
+		},
+		{
+			name:          "string expires_in",
+			status:        200,
+			body:          `{"access_token":"abc","expires_in":"3599","expires_on":"1659351599"}`,
+			expectedToken: Token{AccessToken: "abc", ExpiresAt: now.Add(3599 * time.Second)},
+		},
+		{
+			name:          "error status",
+			status:        400,
+			body:          `{"error":"invalid_request"}`,
+			expectedError: "failed with status 400 Bad Request",
+		},
+		{
+			name:          "missing access token",
+			status:        200,
+			body:          `{"expires_in":3599}`,
+			expectedError: "doesn't contain an access token",
+		},
+		{
+			name:          "invalid body",
+			status:        200,
+			body:          `<html>`,
+			expectedError: "failed to decode the token response",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			token, err := doTokenRequest(srv.Client(), req, now)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedToken, token)
+		})
+	}
+}
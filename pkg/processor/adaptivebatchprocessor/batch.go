@@ -0,0 +1,115 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// batch accumulates the data of a single signal type.
+type batch interface {
+	// add moves the data into the batch.
+	add(data interface{})
+	// itemCount returns the number of log records, metric data points or spans in the batch.
+	itemCount() int
+	// export sends the batch to the next consumer and starts a new one.
+	export(ctx context.Context) error
+}
+
+type logsBatch struct {
+	next  consumer.Logs
+	logs  plog.Logs
+	count int
+}
+
+func newLogsBatch(next consumer.Logs) *logsBatch {
+	return &logsBatch{next: next, logs: plog.NewLogs()}
+}
+
+func (b *logsBatch) add(data interface{}) {
+	ld := data.(plog.Logs)
+	b.count += ld.LogRecordCount()
+	ld.ResourceLogs().MoveAndAppendTo(b.logs.ResourceLogs())
+}
+
+func (b *logsBatch) itemCount() int {
+	return b.count
+}
+
+func (b *logsBatch) export(ctx context.Context) error {
+	ld := b.logs
+	b.logs = plog.NewLogs()
+	b.count = 0
+	return b.next.ConsumeLogs(ctx, ld)
+}
+
+type metricsBatch struct {
+	next    consumer.Metrics
+	metrics pmetric.Metrics
+	count   int
+}
+
+func newMetricsBatch(next consumer.Metrics) *metricsBatch {
+	return &metricsBatch{next: next, metrics: pmetric.NewMetrics()}
+}
+
+func (b *metricsBatch) add(data interface{}) {
+	md := data.(pmetric.Metrics)
+	b.count += md.DataPointCount()
+	md.ResourceMetrics().MoveAndAppendTo(b.metrics.ResourceMetrics())
+}
+
+func (b *metricsBatch) itemCount() int {
+	return b.count
+}
+
+func (b *metricsBatch) export(ctx context.Context) error {
+	md := b.metrics
+	b.metrics = pmetric.NewMetrics()
+	b.count = 0
+	return b.next.ConsumeMetrics(ctx, md)
+}
+
+type tracesBatch struct {
+	next   consumer.Traces
+	traces ptrace.Traces
+	count  int
+}
+
+func newTracesBatch(next consumer.Traces) *tracesBatch {
+	return &tracesBatch{next: next, traces: ptrace.NewTraces()}
+}
+
+func (b *tracesBatch) add(data interface{}) {
+	td := data.(ptrace.Traces)
+	b.count += td.SpanCount()
+	td.ResourceSpans().MoveAndAppendTo(b.traces.ResourceSpans())
+}
+
+func (b *tracesBatch) itemCount() int {
+	return b.count
+}
+
+func (b *tracesBatch) export(ctx context.Context) error {
+	td := b.traces
+	b.traces = ptrace.NewTraces()
+	b.count = 0
+	return b.next.ConsumeTraces(ctx, td)
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// MinBatchSize and MaxBatchSize bound the tuned number of items
+	// (log records, spans or metric data points) after which a batch is sent.
+	MinBatchSize int `mapstructure:"min_batch_size"`
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+	// MinTimeout and MaxTimeout bound the tuned time after which a batch is sent
+	// regardless of its size.
+	MinTimeout time.Duration `mapstructure:"min_timeout"`
+	MaxTimeout time.Duration `mapstructure:"max_timeout"`
+	// TargetLatency is the export latency above which the batch size is decreased.
+	TargetLatency time.Duration `mapstructure:"target_latency"`
+	// ThrottleHook is the id of the extension sharing the feedback about exports,
+	// e.g. sumologicextension. When it's not set, the latency of the next consumer is measured.
+	ThrottleHook *config.ComponentID `mapstructure:"throttle_hook"`
+}
+
+const (
+	defaultMinBatchSize  = 128
+	defaultMaxBatchSize  = 8192
+	defaultMinTimeout    = 200 * time.Millisecond
+	defaultMaxTimeout    = 10 * time.Second
+	defaultTargetLatency = time.Second
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		MinBatchSize:      defaultMinBatchSize,
+		MaxBatchSize:      defaultMaxBatchSize,
+		MinTimeout:        defaultMinTimeout,
+		MaxTimeout:        defaultMaxTimeout,
+		TargetLatency:     defaultTargetLatency,
+	}
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	if cfg.MinBatchSize <= 0 {
+		return errors.New("min_batch_size must be positive")
+	}
+	if cfg.MaxBatchSize < cfg.MinBatchSize {
+		return errors.New("max_batch_size cannot be lower than min_batch_size")
+	}
+	if cfg.MinTimeout <= 0 {
+		return errors.New("min_timeout must be positive")
+	}
+	if cfg.MaxTimeout < cfg.MinTimeout {
+		return errors.New("max_timeout cannot be lower than min_timeout")
+	}
+	if cfg.TargetLatency <= 0 {
+		return errors.New("target_latency must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	hookID := config.NewComponentID("sumologic")
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			MinBatchSize:      500,
+			MaxBatchSize:      20000,
+			MinTimeout:        time.Second,
+			MaxTimeout:        30 * time.Second,
+			TargetLatency:     2 * time.Second,
+			ThrottleHook:      &hookID,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "zero min batch size", modify: func(cfg *Config) { cfg.MinBatchSize = 0 }},
+		{name: "max batch size lower than min", modify: func(cfg *Config) { cfg.MaxBatchSize = cfg.MinBatchSize - 1 }},
+		{name: "zero min timeout", modify: func(cfg *Config) { cfg.MinTimeout = 0 }},
+		{name: "max timeout lower than min", modify: func(cfg *Config) { cfg.MaxTimeout = cfg.MinTimeout - 1 }},
+		{name: "zero target latency", modify: func(cfg *Config) { cfg.TargetLatency = 0 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+	assert.NoError(t, createDefaultConfig().(*Config).Validate())
+}
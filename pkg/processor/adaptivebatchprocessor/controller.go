@@ -0,0 +1,106 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"sync"
+	"time"
+)
+
+// latencySmoothing is the weight of a new latency observation in the moving average.
+const latencySmoothing = 0.3
+
+// controller tunes the batch size and timeout from the feedback about exports:
+//   - when an export is throttled, both are doubled, so that fewer requests are sent,
+//   - when the average latency exceeds the target, the batch size is decreased by a quarter,
+//   - otherwise the batch size grows by an eighth and the timeout shrinks by an eighth,
+//     which increases throughput until the backend starts to slow down.
+type controller struct {
+	cfg *Config
+
+	mutex     sync.Mutex
+	batchSize int
+	timeout   time.Duration
+	// latency is the exponential moving average of the observed latencies, 0 until the first observation.
+	latency time.Duration
+}
+
+func newController(cfg *Config) *controller {
+	return &controller{
+		cfg:       cfg,
+		batchSize: cfg.MinBatchSize,
+		timeout:   cfg.MinTimeout,
+	}
+}
+
+// settings returns the current batch size and timeout.
+func (c *controller) settings() (int, time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.batchSize, c.timeout
+}
+
+// observe tunes the settings after an export which took latency.
+func (c *controller) observe(latency time.Duration, throttled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if throttled {
+		c.batchSize = minInt(c.batchSize*2, c.cfg.MaxBatchSize)
+		c.timeout = minDuration(c.timeout*2, c.cfg.MaxTimeout)
+		return
+	}
+
+	if c.latency == 0 {
+		c.latency = latency
+	} else {
+		c.latency += time.Duration(latencySmoothing * float64(latency-c.latency))
+	}
+
+	if c.latency > c.cfg.TargetLatency {
+		c.batchSize = maxInt(c.batchSize*3/4, c.cfg.MinBatchSize)
+		return
+	}
+	c.batchSize = minInt(c.batchSize+maxInt(c.batchSize/8, 1), c.cfg.MaxBatchSize)
+	c.timeout = maxDuration(c.timeout*7/8, c.cfg.MinTimeout)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerGrowsWithLowLatency(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinBatchSize = 100
+	cfg.MaxBatchSize = 200
+	cfg.MinTimeout = time.Second
+	cfg.MaxTimeout = 8 * time.Second
+	c := newController(cfg)
+
+	batchSize, timeout := c.settings()
+	assert.Equal(t, 100, batchSize)
+	assert.Equal(t, time.Second, timeout)
+
+	c.observe(100*time.Millisecond, false)
+	batchSize, _ = c.settings()
+	assert.Equal(t, 112, batchSize)
+
+	for i := 0; i < 10; i++ {
+		c.observe(100*time.Millisecond, false)
+	}
+	batchSize, timeout = c.settings()
+	assert.Equal(t, 200, batchSize)
+	assert.Equal(t, time.Second, timeout)
+}
+
+func TestControllerShrinksWithHighLatency(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinBatchSize = 100
+	cfg.MaxBatchSize = 1000
+	cfg.TargetLatency = time.Second
+	c := newController(cfg)
+	c.batchSize = 800
+
+	c.observe(2*time.Second, false)
+	batchSize, _ := c.settings()
+	assert.Equal(t, 600, batchSize)
+
+	// a single fast export doesn't bring the average below the target
+	c.observe(100*time.Millisecond, false)
+	batchSize, _ = c.settings()
+	assert.Equal(t, 450, batchSize)
+
+	for i := 0; i < 10; i++ {
+		c.observe(2*time.Second, false)
+	}
+	batchSize, _ = c.settings()
+	assert.Equal(t, 100, batchSize)
+}
+
+func TestControllerBacksOffWhenThrottled(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinBatchSize = 100
+	cfg.MaxBatchSize = 300
+	cfg.MinTimeout = time.Second
+	cfg.MaxTimeout = 3 * time.Second
+	c := newController(cfg)
+
+	c.observe(0, true)
+	batchSize, timeout := c.settings()
+	assert.Equal(t, 200, batchSize)
+	assert.Equal(t, 2*time.Second, timeout)
+
+	c.observe(0, true)
+	batchSize, timeout = c.settings()
+	assert.Equal(t, 300, batchSize)
+	assert.Equal(t, 3*time.Second, timeout)
+
+	// the timeout recovers once exports succeed again
+	c.observe(100*time.Millisecond, false)
+	_, timeout = c.settings()
+	assert.Equal(t, 2625*time.Millisecond, timeout)
+}
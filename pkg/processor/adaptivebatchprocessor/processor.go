@@ -0,0 +1,187 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// throttleHook is implemented by the extensions sharing the feedback about exports,
+// e.g. sumologicextension.
+type throttleHook interface {
+	SubscribeExports(callback func(latency time.Duration, throttled bool)) (unsubscribe func())
+}
+
+// adaptiveBatchProcessor batches the data of a single signal type and sends the batches
+// once they reach the batch size or the timeout passes, both tuned by the controller.
+type adaptiveBatchProcessor struct {
+	logger     *zap.Logger
+	cfg        *Config
+	controller *controller
+	batch      batch
+
+	// measureLatency is set if there's no throttle hook, the latency of the next consumer
+	// is fed to the controller instead.
+	measureLatency bool
+	unsubscribe    func()
+
+	newItems   chan interface{}
+	shutdownC  chan struct{}
+	goroutines sync.WaitGroup
+}
+
+var (
+	_ component.LogsProcessor    = (*adaptiveBatchProcessor)(nil)
+	_ component.MetricsProcessor = (*adaptiveBatchProcessor)(nil)
+	_ component.TracesProcessor  = (*adaptiveBatchProcessor)(nil)
+)
+
+func newAdaptiveBatchProcessor(cfg *Config, logger *zap.Logger, b batch) *adaptiveBatchProcessor {
+	return &adaptiveBatchProcessor{
+		logger:     logger,
+		cfg:        cfg,
+		controller: newController(cfg),
+		batch:      b,
+		newItems:   make(chan interface{}, runtime.NumCPU()),
+		shutdownC:  make(chan struct{}),
+	}
+}
+
+func (p *adaptiveBatchProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+// Start subscribes to the throttle hook, if it's configured, and starts batching.
+func (p *adaptiveBatchProcessor) Start(_ context.Context, host component.Host) error {
+	p.measureLatency = true
+	if p.cfg.ThrottleHook != nil {
+		ext, ok := host.GetExtensions()[*p.cfg.ThrottleHook]
+		if !ok {
+			return fmt.Errorf("extension %q not found", *p.cfg.ThrottleHook)
+		}
+		hook, ok := ext.(throttleHook)
+		if !ok {
+			return fmt.Errorf("extension %q doesn't provide a throttle hook", *p.cfg.ThrottleHook)
+		}
+		p.unsubscribe = hook.SubscribeExports(p.controller.observe)
+		p.measureLatency = false
+	}
+
+	p.goroutines.Add(1)
+	go p.run()
+	return nil
+}
+
+// Shutdown sends the remaining data and stops batching.
+func (p *adaptiveBatchProcessor) Shutdown(context.Context) error {
+	close(p.shutdownC)
+	p.goroutines.Wait()
+	if p.unsubscribe != nil {
+		p.unsubscribe()
+	}
+	return nil
+}
+
+func (p *adaptiveBatchProcessor) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	p.newItems <- ld
+	return nil
+}
+
+func (p *adaptiveBatchProcessor) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	p.newItems <- md
+	return nil
+}
+
+func (p *adaptiveBatchProcessor) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	p.newItems <- td
+	return nil
+}
+
+func (p *adaptiveBatchProcessor) run() {
+	defer p.goroutines.Done()
+
+	_, timeout := p.controller.settings()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-p.shutdownC:
+		DRAIN:
+			for {
+				select {
+				case data := <-p.newItems:
+					p.batch.add(data)
+				default:
+					break DRAIN
+				}
+			}
+			if p.batch.itemCount() > 0 {
+				p.export()
+			}
+			return
+
+		case data := <-p.newItems:
+			p.batch.add(data)
+			batchSize, _ := p.controller.settings()
+			if p.batch.itemCount() < batchSize {
+				continue
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			p.export()
+			_, timeout := p.controller.settings()
+			timer.Reset(timeout)
+
+		case <-timer.C:
+			if p.batch.itemCount() > 0 {
+				p.export()
+			}
+			_, timeout := p.controller.settings()
+			timer.Reset(timeout)
+		}
+	}
+}
+
+func (p *adaptiveBatchProcessor) export() {
+	count := p.batch.itemCount()
+	start := time.Now()
+	err := p.batch.export(context.Background())
+	if p.measureLatency {
+		p.controller.observe(time.Since(start), false)
+	}
+	if err != nil {
+		p.logger.Warn("Sender failed", zap.Error(err))
+	}
+
+	batchSize, timeout := p.controller.settings()
+	p.logger.Debug("Batch sent",
+		zap.Int("items", count),
+		zap.Int("batch_size", batchSize),
+		zap.Duration("timeout", timeout),
+	)
+}
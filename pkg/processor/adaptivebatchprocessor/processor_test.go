@@ -0,0 +1,168 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivebatchprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func logsWithRecords(count int) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for i := 0; i < count; i++ {
+		lrs.AppendEmpty().Body().SetStringVal("log")
+	}
+	return ld
+}
+
+func TestBatchSentWhenSizeIsReached(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinBatchSize = 10
+	cfg.MaxBatchSize = 10
+	cfg.MinTimeout = time.Hour
+	cfg.MaxTimeout = time.Hour
+	sink := &consumertest.LogsSink{}
+	p := newAdaptiveBatchProcessor(cfg, zap.NewNop(), newLogsBatch(sink))
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, p.ConsumeLogs(context.Background(), logsWithRecords(3)))
+	}
+
+	require.Eventually(t, func() bool { return len(sink.AllLogs()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 12, sink.AllLogs()[0].LogRecordCount())
+
+	// the rest is sent on shutdown
+	require.NoError(t, p.Shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+	assert.Equal(t, 3, sink.AllLogs()[1].LogRecordCount())
+}
+
+func TestBatchSentOnTimeout(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinTimeout = 10 * time.Millisecond
+	sink := &consumertest.TracesSink{}
+	p := newAdaptiveBatchProcessor(cfg, zap.NewNop(), newTracesBatch(sink))
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) })
+
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	require.NoError(t, p.ConsumeTraces(context.Background(), td))
+
+	require.Eventually(t, func() bool { return sink.SpanCount() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+func TestMetricsBatchCountsDataPoints(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	b := newMetricsBatch(sink)
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty()
+	gauge.Gauge().DataPoints().AppendEmpty()
+	b.add(md)
+	assert.Equal(t, 2, b.itemCount())
+
+	require.NoError(t, b.export(context.Background()))
+	assert.Equal(t, 0, b.itemCount())
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Equal(t, 2, sink.AllMetrics()[0].DataPointCount())
+}
+
+type fakeHook struct {
+	callback func(latency time.Duration, throttled bool)
+}
+
+func (h *fakeHook) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (h *fakeHook) Shutdown(context.Context) error {
+	return nil
+}
+
+func (h *fakeHook) SubscribeExports(callback func(latency time.Duration, throttled bool)) func() {
+	h.callback = callback
+	return func() { h.callback = nil }
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h hostWithExtensions) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestThrottleHook(t *testing.T) {
+	hookID := config.NewComponentID("sumologic")
+	hook := &fakeHook{}
+	host := hostWithExtensions{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			hookID: hook,
+		},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ThrottleHook = &hookID
+	p := newAdaptiveBatchProcessor(cfg, zap.NewNop(), newLogsBatch(consumertest.NewNop()))
+	require.NoError(t, p.Start(context.Background(), host))
+	assert.False(t, p.measureLatency)
+
+	require.NotNil(t, hook.callback)
+	hook.callback(time.Second, true)
+	batchSize, timeout := p.controller.settings()
+	assert.Equal(t, 2*defaultMinBatchSize, batchSize)
+	assert.Equal(t, 2*defaultMinTimeout, timeout)
+
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Nil(t, hook.callback)
+}
+
+func TestThrottleHookErrors(t *testing.T) {
+	host := hostWithExtensions{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID("other"): &struct{ component.Extension }{},
+		},
+	}
+
+	for _, name := range []string{"missing", "other"} {
+		id := config.NewComponentID(config.Type(name))
+		cfg := createDefaultConfig().(*Config)
+		cfg.ThrottleHook = &id
+		p := newAdaptiveBatchProcessor(cfg, zap.NewNop(), newLogsBatch(consumertest.NewNop()))
+		assert.Error(t, p.Start(context.Background(), host), name)
+	}
+}
@@ -105,6 +105,15 @@ type TraceRejectCfg struct {
 	NamePattern *string `mapstructure:"name_pattern"`
 }
 
+// BudgetRetryCfg holds the configurable settings of the queue of traces which exceeded the budget.
+type BudgetRetryCfg struct {
+	// Window is the maximum time a trace which exceeded the budget waits for the budget to free up.
+	Window time.Duration `mapstructure:"window"`
+	// MaxTraces is the maximum number of traces waiting for the budget, traces exceeding the budget
+	// while the queue is full are not sampled. Default: 1000
+	MaxTraces int `mapstructure:"max_traces"`
+}
+
 // Config holds the configuration for cascading-filter-based sampling.
 type Config struct {
 	*config.ProcessorSettings `mapstructure:"-"`
@@ -124,6 +133,13 @@ type Config struct {
 	// NumTraces is the number of traces kept on memory. Typically, most of the data
 	// of a trace is released after a sampling decision is taken.
 	NumTraces uint64 `mapstructure:"num_traces"`
+	// HeadSamplingFallbackRatio (optional) enables head-based sampling of new traces when NumTraces traces
+	// are already kept in memory. Instead of evicting the oldest trace before its decision was made,
+	// the given ratio (0.0-1.0) of the new traces is forwarded right away and the rest of them is dropped.
+	HeadSamplingFallbackRatio *float32 `mapstructure:"head_sampling_fallback_ratio"`
+	// BudgetRetry (optional) keeps traces which exceeded the SpansPerSecond budget in memory for a while
+	// and samples them if the budget frees up, instead of dropping them right away.
+	BudgetRetry *BudgetRetryCfg `mapstructure:"budget_retry"`
 	// ExpectedNewTracesPerSec sets the expected number of new traces sending to the Cascading Filter processor
 	// per second. This helps with allocating data structures with closer to actual usage size.
 	ExpectedNewTracesPerSec uint64 `mapstructure:"expected_new_traces_per_sec"`
@@ -136,4 +152,7 @@ type Config struct {
 	// TraceRejectCfgs sets the criteria for which traces are evaluated before applying sampling rules. If
 	// trace matches them, it is no further processed
 	TraceRejectCfgs []TraceRejectCfg `mapstructure:"trace_reject_filters"`
+	// ExplainEndpoint (optional) is the address of the debug endpoint which reports how the decision
+	// for a trace still kept in memory was made, e.g. "localhost:55691". Disabled by default.
+	ExplainEndpoint string `mapstructure:"explain_endpoint"`
 }
@@ -43,6 +43,7 @@ func TestLoadConfig(t *testing.T) {
 	minSpansValue := 10
 	minErrorsValue := 2
 	probFilteringRatio := float32(0.1)
+	headSamplingFallbackRatio := float32(0.2)
 	probFilteringRate := int32(100)
 	namePatternValue := "foo.*"
 	healthCheckNamePatternValue := "health.*"
@@ -109,6 +110,8 @@ func TestLoadConfig(t *testing.T) {
 			ExpectedNewTracesPerSec:     10,
 			SpansPerSecond:              1000,
 			ProbabilisticFilteringRatio: &probFilteringRatio,
+			HeadSamplingFallbackRatio:   &headSamplingFallbackRatio,
+			BudgetRetry:                 &cfconfig.BudgetRetryCfg{Window: 5 * time.Second, MaxTraces: 50},
 			TraceRejectCfgs: []cfconfig.TraceRejectCfg{
 				{
 					Name:        "healthcheck-rule",
@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/cascadingfilterprocessor/sampling"
+)
+
+const (
+	explainPath = "/explain/"
+
+	filterStatusMatched      = "matched"
+	filterStatusNotMatched   = "not_matched"
+	filterStatusNotEvaluated = "not_evaluated"
+	decisionPending          = "pending"
+)
+
+var decisionNames = map[sampling.Decision]string{
+	sampling.Unspecified:  "unspecified",
+	sampling.Pending:      decisionPending,
+	sampling.Sampled:      "sampled",
+	sampling.SecondChance: "second_chance",
+	sampling.NotSampled:   "not_sampled",
+	sampling.Dropped:      "dropped",
+}
+
+// traceExplanation is the response of the explain endpoint.
+type traceExplanation struct {
+	TraceID        string              `json:"trace_id"`
+	SpanCount      int32               `json:"span_count"`
+	ArrivalTime    time.Time           `json:"arrival_time"`
+	FinalDecision  string              `json:"final_decision"`
+	Rationale      string              `json:"rationale"`
+	RejectFilters  []filterExplanation `json:"trace_reject_filters"`
+	AcceptFilters  []filterExplanation `json:"trace_accept_filters"`
+	SpansPerSecond int32               `json:"spans_per_second,omitempty"`
+}
+
+type filterExplanation struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// startExplainServer starts the HTTP server reporting how the decisions for the traces kept in memory were made.
+func (cfsp *cascadingFilterSpanProcessor) startExplainServer(host component.Host) error {
+	listener, err := net.Listen("tcp", cfsp.explainEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfsp.explainEndpoint, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(explainPath, cfsp.handleExplain)
+	cfsp.explainServer = &http.Server{Handler: mux}
+
+	go func() {
+		if err := cfsp.explainServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			host.ReportFatalError(err)
+		}
+	}()
+	cfsp.logger.Info("Cascading filter explain endpoint started", zap.String("endpoint", listener.Addr().String()))
+	return nil
+}
+
+// handleExplain handles GET /explain/<trace ID> requests, where the trace ID is hex encoded.
+func (cfsp *cascadingFilterSpanProcessor) handleExplain(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	traceID := strings.TrimPrefix(req.URL.Path, explainPath)
+	decoded, err := hex.DecodeString(traceID)
+	if err != nil || len(decoded) != len(traceKey{}) {
+		http.Error(w, fmt.Sprintf("invalid trace ID %q, expected 32 hex characters", traceID), http.StatusBadRequest)
+		return
+	}
+
+	var id traceKey
+	copy(id[:], decoded)
+	d, ok := cfsp.idToTrace.Load(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("trace %s not found, it was not received or it's not kept in memory anymore", traceID), http.StatusNotFound)
+		return
+	}
+
+	explanation := cfsp.explain(d.(*sampling.TraceData))
+	explanation.TraceID = strings.ToLower(traceID)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(explanation); err != nil {
+		cfsp.logger.Debug("Failed to write trace explanation", zap.Error(err))
+	}
+}
+
+// explain describes how the decision for the trace was made.
+func (cfsp *cascadingFilterSpanProcessor) explain(trace *sampling.TraceData) traceExplanation {
+	trace.Lock()
+	evaluation := trace.Evaluation
+	arrivalTime := trace.ArrivalTime
+	trace.Unlock()
+
+	explanation := traceExplanation{
+		SpanCount:      atomic.LoadInt32(&trace.SpanCount),
+		ArrivalTime:    arrivalTime,
+		SpansPerSecond: cfsp.maxSpansPerSecond,
+		RejectFilters:  make([]filterExplanation, 0, len(cfsp.traceRejectRules)),
+		AcceptFilters:  make([]filterExplanation, 0, len(cfsp.traceAcceptRules)),
+	}
+
+	if evaluation == nil {
+		explanation.FinalDecision = decisionPending
+		explanation.Rationale = "the filters are evaluated once decision_wait passes since the arrival of the first span"
+		for _, rule := range cfsp.traceRejectRules {
+			explanation.RejectFilters = append(explanation.RejectFilters, filterExplanation{Name: rule.Name, Status: filterStatusNotEvaluated})
+		}
+		for _, rule := range cfsp.traceAcceptRules {
+			explanation.AcceptFilters = append(explanation.AcceptFilters, filterExplanation{Name: rule.Name, Status: filterStatusNotEvaluated})
+		}
+		return explanation
+	}
+
+	rejected := false
+	for _, rule := range cfsp.traceRejectRules {
+		status := filterStatusNotMatched
+		switch {
+		case rejected:
+			status = filterStatusNotEvaluated
+		case rule.Name == evaluation.RejectedBy:
+			status = filterStatusMatched
+			rejected = true
+		}
+		explanation.RejectFilters = append(explanation.RejectFilters, filterExplanation{Name: rule.Name, Status: status})
+	}
+
+	var acceptedBy string
+	for i, rule := range cfsp.traceAcceptRules {
+		status := filterStatusNotEvaluated
+		if i < len(evaluation.PolicyDecisions) && evaluation.PolicyDecisions[i] != sampling.Pending {
+			status = decisionNames[evaluation.PolicyDecisions[i]]
+			if evaluation.PolicyDecisions[i] == sampling.Sampled && acceptedBy == "" {
+				acceptedBy = rule.Name
+			}
+		}
+		explanation.AcceptFilters = append(explanation.AcceptFilters, filterExplanation{Name: rule.Name, Status: status})
+	}
+
+	explanation.FinalDecision = decisionNames[evaluation.FinalDecision]
+	explanation.Rationale = rationale(evaluation, acceptedBy, len(cfsp.traceAcceptRules) > 0)
+	return explanation
+}
+
+// rationale describes why the final decision was made.
+func rationale(evaluation *sampling.Evaluation, acceptedBy string, hasAcceptFilters bool) string {
+	switch evaluation.ProvisionalDecision {
+	case sampling.Dropped:
+		return fmt.Sprintf("the trace was dropped by the trace reject filter %q", evaluation.RejectedBy)
+	case sampling.Sampled:
+		accepted := "all traces are accepted, as there are no trace accept filters"
+		if hasAcceptFilters {
+			accepted = fmt.Sprintf("the trace was accepted by the trace accept filter %q", acceptedBy)
+		}
+		if evaluation.FinalDecision == sampling.Sampled {
+			return accepted + " and it fit into the spans per second budget"
+		}
+		return accepted + ", but it exceeded the spans per second budget"
+	case sampling.SecondChance:
+		if evaluation.FinalDecision == sampling.Sampled {
+			return "the trace got a second chance and it fit into the spans per second budget left after the accepted traces"
+		}
+		return "the trace got a second chance, but it exceeded the spans per second budget left after the accepted traces"
+	default:
+		return "the trace was not accepted by any of the trace accept filters"
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cascadingfilterprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/cascadingfilterprocessor/sampling"
+)
+
+func newExplainTestProcessor(rejectEvaluator sampling.DropTraceEvaluator, maxSpansPerSecond int32) *cascadingFilterSpanProcessor {
+	return &cascadingFilterSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    new(consumertest.TracesSink),
+		maxNumTraces:    100,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		traceAcceptRules: []*TraceAcceptEvaluator{
+			{Name: "errors", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.NotSampled}, ctx: context.TODO()},
+			{Name: "slow", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()},
+			{Name: "everything", Evaluator: &mockPolicyEvaluator{NextDecision: sampling.Sampled}, ctx: context.TODO()},
+		},
+		traceRejectRules: []*TraceRejectEvaluator{
+			{Name: "health-checks", Evaluator: &mockDropFalseEvaluator{}, ctx: context.TODO()},
+			{Name: "noisy-service", Evaluator: rejectEvaluator, ctx: context.TODO()},
+		},
+		deleteChan:        make(chan traceKey, 100),
+		policyTicker:      &manualTTicker{},
+		maxSpansPerSecond: maxSpansPerSecond,
+		filteringEnabled:  true,
+		explainEnabled:    true,
+	}
+}
+
+func explainTrace(t *testing.T, cfsp *cascadingFilterSpanProcessor, traceID string) (int, traceExplanation) {
+	rec := httptest.NewRecorder()
+	cfsp.handleExplain(rec, httptest.NewRequest(http.MethodGet, explainPath+traceID, nil))
+
+	var explanation traceExplanation
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &explanation))
+	}
+	return rec.Code, explanation
+}
+
+func TestExplainSampledTrace(t *testing.T) {
+	cfsp := newExplainTestProcessor(&mockDropFalseEvaluator{}, 1000)
+	traceID := pcommon.NewTraceID([16]byte{1, 2, 3, 4})
+	require.NoError(t, cfsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceID)))
+
+	code, explanation := explainTrace(t, cfsp, traceID.HexString())
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, traceID.HexString(), explanation.TraceID)
+	assert.EqualValues(t, 1, explanation.SpanCount)
+	assert.Equal(t, "pending", explanation.FinalDecision)
+	assert.Equal(t, []filterExplanation{
+		{Name: "errors", Status: "not_evaluated"},
+		{Name: "slow", Status: "not_evaluated"},
+		{Name: "everything", Status: "not_evaluated"},
+	}, explanation.AcceptFilters)
+
+	// the first tick takes an empty batch, the second one makes the decision
+	cfsp.samplingPolicyOnTick()
+	cfsp.samplingPolicyOnTick()
+
+	code, explanation = explainTrace(t, cfsp, traceID.HexString())
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "sampled", explanation.FinalDecision)
+	assert.Equal(t, `the trace was accepted by the trace accept filter "slow" and it fit into the spans per second budget`, explanation.Rationale)
+	assert.Equal(t, []filterExplanation{
+		{Name: "health-checks", Status: "not_matched"},
+		{Name: "noisy-service", Status: "not_matched"},
+	}, explanation.RejectFilters)
+	assert.Equal(t, []filterExplanation{
+		{Name: "errors", Status: "not_sampled"},
+		{Name: "slow", Status: "sampled"},
+		{Name: "everything", Status: "not_evaluated"},
+	}, explanation.AcceptFilters)
+	assert.EqualValues(t, 1000, explanation.SpansPerSecond)
+}
+
+func TestExplainTraceExceedingBudget(t *testing.T) {
+	cfsp := newExplainTestProcessor(&mockDropFalseEvaluator{}, 1)
+	traceID := pcommon.NewTraceID([16]byte{1, 2, 3, 4})
+	traces := simpleTracesWithID(traceID)
+	traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().AppendEmpty().SetTraceID(traceID)
+	require.NoError(t, cfsp.ConsumeTraces(context.Background(), traces))
+
+	cfsp.samplingPolicyOnTick()
+	cfsp.samplingPolicyOnTick()
+
+	code, explanation := explainTrace(t, cfsp, traceID.HexString())
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "not_sampled", explanation.FinalDecision)
+	assert.Equal(t, `the trace was accepted by the trace accept filter "slow", but it exceeded the spans per second budget`, explanation.Rationale)
+}
+
+func TestExplainDroppedTrace(t *testing.T) {
+	cfsp := newExplainTestProcessor(&mockDropTrueEvaluator{}, 1000)
+	traceID := pcommon.NewTraceID([16]byte{1, 2, 3, 4})
+	require.NoError(t, cfsp.ConsumeTraces(context.Background(), simpleTracesWithID(traceID)))
+
+	cfsp.samplingPolicyOnTick()
+	cfsp.samplingPolicyOnTick()
+
+	code, explanation := explainTrace(t, cfsp, traceID.HexString())
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "dropped", explanation.FinalDecision)
+	assert.Equal(t, `the trace was dropped by the trace reject filter "noisy-service"`, explanation.Rationale)
+	assert.Equal(t, []filterExplanation{
+		{Name: "health-checks", Status: "not_matched"},
+		{Name: "noisy-service", Status: "matched"},
+	}, explanation.RejectFilters)
+	assert.Equal(t, []filterExplanation{
+		{Name: "errors", Status: "not_evaluated"},
+		{Name: "slow", Status: "not_evaluated"},
+		{Name: "everything", Status: "not_evaluated"},
+	}, explanation.AcceptFilters)
+}
+
+func TestExplainUnknownTrace(t *testing.T) {
+	cfsp := newExplainTestProcessor(&mockDropFalseEvaluator{}, 1000)
+
+	code, _ := explainTrace(t, cfsp, pcommon.NewTraceID([16]byte{1, 2, 3, 4}).HexString())
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = explainTrace(t, cfsp, "not-a-trace-id")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestExplainServer(t *testing.T) {
+	cfsp := newExplainTestProcessor(&mockDropFalseEvaluator{}, 1000)
+	cfsp.explainEndpoint = "localhost:0"
+
+	require.NoError(t, cfsp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NotNil(t, cfsp.explainServer)
+	require.NoError(t, cfsp.Shutdown(context.Background()))
+}
@@ -29,12 +29,18 @@ var (
 	statusSecondChance         = "SecondChance"
 	statusSecondChanceSampled  = "SecondChanceSampled"
 	statusSecondChanceExceeded = "SecondChanceRateExceeded"
+	statusBudgetRetrySampled   = "BudgetRetrySampled"
+	statusBudgetRetryExceeded  = "BudgetRetryRateExceeded"
 	statusDropped              = "Dropped"
 
+	decisionSourceBudget = "budget"
+	decisionSourceMemory = "memory"
+
 	tagPolicyKey, _                  = tag.NewKey("policy")
 	tagCascadingFilterDecisionKey, _ = tag.NewKey("cascading_filter_decision")
 	tagPolicyDecisionKey, _          = tag.NewKey("policy_decision")
 	tagProcessorKey, _               = tag.NewKey("processor")
+	tagDecisionSourceKey, _          = tag.NewKey("decision_source")
 
 	statDecisionLatencyMicroSec  = stats.Int64("policy_decision_latency", "Latency (in microseconds) of a given filtering policy", "µs")
 	statOverallDecisionLatencyus = stats.Int64("cascading_filtering_batch_processing_latency", "Latency (in microseconds) of each run of the cascading filter timer", "µs")
@@ -50,6 +56,7 @@ var (
 	statDroppedTooEarlyCount    = stats.Int64("casdading_trace_dropped_too_early", "Count of traces that needed to be dropped the configured wait time", stats.UnitDimensionless)
 	statNewTraceIDReceivedCount = stats.Int64("cascading_new_trace_id_received", "Counts the arrival of new traces", stats.UnitDimensionless)
 	statTracesOnMemoryGauge     = stats.Int64("cascading_traces_on_memory", "Tracks the number of traces current on memory", stats.UnitDimensionless)
+	statBudgetRetryQueueGauge   = stats.Int64("cascading_budget_retry_queue", "Tracks the number of traces waiting for the budget to free up", stats.UnitDimensionless)
 )
 
 // CascadingFilterMetricViews return the metrics views according to given telemetry level.
@@ -97,7 +104,7 @@ func CascadingFilterMetricViews(level configtelemetry.Level) []*view.View {
 		Name:        statCascadingFilterDecision.Name(),
 		Measure:     statCascadingFilterDecision,
 		Description: statCascadingFilterDecision.Description(),
-		TagKeys:     []tag.Key{tagProcessorKey, tagPolicyKey, tagCascadingFilterDecisionKey},
+		TagKeys:     []tag.Key{tagProcessorKey, tagPolicyKey, tagCascadingFilterDecisionKey, tagDecisionSourceKey},
 		Aggregation: view.Sum(),
 	}
 
@@ -138,6 +145,13 @@ func CascadingFilterMetricViews(level configtelemetry.Level) []*view.View {
 		TagKeys:     []tag.Key{tagProcessorKey},
 		Aggregation: view.LastValue(),
 	}
+	trackBudgetRetryQueueView := &view.View{
+		Name:        statBudgetRetryQueueGauge.Name(),
+		Measure:     statBudgetRetryQueueGauge,
+		Description: statBudgetRetryQueueGauge.Description(),
+		TagKeys:     []tag.Key{tagProcessorKey},
+		Aggregation: view.LastValue(),
+	}
 
 	legacyViews := []*view.View{
 		overallDecisionLatencyView,
@@ -152,6 +166,7 @@ func CascadingFilterMetricViews(level configtelemetry.Level) []*view.View {
 		countTraceDroppedTooEarlyView,
 		countTraceIDArrivalView,
 		trackTracesOnMemorylView,
+		trackBudgetRetryQueueView,
 	}
 
 	// return obsreport.ProcessorMetricViews(typeStr, legacyViews)
@@ -16,7 +16,10 @@ package cascadingfilterprocessor
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
 	"math"
+	"net/http"
 	"runtime"
 	"strings"
 	"sync"
@@ -85,15 +88,50 @@ type cascadingFilterSpanProcessor struct {
 	currentSecond        int64
 	maxSpansPerSecond    int32
 	spansInCurrentSecond int32
+
+	// headSamplingFallback enables head-based sampling of new traces when maxNumTraces is reached,
+	// traces with the trace ID hash below headSamplingThreshold are sampled
+	headSamplingFallback  bool
+	headSamplingRatio     float32
+	headSamplingThreshold uint64
+
+	// explainEndpoint is the address of the endpoint explaining the decisions, evaluations of
+	// the traces are recorded only when explainEnabled is set
+	explainEndpoint string
+	explainEnabled  bool
+	explainServer   *http.Server
+
+	// budgetRetryQueue holds the traces which exceeded the budget and wait for it to free up,
+	// it's only accessed by the policy ticker
+	budgetRetryEnabled   bool
+	budgetRetryWindow    time.Duration
+	budgetRetryMaxTraces int
+	budgetRetryQueue     []budgetRetryEntry
+}
+
+// budgetRetryEntry is a trace waiting in the budget retry queue.
+type budgetRetryEntry struct {
+	id       pcommon.TraceID
+	queuedAt time.Time
+	// evaluation is recorded only when explaining decisions is enabled
+	evaluation *sampling.Evaluation
+	// probabilisticSpans and totalSpans are the spans of the batch the trace was evaluated in,
+	// they are used to calculate the sampling probability of probabilistically selected traces
+	probabilisticSpans int64
+	totalSpans         int64
 }
 
 const (
 	probabilisticFilterPolicyName = "probabilistic_filter"
 	probabilisticRuleVale         = "probabilistic"
 	filteredRuleValue             = "filtered"
+	headSamplingFallbackRuleValue = "head_sampling_fallback"
+	headSamplingFallbackPolicy    = "head_sampling_fallback"
 	AttributeSamplingRule         = "sampling.rule"
 
 	AttributeSamplingProbability = "sampling.probability"
+
+	defaultBudgetRetryMaxTraces = 1000
 )
 
 // newTraceProcessor returns a processor.TraceProcessor that will perform Cascading Filter according to the given
@@ -240,6 +278,37 @@ func newCascadingFilterSpanProcessor(logger *zap.Logger, nextConsumer consumer.T
 		traceAcceptRules:  policies,
 		traceRejectRules:  dropTraceEvals,
 		filteringEnabled:  len(policies) > 0 || len(dropTraceEvals) > 0,
+		explainEndpoint:   cfg.ExplainEndpoint,
+		explainEnabled:    cfg.ExplainEndpoint != "",
+	}
+
+	if cfg.HeadSamplingFallbackRatio != nil {
+		ratio := *cfg.HeadSamplingFallbackRatio
+		if ratio < 0.0 || ratio > 1.0 {
+			return nil, fmt.Errorf("head_sampling_fallback_ratio has to be within 0.0-1.0, got: %v", ratio)
+		}
+		logger.Info("Setting head sampling fallback ratio", zap.Float32("head_sampling_fallback_ratio", ratio))
+		cfsp.headSamplingFallback = true
+		cfsp.headSamplingRatio = ratio
+		cfsp.headSamplingThreshold = headSamplingThreshold(ratio)
+	}
+
+	if cfg.BudgetRetry != nil {
+		if cfg.BudgetRetry.Window <= 0 {
+			return nil, fmt.Errorf("budget_retry window has to be positive, got: %v", cfg.BudgetRetry.Window)
+		}
+		if cfg.BudgetRetry.MaxTraces < 0 {
+			return nil, fmt.Errorf("budget_retry max_traces cannot be negative, got: %v", cfg.BudgetRetry.MaxTraces)
+		}
+		cfsp.budgetRetryEnabled = true
+		cfsp.budgetRetryWindow = cfg.BudgetRetry.Window
+		cfsp.budgetRetryMaxTraces = cfg.BudgetRetry.MaxTraces
+		if cfsp.budgetRetryMaxTraces == 0 {
+			cfsp.budgetRetryMaxTraces = defaultBudgetRetryMaxTraces
+		}
+		logger.Info("Setting budget retry queue",
+			zap.Duration("window", cfsp.budgetRetryWindow),
+			zap.Int("max_traces", cfsp.budgetRetryMaxTraces))
 	}
 
 	cfsp.policyTicker = &policyTicker{onTick: cfsp.samplingPolicyOnTick}
@@ -292,6 +361,15 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 	totalSpans := int64(0)
 	selectedByProbabilisticFilterSpans := int64(0)
 
+	// queued is the number of traces which exceeded the budget in this tick and wait for it to free up
+	var queued int
+	var newlyQueued []budgetRetryEntry
+
+	var evaluations map[traceKey]*sampling.Evaluation
+	if cfsp.explainEnabled {
+		evaluations = make(map[traceKey]*sampling.Evaluation, batchLen)
+	}
+
 	// The first run applies decisions to batches, executing each policy separately
 	for _, id := range batch {
 		d, ok := cfsp.idToTrace.Load(traceKey(id.Bytes()))
@@ -303,15 +381,25 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 		trace.DecisionTime = time.Now()
 
 		var provisionalDecision sampling.Decision
+		var rejectedBy string
 
 		// Dropped traces are not included in probabilistic filtering calculations
-		if cfsp.shouldBeDropped(id, trace) {
+		if rule := cfsp.rejectingRule(id, trace); rule != nil {
 			provisionalDecision = sampling.Dropped
+			rejectedBy = rule.Name
 		} else {
 			totalSpans += int64(trace.SpanCount)
 			provisionalDecision, _ = cfsp.makeProvisionalDecision(id, trace)
 		}
 
+		if evaluations != nil {
+			evaluations[traceKey(id.Bytes())] = &sampling.Evaluation{
+				RejectedBy:          rejectedBy,
+				PolicyDecisions:     append([]sampling.Decision(nil), trace.Decisions...),
+				ProvisionalDecision: provisionalDecision,
+			}
+		}
+
 		if provisionalDecision == sampling.Sampled {
 			trace.FinalDecision = cfsp.updateRate(currSecond, trace.SpanCount)
 			if trace.FinalDecision == sampling.Sampled {
@@ -323,18 +411,24 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 					[]tag.Mutator{
 						tag.Insert(tagProcessorKey, cfsp.instanceName),
 						tag.Insert(tagCascadingFilterDecisionKey, statusSampled),
+						tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
 					},
 					statCascadingFilterDecision.M(int64(1)),
 				)
 				if err != nil {
 					cfsp.logger.Error("Sampling Policy Evaluation error on first run tick", zap.Error(err))
 				}
+			} else if cfsp.budgetRetryHasRoom(queued) {
+				// The trace waits for the budget to free up in the following ticks
+				trace.FinalDecision = sampling.Pending
+				queued++
 			} else {
 				err := stats.RecordWithTags(
 					cfsp.ctx,
 					[]tag.Mutator{
 						tag.Insert(tagProcessorKey, cfsp.instanceName),
 						tag.Insert(tagCascadingFilterDecisionKey, statusExceededKey),
+						tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
 					},
 					statCascadingFilterDecision.M(int64(1)),
 				)
@@ -351,6 +445,7 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 				[]tag.Mutator{
 					tag.Insert(tagProcessorKey, cfsp.instanceName),
 					tag.Insert(tagCascadingFilterDecisionKey, statusNotSampled),
+					tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
 				},
 				statCascadingFilterDecision.M(int64(1)),
 			)
@@ -375,18 +470,23 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 					[]tag.Mutator{
 						tag.Insert(tagProcessorKey, cfsp.instanceName),
 						tag.Insert(tagCascadingFilterDecisionKey, statusSecondChanceSampled),
+						tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
 					},
 					statCascadingFilterDecision.M(int64(1)),
 				)
 				if err != nil {
 					cfsp.logger.Error("Sampling Policy Evaluation error on second run tick", zap.Error(err))
 				}
+			} else if cfsp.budgetRetryHasRoom(queued) {
+				trace.FinalDecision = sampling.Pending
+				queued++
 			} else {
 				err := stats.RecordWithTags(
 					cfsp.ctx,
 					[]tag.Mutator{
 						tag.Insert(tagProcessorKey, cfsp.instanceName),
 						tag.Insert(tagCascadingFilterDecisionKey, statusSecondChanceExceeded),
+						tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
 					},
 					statCascadingFilterDecision.M(int64(1)),
 				)
@@ -396,39 +496,43 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 			}
 		}
 
-		// Sampled or not, remove the batches
-		trace.Lock()
-		traceBatches := trace.ReceivedBatches
-		trace.ReceivedBatches = nil
-		trace.Unlock()
+		evaluation := evaluations[traceKey(id.Bytes())]
 
-		if trace.FinalDecision == sampling.Sampled {
-			metrics.decisionSampled++
-
-			// Combine all individual batches into a single batch so
-			// consumers may operate on the entire trace
-			allSpans := ptrace.NewTraces()
-			for j := 0; j < len(traceBatches); j++ {
-				batch := traceBatches[j]
-				batch.ResourceSpans().MoveAndAppendTo(allSpans.ResourceSpans())
-			}
-
-			if trace.SelectedByProbabilisticFilter {
-				updateProbabilisticRateTag(allSpans, selectedByProbabilisticFilterSpans, totalSpans)
-			} else if len(cfsp.traceAcceptRules) > 0 {
-				// Set filtering tag only if there were actually any accept rules set otherwise
-				updateFilteringTag(allSpans)
+		// Traces waiting for the budget keep their batches, so the spans arriving in the meantime are kept as well
+		if trace.FinalDecision == sampling.Pending {
+			if evaluation != nil {
+				evaluation.FinalDecision = sampling.Pending
+				trace.Lock()
+				trace.Evaluation = evaluation
+				trace.Unlock()
 			}
+			newlyQueued = append(newlyQueued, budgetRetryEntry{id: id, queuedAt: time.Now(), evaluation: evaluation})
+			continue
+		}
 
-			err := cfsp.nextConsumer.ConsumeTraces(cfsp.ctx, allSpans)
-			if err != nil {
-				cfsp.logger.Error("Sampling Policy Evaluation error on consuming traces", zap.Error(err))
-			}
+		// Sampled or not, remove the batches
+		if cfsp.releaseTrace(trace, evaluation, selectedByProbabilisticFilterSpans, totalSpans) {
+			metrics.decisionSampled++
 		} else {
 			metrics.decisionNotSampled++
 		}
 	}
 
+	if cfsp.budgetRetryEnabled {
+		for i := range newlyQueued {
+			newlyQueued[i].probabilisticSpans = selectedByProbabilisticFilterSpans
+			newlyQueued[i].totalSpans = totalSpans
+		}
+		cfsp.retryQueuedTraces(currSecond, &metrics)
+		// The traces queued in this tick wait for the budget of the following ticks
+		cfsp.budgetRetryQueue = append(cfsp.budgetRetryQueue, newlyQueued...)
+
+		err := stats.RecordWithTags(cfsp.ctx,
+			[]tag.Mutator{tag.Insert(tagProcessorKey, cfsp.instanceName)},
+			statBudgetRetryQueueGauge.M(int64(len(cfsp.budgetRetryQueue))))
+		cfsp.logMetricsRecordErrorIfPresent(err, []string{statBudgetRetryQueueGauge.Name()})
+	}
+
 	err := stats.RecordWithTags(cfsp.ctx,
 		[]tag.Mutator{tag.Insert(tagProcessorKey, cfsp.instanceName)},
 		statOverallDecisionLatencyus.M(int64(time.Since(startTime)/time.Microsecond)),
@@ -451,6 +555,157 @@ func (cfsp *cascadingFilterSpanProcessor) samplingPolicyOnTick() {
 	)
 }
 
+// budgetRetryHasRoom returns whether a trace which exceeded the budget can wait for the budget to free up,
+// given the number of traces which were queued in the current tick already.
+func (cfsp *cascadingFilterSpanProcessor) budgetRetryHasRoom(queued int) bool {
+	return cfsp.budgetRetryEnabled && len(cfsp.budgetRetryQueue)+queued < cfsp.budgetRetryMaxTraces
+}
+
+// retryQueuedTraces samples the traces waiting in the budget retry queue, in the order they were queued,
+// as long as the budget of the current second allows it. Traces which waited longer than the window
+// are not sampled.
+func (cfsp *cascadingFilterSpanProcessor) retryQueuedTraces(currSecond int64, metrics *policyMetrics) {
+	now := time.Now()
+	waiting := cfsp.budgetRetryQueue[:0]
+	for _, entry := range cfsp.budgetRetryQueue {
+		d, ok := cfsp.idToTrace.Load(traceKey(entry.id.Bytes()))
+		if !ok {
+			// The trace was evicted from memory in the meantime
+			metrics.idNotFoundOnMapCount++
+			continue
+		}
+		trace := d.(*sampling.TraceData)
+
+		decision := sampling.NotSampled
+		status := statusBudgetRetryExceeded
+		if now.Sub(entry.queuedAt) <= cfsp.budgetRetryWindow {
+			decision = cfsp.updateRate(currSecond, atomic.LoadInt32(&trace.SpanCount))
+			if decision != sampling.Sampled {
+				waiting = append(waiting, entry)
+				continue
+			}
+			status = statusBudgetRetrySampled
+		}
+
+		err := stats.RecordWithTags(
+			cfsp.ctx,
+			[]tag.Mutator{
+				tag.Insert(tagProcessorKey, cfsp.instanceName),
+				tag.Insert(tagCascadingFilterDecisionKey, status),
+				tag.Insert(tagDecisionSourceKey, decisionSourceBudget),
+			},
+			statCascadingFilterDecision.M(int64(1)),
+		)
+		cfsp.logMetricsRecordErrorIfPresent(err, []string{statCascadingFilterDecision.Name()})
+
+		trace.FinalDecision = decision
+		if cfsp.releaseTrace(trace, entry.evaluation, entry.probabilisticSpans, entry.totalSpans) {
+			metrics.decisionSampled++
+		} else {
+			metrics.decisionNotSampled++
+		}
+	}
+
+	// Clear the references to the released traces
+	for i := len(waiting); i < len(cfsp.budgetRetryQueue); i++ {
+		cfsp.budgetRetryQueue[i] = budgetRetryEntry{}
+	}
+	cfsp.budgetRetryQueue = waiting
+}
+
+// releaseTrace applies the final decision to the trace, removing its batches from memory and forwarding
+// them when the trace was sampled. It returns whether the trace was sampled.
+func (cfsp *cascadingFilterSpanProcessor) releaseTrace(trace *sampling.TraceData, evaluation *sampling.Evaluation, probabilisticSpans int64, totalSpans int64) bool {
+	trace.Lock()
+	traceBatches := trace.ReceivedBatches
+	trace.ReceivedBatches = nil
+	if evaluation != nil {
+		evaluation.FinalDecision = trace.FinalDecision
+		trace.Evaluation = evaluation
+	}
+	trace.Unlock()
+
+	if trace.FinalDecision != sampling.Sampled {
+		return false
+	}
+
+	// Combine all individual batches into a single batch so
+	// consumers may operate on the entire trace
+	allSpans := ptrace.NewTraces()
+	for j := 0; j < len(traceBatches); j++ {
+		batch := traceBatches[j]
+		batch.ResourceSpans().MoveAndAppendTo(allSpans.ResourceSpans())
+	}
+
+	if trace.SelectedByProbabilisticFilter {
+		updateProbabilisticRateTag(allSpans, probabilisticSpans, totalSpans)
+	} else if len(cfsp.traceAcceptRules) > 0 {
+		// Set filtering tag only if there were actually any accept rules set otherwise
+		updateFilteringTag(allSpans)
+	}
+
+	err := cfsp.nextConsumer.ConsumeTraces(cfsp.ctx, allSpans)
+	if err != nil {
+		cfsp.logger.Error("Sampling Policy Evaluation error on consuming traces", zap.Error(err))
+	}
+	return true
+}
+
+// headSampleTrace makes the sampling decision for a trace which doesn't fit into the buffer basing on its trace ID only.
+// The decision is deterministic, so all spans of the trace arriving while the buffer is full get the same decision.
+func (cfsp *cascadingFilterSpanProcessor) headSampleTrace(ctx context.Context, id traceKey, resourceSpans ptrace.ResourceSpans, spans []*ptrace.Span) {
+	decision := statusNotSampled
+	if binary.BigEndian.Uint64(id[8:]) < cfsp.headSamplingThreshold {
+		decision = statusSampled
+		traceTd := prepareTraceBatch(resourceSpans, spans)
+		updateHeadSamplingTag(traceTd, cfsp.headSamplingRatio)
+		if err := cfsp.nextConsumer.ConsumeTraces(ctx, traceTd); err != nil {
+			cfsp.logger.Warn("Error sending head sampled spans to destination", zap.Error(err))
+		}
+	}
+
+	err := stats.RecordWithTags(
+		cfsp.ctx,
+		[]tag.Mutator{
+			tag.Insert(tagProcessorKey, cfsp.instanceName),
+			tag.Insert(tagPolicyKey, headSamplingFallbackPolicy),
+			tag.Insert(tagCascadingFilterDecisionKey, decision),
+			tag.Insert(tagDecisionSourceKey, decisionSourceMemory),
+		},
+		statCascadingFilterDecision.M(int64(1)),
+	)
+	cfsp.logMetricsRecordErrorIfPresent(err, []string{statCascadingFilterDecision.Name()})
+}
+
+// headSamplingThreshold returns the trace ID hash value below which traces are sampled with the given ratio.
+func headSamplingThreshold(ratio float32) uint64 {
+	if ratio >= 1.0 {
+		return math.MaxUint64
+	}
+	return uint64(float64(ratio) * math.MaxUint64)
+}
+
+func updateHeadSamplingTag(traces ptrace.Traces, ratio float32) {
+	rs := traces.ResourceSpans()
+
+	for i := 0; i < rs.Len(); i++ {
+		ss := rs.At(i).ScopeSpans()
+		for j := 0; j < ss.Len(); j++ {
+			spans := ss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				attrs := spans.At(k).Attributes()
+				av, found := attrs.Get(AttributeSamplingProbability)
+				if found && av.Type() == pcommon.ValueTypeDouble && !math.IsNaN(av.DoubleVal()) && av.DoubleVal() > 0.0 {
+					av.SetDoubleVal(av.DoubleVal() * float64(ratio))
+				} else {
+					attrs.UpsertDouble(AttributeSamplingProbability, float64(ratio))
+				}
+				attrs.UpsertString(AttributeSamplingRule, headSamplingFallbackRuleValue)
+			}
+		}
+	}
+}
+
 func updateProbabilisticRateTag(traces ptrace.Traces, probabilisticSpans int64, allSpans int64) {
 	ratio := float64(probabilisticSpans) / float64(allSpans)
 
@@ -490,14 +745,19 @@ func updateFilteringTag(traces ptrace.Traces) {
 }
 
 func (cfsp *cascadingFilterSpanProcessor) shouldBeDropped(id pcommon.TraceID, trace *sampling.TraceData) bool {
+	return cfsp.rejectingRule(id, trace) != nil
+}
+
+// rejectingRule returns the first trace reject rule matching the trace, or nil if the trace shouldn't be dropped.
+func (cfsp *cascadingFilterSpanProcessor) rejectingRule(id pcommon.TraceID, trace *sampling.TraceData) *TraceRejectEvaluator {
 	for _, dropRule := range cfsp.traceRejectRules {
 		if dropRule.Evaluator.ShouldDrop(id, trace) {
 			err := stats.RecordWithTags(dropRule.ctx, []tag.Mutator{tag.Insert(tagProcessorKey, cfsp.instanceName)}, statPolicyDecision.M(int64(1)))
 			cfsp.logMetricsRecordErrorIfPresent(err, []string{statPolicyDecision.Name()})
-			return true
+			return dropRule
 		}
 	}
-	return false
+	return nil
 }
 
 func (cfsp *cascadingFilterSpanProcessor) makeProvisionalDecision(id pcommon.TraceID, trace *sampling.TraceData) (sampling.Decision, *TraceAcceptEvaluator) {
@@ -633,6 +893,14 @@ func (cfsp *cascadingFilterSpanProcessor) processTraces(ctx context.Context, res
 			ArrivalTime: time.Now(),
 			SpanCount:   lenSpans,
 		}
+		// When the buffer is full, new traces are head sampled rather than evicting buffered ones
+		if cfsp.headSamplingFallback && atomic.LoadUint64(&cfsp.numTracesOnMap) >= cfsp.maxNumTraces {
+			if _, ok := cfsp.idToTrace.Load(id); !ok {
+				cfsp.headSampleTrace(ctx, id, resourceSpans, spans)
+				continue
+			}
+		}
+
 		d, loaded := cfsp.idToTrace.LoadOrStore(id, initialTraceData)
 
 		actualData := d.(*sampling.TraceData)
@@ -733,12 +1001,18 @@ func (cfsp *cascadingFilterSpanProcessor) Capabilities() consumer.Capabilities {
 }
 
 // Start is invoked during service startup.
-func (cfsp *cascadingFilterSpanProcessor) Start(context.Context, component.Host) error {
+func (cfsp *cascadingFilterSpanProcessor) Start(_ context.Context, host component.Host) error {
+	if cfsp.explainEndpoint != "" {
+		return cfsp.startExplainServer(host)
+	}
 	return nil
 }
 
 // Shutdown is invoked during service shutdown.
-func (cfsp *cascadingFilterSpanProcessor) Shutdown(context.Context) error {
+func (cfsp *cascadingFilterSpanProcessor) Shutdown(ctx context.Context) error {
+	if cfsp.explainServer != nil {
+		return cfsp.explainServer.Shutdown(ctx)
+	}
 	return nil
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -143,6 +144,191 @@ func TestSequentialTraceMapSize(t *testing.T) {
 	}
 }
 
+func TestHeadSamplingFallbackWhenBufferIsFull(t *testing.T) {
+	const maxSize = 10
+	traceIds, batches := generateIdsAndBatches(maxSize)
+	id1 := config.NewComponentIDWithName("cascading_filter", "1")
+	ps1 := config.NewProcessorSettings(id1)
+	ratio := float32(0.5)
+	cfg := cfconfig.Config{
+		ProcessorSettings:         &ps1,
+		DecisionWait:              defaultTestDecisionWait,
+		NumTraces:                 uint64(maxSize),
+		ExpectedNewTracesPerSec:   64,
+		PolicyCfgs:                testPolicy,
+		HeadSamplingFallbackRatio: &ratio,
+	}
+	msp := new(consumertest.TracesSink)
+	sp, err := newTraceProcessor(zap.NewNop(), msp, cfg)
+	require.NoError(t, err)
+	tsp := sp.(*cascadingFilterSpanProcessor)
+	for _, batch := range batches {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), batch))
+	}
+	require.Zero(t, msp.SpanCount())
+
+	// the trace ID hash of the first trace is below the ratio, the hash of the second one is above it
+	sampledID := bigendianconverter.UInt64ToTraceID(2, 1<<62)
+	notSampledID := bigendianconverter.UInt64ToTraceID(2, 3<<62)
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(sampledID)))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(notSampledID)))
+
+	// the buffered traces are kept rather than evicted
+	for i := range traceIds {
+		_, ok := tsp.idToTrace.Load(traceKey(traceIds[i].Bytes()))
+		require.True(t, ok, "Missing expected traceId[%d]", i)
+	}
+	_, ok := tsp.idToTrace.Load(traceKey(sampledID.Bytes()))
+	require.False(t, ok)
+
+	// the head sampled trace is forwarded right away
+	require.Equal(t, 1, msp.SpanCount())
+	span := msp.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, sampledID, span.TraceID())
+	rule, _ := span.Attributes().Get(AttributeSamplingRule)
+	require.Equal(t, headSamplingFallbackRuleValue, rule.StringVal())
+	probability, _ := span.Attributes().Get(AttributeSamplingProbability)
+	require.InDelta(t, 0.5, probability.DoubleVal(), 0.0001)
+
+	// late spans of the trace get the same decision
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(sampledID)))
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(notSampledID)))
+	require.Equal(t, 2, msp.SpanCount())
+}
+
+func TestHeadSamplingFallbackInvalidRatio(t *testing.T) {
+	id1 := config.NewComponentIDWithName("cascading_filter", "1")
+	ps1 := config.NewProcessorSettings(id1)
+	ratio := float32(1.5)
+	cfg := cfconfig.Config{
+		ProcessorSettings:         &ps1,
+		DecisionWait:              defaultTestDecisionWait,
+		NumTraces:                 10,
+		PolicyCfgs:                testPolicy,
+		HeadSamplingFallbackRatio: &ratio,
+	}
+	_, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	require.Error(t, err)
+}
+
+func newBudgetRetryTestProcessor(nextConsumer consumer.Traces, maxTraces int) *cascadingFilterSpanProcessor {
+	const maxSize = 100
+	return &cascadingFilterSpanProcessor{
+		ctx:                  context.Background(),
+		nextConsumer:         nextConsumer,
+		maxNumTraces:         maxSize,
+		logger:               zap.NewNop(),
+		decisionBatcher:      newSyncIDBatcher(1),
+		deleteChan:           make(chan traceKey, maxSize),
+		policyTicker:         &manualTTicker{},
+		maxSpansPerSecond:    3,
+		filteringEnabled:     true,
+		budgetRetryEnabled:   true,
+		budgetRetryWindow:    time.Minute,
+		budgetRetryMaxTraces: maxTraces,
+	}
+}
+
+// tickInNewSecond runs the policy evaluation with the budget of a new second.
+func tickInNewSecond(tsp *cascadingFilterSpanProcessor) {
+	tsp.currentSecond = 0
+	tsp.samplingPolicyOnTick()
+}
+
+func TestBudgetRetrySamplesTraceWhenBudgetFreesUp(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newBudgetRetryTestProcessor(msp, 10)
+
+	sampledID := bigendianconverter.UInt64ToTraceID(1, 1)
+	retriedID := bigendianconverter.UInt64ToTraceID(1, 2)
+	for _, id := range []pcommon.TraceID{sampledID, sampledID, retriedID, retriedID} {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(id)))
+	}
+
+	// the second trace exceeds the budget and waits for it to free up
+	tickInNewSecond(tsp)
+	tickInNewSecond(tsp)
+	require.Equal(t, 2, msp.SpanCount())
+	require.Len(t, tsp.budgetRetryQueue, 1)
+	d, ok := tsp.idToTrace.Load(traceKey(retriedID.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, sampling.Pending, d.(*sampling.TraceData).FinalDecision)
+
+	// spans arriving in the meantime are kept with the trace
+	require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(retriedID)))
+
+	tickInNewSecond(tsp)
+	require.Empty(t, tsp.budgetRetryQueue)
+	require.Equal(t, 5, msp.SpanCount())
+	require.Equal(t, sampling.Sampled, d.(*sampling.TraceData).FinalDecision)
+	for _, traces := range msp.AllTraces()[1:] {
+		span := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+		require.Equal(t, retriedID, span.TraceID())
+	}
+}
+
+func TestBudgetRetryWindowExpires(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newBudgetRetryTestProcessor(msp, 10)
+
+	retriedID := bigendianconverter.UInt64ToTraceID(1, 2)
+	for _, id := range []pcommon.TraceID{retriedID, retriedID, retriedID, retriedID} {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(id)))
+	}
+
+	// the trace doesn't fit into the budget of any second
+	tickInNewSecond(tsp)
+	tickInNewSecond(tsp)
+	tickInNewSecond(tsp)
+	require.Len(t, tsp.budgetRetryQueue, 1)
+
+	tsp.budgetRetryQueue[0].queuedAt = time.Now().Add(-2 * time.Minute)
+	tickInNewSecond(tsp)
+	require.Empty(t, tsp.budgetRetryQueue)
+	require.Zero(t, msp.SpanCount())
+	d, ok := tsp.idToTrace.Load(traceKey(retriedID.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, sampling.NotSampled, d.(*sampling.TraceData).FinalDecision)
+}
+
+func TestBudgetRetryQueueIsFull(t *testing.T) {
+	msp := new(consumertest.TracesSink)
+	tsp := newBudgetRetryTestProcessor(msp, 1)
+
+	sampledID := bigendianconverter.UInt64ToTraceID(1, 1)
+	retriedID := bigendianconverter.UInt64ToTraceID(1, 2)
+	droppedID := bigendianconverter.UInt64ToTraceID(1, 3)
+	for _, id := range []pcommon.TraceID{sampledID, sampledID, retriedID, retriedID, droppedID, droppedID} {
+		require.NoError(t, tsp.ConsumeTraces(context.Background(), simpleTracesWithID(id)))
+	}
+
+	tickInNewSecond(tsp)
+	tickInNewSecond(tsp)
+	require.Len(t, tsp.budgetRetryQueue, 1)
+	d, ok := tsp.idToTrace.Load(traceKey(droppedID.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, sampling.NotSampled, d.(*sampling.TraceData).FinalDecision)
+}
+
+func TestBudgetRetryInvalidConfig(t *testing.T) {
+	id1 := config.NewComponentIDWithName("cascading_filter", "1")
+	ps1 := config.NewProcessorSettings(id1)
+	cfg := cfconfig.Config{
+		ProcessorSettings: &ps1,
+		DecisionWait:      defaultTestDecisionWait,
+		NumTraces:         10,
+		PolicyCfgs:        testPolicy,
+		BudgetRetry:       &cfconfig.BudgetRetryCfg{},
+	}
+	_, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	require.Error(t, err)
+
+	cfg.BudgetRetry = &cfconfig.BudgetRetryCfg{Window: 5 * time.Second}
+	sp, err := newTraceProcessor(zap.NewNop(), consumertest.NewNop(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, defaultBudgetRetryMaxTraces, sp.(*cascadingFilterSpanProcessor).budgetRetryMaxTraces)
+}
+
 func TestConcurrentTraceMapSize(t *testing.T) {
 	_, batches := generateIdsAndBatches(210)
 	const maxSize = 100
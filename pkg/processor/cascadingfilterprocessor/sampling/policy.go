@@ -39,6 +39,22 @@ type TraceData struct {
 	SpanCount int32
 	// ReceivedBatches stores all the batches received for the trace.
 	ReceivedBatches []ptrace.Traces
+	// Evaluation describes how the final decision was made, it's only recorded when explaining
+	// decisions is enabled and it's protected by the lock.
+	Evaluation *Evaluation
+}
+
+// Evaluation records how the sampling decision for a trace was made.
+type Evaluation struct {
+	// RejectedBy is the name of the trace reject filter which dropped the trace, if any.
+	RejectedBy string
+	// PolicyDecisions are the decisions of the trace accept filters, Pending for the filters
+	// which were not evaluated.
+	PolicyDecisions []Decision
+	// ProvisionalDecision is the decision of the filters, before the spans per second budget was applied.
+	ProvisionalDecision Decision
+	// FinalDecision is the decision applied to the trace.
+	FinalDecision Decision
 }
 
 // Decision gives the status of sampling decision.
@@ -0,0 +1,103 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// GroupBy is the list of resource attributes log records are grouped by,
+	// error bursts are detected in each group separately.
+	// Defaults to service.name and k8s.namespace.name.
+	GroupBy []string `mapstructure:"group_by"`
+	// MinSeverity is the lowest severity of log records counted as errors.
+	MinSeverity string `mapstructure:"min_severity"`
+	// Window is the length of the sliding window errors are counted in.
+	Window time.Duration `mapstructure:"window"`
+	// Threshold is the number of errors in the window which makes a burst.
+	Threshold int64 `mapstructure:"threshold"`
+	// MinErrorRatio is the minimal ratio of errors to all log records in the window
+	// which makes a burst. The ratio is not checked when it is 0.
+	MinErrorRatio float64 `mapstructure:"min_error_ratio"`
+	// EvaluationInterval is the interval in which the windows are checked for bursts.
+	EvaluationInterval time.Duration `mapstructure:"evaluation_interval"`
+	// MaxGroups is the maximal number of tracked groups,
+	// log records of new groups are not counted when it is reached.
+	MaxGroups int `mapstructure:"max_groups"`
+	// LogsExporter is the logs exporter alert records are sent to.
+	// Alerts are not emitted when it is not set.
+	LogsExporter *config.ComponentID `mapstructure:"logs_exporter"`
+	// MetricsExporter is the metrics exporter error burst metrics are sent to.
+	// Metrics are not emitted when it is not set.
+	MetricsExporter *config.ComponentID `mapstructure:"metrics_exporter"`
+}
+
+const (
+	defaultMinSeverity        = "ERROR"
+	defaultWindow             = time.Minute
+	defaultThreshold          = 100
+	defaultEvaluationInterval = 10 * time.Second
+	defaultMaxGroups          = 10000
+)
+
+// defaultGroupBy is not set in the default config, as the config loader would merge
+// a configured list into it instead of replacing it.
+var defaultGroupBy = []string{"service.name", "k8s.namespace.name"}
+
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings:  config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		MinSeverity:        defaultMinSeverity,
+		Window:             defaultWindow,
+		Threshold:          defaultThreshold,
+		EvaluationInterval: defaultEvaluationInterval,
+		MaxGroups:          defaultMaxGroups,
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if _, ok := severityTexts[strings.ToUpper(cfg.MinSeverity)]; !ok {
+		return fmt.Errorf("unknown min_severity %q", cfg.MinSeverity)
+	}
+	if cfg.Window < time.Second {
+		return errors.New("window must be at least 1s")
+	}
+	if cfg.Threshold <= 0 {
+		return errors.New("threshold must be positive")
+	}
+	if cfg.MinErrorRatio < 0 || cfg.MinErrorRatio > 1 {
+		return errors.New("min_error_ratio must be in range [0, 1]")
+	}
+	if cfg.EvaluationInterval <= 0 {
+		return errors.New("evaluation_interval must be positive")
+	}
+	if cfg.MaxGroups <= 0 {
+		return errors.New("max_groups must be positive")
+	}
+	if cfg.LogsExporter == nil && cfg.MetricsExporter == nil {
+		return errors.New("either logs_exporter or metrics_exporter has to be set")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	exporterID := config.NewComponentID("nop")
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	defaultCfg := factory.CreateDefaultConfig().(*Config)
+	defaultCfg.LogsExporter = &exporterID
+	assert.Equal(t, p0, defaultCfg)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings:  config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			GroupBy:            []string{"k8s.namespace.name"},
+			MinSeverity:        "warn",
+			Window:             5 * time.Minute,
+			Threshold:          20,
+			MinErrorRatio:      0.5,
+			EvaluationInterval: 30 * time.Second,
+			MaxGroups:          100,
+			LogsExporter:       &exporterID,
+			MetricsExporter:    &exporterID,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "unknown min severity", modify: func(cfg *Config) { cfg.MinSeverity = "SEVERE" }},
+		{name: "too short window", modify: func(cfg *Config) { cfg.Window = 500 * time.Millisecond }},
+		{name: "zero threshold", modify: func(cfg *Config) { cfg.Threshold = 0 }},
+		{name: "negative error ratio", modify: func(cfg *Config) { cfg.MinErrorRatio = -0.1 }},
+		{name: "error ratio above 1", modify: func(cfg *Config) { cfg.MinErrorRatio = 1.5 }},
+		{name: "zero evaluation interval", modify: func(cfg *Config) { cfg.EvaluationInterval = 0 }},
+		{name: "zero max groups", modify: func(cfg *Config) { cfg.MaxGroups = 0 }},
+		{name: "no exporter", modify: func(cfg *Config) { cfg.LogsExporter = nil }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			exporterID := config.NewComponentID("nop")
+			cfg.LogsExporter = &exporterID
+			require.NoError(t, cfg.Validate())
+
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	alertName                = "error_burst"
+	alertNameAttribute       = "alert.name"
+	alertStateAttribute      = "alert.state"
+	alertErrorCountAttribute = "alert.error_count"
+	alertLogCountAttribute   = "alert.log_count"
+	alertWindowAttribute     = "alert.window"
+	alertThresholdAttribute  = "alert.threshold"
+	alertStateFiring         = "firing"
+	alertStateResolved       = "resolved"
+
+	errorsMetric = "error_burst.errors"
+	logsMetric   = "error_burst.logs"
+	firingMetric = "error_burst.firing"
+)
+
+// severityTexts maps severity texts to severity numbers, for log records which only have the text set.
+var severityTexts = map[string]plog.SeverityNumber{
+	"TRACE":       plog.SeverityNumberTRACE,
+	"DEBUG":       plog.SeverityNumberDEBUG,
+	"INFO":        plog.SeverityNumberINFO,
+	"INFORMATION": plog.SeverityNumberINFO,
+	"NOTICE":      plog.SeverityNumberINFO2,
+	"WARN":        plog.SeverityNumberWARN,
+	"WARNING":     plog.SeverityNumberWARN,
+	"ERR":         plog.SeverityNumberERROR,
+	"ERROR":       plog.SeverityNumberERROR,
+	"CRIT":        plog.SeverityNumberFATAL,
+	"CRITICAL":    plog.SeverityNumberFATAL,
+	"ALERT":       plog.SeverityNumberFATAL2,
+	"EMERG":       plog.SeverityNumberFATAL3,
+	"EMERGENCY":   plog.SeverityNumberFATAL3,
+	"FATAL":       plog.SeverityNumberFATAL,
+	"PANIC":       plog.SeverityNumberFATAL,
+}
+
+type errorBurstProcessor struct {
+	groupBy           []string
+	minSeverity       plog.SeverityNumber
+	window            time.Duration
+	threshold         int64
+	minErrorRatio     float64
+	maxGroups         int
+	logsExporterID    *config.ComponentID
+	metricsExporterID *config.ComponentID
+	logger            *zap.Logger
+
+	mutex sync.Mutex
+	// groups of log records by the values of the group by attributes
+	groups map[string]*group
+	// maxGroupsLogged is set once the warning about reaching max groups is logged
+	maxGroupsLogged bool
+
+	evaluationInterval time.Duration
+	logsConsumer       consumer.Logs
+	metricsConsumer    consumer.Metrics
+	shutdownCh         chan struct{}
+	wg                 sync.WaitGroup
+
+	now func() time.Time
+}
+
+type group struct {
+	// values of the group by attributes, empty if the attribute is not set
+	values []string
+	window *slidingWindow
+	firing bool
+}
+
+func newErrorBurstProcessor(cfg *Config, logger *zap.Logger) *errorBurstProcessor {
+	groupBy := cfg.GroupBy
+	if groupBy == nil {
+		groupBy = defaultGroupBy
+	}
+
+	return &errorBurstProcessor{
+		groupBy:            groupBy,
+		minSeverity:        severityTexts[strings.ToUpper(cfg.MinSeverity)],
+		window:             cfg.Window,
+		threshold:          cfg.Threshold,
+		minErrorRatio:      cfg.MinErrorRatio,
+		maxGroups:          cfg.MaxGroups,
+		logsExporterID:     cfg.LogsExporter,
+		metricsExporterID:  cfg.MetricsExporter,
+		logger:             logger,
+		groups:             map[string]*group{},
+		evaluationInterval: cfg.EvaluationInterval,
+		shutdownCh:         make(chan struct{}),
+		now:                time.Now,
+	}
+}
+
+func (p *errorBurstProcessor) start(_ context.Context, host component.Host) error {
+	if p.logsExporterID != nil {
+		exporter, ok := host.GetExporters()[config.LogsDataType][*p.logsExporterID]
+		if !ok {
+			return fmt.Errorf("logs exporter %q not found, it has to be used in a logs pipeline", p.logsExporterID)
+		}
+		logsConsumer, ok := exporter.(consumer.Logs)
+		if !ok {
+			return fmt.Errorf("exporter %q is not a logs exporter", p.logsExporterID)
+		}
+		p.logsConsumer = logsConsumer
+	}
+
+	if p.metricsExporterID != nil {
+		exporter, ok := host.GetExporters()[config.MetricsDataType][*p.metricsExporterID]
+		if !ok {
+			return fmt.Errorf("metrics exporter %q not found, it has to be used in a metrics pipeline", p.metricsExporterID)
+		}
+		metricsConsumer, ok := exporter.(consumer.Metrics)
+		if !ok {
+			return fmt.Errorf("exporter %q is not a metrics exporter", p.metricsExporterID)
+		}
+		p.metricsConsumer = metricsConsumer
+	}
+
+	p.wg.Add(1)
+	go p.evaluateLoop()
+	return nil
+}
+
+func (p *errorBurstProcessor) shutdown(_ context.Context) error {
+	close(p.shutdownCh)
+	p.wg.Wait()
+	return nil
+}
+
+func (p *errorBurstProcessor) evaluateLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.evaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.evaluate(context.Background()); err != nil {
+				p.logger.Warn("Failed to emit error burst alerts", zap.Error(err))
+			}
+		case <-p.shutdownCh:
+			return
+		}
+	}
+}
+
+func (p *errorBurstProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		var errors, total int64
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				if severityNumber(lrs.At(k)) >= p.minSeverity {
+					errors++
+				}
+				total++
+			}
+		}
+		if total == 0 {
+			continue
+		}
+
+		if g := p.group(rls.At(i).Resource().Attributes(), now); g != nil {
+			g.window.add(now, errors, total)
+		}
+	}
+	return ld, nil
+}
+
+// severityNumber returns the severity number of the log record,
+// derived from the severity text if the number is not set.
+func severityNumber(lr plog.LogRecord) plog.SeverityNumber {
+	if number := lr.SeverityNumber(); number != plog.SeverityNumberUNDEFINED {
+		return number
+	}
+	return severityTexts[strings.ToUpper(strings.TrimSpace(lr.SeverityText()))]
+}
+
+// group returns the group of the resource, or nil if it's a new group and there are max groups already.
+func (p *errorBurstProcessor) group(attrs pcommon.Map, now time.Time) *group {
+	values := make([]string, len(p.groupBy))
+	for i, key := range p.groupBy {
+		if value, ok := attrs.Get(key); ok {
+			values[i] = value.AsString()
+		}
+	}
+	key := strings.Join(values, "\x00")
+
+	if g, ok := p.groups[key]; ok {
+		return g
+	}
+	if len(p.groups) >= p.maxGroups {
+		if !p.maxGroupsLogged {
+			p.logger.Warn("Max groups reached, log records of new groups are not counted", zap.Int("max_groups", p.maxGroups))
+			p.maxGroupsLogged = true
+		}
+		return nil
+	}
+
+	g := &group{values: values, window: newSlidingWindow(p.window, now)}
+	p.groups[key] = g
+	return g
+}
+
+// evaluate checks the windows of all groups for error bursts, sends alerts for the bursts
+// which started or ended since the last evaluation and the metrics of all groups.
+func (p *errorBurstProcessor) evaluate(ctx context.Context) error {
+	alerts, metrics := p.collect()
+
+	var err error
+	if p.logsConsumer != nil && alerts.LogRecordCount() > 0 {
+		err = multierr.Append(err, p.logsConsumer.ConsumeLogs(ctx, alerts))
+	}
+	if p.metricsConsumer != nil && metrics.DataPointCount() > 0 {
+		err = multierr.Append(err, p.metricsConsumer.ConsumeMetrics(ctx, metrics))
+	}
+	return err
+}
+
+// collect returns the alerts and metrics of all groups, groups without any log records
+// in the window are dropped.
+func (p *errorBurstProcessor) collect() (plog.Logs, pmetric.Metrics) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	alerts := plog.NewLogs()
+	metrics := pmetric.NewMetrics()
+
+	keys := make([]string, 0, len(p.groups))
+	for key := range p.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		g := p.groups[key]
+		errors, total := g.window.counts(now)
+
+		if burst := p.isBurst(errors, total); burst != g.firing {
+			g.firing = burst
+			p.appendAlert(alerts, g, errors, total, now)
+		}
+		if total == 0 {
+			delete(p.groups, key)
+			continue
+		}
+		if p.metricsConsumer != nil {
+			p.appendMetrics(metrics, g, errors, total, now)
+		}
+	}
+	return alerts, metrics
+}
+
+func (p *errorBurstProcessor) isBurst(errors int64, total int64) bool {
+	if errors < p.threshold {
+		return false
+	}
+	return p.minErrorRatio == 0 || float64(errors)/float64(total) >= p.minErrorRatio
+}
+
+func (p *errorBurstProcessor) setGroupAttributes(attrs pcommon.Map, g *group) {
+	for i, key := range p.groupBy {
+		if g.values[i] != "" {
+			attrs.UpsertString(key, g.values[i])
+		}
+	}
+}
+
+func (p *errorBurstProcessor) appendAlert(alerts plog.Logs, g *group, errors int64, total int64, now time.Time) {
+	rl := alerts.ResourceLogs().AppendEmpty()
+	p.setGroupAttributes(rl.Resource().Attributes(), g)
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(typeStr)
+
+	lr := sl.LogRecords().AppendEmpty()
+	timestamp := pcommon.NewTimestampFromTime(now)
+	lr.SetTimestamp(timestamp)
+	lr.SetObservedTimestamp(timestamp)
+
+	state := alertStateResolved
+	if g.firing {
+		state = alertStateFiring
+		lr.SetSeverityNumber(plog.SeverityNumberWARN)
+		lr.SetSeverityText("WARN")
+		lr.Body().SetStringVal(fmt.Sprintf("Error burst: %d errors out of %d log records in the last %s, threshold is %d",
+			errors, total, p.window, p.threshold))
+	} else {
+		lr.SetSeverityNumber(plog.SeverityNumberINFO)
+		lr.SetSeverityText("INFO")
+		lr.Body().SetStringVal(fmt.Sprintf("Error burst resolved: %d errors out of %d log records in the last %s, threshold is %d",
+			errors, total, p.window, p.threshold))
+	}
+
+	attrs := lr.Attributes()
+	attrs.UpsertString(alertNameAttribute, alertName)
+	attrs.UpsertString(alertStateAttribute, state)
+	attrs.UpsertInt(alertErrorCountAttribute, errors)
+	attrs.UpsertInt(alertLogCountAttribute, total)
+	attrs.UpsertString(alertWindowAttribute, p.window.String())
+	attrs.UpsertInt(alertThresholdAttribute, p.threshold)
+}
+
+func (p *errorBurstProcessor) appendMetrics(metrics pmetric.Metrics, g *group, errors int64, total int64, now time.Time) {
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	p.setGroupAttributes(rm.Resource().Attributes(), g)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(typeStr)
+
+	timestamp := pcommon.NewTimestampFromTime(now)
+	appendGauge := func(name string, description string, value int64) {
+		m := sm.Metrics().AppendEmpty()
+		m.SetName(name)
+		m.SetDescription(description)
+		m.SetUnit("1")
+		m.SetDataType(pmetric.MetricDataTypeGauge)
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(timestamp)
+		dp.SetIntVal(value)
+	}
+
+	firing := int64(0)
+	if g.firing {
+		firing = 1
+	}
+	appendGauge(errorsMetric, "Number of error log records in the window", errors)
+	appendGauge(logsMetric, "Number of log records in the window", total)
+	appendGauge(firingMetric, "Whether an error burst alert is firing", firing)
+}
@@ -0,0 +1,274 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+var testExporterID = config.NewComponentID("sink")
+
+// newTestProcessor creates a processor started with sink exporters for both alerts and metrics.
+func newTestProcessor(t *testing.T, modify func(*Config)) (*errorBurstProcessor, *consumertest.LogsSink, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Threshold = 3
+	cfg.LogsExporter = &testExporterID
+	cfg.MetricsExporter = &testExporterID
+	cfg.EvaluationInterval = time.Hour
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	processor := newErrorBurstProcessor(cfg, zap.NewNop())
+	processor.now = func() time.Time { return testNow }
+
+	logsSink := new(consumertest.LogsSink)
+	metricsSink := new(consumertest.MetricsSink)
+	require.NoError(t, processor.start(context.Background(), newTestHost(logsSink, metricsSink)))
+	t.Cleanup(func() { require.NoError(t, processor.shutdown(context.Background())) })
+	return processor, logsSink, metricsSink
+}
+
+// newLogs creates logs of a service with a record for each of the severities.
+func newLogs(service string, severities ...plog.SeverityNumber) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().UpsertString("service.name", service)
+	rl.Resource().Attributes().UpsertString("k8s.namespace.name", "default")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, severity := range severities {
+		lr := lrs.AppendEmpty()
+		lr.SetSeverityNumber(severity)
+		lr.Body().SetStringVal("message")
+	}
+	return logs
+}
+
+func repeat(severity plog.SeverityNumber, n int) []plog.SeverityNumber {
+	severities := make([]plog.SeverityNumber, n)
+	for i := range severities {
+		severities[i] = severity
+	}
+	return severities
+}
+
+// alerts returns the alert records sent to the sink.
+func alerts(sink *consumertest.LogsSink) []plog.LogRecord {
+	var records []plog.LogRecord
+	for _, logs := range sink.AllLogs() {
+		rls := logs.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			lrs := rls.At(i).ScopeLogs().At(0).LogRecords()
+			for j := 0; j < lrs.Len(); j++ {
+				records = append(records, lrs.At(j))
+			}
+		}
+	}
+	return records
+}
+
+func attribute(t *testing.T, lr plog.LogRecord, key string) string {
+	value, ok := lr.Attributes().Get(key)
+	require.True(t, ok, key)
+	return value.AsString()
+}
+
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.LogsSink
+	*consumertest.MetricsSink
+}
+
+func (e *sinkExporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+type testHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h *testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func newTestHost(logsSink *consumertest.LogsSink, metricsSink *consumertest.MetricsSink) component.Host {
+	return &testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.LogsDataType:    {testExporterID: &sinkExporter{LogsSink: logsSink}},
+			config.MetricsDataType: {testExporterID: &sinkExporter{MetricsSink: metricsSink}},
+		},
+	}
+}
+
+func TestProcessLogsPassesLogsThrough(t *testing.T) {
+	processor, _, _ := newTestProcessor(t, nil)
+
+	logs := newLogs("api", plog.SeverityNumberERROR, plog.SeverityNumberINFO)
+	processed, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	assert.Equal(t, logs, processed)
+}
+
+func TestErrorBurstFiresAndResolves(t *testing.T) {
+	processor, logsSink, metricsSink := newTestProcessor(t, nil)
+
+	_, err := processor.processLogs(context.Background(), newLogs("api",
+		plog.SeverityNumberERROR, plog.SeverityNumberINFO, plog.SeverityNumberFATAL))
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+	assert.Empty(t, alerts(logsSink))
+
+	_, err = processor.processLogs(context.Background(), newLogs("api", plog.SeverityNumberERROR2))
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+
+	records := alerts(logsSink)
+	require.Len(t, records, 1)
+	firing := records[0]
+	assert.Equal(t, alertName, attribute(t, firing, alertNameAttribute))
+	assert.Equal(t, alertStateFiring, attribute(t, firing, alertStateAttribute))
+	assert.Equal(t, "3", attribute(t, firing, alertErrorCountAttribute))
+	assert.Equal(t, "4", attribute(t, firing, alertLogCountAttribute))
+	assert.Equal(t, "1m0s", attribute(t, firing, alertWindowAttribute))
+	assert.Equal(t, "3", attribute(t, firing, alertThresholdAttribute))
+	assert.Equal(t, plog.SeverityNumberWARN, firing.SeverityNumber())
+	assert.Equal(t, testNow, firing.Timestamp().AsTime())
+
+	resource := logsSink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().AsRaw()
+	assert.Equal(t, map[string]interface{}{"service.name": "api", "k8s.namespace.name": "default"}, resource)
+
+	// the alert is sent only once while the burst lasts
+	require.NoError(t, processor.evaluate(context.Background()))
+	assert.Len(t, alerts(logsSink), 1)
+
+	metrics := metricsSink.AllMetrics()
+	require.Len(t, metrics, 3)
+	gauges := metricValues(metrics[2])
+	assert.Equal(t, map[string]int64{errorsMetric: 3, logsMetric: 4, firingMetric: 1}, gauges)
+
+	processor.now = func() time.Time { return testNow.Add(2 * time.Minute) }
+	require.NoError(t, processor.evaluate(context.Background()))
+
+	records = alerts(logsSink)
+	require.Len(t, records, 2)
+	resolved := records[1]
+	assert.Equal(t, alertStateResolved, attribute(t, resolved, alertStateAttribute))
+	assert.Equal(t, "0", attribute(t, resolved, alertErrorCountAttribute))
+	assert.Equal(t, plog.SeverityNumberINFO, resolved.SeverityNumber())
+
+	// groups without log records in the window are dropped
+	assert.Empty(t, processor.groups)
+}
+
+func metricValues(metrics pmetric.Metrics) map[string]int64 {
+	values := map[string]int64{}
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		values[ms.At(i).Name()] = ms.At(i).Gauge().DataPoints().At(0).IntVal()
+	}
+	return values
+}
+
+func TestErrorBurstMinErrorRatio(t *testing.T) {
+	processor, logsSink, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.MinErrorRatio = 0.5
+	})
+
+	severities := append(repeat(plog.SeverityNumberERROR, 3), repeat(plog.SeverityNumberINFO, 4)...)
+	_, err := processor.processLogs(context.Background(), newLogs("api", severities...))
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+	assert.Empty(t, alerts(logsSink))
+
+	_, err = processor.processLogs(context.Background(), newLogs("api", plog.SeverityNumberERROR))
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+	assert.Len(t, alerts(logsSink), 1)
+}
+
+func TestErrorBurstGroups(t *testing.T) {
+	processor, logsSink, _ := newTestProcessor(t, nil)
+
+	_, err := processor.processLogs(context.Background(), newLogs("api", repeat(plog.SeverityNumberERROR, 2)...))
+	require.NoError(t, err)
+	_, err = processor.processLogs(context.Background(), newLogs("web", repeat(plog.SeverityNumberERROR, 3)...))
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+
+	require.Len(t, alerts(logsSink), 1)
+	service, ok := logsSink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "web", service.StringVal())
+}
+
+func TestErrorBurstSeverityText(t *testing.T) {
+	processor, logsSink, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.MinSeverity = "warn"
+	})
+
+	logs := newLogs("api", repeat(plog.SeverityNumberUNDEFINED, 4)...)
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i, text := range []string{"warning", "Error", "info", " CRITICAL "} {
+		lrs.At(i).SetSeverityText(text)
+	}
+	_, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	require.NoError(t, processor.evaluate(context.Background()))
+
+	records := alerts(logsSink)
+	require.Len(t, records, 1)
+	assert.Equal(t, "3", attribute(t, records[0], alertErrorCountAttribute))
+}
+
+func TestErrorBurstMaxGroups(t *testing.T) {
+	processor, _, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.MaxGroups = 1
+	})
+
+	_, err := processor.processLogs(context.Background(), newLogs("api", plog.SeverityNumberERROR))
+	require.NoError(t, err)
+	_, err = processor.processLogs(context.Background(), newLogs("web", plog.SeverityNumberERROR))
+	require.NoError(t, err)
+	assert.Len(t, processor.groups, 1)
+}
+
+func TestStartExporterNotFound(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	missingID := config.NewComponentID("missing")
+	cfg.LogsExporter = &missingID
+
+	processor := newErrorBurstProcessor(cfg, zap.NewNop())
+	err := processor.start(context.Background(), newTestHost(nil, nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import "time"
+
+// windowBuckets is the number of buckets a window is split into.
+// The window slides by the length of a bucket.
+const windowBuckets = 10
+
+type bucket struct {
+	errors int64
+	total  int64
+}
+
+// slidingWindow counts log records of the last window. Records are counted in buckets,
+// which are dropped once they are older than the window.
+type slidingWindow struct {
+	bucketSize time.Duration
+	buckets    [windowBuckets]bucket
+	// current is the index of the bucket records are counted in, currentStart is its start time.
+	current      int
+	currentStart time.Time
+}
+
+func newSlidingWindow(window time.Duration, now time.Time) *slidingWindow {
+	bucketSize := window / windowBuckets
+	return &slidingWindow{
+		bucketSize:   bucketSize,
+		currentStart: now.Truncate(bucketSize),
+	}
+}
+
+// advance slides the window to now, dropping the buckets which fell out of it.
+func (w *slidingWindow) advance(now time.Time) {
+	start := now.Truncate(w.bucketSize)
+	steps := int(start.Sub(w.currentStart) / w.bucketSize)
+	if steps <= 0 {
+		return
+	}
+	if steps > windowBuckets {
+		steps = windowBuckets
+	}
+	for i := 0; i < steps; i++ {
+		w.current = (w.current + 1) % windowBuckets
+		w.buckets[w.current] = bucket{}
+	}
+	w.currentStart = start
+}
+
+func (w *slidingWindow) add(now time.Time, errors int64, total int64) {
+	w.advance(now)
+	w.buckets[w.current].errors += errors
+	w.buckets[w.current].total += total
+}
+
+// counts returns the number of errors and all log records in the window ending now.
+func (w *slidingWindow) counts(now time.Time) (errors int64, total int64) {
+	w.advance(now)
+	for _, b := range w.buckets {
+		errors += b.errors
+		total += b.total
+	}
+	return errors, total
+}
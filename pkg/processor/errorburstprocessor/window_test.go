@@ -0,0 +1,52 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorburstprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	w := newSlidingWindow(time.Minute, testNow)
+
+	w.add(testNow, 1, 2)
+	w.add(testNow.Add(30*time.Second), 3, 4)
+	errors, total := w.counts(testNow.Add(30 * time.Second))
+	assert.Equal(t, int64(4), errors)
+	assert.Equal(t, int64(6), total)
+
+	// the first bucket falls out of the window
+	errors, total = w.counts(testNow.Add(time.Minute))
+	assert.Equal(t, int64(3), errors)
+	assert.Equal(t, int64(4), total)
+
+	w.add(testNow.Add(80*time.Second), 5, 5)
+	errors, total = w.counts(testNow.Add(85 * time.Second))
+	assert.Equal(t, int64(8), errors)
+	assert.Equal(t, int64(9), total)
+
+	// all buckets fall out of the window
+	errors, total = w.counts(testNow.Add(10 * time.Minute))
+	assert.Zero(t, errors)
+	assert.Zero(t, total)
+
+	w.add(testNow.Add(10*time.Minute), 1, 1)
+	errors, total = w.counts(testNow.Add(10 * time.Minute))
+	assert.Equal(t, int64(1), errors)
+	assert.Equal(t, int64(1), total)
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+// maxCachedPods bounds the number of pods the attributes are cached for. The cache is cleared
+// once it's exceeded, which drops the entries of deleted pods as well.
+const maxCachedPods = 10000
+
+type podAttribute struct {
+	key   string
+	value string
+}
+
+type cachedPodAttributes struct {
+	// resourceVersion is the version of the pod the attributes were computed from,
+	// every change of the labels or other metadata of the pod changes it, which invalidates the entry.
+	resourceVersion string
+	attributes      []podAttribute
+}
+
+// podAttributesCache caches the attributes added to the resources of each pod, keyed by the pod UID
+// and its resource version, so repeated records from the same pod don't iterate over the metadata map again.
+// The zero value is ready to use.
+type podAttributesCache struct {
+	mutex sync.RWMutex
+	pods  map[string]cachedPodAttributes
+}
+
+func (c *podAttributesCache) get(pod *kube.Pod) []podAttribute {
+	if pod.PodUID == "" || pod.ResourceVersion == "" {
+		return sortedPodAttributes(pod.Attributes)
+	}
+
+	c.mutex.RLock()
+	cached, ok := c.pods[pod.PodUID]
+	c.mutex.RUnlock()
+	if ok && cached.resourceVersion == pod.ResourceVersion {
+		return cached.attributes
+	}
+
+	attributes := sortedPodAttributes(pod.Attributes)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.pods == nil || len(c.pods) >= maxCachedPods {
+		c.pods = make(map[string]cachedPodAttributes)
+	}
+	c.pods[pod.PodUID] = cachedPodAttributes{resourceVersion: pod.ResourceVersion, attributes: attributes}
+	return attributes
+}
+
+// sortedPodAttributes returns the attributes sorted by their keys, so they are always added in the same order.
+func sortedPodAttributes(attributes map[string]string) []podAttribute {
+	sorted := make([]podAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		sorted = append(sorted, podAttribute{key: key, value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+	return sorted
+}
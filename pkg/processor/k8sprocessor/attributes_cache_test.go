@@ -0,0 +1,74 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+func TestPodAttributesCache(t *testing.T) {
+	var cache podAttributesCache
+
+	pod := &kube.Pod{
+		PodUID:          "1234",
+		ResourceVersion: "1",
+		Attributes:      map[string]string{"k8s.pod.name": "nginx-1", "k8s.namespace.name": "default"},
+	}
+	expected := []podAttribute{
+		{key: "k8s.namespace.name", value: "default"},
+		{key: "k8s.pod.name", value: "nginx-1"},
+	}
+	assert.Equal(t, expected, cache.get(pod))
+
+	// the cached attributes are returned for the same resource version of the pod
+	assert.Equal(t, expected, cache.get(&kube.Pod{
+		PodUID:          "1234",
+		ResourceVersion: "1",
+		Attributes:      map[string]string{"k8s.pod.name": "nginx-1", "k8s.namespace.name": "default"},
+	}))
+
+	// a new resource version, e.g. with changed labels, invalidates the entry
+	updated := &kube.Pod{
+		PodUID:          "1234",
+		ResourceVersion: "2",
+		Attributes:      map[string]string{"k8s.pod.name": "nginx-1", "k8s.pod.label.app": "nginx"},
+	}
+	assert.Equal(t, []podAttribute{
+		{key: "k8s.pod.label.app", value: "nginx"},
+		{key: "k8s.pod.name", value: "nginx-1"},
+	}, cache.get(updated))
+	assert.Len(t, cache.pods, 1)
+
+	// pods without UID or resource version aren't cached
+	assert.Equal(t, []podAttribute{{key: "k8s.pod.name", value: "nginx-2"}},
+		cache.get(&kube.Pod{ResourceVersion: "1", Attributes: map[string]string{"k8s.pod.name": "nginx-2"}}))
+	assert.Equal(t, []podAttribute{{key: "k8s.pod.name", value: "nginx-3"}},
+		cache.get(&kube.Pod{PodUID: "5678", Attributes: map[string]string{"k8s.pod.name": "nginx-3"}}))
+	assert.Len(t, cache.pods, 1)
+}
+
+func TestPodAttributesCacheIsBounded(t *testing.T) {
+	var cache podAttributesCache
+
+	for i := 0; i < maxCachedPods+1; i++ {
+		cache.get(&kube.Pod{PodUID: strconv.Itoa(i), ResourceVersion: "1"})
+	}
+	assert.Len(t, cache.pods, 1)
+}
@@ -35,6 +35,9 @@ type fakeClient struct {
 	Associations []kube.Association
 	Informer     cache.SharedInformer
 	StopCh       chan struct{}
+	NotSynced    bool
+	// RefreshedPods contains the pods passed to RefreshPod as namespace/name/uid.
+	RefreshedPods []string
 }
 
 func selectors() (labels.Selector, fields.Selector) {
@@ -84,6 +87,16 @@ func (f *fakeClient) Start() {
 	}
 }
 
+// HasSynced returns true unless NotSynced is set.
+func (f *fakeClient) HasSynced() bool {
+	return !f.NotSynced
+}
+
+// RefreshPod records the refreshed pod.
+func (f *fakeClient) RefreshPod(namespace, name, uid string) {
+	f.RefreshedPods = append(f.RefreshedPods, namespace+"/"+name+"/"+uid)
+}
+
 // Stop is a noop for FakeClient.
 func (f *fakeClient) Stop() {
 	close(f.StopCh)
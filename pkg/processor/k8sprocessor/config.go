@@ -15,6 +15,9 @@
 package k8sprocessor
 
 import (
+	"errors"
+	"time"
+
 	"go.opentelemetry.io/collector/config"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -51,9 +54,31 @@ type Config struct {
 	// Exclude section allows to define names of pod that should be
 	// ignored while tagging.
 	Exclude ExcludeConfig `mapstructure:"exclude"`
+
+	// WaitForMetadata makes the processor wait until Pod metadata is synced
+	// from the K8S API before reporting that it has started, so that data
+	// received right after a restart is tagged with Pod metadata.
+	WaitForMetadata bool `mapstructure:"wait_for_metadata"`
+
+	// WaitForMetadataTimeout is the maximum time to wait for Pod metadata
+	// to be synced. The processor fails to start if it is exceeded.
+	WaitForMetadataTimeout time.Duration `mapstructure:"wait_for_metadata_timeout"`
+
+	// PodEventsBus is the id of the pod events bus extension. The processor subscribes
+	// to pod events published to it to update its Pod metadata cache right after pods
+	// are created or replaced, without waiting for the pod watch.
+	PodEventsBus *config.ComponentID `mapstructure:"pod_events_bus"`
+
+	// PodIndex is the id of the pod index extension. The processor registers its
+	// Pod metadata cache with it, so that other components can resolve IP addresses
+	// to pods without running their own informers.
+	PodIndex *config.ComponentID `mapstructure:"pod_index"`
 }
 
 func (cfg *Config) Validate() error {
+	if cfg.WaitForMetadata && cfg.WaitForMetadataTimeout <= 0 {
+		return errors.New("wait_for_metadata_timeout must be positive when wait_for_metadata is enabled")
+	}
 	return cfg.APIConfig.Validate()
 }
 
@@ -17,6 +17,7 @@ package k8sprocessor
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,6 +50,8 @@ func TestLoadConfig(t *testing.T) {
 			ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
 			APIConfig:         k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeServiceAccount},
 			Extract:           ExtractConfig{Delimiter: ", "},
+
+			WaitForMetadataTimeout: 10 * time.Second,
 		},
 		p0,
 	)
@@ -126,6 +129,8 @@ func TestLoadConfig(t *testing.T) {
 					{Name: "jaeger-collector"},
 				},
 			},
+			WaitForMetadata:        true,
+			WaitForMetadataTimeout: 30 * time.Second,
 		},
 		p1,
 	)
@@ -16,6 +16,7 @@ package k8sprocessor
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
@@ -29,6 +30,8 @@ import (
 const (
 	// The value of "type" key in configuration.
 	typeStr = "k8s_tagger"
+
+	defaultWaitForMetadataTimeout = 10 * time.Second
 )
 
 var kubeClientProvider = kube.ClientProvider(nil)
@@ -52,6 +55,7 @@ func createDefaultConfig() config.Processor {
 		Extract: ExtractConfig{
 			Delimiter: DefaultDelimiter,
 		},
+		WaitForMetadataTimeout: defaultWaitForMetadataTimeout,
 	}
 }
 
@@ -202,5 +206,17 @@ func createProcessorOpts(cfg config.Processor) []Option {
 
 	opts = append(opts, WithExcludes(oCfg.Exclude))
 
+	if oCfg.WaitForMetadata {
+		opts = append(opts, WithWaitForMetadata(oCfg.WaitForMetadataTimeout))
+	}
+
+	if oCfg.PodEventsBus != nil {
+		opts = append(opts, WithPodEventsBus(*oCfg.PodEventsBus))
+	}
+
+	if oCfg.PodIndex != nil {
+		opts = append(opts, WithPodIndex(*oCfg.PodIndex))
+	}
+
 	return opts
 }
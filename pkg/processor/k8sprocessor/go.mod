@@ -3,6 +3,8 @@ module github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8spr
 go 1.18
 
 require (
+	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension v0.0.54-beta.0
+	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podindexextension v0.0.54-beta.0
 	github.com/onsi/ginkgo v1.14.1 // indirect
 	github.com/onsi/gomega v1.10.2 // indirect
 	github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig v0.54.0
@@ -75,3 +77,7 @@ require (
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.1 // indirect
 	sigs.k8s.io/yaml v1.2.0 // indirect
 )
+
+replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension => ./../../extension/podeventsbusextension
+
+replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podindexextension => ./../../extension/podindexextension
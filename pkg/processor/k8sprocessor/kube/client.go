@@ -15,6 +15,7 @@
 package kube
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -22,10 +23,12 @@ import (
 
 	"go.uber.org/zap"
 	api_v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
@@ -45,6 +48,12 @@ type WatchClient struct {
 	op          OwnerAPI
 	delimiter   string
 
+	labelSelector labels.Selector
+	fieldSelector fields.Selector
+	// refreshing contains the pods which are being fetched by RefreshPod.
+	refreshMut sync.Mutex
+	refreshing map[PodIdentifier]struct{}
+
 	// A map containing Pod related data, used to associate them with resources.
 	// Key can be either an IP address or Pod UID
 	Pods         map[PodIdentifier]*Pod
@@ -78,6 +87,7 @@ func New(
 		stopCh:       make(chan struct{}),
 		delimiter:    delimiter,
 		Pods:         map[PodIdentifier]*Pod{},
+		refreshing:   map[PodIdentifier]struct{}{},
 	}
 	go c.deleteLoop(deleteInterval, gracePeriod)
 
@@ -95,6 +105,7 @@ func New(
 	if err != nil {
 		return nil, err
 	}
+	c.labelSelector, c.fieldSelector = labelSelector, fieldSelector
 
 	if c.Rules.OwnerLookupEnabled {
 		if newOwnerProviderFunc == nil {
@@ -147,6 +158,14 @@ func (c *WatchClient) Start() {
 	c.informer.Run(c.stopCh)
 }
 
+// HasSynced returns true once the pod informer and, if enabled, the owner informers have synced.
+func (c *WatchClient) HasSynced() bool {
+	if c.op != nil && !c.op.HasSynced() {
+		return false
+	}
+	return c.informer.HasSynced()
+}
+
 // Stop signals the the k8s watcher/informer to stop watching for new events.
 func (c *WatchClient) Stop() {
 	close(c.stopCh)
@@ -254,6 +273,77 @@ func (c *WatchClient) GetPod(identifier PodIdentifier) (*Pod, bool) {
 	return nil, false
 }
 
+// RefreshPod fetches the pod from the K8S API and updates the cache in the background.
+// A pod which no longer exists is forgotten. Pods which don't match the filters are skipped,
+// same as pods already cached with the given UID and an IP address.
+func (c *WatchClient) RefreshPod(namespace, name, uid string) {
+	if c.Filters.Namespace != "" && namespace != c.Filters.Namespace {
+		return
+	}
+
+	id := generatePodIDFromName(Pod{Name: name, Namespace: namespace})
+	c.m.RLock()
+	cached, ok := c.Pods[id]
+	c.m.RUnlock()
+	if ok && cached.PodUID == uid && cached.Address != "" {
+		return
+	}
+
+	c.refreshMut.Lock()
+	if _, ok := c.refreshing[id]; ok {
+		c.refreshMut.Unlock()
+		return
+	}
+	c.refreshing[id] = struct{}{}
+	c.refreshMut.Unlock()
+
+	go func() {
+		defer func() {
+			c.refreshMut.Lock()
+			delete(c.refreshing, id)
+			c.refreshMut.Unlock()
+		}()
+		c.refreshPod(namespace, name, cached)
+	}()
+}
+
+func (c *WatchClient) refreshPod(namespace, name string, cached *Pod) {
+	ctx, cancel := context.WithTimeout(context.Background(), podRefreshTimeout)
+	defer cancel()
+
+	pod, err := c.kc.CoreV1().Pods(namespace).Get(ctx, name, v1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if cached != nil {
+			c.logger.Debug("Refreshed pod not found, forgetting it", zap.String("pod", name), zap.String("namespace", namespace))
+			c.forgetPod(&api_v1.Pod{
+				ObjectMeta: v1.ObjectMeta{Name: cached.Name, Namespace: cached.Namespace, UID: types.UID(cached.PodUID)},
+				Status:     api_v1.PodStatus{PodIP: cached.Address},
+			})
+		}
+		return
+	case err != nil:
+		c.logger.Debug("Failed to refresh pod", zap.String("pod", name), zap.String("namespace", namespace), zap.Error(err))
+		return
+	}
+
+	if !c.labelSelector.Matches(labels.Set(pod.Labels)) || !c.fieldSelector.Matches(podFields(pod)) {
+		return
+	}
+	observability.RecordPodRefreshed()
+	c.addOrUpdatePod(removeUnnecessaryPodData(pod, c.Rules))
+}
+
+// podFields returns the pod fields which can be used in field filters.
+func podFields(pod *api_v1.Pod) fields.Set {
+	return fields.Set{
+		"metadata.name":      pod.Name,
+		"metadata.namespace": pod.Namespace,
+		podNodeField:         pod.Spec.NodeName,
+		"status.phase":       string(pod.Status.Phase),
+	}
+}
+
 func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 	tags := map[string]string{}
 	if c.Rules.PodName {
@@ -469,11 +559,12 @@ func (c *WatchClient) extractField(v string, r FieldExtractionRule) string {
 
 func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 	newPod := &Pod{
-		Name:      pod.Name,
-		Namespace: pod.Namespace,
-		Address:   pod.Status.PodIP,
-		PodUID:    string(pod.UID),
-		StartTime: pod.Status.StartTime,
+		Name:            pod.Name,
+		Namespace:       pod.Namespace,
+		Address:         pod.Status.PodIP,
+		PodUID:          string(pod.UID),
+		ResourceVersion: pod.ResourceVersion,
+		StartTime:       pod.Status.StartTime,
 	}
 
 	if c.shouldIgnorePod(pod) {
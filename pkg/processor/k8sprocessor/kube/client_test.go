@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 
@@ -93,12 +94,14 @@ func podAddAndUpdateTest(t *testing.T, c *WatchClient, handler func(obj interfac
 	pod.Name = "podC"
 	pod.Status.PodIP = "2.2.2.2"
 	pod.UID = "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	pod.ResourceVersion = "1234"
 	handler(pod)
 	assert.Equal(t, len(c.Pods), 3)
 	got = c.Pods["2.2.2.2"]
 	assert.Equal(t, got.Address, "2.2.2.2")
 	assert.Equal(t, got.Name, "podC")
 	assert.Equal(t, got.PodUID, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	assert.Equal(t, got.ResourceVersion, "1234")
 	got = c.Pods["aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"]
 	assert.Equal(t, got.Address, "2.2.2.2")
 	assert.Equal(t, got.Name, "podC")
@@ -1125,6 +1128,102 @@ func Test_PodsGetAddedAndDeletedFromCache(t *testing.T) {
 	})
 }
 
+func TestRefreshPod(t *testing.T) {
+	newPod := func(name, uid, ip string) *api_v1.Pod {
+		pod := &api_v1.Pod{}
+		pod.Name = name
+		pod.Namespace = "default"
+		pod.UID = types.UID(uid)
+		pod.Labels = map[string]string{"app": "nginx"}
+		pod.Status.PodIP = ip
+		return pod
+	}
+	createPod := func(t *testing.T, c *WatchClient, pod *api_v1.Pod) {
+		_, err := c.kc.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, meta_v1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	getActions := func(c *WatchClient) int {
+		return len(c.kc.(*fake.Clientset).Actions())
+	}
+
+	t.Run("new pod is fetched", func(t *testing.T) {
+		c, _ := newTestClient(t)
+		createPod(t, c, newPod("nginx-1", "1234", "1.1.1.1"))
+
+		c.RefreshPod("default", "nginx-1", "1234")
+		assert.Eventually(t, func() bool {
+			pod, ok := c.GetPod(PodIdentifier("1.1.1.1"))
+			return ok && pod.Name == "nginx-1"
+		}, time.Second, 10*time.Millisecond)
+		pod, ok := c.GetPod(PodIdentifier("1234"))
+		require.True(t, ok)
+		assert.Equal(t, "nginx-1", pod.Name)
+	})
+
+	t.Run("cached pod is not fetched", func(t *testing.T) {
+		c, _ := newTestClient(t)
+		pod := newPod("nginx-1", "1234", "1.1.1.1")
+		c.handlePodAdd(pod)
+		createPod(t, c, pod)
+		actions := getActions(c)
+
+		c.RefreshPod("default", "nginx-1", "1234")
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, actions, getActions(c))
+	})
+
+	t.Run("replaced pod is fetched", func(t *testing.T) {
+		c, _ := newTestClient(t)
+		c.handlePodAdd(newPod("nginx-0", "1234", "1.1.1.1"))
+		createPod(t, c, newPod("nginx-0", "5678", "2.2.2.2"))
+
+		c.RefreshPod("default", "nginx-0", "5678")
+		assert.Eventually(t, func() bool {
+			pod, ok := c.GetPod(PodIdentifier("nginx-0.default"))
+			return ok && pod.PodUID == "5678"
+		}, time.Second, 10*time.Millisecond)
+		pod, ok := c.GetPod(PodIdentifier("2.2.2.2"))
+		require.True(t, ok)
+		assert.Equal(t, "5678", pod.PodUID)
+	})
+
+	t.Run("deleted pod is forgotten", func(t *testing.T) {
+		c, _ := newTestClient(t)
+		c.handlePodAdd(newPod("nginx-1", "1234", "1.1.1.1"))
+
+		c.RefreshPod("default", "nginx-1", "5678")
+		assert.Eventually(t, func() bool {
+			c.deleteMut.Lock()
+			defer c.deleteMut.Unlock()
+			return len(c.deleteQueue) == 3
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("filtered out pod is not cached", func(t *testing.T) {
+		c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{}, Filters{
+			Labels: []FieldFilter{{Key: "app", Value: "redis", Op: selection.Equals}},
+		})
+		createPod(t, c, newPod("nginx-1", "1234", "1.1.1.1"))
+
+		c.RefreshPod("default", "nginx-1", "1234")
+		assert.Eventually(t, func() bool {
+			return getActions(c) > 1
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		_, ok := c.GetPod(PodIdentifier("1.1.1.1"))
+		assert.False(t, ok)
+	})
+
+	t.Run("other namespace is skipped", func(t *testing.T) {
+		c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{}, Filters{Namespace: "kube-system"})
+		actions := getActions(c)
+
+		c.RefreshPod("default", "nginx-1", "1234")
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, actions, getActions(c))
+	})
+}
+
 func newTestClientWithRulesAndFilters(t *testing.T, e ExtractionRules, f Filters) (*WatchClient, *observer.ObservedLogs) {
 	observedLogger, logs := observer.New(zapcore.WarnLevel)
 	logger := zap.New(observedLogger)
@@ -95,6 +95,11 @@ func (op *fakeOwnerCache) Start() {}
 // Stop
 func (op *fakeOwnerCache) Stop() {}
 
+// HasSynced always returns true for fakeOwnerCache
+func (op *fakeOwnerCache) HasSynced() bool {
+	return true
+}
+
 // GetServices fetches list of services for a given pod
 func (op *fakeOwnerCache) GetServices(pod *api_v1.Pod) []string {
 	return []string{"foo", "bar"}
@@ -53,6 +53,7 @@ type PodIdentifier string
 const (
 	DefaultPodDeleteGracePeriod = time.Second * 120
 	watchSyncPeriod             = time.Minute * 5
+	podRefreshTimeout           = time.Second * 5
 )
 
 // Client defines the main interface that allows querying pods by metadata.
@@ -60,6 +61,12 @@ type Client interface {
 	GetPod(PodIdentifier) (*Pod, bool)
 	Start()
 	Stop()
+	// HasSynced returns true once the initial state of the watched
+	// resources has been retrieved from the K8S API.
+	HasSynced() bool
+	// RefreshPod updates the cached metadata of the pod from the K8S API in the background,
+	// without waiting for the pod watch. It's a no-op if the pod with the given UID is already cached.
+	RefreshPod(namespace, name, uid string)
 }
 
 // ClientProvider defines a func type that returns a new Client.
@@ -90,7 +97,9 @@ type Pod struct {
 	Namespace  string
 	Address    string
 	PodUID     string
-	Ignore     bool
+	// ResourceVersion is the version of the pod metadata the attributes were extracted from.
+	ResourceVersion string
+	Ignore          bool
 }
 
 func (p Pod) GetName() string {
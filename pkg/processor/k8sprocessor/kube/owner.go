@@ -57,6 +57,7 @@ type OwnerAPI interface {
 	GetServices(pod *api_v1.Pod) []string
 	Start()
 	Stop()
+	HasSynced() bool
 }
 
 // OwnerCache is a simple structure which aids querying for owners
@@ -94,6 +95,16 @@ func (op *OwnerCache) Start() {
 	}
 }
 
+// HasSynced returns true once all the informers have synced
+func (op *OwnerCache) HasSynced() bool {
+	for _, informer := range op.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
 // Stop shutdowns the informers
 func (op *OwnerCache) Stop() {
 	close(op.stopCh)
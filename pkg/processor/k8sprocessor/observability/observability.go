@@ -30,6 +30,7 @@ func init() {
 		viewPodsUpdated,
 		viewPodsAdded,
 		viewPodsDeleted,
+		viewPodsRefreshed,
 		viewOtherUpdated,
 		viewOtherAdded,
 		viewOtherDeleted,
@@ -42,10 +43,11 @@ func init() {
 }
 
 var (
-	mPodsUpdated  = stats.Int64("otelsvc/k8s/pod_updated", "Number of pod update events received", "1")
-	mPodsAdded    = stats.Int64("otelsvc/k8s/pod_added", "Number of pod add events received", "1")
-	mPodsDeleted  = stats.Int64("otelsvc/k8s/pod_deleted", "Number of pod delete events received", "1")
-	mPodTableSize = stats.Int64("otelsvc/k8s/pod_table_size", "Size of table containing pod info", "1")
+	mPodsUpdated   = stats.Int64("otelsvc/k8s/pod_updated", "Number of pod update events received", "1")
+	mPodsAdded     = stats.Int64("otelsvc/k8s/pod_added", "Number of pod add events received", "1")
+	mPodsDeleted   = stats.Int64("otelsvc/k8s/pod_deleted", "Number of pod delete events received", "1")
+	mPodsRefreshed = stats.Int64("otelsvc/k8s/pod_refreshed", "Number of pods refreshed after pod events", "1")
+	mPodTableSize  = stats.Int64("otelsvc/k8s/pod_table_size", "Size of table containing pod info", "1")
 
 	mOtherUpdated = stats.Int64("otelsvc/k8s/other_updated", "Number of other update events received", "1")
 	mOtherAdded   = stats.Int64("otelsvc/k8s/other_added", "Number of other add events received", "1")
@@ -75,6 +77,13 @@ var viewPodsDeleted = &view.View{
 	Aggregation: view.Sum(),
 }
 
+var viewPodsRefreshed = &view.View{
+	Name:        mPodsRefreshed.Name(),
+	Description: mPodsRefreshed.Description(),
+	Measure:     mPodsRefreshed,
+	Aggregation: view.Sum(),
+}
+
 var viewOtherUpdated = &view.View{
 	Name:        mOtherUpdated.Name(),
 	Description: mOtherUpdated.Description(),
@@ -124,6 +133,11 @@ func RecordPodDeleted() {
 	stats.Record(context.Background(), mPodsDeleted.M(int64(1)))
 }
 
+// RecordPodRefreshed increments the metric that records pods refreshed after pod events.
+func RecordPodRefreshed() {
+	stats.Record(context.Background(), mPodsRefreshed.M(int64(1)))
+}
+
 // RecordOtherUpdated increments the metric that records other update events received.
 func RecordOtherUpdated() {
 	stats.Record(context.Background(), mOtherUpdated.M(int64(1)))
@@ -77,6 +77,10 @@ func TestMetrics(t *testing.T) {
 			"otelsvc/k8s/pod_updated",
 			RecordPodUpdated,
 		},
+		{
+			"otelsvc/k8s/pod_refreshed",
+			RecordPodRefreshed,
+		},
 		{
 			"otelsvc/k8s/ip_lookup_miss",
 			RecordIPLookupMiss,
@@ -19,7 +19,9 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/collector/config"
 	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -382,3 +384,31 @@ func WithExcludes(excludeConfig ExcludeConfig) Option {
 		return nil
 	}
 }
+
+// WithWaitForMetadata makes the processor wait for Pod metadata to be synced
+// from the K8S API on start, failing if it takes longer than timeout.
+func WithWaitForMetadata(timeout time.Duration) Option {
+	return func(p *kubernetesprocessor) error {
+		p.waitForMetadata = true
+		p.waitForMetadataTimeout = timeout
+		return nil
+	}
+}
+
+// WithPodIndex registers the processor's Pod metadata cache with the pod index
+// extension with the given id, so that other components can resolve IP addresses to pods.
+func WithPodIndex(id config.ComponentID) Option {
+	return func(p *kubernetesprocessor) error {
+		p.podIndexID = &id
+		return nil
+	}
+}
+
+// WithPodEventsBus makes the processor refresh Pod metadata on pod events
+// published to the pod events bus extension with the given id.
+func WithPodEventsBus(id config.ComponentID) Option {
+	return func(p *kubernetesprocessor) error {
+		p.podEventsBusID = &id
+		return nil
+	}
+}
@@ -16,15 +16,19 @@ package k8sprocessor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
 
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podindexextension"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
@@ -32,6 +36,8 @@ import (
 const (
 	k8sIPLabelName    string = "k8s.pod.ip"
 	clientIPLabelName string = "ip"
+
+	metadataSyncCheckInterval = 100 * time.Millisecond
 )
 
 type kubernetesprocessor struct {
@@ -44,6 +50,17 @@ type kubernetesprocessor struct {
 	podAssociations []kube.Association
 	podIgnore       kube.Excludes
 	delimiter       string
+
+	waitForMetadata        bool
+	waitForMetadataTimeout time.Duration
+
+	podEventsBusID          *config.ComponentID
+	unsubscribePodEventsBus func()
+
+	podIndexID         *config.ComponentID
+	unregisterPodIndex func()
+
+	attributesCache podAttributesCache
 }
 
 func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kube.ClientProvider) error {
@@ -73,20 +90,98 @@ func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kub
 	return nil
 }
 
-func (kp *kubernetesprocessor) Start(_ context.Context, _ component.Host) error {
+func (kp *kubernetesprocessor) Start(ctx context.Context, host component.Host) error {
 	if !kp.passthroughMode {
+		if kp.podEventsBusID != nil {
+			bus, err := podeventsbusextension.GetPodEventsBus(host, *kp.podEventsBusID)
+			if err != nil {
+				return err
+			}
+			kp.unsubscribePodEventsBus = bus.Subscribe(kp.handlePodEvent)
+		}
+
+		if kp.podIndexID != nil {
+			index, err := podindexextension.GetPodIndex(host, *kp.podIndexID)
+			if err != nil {
+				return err
+			}
+			kp.unregisterPodIndex = index.Register(kp)
+		}
+
 		go kp.kc.Start()
+
+		if kp.waitForMetadata {
+			return kp.waitForMetadataSync(ctx)
+		}
+	}
+	return nil
+}
+
+// waitForMetadataSync blocks until the k8s client has synced Pod metadata, the timeout
+// is exceeded or the context is cancelled.
+func (kp *kubernetesprocessor) waitForMetadataSync(ctx context.Context) error {
+	kp.logger.Info("Waiting for k8s metadata to be synced", zap.Duration("timeout", kp.waitForMetadataTimeout))
+
+	timeout := time.NewTimer(kp.waitForMetadataTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(metadataSyncCheckInterval)
+	defer ticker.Stop()
+
+	for !kp.kc.HasSynced() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return fmt.Errorf("k8s metadata was not synced within %s", kp.waitForMetadataTimeout)
+		case <-ticker.C:
+		}
 	}
+
+	kp.logger.Info("k8s metadata synced")
 	return nil
 }
 
 func (kp *kubernetesprocessor) Shutdown(context.Context) error {
+	if kp.unsubscribePodEventsBus != nil {
+		kp.unsubscribePodEventsBus()
+	}
+	if kp.unregisterPodIndex != nil {
+		kp.unregisterPodIndex()
+	}
 	if !kp.passthroughMode {
 		kp.kc.Stop()
 	}
 	return nil
 }
 
+// handlePodEvent refreshes the metadata of the pod the event is about, so that data coming from
+// a new pod is tagged before the pod watch reports it.
+func (kp *kubernetesprocessor) handlePodEvent(event podeventsbusextension.PodEvent) {
+	kp.kc.RefreshPod(event.Namespace, event.Name, event.UID)
+}
+
+// PodByIP returns the pod with the given IP address from the Pod metadata cache,
+// so that other components can resolve IP addresses with the pod index extension.
+func (kp *kubernetesprocessor) PodByIP(ip string) (podindexextension.Pod, bool) {
+	pod, ok := kp.kc.GetPod(kube.PodIdentifier(ip))
+	// pods are cached by their UIDs as well
+	if !ok || pod.Address != ip {
+		return podindexextension.Pod{}, false
+	}
+
+	attributes := make(map[string]string, len(pod.Attributes))
+	for k, v := range pod.Attributes {
+		attributes[k] = v
+	}
+	return podindexextension.Pod{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		UID:        pod.PodUID,
+		Address:    pod.Address,
+		Attributes: attributes,
+	}, true
+}
+
 // ProcessTraces process traces and add k8s metadata using resource IP or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
 	rss := td.ResourceSpans()
@@ -132,15 +227,15 @@ func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pco
 		return
 	}
 	attrsToAdd := kp.getAttributesForPod(podIdentifierValue)
-	for key, val := range attrsToAdd {
-		resource.Attributes().InsertString(key, val)
+	for _, attr := range attrsToAdd {
+		resource.Attributes().InsertString(attr.key, attr.value)
 	}
 }
 
-func (kp *kubernetesprocessor) getAttributesForPod(identifier kube.PodIdentifier) map[string]string {
+func (kp *kubernetesprocessor) getAttributesForPod(identifier kube.PodIdentifier) []podAttribute {
 	pod, ok := kp.kc.GetPod(identifier)
 	if !ok {
 		return nil
 	}
-	return pod.Attributes
+	return kp.attributesCache.get(pod)
 }
@@ -36,6 +36,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
 
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podindexextension"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
 )
@@ -1019,6 +1021,180 @@ func TestStartStop(t *testing.T) {
 	assert.True(t, controller.HasStopped())
 }
 
+func TestStartWaitForMetadata(t *testing.T) {
+	t.Run("synced", func(t *testing.T) {
+		var kp *kubernetesprocessor
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithWaitForMetadata(time.Second),
+			withExtractKubernetesProcessorInto(&kp),
+		)
+		require.NoError(t, err)
+
+		assert.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var kp *kubernetesprocessor
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithWaitForMetadata(300*time.Millisecond),
+			withExtractKubernetesProcessorInto(&kp),
+		)
+		require.NoError(t, err)
+		kp.kc.(*fakeClient).NotSynced = true
+
+		err = p.Start(context.Background(), componenttest.NewNopHost())
+		assert.EqualError(t, err, "k8s metadata was not synced within 300ms")
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		var kp *kubernetesprocessor
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithWaitForMetadata(time.Minute),
+			withExtractKubernetesProcessorInto(&kp),
+		)
+		require.NoError(t, err)
+		kp.kc.(*fakeClient).NotSynced = true
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, p.Start(ctx, componenttest.NewNopHost()), context.Canceled)
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestPodEventsBus(t *testing.T) {
+	busID := config.NewComponentID("pod_events_bus")
+	busFactory := podeventsbusextension.NewFactory()
+	bus, err := busFactory.CreateExtension(
+		context.Background(),
+		componenttest.NewNopExtensionCreateSettings(),
+		busFactory.CreateDefaultConfig(),
+	)
+	require.NoError(t, err)
+	host := extensionsHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{busID: bus},
+	}
+
+	t.Run("refresh on pod events", func(t *testing.T) {
+		var kp *kubernetesprocessor
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithPodEventsBus(busID),
+			withExtractKubernetesProcessorInto(&kp),
+		)
+		require.NoError(t, err)
+		require.NoError(t, p.Start(context.Background(), host))
+
+		bus.(podeventsbusextension.PodEventsBus).Publish(podeventsbusextension.PodEvent{
+			Namespace: "default",
+			Name:      "nginx-1",
+			UID:       "1234",
+			Reason:    "Scheduled",
+		})
+		assert.Equal(t, []string{"default/nginx-1/1234"}, kp.kc.(*fakeClient).RefreshedPods)
+
+		// the processor unsubscribes on shutdown
+		assert.NoError(t, p.Shutdown(context.Background()))
+		bus.(podeventsbusextension.PodEventsBus).Publish(podeventsbusextension.PodEvent{Name: "nginx-2"})
+		assert.Len(t, kp.kc.(*fakeClient).RefreshedPods, 1)
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithPodEventsBus(config.NewComponentIDWithName("pod_events_bus", "missing")),
+		)
+		require.NoError(t, err)
+		assert.Error(t, p.Start(context.Background(), host))
+	})
+}
+
+func TestPodIndex(t *testing.T) {
+	indexID := config.NewComponentID("pod_index")
+	indexFactory := podindexextension.NewFactory()
+	index, err := indexFactory.CreateExtension(
+		context.Background(),
+		componenttest.NewNopExtensionCreateSettings(),
+		indexFactory.CreateDefaultConfig(),
+	)
+	require.NoError(t, err)
+	host := extensionsHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{indexID: index},
+	}
+
+	t.Run("resolve IP addresses", func(t *testing.T) {
+		var kp *kubernetesprocessor
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithPodIndex(indexID),
+			withExtractKubernetesProcessorInto(&kp),
+		)
+		require.NoError(t, err)
+		require.NoError(t, p.Start(context.Background(), host))
+
+		pod := &kube.Pod{
+			Name:       "nginx-1",
+			Namespace:  "default",
+			PodUID:     "1234",
+			Address:    "10.0.0.1",
+			Attributes: map[string]string{"k8s.deployment.name": "nginx"},
+		}
+		kp.kc.(*fakeClient).Pods[kube.PodIdentifier("10.0.0.1")] = pod
+		kp.kc.(*fakeClient).Pods[kube.PodIdentifier("1234")] = pod
+
+		got, ok := index.(podindexextension.PodIndex).PodByIP("10.0.0.1")
+		require.True(t, ok)
+		assert.Equal(t, podindexextension.Pod{
+			Namespace:  "default",
+			Name:       "nginx-1",
+			UID:        "1234",
+			Address:    "10.0.0.1",
+			Attributes: map[string]string{"k8s.deployment.name": "nginx"},
+		}, got)
+
+		// pods are only resolved by their IP addresses
+		_, ok = index.(podindexextension.PodIndex).PodByIP("1234")
+		assert.False(t, ok)
+
+		// the processor unregisters on shutdown
+		assert.NoError(t, p.Shutdown(context.Background()))
+		_, ok = index.(podindexextension.PodIndex).PodByIP("10.0.0.1")
+		assert.False(t, ok)
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		p, err := newTracesProcessor(
+			NewFactory().CreateDefaultConfig(),
+			consumertest.NewNop(),
+			WithPodIndex(config.NewComponentIDWithName("pod_index", "missing")),
+		)
+		require.NoError(t, err)
+		assert.Error(t, p.Start(context.Background(), host))
+	})
+}
+
 func assertResourceHasStringAttribute(t *testing.T, r pcommon.Resource, k, v string) {
 	got, ok := r.Attributes().Get(k)
 	assert.True(t, ok, fmt.Sprintf("resource does not contain attribute %s", k))
@@ -0,0 +1,101 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// SimilarityThreshold is the minimal ratio of tokens a record has to share
+	// with a pattern to be clustered into it.
+	SimilarityThreshold float64 `mapstructure:"similarity_threshold"`
+	// TreeDepth is the depth of the prefix tree records are routed through,
+	// the first TreeDepth-2 tokens of a record are used to find candidate patterns.
+	TreeDepth int `mapstructure:"tree_depth"`
+	// MaxChildren is the maximal number of children of a prefix tree node.
+	MaxChildren int `mapstructure:"max_children"`
+	// MaxPatterns is the maximal number of tracked patterns,
+	// the least recently used pattern is dropped when it is reached.
+	MaxPatterns int `mapstructure:"max_patterns"`
+	// PatternIDAttribute is the name of the attribute set to the pattern ID
+	// on each log record. Records are not tagged when it is empty.
+	PatternIDAttribute string `mapstructure:"pattern_id_attribute"`
+	// MetricsExporter is the metrics exporter pattern metrics are sent to.
+	// Metrics are not emitted when it is not set.
+	MetricsExporter *config.ComponentID `mapstructure:"metrics_exporter"`
+	// EmitInterval is the interval in which pattern metrics are emitted.
+	EmitInterval time.Duration `mapstructure:"emit_interval"`
+	// Exemplars is the maximal number of exemplar records attached to
+	// the metric of each pattern per interval.
+	Exemplars int `mapstructure:"exemplars"`
+}
+
+const (
+	defaultSimilarityThreshold = 0.5
+	defaultTreeDepth           = 4
+	defaultMaxChildren         = 100
+	defaultMaxPatterns         = 1000
+	defaultPatternIDAttribute  = "pattern_id"
+	defaultEmitInterval        = time.Minute
+	defaultExemplars           = 3
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings:   config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		SimilarityThreshold: defaultSimilarityThreshold,
+		TreeDepth:           defaultTreeDepth,
+		MaxChildren:         defaultMaxChildren,
+		MaxPatterns:         defaultMaxPatterns,
+		PatternIDAttribute:  defaultPatternIDAttribute,
+		EmitInterval:        defaultEmitInterval,
+		Exemplars:           defaultExemplars,
+	}
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	if cfg.SimilarityThreshold <= 0 || cfg.SimilarityThreshold > 1 {
+		return errors.New("similarity_threshold must be in range (0, 1]")
+	}
+	if cfg.TreeDepth < 3 {
+		return errors.New("tree_depth must be at least 3")
+	}
+	if cfg.MaxChildren <= 0 {
+		return errors.New("max_children must be positive")
+	}
+	if cfg.MaxPatterns <= 0 {
+		return errors.New("max_patterns must be positive")
+	}
+	if cfg.EmitInterval <= 0 {
+		return errors.New("emit_interval must be positive")
+	}
+	if cfg.Exemplars < 0 {
+		return errors.New("exemplars cannot be negative")
+	}
+	if cfg.MetricsExporter == nil && cfg.PatternIDAttribute == "" {
+		return errors.New("either metrics_exporter or pattern_id_attribute has to be set")
+	}
+	return nil
+}
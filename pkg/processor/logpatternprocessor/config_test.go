@@ -0,0 +1,81 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	exporterID := config.NewComponentID("nop")
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings:   config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			SimilarityThreshold: 0.7,
+			TreeDepth:           5,
+			MaxChildren:         50,
+			MaxPatterns:         500,
+			PatternIDAttribute:  "",
+			MetricsExporter:     &exporterID,
+			EmitInterval:        30 * time.Second,
+			Exemplars:           5,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "zero similarity threshold", modify: func(cfg *Config) { cfg.SimilarityThreshold = 0 }},
+		{name: "similarity threshold above 1", modify: func(cfg *Config) { cfg.SimilarityThreshold = 1.5 }},
+		{name: "too shallow tree", modify: func(cfg *Config) { cfg.TreeDepth = 2 }},
+		{name: "zero max children", modify: func(cfg *Config) { cfg.MaxChildren = 0 }},
+		{name: "zero max patterns", modify: func(cfg *Config) { cfg.MaxPatterns = 0 }},
+		{name: "zero emit interval", modify: func(cfg *Config) { cfg.EmitInterval = 0 }},
+		{name: "negative exemplars", modify: func(cfg *Config) { cfg.Exemplars = -1 }},
+		{name: "no output", modify: func(cfg *Config) { cfg.PatternIDAttribute = "" }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// wildcard replaces tokens which vary between records of the same pattern.
+const wildcard = "<*>"
+
+// pattern is a cluster of log records sharing the same template.
+type pattern struct {
+	// id is computed from the template the pattern was created with,
+	// so it doesn't change when the template gets more general.
+	id       string
+	template []string
+	// lastUsed is the value of drain.clock when the pattern was last matched.
+	lastUsed uint64
+	// parent is the leaf node the pattern is stored in.
+	parent *node
+}
+
+func (p *pattern) String() string {
+	return strings.Join(p.template, " ")
+}
+
+type node struct {
+	children map[string]*node
+	patterns []*pattern
+}
+
+func newNode() *node {
+	return &node{children: map[string]*node{}}
+}
+
+// drain clusters log records into patterns using the Drain algorithm:
+// records are routed through a fixed depth prefix tree by their number of
+// tokens and first tokens, and then matched to the most similar pattern
+// in the leaf. It is not safe for concurrent use.
+//
+// See: https://jiemingzhu.github.io/pub/pjhe_icws2017.pdf
+type drain struct {
+	depth               int
+	maxChildren         int
+	maxPatterns         int
+	similarityThreshold float64
+
+	root     *node
+	patterns map[string]*pattern
+	clock    uint64
+}
+
+func newDrain(depth int, maxChildren int, maxPatterns int, similarityThreshold float64) *drain {
+	return &drain{
+		depth:               depth,
+		maxChildren:         maxChildren,
+		maxPatterns:         maxPatterns,
+		similarityThreshold: similarityThreshold,
+		root:                newNode(),
+		patterns:            map[string]*pattern{},
+	}
+}
+
+// add matches the record to a pattern, creating a new one if none is similar enough.
+func (d *drain) add(body string) *pattern {
+	tokens := tokenize(body)
+	d.clock++
+
+	leaf := d.leaf(tokens)
+	if p := d.match(leaf, tokens); p != nil {
+		p.lastUsed = d.clock
+		for i, token := range tokens {
+			if p.template[i] != token {
+				p.template[i] = wildcard
+			}
+		}
+		return p
+	}
+
+	if len(d.patterns) >= d.maxPatterns {
+		d.evict()
+	}
+
+	p := &pattern{
+		id:       patternID(tokens),
+		template: tokens,
+		lastUsed: d.clock,
+		parent:   leaf,
+	}
+	leaf.patterns = append(leaf.patterns, p)
+	d.patterns[p.id] = p
+	return p
+}
+
+// leaf returns the leaf node for the tokens, creating the path if necessary.
+func (d *drain) leaf(tokens []string) *node {
+	length := fmt.Sprint(len(tokens))
+	current, ok := d.root.children[length]
+	if !ok {
+		current = newNode()
+		d.root.children[length] = current
+	}
+
+	// The root and the length layer are part of the depth.
+	for i := 0; i < d.depth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if hasDigit(key) {
+			key = wildcard
+		}
+
+		next, ok := current.children[key]
+		if !ok {
+			// Once the node is full, all new tokens share the wildcard child.
+			if len(current.children) >= d.maxChildren {
+				key = wildcard
+				next, ok = current.children[wildcard]
+			}
+			if !ok {
+				next = newNode()
+				current.children[key] = next
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// match returns the most similar pattern in the leaf if its similarity
+// is at least the configured threshold.
+func (d *drain) match(leaf *node, tokens []string) *pattern {
+	var (
+		best           *pattern
+		bestSimilarity = -1.0
+		bestWildcards  = -1
+	)
+	for _, p := range leaf.patterns {
+		similarity, wildcards := similarity(p.template, tokens)
+		if similarity > bestSimilarity || (similarity == bestSimilarity && wildcards > bestWildcards) {
+			best, bestSimilarity, bestWildcards = p, similarity, wildcards
+		}
+	}
+	if best == nil || bestSimilarity < d.similarityThreshold {
+		return nil
+	}
+	return best
+}
+
+// evict removes the least recently used pattern.
+func (d *drain) evict() {
+	var oldest *pattern
+	for _, p := range d.patterns {
+		if oldest == nil || p.lastUsed < oldest.lastUsed {
+			oldest = p
+		}
+	}
+	if oldest == nil {
+		return
+	}
+
+	delete(d.patterns, oldest.id)
+	leaf := oldest.parent
+	for i, p := range leaf.patterns {
+		if p == oldest {
+			leaf.patterns = append(leaf.patterns[:i], leaf.patterns[i+1:]...)
+			break
+		}
+	}
+}
+
+// similarity returns the ratio of template tokens equal to the record tokens
+// and the number of wildcards in the template. Both have the same length.
+func similarity(template []string, tokens []string) (float64, int) {
+	if len(tokens) == 0 {
+		return 1, 0
+	}
+
+	var equal, wildcards int
+	for i, token := range template {
+		if token == wildcard {
+			wildcards++
+			continue
+		}
+		if token == tokens[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens)), wildcards
+}
+
+func tokenize(body string) []string {
+	return strings.Fields(body)
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+func patternID(tokens []string) string {
+	h := fnv.New64a()
+	for _, token := range tokens {
+		h.Write([]byte(token))
+		h.Write([]byte{' '})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
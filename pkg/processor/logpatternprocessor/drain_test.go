@@ -0,0 +1,105 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainClustersSimilarRecords(t *testing.T) {
+	d := newDrain(4, 100, 1000, 0.5)
+
+	p1 := d.add("connected to 10.0.0.1 in 15 ms")
+	p2 := d.add("connected to 10.0.0.2 in 120 ms")
+	p3 := d.add("connected to 10.0.0.7 in 3 ms")
+
+	assert.Same(t, p1, p2)
+	assert.Same(t, p1, p3)
+	assert.Equal(t, "connected to <*> in <*> ms", p1.String())
+	assert.Len(t, d.patterns, 1)
+}
+
+func TestDrainSeparatesDifferentRecords(t *testing.T) {
+	d := newDrain(4, 100, 1000, 0.5)
+
+	connected := d.add("connected to 10.0.0.1 in 15 ms")
+	failed := d.add("failed to read config file /etc/app.yaml")
+	disconnected := d.add("user alice logged out")
+	short := d.add("connected to 10.0.0.1")
+
+	assert.NotEqual(t, connected.id, failed.id)
+	assert.NotEqual(t, connected.id, disconnected.id)
+	assert.NotEqual(t, connected.id, short.id)
+	assert.Len(t, d.patterns, 4)
+
+	assert.Same(t, failed, d.add("failed to read config file /etc/other.yaml"))
+	assert.Equal(t, "failed to read config file <*>", failed.String())
+}
+
+func TestDrainPatternIDIsStable(t *testing.T) {
+	d := newDrain(4, 100, 1000, 0.5)
+
+	p := d.add("request GET /health took 3ms")
+	id := p.id
+	d.add("request GET /ready took 12ms")
+
+	assert.Equal(t, "request GET <*> took <*>", p.String())
+	assert.Equal(t, id, p.id)
+
+	// the ID depends only on the record which created the pattern
+	other := newDrain(4, 100, 1000, 0.5)
+	assert.Equal(t, id, other.add("request GET /health took 3ms").id)
+}
+
+func TestDrainEvictsLeastRecentlyUsedPattern(t *testing.T) {
+	d := newDrain(4, 100, 2, 0.5)
+
+	first := d.add("first pattern record")
+	second := d.add("second kind of record here")
+	d.add("first pattern record")
+
+	third := d.add("third type with many more tokens")
+	assert.Len(t, d.patterns, 2)
+	assert.Contains(t, d.patterns, first.id)
+	assert.Contains(t, d.patterns, third.id)
+	assert.NotContains(t, d.patterns, second.id)
+
+	// the evicted pattern is created again
+	assert.NotSame(t, second, d.add("second kind of record here"))
+}
+
+func TestDrainMaxChildren(t *testing.T) {
+	d := newDrain(4, 2, 1000, 0.5)
+
+	d.add("alpha started")
+	d.add("beta started")
+	gamma := d.add("gamma started")
+	delta := d.add("delta started")
+
+	// alpha and beta fill the node, other first tokens share the wildcard node
+	assert.Len(t, d.root.children["2"].children, 3)
+	assert.Same(t, gamma, delta)
+	assert.Equal(t, "<*> started", gamma.String())
+}
+
+func TestDrainEmptyBody(t *testing.T) {
+	d := newDrain(4, 100, 1000, 0.5)
+
+	p := d.add("")
+	assert.Same(t, p, d.add("   "))
+	assert.Equal(t, "", p.String())
+}
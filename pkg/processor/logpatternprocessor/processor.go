@@ -0,0 +1,257 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	patternRecordsMetric = "log_pattern.records"
+	patternIDLabel       = "pattern_id"
+	patternLabel         = "pattern"
+	exemplarBodyLabel    = "log.body"
+)
+
+type logPatternProcessor struct {
+	patternIDAttribute string
+	metricsExporterID  *config.ComponentID
+	emitInterval       time.Duration
+	exemplars          int
+	logger             *zap.Logger
+
+	mutex sync.Mutex
+	drain *drain
+	// stats of patterns matched since the last emit, by pattern ID
+	stats         map[string]*patternStats
+	intervalStart time.Time
+
+	metricsConsumer consumer.Metrics
+	shutdownCh      chan struct{}
+	wg              sync.WaitGroup
+
+	now func() time.Time
+}
+
+type patternStats struct {
+	pattern *pattern
+	// template is set when the metrics are collected,
+	// the pattern template can change concurrently afterwards.
+	template  string
+	count     int64
+	exemplars []exemplar
+}
+
+type exemplar struct {
+	timestamp pcommon.Timestamp
+	body      string
+	traceID   pcommon.TraceID
+	spanID    pcommon.SpanID
+}
+
+func newLogPatternProcessor(cfg *Config, logger *zap.Logger) *logPatternProcessor {
+	return &logPatternProcessor{
+		patternIDAttribute: cfg.PatternIDAttribute,
+		metricsExporterID:  cfg.MetricsExporter,
+		emitInterval:       cfg.EmitInterval,
+		exemplars:          cfg.Exemplars,
+		logger:             logger,
+		drain:              newDrain(cfg.TreeDepth, cfg.MaxChildren, cfg.MaxPatterns, cfg.SimilarityThreshold),
+		stats:              map[string]*patternStats{},
+		shutdownCh:         make(chan struct{}),
+		now:                time.Now,
+	}
+}
+
+func (p *logPatternProcessor) start(_ context.Context, host component.Host) error {
+	p.intervalStart = p.now()
+	if p.metricsExporterID == nil {
+		return nil
+	}
+
+	exporter, ok := host.GetExporters()[config.MetricsDataType][*p.metricsExporterID]
+	if !ok {
+		return fmt.Errorf("metrics exporter %q not found, it has to be used in a metrics pipeline", p.metricsExporterID)
+	}
+	metricsConsumer, ok := exporter.(consumer.Metrics)
+	if !ok {
+		return fmt.Errorf("exporter %q is not a metrics exporter", p.metricsExporterID)
+	}
+	p.metricsConsumer = metricsConsumer
+
+	p.wg.Add(1)
+	go p.emitLoop()
+	return nil
+}
+
+func (p *logPatternProcessor) shutdown(ctx context.Context) error {
+	if p.metricsConsumer == nil {
+		return nil
+	}
+
+	close(p.shutdownCh)
+	p.wg.Wait()
+	// flush metrics of the last interval
+	return p.emitMetrics(ctx)
+}
+
+func (p *logPatternProcessor) emitLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.emitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.emitMetrics(context.Background()); err != nil {
+				p.logger.Warn("Failed to emit log pattern metrics", zap.Error(err))
+			}
+		case <-p.shutdownCh:
+			return
+		}
+	}
+}
+
+func (p *logPatternProcessor) emitMetrics(ctx context.Context) error {
+	metrics := p.collectMetrics()
+	if metrics.DataPointCount() == 0 {
+		return nil
+	}
+	return p.metricsConsumer.ConsumeMetrics(ctx, metrics)
+}
+
+func (p *logPatternProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := pcommon.NewTimestampFromTime(p.now())
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				p.processLogRecord(lrs.At(k), now)
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (p *logPatternProcessor) processLogRecord(lr plog.LogRecord, now pcommon.Timestamp) {
+	body := lr.Body().AsString()
+	pattern := p.drain.add(body)
+
+	if p.patternIDAttribute != "" {
+		lr.Attributes().UpsertString(p.patternIDAttribute, pattern.id)
+	}
+
+	if p.metricsExporterID == nil {
+		return
+	}
+
+	stats, ok := p.stats[pattern.id]
+	if !ok {
+		stats = &patternStats{pattern: pattern}
+		p.stats[pattern.id] = stats
+	}
+	stats.count++
+
+	if len(stats.exemplars) < p.exemplars {
+		timestamp := lr.Timestamp()
+		if timestamp == 0 {
+			timestamp = now
+		}
+		stats.exemplars = append(stats.exemplars, exemplar{
+			timestamp: timestamp,
+			body:      body,
+			traceID:   lr.TraceID(),
+			spanID:    lr.SpanID(),
+		})
+	}
+}
+
+// collectMetrics returns metrics of patterns matched since the last call
+// and starts a new interval.
+func (p *logPatternProcessor) collectMetrics() pmetric.Metrics {
+	p.mutex.Lock()
+	stats := p.stats
+	start := p.intervalStart
+	for _, s := range stats {
+		s.template = s.pattern.String()
+	}
+	p.stats = map[string]*patternStats{}
+	p.intervalStart = p.now()
+	end := p.intervalStart
+	p.mutex.Unlock()
+
+	metrics := pmetric.NewMetrics()
+	if len(stats) == 0 {
+		return metrics
+	}
+
+	sm := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(typeStr)
+
+	m := sm.Metrics().AppendEmpty()
+	m.SetName(patternRecordsMetric)
+	m.SetDescription("Number of log records matching the pattern")
+	m.SetUnit("1")
+	m.SetDataType(pmetric.MetricDataTypeSum)
+	m.Sum().SetIsMonotonic(true)
+	m.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+
+	ids := make([]string, 0, len(stats))
+	for id := range stats {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	dps := m.Sum().DataPoints()
+	dps.EnsureCapacity(len(ids))
+	for _, id := range ids {
+		s := stats[id]
+
+		dp := dps.AppendEmpty()
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(end))
+		dp.SetIntVal(s.count)
+		dp.Attributes().UpsertString(patternIDLabel, id)
+		dp.Attributes().UpsertString(patternLabel, s.template)
+
+		for _, e := range s.exemplars {
+			ex := dp.Exemplars().AppendEmpty()
+			ex.SetTimestamp(e.timestamp)
+			ex.SetIntVal(1)
+			ex.SetTraceID(e.traceID)
+			ex.SetSpanID(e.spanID)
+			ex.FilteredAttributes().UpsertString(exemplarBodyLabel, e.body)
+		}
+	}
+	return metrics
+}
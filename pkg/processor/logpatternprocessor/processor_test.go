@@ -0,0 +1,209 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logpatternprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+var testExporterID = config.NewComponentID("sink")
+
+func newTestProcessor(t *testing.T, modify func(*Config)) *logPatternProcessor {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	processor := newLogPatternProcessor(cfg, zap.NewNop())
+	processor.now = func() time.Time { return testNow }
+	return processor
+}
+
+// newLogs creates logs with a record for each of the bodies.
+func newLogs(bodies ...string) plog.Logs {
+	logs := plog.NewLogs()
+	lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		lrs.AppendEmpty().Body().SetStringVal(body)
+	}
+	return logs
+}
+
+func patternIDs(t *testing.T, logs plog.Logs, attribute string) []string {
+	var ids []string
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		id, ok := lrs.At(i).Attributes().Get(attribute)
+		require.True(t, ok)
+		ids = append(ids, id.StringVal())
+	}
+	return ids
+}
+
+// sinkExporter is a metrics exporter storing received metrics.
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.MetricsSink
+}
+
+type testHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h *testHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func newTestHost(sink *consumertest.MetricsSink) component.Host {
+	return &testHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.MetricsDataType: {testExporterID: &sinkExporter{MetricsSink: sink}},
+		},
+	}
+}
+
+func TestProcessLogsSetsPatternID(t *testing.T) {
+	processor := newTestProcessor(t, nil)
+
+	logs, err := processor.processLogs(context.Background(), newLogs(
+		"connected to 10.0.0.1 in 15 ms",
+		"failed to read config file /etc/app.yaml",
+		"connected to 10.0.0.2 in 120 ms",
+	))
+	require.NoError(t, err)
+
+	ids := patternIDs(t, logs, "pattern_id")
+	assert.Equal(t, ids[0], ids[2])
+	assert.NotEqual(t, ids[0], ids[1])
+
+	// stats are gathered only when metrics are emitted
+	assert.Empty(t, processor.stats)
+}
+
+func TestCollectMetrics(t *testing.T) {
+	processor := newTestProcessor(t, func(cfg *Config) {
+		cfg.MetricsExporter = &testExporterID
+		cfg.PatternIDAttribute = "custom_pattern_id"
+		cfg.Exemplars = 2
+	})
+	processor.intervalStart = testNow.Add(-time.Minute)
+
+	logs := newLogs(
+		"connected to 10.0.0.1 in 15 ms",
+		"failed to read config file /etc/app.yaml",
+		"connected to 10.0.0.2 in 120 ms",
+		"connected to 10.0.0.3 in 7 ms",
+	)
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SetTraceID(
+		pcommon.NewTraceID([16]byte{1, 2, 3}),
+	)
+	logs, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	ids := patternIDs(t, logs, "custom_pattern_id")
+
+	metrics := processor.collectMetrics()
+	require.Equal(t, 1, metrics.MetricCount())
+	m := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "log_pattern.records", m.Name())
+	assert.Equal(t, pmetric.MetricAggregationTemporalityDelta, m.Sum().AggregationTemporality())
+
+	dps := m.Sum().DataPoints()
+	require.Equal(t, 2, dps.Len())
+
+	byID := map[string]pmetric.NumberDataPoint{}
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		id, ok := dp.Attributes().Get("pattern_id")
+		require.True(t, ok)
+		byID[id.StringVal()] = dp
+
+		assert.Equal(t, pcommon.NewTimestampFromTime(testNow.Add(-time.Minute)), dp.StartTimestamp())
+		assert.Equal(t, pcommon.NewTimestampFromTime(testNow), dp.Timestamp())
+	}
+
+	connected := byID[ids[0]]
+	assert.EqualValues(t, 3, connected.IntVal())
+	pattern, ok := connected.Attributes().Get("pattern")
+	require.True(t, ok)
+	assert.Equal(t, "connected to <*> in <*> ms", pattern.StringVal())
+
+	require.Equal(t, 2, connected.Exemplars().Len())
+	first := connected.Exemplars().At(0)
+	body, ok := first.FilteredAttributes().Get("log.body")
+	require.True(t, ok)
+	assert.Equal(t, "connected to 10.0.0.1 in 15 ms", body.StringVal())
+	assert.Equal(t, pcommon.NewTraceID([16]byte{1, 2, 3}), first.TraceID())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testNow), first.Timestamp())
+
+	failed := byID[ids[1]]
+	assert.EqualValues(t, 1, failed.IntVal())
+	assert.Equal(t, 1, failed.Exemplars().Len())
+
+	// a new interval is started
+	assert.Equal(t, 0, processor.collectMetrics().MetricCount())
+}
+
+func TestEmitMetricsToExporter(t *testing.T) {
+	processor := newTestProcessor(t, func(cfg *Config) {
+		cfg.MetricsExporter = &testExporterID
+		cfg.EmitInterval = 10 * time.Millisecond
+	})
+	sink := new(consumertest.MetricsSink)
+	require.NoError(t, processor.start(context.Background(), newTestHost(sink)))
+
+	_, err := processor.processLogs(context.Background(), newLogs("user alice logged in"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// metrics of the last interval are flushed on shutdown
+	_, err = processor.processLogs(context.Background(), newLogs("user bob logged in"))
+	require.NoError(t, err)
+	require.NoError(t, processor.shutdown(context.Background()))
+	assert.Equal(t, 2, sink.DataPointCount())
+}
+
+func TestStartWithMissingExporter(t *testing.T) {
+	processor := newTestProcessor(t, func(cfg *Config) {
+		missing := config.NewComponentID("missing")
+		cfg.MetricsExporter = &missing
+	})
+
+	err := processor.start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `metrics exporter "missing" not found`)
+	require.NoError(t, processor.shutdown(context.Background()))
+}
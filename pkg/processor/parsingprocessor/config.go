@@ -0,0 +1,150 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// Preset is the format the log records are parsed as.
+	Preset Preset `mapstructure:"preset"`
+	// SourceAttribute is the name of the log record attribute to parse.
+	// The log record body is parsed when it's empty.
+	SourceAttribute string `mapstructure:"source_attribute"`
+	// TargetAttribute is the name of the log record attribute the parsed fields are put into as a map.
+	// The parsed fields are added directly to the log record attributes when it's empty.
+	TargetAttribute string `mapstructure:"target_attribute"`
+	// CSV configures the csv preset.
+	CSV CSVConfig `mapstructure:"csv"`
+	// KeyValue configures the key_value preset.
+	KeyValue KeyValueConfig `mapstructure:"key_value"`
+
+	// OnError defines what happens with log records which failed to be parsed.
+	OnError OnErrorAction `mapstructure:"on_error"`
+	// ErrorAttribute is the name of the attribute set to the parsing error on kept and routed log records.
+	// No attribute is set when it's empty.
+	ErrorAttribute string `mapstructure:"error_attribute"`
+	// ErrorCategory is the source category routed log records are sent with.
+	ErrorCategory string `mapstructure:"error_category"`
+}
+
+// CSVConfig defines how csv lines are parsed.
+type CSVConfig struct {
+	// Header contains the names of the fields, in the order they appear in a line.
+	Header []string `mapstructure:"header"`
+	// Delimiter separates the fields. It must be a single character.
+	Delimiter string `mapstructure:"delimiter"`
+}
+
+// KeyValueConfig defines how key=value pairs are parsed.
+type KeyValueConfig struct {
+	// Delimiter separates a key from its value.
+	Delimiter string `mapstructure:"delimiter"`
+	// PairDelimiter separates the pairs.
+	PairDelimiter string `mapstructure:"pair_delimiter"`
+}
+
+type Preset string
+
+const (
+	PresetJSON     Preset = "json"
+	PresetLogfmt   Preset = "logfmt"
+	PresetCSV      Preset = "csv"
+	PresetKeyValue Preset = "key_value"
+)
+
+type OnErrorAction string
+
+const (
+	// OnErrorKeep passes the unparsed log record on.
+	OnErrorKeep OnErrorAction = "keep"
+	// OnErrorDrop drops the unparsed log record.
+	OnErrorDrop OnErrorAction = "drop"
+	// OnErrorRoute passes the unparsed log record on with the source category set to ErrorCategory.
+	OnErrorRoute OnErrorAction = "route"
+)
+
+const (
+	defaultOnError         = OnErrorKeep
+	defaultErrorAttribute  = "parsing.error"
+	defaultCSVDelimiter    = ","
+	defaultKVDelimiter     = "="
+	defaultKVPairDelimiter = " "
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		Preset:            PresetJSON,
+		CSV: CSVConfig{
+			Delimiter: defaultCSVDelimiter,
+		},
+		KeyValue: KeyValueConfig{
+			Delimiter:     defaultKVDelimiter,
+			PairDelimiter: defaultKVPairDelimiter,
+		},
+		OnError:        defaultOnError,
+		ErrorAttribute: defaultErrorAttribute,
+	}
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	switch cfg.Preset {
+	case PresetJSON, PresetLogfmt:
+	case PresetCSV:
+		if len(cfg.CSV.Header) == 0 {
+			return errors.New("csv.header cannot be empty for the csv preset")
+		}
+		if utf8.RuneCountInString(cfg.CSV.Delimiter) != 1 {
+			return fmt.Errorf("csv.delimiter must be a single character, got %q", cfg.CSV.Delimiter)
+		}
+	case PresetKeyValue:
+		if cfg.KeyValue.Delimiter == "" {
+			return errors.New("key_value.delimiter cannot be empty")
+		}
+		if cfg.KeyValue.PairDelimiter == "" {
+			return errors.New("key_value.pair_delimiter cannot be empty")
+		}
+		if cfg.KeyValue.Delimiter == cfg.KeyValue.PairDelimiter {
+			return errors.New("key_value.delimiter and key_value.pair_delimiter must differ")
+		}
+	default:
+		return fmt.Errorf("unknown preset %q, expected one of: %s, %s, %s, %s",
+			cfg.Preset, PresetJSON, PresetLogfmt, PresetCSV, PresetKeyValue)
+	}
+
+	switch cfg.OnError {
+	case OnErrorKeep, OnErrorDrop:
+	case OnErrorRoute:
+		if cfg.ErrorCategory == "" {
+			return errors.New("error_category cannot be empty when on_error is route")
+		}
+	default:
+		return fmt.Errorf("unknown on_error action %q, expected one of: %s, %s, %s",
+			cfg.OnError, OnErrorKeep, OnErrorDrop, OnErrorRoute)
+	}
+	return nil
+}
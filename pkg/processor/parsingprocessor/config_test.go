@@ -0,0 +1,114 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "csv")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "csv")),
+			Preset:            PresetCSV,
+			SourceAttribute:   "message",
+			TargetAttribute:   "fields",
+			CSV: CSVConfig{
+				Header:    []string{"time", "level", "message"},
+				Delimiter: ";",
+			},
+			KeyValue: KeyValueConfig{
+				Delimiter:     "=",
+				PairDelimiter: " ",
+			},
+			OnError:        OnErrorRoute,
+			ErrorAttribute: "parse_error",
+			ErrorCategory:  "parsing/errors",
+		})
+
+	p2 := cfg.Processors[config.NewComponentIDWithName(typeStr, "key_value")]
+	assert.Equal(t, p2,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "key_value")),
+			Preset:            PresetKeyValue,
+			CSV: CSVConfig{
+				Delimiter: ",",
+			},
+			KeyValue: KeyValueConfig{
+				Delimiter:     ":",
+				PairDelimiter: ",",
+			},
+			OnError:        OnErrorDrop,
+			ErrorAttribute: "parsing.error",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "unknown preset", modify: func(cfg *Config) { cfg.Preset = "xml" }},
+		{name: "csv without header", modify: func(cfg *Config) { cfg.Preset = PresetCSV }},
+		{name: "multi character csv delimiter", modify: func(cfg *Config) {
+			cfg.Preset = PresetCSV
+			cfg.CSV.Header = []string{"a"}
+			cfg.CSV.Delimiter = "||"
+		}},
+		{name: "empty key value delimiter", modify: func(cfg *Config) {
+			cfg.Preset = PresetKeyValue
+			cfg.KeyValue.Delimiter = ""
+		}},
+		{name: "empty key value pair delimiter", modify: func(cfg *Config) {
+			cfg.Preset = PresetKeyValue
+			cfg.KeyValue.PairDelimiter = ""
+		}},
+		{name: "same key value delimiters", modify: func(cfg *Config) {
+			cfg.Preset = PresetKeyValue
+			cfg.KeyValue.PairDelimiter = "="
+		}},
+		{name: "unknown on_error", modify: func(cfg *Config) { cfg.OnError = "retry" }},
+		{name: "route without error category", modify: func(cfg *Config) { cfg.OnError = OnErrorRoute }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func init() {
+	err := view.Register(viewRecords)
+	if err != nil {
+		fmt.Printf("Error registering parsing processor's views: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+const (
+	resultParsed = "parsed"
+	resultFailed = "failed"
+)
+
+var (
+	tagProcessorKey, _ = tag.NewKey("processor")
+	tagPresetKey, _    = tag.NewKey("preset")
+	tagResultKey, _    = tag.NewKey("result")
+
+	mRecords = stats.Int64("parsing_processor_records", "Number of log records processed per parsing result", stats.UnitDimensionless)
+)
+
+var viewRecords = &view.View{
+	Name:        mRecords.Name(),
+	Description: mRecords.Description(),
+	Measure:     mRecords,
+	TagKeys:     []tag.Key{tagProcessorKey, tagPresetKey, tagResultKey},
+	Aggregation: view.Sum(),
+}
+
+func recordRecords(processor string, preset Preset, result string, count int64) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Upsert(tagProcessorKey, processor),
+			tag.Upsert(tagPresetKey, string(preset)),
+			tag.Upsert(tagResultKey, result),
+		},
+		mRecords.M(count),
+	)
+}
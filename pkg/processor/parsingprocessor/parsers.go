@@ -0,0 +1,231 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseFunc parses a line into fields. The values are of the types accepted by pcommon.NewMapFromRaw.
+type parseFunc func(line string) (map[string]interface{}, error)
+
+func newParseFunc(cfg *Config) parseFunc {
+	switch cfg.Preset {
+	case PresetLogfmt:
+		return parseLogfmt
+	case PresetCSV:
+		delimiter, _ := utf8.DecodeRuneInString(cfg.CSV.Delimiter)
+		return csvParser{header: cfg.CSV.Header, delimiter: delimiter}.parse
+	case PresetKeyValue:
+		return keyValueParser{delimiter: cfg.KeyValue.Delimiter, pairDelimiter: cfg.KeyValue.PairDelimiter}.parse
+	default:
+		return parseJSON
+	}
+}
+
+// parseJSON parses a JSON object. Integer numbers are kept as integers.
+func parseJSON(line string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	if fields == nil {
+		return nil, errors.New("json is not an object")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the json object")
+	}
+
+	for key, value := range fields {
+		fields[key] = convertJSONValue(value)
+	}
+	return fields, nil
+}
+
+func convertJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = convertJSONValue(value)
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = convertJSONValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseLogfmt parses space separated key=value pairs, where values can be double quoted.
+// Keys without a value are set to true, but at least one key=value pair is required,
+// so that plain text isn't parsed as a list of flags.
+func parseLogfmt(line string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	pairs := 0
+
+	for i := 0; i < len(line); {
+		if isLogfmtSpace(line[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && !isLogfmtSpace(line[i]) {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("missing key at position %d", start)
+		}
+		if i == len(line) || line[i] != '=' {
+			fields[key] = true
+			continue
+		}
+		i++
+
+		var value string
+		if i < len(line) && line[i] == '"' {
+			end := closingQuote(line, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value of key %q", key)
+			}
+			unquoted, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value of key %q: %w", key, err)
+			}
+			value = unquoted
+			i = end + 1
+		} else {
+			start = i
+			for i < len(line) && !isLogfmtSpace(line[i]) {
+				i++
+			}
+			value = line[start:i]
+		}
+		fields[key] = value
+		pairs++
+	}
+
+	if pairs == 0 {
+		return nil, errors.New("no key=value pairs found")
+	}
+	return fields, nil
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// closingQuote returns the index of the quote closing the one at start, skipping escaped quotes, or -1.
+func closingQuote(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+type csvParser struct {
+	header    []string
+	delimiter rune
+}
+
+// parse parses a single csv line, which must have exactly as many fields as the header.
+func (p csvParser) parse(line string) (map[string]interface{}, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = p.delimiter
+	reader.FieldsPerRecord = len(p.header)
+
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty csv line")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		return nil, errors.New("unexpected data after the csv line")
+	}
+
+	fields := make(map[string]interface{}, len(p.header))
+	for i, name := range p.header {
+		fields[name] = record[i]
+	}
+	return fields, nil
+}
+
+type keyValueParser struct {
+	delimiter     string
+	pairDelimiter string
+}
+
+// parse parses pairs of keys and values. Surrounding whitespace and quotes are trimmed from values.
+func (p keyValueParser) parse(line string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	for _, pair := range strings.Split(line, p.pairDelimiter) {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, p.delimiter)
+		if !ok {
+			return nil, fmt.Errorf("missing %q delimiter in %q", p.delimiter, pair)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("missing key in %q", pair)
+		}
+		fields[key] = trimQuotes(strings.TrimSpace(value))
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no key value pairs found")
+	}
+	return fields, nil
+}
+
+func trimQuotes(value string) string {
+	if len(value) >= 2 {
+		if first := value[0]; (first == '"' || first == '\'') && value[len(value)-1] == first {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	fields, err := parseJSON(`{"level":"info","count":3,"ratio":0.5,"ok":true,"tags":["a",1],"http":{"status":200},"none":null}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"level": "info",
+		"count": int64(3),
+		"ratio": 0.5,
+		"ok":    true,
+		"tags":  []interface{}{"a", int64(1)},
+		"http":  map[string]interface{}{"status": int64(200)},
+		"none":  nil,
+	}, fields)
+
+	for _, line := range []string{``, `{"level":`, `["a"]`, `null`, `"text"`, `{"a":1} {"b":2}`, `plain text`} {
+		_, err := parseJSON(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	fields, err := parseLogfmt(`level=info msg="user \"jane\" logged in" duration=12ms empty= debug`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"level":    "info",
+		"msg":      `user "jane" logged in`,
+		"duration": "12ms",
+		"empty":    "",
+		"debug":    true,
+	}, fields)
+
+	for _, line := range []string{``, `plain text`, `=value`, `msg="unterminated`, `msg="bad \q escape"`} {
+		_, err := parseLogfmt(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	parser := csvParser{header: []string{"time", "level", "message"}, delimiter: ';'}
+
+	fields, err := parser.parse(`2022-08-01;info;"hello; world"`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"time":    "2022-08-01",
+		"level":   "info",
+		"message": "hello; world",
+	}, fields)
+
+	for _, line := range []string{``, `2022-08-01;info`, `a;b;c;d`, `a;b;"c`, "a;b;c\nd;e;f"} {
+		_, err := parser.parse(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	parser := keyValueParser{delimiter: ":", pairDelimiter: ","}
+
+	fields, err := parser.parse(`user: jane, action:"login",,ip:10.0.0.1:8080`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"user":   "jane",
+		"action": "login",
+		"ip":     "10.0.0.1:8080",
+	}, fields)
+
+	for _, line := range []string{``, `,,`, `user:jane,plain`, `:value`} {
+		_, err := parser.parse(line)
+		assert.Error(t, err, line)
+	}
+}
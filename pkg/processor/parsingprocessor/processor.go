@@ -0,0 +1,181 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// sourceCategoryAttribute is the resource attribute the Sumo Logic exporter takes the source category from.
+const sourceCategoryAttribute = "_sourceCategory"
+
+type parsingProcessor struct {
+	name            string
+	preset          Preset
+	parse           parseFunc
+	sourceAttribute string
+	targetAttribute string
+	onError         OnErrorAction
+	errorAttribute  string
+	errorCategory   string
+	logger          *zap.Logger
+}
+
+func newParsingProcessor(cfg *Config, logger *zap.Logger) *parsingProcessor {
+	return &parsingProcessor{
+		name:            cfg.ID().String(),
+		preset:          cfg.Preset,
+		parse:           newParseFunc(cfg),
+		sourceAttribute: cfg.SourceAttribute,
+		targetAttribute: cfg.TargetAttribute,
+		onError:         cfg.OnError,
+		errorAttribute:  cfg.ErrorAttribute,
+		errorCategory:   cfg.ErrorCategory,
+		logger:          logger,
+	}
+}
+
+func (p *parsingProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	var parsed, failed int64
+	// routed holds the log records which failed to be parsed, grouped under resources with the error category.
+	routed := plog.NewLogs()
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		var (
+			routedRl          plog.ResourceLogs
+			hasRoutedResource bool
+		)
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sl := sls.At(j)
+			var (
+				routedRecords    plog.LogRecordSlice
+				hasRoutedRecords bool
+			)
+
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				err := p.parseLogRecord(lr)
+				if err == nil {
+					parsed++
+					return false
+				}
+				failed++
+				p.logger.Debug("Failed to parse log record", zap.Error(err))
+
+				switch p.onError {
+				case OnErrorDrop:
+					return true
+				case OnErrorRoute:
+					if !hasRoutedRecords {
+						if !hasRoutedResource {
+							routedRl = p.newRoutedResourceLogs(rl, routed)
+							hasRoutedResource = true
+						}
+						routedSl := routedRl.ScopeLogs().AppendEmpty()
+						sl.Scope().CopyTo(routedSl.Scope())
+						routedSl.SetSchemaUrl(sl.SchemaUrl())
+						routedRecords = routedSl.LogRecords()
+						hasRoutedRecords = true
+					}
+					p.setError(lr, err)
+					lr.MoveTo(routedRecords.AppendEmpty())
+					return true
+				default:
+					p.setError(lr, err)
+					return false
+				}
+			})
+		}
+	}
+
+	if parsed > 0 {
+		recordRecords(p.name, p.preset, resultParsed, parsed)
+	}
+	if failed > 0 {
+		recordRecords(p.name, p.preset, resultFailed, failed)
+
+		if p.onError != OnErrorKeep {
+			removeEmpty(ld)
+		}
+	}
+
+	routed.ResourceLogs().MoveAndAppendTo(rls)
+	return ld, nil
+}
+
+// newRoutedResourceLogs adds a copy of the resource of rl with the error category to routed.
+func (p *parsingProcessor) newRoutedResourceLogs(rl plog.ResourceLogs, routed plog.Logs) plog.ResourceLogs {
+	routedRl := routed.ResourceLogs().AppendEmpty()
+	rl.Resource().CopyTo(routedRl.Resource())
+	routedRl.SetSchemaUrl(rl.SchemaUrl())
+	routedRl.Resource().Attributes().UpsertString(sourceCategoryAttribute, p.errorCategory)
+	return routedRl
+}
+
+func (p *parsingProcessor) parseLogRecord(lr plog.LogRecord) error {
+	source := lr.Body()
+	if p.sourceAttribute != "" {
+		value, ok := lr.Attributes().Get(p.sourceAttribute)
+		if !ok {
+			return fmt.Errorf("attribute %q not found", p.sourceAttribute)
+		}
+		source = value
+	}
+	if source.Type() != pcommon.ValueTypeString {
+		return fmt.Errorf("cannot parse value of type %s", source.Type())
+	}
+
+	fields, err := p.parse(source.StringVal())
+	if err != nil {
+		return err
+	}
+
+	parsed := pcommon.NewMapFromRaw(fields)
+	if p.targetAttribute != "" {
+		target := pcommon.NewValueMap()
+		parsed.CopyTo(target.MapVal())
+		lr.Attributes().Upsert(p.targetAttribute, target)
+		return nil
+	}
+	parsed.Range(func(k string, v pcommon.Value) bool {
+		lr.Attributes().Upsert(k, v)
+		return true
+	})
+	return nil
+}
+
+func (p *parsingProcessor) setError(lr plog.LogRecord, err error) {
+	if p.errorAttribute != "" {
+		lr.Attributes().UpsertString(p.errorAttribute, err.Error())
+	}
+}
+
+// removeEmpty removes scopes and resources left without log records.
+func removeEmpty(ld plog.Logs) {
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+}
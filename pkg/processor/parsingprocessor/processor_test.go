@@ -0,0 +1,174 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parsingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func newTestLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().UpsertString(sourceCategoryAttribute, "app")
+	rl.Resource().Attributes().UpsertString("host", "node-1")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("scope")
+	for _, body := range bodies {
+		sl.LogRecords().AppendEmpty().Body().SetStringVal(body)
+	}
+	return ld
+}
+
+func processLogs(t *testing.T, cfg *Config, ld plog.Logs) plog.Logs {
+	require.NoError(t, cfg.Validate())
+	p := newParsingProcessor(cfg, zap.NewNop())
+	ld, err := p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	return ld
+}
+
+func TestProcessLogsJSON(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+
+	ld := processLogs(t, cfg, newTestLogs(`{"level":"info","status":200}`))
+
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, map[string]interface{}{"level": "info", "status": int64(200)}, lr.Attributes().AsRaw())
+	assert.Equal(t, `{"level":"info","status":200}`, lr.Body().StringVal())
+}
+
+func TestProcessLogsSourceAndTargetAttribute(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Preset = PresetLogfmt
+	cfg.SourceAttribute = "message"
+	cfg.TargetAttribute = "fields"
+
+	ld := newTestLogs("body")
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	lr.Attributes().UpsertString("message", "level=warn user=jane")
+
+	ld = processLogs(t, cfg, ld)
+
+	lr = ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, map[string]interface{}{
+		"message": "level=warn user=jane",
+		"fields":  map[string]interface{}{"level": "warn", "user": "jane"},
+	}, lr.Attributes().AsRaw())
+}
+
+func TestProcessLogsOnErrorKeep(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+
+	ld := newTestLogs(`{"level":"info"}`, "not json")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty().Body().SetIntVal(1)
+	ld = processLogs(t, cfg, ld)
+
+	logs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, logs.Len())
+	assert.Equal(t, map[string]interface{}{"level": "info"}, logs.At(0).Attributes().AsRaw())
+
+	for i := 1; i < logs.Len(); i++ {
+		value, ok := logs.At(i).Attributes().Get(defaultErrorAttribute)
+		require.True(t, ok)
+		assert.NotEmpty(t, value.StringVal())
+	}
+	assert.Equal(t, "not json", logs.At(1).Body().StringVal())
+}
+
+func TestProcessLogsOnErrorKeepWithoutErrorAttribute(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ErrorAttribute = ""
+
+	ld := processLogs(t, cfg, newTestLogs("not json"))
+
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, 0, lr.Attributes().Len())
+}
+
+func TestProcessLogsOnErrorDrop(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.OnError = OnErrorDrop
+
+	ld := processLogs(t, cfg, newTestLogs("not json", `{"level":"info"}`, "not json either"))
+	require.Equal(t, 1, ld.LogRecordCount())
+	assert.Equal(t, `{"level":"info"}`, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal())
+
+	ld = processLogs(t, cfg, newTestLogs("not json"))
+	assert.Equal(t, 0, ld.ResourceLogs().Len())
+}
+
+func TestProcessLogsOnErrorRoute(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.OnError = OnErrorRoute
+	cfg.ErrorCategory = "parsing/errors"
+
+	ld := processLogs(t, cfg, newTestLogs("not json", `{"level":"info"}`, "not json either"))
+
+	rls := ld.ResourceLogs()
+	require.Equal(t, 2, rls.Len())
+
+	parsed := rls.At(0)
+	assert.Equal(t, map[string]interface{}{sourceCategoryAttribute: "app", "host": "node-1"}, parsed.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, parsed.ScopeLogs().At(0).LogRecords().Len())
+	assert.Equal(t, `{"level":"info"}`, parsed.ScopeLogs().At(0).LogRecords().At(0).Body().StringVal())
+
+	routed := rls.At(1)
+	assert.Equal(t, map[string]interface{}{sourceCategoryAttribute: "parsing/errors", "host": "node-1"}, routed.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, routed.ScopeLogs().Len())
+	assert.Equal(t, "scope", routed.ScopeLogs().At(0).Scope().Name())
+
+	logs := routed.ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, "not json", logs.At(0).Body().StringVal())
+	assert.Equal(t, "not json either", logs.At(1).Body().StringVal())
+	for i := 0; i < logs.Len(); i++ {
+		_, ok := logs.At(i).Attributes().Get(defaultErrorAttribute)
+		assert.True(t, ok)
+	}
+}
+
+func TestProcessLogsOnErrorRouteAllFailed(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.OnError = OnErrorRoute
+	cfg.ErrorCategory = "parsing/errors"
+
+	ld := processLogs(t, cfg, newTestLogs("not json"))
+
+	require.Equal(t, 1, ld.ResourceLogs().Len())
+	category, ok := ld.ResourceLogs().At(0).Resource().Attributes().Get(sourceCategoryAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "parsing/errors", category.StringVal())
+	assert.Equal(t, 1, ld.LogRecordCount())
+}
+
+func TestProcessLogsMissingSourceAttribute(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SourceAttribute = "message"
+
+	ld := processLogs(t, cfg, newTestLogs(`{"level":"info"}`))
+
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	value, ok := lr.Attributes().Get(defaultErrorAttribute)
+	require.True(t, ok)
+	assert.Equal(t, pcommon.ValueTypeString, value.Type())
+	assert.Contains(t, value.StringVal(), "message")
+}
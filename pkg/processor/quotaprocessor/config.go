@@ -0,0 +1,113 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// SourceAttributes are the resource attributes identifying a source.
+	// Data with the same values of these attributes is accounted together.
+	SourceAttributes []string `mapstructure:"source_attributes"`
+	// Window is the length of the sliding window over which bytes are accounted.
+	Window time.Duration `mapstructure:"window"`
+	// DefaultQuota is applied to sources which don't have a quota configured in Quotas.
+	DefaultQuota QuotaConfig `mapstructure:"default_quota"`
+	// Quotas configure quotas for specific sources.
+	Quotas []SourceQuotaConfig `mapstructure:"quotas"`
+	// OverQuotaAttribute is the name of the resource attribute set to true
+	// on data of sources which exceeded their soft limit.
+	OverQuotaAttribute string `mapstructure:"over_quota_attribute"`
+}
+
+// QuotaConfig defines limits of bytes per window. Zero means no limit.
+type QuotaConfig struct {
+	// SoftLimit is the number of bytes after which data is tagged as over quota.
+	SoftLimit int64 `mapstructure:"soft_limit"`
+	// HardLimit is the number of bytes after which data is dropped.
+	HardLimit int64 `mapstructure:"hard_limit"`
+}
+
+// SourceQuotaConfig defines a quota for a single source.
+type SourceQuotaConfig struct {
+	// Source is the value of the source attributes joined with "/",
+	// e.g. "prod/payments" for source_attributes [cluster, k8s.namespace.name].
+	Source      string `mapstructure:"source"`
+	QuotaConfig `mapstructure:",squash"`
+}
+
+const (
+	defaultSourceAttribute    = "_sourceCategory"
+	defaultWindow             = time.Minute
+	defaultOverQuotaAttribute = "quota.exceeded"
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings:  config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		SourceAttributes:   []string{defaultSourceAttribute},
+		Window:             defaultWindow,
+		OverQuotaAttribute: defaultOverQuotaAttribute,
+	}
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	if len(cfg.SourceAttributes) == 0 {
+		return errors.New("source_attributes cannot be empty")
+	}
+	if cfg.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if cfg.OverQuotaAttribute == "" {
+		return errors.New("over_quota_attribute cannot be empty")
+	}
+	if err := cfg.DefaultQuota.validate(); err != nil {
+		return fmt.Errorf("invalid default_quota: %w", err)
+	}
+
+	sources := map[string]struct{}{}
+	for _, quota := range cfg.Quotas {
+		if _, ok := sources[quota.Source]; ok {
+			return fmt.Errorf("duplicate quota for source %q", quota.Source)
+		}
+		sources[quota.Source] = struct{}{}
+
+		if err := quota.validate(); err != nil {
+			return fmt.Errorf("invalid quota for source %q: %w", quota.Source, err)
+		}
+	}
+	return nil
+}
+
+func (q QuotaConfig) validate() error {
+	if q.SoftLimit < 0 || q.HardLimit < 0 {
+		return errors.New("limits cannot be negative")
+	}
+	if q.SoftLimit > 0 && q.HardLimit > 0 && q.SoftLimit > q.HardLimit {
+		return errors.New("soft_limit cannot be greater than hard_limit")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			SourceAttributes:  []string{"cluster", "k8s.namespace.name"},
+			Window:            5 * time.Minute,
+			DefaultQuota: QuotaConfig{
+				SoftLimit: 1000000,
+				HardLimit: 2000000,
+			},
+			Quotas: []SourceQuotaConfig{
+				{Source: "prod/payments", QuotaConfig: QuotaConfig{SoftLimit: 5000000}},
+				{Source: "prod/batch", QuotaConfig: QuotaConfig{HardLimit: 100000}},
+			},
+			OverQuotaAttribute: "over_quota",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no source attributes", modify: func(cfg *Config) { cfg.SourceAttributes = nil }},
+		{name: "zero window", modify: func(cfg *Config) { cfg.Window = 0 }},
+		{name: "empty over quota attribute", modify: func(cfg *Config) { cfg.OverQuotaAttribute = "" }},
+		{name: "negative limit", modify: func(cfg *Config) { cfg.DefaultQuota.HardLimit = -1 }},
+		{name: "soft limit above hard limit", modify: func(cfg *Config) {
+			cfg.Quotas = []SourceQuotaConfig{{Source: "a", QuotaConfig: QuotaConfig{SoftLimit: 10, HardLimit: 5}}}
+		}},
+		{name: "duplicate source", modify: func(cfg *Config) {
+			cfg.Quotas = []SourceQuotaConfig{{Source: "a"}, {Source: "a"}}
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
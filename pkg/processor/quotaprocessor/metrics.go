@@ -0,0 +1,67 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func init() {
+	err := view.Register(viewBytes)
+	if err != nil {
+		fmt.Printf("Error registering quota processor's views: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+const (
+	decisionAccepted  = "accepted"
+	decisionOverQuota = "over_quota"
+	decisionDropped   = "dropped"
+)
+
+var (
+	tagProcessorKey, _ = tag.NewKey("processor")
+	tagSourceKey, _    = tag.NewKey("source")
+	tagDecisionKey, _  = tag.NewKey("decision")
+
+	mBytes = stats.Int64("quota_processor_bytes", "Number of bytes processed per source and quota decision", stats.UnitBytes)
+)
+
+var viewBytes = &view.View{
+	Name:        mBytes.Name(),
+	Description: mBytes.Description(),
+	Measure:     mBytes,
+	TagKeys:     []tag.Key{tagProcessorKey, tagSourceKey, tagDecisionKey},
+	Aggregation: view.Sum(),
+}
+
+func recordBytes(processor string, source string, decision string, bytes int64) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Upsert(tagProcessorKey, processor),
+			tag.Upsert(tagSourceKey, source),
+			tag.Upsert(tagDecisionKey, decision),
+		},
+		mBytes.M(bytes),
+	)
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// sourceSeparator joins values of the source attributes into a source key.
+const sourceSeparator = "/"
+
+var (
+	logsSizer    = plog.NewProtoMarshaler().(plog.Sizer)
+	metricsSizer = pmetric.NewProtoMarshaler().(pmetric.Sizer)
+	tracesSizer  = ptrace.NewProtoMarshaler().(ptrace.Sizer)
+)
+
+type quotaProcessor struct {
+	name               string
+	sourceAttributes   []string
+	window             time.Duration
+	defaultQuota       QuotaConfig
+	quotas             map[string]QuotaConfig
+	overQuotaAttribute string
+	logger             *zap.Logger
+
+	mutex       sync.Mutex
+	sources     map[string]*sourceUsage
+	lastCleanup time.Time
+
+	now func() time.Time
+}
+
+type sourceUsage struct {
+	window *slidingWindow
+	// dropping is true while data of the source is dropped because of the hard limit.
+	dropping bool
+}
+
+func newQuotaProcessor(cfg *Config, logger *zap.Logger) *quotaProcessor {
+	quotas := make(map[string]QuotaConfig, len(cfg.Quotas))
+	for _, quota := range cfg.Quotas {
+		quotas[quota.Source] = quota.QuotaConfig
+	}
+
+	return &quotaProcessor{
+		name:               cfg.ID().String(),
+		sourceAttributes:   cfg.SourceAttributes,
+		window:             cfg.Window,
+		defaultQuota:       cfg.DefaultQuota,
+		quotas:             quotas,
+		overQuotaAttribute: cfg.OverQuotaAttribute,
+		logger:             logger,
+		sources:            map[string]*sourceUsage{},
+		now:                time.Now,
+	}
+}
+
+func (p *quotaProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		// Move the resource into a temporary container to measure its size without copying it.
+		tmp := plog.NewLogs()
+		rl.MoveTo(tmp.ResourceLogs().AppendEmpty())
+		size := logsSizer.LogsSize(tmp)
+		tmp.ResourceLogs().At(0).MoveTo(rl)
+
+		return p.enforce(rl.Resource(), size)
+	})
+	return ld, nil
+}
+
+func (p *quotaProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		// Move the resource into a temporary container to measure its size without copying it.
+		tmp := pmetric.NewMetrics()
+		rm.MoveTo(tmp.ResourceMetrics().AppendEmpty())
+		size := metricsSizer.MetricsSize(tmp)
+		tmp.ResourceMetrics().At(0).MoveTo(rm)
+
+		return p.enforce(rm.Resource(), size)
+	})
+	return md, nil
+}
+
+func (p *quotaProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		// Move the resource into a temporary container to measure its size without copying it.
+		tmp := ptrace.NewTraces()
+		rs.MoveTo(tmp.ResourceSpans().AppendEmpty())
+		size := tracesSizer.TracesSize(tmp)
+		tmp.ResourceSpans().At(0).MoveTo(rs)
+
+		return p.enforce(rs.Resource(), size)
+	})
+	return td, nil
+}
+
+// enforce accounts size bytes to the source of the resource and applies its quota.
+// It returns true if the data should be dropped.
+func (p *quotaProcessor) enforce(resource pcommon.Resource, size int) bool {
+	source := p.sourceKey(resource.Attributes())
+	decision := p.account(source, int64(size))
+	recordBytes(p.name, source, decision, int64(size))
+
+	switch decision {
+	case decisionDropped:
+		return true
+	case decisionOverQuota:
+		resource.Attributes().UpsertBool(p.overQuotaAttribute, true)
+	}
+	return false
+}
+
+func (p *quotaProcessor) sourceKey(attributes pcommon.Map) string {
+	values := make([]string, 0, len(p.sourceAttributes))
+	for _, key := range p.sourceAttributes {
+		value := ""
+		if v, ok := attributes.Get(key); ok {
+			value = v.AsString()
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, sourceSeparator)
+}
+
+func (p *quotaProcessor) quotaFor(source string) QuotaConfig {
+	if quota, ok := p.quotas[source]; ok {
+		return quota
+	}
+	return p.defaultQuota
+}
+
+// account checks whether size bytes fit into the quota of the source and returns the decision.
+// Dropped bytes aren't accounted, so that a source is let through again once its usage falls under the hard limit.
+func (p *quotaProcessor) account(source string, size int64) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	p.cleanup(now)
+
+	usage, ok := p.sources[source]
+	if !ok {
+		usage = &sourceUsage{window: newSlidingWindow(p.window, now)}
+		p.sources[source] = usage
+	}
+
+	quota := p.quotaFor(source)
+	total := usage.window.total(now) + size
+
+	if quota.HardLimit > 0 && total > quota.HardLimit {
+		if !usage.dropping {
+			p.logger.Warn("Source exceeded its hard quota, dropping its data",
+				zap.String("source", source), zap.Int64("hard_limit", quota.HardLimit), zap.Duration("window", p.window))
+			usage.dropping = true
+		}
+		return decisionDropped
+	}
+	if usage.dropping {
+		p.logger.Info("Source is under its hard quota again", zap.String("source", source))
+		usage.dropping = false
+	}
+
+	usage.window.add(now, size)
+	if quota.SoftLimit > 0 && total > quota.SoftLimit {
+		return decisionOverQuota
+	}
+	return decisionAccepted
+}
+
+// cleanup removes sources without any usage in the window, so that the number of tracked sources doesn't grow unbounded.
+func (p *quotaProcessor) cleanup(now time.Time) {
+	if now.Sub(p.lastCleanup) < p.window {
+		return
+	}
+	p.lastCleanup = now
+
+	for source, usage := range p.sources {
+		if usage.window.total(now) == 0 {
+			delete(p.sources, source)
+		}
+	}
+}
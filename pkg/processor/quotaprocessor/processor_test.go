@@ -0,0 +1,161 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestProcessor(t *testing.T, modify func(*Config)) (*quotaProcessor, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	now := testNow
+	processor := newQuotaProcessor(cfg, zap.NewNop())
+	processor.now = func() time.Time { return now }
+	return processor, &now
+}
+
+// newLogs creates logs with a single record with the given body for each of the source categories.
+func newLogs(body string, sourceCategories ...string) plog.Logs {
+	logs := plog.NewLogs()
+	for _, sourceCategory := range sourceCategories {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("_sourceCategory", sourceCategory)
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal(body)
+	}
+	return logs
+}
+
+func logsSize(sourceCategory string, body string) int {
+	return logsSizer.LogsSize(newLogs(body, sourceCategory))
+}
+
+func TestHardQuota(t *testing.T) {
+	body := "some log line"
+	size := int64(logsSize("noisy", body))
+
+	processor, now := newTestProcessor(t, func(cfg *Config) {
+		cfg.DefaultQuota.HardLimit = 2 * size
+	})
+
+	for i := 0; i < 2; i++ {
+		result, err := processor.processLogs(context.Background(), newLogs(body, "noisy"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.LogRecordCount())
+	}
+
+	result, err := processor.processLogs(context.Background(), newLogs(body, "noisy", "quiet"))
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ResourceLogs().Len(), "only the source over quota should be dropped")
+	sourceCategory, _ := result.ResourceLogs().At(0).Resource().Attributes().Get("_sourceCategory")
+	assert.Equal(t, "quiet", sourceCategory.AsString())
+
+	// data is let through again once the window moves
+	*now = now.Add(time.Minute)
+	result, err = processor.processLogs(context.Background(), newLogs(body, "noisy"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.LogRecordCount())
+}
+
+func TestSoftQuota(t *testing.T) {
+	body := "some log line"
+	size := int64(logsSize("payments", body))
+
+	processor, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.DefaultQuota.HardLimit = size
+		cfg.Quotas = []SourceQuotaConfig{
+			{Source: "payments", QuotaConfig: QuotaConfig{SoftLimit: size}},
+		}
+	})
+
+	result, err := processor.processLogs(context.Background(), newLogs(body, "payments"))
+	require.NoError(t, err)
+	_, ok := result.ResourceLogs().At(0).Resource().Attributes().Get(defaultOverQuotaAttribute)
+	assert.False(t, ok)
+
+	// the source specific quota replaces the default one, so the data isn't dropped
+	result, err = processor.processLogs(context.Background(), newLogs(body, "payments"))
+	require.NoError(t, err)
+	require.Equal(t, 1, result.LogRecordCount())
+	overQuota, ok := result.ResourceLogs().At(0).Resource().Attributes().Get(defaultOverQuotaAttribute)
+	require.True(t, ok)
+	assert.True(t, overQuota.BoolVal())
+}
+
+func TestSourceKey(t *testing.T) {
+	processor, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.SourceAttributes = []string{"cluster", "k8s.namespace.name"}
+	})
+
+	logs := plog.NewLogs()
+	attributes := logs.ResourceLogs().AppendEmpty().Resource().Attributes()
+	attributes.InsertString("cluster", "prod")
+	attributes.InsertString("k8s.namespace.name", "payments")
+	assert.Equal(t, "prod/payments", processor.sourceKey(attributes))
+
+	attributes.Remove("cluster")
+	assert.Equal(t, "/payments", processor.sourceKey(attributes))
+}
+
+func TestCleanup(t *testing.T) {
+	processor, now := newTestProcessor(t, nil)
+
+	_, err := processor.processLogs(context.Background(), newLogs("log", "a", "b"))
+	require.NoError(t, err)
+	assert.Len(t, processor.sources, 2)
+
+	*now = now.Add(2 * time.Minute)
+	_, err = processor.processLogs(context.Background(), newLogs("log", "a"))
+	require.NoError(t, err)
+	assert.Len(t, processor.sources, 1)
+	assert.Contains(t, processor.sources, "a")
+}
+
+func TestHardQuotaMetricsAndTraces(t *testing.T) {
+	processor, _ := newTestProcessor(t, func(cfg *Config) {
+		cfg.DefaultQuota.HardLimit = 1
+	})
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("_sourceCategory", "metrics")
+	rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("metric")
+	resultMetrics, err := processor.processMetrics(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, 0, resultMetrics.ResourceMetrics().Len())
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("_sourceCategory", "traces")
+	rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	resultTraces, err := processor.processTraces(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, 0, resultTraces.ResourceSpans().Len())
+}
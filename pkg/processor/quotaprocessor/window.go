@@ -0,0 +1,71 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import "time"
+
+// windowBuckets is the number of buckets a sliding window is divided into.
+const windowBuckets = 10
+
+// slidingWindow sums values added within the last window duration.
+// The window moves in steps of window / windowBuckets.
+type slidingWindow struct {
+	bucketDuration time.Duration
+	buckets        [windowBuckets]int64
+	// head is the index of the bucket values are currently added to.
+	head int
+	// headStart is the time the head bucket started at.
+	headStart time.Time
+}
+
+func newSlidingWindow(window time.Duration, now time.Time) *slidingWindow {
+	bucketDuration := window / windowBuckets
+	if bucketDuration <= 0 {
+		bucketDuration = 1
+	}
+	return &slidingWindow{
+		bucketDuration: bucketDuration,
+		headStart:      now,
+	}
+}
+
+// advance moves the head to the bucket for now, clearing buckets which fell out of the window.
+func (w *slidingWindow) advance(now time.Time) {
+	steps := int64(now.Sub(w.headStart) / w.bucketDuration)
+	if steps <= 0 {
+		return
+	}
+
+	for i := int64(0); i < steps && i < windowBuckets; i++ {
+		w.head = (w.head + 1) % windowBuckets
+		w.buckets[w.head] = 0
+	}
+	w.headStart = w.headStart.Add(time.Duration(steps) * w.bucketDuration)
+}
+
+func (w *slidingWindow) add(now time.Time, value int64) {
+	w.advance(now)
+	w.buckets[w.head] += value
+}
+
+func (w *slidingWindow) total(now time.Time) int64 {
+	w.advance(now)
+
+	var total int64
+	for _, value := range w.buckets {
+		total += value
+	}
+	return total
+}
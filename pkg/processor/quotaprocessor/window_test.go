@@ -0,0 +1,43 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	start := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	window := newSlidingWindow(10*time.Second, start)
+
+	window.add(start, 100)
+	window.add(start.Add(5*time.Second), 50)
+	assert.EqualValues(t, 150, window.total(start.Add(9*time.Second)))
+
+	// the first value falls out of the window
+	assert.EqualValues(t, 50, window.total(start.Add(10*time.Second)))
+
+	window.add(start.Add(12*time.Second), 25)
+	assert.EqualValues(t, 75, window.total(start.Add(14*time.Second)))
+	assert.EqualValues(t, 25, window.total(start.Add(15*time.Second)))
+
+	// all values fall out of the window after a long pause
+	assert.EqualValues(t, 0, window.total(start.Add(time.Hour)))
+	window.add(start.Add(time.Hour), 10)
+	assert.EqualValues(t, 10, window.total(start.Add(time.Hour+time.Second)))
+}
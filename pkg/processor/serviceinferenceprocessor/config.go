@@ -0,0 +1,125 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceinferenceprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// ServiceName lists the sources service.name is inferred from, in order of precedence.
+	ServiceName []SourceConfig `mapstructure:"service_name"`
+	// DeploymentEnvironment lists the sources deployment.environment is inferred from,
+	// in order of precedence.
+	DeploymentEnvironment []SourceConfig `mapstructure:"deployment_environment"`
+}
+
+// SourceConfig describes a single resource attribute a value can be inferred from.
+type SourceConfig struct {
+	// Attribute is the name of the resource attribute, e.g. k8s.pod.label.app.
+	Attribute string `mapstructure:"attribute"`
+	// Convention is the naming convention used to extract the value from the attribute.
+	// By default the attribute value is used as is.
+	Convention ConventionType `mapstructure:"convention"`
+}
+
+type ConventionType string
+
+const (
+	// NoConvention uses the attribute value as is.
+	NoConvention ConventionType = ""
+	// ImageNameConvention extracts the image name from a container image reference,
+	// e.g. "registry.example.com/team/checkout:1.2.3" becomes "checkout".
+	ImageNameConvention ConventionType = "image_name"
+	// PodNameConvention strips the suffixes added by workload controllers from a pod name,
+	// e.g. "checkout-5d8c7f9b6-x2x4z" becomes "checkout".
+	PodNameConvention ConventionType = "pod_name"
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+var _ config.Unmarshallable = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		ServiceName: []SourceConfig{
+			{Attribute: "k8s.pod.annotation.resource.opentelemetry.io/service.name"},
+			{Attribute: "k8s.pod.label.app.kubernetes.io/name"},
+			{Attribute: "k8s.pod.label.app"},
+			{Attribute: "k8s.deployment.name"},
+			{Attribute: "k8s.statefulset.name"},
+			{Attribute: "k8s.daemonset.name"},
+			{Attribute: "k8s.cronjob.name"},
+			{Attribute: "k8s.job.name"},
+			{Attribute: "k8s.container.image", Convention: ImageNameConvention},
+			{Attribute: "k8s.pod.name", Convention: PodNameConvention},
+		},
+		DeploymentEnvironment: []SourceConfig{
+			{Attribute: "k8s.pod.annotation.resource.opentelemetry.io/deployment.environment"},
+			{Attribute: "k8s.pod.label.environment"},
+			{Attribute: "k8s.pod.label.env"},
+		},
+	}
+}
+
+// Unmarshal a confmap.Conf into the config struct.
+// The configured lists of sources replace the default ones, instead of being merged into them item by item.
+func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
+	if componentParser == nil {
+		return nil
+	}
+	if componentParser.IsSet("service_name") {
+		cfg.ServiceName = nil
+	}
+	if componentParser.IsSet("deployment_environment") {
+		cfg.DeploymentEnvironment = nil
+	}
+	return componentParser.UnmarshalExact(cfg)
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	if len(cfg.ServiceName) == 0 && len(cfg.DeploymentEnvironment) == 0 {
+		return errors.New("at least one of service_name and deployment_environment must be set")
+	}
+	if err := validateSources(cfg.ServiceName); err != nil {
+		return fmt.Errorf("invalid service_name: %w", err)
+	}
+	if err := validateSources(cfg.DeploymentEnvironment); err != nil {
+		return fmt.Errorf("invalid deployment_environment: %w", err)
+	}
+	return nil
+}
+
+func validateSources(sources []SourceConfig) error {
+	for _, source := range sources {
+		if source.Attribute == "" {
+			return errors.New("source attribute cannot be empty")
+		}
+		switch source.Convention {
+		case NoConvention, ImageNameConvention, PodNameConvention:
+		default:
+			return fmt.Errorf("unknown convention %q for attribute %q", source.Convention, source.Attribute)
+		}
+	}
+	return nil
+}
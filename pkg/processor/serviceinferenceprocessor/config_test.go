@@ -0,0 +1,81 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceinferenceprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	// the configured sources replace the default ones
+	assert.Equal(t,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			ServiceName: []SourceConfig{
+				{Attribute: "k8s.pod.label.component"},
+				{Attribute: "k8s.container.image", Convention: ImageNameConvention},
+			},
+			DeploymentEnvironment: []SourceConfig{
+				{Attribute: "k8s.namespace.name"},
+			},
+		}, p1)
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no sources", modify: func(cfg *Config) {
+			cfg.ServiceName = nil
+			cfg.DeploymentEnvironment = nil
+		}},
+		{name: "empty attribute", modify: func(cfg *Config) {
+			cfg.ServiceName = append(cfg.ServiceName, SourceConfig{})
+		}},
+		{name: "unknown convention", modify: func(cfg *Config) {
+			cfg.DeploymentEnvironment = []SourceConfig{{Attribute: "k8s.namespace.name", Convention: "suffix"}}
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
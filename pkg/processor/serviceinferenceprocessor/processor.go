@@ -0,0 +1,157 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceinferenceprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	serviceNameAttribute           = "service.name"
+	deploymentEnvironmentAttribute = "deployment.environment"
+
+	// unknownServicePrefix is the prefix of the service.name set by the OpenTelemetry SDKs
+	// when the service name isn't configured, e.g. "unknown_service:java".
+	unknownServicePrefix = "unknown_service"
+)
+
+var (
+	// Kubernetes generates name suffixes using this alphabet, see k8s.io/apimachinery/pkg/util/rand.
+	// Deployment pods get the pod template hash and a random suffix, daemon set and job pods only the random suffix.
+	generatedPodNameRegex = regexp.MustCompile(`^(.+?)(-[bcdfghjklmnpqrstvwxz2456789]{6,10})?-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+	// Stateful set pods get their ordinal as the suffix.
+	statefulPodNameRegex = regexp.MustCompile(`^(.+)-[0-9]+$`)
+)
+
+type serviceInferenceProcessor struct {
+	serviceName           []SourceConfig
+	deploymentEnvironment []SourceConfig
+}
+
+func newServiceInferenceProcessor(cfg *Config) *serviceInferenceProcessor {
+	return &serviceInferenceProcessor{
+		serviceName:           cfg.ServiceName,
+		deploymentEnvironment: cfg.DeploymentEnvironment,
+	}
+}
+
+func (p *serviceInferenceProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		p.processResource(rls.At(i).Resource())
+	}
+	return ld, nil
+}
+
+func (p *serviceInferenceProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		p.processResource(rms.At(i).Resource())
+	}
+	return md, nil
+}
+
+func (p *serviceInferenceProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		p.processResource(rss.At(i).Resource())
+	}
+	return td, nil
+}
+
+func (p *serviceInferenceProcessor) processResource(resource pcommon.Resource) {
+	attributes := resource.Attributes()
+
+	if !hasServiceName(attributes) {
+		if value, ok := infer(attributes, p.serviceName); ok {
+			attributes.UpsertString(serviceNameAttribute, value)
+		}
+	}
+
+	if _, ok := attributes.Get(deploymentEnvironmentAttribute); !ok {
+		if value, ok := infer(attributes, p.deploymentEnvironment); ok {
+			attributes.InsertString(deploymentEnvironmentAttribute, value)
+		}
+	}
+}
+
+// hasServiceName checks whether the resource has a service name set explicitly,
+// the default one set by the OpenTelemetry SDKs doesn't count.
+func hasServiceName(attributes pcommon.Map) bool {
+	value, ok := attributes.Get(serviceNameAttribute)
+	if !ok {
+		return false
+	}
+	name := value.AsString()
+	return name != "" && !strings.HasPrefix(name, unknownServicePrefix)
+}
+
+// infer returns the value of the first source present in the attributes.
+func infer(attributes pcommon.Map, sources []SourceConfig) (string, bool) {
+	for _, source := range sources {
+		value, ok := attributes.Get(source.Attribute)
+		if !ok {
+			continue
+		}
+		if result := applyConvention(value.AsString(), source.Convention); result != "" {
+			return result, true
+		}
+	}
+	return "", false
+}
+
+func applyConvention(value string, convention ConventionType) string {
+	switch convention {
+	case ImageNameConvention:
+		return imageName(value)
+	case PodNameConvention:
+		return podName(value)
+	default:
+		return value
+	}
+}
+
+// imageName returns the name of the image without the registry, repository path, tag and digest,
+// e.g. "registry.example.com:5000/team/checkout:1.2.3@sha256:..." becomes "checkout".
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	if i := strings.Index(image, ":"); i >= 0 {
+		image = image[:i]
+	}
+	return image
+}
+
+// podName returns the name of the workload owning the pod, based on the names generated by the controllers.
+func podName(pod string) string {
+	if match := generatedPodNameRegex.FindStringSubmatch(pod); match != nil {
+		return match[1]
+	}
+	if match := statefulPodNameRegex.FindStringSubmatch(pod); match != nil {
+		return match[1]
+	}
+	return pod
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceinferenceprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func processResourceAttributes(t *testing.T, cfg *Config, attributes map[string]interface{}) map[string]interface{} {
+	logs := plog.NewLogs()
+	pcommon.NewMapFromRaw(attributes).CopyTo(logs.ResourceLogs().AppendEmpty().Resource().Attributes())
+
+	result, err := newServiceInferenceProcessor(cfg).processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	return result.ResourceLogs().At(0).Resource().Attributes().AsRaw()
+}
+
+func TestInferServiceName(t *testing.T) {
+	testcases := []struct {
+		name       string
+		attributes map[string]interface{}
+		expected   string
+	}{
+		{
+			name: "annotation takes precedence",
+			attributes: map[string]interface{}{
+				"k8s.pod.annotation.resource.opentelemetry.io/service.name": "checkout-api",
+				"k8s.pod.label.app.kubernetes.io/name":                      "checkout",
+				"k8s.deployment.name":                                       "checkout-deployment",
+			},
+			expected: "checkout-api",
+		},
+		{
+			name: "recommended label",
+			attributes: map[string]interface{}{
+				"k8s.pod.label.app.kubernetes.io/name": "checkout",
+				"k8s.pod.label.app":                    "legacy",
+			},
+			expected: "checkout",
+		},
+		{
+			name: "workload name",
+			attributes: map[string]interface{}{
+				"k8s.statefulset.name": "postgres",
+				"k8s.container.image":  "docker.io/library/postgres:14",
+			},
+			expected: "postgres",
+		},
+		{
+			name: "image name",
+			attributes: map[string]interface{}{
+				"k8s.container.image": "registry.example.com:5000/team/checkout:1.2.3@sha256:3c8a2c9b",
+				"k8s.pod.name":        "payments-5d8c7f9b6-x2x4z",
+			},
+			expected: "checkout",
+		},
+		{
+			name: "deployment pod name",
+			attributes: map[string]interface{}{
+				"k8s.pod.name": "payments-5d8c7f9b6-x2x4z",
+			},
+			expected: "payments",
+		},
+		{
+			name: "daemon set pod name",
+			attributes: map[string]interface{}{
+				"k8s.pod.name": "node-agent-x2x4z",
+			},
+			expected: "node-agent",
+		},
+		{
+			name: "stateful set pod name",
+			attributes: map[string]interface{}{
+				"k8s.pod.name": "kafka-broker-2",
+			},
+			expected: "kafka-broker",
+		},
+		{
+			name: "static pod name",
+			attributes: map[string]interface{}{
+				"k8s.pod.name": "kube-apiserver",
+			},
+			expected: "kube-apiserver",
+		},
+		{
+			name: "empty value is skipped",
+			attributes: map[string]interface{}{
+				"k8s.pod.label.app":   "",
+				"k8s.deployment.name": "checkout",
+			},
+			expected: "checkout",
+		},
+		{
+			name: "sdk default is replaced",
+			attributes: map[string]interface{}{
+				"service.name":        "unknown_service:java",
+				"k8s.deployment.name": "checkout",
+			},
+			expected: "checkout",
+		},
+		{
+			name: "sdk attribute is kept",
+			attributes: map[string]interface{}{
+				"service.name":        "cart",
+				"k8s.deployment.name": "checkout",
+			},
+			expected: "cart",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			attributes := processResourceAttributes(t, createDefaultConfig().(*Config), tc.attributes)
+			assert.Equal(t, tc.expected, attributes["service.name"])
+		})
+	}
+}
+
+func TestInferNothing(t *testing.T) {
+	attributes := processResourceAttributes(t, createDefaultConfig().(*Config), map[string]interface{}{
+		"host.name": "node-1",
+	})
+	assert.Equal(t, map[string]interface{}{"host.name": "node-1"}, attributes)
+}
+
+func TestInferDeploymentEnvironment(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.DeploymentEnvironment = append(cfg.DeploymentEnvironment, SourceConfig{Attribute: "k8s.namespace.name"})
+
+	attributes := processResourceAttributes(t, cfg, map[string]interface{}{
+		"k8s.pod.label.env":  "staging",
+		"k8s.namespace.name": "checkout",
+	})
+	assert.Equal(t, "staging", attributes["deployment.environment"])
+
+	attributes = processResourceAttributes(t, cfg, map[string]interface{}{
+		"k8s.namespace.name": "checkout",
+	})
+	assert.Equal(t, "checkout", attributes["deployment.environment"])
+
+	attributes = processResourceAttributes(t, cfg, map[string]interface{}{
+		"deployment.environment": "production",
+		"k8s.pod.label.env":      "staging",
+	})
+	assert.Equal(t, "production", attributes["deployment.environment"])
+}
+
+func TestProcessMetricsAndTraces(t *testing.T) {
+	p := newServiceInferenceProcessor(createDefaultConfig().(*Config))
+
+	metrics := pmetric.NewMetrics()
+	metrics.ResourceMetrics().AppendEmpty().Resource().Attributes().InsertString("k8s.deployment.name", "checkout")
+	metrics, err := p.processMetrics(context.Background(), metrics)
+	require.NoError(t, err)
+	serviceName, ok := metrics.ResourceMetrics().At(0).Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "checkout", serviceName.StringVal())
+
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty().Resource().Attributes().InsertString("k8s.deployment.name", "checkout")
+	traces, err = p.processTraces(context.Background(), traces)
+	require.NoError(t, err)
+	serviceName, ok = traces.ResourceSpans().At(0).Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "checkout", serviceName.StringVal())
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampnormalizerprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+
+	// TimestampAttribute is the name of the log record attribute holding the event time.
+	// When set and present on a record, its value replaces the record timestamp.
+	TimestampAttribute string `mapstructure:"timestamp_attribute"`
+	// TimestampLayout is the Go time layout used to parse string values of TimestampAttribute.
+	// Numeric values are always treated as epoch timestamps with the unit detected from their magnitude.
+	TimestampLayout string `mapstructure:"timestamp_layout"`
+	// SourceTimezone is the IANA timezone the sources write their wall clock time in.
+	// It applies to the string values of TimestampAttribute which carry no zone.
+	SourceTimezone string `mapstructure:"source_timezone"`
+	// MaxFutureSkew is the maximum allowed distance of a timestamp into the future.
+	// Timestamps further in the future are clamped to the observed time.
+	MaxFutureSkew time.Duration `mapstructure:"max_future_skew"`
+	// LateThreshold is the delay between the event time and the observed time
+	// after which a record is tagged as late. Zero disables tagging.
+	LateThreshold time.Duration `mapstructure:"late_threshold"`
+	// LateAttribute is the name of the attribute set to true on late records.
+	LateAttribute string `mapstructure:"late_attribute"`
+}
+
+const (
+	defaultMaxFutureSkew = 5 * time.Minute
+	defaultLateThreshold = 10 * time.Minute
+	defaultLateAttribute = "data.late"
+)
+
+// Ensure the Config struct satisfies the config.Processor interface.
+var _ config.Processor = (*Config)(nil)
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		MaxFutureSkew:     defaultMaxFutureSkew,
+		LateThreshold:     defaultLateThreshold,
+		LateAttribute:     defaultLateAttribute,
+	}
+}
+
+// Validate config
+func (cfg *Config) Validate() error {
+	if cfg.MaxFutureSkew < 0 {
+		return errors.New("max_future_skew cannot be negative")
+	}
+	if cfg.LateThreshold < 0 {
+		return errors.New("late_threshold cannot be negative")
+	}
+	if cfg.LateThreshold > 0 && cfg.LateAttribute == "" {
+		return errors.New("late_attribute cannot be empty when late_threshold is set")
+	}
+	if cfg.TimestampLayout != "" && cfg.TimestampAttribute == "" {
+		return errors.New("timestamp_layout requires timestamp_attribute to be set")
+	}
+	if cfg.SourceTimezone != "" {
+		if _, err := time.LoadLocation(cfg.SourceTimezone); err != nil {
+			return fmt.Errorf("invalid source_timezone %q: %w", cfg.SourceTimezone, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampnormalizerprocessor
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Upper bounds (exclusive) of epoch values for each unit. They allow dates up to
+// roughly year 5000 to be detected correctly for every unit.
+const (
+	maxEpochSeconds      = 1e11
+	maxEpochMilliseconds = 1e14
+	maxEpochMicroseconds = 1e17
+)
+
+type timestampNormalizerProcessor struct {
+	timestampAttribute string
+	timestampLayout    string
+	location           *time.Location
+	maxFutureSkew      time.Duration
+	lateThreshold      time.Duration
+	lateAttribute      string
+
+	now func() time.Time
+}
+
+func newTimestampNormalizerProcessor(cfg *Config) (*timestampNormalizerProcessor, error) {
+	var location *time.Location
+	if cfg.SourceTimezone != "" {
+		var err error
+		location, err = time.LoadLocation(cfg.SourceTimezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &timestampNormalizerProcessor{
+		timestampAttribute: cfg.TimestampAttribute,
+		timestampLayout:    cfg.TimestampLayout,
+		location:           location,
+		maxFutureSkew:      cfg.MaxFutureSkew,
+		lateThreshold:      cfg.LateThreshold,
+		lateAttribute:      cfg.LateAttribute,
+		now:                time.Now,
+	}, nil
+}
+
+func (p *timestampNormalizerProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	now := p.now()
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			logs := sls.At(j).LogRecords()
+			for k := 0; k < logs.Len(); k++ {
+				p.processLogRecord(logs.At(k), now)
+			}
+		}
+	}
+
+	return ld, nil
+}
+
+func (p *timestampNormalizerProcessor) processLogRecord(lr plog.LogRecord, now time.Time) {
+	observed := now
+	if lr.ObservedTimestamp() != 0 {
+		observed = lr.ObservedTimestamp().AsTime()
+	}
+
+	ts, ok := p.eventTime(lr)
+	if !ok {
+		return
+	}
+
+	if ts.After(observed.Add(p.maxFutureSkew)) {
+		ts = observed
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+
+	if p.lateThreshold > 0 && observed.Sub(ts) > p.lateThreshold {
+		lr.Attributes().UpsertBool(p.lateAttribute, true)
+	}
+}
+
+// eventTime returns the normalized event time of the log record.
+// The second return value is false if the record carries no usable timestamp.
+func (p *timestampNormalizerProcessor) eventTime(lr plog.LogRecord) (time.Time, bool) {
+	if p.timestampAttribute != "" {
+		if value, ok := lr.Attributes().Get(p.timestampAttribute); ok {
+			if ts, ok := p.parseValue(value); ok {
+				return ts, true
+			}
+		}
+	}
+
+	if lr.Timestamp() == 0 {
+		return time.Time{}, false
+	}
+	// The timestamp might have been set by a source using a coarser unit than nanoseconds.
+	return p.fromEpoch(int64(lr.Timestamp())), true
+}
+
+func (p *timestampNormalizerProcessor) parseValue(value pcommon.Value) (time.Time, bool) {
+	switch value.Type() {
+	case pcommon.ValueTypeInt:
+		return p.fromEpoch(value.IntVal()), true
+	case pcommon.ValueTypeDouble:
+		return p.fromFractionalEpoch(value.DoubleVal()), true
+	case pcommon.ValueTypeString:
+		return p.parseString(value.StringVal())
+	default:
+		return time.Time{}, false
+	}
+}
+
+func (p *timestampNormalizerProcessor) parseString(value string) (time.Time, bool) {
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return p.fromEpoch(epoch), true
+	}
+	if epoch, err := strconv.ParseFloat(value, 64); err == nil {
+		return p.fromFractionalEpoch(epoch), true
+	}
+
+	layout := p.timestampLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	// Only the wall clock of strings without a zone is read in the source timezone,
+	// epoch timestamps and strings with an offset are absolute already.
+	location := p.location
+	if location == nil {
+		location = time.UTC
+	}
+	ts, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// fromEpoch converts an epoch timestamp in an unknown unit into time,
+// detecting whether it is expressed in seconds, milliseconds, microseconds or nanoseconds.
+func (p *timestampNormalizerProcessor) fromEpoch(epoch int64) time.Time {
+	var ts time.Time
+	switch abs := math.Abs(float64(epoch)); {
+	case abs < maxEpochSeconds:
+		ts = time.Unix(epoch, 0)
+	case abs < maxEpochMilliseconds:
+		ts = time.UnixMilli(epoch)
+	case abs < maxEpochMicroseconds:
+		ts = time.UnixMicro(epoch)
+	default:
+		ts = time.Unix(0, epoch)
+	}
+	return ts
+}
+
+func (p *timestampNormalizerProcessor) fromFractionalEpoch(epoch float64) time.Time {
+	switch abs := math.Abs(epoch); {
+	case abs < maxEpochSeconds:
+		epoch *= float64(time.Second)
+	case abs < maxEpochMilliseconds:
+		epoch *= float64(time.Millisecond)
+	case abs < maxEpochMicroseconds:
+		epoch *= float64(time.Microsecond)
+	}
+	return time.Unix(0, int64(epoch))
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampnormalizerprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestProcessor(t *testing.T, modify func(*Config)) *timestampNormalizerProcessor {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	processor, err := newTimestampNormalizerProcessor(cfg)
+	require.NoError(t, err)
+	processor.now = func() time.Time { return testNow }
+	return processor
+}
+
+func processRecord(t *testing.T, processor *timestampNormalizerProcessor, fill func(plog.LogRecord)) plog.LogRecord {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	fill(lr)
+
+	result, err := processor.processLogs(context.Background(), logs)
+	require.NoError(t, err)
+	return result.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+}
+
+func TestEpochUnitDetection(t *testing.T) {
+	expected := time.Date(2022, 7, 1, 11, 59, 0, 0, time.UTC)
+
+	testcases := []struct {
+		name  string
+		value pcommon.Value
+	}{
+		{name: "seconds", value: pcommon.NewValueInt(expected.Unix())},
+		{name: "milliseconds", value: pcommon.NewValueInt(expected.UnixMilli())},
+		{name: "microseconds", value: pcommon.NewValueInt(expected.UnixMicro())},
+		{name: "nanoseconds", value: pcommon.NewValueInt(expected.UnixNano())},
+		{name: "fractional seconds", value: pcommon.NewValueDouble(float64(expected.Unix()))},
+		{name: "string seconds", value: pcommon.NewValueString("1656676740")},
+		{name: "rfc3339", value: pcommon.NewValueString("2022-07-01T11:59:00Z")},
+	}
+
+	processor := newTestProcessor(t, func(cfg *Config) {
+		cfg.TimestampAttribute = "ts"
+	})
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lr := processRecord(t, processor, func(lr plog.LogRecord) {
+				lr.Attributes().Insert("ts", tc.value)
+			})
+			assert.Equal(t, expected, lr.Timestamp().AsTime())
+		})
+	}
+}
+
+func TestMisScaledRecordTimestamp(t *testing.T) {
+	expected := time.Date(2022, 7, 1, 11, 59, 0, 0, time.UTC)
+	processor := newTestProcessor(t, nil)
+
+	lr := processRecord(t, processor, func(lr plog.LogRecord) {
+		lr.SetTimestamp(pcommon.Timestamp(expected.UnixMilli()))
+	})
+	assert.Equal(t, expected, lr.Timestamp().AsTime())
+}
+
+func TestSourceTimezone(t *testing.T) {
+	processor := newTestProcessor(t, func(cfg *Config) {
+		cfg.SourceTimezone = "Europe/Warsaw"
+		cfg.TimestampAttribute = "ts"
+		cfg.TimestampLayout = "2006-01-02 15:04:05"
+	})
+
+	t.Run("record timestamp is unchanged", func(t *testing.T) {
+		expected := time.Date(2022, 7, 1, 11, 30, 0, 0, time.UTC)
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(expected))
+		})
+		assert.Equal(t, expected, lr.Timestamp().AsTime())
+	})
+
+	t.Run("epoch attribute is unchanged", func(t *testing.T) {
+		expected := time.Date(2022, 7, 1, 11, 30, 0, 0, time.UTC)
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.Attributes().InsertInt("ts", expected.Unix())
+		})
+		assert.Equal(t, expected, lr.Timestamp().AsTime())
+	})
+
+	t.Run("layout", func(t *testing.T) {
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("ts", "2022-07-01 13:30:00")
+		})
+		assert.Equal(t, time.Date(2022, 7, 1, 11, 30, 0, 0, time.UTC), lr.Timestamp().AsTime())
+	})
+}
+
+func TestFutureTimestampIsClamped(t *testing.T) {
+	processor := newTestProcessor(t, nil)
+
+	t.Run("beyond skew", func(t *testing.T) {
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(testNow.Add(time.Hour)))
+		})
+		assert.Equal(t, testNow, lr.Timestamp().AsTime())
+	})
+
+	t.Run("within skew", func(t *testing.T) {
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(testNow.Add(time.Minute)))
+		})
+		assert.Equal(t, testNow.Add(time.Minute), lr.Timestamp().AsTime())
+	})
+
+	t.Run("observed timestamp takes precedence", func(t *testing.T) {
+		observed := testNow.Add(-time.Hour)
+		lr := processRecord(t, processor, func(lr plog.LogRecord) {
+			lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(observed))
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(testNow))
+		})
+		assert.Equal(t, observed, lr.Timestamp().AsTime())
+	})
+}
+
+func TestLateDataTagging(t *testing.T) {
+	processor := newTestProcessor(t, nil)
+
+	testcases := []struct {
+		name      string
+		timestamp time.Time
+		late      bool
+	}{
+		{name: "on time", timestamp: testNow.Add(-time.Minute), late: false},
+		{name: "late", timestamp: testNow.Add(-time.Hour), late: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lr := processRecord(t, processor, func(lr plog.LogRecord) {
+				lr.SetTimestamp(pcommon.NewTimestampFromTime(tc.timestamp))
+			})
+			value, ok := lr.Attributes().Get("data.late")
+			require.Equal(t, tc.late, ok)
+			if tc.late {
+				assert.True(t, value.BoolVal())
+			}
+		})
+	}
+}
+
+func TestRecordWithoutTimestampIsUntouched(t *testing.T) {
+	processor := newTestProcessor(t, nil)
+
+	lr := processRecord(t, processor, func(lr plog.LogRecord) {
+		lr.Body().SetStringVal("no timestamp")
+	})
+	assert.Equal(t, pcommon.Timestamp(0), lr.Timestamp())
+	assert.Equal(t, 0, lr.Attributes().Len())
+}
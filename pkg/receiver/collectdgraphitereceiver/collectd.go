@@ -0,0 +1,212 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Part types of the collectd binary network protocol.
+// For more info: https://collectd.org/wiki/index.php/Binary_protocol
+const (
+	collectdPartHost           uint16 = 0x0000
+	collectdPartTime           uint16 = 0x0001
+	collectdPartPlugin         uint16 = 0x0002
+	collectdPartPluginInstance uint16 = 0x0003
+	collectdPartType           uint16 = 0x0004
+	collectdPartTypeInstance   uint16 = 0x0005
+	collectdPartValues         uint16 = 0x0006
+	collectdPartInterval       uint16 = 0x0007
+	collectdPartTimeHR         uint16 = 0x0008
+	collectdPartIntervalHR     uint16 = 0x0009
+)
+
+// Data source types of values in a values part.
+const (
+	collectdTypeCounter  byte = 0
+	collectdTypeGauge    byte = 1
+	collectdTypeDerive   byte = 2
+	collectdTypeAbsolute byte = 3
+)
+
+const collectdPartHeaderLength = 4
+
+// collectdState holds the identifier parts which apply to all following values parts in a packet.
+type collectdState struct {
+	host           string
+	plugin         string
+	pluginInstance string
+	typ            string
+	typeInstance   string
+	time           time.Time
+}
+
+// parseCollectdPacket decodes a collectd binary protocol packet
+// and appends a metric for every value found to the metric slice.
+func parseCollectdPacket(packet []byte, now time.Time, metrics pmetric.MetricSlice) error {
+	state := collectdState{time: now}
+
+	for len(packet) > 0 {
+		if len(packet) < collectdPartHeaderLength {
+			return errors.New("truncated collectd part header")
+		}
+		partType := binary.BigEndian.Uint16(packet[0:2])
+		partLength := int(binary.BigEndian.Uint16(packet[2:4]))
+		if partLength < collectdPartHeaderLength || partLength > len(packet) {
+			return fmt.Errorf("invalid collectd part length: %d", partLength)
+		}
+		payload := packet[collectdPartHeaderLength:partLength]
+		packet = packet[partLength:]
+
+		var err error
+		switch partType {
+		case collectdPartHost:
+			state.host, err = parseCollectdString(payload)
+		case collectdPartPlugin:
+			state.plugin, err = parseCollectdString(payload)
+		case collectdPartPluginInstance:
+			state.pluginInstance, err = parseCollectdString(payload)
+		case collectdPartType:
+			state.typ, err = parseCollectdString(payload)
+		case collectdPartTypeInstance:
+			state.typeInstance, err = parseCollectdString(payload)
+		case collectdPartTime:
+			var seconds uint64
+			seconds, err = parseCollectdNumber(payload)
+			state.time = time.Unix(int64(seconds), 0)
+		case collectdPartTimeHR:
+			var hr uint64
+			hr, err = parseCollectdNumber(payload)
+			state.time = collectdHRToTime(hr)
+		case collectdPartValues:
+			err = appendCollectdValues(payload, state, metrics)
+		case collectdPartInterval, collectdPartIntervalHR:
+			// The interval is not needed to build the data points.
+		default:
+			// Unknown parts (e.g. notifications or signatures) are skipped.
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseCollectdString(payload []byte) (string, error) {
+	if len(payload) == 0 || payload[len(payload)-1] != 0 {
+		return "", errors.New("collectd string part is not null terminated")
+	}
+	return string(payload[:len(payload)-1]), nil
+}
+
+func parseCollectdNumber(payload []byte) (uint64, error) {
+	if len(payload) != 8 {
+		return 0, fmt.Errorf("invalid collectd numeric part length: %d", len(payload))
+	}
+	return binary.BigEndian.Uint64(payload), nil
+}
+
+// collectdHRToTime converts high resolution time, expressed in 2^-30 seconds, into time.
+func collectdHRToTime(hr uint64) time.Time {
+	seconds := hr >> 30
+	nanos := ((hr & (1<<30 - 1)) * uint64(time.Second)) >> 30
+	return time.Unix(int64(seconds), int64(nanos))
+}
+
+func appendCollectdValues(payload []byte, state collectdState, metrics pmetric.MetricSlice) error {
+	if len(payload) < 2 {
+		return errors.New("truncated collectd values part")
+	}
+	count := int(binary.BigEndian.Uint16(payload[0:2]))
+	if len(payload) != 2+count*9 {
+		return fmt.Errorf("invalid collectd values part length for %d values", count)
+	}
+	types := payload[2 : 2+count]
+	values := payload[2+count:]
+	for _, typ := range types {
+		if typ > collectdTypeAbsolute {
+			return fmt.Errorf("unknown collectd data source type: %d", typ)
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		raw := values[i*8 : (i+1)*8]
+
+		metric := metrics.AppendEmpty()
+		metric.SetName(state.metricName())
+
+		var dp pmetric.NumberDataPoint
+		switch types[i] {
+		case collectdTypeGauge:
+			// gauge values are the only ones encoded in little endian
+			metric.SetDataType(pmetric.MetricDataTypeGauge)
+			dp = metric.Gauge().DataPoints().AppendEmpty()
+			dp.SetDoubleVal(math.Float64frombits(binary.LittleEndian.Uint64(raw)))
+		case collectdTypeCounter, collectdTypeDerive:
+			metric.SetDataType(pmetric.MetricDataTypeSum)
+			metric.Sum().SetIsMonotonic(types[i] == collectdTypeCounter)
+			metric.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+			dp = metric.Sum().DataPoints().AppendEmpty()
+			dp.SetIntVal(int64(binary.BigEndian.Uint64(raw)))
+		case collectdTypeAbsolute:
+			metric.SetDataType(pmetric.MetricDataTypeSum)
+			metric.Sum().SetIsMonotonic(true)
+			metric.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+			dp = metric.Sum().DataPoints().AppendEmpty()
+			dp.SetIntVal(int64(binary.BigEndian.Uint64(raw)))
+		}
+
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(state.time))
+		state.fillAttributes(dp.Attributes())
+		if count > 1 {
+			dp.Attributes().UpsertString("ds_index", strconv.Itoa(i))
+		}
+	}
+
+	return nil
+}
+
+// metricName builds the metric name as `plugin.type`, instances are kept as attributes.
+func (s collectdState) metricName() string {
+	parts := []string{}
+	for _, part := range []string{s.plugin, s.typ} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (s collectdState) fillAttributes(attributes pcommon.Map) {
+	for key, value := range map[string]string{
+		"host":            s.host,
+		"plugin_instance": s.pluginInstance,
+		"type_instance":   s.typeInstance,
+	} {
+		if value != "" {
+			attributes.UpsertString(key, value)
+		}
+	}
+}
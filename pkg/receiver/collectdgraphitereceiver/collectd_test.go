@@ -0,0 +1,126 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func collectdStringPart(partType uint16, value string) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, partType)
+	binary.Write(buf, binary.BigEndian, uint16(collectdPartHeaderLength+len(value)+1))
+	buf.WriteString(value)
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+func collectdNumberPart(partType uint16, value uint64) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, partType)
+	binary.Write(buf, binary.BigEndian, uint16(collectdPartHeaderLength+8))
+	binary.Write(buf, binary.BigEndian, value)
+	return buf.Bytes()
+}
+
+func collectdValuesPart(types []byte, values []uint64) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, collectdPartValues)
+	binary.Write(buf, binary.BigEndian, uint16(collectdPartHeaderLength+2+len(types)*9))
+	binary.Write(buf, binary.BigEndian, uint16(len(types)))
+	buf.Write(types)
+	for i, value := range values {
+		if types[i] == collectdTypeGauge {
+			binary.Write(buf, binary.LittleEndian, value)
+		} else {
+			binary.Write(buf, binary.BigEndian, value)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParseCollectdPacket(t *testing.T) {
+	packet := bytes.Join([][]byte{
+		collectdStringPart(collectdPartHost, "web01"),
+		collectdNumberPart(collectdPartTimeHR, uint64(1656676800)<<30|1<<29),
+		collectdStringPart(collectdPartPlugin, "cpu"),
+		collectdStringPart(collectdPartPluginInstance, "0"),
+		collectdStringPart(collectdPartType, "percent"),
+		collectdStringPart(collectdPartTypeInstance, "user"),
+		collectdValuesPart([]byte{collectdTypeGauge}, []uint64{math.Float64bits(12.5)}),
+		collectdStringPart(collectdPartPlugin, "interface"),
+		collectdStringPart(collectdPartPluginInstance, "eth0"),
+		collectdStringPart(collectdPartType, "if_octets"),
+		collectdStringPart(collectdPartTypeInstance, ""),
+		collectdValuesPart([]byte{collectdTypeDerive, collectdTypeDerive}, []uint64{100, 200}),
+	}, nil)
+
+	metrics := pmetric.NewMetricSlice()
+	require.NoError(t, parseCollectdPacket(packet, time.Now(), metrics))
+	require.Equal(t, 3, metrics.Len())
+
+	cpu := metrics.At(0)
+	assert.Equal(t, "cpu.percent", cpu.Name())
+	require.Equal(t, pmetric.MetricDataTypeGauge, cpu.DataType())
+	dp := cpu.Gauge().DataPoints().At(0)
+	assert.Equal(t, 12.5, dp.DoubleVal())
+	assert.Equal(t, time.Unix(1656676800, int64(time.Second/2)).UTC(), dp.Timestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{
+		"host":            "web01",
+		"plugin_instance": "0",
+		"type_instance":   "user",
+	}, dp.Attributes().AsRaw())
+
+	for i, expected := range []int64{100, 200} {
+		octets := metrics.At(i + 1)
+		assert.Equal(t, "interface.if_octets", octets.Name())
+		require.Equal(t, pmetric.MetricDataTypeSum, octets.DataType())
+		assert.False(t, octets.Sum().IsMonotonic())
+		dp := octets.Sum().DataPoints().At(0)
+		assert.Equal(t, expected, dp.IntVal())
+		dsIndex, ok := dp.Attributes().Get("ds_index")
+		require.True(t, ok)
+		assert.Equal(t, []string{"0", "1"}[i], dsIndex.StringVal())
+	}
+}
+
+func TestParseInvalidCollectdPacket(t *testing.T) {
+	testcases := []struct {
+		name   string
+		packet []byte
+	}{
+		{name: "truncated header", packet: []byte{0, 0, 0}},
+		{name: "length exceeds packet", packet: []byte{0, 0, 0, 10, 'a'}},
+		{name: "string without terminator", packet: []byte{0, 0, 0, 5, 'a'}},
+		{name: "invalid number length", packet: []byte{0, 1, 0, 5, 1}},
+		{name: "unknown data source type", packet: collectdValuesPart([]byte{7}, []uint64{1})},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := pmetric.NewMetricSlice()
+			assert.Error(t, parseCollectdPacket(tc.packet, time.Now(), metrics))
+			assert.Equal(t, 0, metrics.Len())
+		})
+	}
+}
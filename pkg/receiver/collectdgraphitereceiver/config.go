@@ -0,0 +1,85 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confignet"
+)
+
+const (
+	defaultGraphiteEndpoint  = "0.0.0.0:2003"
+	defaultGraphiteTransport = "tcp"
+	defaultCollectdEndpoint  = "0.0.0.0:25826"
+)
+
+// Config defines configuration for the receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+
+	// Graphite configures the listener for the graphite plaintext protocol.
+	// The listener is disabled when the section is not present.
+	Graphite *GraphiteConfig `mapstructure:"graphite"`
+
+	// Collectd configures the listener for the collectd binary network protocol.
+	// The listener is disabled when the section is not present.
+	Collectd *CollectdConfig `mapstructure:"collectd"`
+}
+
+// GraphiteConfig defines configuration for the graphite plaintext listener.
+type GraphiteConfig struct {
+	// Endpoint and transport (tcp or udp) to listen on.
+	confignet.NetAddr `mapstructure:",squash"`
+
+	// Templates map dotted metric paths to metric names and attributes.
+	// The first template with a matching filter is used.
+	Templates []string `mapstructure:"templates"`
+}
+
+// CollectdConfig defines configuration for the collectd binary protocol listener.
+type CollectdConfig struct {
+	// Endpoint is the UDP address to listen on.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ReceiverSettings.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.Graphite == nil && cfg.Collectd == nil {
+		return errors.New("at least one of graphite or collectd listeners has to be configured")
+	}
+
+	if cfg.Graphite != nil {
+		switch cfg.Graphite.Transport {
+		case "", "tcp", "udp":
+		default:
+			return fmt.Errorf("unsupported graphite transport: %q, supported values are 'tcp' and 'udp'", cfg.Graphite.Transport)
+		}
+
+		for _, template := range cfg.Graphite.Templates {
+			if _, err := parseTemplate(template); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
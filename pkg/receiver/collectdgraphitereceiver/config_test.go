@@ -0,0 +1,84 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	r0 := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, r0,
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+			Graphite: &GraphiteConfig{
+				NetAddr: confignet.NetAddr{Endpoint: "0.0.0.0:2003"},
+			},
+		})
+
+	r1 := cfg.Receivers[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "custom")),
+			Graphite: &GraphiteConfig{
+				NetAddr:   confignet.NetAddr{Endpoint: "localhost:2004", Transport: "udp"},
+				Templates: []string{"servers.* -.host.name*", "region.host.name"},
+			},
+			Collectd: &CollectdConfig{
+				Endpoint: "localhost:25827",
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no listeners", modify: func(cfg *Config) {}},
+		{name: "unsupported transport", modify: func(cfg *Config) {
+			cfg.Graphite = &GraphiteConfig{NetAddr: confignet.NetAddr{Transport: "unix"}}
+		}},
+		{name: "invalid template", modify: func(cfg *Config) {
+			cfg.Graphite = &GraphiteConfig{Templates: []string{"name*.host"}}
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
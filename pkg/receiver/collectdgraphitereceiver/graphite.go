@@ -0,0 +1,192 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// templateName marks a path segment which becomes part of the metric name.
+	templateName = "name"
+	// templateNameRest marks that this and all following segments become part of the metric name.
+	templateNameRest = "name*"
+	// templateSkip marks a path segment which is dropped.
+	templateSkip = "-"
+)
+
+// graphiteTemplate describes how to translate a dotted graphite path into a metric name and attributes.
+//
+// A template is written as `[filter] pattern`, e.g. `servers.* -.host.name*`.
+// The filter is a dotted glob where `*` matches a single segment.
+// Each segment of the pattern is `name` (part of the metric name), `name*` (this and the remaining segments
+// are part of the metric name), `-` (segment is dropped) or any other word, which becomes the attribute key
+// for the segment's value.
+type graphiteTemplate struct {
+	filter  []string
+	pattern []string
+}
+
+func parseTemplate(template string) (graphiteTemplate, error) {
+	fields := strings.Fields(template)
+	var t graphiteTemplate
+	switch len(fields) {
+	case 1:
+		t.pattern = strings.Split(fields[0], ".")
+	case 2:
+		t.filter = strings.Split(fields[0], ".")
+		t.pattern = strings.Split(fields[1], ".")
+	default:
+		return t, fmt.Errorf("invalid graphite template %q, expected '[filter] pattern'", template)
+	}
+
+	for i, part := range t.pattern {
+		if part == "" {
+			return t, fmt.Errorf("invalid graphite template %q, empty segment", template)
+		}
+		if part == templateNameRest && i != len(t.pattern)-1 {
+			return t, fmt.Errorf("invalid graphite template %q, '%s' has to be the last segment", template, templateNameRest)
+		}
+	}
+	return t, nil
+}
+
+func (t graphiteTemplate) matches(segments []string) bool {
+	if len(t.filter) > len(segments) {
+		return false
+	}
+	for i, f := range t.filter {
+		if f != "*" && f != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t graphiteTemplate) apply(segments []string) (string, map[string]string) {
+	var name []string
+	attributes := map[string]string{}
+
+	for i, segment := range segments {
+		if i >= len(t.pattern) {
+			// Segments not covered by the pattern are kept in the name
+			// unless the pattern ended with `name*`, which already consumed them.
+			name = append(name, segment)
+			continue
+		}
+
+		switch part := t.pattern[i]; part {
+		case templateName:
+			name = append(name, segment)
+		case templateNameRest:
+			return strings.Join(append(name, segments[i:]...), "."), attributes
+		case templateSkip:
+		default:
+			if existing, ok := attributes[part]; ok {
+				attributes[part] = existing + "." + segment
+			} else {
+				attributes[part] = segment
+			}
+		}
+	}
+
+	return strings.Join(name, "."), attributes
+}
+
+type graphiteParser struct {
+	templates []graphiteTemplate
+}
+
+func newGraphiteParser(templates []string) (*graphiteParser, error) {
+	parser := &graphiteParser{}
+	for _, template := range templates {
+		t, err := parseTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+		parser.templates = append(parser.templates, t)
+	}
+	return parser, nil
+}
+
+// parseLine parses a single graphite plaintext line: `path[;tag=value...] value [timestamp]`
+// and appends the resulting metric to the metric slice.
+func (p *graphiteParser) parseLine(line string, now time.Time, metrics pmetric.MetricSlice) error {
+	fields := strings.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return fmt.Errorf("invalid graphite line %q, expected 'path value [timestamp]'", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid value in graphite line %q: %w", line, err)
+	}
+
+	timestamp := now
+	if len(fields) == 3 && fields[2] != "-1" {
+		epoch, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp in graphite line %q: %w", line, err)
+		}
+		timestamp = time.Unix(0, int64(epoch*float64(time.Second)))
+	}
+
+	// Graphite 1.1 tags: path;tag1=value1;tag2=value2
+	pathAndTags := strings.Split(fields[0], ";")
+	path := pathAndTags[0]
+	if path == "" {
+		return fmt.Errorf("empty metric path in graphite line %q", line)
+	}
+
+	name, attributes := p.translatePath(path)
+	for _, tag := range pathAndTags[1:] {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("invalid tag %q in graphite line %q", tag, line)
+		}
+		attributes[kv[0]] = kv[1]
+	}
+
+	metric := metrics.AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	dp := metric.Gauge().DataPoints().AppendEmpty()
+	dp.SetDoubleVal(value)
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	for k, v := range attributes {
+		dp.Attributes().UpsertString(k, v)
+	}
+	return nil
+}
+
+func (p *graphiteParser) translatePath(path string) (string, map[string]string) {
+	segments := strings.Split(path, ".")
+	for _, t := range p.templates {
+		if t.matches(segments) {
+			name, attributes := t.apply(segments)
+			if name == "" {
+				name = path
+			}
+			return name, attributes
+		}
+	}
+	return path, map[string]string{}
+}
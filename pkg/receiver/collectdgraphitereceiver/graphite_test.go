@@ -0,0 +1,148 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestGraphiteTemplates(t *testing.T) {
+	testcases := []struct {
+		name               string
+		templates          []string
+		path               string
+		expectedName       string
+		expectedAttributes map[string]string
+	}{
+		{
+			name:               "no templates",
+			path:               "servers.web01.cpu.load",
+			expectedName:       "servers.web01.cpu.load",
+			expectedAttributes: map[string]string{},
+		},
+		{
+			name:               "filter and name rest",
+			templates:          []string{"servers.* -.host.name*"},
+			path:               "servers.web01.cpu.load",
+			expectedName:       "cpu.load",
+			expectedAttributes: map[string]string{"host": "web01"},
+		},
+		{
+			name:               "first matching template wins",
+			templates:          []string{"apps.* -.app.name", "servers.* -.host.name.name"},
+			path:               "servers.web01.cpu.load",
+			expectedName:       "cpu.load",
+			expectedAttributes: map[string]string{"host": "web01"},
+		},
+		{
+			name:               "template without filter",
+			templates:          []string{"region.host.name"},
+			path:               "us-east.web01.requests",
+			expectedName:       "requests",
+			expectedAttributes: map[string]string{"region": "us-east", "host": "web01"},
+		},
+		{
+			name:               "repeated attribute is joined",
+			templates:          []string{"host.host.name"},
+			path:               "web01.example.requests",
+			expectedName:       "requests",
+			expectedAttributes: map[string]string{"host": "web01.example"},
+		},
+		{
+			name:               "uncovered segments are kept in name",
+			templates:          []string{"host.name"},
+			path:               "web01.cpu.user",
+			expectedName:       "cpu.user",
+			expectedAttributes: map[string]string{"host": "web01"},
+		},
+		{
+			name:               "non matching filter",
+			templates:          []string{"apps.* -.app.name*"},
+			path:               "servers.web01.cpu.load",
+			expectedName:       "servers.web01.cpu.load",
+			expectedAttributes: map[string]string{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newGraphiteParser(tc.templates)
+			require.NoError(t, err)
+
+			name, attributes := parser.translatePath(tc.path)
+			assert.Equal(t, tc.expectedName, name)
+			assert.Equal(t, tc.expectedAttributes, attributes)
+		})
+	}
+}
+
+func TestInvalidGraphiteTemplates(t *testing.T) {
+	for _, template := range []string{
+		"",
+		"a b c",
+		"host..name",
+		"name*.host",
+	} {
+		_, err := parseTemplate(template)
+		assert.Error(t, err, template)
+	}
+}
+
+func TestGraphiteParseLine(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	parser, err := newGraphiteParser([]string{"servers.* -.host.name*"})
+	require.NoError(t, err)
+
+	metrics := pmetric.NewMetricSlice()
+	require.NoError(t, parser.parseLine("servers.web01.cpu.load;dc=east 0.75 1656676800", now, metrics))
+	require.NoError(t, parser.parseLine("requests 10", now, metrics))
+	require.NoError(t, parser.parseLine("requests 11 -1", now, metrics))
+	require.Equal(t, 3, metrics.Len())
+
+	m := metrics.At(0)
+	assert.Equal(t, "cpu.load", m.Name())
+	require.Equal(t, pmetric.MetricDataTypeGauge, m.DataType())
+	dp := m.Gauge().DataPoints().At(0)
+	assert.Equal(t, 0.75, dp.DoubleVal())
+	assert.Equal(t, time.Unix(1656676800, 0).UTC(), dp.Timestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{"host": "web01", "dc": "east"}, dp.Attributes().AsRaw())
+
+	for i := 1; i < 3; i++ {
+		assert.Equal(t, now, metrics.At(i).Gauge().DataPoints().At(0).Timestamp().AsTime())
+	}
+}
+
+func TestGraphiteParseInvalidLine(t *testing.T) {
+	parser, err := newGraphiteParser(nil)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"requests",
+		"requests abc",
+		"requests 1 abc",
+		"requests 1 2 3",
+		";tag=value 1",
+		"requests;tag 1",
+	} {
+		metrics := pmetric.NewMetricSlice()
+		assert.Error(t, parser.parseLine(line, time.Now(), metrics), line)
+		assert.Equal(t, 0, metrics.Len(), line)
+	}
+}
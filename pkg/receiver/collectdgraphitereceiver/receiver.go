@@ -0,0 +1,280 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxDatagramSize is the maximum size of a UDP datagram.
+	maxDatagramSize = 65535
+	// maxLinesPerBatch limits the number of graphite lines sent to the pipeline at once from a TCP connection.
+	maxLinesPerBatch = 1000
+)
+
+type collectdGraphiteReceiver struct {
+	cfg            *Config
+	graphiteParser *graphiteParser
+
+	listeners   []net.Listener
+	packetConns []net.PacketConn
+	connsMutex  sync.Mutex
+	conns       map[net.Conn]struct{}
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	consumer consumer.Metrics
+	logger   *zap.Logger
+
+	now func() time.Time
+}
+
+func newCollectdGraphiteReceiver(
+	params component.ReceiverCreateSettings,
+	cfg *Config,
+	consumer consumer.Metrics,
+) (*collectdGraphiteReceiver, error) {
+	receiver := &collectdGraphiteReceiver{
+		cfg:      cfg,
+		conns:    map[net.Conn]struct{}{},
+		consumer: consumer,
+		logger:   params.Logger,
+		now:      time.Now,
+	}
+
+	if cfg.Graphite != nil {
+		parser, err := newGraphiteParser(cfg.Graphite.Templates)
+		if err != nil {
+			return nil, err
+		}
+		receiver.graphiteParser = parser
+	}
+
+	return receiver, nil
+}
+
+// Start tells the receiver to start.
+func (r *collectdGraphiteReceiver) Start(ctx context.Context, host component.Host) error {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	if r.cfg.Graphite != nil {
+		endpoint := r.cfg.Graphite.Endpoint
+		if endpoint == "" {
+			endpoint = defaultGraphiteEndpoint
+		}
+		transport := r.cfg.Graphite.Transport
+		if transport == "" {
+			transport = defaultGraphiteTransport
+		}
+
+		if transport == "udp" {
+			conn, err := net.ListenPacket("udp", endpoint)
+			if err != nil {
+				return fmt.Errorf("failed to start graphite udp listener on %s: %w", endpoint, err)
+			}
+			r.packetConns = append(r.packetConns, conn)
+			r.wg.Add(1)
+			go r.readPackets(conn, r.handleGraphiteDatagram)
+		} else {
+			listener, err := net.Listen("tcp", endpoint)
+			if err != nil {
+				return fmt.Errorf("failed to start graphite tcp listener on %s: %w", endpoint, err)
+			}
+			r.listeners = append(r.listeners, listener)
+			r.wg.Add(1)
+			go r.acceptGraphiteConnections(listener)
+		}
+		r.logger.Info("Started graphite listener", zap.String("endpoint", endpoint), zap.String("transport", transport))
+	}
+
+	if r.cfg.Collectd != nil {
+		endpoint := r.cfg.Collectd.Endpoint
+		if endpoint == "" {
+			endpoint = defaultCollectdEndpoint
+		}
+
+		conn, err := net.ListenPacket("udp", endpoint)
+		if err != nil {
+			r.closeListeners()
+			return fmt.Errorf("failed to start collectd listener on %s: %w", endpoint, err)
+		}
+		r.packetConns = append(r.packetConns, conn)
+		r.wg.Add(1)
+		go r.readPackets(conn, r.handleCollectdPacket)
+		r.logger.Info("Started collectd listener", zap.String("endpoint", endpoint))
+	}
+
+	return nil
+}
+
+// Shutdown is invoked during service shutdown.
+func (r *collectdGraphiteReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.closeListeners()
+
+	r.connsMutex.Lock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.connsMutex.Unlock()
+
+	r.wg.Wait()
+	return nil
+}
+
+func (r *collectdGraphiteReceiver) closeListeners() {
+	for _, listener := range r.listeners {
+		listener.Close()
+	}
+	for _, conn := range r.packetConns {
+		conn.Close()
+	}
+}
+
+func (r *collectdGraphiteReceiver) acceptGraphiteConnections(listener net.Listener) {
+	defer r.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.logger.Warn("Failed to accept graphite connection", zap.Error(err))
+			continue
+		}
+
+		r.connsMutex.Lock()
+		r.conns[conn] = struct{}{}
+		r.connsMutex.Unlock()
+
+		r.wg.Add(1)
+		go r.handleGraphiteConnection(conn)
+	}
+}
+
+// handleGraphiteConnection reads lines from the connection and sends them to the pipeline in batches.
+// A batch is flushed when there's no more buffered data to read or when it reaches maxLinesPerBatch lines.
+func (r *collectdGraphiteReceiver) handleGraphiteConnection(conn net.Conn) {
+	defer r.wg.Done()
+	defer func() {
+		r.connsMutex.Lock()
+		delete(r.conns, conn)
+		r.connsMutex.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	metrics := pmetric.NewMetrics()
+	metricSlice := newMetricSlice(metrics)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			if parseErr := r.graphiteParser.parseLine(line, r.now(), metricSlice); parseErr != nil {
+				r.logger.Debug("Failed to parse graphite line", zap.Error(parseErr))
+			}
+		}
+
+		if metricSlice.Len() > 0 && (err != nil || reader.Buffered() == 0 || metricSlice.Len() >= maxLinesPerBatch) {
+			r.consume(metrics)
+			metrics = pmetric.NewMetrics()
+			metricSlice = newMetricSlice(metrics)
+		}
+
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				r.logger.Debug("Graphite connection closed", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+func (r *collectdGraphiteReceiver) readPackets(conn net.PacketConn, handle func([]byte)) {
+	defer r.wg.Done()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.logger.Warn("Failed to read datagram", zap.Error(err))
+			continue
+		}
+		handle(buf[:n])
+	}
+}
+
+func (r *collectdGraphiteReceiver) handleGraphiteDatagram(datagram []byte) {
+	metrics := pmetric.NewMetrics()
+	metricSlice := newMetricSlice(metrics)
+	now := r.now()
+
+	for _, line := range strings.Split(string(datagram), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if err := r.graphiteParser.parseLine(line, now, metricSlice); err != nil {
+			r.logger.Debug("Failed to parse graphite line", zap.Error(err))
+		}
+	}
+
+	if metricSlice.Len() > 0 {
+		r.consume(metrics)
+	}
+}
+
+func (r *collectdGraphiteReceiver) handleCollectdPacket(packet []byte) {
+	metrics := pmetric.NewMetrics()
+	metricSlice := newMetricSlice(metrics)
+
+	if err := parseCollectdPacket(packet, r.now(), metricSlice); err != nil {
+		r.logger.Debug("Failed to parse collectd packet", zap.Error(err))
+	}
+
+	if metricSlice.Len() > 0 {
+		r.consume(metrics)
+	}
+}
+
+func (r *collectdGraphiteReceiver) consume(metrics pmetric.Metrics) {
+	if err := r.consumer.ConsumeMetrics(r.ctx, metrics); err != nil {
+		r.logger.Error("ConsumeMetrics() error", zap.Error(err))
+	}
+}
+
+func newMetricSlice(metrics pmetric.Metrics) pmetric.MetricSlice {
+	return metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+}
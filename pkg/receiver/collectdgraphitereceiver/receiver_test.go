@@ -0,0 +1,123 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectdgraphitereceiver
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func startReceiver(t *testing.T, cfg *Config) (*collectdGraphiteReceiver, *consumertest.MetricsSink) {
+	sink := new(consumertest.MetricsSink)
+	receiver, err := newCollectdGraphiteReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, receiver.Shutdown(context.Background()))
+	})
+	return receiver, sink
+}
+
+func metricNames(sink *consumertest.MetricsSink) []string {
+	names := []string{}
+	for _, metrics := range sink.AllMetrics() {
+		rms := metrics.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			sms := rms.At(i).ScopeMetrics()
+			for j := 0; j < sms.Len(); j++ {
+				ms := sms.At(j).Metrics()
+				for k := 0; k < ms.Len(); k++ {
+					names = append(names, ms.At(k).Name())
+				}
+			}
+		}
+	}
+	return names
+}
+
+func TestGraphiteTCP(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Graphite = &GraphiteConfig{
+		NetAddr:   confignet.NetAddr{Endpoint: "127.0.0.1:0", Transport: "tcp"},
+		Templates: []string{"servers.* -.host.name*"},
+	}
+	receiver, sink := startReceiver(t, cfg)
+
+	conn, err := net.Dial("tcp", receiver.listeners[0].Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("servers.web01.cpu.load 0.5 1656676800\ninvalid\nrequests 3\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"cpu.load", "requests"}, metricNames(sink))
+}
+
+func TestGraphiteUDP(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Graphite = &GraphiteConfig{
+		NetAddr: confignet.NetAddr{Endpoint: "127.0.0.1:0", Transport: "udp"},
+	}
+	receiver, sink := startReceiver(t, cfg)
+
+	conn, err := net.Dial("udp", receiver.packetConns[0].LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("requests 3\nerrors 1\n"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"requests", "errors"}, metricNames(sink))
+}
+
+func TestCollectdUDP(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Collectd = &CollectdConfig{Endpoint: "127.0.0.1:0"}
+	receiver, sink := startReceiver(t, cfg)
+
+	conn, err := net.Dial("udp", receiver.packetConns[0].LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(bytes.Join([][]byte{
+		collectdStringPart(collectdPartHost, "web01"),
+		collectdStringPart(collectdPartPlugin, "load"),
+		collectdStringPart(collectdPartType, "load"),
+		collectdValuesPart([]byte{collectdTypeGauge}, []uint64{math.Float64bits(0.25)}),
+	}, nil))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	metric := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "load.load", metric.Name())
+	require.Equal(t, pmetric.MetricDataTypeGauge, metric.DataType())
+	assert.Equal(t, 0.25, metric.Gauge().DataPoints().At(0).DoubleVal())
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	defaultEndpoint    = "0.0.0.0:2055"
+	defaultTemplateTTL = 30 * time.Minute
+)
+
+// Config defines configuration for the receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+
+	// Endpoint is the UDP address to listen on.
+	// The protocol of every datagram (NetFlow v5, NetFlow v9, IPFIX or sFlow v5) is detected automatically.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// NormalizeSampling multiplies the byte and packet counts of sampled flows by their sampling rate,
+	// so that they reflect the estimated real traffic.
+	NormalizeSampling bool `mapstructure:"normalize_sampling"`
+
+	// TemplateTTL is the time after which NetFlow v9 and IPFIX templates which weren't refreshed by the exporter
+	// are removed from the cache. Zero means templates never expire.
+	TemplateTTL time.Duration `mapstructure:"template_ttl"`
+}
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ReceiverSettings.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint cannot be empty")
+	}
+	if cfg.TemplateTTL < 0 {
+		return errors.New("template_ttl cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	attributeExporterAddress    = "flow.exporter.address"
+	attributeFlowType           = "flow.type"
+	attributeSourceAddress      = "source.address"
+	attributeSourcePort         = "source.port"
+	attributeDestinationAddress = "destination.address"
+	attributeDestinationPort    = "destination.port"
+	attributeNetworkTransport   = "network.transport"
+	attributeProtocol           = "flow.protocol"
+	attributeBytes              = "flow.bytes"
+	attributePackets            = "flow.packets"
+	attributeSamplingRate       = "flow.sampling_rate"
+	attributeTCPFlags           = "flow.tcp_flags"
+	attributeTOS                = "flow.tos"
+	attributeInputInterface     = "flow.interface.input"
+	attributeOutputInterface    = "flow.interface.output"
+	attributeNextHop            = "flow.next_hop"
+	attributeSourceAS           = "flow.as.source"
+	attributeDestinationAS      = "flow.as.destination"
+	attributeStartTime          = "flow.start_time"
+
+	metricBytes   = "flow.bytes"
+	metricPackets = "flow.packets"
+)
+
+// transportNames maps IP protocol numbers to the names used in the network.transport attribute.
+var transportNames = map[uint8]string{
+	1:   "icmp",
+	6:   "tcp",
+	17:  "udp",
+	47:  "gre",
+	50:  "esp",
+	58:  "icmpv6",
+	132: "sctp",
+}
+
+func setResourceAttributes(packet flowPacket, resource pcommon.Resource) {
+	attrs := resource.Attributes()
+	attrs.UpsertString(attributeExporterAddress, packet.exporter)
+	attrs.UpsertString(attributeFlowType, string(packet.flowType))
+}
+
+// packetToLogs converts every flow of the packet into a log record.
+func packetToLogs(packet flowPacket, now time.Time) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	setResourceAttributes(packet, rl.Resource())
+
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	records.EnsureCapacity(len(packet.records))
+	for _, record := range packet.records {
+		lr := records.AppendEmpty()
+		lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(now))
+		if record.end.IsZero() {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(now))
+		} else {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(record.end))
+		}
+		lr.Body().SetStringVal(record.summary())
+		setRecordAttributes(record, lr.Attributes())
+	}
+	return logs
+}
+
+func setRecordAttributes(record flowRecord, attrs pcommon.Map) {
+	if record.srcAddr != nil {
+		attrs.UpsertString(attributeSourceAddress, record.srcAddr.String())
+		attrs.UpsertInt(attributeSourcePort, int64(record.srcPort))
+	}
+	if record.dstAddr != nil {
+		attrs.UpsertString(attributeDestinationAddress, record.dstAddr.String())
+		attrs.UpsertInt(attributeDestinationPort, int64(record.dstPort))
+	}
+	attrs.UpsertInt(attributeProtocol, int64(record.protocol))
+	if name, ok := transportNames[record.protocol]; ok {
+		attrs.UpsertString(attributeNetworkTransport, name)
+	}
+	attrs.UpsertInt(attributeBytes, int64(record.bytes))
+	attrs.UpsertInt(attributePackets, int64(record.packets))
+	if record.samplingRate > 0 {
+		attrs.UpsertInt(attributeSamplingRate, int64(record.samplingRate))
+	}
+	if record.tcpFlags != 0 {
+		attrs.UpsertInt(attributeTCPFlags, int64(record.tcpFlags))
+	}
+	attrs.UpsertInt(attributeTOS, int64(record.tos))
+	if record.inputInterface != 0 {
+		attrs.UpsertInt(attributeInputInterface, int64(record.inputInterface))
+	}
+	if record.outputInterface != 0 {
+		attrs.UpsertInt(attributeOutputInterface, int64(record.outputInterface))
+	}
+	if record.nextHop != nil && !record.nextHop.IsUnspecified() {
+		attrs.UpsertString(attributeNextHop, record.nextHop.String())
+	}
+	if record.srcAS != 0 {
+		attrs.UpsertInt(attributeSourceAS, int64(record.srcAS))
+	}
+	if record.dstAS != 0 {
+		attrs.UpsertInt(attributeDestinationAS, int64(record.dstAS))
+	}
+	if !record.start.IsZero() {
+		attrs.UpsertString(attributeStartTime, record.start.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// summary returns a human readable description of the flow, e.g. "10.0.0.1:51234 -> 10.0.0.2:443 tcp".
+func (r flowRecord) summary() string {
+	protocol, ok := transportNames[r.protocol]
+	if !ok {
+		protocol = strconv.Itoa(int(r.protocol))
+	}
+	return fmt.Sprintf("%s -> %s %s", hostPort(r.srcAddr, r.srcPort), hostPort(r.dstAddr, r.dstPort), protocol)
+}
+
+func hostPort(ip net.IP, port uint16) string {
+	if ip == nil {
+		return "unknown"
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// packetToMetrics sums up the bytes and packets of the flows of the packet per protocol.
+func packetToMetrics(packet flowPacket, now time.Time) pmetric.Metrics {
+	type totals struct {
+		bytes   uint64
+		packets uint64
+	}
+	perProtocol := map[uint8]*totals{}
+	start := now
+	for _, record := range packet.records {
+		t, ok := perProtocol[record.protocol]
+		if !ok {
+			t = &totals{}
+			perProtocol[record.protocol] = t
+		}
+		t.bytes += record.bytes
+		t.packets += record.packets
+		if !record.start.IsZero() && record.start.Before(start) {
+			start = record.start
+		}
+	}
+
+	protocols := make([]uint8, 0, len(perProtocol))
+	for protocol := range perProtocol {
+		protocols = append(protocols, protocol)
+	}
+	sort.Slice(protocols, func(i, j int) bool { return protocols[i] < protocols[j] })
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	setResourceAttributes(packet, rm.Resource())
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	bytesMetric := newDeltaSum(ms, metricBytes, "Number of bytes of the flows", "By")
+	packetsMetric := newDeltaSum(ms, metricPackets, "Number of packets of the flows", "{packets}")
+	for _, protocol := range protocols {
+		t := perProtocol[protocol]
+		addDataPoint(bytesMetric, protocol, t.bytes, start, now)
+		addDataPoint(packetsMetric, protocol, t.packets, start, now)
+	}
+	return metrics
+}
+
+func newDeltaSum(ms pmetric.MetricSlice, name string, description string, unit string) pmetric.Sum {
+	metric := ms.AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	metric.SetDataType(pmetric.MetricDataTypeSum)
+	metric.Sum().SetIsMonotonic(true)
+	metric.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+	return metric.Sum()
+}
+
+func addDataPoint(sum pmetric.Sum, protocol uint8, value uint64, start time.Time, now time.Time) {
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(now))
+	dp.SetIntVal(int64(value))
+	dp.Attributes().UpsertInt(attributeProtocol, int64(protocol))
+	if name, ok := transportNames[protocol]; ok {
+		dp.Attributes().UpsertString(attributeNetworkTransport, name)
+	}
+}
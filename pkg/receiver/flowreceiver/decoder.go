@@ -0,0 +1,230 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+type flowType string
+
+const (
+	flowTypeNetflowV5 flowType = "netflow_v5"
+	flowTypeNetflowV9 flowType = "netflow_v9"
+	flowTypeIPFIX     flowType = "ipfix"
+	flowTypeSflowV5   flowType = "sflow_v5"
+)
+
+// flowRecord is a single flow decoded from any of the supported protocols.
+type flowRecord struct {
+	srcAddr         net.IP
+	dstAddr         net.IP
+	nextHop         net.IP
+	srcPort         uint16
+	dstPort         uint16
+	protocol        uint8
+	tcpFlags        uint8
+	tos             uint8
+	inputInterface  uint32
+	outputInterface uint32
+	srcAS           uint32
+	dstAS           uint32
+	bytes           uint64
+	packets         uint64
+	// samplingRate is the number of packets out of which one was sampled. Zero if unknown.
+	samplingRate uint32
+	start        time.Time
+	end          time.Time
+}
+
+// flowPacket holds the flows decoded from a single datagram.
+type flowPacket struct {
+	flowType flowType
+	// exporter is the address of the device which exported the flows.
+	exporter string
+	records  []flowRecord
+}
+
+// decoder decodes flow datagrams. It's not safe for concurrent use, as it holds the template cache.
+type decoder struct {
+	normalizeSampling bool
+	templates         *templateCache
+}
+
+func newDecoder(cfg *Config) *decoder {
+	return &decoder{
+		normalizeSampling: cfg.NormalizeSampling,
+		templates:         newTemplateCache(cfg.TemplateTTL),
+	}
+}
+
+// decode detects the protocol of the datagram and decodes its flows.
+// exporter is the address the datagram was received from. When an error is returned,
+// the packet still contains the flows which were decoded before the error occurred.
+func (d *decoder) decode(exporter string, datagram []byte, now time.Time) (flowPacket, error) {
+	if len(datagram) < 4 {
+		return flowPacket{}, errors.New("datagram too short")
+	}
+
+	var (
+		packet flowPacket
+		err    error
+	)
+	// sFlow starts with a 32-bit version, all the other protocols with a 16-bit one.
+	switch version := binary.BigEndian.Uint16(datagram); {
+	case version == 5:
+		packet, err = decodeNetflowV5(exporter, datagram)
+	case version == 9:
+		packet, err = d.decodeNetflowV9(exporter, datagram, now)
+	case version == 10:
+		packet, err = d.decodeIPFIX(exporter, datagram, now)
+	case version == 0 && binary.BigEndian.Uint32(datagram) == 5:
+		packet, err = decodeSflowV5(datagram, now)
+	default:
+		return flowPacket{}, fmt.Errorf("unsupported flow protocol version %d", version)
+	}
+
+	if d.normalizeSampling {
+		for i := range packet.records {
+			normalizeSampling(&packet.records[i])
+		}
+	}
+	return packet, err
+}
+
+func normalizeSampling(record *flowRecord) {
+	if record.samplingRate > 1 {
+		record.bytes *= uint64(record.samplingRate)
+		record.packets *= uint64(record.samplingRate)
+	}
+}
+
+// Information elements shared by NetFlow v9 and IPFIX.
+// For more info: https://www.iana.org/assignments/ipfix/ipfix.xhtml
+const (
+	fieldOctetDeltaCount          uint16 = 1
+	fieldPacketDeltaCount         uint16 = 2
+	fieldProtocolIdentifier       uint16 = 4
+	fieldIPClassOfService         uint16 = 5
+	fieldTCPControlBits           uint16 = 6
+	fieldSourceTransportPort      uint16 = 7
+	fieldSourceIPv4Address        uint16 = 8
+	fieldIngressInterface         uint16 = 10
+	fieldDestinationTransportPort uint16 = 11
+	fieldDestinationIPv4Address   uint16 = 12
+	fieldEgressInterface          uint16 = 14
+	fieldIPNextHopIPv4Address     uint16 = 15
+	fieldBGPSourceAsNumber        uint16 = 16
+	fieldBGPDestinationAsNumber   uint16 = 17
+	fieldFlowEndSysUpTime         uint16 = 21
+	fieldFlowStartSysUpTime       uint16 = 22
+	fieldSourceIPv6Address        uint16 = 27
+	fieldDestinationIPv6Address   uint16 = 28
+	fieldSamplingInterval         uint16 = 34
+	fieldSamplerRandomInterval    uint16 = 50
+	fieldIPNextHopIPv6Address     uint16 = 62
+	fieldOctetTotalCount          uint16 = 85
+	fieldPacketTotalCount         uint16 = 86
+	fieldFlowStartSeconds         uint16 = 150
+	fieldFlowEndSeconds           uint16 = 151
+	fieldFlowStartMilliseconds    uint16 = 152
+	fieldFlowEndMilliseconds      uint16 = 153
+	fieldSamplingPacketInterval   uint16 = 305
+)
+
+// exportTimes hold the export header times needed to decode system uptime based timestamps.
+type exportTimes struct {
+	exportTime time.Time
+	// sysUptime is the uptime of the exporter in milliseconds. IPFIX doesn't carry it.
+	sysUptime uint32
+}
+
+// uptimeToTime converts the exporter uptime in milliseconds to time.
+// The difference is computed as a signed 32-bit number to handle the uptime wrapping around.
+func (t exportTimes) uptimeToTime(uptime uint32) time.Time {
+	return t.exportTime.Add(-time.Duration(int32(t.sysUptime-uptime)) * time.Millisecond)
+}
+
+// setField sets the field of the record corresponding to the information element.
+// Unknown information elements are ignored.
+func (r *flowRecord) setField(id uint16, value []byte, times exportTimes) {
+	switch id {
+	case fieldOctetDeltaCount, fieldOctetTotalCount:
+		r.bytes = decodeUint(value)
+	case fieldPacketDeltaCount, fieldPacketTotalCount:
+		r.packets = decodeUint(value)
+	case fieldProtocolIdentifier:
+		r.protocol = uint8(decodeUint(value))
+	case fieldIPClassOfService:
+		r.tos = uint8(decodeUint(value))
+	case fieldTCPControlBits:
+		r.tcpFlags = uint8(decodeUint(value))
+	case fieldSourceTransportPort:
+		r.srcPort = uint16(decodeUint(value))
+	case fieldDestinationTransportPort:
+		r.dstPort = uint16(decodeUint(value))
+	case fieldSourceIPv4Address, fieldSourceIPv6Address:
+		r.srcAddr = decodeIP(value)
+	case fieldDestinationIPv4Address, fieldDestinationIPv6Address:
+		r.dstAddr = decodeIP(value)
+	case fieldIPNextHopIPv4Address, fieldIPNextHopIPv6Address:
+		r.nextHop = decodeIP(value)
+	case fieldIngressInterface:
+		r.inputInterface = uint32(decodeUint(value))
+	case fieldEgressInterface:
+		r.outputInterface = uint32(decodeUint(value))
+	case fieldBGPSourceAsNumber:
+		r.srcAS = uint32(decodeUint(value))
+	case fieldBGPDestinationAsNumber:
+		r.dstAS = uint32(decodeUint(value))
+	case fieldSamplingInterval, fieldSamplerRandomInterval, fieldSamplingPacketInterval:
+		r.samplingRate = uint32(decodeUint(value))
+	case fieldFlowStartSysUpTime:
+		r.start = times.uptimeToTime(uint32(decodeUint(value)))
+	case fieldFlowEndSysUpTime:
+		r.end = times.uptimeToTime(uint32(decodeUint(value)))
+	case fieldFlowStartSeconds:
+		r.start = time.Unix(int64(decodeUint(value)), 0)
+	case fieldFlowEndSeconds:
+		r.end = time.Unix(int64(decodeUint(value)), 0)
+	case fieldFlowStartMilliseconds:
+		r.start = time.UnixMilli(int64(decodeUint(value)))
+	case fieldFlowEndMilliseconds:
+		r.end = time.UnixMilli(int64(decodeUint(value)))
+	}
+}
+
+// decodeUint decodes a big endian unsigned integer of up to 8 bytes.
+// IPFIX allows exporters to send integers using fewer bytes than their natural size.
+func decodeUint(value []byte) uint64 {
+	var v uint64
+	for _, b := range value {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func decodeIP(value []byte) net.IP {
+	if len(value) != net.IPv4len && len(value) != net.IPv6len {
+		return nil
+	}
+	ip := make(net.IP, len(value))
+	copy(ip, value)
+	return ip
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// datagramBuilder builds flow protocol datagrams.
+type datagramBuilder struct {
+	bytes.Buffer
+}
+
+func (b *datagramBuilder) uint8(v uint8) *datagramBuilder {
+	b.WriteByte(v)
+	return b
+}
+
+func (b *datagramBuilder) uint16(v uint16) *datagramBuilder {
+	_ = binary.Write(b, binary.BigEndian, v)
+	return b
+}
+
+func (b *datagramBuilder) uint32(v uint32) *datagramBuilder {
+	_ = binary.Write(b, binary.BigEndian, v)
+	return b
+}
+
+func (b *datagramBuilder) uint64(v uint64) *datagramBuilder {
+	_ = binary.Write(b, binary.BigEndian, v)
+	return b
+}
+
+func (b *datagramBuilder) ip(s string) *datagramBuilder {
+	ip := net.ParseIP(s)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	b.Write(ip)
+	return b
+}
+
+func (b *datagramBuilder) raw(data []byte) *datagramBuilder {
+	b.Write(data)
+	return b
+}
+
+// set wraps the content into a NetFlow v9 or IPFIX set.
+func set(id uint16, content []byte) []byte {
+	return (&datagramBuilder{}).uint16(id).uint16(uint16(4 + len(content))).raw(content).Bytes()
+}
+
+func newTestDecoder() *decoder {
+	return newDecoder(createDefaultConfig().(*Config))
+}
+
+func TestDecodeUnsupported(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	_, err := d.decode("10.0.0.1", []byte{0, 1}, now)
+	assert.Error(t, err)
+
+	_, err = d.decode("10.0.0.1", (&datagramBuilder{}).uint16(7).uint16(0).Bytes(), now)
+	assert.Error(t, err)
+
+	_, err = d.decode("10.0.0.1", (&datagramBuilder{}).uint32(4).Bytes(), now)
+	assert.Error(t, err)
+}
+
+func TestDecodeUint(t *testing.T) {
+	assert.Equal(t, uint64(0), decodeUint(nil))
+	assert.Equal(t, uint64(0x0102), decodeUint([]byte{1, 2}))
+	assert.Equal(t, uint64(0x010203), decodeUint([]byte{1, 2, 3}))
+	assert.Equal(t, uint64(0x0102030405060708), decodeUint([]byte{1, 2, 3, 4, 5, 6, 7, 8}))
+}
+
+func TestUptimeToTime(t *testing.T) {
+	exportTime := time.Unix(1660000000, 0)
+	times := exportTimes{exportTime: exportTime, sysUptime: 10000}
+
+	assert.Equal(t, exportTime.Add(-4*time.Second), times.uptimeToTime(6000))
+
+	// the uptime wrapped around since the flow started
+	times.sysUptime = 1000
+	assert.Equal(t, exportTime.Add(-2*time.Second), times.uptimeToTime(^uint32(0)-999))
+}
+
+func TestTemplateCacheTTL(t *testing.T) {
+	cache := newTemplateCache(time.Minute)
+	key := domainKey{exporter: "10.0.0.1", domain: 1}.template(256)
+	now := time.Now()
+
+	cache.add(key, &template{received: now})
+	assert.NotNil(t, cache.get(key, now.Add(time.Minute)))
+	assert.Nil(t, cache.get(key, now.Add(time.Minute+time.Second)))
+	assert.Nil(t, cache.get(key, now))
+
+	cache = newTemplateCache(0)
+	cache.add(key, &template{received: now})
+	assert.NotNil(t, cache.get(key, now.Add(24*time.Hour)))
+}
+
+func TestNormalizeSampling(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	datagram := netflowV5Datagram(100)
+
+	packet, err := newDecoder(cfg).decode("10.0.0.1", datagram, time.Now())
+	require.NoError(t, err)
+	require.Len(t, packet.records, 1)
+	assert.Equal(t, uint64(150000), packet.records[0].bytes)
+	assert.Equal(t, uint64(1000), packet.records[0].packets)
+
+	cfg.NormalizeSampling = false
+	packet, err = newDecoder(cfg).decode("10.0.0.1", datagram, time.Now())
+	require.NoError(t, err)
+	require.Len(t, packet.records, 1)
+	assert.Equal(t, uint64(1500), packet.records[0].bytes)
+	assert.Equal(t, uint64(10), packet.records[0].packets)
+	assert.Equal(t, uint32(100), packet.records[0].samplingRate)
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+const (
+	// Value of "type" key in configuration.
+	typeStr = "flow"
+)
+
+var (
+	receiversMutex sync.Mutex
+	// receivers holds the receivers per configuration, so that logs and metrics pipelines share one listener.
+	receivers = map[*Config]*flowReceiver{}
+)
+
+// NewFactory creates a factory for flow receiver.
+func NewFactory() component.ReceiverFactory {
+	return component.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithLogsReceiver(createLogsReceiver),
+		component.WithMetricsReceiver(createMetricsReceiver))
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings:  config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		Endpoint:          defaultEndpoint,
+		NormalizeSampling: true,
+		TemplateTTL:       defaultTemplateTTL,
+	}
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	consumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	receiver := getOrCreateReceiver(params, cfg.(*Config))
+	receiver.logsConsumer = consumer
+	return receiver, nil
+}
+
+func createMetricsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	consumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	receiver := getOrCreateReceiver(params, cfg.(*Config))
+	receiver.metricsConsumer = consumer
+	return receiver, nil
+}
+
+func getOrCreateReceiver(params component.ReceiverCreateSettings, cfg *Config) *flowReceiver {
+	receiversMutex.Lock()
+	defer receiversMutex.Unlock()
+
+	receiver, ok := receivers[cfg]
+	if !ok {
+		receiver = newFlowReceiver(params, cfg)
+		receivers[cfg] = receiver
+	}
+	return receiver
+}
+
+func removeReceiver(cfg *Config) {
+	receiversMutex.Lock()
+	defer receiversMutex.Unlock()
+
+	delete(receivers, cfg)
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IPFIX layout.
+// For more info: https://www.rfc-editor.org/rfc/rfc7011
+const (
+	ipfixHeaderLength = 16
+
+	ipfixTemplateSetID        uint16 = 2
+	ipfixOptionsTemplateSetID uint16 = 3
+)
+
+func (d *decoder) decodeIPFIX(exporter string, datagram []byte, now time.Time) (flowPacket, error) {
+	packet := flowPacket{flowType: flowTypeIPFIX, exporter: exporter}
+	if len(datagram) < ipfixHeaderLength {
+		return packet, errors.New("truncated ipfix header")
+	}
+
+	length := int(binary.BigEndian.Uint16(datagram[2:4]))
+	if length < ipfixHeaderLength || length > len(datagram) {
+		return packet, fmt.Errorf("invalid ipfix message length: %d", length)
+	}
+	times := exportTimes{
+		exportTime: time.Unix(int64(binary.BigEndian.Uint32(datagram[4:8])), 0),
+	}
+	domain := domainKey{exporter: exporter, domain: binary.BigEndian.Uint32(datagram[12:16])}
+	missingTemplates := 0
+
+	err := decodeSets(datagram[ipfixHeaderLength:length], func(id uint16, set []byte) error {
+		switch {
+		case id == ipfixTemplateSetID:
+			return d.decodeIPFIXTemplates(set, domain, false, now)
+		case id == ipfixOptionsTemplateSetID:
+			return d.decodeIPFIXTemplates(set, domain, true, now)
+		case id >= minTemplateID:
+			t := d.templates.get(domain.template(id), now)
+			if t == nil {
+				missingTemplates++
+				return nil
+			}
+			records, err := d.decodeDataSet(set, t, domain, times)
+			packet.records = append(packet.records, records...)
+			return err
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return packet, err
+	}
+	if missingTemplates > 0 {
+		return packet, fmt.Errorf("skipped %d ipfix data sets with unknown templates", missingTemplates)
+	}
+	return packet, nil
+}
+
+// decodeIPFIXTemplates decodes a template or an options template set.
+// Templates with no fields withdraw previously announced templates.
+func (d *decoder) decodeIPFIXTemplates(set []byte, domain domainKey, options bool, now time.Time) error {
+	headerLength := 4
+	if options {
+		headerLength = 6
+	}
+
+	for len(set) >= headerLength {
+		id := binary.BigEndian.Uint16(set[0:2])
+		count := int(binary.BigEndian.Uint16(set[2:4]))
+		if id < minTemplateID {
+			// The rest of the set is padding.
+			return nil
+		}
+		if count == 0 {
+			d.templates.remove(domain.template(id))
+			set = set[4:]
+			continue
+		}
+
+		scopeCount := 0
+		if options {
+			scopeCount = int(binary.BigEndian.Uint16(set[4:6]))
+			if scopeCount > count {
+				return fmt.Errorf("ipfix options template %d has more scope fields than fields", id)
+			}
+		}
+
+		fields, rest, err := decodeTemplateFields(set[headerLength:], count, true)
+		if err != nil {
+			return err
+		}
+		set = rest
+		for i := 0; i < scopeCount; i++ {
+			fields[i].scope = true
+		}
+
+		d.templates.add(
+			domain.template(id),
+			&template{fields: fields, options: options, received: now},
+		)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fieldInterfaceName uint16 = 82
+
+// ipfixMessage returns an IPFIX message consisting of the sets.
+func ipfixMessage(domain uint32, sets ...[]byte) []byte {
+	length := ipfixHeaderLength
+	for _, s := range sets {
+		length += len(s)
+	}
+	b := (&datagramBuilder{}).uint16(10).uint16(uint16(length)).uint32(testUnixSecs).uint32(1).uint32(domain)
+	for _, s := range sets {
+		b.raw(s)
+	}
+	return b.Bytes()
+}
+
+func ipfixTemplateSet() []byte {
+	template := (&datagramBuilder{}).uint16(300).uint16(8).
+		uint16(fieldSourceIPv6Address).uint16(16).
+		uint16(fieldDestinationIPv6Address).uint16(16).
+		uint16(fieldProtocolIdentifier).uint16(1).
+		// reduced size encoding of an unsigned64 counter
+		uint16(fieldOctetDeltaCount).uint16(4).
+		uint16(fieldPacketDeltaCount).uint16(8).
+		uint16(fieldFlowEndMilliseconds).uint16(8).
+		// enterprise specific field
+		uint16(0x8000 | 1).uint16(2).uint32(29305).
+		uint16(fieldInterfaceName).uint16(variableLength)
+	return set(ipfixTemplateSetID, template.Bytes())
+}
+
+func ipfixDataSet(interfaceName string) []byte {
+	data := (&datagramBuilder{}).ip("2001:db8::1").ip("2001:db8::2").uint8(58).
+		uint32(640).uint64(8).uint64(testUnixSecs*1000 + 250).uint16(7).
+		uint8(uint8(len(interfaceName))).raw([]byte(interfaceName))
+	return set(300, data.Bytes())
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	packet, err := d.decode("192.168.1.1", ipfixMessage(7, ipfixTemplateSet(), ipfixDataSet("eth0")), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, flowPacket{
+		flowType: flowTypeIPFIX,
+		exporter: "192.168.1.1",
+		records: []flowRecord{{
+			srcAddr:  net.ParseIP("2001:db8::1"),
+			dstAddr:  net.ParseIP("2001:db8::2"),
+			protocol: 58,
+			bytes:    640,
+			packets:  8,
+			end:      time.UnixMilli(testUnixSecs*1000 + 250),
+		}},
+	}, packet)
+
+	// the template is cached for the following messages of the domain
+	packet, err = d.decode("192.168.1.1", ipfixMessage(7, ipfixDataSet("a-very-long-interface-name")), now)
+	require.NoError(t, err)
+	assert.Len(t, packet.records, 1)
+
+	_, err = d.decode("192.168.1.1", ipfixMessage(8, ipfixDataSet("eth0")), now)
+	assert.Error(t, err)
+}
+
+func TestDecodeIPFIXTemplateWithdrawal(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	_, err := d.decode("192.168.1.1", ipfixMessage(7, ipfixTemplateSet()), now)
+	require.NoError(t, err)
+
+	withdrawal := set(ipfixTemplateSetID, (&datagramBuilder{}).uint16(300).uint16(0).Bytes())
+	_, err = d.decode("192.168.1.1", ipfixMessage(7, withdrawal), now)
+	require.NoError(t, err)
+
+	packet, err := d.decode("192.168.1.1", ipfixMessage(7, ipfixDataSet("eth0")), now)
+	assert.Error(t, err)
+	assert.Empty(t, packet.records)
+}
+
+func TestDecodeIPFIXTemplateExpiration(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	_, err := d.decode("192.168.1.1", ipfixMessage(7, ipfixTemplateSet()), now)
+	require.NoError(t, err)
+
+	packet, err := d.decode("192.168.1.1", ipfixMessage(7, ipfixDataSet("eth0")), now.Add(defaultTemplateTTL+time.Second))
+	assert.Error(t, err)
+	assert.Empty(t, packet.records)
+}
+
+func TestDecodeIPFIXSamplingOptions(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	optionsTemplate := (&datagramBuilder{}).uint16(400).uint16(2).uint16(1).
+		uint16(149).uint16(4). // scope: observationDomainId
+		uint16(fieldSamplingPacketInterval).uint16(4)
+	optionsData := (&datagramBuilder{}).uint32(7).uint32(10)
+
+	packet, err := d.decode("192.168.1.1", ipfixMessage(7,
+		set(ipfixOptionsTemplateSetID, optionsTemplate.Bytes()),
+		set(400, optionsData.Bytes()),
+		ipfixTemplateSet(),
+		ipfixDataSet("eth0"),
+	), now)
+	require.NoError(t, err)
+
+	require.Len(t, packet.records, 1)
+	assert.Equal(t, uint32(10), packet.records[0].samplingRate)
+	assert.Equal(t, uint64(6400), packet.records[0].bytes)
+	assert.Equal(t, uint64(80), packet.records[0].packets)
+}
+
+func TestDecodeIPFIXErrors(t *testing.T) {
+	truncatedData := (&datagramBuilder{}).ip("2001:db8::1").ip("2001:db8::2").uint8(58).
+		uint32(640).uint64(8).uint64(0).uint16(7).uint8(200).raw([]byte("eth0"))
+
+	testcases := []struct {
+		name     string
+		datagram []byte
+	}{
+		{name: "truncated header", datagram: ipfixMessage(7)[:12]},
+		{name: "invalid message length", datagram: (&datagramBuilder{}).uint16(10).uint16(100).raw(make([]byte, 12)).Bytes()},
+		{name: "truncated variable length field", datagram: ipfixMessage(7, ipfixTemplateSet(), set(300, truncatedData.Bytes()))},
+		{name: "truncated enterprise number", datagram: ipfixMessage(7, set(ipfixTemplateSetID, (&datagramBuilder{}).uint16(300).uint16(1).uint16(0x8001).uint16(2).Bytes()))},
+		{name: "too many scope fields", datagram: ipfixMessage(7, set(ipfixOptionsTemplateSetID, (&datagramBuilder{}).uint16(400).uint16(1).uint16(2).uint16(149).uint16(4).Bytes()))},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestDecoder().decode("192.168.1.1", tc.datagram, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
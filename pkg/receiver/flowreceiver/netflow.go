@@ -0,0 +1,174 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NetFlow v5 layout.
+// For more info: https://www.cisco.com/c/en/us/td/docs/net_mgmt/netflow_collection_engine/3-6/user/guide/format.html
+const (
+	netflowV5HeaderLength = 24
+	netflowV5RecordLength = 48
+)
+
+// NetFlow v9 layout.
+// For more info: https://www.ietf.org/rfc/rfc3954.txt
+const (
+	netflowV9HeaderLength = 20
+
+	netflowV9TemplateSetID        uint16 = 0
+	netflowV9OptionsTemplateSetID uint16 = 1
+)
+
+func decodeNetflowV5(exporter string, datagram []byte) (flowPacket, error) {
+	packet := flowPacket{flowType: flowTypeNetflowV5, exporter: exporter}
+	if len(datagram) < netflowV5HeaderLength {
+		return packet, errors.New("truncated netflow v5 header")
+	}
+
+	count := int(binary.BigEndian.Uint16(datagram[2:4]))
+	times := exportTimes{
+		sysUptime:  binary.BigEndian.Uint32(datagram[4:8]),
+		exportTime: time.Unix(int64(binary.BigEndian.Uint32(datagram[8:12])), int64(binary.BigEndian.Uint32(datagram[12:16]))),
+	}
+	// The two most significant bits hold the sampling mode, the rest is the sampling interval.
+	samplingRate := uint32(binary.BigEndian.Uint16(datagram[22:24]) & 0x3FFF)
+
+	data := datagram[netflowV5HeaderLength:]
+	if len(data) < count*netflowV5RecordLength {
+		return packet, fmt.Errorf("netflow v5 datagram holds less than %d records", count)
+	}
+
+	packet.records = make([]flowRecord, 0, count)
+	for i := 0; i < count; i++ {
+		r := data[i*netflowV5RecordLength : (i+1)*netflowV5RecordLength]
+		packet.records = append(packet.records, flowRecord{
+			srcAddr:         decodeIP(r[0:4]),
+			dstAddr:         decodeIP(r[4:8]),
+			nextHop:         decodeIP(r[8:12]),
+			inputInterface:  uint32(binary.BigEndian.Uint16(r[12:14])),
+			outputInterface: uint32(binary.BigEndian.Uint16(r[14:16])),
+			packets:         uint64(binary.BigEndian.Uint32(r[16:20])),
+			bytes:           uint64(binary.BigEndian.Uint32(r[20:24])),
+			start:           times.uptimeToTime(binary.BigEndian.Uint32(r[24:28])),
+			end:             times.uptimeToTime(binary.BigEndian.Uint32(r[28:32])),
+			srcPort:         binary.BigEndian.Uint16(r[32:34]),
+			dstPort:         binary.BigEndian.Uint16(r[34:36]),
+			tcpFlags:        r[37],
+			protocol:        r[38],
+			tos:             r[39],
+			srcAS:           uint32(binary.BigEndian.Uint16(r[40:42])),
+			dstAS:           uint32(binary.BigEndian.Uint16(r[42:44])),
+			samplingRate:    samplingRate,
+		})
+	}
+	return packet, nil
+}
+
+func (d *decoder) decodeNetflowV9(exporter string, datagram []byte, now time.Time) (flowPacket, error) {
+	packet := flowPacket{flowType: flowTypeNetflowV9, exporter: exporter}
+	if len(datagram) < netflowV9HeaderLength {
+		return packet, errors.New("truncated netflow v9 header")
+	}
+
+	times := exportTimes{
+		sysUptime:  binary.BigEndian.Uint32(datagram[4:8]),
+		exportTime: time.Unix(int64(binary.BigEndian.Uint32(datagram[8:12])), 0),
+	}
+	domain := domainKey{exporter: exporter, domain: binary.BigEndian.Uint32(datagram[16:20])}
+	missingTemplates := 0
+
+	err := decodeSets(datagram[netflowV9HeaderLength:], func(id uint16, set []byte) error {
+		switch {
+		case id == netflowV9TemplateSetID:
+			return d.decodeNetflowV9Templates(set, domain, now)
+		case id == netflowV9OptionsTemplateSetID:
+			return d.decodeNetflowV9OptionsTemplates(set, domain, now)
+		case id >= minTemplateID:
+			t := d.templates.get(domain.template(id), now)
+			if t == nil {
+				missingTemplates++
+				return nil
+			}
+			records, err := d.decodeDataSet(set, t, domain, times)
+			packet.records = append(packet.records, records...)
+			return err
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return packet, err
+	}
+	if missingTemplates > 0 {
+		return packet, fmt.Errorf("skipped %d netflow v9 data sets with unknown templates", missingTemplates)
+	}
+	return packet, nil
+}
+
+func (d *decoder) decodeNetflowV9Templates(set []byte, domain domainKey, now time.Time) error {
+	for len(set) >= 4 {
+		id := binary.BigEndian.Uint16(set[0:2])
+		count := int(binary.BigEndian.Uint16(set[2:4]))
+		if id < minTemplateID {
+			// The rest of the set is padding.
+			return nil
+		}
+
+		fields, rest, err := decodeTemplateFields(set[4:], count, false)
+		if err != nil {
+			return err
+		}
+		set = rest
+
+		d.templates.add(
+			domain.template(id),
+			&template{fields: fields, received: now},
+		)
+	}
+	return nil
+}
+
+func (d *decoder) decodeNetflowV9OptionsTemplates(set []byte, domain domainKey, now time.Time) error {
+	for len(set) >= 6 {
+		id := binary.BigEndian.Uint16(set[0:2])
+		// Scope and option lengths are expressed in bytes, every field specifier takes 4 bytes.
+		scopeCount := int(binary.BigEndian.Uint16(set[2:4])) / 4
+		optionCount := int(binary.BigEndian.Uint16(set[4:6])) / 4
+		if id < minTemplateID {
+			return nil
+		}
+
+		fields, rest, err := decodeTemplateFields(set[6:], scopeCount+optionCount, false)
+		if err != nil {
+			return err
+		}
+		set = rest
+		for i := 0; i < scopeCount; i++ {
+			fields[i].scope = true
+		}
+
+		d.templates.add(
+			domain.template(id),
+			&template{fields: fields, options: true, received: now},
+		)
+	}
+	return nil
+}
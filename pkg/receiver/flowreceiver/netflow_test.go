@@ -0,0 +1,195 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUnixSecs  = 1660000000
+	testSysUptime = 100000
+)
+
+// netflowV5Datagram returns a NetFlow v5 datagram with a single flow.
+func netflowV5Datagram(samplingInterval uint16) []byte {
+	b := &datagramBuilder{}
+	b.uint16(5).uint16(1).uint32(testSysUptime).uint32(testUnixSecs).uint32(0).
+		uint32(42).uint8(0).uint8(0).uint16(0x4000 | samplingInterval)
+	b.ip("10.0.0.1").ip("10.0.0.2").ip("10.0.0.254").uint16(3).uint16(4).
+		uint32(10).uint32(1500).uint32(testSysUptime - 5000).uint32(testSysUptime - 1000).
+		uint16(51234).uint16(443).uint8(0).uint8(0x1b).uint8(6).uint8(0x10).
+		uint16(64512).uint16(64513).uint8(24).uint8(24).uint16(0)
+	return b.Bytes()
+}
+
+func TestDecodeNetflowV5(t *testing.T) {
+	packet, err := decodeNetflowV5("192.168.1.1", netflowV5Datagram(0))
+	require.NoError(t, err)
+
+	exportTime := time.Unix(testUnixSecs, 0)
+	assert.Equal(t, flowPacket{
+		flowType: flowTypeNetflowV5,
+		exporter: "192.168.1.1",
+		records: []flowRecord{{
+			srcAddr:         net.IP{10, 0, 0, 1},
+			dstAddr:         net.IP{10, 0, 0, 2},
+			nextHop:         net.IP{10, 0, 0, 254},
+			srcPort:         51234,
+			dstPort:         443,
+			protocol:        6,
+			tcpFlags:        0x1b,
+			tos:             0x10,
+			inputInterface:  3,
+			outputInterface: 4,
+			srcAS:           64512,
+			dstAS:           64513,
+			bytes:           1500,
+			packets:         10,
+			start:           exportTime.Add(-5 * time.Second),
+			end:             exportTime.Add(-time.Second),
+		}},
+	}, packet)
+
+	_, err = decodeNetflowV5("192.168.1.1", netflowV5Datagram(0)[:60])
+	assert.Error(t, err)
+}
+
+func netflowV9Header(count uint16, sourceID uint32) *datagramBuilder {
+	return (&datagramBuilder{}).uint16(9).uint16(count).uint32(testSysUptime).uint32(testUnixSecs).uint32(1).uint32(sourceID)
+}
+
+func netflowV9TemplateSet() []byte {
+	template := (&datagramBuilder{}).uint16(256).uint16(9).
+		uint16(fieldSourceIPv4Address).uint16(4).
+		uint16(fieldDestinationIPv4Address).uint16(4).
+		uint16(fieldSourceTransportPort).uint16(2).
+		uint16(fieldDestinationTransportPort).uint16(2).
+		uint16(fieldProtocolIdentifier).uint16(1).
+		uint16(fieldOctetDeltaCount).uint16(4).
+		uint16(fieldPacketDeltaCount).uint16(4).
+		uint16(fieldFlowStartSysUpTime).uint16(4).
+		uint16(fieldFlowEndSysUpTime).uint16(4)
+	return set(netflowV9TemplateSetID, template.Bytes())
+}
+
+func netflowV9DataSet() []byte {
+	data := &datagramBuilder{}
+	data.ip("10.0.0.1").ip("10.0.0.2").uint16(53000).uint16(53).uint8(17).uint32(120).uint32(2).
+		uint32(testSysUptime - 3000).uint32(testSysUptime - 2000)
+	data.ip("10.0.0.3").ip("10.0.0.4").uint16(40000).uint16(80).uint8(6).uint32(4000).uint32(5).
+		uint32(testSysUptime - 3000).uint32(testSysUptime - 1000)
+	// padding to a multiple of 4 bytes
+	data.uint16(0)
+	return set(256, data.Bytes())
+}
+
+func netflowV9OptionsSets() []byte {
+	template := (&datagramBuilder{}).uint16(257).uint16(4).uint16(4).
+		uint16(1).uint16(4). // scope: system
+		uint16(fieldSamplingInterval).uint16(4).
+		uint16(0) // padding
+	data := (&datagramBuilder{}).uint32(0).uint32(50)
+	return append(set(netflowV9OptionsTemplateSetID, template.Bytes()), set(257, data.Bytes())...)
+}
+
+func TestDecodeNetflowV9(t *testing.T) {
+	d := newTestDecoder()
+	d.normalizeSampling = false
+	now := time.Now()
+
+	datagram := netflowV9Header(3, 1).raw(netflowV9TemplateSet()).raw(netflowV9DataSet()).Bytes()
+	packet, err := d.decode("192.168.1.1", datagram, now)
+	require.NoError(t, err)
+
+	exportTime := time.Unix(testUnixSecs, 0)
+	assert.Equal(t, flowTypeNetflowV9, packet.flowType)
+	assert.Equal(t, "192.168.1.1", packet.exporter)
+	assert.Equal(t, []flowRecord{
+		{
+			srcAddr:  net.IP{10, 0, 0, 1},
+			dstAddr:  net.IP{10, 0, 0, 2},
+			srcPort:  53000,
+			dstPort:  53,
+			protocol: 17,
+			bytes:    120,
+			packets:  2,
+			start:    exportTime.Add(-3 * time.Second),
+			end:      exportTime.Add(-2 * time.Second),
+		},
+		{
+			srcAddr:  net.IP{10, 0, 0, 3},
+			dstAddr:  net.IP{10, 0, 0, 4},
+			srcPort:  40000,
+			dstPort:  80,
+			protocol: 6,
+			bytes:    4000,
+			packets:  5,
+			start:    exportTime.Add(-3 * time.Second),
+			end:      exportTime.Add(-time.Second),
+		},
+	}, packet.records)
+
+	// the template is cached for the following datagrams
+	packet, err = d.decode("192.168.1.1", netflowV9Header(2, 1).raw(netflowV9DataSet()).Bytes(), now)
+	require.NoError(t, err)
+	assert.Len(t, packet.records, 2)
+
+	// templates are scoped to the exporter and the source id
+	packet, err = d.decode("192.168.1.1", netflowV9Header(2, 2).raw(netflowV9DataSet()).Bytes(), now)
+	assert.Error(t, err)
+	assert.Empty(t, packet.records)
+
+	packet, err = d.decode("192.168.1.2", netflowV9Header(2, 1).raw(netflowV9DataSet()).Bytes(), now)
+	assert.Error(t, err)
+	assert.Empty(t, packet.records)
+}
+
+func TestDecodeNetflowV9SamplingOptions(t *testing.T) {
+	d := newTestDecoder()
+	now := time.Now()
+
+	datagram := netflowV9Header(5, 1).raw(netflowV9OptionsSets()).raw(netflowV9TemplateSet()).raw(netflowV9DataSet()).Bytes()
+	packet, err := d.decode("192.168.1.1", datagram, now)
+	require.NoError(t, err)
+
+	require.Len(t, packet.records, 2)
+	assert.Equal(t, uint32(50), packet.records[0].samplingRate)
+	assert.Equal(t, uint64(6000), packet.records[0].bytes)
+	assert.Equal(t, uint64(100), packet.records[0].packets)
+}
+
+func TestDecodeNetflowV9Errors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		datagram []byte
+	}{
+		{name: "truncated header", datagram: netflowV9Header(0, 1).Bytes()[:12]},
+		{name: "invalid set length", datagram: netflowV9Header(1, 1).uint16(0).uint16(100).Bytes()},
+		{name: "truncated template", datagram: netflowV9Header(1, 1).raw(set(netflowV9TemplateSetID, (&datagramBuilder{}).uint16(256).uint16(2).uint16(8).uint16(4).Bytes())).Bytes()},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestDecoder().decode("192.168.1.1", tc.datagram, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
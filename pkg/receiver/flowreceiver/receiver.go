@@ -0,0 +1,145 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.uber.org/zap"
+)
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+// flowReceiver listens for flow datagrams. A single instance is shared by the logs and metrics pipelines
+// using the same configuration, so that both can be fed from one UDP socket.
+type flowReceiver struct {
+	cfg     *Config
+	decoder *decoder
+
+	logsConsumer    consumer.Logs
+	metricsConsumer consumer.Metrics
+
+	conn         net.PacketConn
+	wg           sync.WaitGroup
+	ctx          context.Context
+	cancel       context.CancelFunc
+	startOnce    sync.Once
+	startErr     error
+	shutdownOnce sync.Once
+
+	logger *zap.Logger
+
+	now func() time.Time
+}
+
+func newFlowReceiver(params component.ReceiverCreateSettings, cfg *Config) *flowReceiver {
+	return &flowReceiver{
+		cfg:     cfg,
+		decoder: newDecoder(cfg),
+		logger:  params.Logger,
+		now:     time.Now,
+	}
+}
+
+// Start tells the receiver to start. The listener is started only once for all pipelines.
+func (r *flowReceiver) Start(ctx context.Context, host component.Host) error {
+	r.startOnce.Do(func() {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+
+		conn, err := net.ListenPacket("udp", r.cfg.Endpoint)
+		if err != nil {
+			r.startErr = fmt.Errorf("failed to start flow listener on %s: %w", r.cfg.Endpoint, err)
+			return
+		}
+		r.conn = conn
+
+		r.wg.Add(1)
+		go r.readDatagrams()
+		r.logger.Info("Started flow listener", zap.String("endpoint", r.cfg.Endpoint))
+	})
+	return r.startErr
+}
+
+// Shutdown is invoked during service shutdown.
+func (r *flowReceiver) Shutdown(ctx context.Context) error {
+	r.shutdownOnce.Do(func() {
+		removeReceiver(r.cfg)
+		if r.cancel != nil {
+			r.cancel()
+		}
+		if r.conn != nil {
+			r.conn.Close()
+		}
+		r.wg.Wait()
+	})
+	return nil
+}
+
+// readDatagrams handles the datagrams one by one, as the decoder holding the templates isn't safe for concurrent use.
+func (r *flowReceiver) readDatagrams() {
+	defer r.wg.Done()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.logger.Warn("Failed to read datagram", zap.Error(err))
+			continue
+		}
+		r.handleDatagram(exporterAddress(addr), buf[:n])
+	}
+}
+
+func (r *flowReceiver) handleDatagram(exporter string, datagram []byte) {
+	now := r.now()
+
+	packet, err := r.decoder.decode(exporter, datagram, now)
+	if err != nil {
+		r.logger.Debug("Failed to decode flow datagram", zap.String("exporter", exporter), zap.Error(err))
+	}
+	if len(packet.records) == 0 {
+		return
+	}
+
+	if r.logsConsumer != nil {
+		if err := r.logsConsumer.ConsumeLogs(r.ctx, packetToLogs(packet, now)); err != nil {
+			r.logger.Error("ConsumeLogs() error", zap.Error(err))
+		}
+	}
+	if r.metricsConsumer != nil {
+		if err := r.metricsConsumer.ConsumeMetrics(r.ctx, packetToMetrics(packet, now)); err != nil {
+			r.logger.Error("ConsumeMetrics() error", zap.Error(err))
+		}
+	}
+}
+
+// exporterAddress returns the IP address of the exporter the datagram was received from.
+func exporterAddress(addr net.Addr) string {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.IP.String()
+	}
+	return addr.String()
+}
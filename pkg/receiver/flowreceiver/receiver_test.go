@@ -0,0 +1,135 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestReceiver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "127.0.0.1:0"
+	now := time.Unix(testUnixSecs+10, 0)
+
+	params := componenttest.NewNopReceiverCreateSettings()
+	logsSink := new(consumertest.LogsSink)
+	metricsSink := new(consumertest.MetricsSink)
+	logsReceiver, err := createLogsReceiver(context.Background(), params, cfg, logsSink)
+	require.NoError(t, err)
+	metricsReceiver, err := createMetricsReceiver(context.Background(), params, cfg, metricsSink)
+	require.NoError(t, err)
+
+	receiver := logsReceiver.(*flowReceiver)
+	receiver.now = func() time.Time { return now }
+	require.NoError(t, logsReceiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, metricsReceiver.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, logsReceiver.Shutdown(context.Background()))
+		assert.NoError(t, metricsReceiver.Shutdown(context.Background()))
+	})
+
+	conn, err := net.Dial("udp", receiver.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("not a flow datagram"))
+	require.NoError(t, err)
+	_, err = conn.Write(netflowV5Datagram(10))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return logsSink.LogRecordCount() == 1 && metricsSink.DataPointCount() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	rl := logsSink.AllLogs()[0].ResourceLogs().At(0)
+	assert.Equal(t, map[string]interface{}{
+		attributeExporterAddress: "127.0.0.1",
+		attributeFlowType:        "netflow_v5",
+	}, rl.Resource().Attributes().AsRaw())
+
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "10.0.0.1:51234 -> 10.0.0.2:443 tcp", lr.Body().StringVal())
+	assert.Equal(t, pcommon.NewTimestampFromTime(time.Unix(testUnixSecs-1, 0)), lr.Timestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(now), lr.ObservedTimestamp())
+	assert.Equal(t, map[string]interface{}{
+		attributeSourceAddress:      "10.0.0.1",
+		attributeSourcePort:         int64(51234),
+		attributeDestinationAddress: "10.0.0.2",
+		attributeDestinationPort:    int64(443),
+		attributeProtocol:           int64(6),
+		attributeNetworkTransport:   "tcp",
+		attributeBytes:              int64(15000),
+		attributePackets:            int64(100),
+		attributeSamplingRate:       int64(10),
+		attributeTCPFlags:           int64(0x1b),
+		attributeTOS:                int64(0x10),
+		attributeInputInterface:     int64(3),
+		attributeOutputInterface:    int64(4),
+		attributeNextHop:            "10.0.0.254",
+		attributeSourceAS:           int64(64512),
+		attributeDestinationAS:      int64(64513),
+		attributeStartTime:          "2022-08-08T23:06:35Z",
+	}, lr.Attributes().AsRaw())
+
+	ms := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+	for i, expected := range []struct {
+		name  string
+		value int64
+	}{{name: metricBytes, value: 15000}, {name: metricPackets, value: 100}} {
+		metric := ms.At(i)
+		assert.Equal(t, expected.name, metric.Name())
+		require.Equal(t, pmetric.MetricDataTypeSum, metric.DataType())
+		assert.Equal(t, pmetric.MetricAggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+		dp := metric.Sum().DataPoints().At(0)
+		assert.Equal(t, expected.value, dp.IntVal())
+		assert.Equal(t, pcommon.NewTimestampFromTime(time.Unix(testUnixSecs-5, 0)), dp.StartTimestamp())
+		assert.Equal(t, map[string]interface{}{
+			attributeProtocol:         int64(6),
+			attributeNetworkTransport: "tcp",
+		}, dp.Attributes().AsRaw())
+	}
+}
+
+func TestReceiverStartError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "invalid:endpoint:1"
+
+	receiver := newFlowReceiver(componenttest.NewNopReceiverCreateSettings(), cfg)
+	assert.Error(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, receiver.Shutdown(context.Background()))
+}
+
+func TestSummary(t *testing.T) {
+	record := flowRecord{
+		srcAddr:  net.ParseIP("2001:db8::1"),
+		dstAddr:  net.ParseIP("2001:db8::2"),
+		srcPort:  1234,
+		dstPort:  53,
+		protocol: 99,
+	}
+	assert.Equal(t, "[2001:db8::1]:1234 -> [2001:db8::2]:53 99", record.summary())
+
+	assert.Equal(t, "unknown -> unknown icmp", flowRecord{protocol: 1}.summary())
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sFlow v5 layout.
+// For more info: https://sflow.org/sflow_version_5.txt
+const (
+	sflowAddressIPv4 uint32 = 1
+	sflowAddressIPv6 uint32 = 2
+
+	sflowFlowSample         uint32 = 1
+	sflowExpandedFlowSample uint32 = 3
+
+	sflowSampledHeader uint32 = 1
+	sflowSampledIPv4   uint32 = 3
+	sflowSampledIPv6   uint32 = 4
+
+	sflowHeaderEthernet uint32 = 1
+	sflowHeaderIPv4     uint32 = 11
+	sflowHeaderIPv6     uint32 = 12
+)
+
+const (
+	etherTypeIPv4 uint16 = 0x0800
+	etherTypeIPv6 uint16 = 0x86DD
+	etherTypeVLAN uint16 = 0x8100
+
+	protocolTCP  uint8 = 6
+	protocolUDP  uint8 = 17
+	protocolSCTP uint8 = 132
+)
+
+var errSflowTruncated = errors.New("truncated sflow datagram")
+
+// xdrReader reads the XDR encoded sFlow structures. After the first error all reads return zero values.
+type xdrReader struct {
+	data []byte
+	err  error
+}
+
+func (r *xdrReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// bytes reads n bytes. XDR opaque data is padded to a multiple of 4 bytes, the padding is skipped.
+func (r *xdrReader) bytes(n int) []byte {
+	padded := (n + 3) &^ 3
+	if r.err != nil || n < 0 || padded > len(r.data) {
+		r.err = errSflowTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[padded:]
+	return b
+}
+
+// decodeSflowV5 decodes the flow samples of an sFlow datagram. Counter samples are skipped.
+// sFlow doesn't carry flow timestamps, so the flows are stamped with the time of receipt.
+func decodeSflowV5(datagram []byte, now time.Time) (flowPacket, error) {
+	packet := flowPacket{flowType: flowTypeSflowV5}
+	r := &xdrReader{data: datagram}
+
+	r.uint32() // version
+	switch addressType := r.uint32(); addressType {
+	case sflowAddressIPv4:
+		packet.exporter = decodeIP(r.bytes(4)).String()
+	case sflowAddressIPv6:
+		packet.exporter = decodeIP(r.bytes(16)).String()
+	default:
+		return packet, fmt.Errorf("unsupported sflow agent address type %d", addressType)
+	}
+	r.uint32() // sub agent id
+	r.uint32() // sequence number
+	r.uint32() // uptime
+	count := int(r.uint32())
+	if r.err != nil {
+		return packet, r.err
+	}
+
+	for i := 0; i < count; i++ {
+		format := r.uint32()
+		sample := &xdrReader{data: r.bytes(int(r.uint32()))}
+		if r.err != nil {
+			return packet, r.err
+		}
+
+		var (
+			record flowRecord
+			ok     bool
+		)
+		// The upper 20 bits hold the enterprise number, only the standard formats are supported.
+		switch format {
+		case sflowFlowSample:
+			record, ok = decodeSflowFlowSample(sample, false)
+		case sflowExpandedFlowSample:
+			record, ok = decodeSflowFlowSample(sample, true)
+		default:
+			continue
+		}
+		if sample.err != nil {
+			return packet, sample.err
+		}
+		if ok {
+			record.start = now
+			record.end = now
+			packet.records = append(packet.records, record)
+		}
+	}
+	return packet, nil
+}
+
+// decodeSflowFlowSample decodes a flow sample into a flow of a single packet.
+// It returns false if the sample doesn't contain any supported flow record.
+func decodeSflowFlowSample(r *xdrReader, expanded bool) (flowRecord, bool) {
+	var record flowRecord
+
+	r.uint32() // sequence number
+	r.uint32() // source id
+	if expanded {
+		r.uint32() // source id index
+	}
+	record.samplingRate = r.uint32()
+	r.uint32() // sample pool
+	r.uint32() // drops
+	if expanded {
+		r.uint32() // input interface format
+		record.inputInterface = r.uint32()
+		r.uint32() // output interface format
+		record.outputInterface = r.uint32()
+	} else {
+		// The two most significant bits hold the interface format.
+		record.inputInterface = r.uint32() & 0x3FFFFFFF
+		record.outputInterface = r.uint32() & 0x3FFFFFFF
+	}
+	count := int(r.uint32())
+
+	ok := false
+	for i := 0; i < count && r.err == nil; i++ {
+		format := r.uint32()
+		data := &xdrReader{data: r.bytes(int(r.uint32()))}
+		if r.err != nil {
+			break
+		}
+
+		switch format {
+		case sflowSampledHeader:
+			protocol := data.uint32()
+			frameLength := data.uint32()
+			data.uint32() // stripped
+			header := data.bytes(int(data.uint32()))
+			if data.err == nil && decodeSampledHeader(protocol, header, &record) {
+				record.bytes = uint64(frameLength)
+				record.packets = 1
+				ok = true
+			}
+		case sflowSampledIPv4, sflowSampledIPv6:
+			addressLength := 4
+			if format == sflowSampledIPv6 {
+				addressLength = 16
+			}
+			length := data.uint32()
+			protocol := data.uint32()
+			srcAddr := data.bytes(addressLength)
+			dstAddr := data.bytes(addressLength)
+			srcPort := data.uint32()
+			dstPort := data.uint32()
+			tcpFlags := data.uint32()
+			tos := data.uint32()
+			if data.err == nil {
+				record.bytes = uint64(length)
+				record.packets = 1
+				record.protocol = uint8(protocol)
+				record.srcAddr = decodeIP(srcAddr)
+				record.dstAddr = decodeIP(dstAddr)
+				record.srcPort = uint16(srcPort)
+				record.dstPort = uint16(dstPort)
+				record.tcpFlags = uint8(tcpFlags)
+				record.tos = uint8(tos)
+				ok = true
+			}
+		}
+	}
+	return record, ok
+}
+
+// decodeSampledHeader decodes the addresses and ports from the header of a sampled packet.
+// It returns false if the header couldn't be decoded.
+func decodeSampledHeader(protocol uint32, header []byte, record *flowRecord) bool {
+	switch protocol {
+	case sflowHeaderEthernet:
+		if len(header) < 14 {
+			return false
+		}
+		etherType := binary.BigEndian.Uint16(header[12:14])
+		header = header[14:]
+		for etherType == etherTypeVLAN && len(header) >= 4 {
+			etherType = binary.BigEndian.Uint16(header[2:4])
+			header = header[4:]
+		}
+
+		switch etherType {
+		case etherTypeIPv4:
+			return decodeIPv4Header(header, record)
+		case etherTypeIPv6:
+			return decodeIPv6Header(header, record)
+		}
+		return false
+	case sflowHeaderIPv4:
+		return decodeIPv4Header(header, record)
+	case sflowHeaderIPv6:
+		return decodeIPv6Header(header, record)
+	default:
+		return false
+	}
+}
+
+func decodeIPv4Header(header []byte, record *flowRecord) bool {
+	if len(header) < 20 {
+		return false
+	}
+	headerLength := int(header[0]&0x0F) * 4
+	record.tos = header[1]
+	record.protocol = header[9]
+	record.srcAddr = decodeIP(header[12:16])
+	record.dstAddr = decodeIP(header[16:20])
+	if headerLength >= 20 && len(header) >= headerLength {
+		decodeTransportHeader(header[headerLength:], record)
+	}
+	return true
+}
+
+// decodeIPv6Header decodes the fixed IPv6 header. Extension headers aren't followed.
+func decodeIPv6Header(header []byte, record *flowRecord) bool {
+	if len(header) < 40 {
+		return false
+	}
+	record.tos = uint8(binary.BigEndian.Uint16(header[0:2]) >> 4)
+	record.protocol = header[6]
+	record.srcAddr = decodeIP(header[8:24])
+	record.dstAddr = decodeIP(header[24:40])
+	decodeTransportHeader(header[40:], record)
+	return true
+}
+
+func decodeTransportHeader(header []byte, record *flowRecord) {
+	switch record.protocol {
+	case protocolTCP, protocolUDP, protocolSCTP:
+		if len(header) < 4 {
+			return
+		}
+		record.srcPort = binary.BigEndian.Uint16(header[0:2])
+		record.dstPort = binary.BigEndian.Uint16(header[2:4])
+		if record.protocol == protocolTCP && len(header) >= 14 {
+			record.tcpFlags = header[13]
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sflowDatagram returns an sFlow v5 datagram from an IPv4 agent with the samples.
+func sflowDatagram(samples ...[]byte) []byte {
+	b := (&datagramBuilder{}).uint32(5).uint32(sflowAddressIPv4).ip("192.168.1.10").uint32(0).uint32(1).uint32(1000).uint32(uint32(len(samples)))
+	for _, sample := range samples {
+		b.raw(sample)
+	}
+	return b.Bytes()
+}
+
+// xdrOpaque prefixes the data with its length and pads it to a multiple of 4 bytes.
+func xdrOpaque(data []byte) []byte {
+	b := (&datagramBuilder{}).uint32(uint32(len(data))).raw(data)
+	for b.Len()%4 != 0 {
+		b.uint8(0)
+	}
+	return b.Bytes()
+}
+
+func sflowRecord(format uint32, data []byte) []byte {
+	return (&datagramBuilder{}).uint32(format).raw(xdrOpaque(data)).Bytes()
+}
+
+// ethernetFrame returns the header of a VLAN tagged ethernet frame carrying a TCP segment.
+func ethernetFrame() []byte {
+	b := &datagramBuilder{}
+	b.raw(make([]byte, 12)).uint16(etherTypeVLAN).uint16(100).uint16(etherTypeIPv4)
+	// IPv4 header
+	b.uint8(0x45).uint8(0x20).uint16(1500).uint32(0).uint8(64).uint8(protocolTCP).uint16(0).ip("10.0.0.1").ip("10.0.0.2")
+	// TCP header
+	b.uint16(51234).uint16(443).uint32(0).uint32(0).uint8(0x50).uint8(0x18).uint16(0)
+	return b.Bytes()
+}
+
+func sflowFlowSampleWithHeader() []byte {
+	header := (&datagramBuilder{}).uint32(sflowHeaderEthernet).uint32(1518).uint32(4).raw(xdrOpaque(ethernetFrame()))
+	sample := (&datagramBuilder{}).uint32(1).uint32(3).uint32(512).uint32(1024).uint32(0).
+		uint32(0x40000000 | 3).uint32(7).uint32(1).
+		raw(sflowRecord(sflowSampledHeader, header.Bytes()))
+	return sflowRecord(sflowFlowSample, sample.Bytes())
+}
+
+func sflowExpandedFlowSampleWithIPv4() []byte {
+	ipv4 := (&datagramBuilder{}).uint32(64).uint32(uint32(protocolUDP)).ip("10.0.0.3").ip("10.0.0.4").
+		uint32(5353).uint32(53).uint32(0).uint32(0)
+	sample := (&datagramBuilder{}).uint32(2).uint32(0).uint32(3).uint32(128).uint32(256).uint32(0).
+		uint32(0).uint32(5).uint32(0).uint32(6).uint32(2).
+		// unsupported records are skipped
+		raw(sflowRecord(1001, make([]byte, 16))).
+		raw(sflowRecord(sflowSampledIPv4, ipv4.Bytes()))
+	return sflowRecord(sflowExpandedFlowSample, sample.Bytes())
+}
+
+func sflowCounterSample() []byte {
+	return sflowRecord(2, make([]byte, 24))
+}
+
+func TestDecodeSflow(t *testing.T) {
+	now := time.Now()
+
+	packet, err := newTestDecoder().decode("192.168.1.1", sflowDatagram(
+		sflowFlowSampleWithHeader(),
+		sflowCounterSample(),
+		sflowExpandedFlowSampleWithIPv4(),
+	), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, flowPacket{
+		flowType: flowTypeSflowV5,
+		exporter: "192.168.1.10",
+		records: []flowRecord{
+			{
+				srcAddr:         net.IP{10, 0, 0, 1},
+				dstAddr:         net.IP{10, 0, 0, 2},
+				srcPort:         51234,
+				dstPort:         443,
+				protocol:        protocolTCP,
+				tcpFlags:        0x18,
+				tos:             0x20,
+				inputInterface:  3,
+				outputInterface: 7,
+				bytes:           1518 * 512,
+				packets:         512,
+				samplingRate:    512,
+				start:           now,
+				end:             now,
+			},
+			{
+				srcAddr:         net.IP{10, 0, 0, 3},
+				dstAddr:         net.IP{10, 0, 0, 4},
+				srcPort:         5353,
+				dstPort:         53,
+				protocol:        protocolUDP,
+				inputInterface:  5,
+				outputInterface: 6,
+				bytes:           64 * 128,
+				packets:         128,
+				samplingRate:    128,
+				start:           now,
+				end:             now,
+			},
+		},
+	}, packet)
+}
+
+func TestDecodeSampledIPv6Header(t *testing.T) {
+	b := &datagramBuilder{}
+	b.uint32(0x60100000).uint16(20).uint8(protocolUDP).uint8(64).ip("2001:db8::1").ip("2001:db8::2")
+	b.uint16(5353).uint16(53).uint16(20).uint16(0)
+
+	var record flowRecord
+	require.True(t, decodeSampledHeader(sflowHeaderIPv6, b.Bytes(), &record))
+	assert.Equal(t, flowRecord{
+		srcAddr:  net.ParseIP("2001:db8::1"),
+		dstAddr:  net.ParseIP("2001:db8::2"),
+		srcPort:  5353,
+		dstPort:  53,
+		protocol: protocolUDP,
+		tos:      0x01,
+	}, record)
+
+	assert.False(t, decodeSampledHeader(sflowHeaderIPv6, b.Bytes()[:39], &record))
+	assert.False(t, decodeSampledHeader(sflowHeaderEthernet, b.Bytes(), &record))
+	assert.False(t, decodeSampledHeader(2, b.Bytes(), &record))
+}
+
+func TestDecodeSflowErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		datagram []byte
+	}{
+		{name: "truncated header", datagram: sflowDatagram()[:16]},
+		{name: "unsupported agent address", datagram: (&datagramBuilder{}).uint32(5).uint32(3).Bytes()},
+		{name: "missing samples", datagram: (&datagramBuilder{}).uint32(5).uint32(sflowAddressIPv4).ip("192.168.1.10").uint32(0).uint32(1).uint32(1000).uint32(1).Bytes()},
+		{name: "truncated sample", datagram: sflowDatagram(sflowFlowSampleWithHeader()[:20])},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestDecoder().decode("192.168.1.1", tc.datagram, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
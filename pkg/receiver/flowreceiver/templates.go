@@ -0,0 +1,229 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// variableLength is the field length denoting an IPFIX variable length field.
+const variableLength uint16 = 0xFFFF
+
+// templateField describes a single field of a NetFlow v9 or IPFIX template.
+type templateField struct {
+	id     uint16
+	length uint16
+	// enterpriseNumber is set for IPFIX enterprise specific information elements.
+	enterpriseNumber uint32
+	// scope is true for scope fields of options templates.
+	scope bool
+}
+
+// template describes the layout of data records.
+type template struct {
+	fields []templateField
+	// options is true for options templates, whose records describe the exporter rather than flows.
+	options  bool
+	received time.Time
+}
+
+// minRecordLength returns the minimal length of a data record described by the template.
+// It's used to tell records from the padding at the end of a set.
+func (t *template) minRecordLength() int {
+	length := 0
+	for _, field := range t.fields {
+		if field.length == variableLength {
+			length++
+		} else {
+			length += int(field.length)
+		}
+	}
+	return length
+}
+
+// templateKey identifies a template. Template IDs are only unique within
+// the observation domain (source ID in NetFlow v9) of an exporter.
+type templateKey struct {
+	exporter string
+	domain   uint32
+	id       uint16
+}
+
+// domainKey identifies an observation domain of an exporter.
+type domainKey struct {
+	exporter string
+	domain   uint32
+}
+
+func (k domainKey) template(id uint16) templateKey {
+	return templateKey{exporter: k.exporter, domain: k.domain, id: id}
+}
+
+// templateCache holds the templates and the sampling rates announced by the exporters.
+type templateCache struct {
+	ttl           time.Duration
+	templates     map[templateKey]*template
+	samplingRates map[domainKey]uint32
+}
+
+func newTemplateCache(ttl time.Duration) *templateCache {
+	return &templateCache{
+		ttl:           ttl,
+		templates:     map[templateKey]*template{},
+		samplingRates: map[domainKey]uint32{},
+	}
+}
+
+func (c *templateCache) add(key templateKey, t *template) {
+	c.templates[key] = t
+}
+
+func (c *templateCache) remove(key templateKey) {
+	delete(c.templates, key)
+}
+
+// get returns the template or nil if it's not known or it expired.
+func (c *templateCache) get(key templateKey, now time.Time) *template {
+	t, ok := c.templates[key]
+	if !ok {
+		return nil
+	}
+	if c.ttl > 0 && now.Sub(t.received) > c.ttl {
+		delete(c.templates, key)
+		return nil
+	}
+	return t
+}
+
+func (c *templateCache) setSamplingRate(key domainKey, rate uint32) {
+	c.samplingRates[key] = rate
+}
+
+// samplingRate returns the sampling rate announced in options data of the domain, or zero if unknown.
+func (c *templateCache) samplingRate(key domainKey) uint32 {
+	return c.samplingRates[key]
+}
+
+// minTemplateID is the lowest ID of templates and data sets, lower IDs denote template sets.
+const minTemplateID uint16 = 256
+
+// decodeSets calls handle for every set (flowset in NetFlow v9) of a NetFlow v9 or IPFIX message.
+func decodeSets(data []byte, handle func(id uint16, set []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return errors.New("truncated set header")
+		}
+		id := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || length > len(data) {
+			return fmt.Errorf("invalid set length: %d", length)
+		}
+		if err := handle(id, data[4:length]); err != nil {
+			return err
+		}
+		data = data[length:]
+	}
+	return nil
+}
+
+// decodeTemplateFields decodes count field specifiers. IPFIX specifiers can carry an enterprise number.
+// It returns the fields and the rest of the data.
+func decodeTemplateFields(data []byte, count int, ipfix bool) ([]templateField, []byte, error) {
+	fields := make([]templateField, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, nil, errors.New("truncated template field")
+		}
+		field := templateField{
+			id:     binary.BigEndian.Uint16(data[0:2]),
+			length: binary.BigEndian.Uint16(data[2:4]),
+		}
+		data = data[4:]
+
+		if ipfix && field.id&0x8000 != 0 {
+			if len(data) < 4 {
+				return nil, nil, errors.New("truncated template field enterprise number")
+			}
+			field.id &^= 0x8000
+			field.enterpriseNumber = binary.BigEndian.Uint32(data[0:4])
+			data = data[4:]
+		}
+		if field.length == variableLength && !ipfix {
+			return nil, nil, fmt.Errorf("invalid length of field %d", field.id)
+		}
+		fields = append(fields, field)
+	}
+	return fields, data, nil
+}
+
+// decodeDataSet decodes the data records described by the template.
+// Records of options templates don't describe flows, they update the sampling rate of the domain instead.
+func (d *decoder) decodeDataSet(data []byte, t *template, domain domainKey, times exportTimes) ([]flowRecord, error) {
+	minLength := t.minRecordLength()
+	if minLength == 0 {
+		return nil, errors.New("template without fields")
+	}
+
+	var records []flowRecord
+	// Anything shorter than a record is padding.
+	for len(data) >= minLength {
+		var record flowRecord
+		for _, field := range t.fields {
+			length := int(field.length)
+			if field.length == variableLength {
+				var err error
+				if length, data, err = decodeVariableLength(data); err != nil {
+					return records, err
+				}
+			}
+			if len(data) < length {
+				return records, errors.New("truncated data record")
+			}
+			if field.enterpriseNumber == 0 && !field.scope {
+				record.setField(field.id, data[:length], times)
+			}
+			data = data[length:]
+		}
+
+		if t.options {
+			if record.samplingRate > 0 {
+				d.templates.setSamplingRate(domain, record.samplingRate)
+			}
+			continue
+		}
+		if record.samplingRate == 0 {
+			record.samplingRate = d.templates.samplingRate(domain)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeVariableLength decodes the length prefix of an IPFIX variable length field.
+func decodeVariableLength(data []byte) (int, []byte, error) {
+	if len(data) < 1 {
+		return 0, nil, errors.New("truncated variable length field")
+	}
+	if data[0] < 255 {
+		return int(data[0]), data[1:], nil
+	}
+	if len(data) < 3 {
+		return 0, nil, errors.New("truncated variable length field")
+	}
+	return int(binary.BigEndian.Uint16(data[1:3])), data[3:], nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+const (
+	defaultTimeout     = 20 * time.Second
+	defaultIPMIPath    = "ipmitool"
+	defaultSMARTPath   = "smartctl"
+	defaultSudoPath    = "sudo"
+	maxCommandDuration = 5 * time.Minute
+)
+
+// Config defines configuration for the receiver.
+type Config struct {
+	scraperhelper.ScraperControllerSettings `mapstructure:",squash"`
+
+	// Timeout is the maximal duration of a single command run.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// UseSudo runs the commands with `sudo -n`, as they usually require root privileges.
+	UseSudo bool `mapstructure:"use_sudo"`
+
+	// IPMI configures collection of IPMI sensor readings.
+	IPMI IPMIConfig `mapstructure:"ipmi"`
+	// SMART configures collection of SMART disk attributes.
+	SMART SMARTConfig `mapstructure:"smart"`
+}
+
+// IPMIConfig defines how IPMI sensors are read.
+type IPMIConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the path of the ipmitool binary, it's looked up in PATH if it's not absolute.
+	Path string `mapstructure:"path"`
+}
+
+// SMARTConfig defines which disks are checked.
+type SMARTConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the path of the smartctl binary, it's looked up in PATH if it's not absolute.
+	Path string `mapstructure:"path"`
+	// Devices are the paths of the checked disks, e.g. /dev/sda.
+	// All disks found by `smartctl --scan` are checked when it's empty.
+	Devices []string `mapstructure:"devices"`
+}
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ReceiverSettings.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.CollectionInterval <= 0 {
+		return errors.New("collection_interval must be positive")
+	}
+	if cfg.Timeout <= 0 || cfg.Timeout > maxCommandDuration {
+		return fmt.Errorf("timeout must be positive and at most %s", maxCommandDuration)
+	}
+	if !cfg.IPMI.Enabled && !cfg.SMART.Enabled {
+		return errors.New("either ipmi or smart has to be enabled")
+	}
+	if cfg.IPMI.Enabled && cfg.IPMI.Path == "" {
+		return errors.New("ipmi path cannot be empty")
+	}
+	if cfg.SMART.Enabled && cfg.SMART.Path == "" {
+		return errors.New("smart path cannot be empty")
+	}
+	for _, device := range cfg.SMART.Devices {
+		// devices are passed to smartctl as arguments, which mustn't be mistaken for options
+		if filepath.Clean(device) != device || !filepath.IsAbs(device) {
+			return fmt.Errorf("smart device %q has to be a clean absolute path", device)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	r0 := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, r0, factory.CreateDefaultConfig())
+
+	r1 := cfg.Receivers[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, r1,
+		&Config{
+			ScraperControllerSettings: scraperhelper.ScraperControllerSettings{
+				ReceiverSettings:   config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "custom")),
+				CollectionInterval: 5 * time.Minute,
+			},
+			Timeout: time.Minute,
+			UseSudo: true,
+			IPMI: IPMIConfig{
+				Enabled: false,
+				Path:    defaultIPMIPath,
+			},
+			SMART: SMARTConfig{
+				Enabled: true,
+				Path:    "/usr/sbin/smartctl",
+				Devices: []string{"/dev/sda", "/dev/nvme0"},
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "zero collection interval", modify: func(cfg *Config) { cfg.CollectionInterval = 0 }},
+		{name: "zero timeout", modify: func(cfg *Config) { cfg.Timeout = 0 }},
+		{name: "too long timeout", modify: func(cfg *Config) { cfg.Timeout = time.Hour }},
+		{name: "nothing enabled", modify: func(cfg *Config) {
+			cfg.IPMI.Enabled = false
+			cfg.SMART.Enabled = false
+		}},
+		{name: "no ipmi path", modify: func(cfg *Config) { cfg.IPMI.Path = "" }},
+		{name: "no smart path", modify: func(cfg *Config) { cfg.SMART.Path = "" }},
+		{name: "option as device", modify: func(cfg *Config) { cfg.SMART.Devices = []string{"--scan"} }},
+		{name: "relative device", modify: func(cfg *Config) { cfg.SMART.Devices = []string{"sda"} }},
+		{name: "unclean device", modify: func(cfg *Config) { cfg.SMART.Devices = []string{"/dev/../etc/shadow"} }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, cfg.Validate())
+
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// maxOutputSize is the maximal size of the output of a command, larger outputs are rejected.
+	maxOutputSize = 4 * 1024 * 1024
+	// maxStderrSize is the number of bytes of stderr included in errors.
+	maxStderrSize = 512
+)
+
+var errOutputTooLarge = fmt.Errorf("command output exceeds %d bytes", maxOutputSize)
+
+// commandResult is the output of a finished command.
+type commandResult struct {
+	stdout   []byte
+	stderr   []byte
+	exitCode int
+}
+
+// err describes the failure of a command which exited with a non-zero code.
+func (r commandResult) err(path string) error {
+	return fmt.Errorf("%s exited with code %d: %s", path, r.exitCode, strings.TrimSpace(string(r.stderr)))
+}
+
+// commandRunner runs a command and returns its result. The error is returned only
+// if the command couldn't be run or didn't finish, a non-zero exit code is not an error.
+type commandRunner func(ctx context.Context, path string, args ...string) (commandResult, error)
+
+// newCommandRunner returns a runner executing commands directly, without a shell, so arguments
+// are never interpreted. The commands run in the C locale to get stable number formats.
+func newCommandRunner(timeout time.Duration, useSudo bool) commandRunner {
+	return func(ctx context.Context, path string, args ...string) (commandResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		if useSudo {
+			// -n makes sudo fail instead of waiting for a password
+			args = append([]string{"-n", path}, args...)
+			path = defaultSudoPath
+		}
+
+		cmd := exec.Command(path, args...)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		stdout := &limitedBuffer{limit: maxOutputSize}
+		stderr := &limitedBuffer{limit: maxStderrSize}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		// the whole process group is killed on timeout, so children, e.g. of sudo,
+		// don't keep the output pipes open
+		setProcessGroup(cmd)
+
+		if err := cmd.Start(); err != nil {
+			return commandResult{}, fmt.Errorf("failed to run %s: %w", path, err)
+		}
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+			<-done
+			if ctx.Err() == context.DeadlineExceeded {
+				return commandResult{}, fmt.Errorf("%s timed out after %s", path, timeout)
+			}
+			return commandResult{}, ctx.Err()
+		}
+		if stdout.exceeded {
+			return commandResult{}, fmt.Errorf("%s: %w", path, errOutputTooLarge)
+		}
+
+		result := commandResult{stdout: stdout.Bytes(), stderr: stderr.Bytes()}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.exitCode = exitErr.ExitCode()
+			return result, nil
+		}
+		if err != nil {
+			return commandResult{}, fmt.Errorf("failed to run %s: %w", path, err)
+		}
+		return result, nil
+	}
+}
+
+// limitedBuffer stores up to limit bytes, the rest is discarded. It doesn't embed bytes.Buffer,
+// as its ReadFrom would be used by io.Copy instead of Write.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.exceeded = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		// the command is not failed by a short write, the rest of the output is discarded
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test uses sh")
+	}
+	run := newCommandRunner(time.Second, false)
+
+	result, err := run(context.Background(), "sh", "-c", `echo "$1"; echo failure >&2; exit 3`, "sh", "$(reboot); x | y")
+	require.NoError(t, err)
+	// arguments are not interpreted by a shell
+	assert.Equal(t, "$(reboot); x | y\n", string(result.stdout))
+	assert.Equal(t, "failure\n", string(result.stderr))
+	assert.Equal(t, 3, result.exitCode)
+	assert.EqualError(t, result.err("sh"), "sh exited with code 3: failure")
+
+	_, err = run(context.Background(), "sh", "-c", "sleep 5")
+	assert.EqualError(t, err, "sh timed out after 1s")
+
+	_, err = run(context.Background(), "sh", "-c", "head -c 5000000 /dev/zero")
+	assert.True(t, errors.Is(err, errOutputTooLarge))
+
+	_, err = run(context.Background(), "/nonexistent/ipmitool")
+	assert.Error(t, err)
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.False(t, b.exceeded)
+
+	n, err = b.Write([]byte(strings.Repeat("d", 10)))
+	require.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.True(t, b.exceeded)
+	assert.Equal(t, "abcdd", b.String())
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ipmiArgs make ipmitool list readings of all full sensor records, one sensor per line, e.g.
+//
+//	Inlet Temp       | 04h | ok  |  7.1 | 23 degrees C
+//	Fan1A            | 30h | ok  |  7.1 | 5880 RPM
+//	Fan2B            | 33h | ns  |  7.1 | No Reading
+var ipmiArgs = []string{"sdr", "elist", "full"}
+
+// ipmiStatusOK is the status of sensors which are within their thresholds.
+const ipmiStatusOK = "ok"
+
+// ipmiStatusNoReading is the status of sensors which are not present or disabled.
+const ipmiStatusNoReading = "ns"
+
+type sensorKind int
+
+const (
+	sensorKindOther sensorKind = iota
+	sensorKindTemperature
+	sensorKindFan
+	sensorKindVoltage
+	sensorKindCurrent
+	sensorKindPower
+)
+
+// ipmiSensor is a reading of an IPMI sensor.
+type ipmiSensor struct {
+	name string
+	// entity is the ID of the entity the sensor belongs to, e.g. 3.1 for the first processor.
+	// It tells apart sensors with the same name.
+	entity string
+	status string
+	kind   sensorKind
+	// value is set only for sensors with a numeric reading of a known unit.
+	value    float64
+	hasValue bool
+}
+
+// parseIPMISensors parses the output of ipmitool, lines which don't look like sensor readings are skipped.
+func parseIPMISensors(output []byte) []ipmiSensor {
+	var sensors []ipmiSensor
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) != 5 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		sensor := ipmiSensor{
+			name:   fields[0],
+			entity: fields[3],
+			status: strings.ToLower(fields[2]),
+		}
+		if sensor.name == "" || sensor.status == "" || sensor.status == ipmiStatusNoReading {
+			continue
+		}
+		sensor.kind, sensor.value, sensor.hasValue = parseIPMIReading(fields[4])
+		sensors = append(sensors, sensor)
+	}
+	return sensors
+}
+
+// parseIPMIReading parses a reading like "23 degrees C" into a value in the base unit of its kind.
+func parseIPMIReading(reading string) (sensorKind, float64, bool) {
+	parts := strings.SplitN(reading, " ", 2)
+	if len(parts) != 2 {
+		return sensorKindOther, 0, false
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return sensorKindOther, 0, false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(parts[1])) {
+	case "degrees c":
+		return sensorKindTemperature, value, true
+	case "degrees f":
+		return sensorKindTemperature, (value - 32) * 5 / 9, true
+	case "rpm":
+		return sensorKindFan, value, true
+	case "volts":
+		return sensorKindVoltage, value, true
+	case "amps":
+		return sensorKindCurrent, value, true
+	case "watts":
+		return sensorKindPower, value, true
+	default:
+		return sensorKindOther, 0, false
+	}
+}
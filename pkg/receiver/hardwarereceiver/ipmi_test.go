@@ -0,0 +1,73 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPMISensors(t *testing.T) {
+	output, err := os.ReadFile(filepath.Join("testdata", "ipmitool_sdr_elist.txt"))
+	require.NoError(t, err)
+
+	sensors := parseIPMISensors(output)
+	// the sensor without a reading is skipped
+	require.Len(t, sensors, 9)
+	assert.Equal(t, ipmiSensor{name: "Inlet Temp", entity: "7.1", status: "ok", kind: sensorKindTemperature, value: 23, hasValue: true}, sensors[0])
+	assert.Equal(t, ipmiSensor{name: "Temp", entity: "3.2", status: "cr", kind: sensorKindTemperature, value: 98, hasValue: true}, sensors[3])
+	assert.Equal(t, ipmiSensor{name: "Fan1A", entity: "7.1", status: "ok", kind: sensorKindFan, value: 5880, hasValue: true}, sensors[4])
+	assert.Equal(t, ipmiSensor{name: "Current 1", entity: "10.1", status: "ok", kind: sensorKindCurrent, value: 0.6, hasValue: true}, sensors[5])
+	assert.Equal(t, ipmiSensor{name: "PS1 Status", entity: "10.1", status: "ok", kind: sensorKindOther}, sensors[8])
+}
+
+func TestParseIPMISensorsSkipsGarbage(t *testing.T) {
+	output := []byte("Get Device ID command failed\n| | | |\nFan1 | 30h | ok | 7.1 | fast RPM | extra\nFan2 | 31h | OK | 7.1 | 1e400 RPM\n")
+
+	sensors := parseIPMISensors(output)
+	require.Len(t, sensors, 1)
+	// readings out of the float range are not reported
+	assert.Equal(t, ipmiSensor{name: "Fan2", entity: "7.1", status: "ok", kind: sensorKindOther}, sensors[0])
+}
+
+func TestParseIPMIReading(t *testing.T) {
+	testcases := []struct {
+		reading  string
+		kind     sensorKind
+		value    float64
+		hasValue bool
+	}{
+		{reading: "23 degrees C", kind: sensorKindTemperature, value: 23, hasValue: true},
+		{reading: "212 degrees F", kind: sensorKindTemperature, value: 100, hasValue: true},
+		{reading: "1.80 Volts", kind: sensorKindVoltage, value: 1.8, hasValue: true},
+		{reading: "112 Watts", kind: sensorKindPower, value: 112, hasValue: true},
+		{reading: "50 percent", kind: sensorKindOther},
+		{reading: "0x00", kind: sensorKindOther},
+		{reading: "", kind: sensorKindOther},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.reading, func(t *testing.T) {
+			kind, value, hasValue := parseIPMIReading(tc.reading)
+			assert.Equal(t, tc.kind, kind)
+			assert.Equal(t, tc.value, value)
+			assert.Equal(t, tc.hasValue, hasValue)
+		})
+	}
+}
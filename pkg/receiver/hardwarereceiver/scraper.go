@@ -0,0 +1,295 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+	"go.uber.org/zap"
+)
+
+const (
+	sensorAttribute = "sensor"
+	entityAttribute = "entity"
+	statusAttribute = "status"
+
+	smartAttributeIDAttribute   = "smart.attribute.id"
+	smartAttributeNameAttribute = "smart.attribute.name"
+
+	diskDeviceAttribute          = "disk.device"
+	diskTypeAttribute            = "disk.type"
+	diskModelAttribute           = "disk.model"
+	diskSerialNumberAttribute    = "disk.serial_number"
+	diskFirmwareVersionAttribute = "disk.firmware_version"
+)
+
+// ipmiMetrics are the metrics of sensor kinds.
+var ipmiMetrics = map[sensorKind]struct {
+	name        string
+	description string
+	unit        string
+}{
+	sensorKindTemperature: {"ipmi.temperature", "Temperature reported by the IPMI sensor.", "Cel"},
+	sensorKindFan:         {"ipmi.fan.speed", "Fan speed reported by the IPMI sensor.", "{rpm}"},
+	sensorKindVoltage:     {"ipmi.voltage", "Voltage reported by the IPMI sensor.", "V"},
+	sensorKindCurrent:     {"ipmi.current", "Current reported by the IPMI sensor.", "A"},
+	sensorKindPower:       {"ipmi.power", "Power reported by the IPMI sensor.", "W"},
+}
+
+type hardwareScraper struct {
+	cfg    *Config
+	run    commandRunner
+	logger *zap.Logger
+
+	now func() time.Time
+}
+
+func newHardwareScraper(cfg *Config, set component.ReceiverCreateSettings) *hardwareScraper {
+	return &hardwareScraper{
+		cfg:    cfg,
+		run:    newCommandRunner(cfg.Timeout, cfg.UseSudo),
+		logger: set.Logger,
+		now:    time.Now,
+	}
+}
+
+func (s *hardwareScraper) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	now := pcommon.NewTimestampFromTime(s.now())
+	errs := &scrapererror.ScrapeErrors{}
+
+	if s.cfg.IPMI.Enabled {
+		if err := s.scrapeIPMI(ctx, metrics, now); err != nil {
+			errs.AddPartial(1, err)
+		}
+	}
+	if s.cfg.SMART.Enabled {
+		s.scrapeSMART(ctx, metrics, now, errs)
+	}
+
+	return metrics, errs.Combine()
+}
+
+func (s *hardwareScraper) scrapeIPMI(ctx context.Context, metrics pmetric.Metrics, now pcommon.Timestamp) error {
+	result, err := s.run(ctx, s.cfg.IPMI.Path, ipmiArgs...)
+	if err != nil {
+		return err
+	}
+	if result.exitCode != 0 {
+		return result.err(s.cfg.IPMI.Path)
+	}
+
+	sensors := parseIPMISensors(result.stdout)
+	if len(sensors) == 0 {
+		return nil
+	}
+
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	status := newGauge(ms, "ipmi.sensor.status", "Whether the IPMI sensor is within its thresholds, 1 if it is, 0 otherwise.", "1")
+	readings := map[sensorKind]pmetric.Metric{}
+	for _, sensor := range sensors {
+		dp := status.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetIntVal(boolToInt(sensor.status == ipmiStatusOK))
+		setSensorAttributes(dp.Attributes(), sensor)
+		dp.Attributes().UpsertString(statusAttribute, sensor.status)
+
+		if !sensor.hasValue {
+			continue
+		}
+		m, ok := readings[sensor.kind]
+		if !ok {
+			desc := ipmiMetrics[sensor.kind]
+			m = newGauge(ms, desc.name, desc.description, desc.unit)
+			readings[sensor.kind] = m
+		}
+		dp = m.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(sensor.value)
+		setSensorAttributes(dp.Attributes(), sensor)
+	}
+	return nil
+}
+
+func setSensorAttributes(attrs pcommon.Map, sensor ipmiSensor) {
+	attrs.UpsertString(sensorAttribute, sensor.name)
+	if sensor.entity != "" {
+		attrs.UpsertString(entityAttribute, sensor.entity)
+	}
+}
+
+func (s *hardwareScraper) scrapeSMART(ctx context.Context, metrics pmetric.Metrics, now pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
+	devices, err := s.smartDevices(ctx)
+	if err != nil {
+		errs.AddPartial(1, err)
+		return
+	}
+
+	for _, device := range devices {
+		out, err := s.checkDisk(ctx, device)
+		if err != nil {
+			errs.AddPartial(1, err)
+			continue
+		}
+		if !out.hasData() {
+			s.logger.Debug("No SMART data available for the disk, it's in standby or it doesn't support SMART",
+				zap.String("device", device.Name))
+			continue
+		}
+		appendDiskMetrics(metrics.ResourceMetrics().AppendEmpty(), device, out, now)
+	}
+}
+
+// smartDevices returns the configured disks, or all disks found by smartctl if none are configured.
+func (s *hardwareScraper) smartDevices(ctx context.Context) ([]smartDevice, error) {
+	if len(s.cfg.SMART.Devices) > 0 {
+		devices := make([]smartDevice, 0, len(s.cfg.SMART.Devices))
+		for _, name := range s.cfg.SMART.Devices {
+			devices = append(devices, smartDevice{Name: name})
+		}
+		return devices, nil
+	}
+
+	result, err := s.run(ctx, s.cfg.SMART.Path, smartScanArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if result.exitCode != 0 {
+		return nil, result.err(s.cfg.SMART.Path)
+	}
+	return parseSMARTScan(result.stdout)
+}
+
+func (s *hardwareScraper) checkDisk(ctx context.Context, device smartDevice) (*smartOutput, error) {
+	result, err := s.run(ctx, s.cfg.SMART.Path, device.args()...)
+	if err != nil {
+		return nil, err
+	}
+	if result.exitCode&smartExitFatalMask != 0 {
+		return nil, fmt.Errorf("failed to check disk %s: %w", device.Name, result.err(s.cfg.SMART.Path))
+	}
+
+	out, err := parseSMARTOutput(result.stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check disk %s: %w", device.Name, err)
+	}
+	return out, nil
+}
+
+func appendDiskMetrics(rm pmetric.ResourceMetrics, device smartDevice, out *smartOutput, now pcommon.Timestamp) {
+	attrs := rm.Resource().Attributes()
+	attrs.UpsertString(diskDeviceAttribute, device.Name)
+	diskType := device.Type
+	if diskType == "" {
+		diskType = out.Device.Type
+	}
+	upsertNonEmpty(attrs, diskTypeAttribute, diskType)
+	upsertNonEmpty(attrs, diskModelAttribute, out.ModelName)
+	upsertNonEmpty(attrs, diskSerialNumberAttribute, out.SerialNumber)
+	upsertNonEmpty(attrs, diskFirmwareVersionAttribute, out.FirmwareVersion)
+
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	appendValue := func(name string, description string, unit string, value float64) {
+		dp := newGauge(ms, name, description, unit).Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(value)
+	}
+
+	if out.SmartStatus != nil {
+		appendValue("smart.health_status", "Whether the disk passed the SMART overall health self-assessment, 1 if it did, 0 otherwise.",
+			"1", float64(boolToInt(out.SmartStatus.Passed)))
+	}
+	if out.Temperature != nil {
+		appendValue("smart.temperature", "Current temperature of the disk.", "Cel", out.Temperature.Current)
+	}
+	if out.PowerOnTime != nil {
+		appendValue("smart.power_on_time", "Time the disk has been powered on.", "h", out.PowerOnTime.Hours)
+	}
+	if out.PowerCycleCount != nil {
+		appendValue("smart.power_cycles", "Number of power cycles of the disk.", "{cycles}", *out.PowerCycleCount)
+	}
+
+	if nvme := out.NVMeHealth; nvme != nil {
+		if nvme.CriticalWarning != nil {
+			appendValue("smart.nvme.critical_warning", "Critical warning bits reported by the NVMe disk.", "1", *nvme.CriticalWarning)
+		}
+		if nvme.AvailableSpare != nil {
+			appendValue("smart.nvme.available_spare", "Remaining spare capacity of the NVMe disk.", "%", *nvme.AvailableSpare)
+		}
+		if nvme.PercentageUsed != nil {
+			appendValue("smart.nvme.percentage_used", "Estimate of the NVMe disk life used.", "%", *nvme.PercentageUsed)
+		}
+		if nvme.MediaErrors != nil {
+			appendValue("smart.nvme.media_errors", "Number of unrecovered data integrity errors of the NVMe disk.", "{errors}", *nvme.MediaErrors)
+		}
+	}
+
+	if out.ATASmartAttributes == nil || len(out.ATASmartAttributes.Table) == 0 {
+		return
+	}
+	value := newGauge(ms, "smart.attribute.value", "Normalized value of the SMART attribute, higher is better.", "1")
+	worst := newGauge(ms, "smart.attribute.worst", "Lowest normalized value of the SMART attribute.", "1")
+	threshold := newGauge(ms, "smart.attribute.threshold", "Normalized value of the SMART attribute below which the disk is failing.", "1")
+	raw := newGauge(ms, "smart.attribute.raw", "Raw value of the SMART attribute, its meaning is vendor specific.", "1")
+	for _, attribute := range out.ATASmartAttributes.Table {
+		appendAttributeDataPoint(value, attribute, attribute.Value, now)
+		appendAttributeDataPoint(worst, attribute, attribute.Worst, now)
+		appendAttributeDataPoint(threshold, attribute, attribute.Thresh, now)
+		dp := raw.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleVal(attribute.Raw.Value)
+		setAttributeAttributes(dp.Attributes(), attribute)
+	}
+}
+
+func appendAttributeDataPoint(m pmetric.Metric, attribute smartAttribute, value int64, now pcommon.Timestamp) {
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetIntVal(value)
+	setAttributeAttributes(dp.Attributes(), attribute)
+}
+
+func setAttributeAttributes(attrs pcommon.Map, attribute smartAttribute) {
+	attrs.UpsertInt(smartAttributeIDAttribute, attribute.ID)
+	attrs.UpsertString(smartAttributeNameAttribute, attribute.Name)
+}
+
+func newGauge(ms pmetric.MetricSlice, name string, description string, unit string) pmetric.Metric {
+	m := ms.AppendEmpty()
+	m.SetName(name)
+	m.SetDescription(description)
+	m.SetUnit(unit)
+	m.SetDataType(pmetric.MetricDataTypeGauge)
+	return m
+}
+
+func upsertNonEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.UpsertString(key, value)
+	}
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,232 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeCommand is the result of a command run by the fake runner, stdout is read from the testdata file.
+type fakeCommand struct {
+	file     string
+	exitCode int
+	err      error
+}
+
+// newFakeRunner returns a runner returning results of commands by their command lines.
+func newFakeRunner(t *testing.T, commands map[string]fakeCommand) commandRunner {
+	return func(_ context.Context, path string, args ...string) (commandResult, error) {
+		command, ok := commands[strings.Join(append([]string{path}, args...), " ")]
+		if !ok {
+			return commandResult{}, errors.New("unexpected command")
+		}
+		if command.err != nil {
+			return commandResult{}, command.err
+		}
+
+		result := commandResult{exitCode: command.exitCode, stderr: []byte("failure")}
+		if command.file != "" {
+			stdout, err := os.ReadFile(filepath.Join("testdata", command.file))
+			require.NoError(t, err)
+			result.stdout = stdout
+		}
+		return result, nil
+	}
+}
+
+func newTestScraper(t *testing.T, commands map[string]fakeCommand, modify func(*Config)) *hardwareScraper {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	scraper := newHardwareScraper(cfg, componenttest.NewNopReceiverCreateSettings())
+	scraper.run = newFakeRunner(t, commands)
+	scraper.now = func() time.Time { return testNow }
+	return scraper
+}
+
+var (
+	ipmiCommand      = "ipmitool sdr elist full"
+	smartScanCommand = "smartctl --scan --json"
+	smartSDACommand  = "smartctl --json --all --nocheck=standby,0 --device=sat /dev/sda"
+	smartNVMeCommand = "smartctl --json --all --nocheck=standby,0 --device=nvme /dev/nvme0"
+)
+
+// dataPoints returns the values of the data points of the metric by the values of the attribute.
+func dataPoints(t *testing.T, ms pmetric.MetricSlice, name string, attribute string) map[string]float64 {
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		if m.Name() != name {
+			continue
+		}
+
+		values := map[string]float64{}
+		dps := m.Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			dp := dps.At(j)
+			assert.Equal(t, pcommon.NewTimestampFromTime(testNow), dp.Timestamp())
+			key := ""
+			if attribute != "" {
+				value, ok := dp.Attributes().Get(attribute)
+				require.True(t, ok)
+				key = value.AsString()
+			}
+			if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+				values[key] = float64(dp.IntVal())
+			} else {
+				values[key] = dp.DoubleVal()
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func TestScrapeIPMI(t *testing.T) {
+	scraper := newTestScraper(t, map[string]fakeCommand{
+		ipmiCommand: {file: "ipmitool_sdr_elist.txt"},
+	}, func(cfg *Config) {
+		cfg.SMART.Enabled = false
+	})
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, 6, ms.Len())
+	assert.Equal(t, map[string]float64{"Inlet Temp": 23, "Exhaust Temp": 35, "Temp": 98}, dataPoints(t, ms, "ipmi.temperature", sensorAttribute))
+	// sensors with the same name are told apart by the entity
+	assert.Equal(t, map[string]float64{"7.1": 35, "3.1": 40, "3.2": 98}, dataPoints(t, ms, "ipmi.temperature", entityAttribute))
+	assert.Equal(t, map[string]float64{"Fan1A": 5880}, dataPoints(t, ms, "ipmi.fan.speed", sensorAttribute))
+	assert.Equal(t, map[string]float64{"Voltage 1": 230}, dataPoints(t, ms, "ipmi.voltage", sensorAttribute))
+	assert.Equal(t, map[string]float64{"Current 1": 0.6}, dataPoints(t, ms, "ipmi.current", sensorAttribute))
+	assert.Equal(t, map[string]float64{"Pwr Consumption": 112}, dataPoints(t, ms, "ipmi.power", sensorAttribute))
+	assert.Equal(t, map[string]float64{"ok": 1, "cr": 0}, dataPoints(t, ms, "ipmi.sensor.status", statusAttribute))
+	assert.Len(t, dataPoints(t, ms, "ipmi.sensor.status", sensorAttribute), 8)
+}
+
+func TestScrapeSMART(t *testing.T) {
+	scraper := newTestScraper(t, map[string]fakeCommand{
+		smartScanCommand: {file: "smartctl_scan.json"},
+		// the disk has errors logged
+		smartSDACommand:  {file: "smartctl_sda.json", exitCode: 64},
+		smartNVMeCommand: {file: "smartctl_nvme0.json"},
+	}, func(cfg *Config) {
+		cfg.IPMI.Enabled = false
+	})
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.ResourceMetrics().Len())
+
+	sda := metrics.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]interface{}{
+		diskDeviceAttribute:          "/dev/sda",
+		diskTypeAttribute:            "sat",
+		diskModelAttribute:           "ST4000NM0035-1V4107",
+		diskSerialNumberAttribute:    "ZC11ABCD",
+		diskFirmwareVersionAttribute: "TN03",
+	}, sda.Resource().Attributes().AsRaw())
+	ms := sda.ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, 8, ms.Len())
+	assert.Equal(t, map[string]float64{"": 1}, dataPoints(t, ms, "smart.health_status", ""))
+	assert.Equal(t, map[string]float64{"": 31}, dataPoints(t, ms, "smart.temperature", ""))
+	assert.Equal(t, map[string]float64{"": 31250}, dataPoints(t, ms, "smart.power_on_time", ""))
+	assert.Equal(t, map[string]float64{"": 42}, dataPoints(t, ms, "smart.power_cycles", ""))
+	assert.Equal(t, map[string]float64{"Raw_Read_Error_Rate": 83, "Reallocated_Sector_Ct": 100, "Temperature_Celsius": 31},
+		dataPoints(t, ms, "smart.attribute.value", smartAttributeNameAttribute))
+	assert.Equal(t, map[string]float64{"1": 44, "5": 10, "194": 0}, dataPoints(t, ms, "smart.attribute.threshold", smartAttributeIDAttribute))
+	assert.Equal(t, float64(8), dataPoints(t, ms, "smart.attribute.raw", smartAttributeNameAttribute)["Reallocated_Sector_Ct"])
+
+	nvme := metrics.ResourceMetrics().At(1)
+	device, ok := nvme.Resource().Attributes().Get(diskDeviceAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "/dev/nvme0", device.StringVal())
+	ms = nvme.ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, 8, ms.Len())
+	assert.Equal(t, map[string]float64{"": 0}, dataPoints(t, ms, "smart.health_status", ""))
+	assert.Equal(t, map[string]float64{"": 4}, dataPoints(t, ms, "smart.nvme.critical_warning", ""))
+	assert.Equal(t, map[string]float64{"": 3}, dataPoints(t, ms, "smart.nvme.percentage_used", ""))
+	assert.Nil(t, dataPoints(t, ms, "smart.attribute.value", smartAttributeNameAttribute))
+}
+
+func TestScrapeSMARTConfiguredDevices(t *testing.T) {
+	scraper := newTestScraper(t, map[string]fakeCommand{
+		"/usr/sbin/smartctl --json --all --nocheck=standby,0 /dev/sda": {file: "smartctl_sda.json"},
+		"/usr/sbin/smartctl --json --all --nocheck=standby,0 /dev/sdb": {file: "smartctl_standby.json"},
+	}, func(cfg *Config) {
+		cfg.IPMI.Enabled = false
+		cfg.SMART.Path = "/usr/sbin/smartctl"
+		cfg.SMART.Devices = []string{"/dev/sda", "/dev/sdb"}
+	})
+
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+	// the disk in standby is skipped
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	diskType, ok := metrics.ResourceMetrics().At(0).Resource().Attributes().Get(diskTypeAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "sat", diskType.StringVal())
+}
+
+func TestScrapePartialFailure(t *testing.T) {
+	scraper := newTestScraper(t, map[string]fakeCommand{
+		ipmiCommand:      {exitCode: 1},
+		smartScanCommand: {file: "smartctl_scan.json"},
+		smartSDACommand:  {file: "smartctl_sda.json"},
+		// the device couldn't be opened
+		smartNVMeCommand: {exitCode: 2},
+	}, nil)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.Error(t, err)
+	assert.True(t, scrapererror.IsPartialScrapeError(err))
+	assert.Contains(t, err.Error(), "ipmitool exited with code 1: failure")
+	assert.Contains(t, err.Error(), "failed to check disk /dev/nvme0: smartctl exited with code 2: failure")
+
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	device, ok := metrics.ResourceMetrics().At(0).Resource().Attributes().Get(diskDeviceAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "/dev/sda", device.StringVal())
+}
+
+func TestScrapeCommandError(t *testing.T) {
+	scraper := newTestScraper(t, map[string]fakeCommand{
+		ipmiCommand:      {err: errors.New("ipmitool timed out after 20s")},
+		smartScanCommand: {err: errors.New("failed to run smartctl: executable file not found in $PATH")},
+	}, nil)
+
+	metrics, err := scraper.scrape(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 0, metrics.ResourceMetrics().Len())
+}
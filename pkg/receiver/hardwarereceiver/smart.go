@@ -0,0 +1,143 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	// smartExitFatalMask are the exit status bits of smartctl telling the command line
+	// couldn't be parsed or the device couldn't be opened. Other bits report disk problems,
+	// the output is valid then.
+	smartExitFatalMask = 0x3
+)
+
+// smartScanArgs make smartctl list the disks it can check.
+var smartScanArgs = []string{"--scan", "--json"}
+
+// smartDeviceArgs make smartctl print all SMART information about a disk, without waking it up
+// if it's in standby. Only the device, prefixed by its type if known, has to be appended.
+var smartDeviceArgs = []string{"--json", "--all", "--nocheck=standby,0"}
+
+// smartDeviceType matches device types reported by smartctl, e.g. sat, nvme or megaraid,0.
+var smartDeviceType = regexp.MustCompile(`^[a-z0-9_+-]+(,[a-z0-9_/+-]+)*$`)
+
+type smartDevice struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type smartScanOutput struct {
+	Devices []smartDevice `json:"devices"`
+}
+
+// smartOutput holds the parts of the smartctl output the metrics are collected from.
+// Pointers tell missing values apart from zeros.
+type smartOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+	} `json:"smartctl"`
+	Device          smartDevice `json:"device"`
+	ModelName       string      `json:"model_name"`
+	SerialNumber    string      `json:"serial_number"`
+	FirmwareVersion string      `json:"firmware_version"`
+	SmartStatus     *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current float64 `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours float64 `json:"hours"`
+	} `json:"power_on_time"`
+	PowerCycleCount    *float64 `json:"power_cycle_count"`
+	ATASmartAttributes *struct {
+		Table []smartAttribute `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth *struct {
+		CriticalWarning *float64 `json:"critical_warning"`
+		AvailableSpare  *float64 `json:"available_spare"`
+		PercentageUsed  *float64 `json:"percentage_used"`
+		MediaErrors     *float64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// smartAttribute is an ATA SMART attribute. Value, worst and threshold are normalized to 1-253,
+// higher is better, the raw value is vendor specific.
+type smartAttribute struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Value  int64  `json:"value"`
+	Worst  int64  `json:"worst"`
+	Thresh int64  `json:"thresh"`
+	Raw    struct {
+		Value float64 `json:"value"`
+	} `json:"raw"`
+}
+
+// hasData tells whether the output has any metrics, it has none e.g. for disks in standby
+// or without SMART support.
+func (o *smartOutput) hasData() bool {
+	return o.SmartStatus != nil || o.Temperature != nil || o.PowerOnTime != nil ||
+		o.PowerCycleCount != nil || o.ATASmartAttributes != nil || o.NVMeHealth != nil
+}
+
+func parseSMARTScan(output []byte) ([]smartDevice, error) {
+	var scan smartScanOutput
+	if err := json.Unmarshal(output, &scan); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl scan output: %w", err)
+	}
+
+	devices := make([]smartDevice, 0, len(scan.Devices))
+	for _, device := range scan.Devices {
+		if err := device.validate(); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+func parseSMARTOutput(output []byte) (*smartOutput, error) {
+	var out smartOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl output: %w", err)
+	}
+	return &out, nil
+}
+
+// validate checks that the device can be safely passed to smartctl as arguments.
+func (d smartDevice) validate() error {
+	if filepath.Clean(d.Name) != d.Name || !filepath.IsAbs(d.Name) {
+		return fmt.Errorf("invalid device name %q", d.Name)
+	}
+	if d.Type != "" && !smartDeviceType.MatchString(d.Type) {
+		return fmt.Errorf("invalid type %q of device %q", d.Type, d.Name)
+	}
+	return nil
+}
+
+// args returns the smartctl arguments checking the device.
+func (d smartDevice) args() []string {
+	args := append([]string{}, smartDeviceArgs...)
+	if d.Type != "" {
+		args = append(args, "--device="+d.Type)
+	}
+	return append(args, d.Name)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hardwarereceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSMARTScan(t *testing.T) {
+	output, err := os.ReadFile(filepath.Join("testdata", "smartctl_scan.json"))
+	require.NoError(t, err)
+
+	devices, err := parseSMARTScan(output)
+	require.NoError(t, err)
+	assert.Equal(t, []smartDevice{{Name: "/dev/sda", Type: "sat"}, {Name: "/dev/nvme0", Type: "nvme"}}, devices)
+
+	_, err = parseSMARTScan([]byte(`{"devices": [{"name": "/dev/sda", "type": "sat --all"}]}`))
+	assert.Error(t, err)
+	_, err = parseSMARTScan([]byte(`{"devices": [{"name": "--scan", "type": "sat"}]}`))
+	assert.Error(t, err)
+	_, err = parseSMARTScan([]byte(`/dev/sda -d sat # /dev/sda [SAT], ATA device`))
+	assert.Error(t, err)
+}
+
+func TestSMARTDeviceArgs(t *testing.T) {
+	assert.Equal(t, []string{"--json", "--all", "--nocheck=standby,0", "--device=megaraid,0", "/dev/bus/0"},
+		smartDevice{Name: "/dev/bus/0", Type: "megaraid,0"}.args())
+	assert.Equal(t, []string{"--json", "--all", "--nocheck=standby,0", "/dev/sda"},
+		smartDevice{Name: "/dev/sda"}.args())
+}
+
+func TestParseSMARTOutput(t *testing.T) {
+	output, err := os.ReadFile(filepath.Join("testdata", "smartctl_sda.json"))
+	require.NoError(t, err)
+
+	out, err := parseSMARTOutput(output)
+	require.NoError(t, err)
+	assert.True(t, out.hasData())
+	assert.Equal(t, "ST4000NM0035-1V4107", out.ModelName)
+	require.NotNil(t, out.SmartStatus)
+	assert.True(t, out.SmartStatus.Passed)
+	require.Len(t, out.ATASmartAttributes.Table, 3)
+	assert.Equal(t, smartAttribute{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Thresh: 10}, withoutRaw(out.ATASmartAttributes.Table[1]))
+	assert.Equal(t, float64(8), out.ATASmartAttributes.Table[1].Raw.Value)
+	assert.Nil(t, out.NVMeHealth)
+
+	output, err = os.ReadFile(filepath.Join("testdata", "smartctl_standby.json"))
+	require.NoError(t, err)
+	out, err = parseSMARTOutput(output)
+	require.NoError(t, err)
+	assert.False(t, out.hasData())
+}
+
+func withoutRaw(attribute smartAttribute) smartAttribute {
+	attribute.Raw.Value = 0
+	return attribute
+}
@@ -0,0 +1,312 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	// mqscPath is the path of the administrative REST API endpoint running MQSC commands.
+	mqscPath = "/ibmmq/rest/v2/admin/action/qmgr/%s/mqsc"
+	// messageListPath is the path of the messaging REST API endpoint listing messages on a queue.
+	messageListPath = "/ibmmq/rest/v3/messaging/qmgr/%s/queue/%s/messagelist"
+	// messagePath is the path of the messaging REST API endpoint browsing a message.
+	messagePath = "/ibmmq/rest/v3/messaging/qmgr/%s/queue/%s/message"
+
+	// csrfTokenHeader has to be present on all POST requests to the REST API, its value is ignored.
+	csrfTokenHeader = "ibm-mq-rest-csrf-token"
+
+	// reasonUnknownObjectName is returned when no queue matches the command.
+	reasonUnknownObjectName = 2085
+)
+
+// mqClient is a client of the IBM MQ REST API, which is served by the mqweb server.
+type mqClient struct {
+	client   *http.Client
+	endpoint string
+	username string
+	password string
+}
+
+func newMQClient(cfg *Config, host component.Host, set component.TelemetrySettings) (*mqClient, error) {
+	client, err := cfg.HTTPClientSettings.ToClient(host.GetExtensions(), set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return &mqClient{
+		client:   client,
+		endpoint: cfg.Endpoint,
+		username: cfg.Username,
+		password: cfg.Password,
+	}, nil
+}
+
+// queueStatus holds depths and usage of a local queue.
+type queueStatus struct {
+	name            string
+	currentDepth    int64
+	maxDepth        int64
+	openInputCount  int64
+	openOutputCount int64
+	// oldestMessageAge is the age of the oldest message in seconds,
+	// it's available only when queue monitoring (MONQ) is enabled.
+	oldestMessageAge    int64
+	hasOldestMessageAge bool
+}
+
+// message is a message browsed from a queue.
+type message struct {
+	id            string
+	correlationID string
+	format        string
+	contentType   string
+	body          []byte
+}
+
+type mqscCommand struct {
+	Type               string   `json:"type"`
+	Command            string   `json:"command"`
+	Qualifier          string   `json:"qualifier"`
+	Name               string   `json:"name"`
+	ResponseParameters []string `json:"responseParameters,omitempty"`
+}
+
+type mqscResponse struct {
+	CommandResponse []struct {
+		CompletionCode int                    `json:"completionCode"`
+		ReasonCode     int                    `json:"reasonCode"`
+		Message        []string               `json:"message"`
+		Parameters     map[string]interface{} `json:"parameters"`
+	} `json:"commandResponse"`
+	OverallCompletionCode int `json:"overallCompletionCode"`
+	OverallReasonCode     int `json:"overallReasonCode"`
+}
+
+type messageListResponse struct {
+	Messages []struct {
+		MessageID     string `json:"messageId"`
+		CorrelationID string `json:"correlationId"`
+		Format        string `json:"format"`
+	} `json:"messages"`
+}
+
+// queueStatuses returns statuses of the local queues matching the pattern,
+// which can end with * to match queues by prefix.
+func (c *mqClient) queueStatuses(ctx context.Context, qmgr string, pattern string) ([]queueStatus, error) {
+	queues, err := c.runCommand(ctx, qmgr, mqscCommand{
+		Type:               "runCommandJSON",
+		Command:            "display",
+		Qualifier:          "qlocal",
+		Name:               pattern,
+		ResponseParameters: []string{"curdepth", "maxdepth"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to display queues %s: %w", pattern, err)
+	}
+
+	statuses, err := c.runCommand(ctx, qmgr, mqscCommand{
+		Type:               "runCommandJSON",
+		Command:            "display",
+		Qualifier:          "qstatus",
+		Name:               pattern,
+		ResponseParameters: []string{"ipprocs", "opprocs", "msgage"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to display status of queues %s: %w", pattern, err)
+	}
+
+	byName := make(map[string]map[string]interface{}, len(statuses))
+	for _, status := range statuses {
+		name, _ := status["queue"].(string)
+		byName[name] = status
+	}
+
+	ret := make([]queueStatus, 0, len(queues))
+	for _, queue := range queues {
+		name, _ := queue["queue"].(string)
+		if name == "" {
+			continue
+		}
+
+		qs := queueStatus{name: name}
+		qs.currentDepth, _ = intParameter(queue, "curdepth")
+		qs.maxDepth, _ = intParameter(queue, "maxdepth")
+		if status, ok := byName[name]; ok {
+			qs.openInputCount, _ = intParameter(status, "ipprocs")
+			qs.openOutputCount, _ = intParameter(status, "opprocs")
+			qs.oldestMessageAge, qs.hasOldestMessageAge = intParameter(status, "msgage")
+		}
+		ret = append(ret, qs)
+	}
+	return ret, nil
+}
+
+// runCommand runs the MQSC command and returns parameters of the objects in the response.
+func (c *mqClient) runCommand(ctx context.Context, qmgr string, command mqscCommand) ([]map[string]interface{}, error) {
+	body, err := json.Marshal(command)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf(mqscPath, url.PathEscape(qmgr)), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(csrfTokenHeader, "otelcol")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response mqscResponse
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode MQSC response: %w", err)
+	}
+
+	ret := make([]map[string]interface{}, 0, len(response.CommandResponse))
+	for _, r := range response.CommandResponse {
+		switch {
+		case r.CompletionCode == 0:
+			ret = append(ret, r.Parameters)
+		case r.ReasonCode == reasonUnknownObjectName:
+			// no queue matches the pattern
+		default:
+			return nil, fmt.Errorf("MQSC command failed with completion code %d and reason code %d: %s",
+				r.CompletionCode, r.ReasonCode, strings.Join(r.Message, " "))
+		}
+	}
+	if response.OverallCompletionCode != 0 && len(response.CommandResponse) == 0 {
+		return nil, fmt.Errorf("MQSC command failed with completion code %d and reason code %d",
+			response.OverallCompletionCode, response.OverallReasonCode)
+	}
+	return ret, nil
+}
+
+// listMessages lists messages on the queue, the bodies are not fetched.
+func (c *mqClient) listMessages(ctx context.Context, qmgr string, queue string) ([]message, error) {
+	req, err := c.newRequest(ctx, http.MethodGet,
+		fmt.Sprintf(messageListPath, url.PathEscape(qmgr), url.PathEscape(queue)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages on queue %s: %w", queue, err)
+	}
+	defer resp.Body.Close()
+
+	var list messageListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode message list: %w", err)
+	}
+
+	messages := make([]message, 0, len(list.Messages))
+	for _, m := range list.Messages {
+		messages = append(messages, message{
+			id:            m.MessageID,
+			correlationID: m.CorrelationID,
+			format:        m.Format,
+		})
+	}
+	return messages, nil
+}
+
+// browseMessage fetches the body of the message without removing it from the queue.
+// Bodies longer than maxSize are truncated. It returns false if the message is not on the queue anymore.
+func (c *mqClient) browseMessage(ctx context.Context, qmgr string, queue string, msg *message, maxSize int) (bool, error) {
+	path := fmt.Sprintf(messagePath, url.PathEscape(qmgr), url.PathEscape(queue)) +
+		"?messageId=" + url.QueryEscape(msg.id)
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to browse message %s on queue %s: %w", msg.id, queue, err)
+	}
+	defer resp.Body.Close()
+
+	// the message was consumed in the meantime
+	if resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)))
+	if err != nil {
+		return false, err
+	}
+	msg.contentType = resp.Header.Get("Content-Type")
+	msg.body = body
+	return true, nil
+}
+
+func (c *mqClient) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.endpoint, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+// do sends the request and returns an error if the response status is not successful.
+func (c *mqClient) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("request to %s failed with status %s: %s", req.URL.Path, resp.Status, body)
+	}
+	return resp, nil
+}
+
+// intParameter returns the value of an integer parameter of an MQSC response.
+// The second return value is false when the parameter is missing or blank.
+func intParameter(params map[string]interface{}, name string) (int64, bool) {
+	switch v := params[name].(type) {
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
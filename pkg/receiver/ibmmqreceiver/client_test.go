@@ -0,0 +1,297 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testQueueManager = "QM1"
+	testUsername     = "admin"
+	testPassword     = "passw0rd"
+)
+
+type fakeMessage struct {
+	id          string
+	format      string
+	contentType string
+	body        string
+}
+
+// fakeMQ imitates the IBM MQ REST API of a single queue manager.
+type fakeMQ struct {
+	t *testing.T
+
+	mutex sync.Mutex
+	// queues holds parameters of DISPLAY QLOCAL by queue name
+	queues map[string]map[string]interface{}
+	// statuses holds parameters of DISPLAY QSTATUS by queue name
+	statuses map[string]map[string]interface{}
+	messages map[string][]fakeMessage
+	browsed  []string
+}
+
+func newFakeMQ(t *testing.T) (*fakeMQ, *mqClient) {
+	mq := &fakeMQ{
+		t:        t,
+		queues:   map[string]map[string]interface{}{},
+		statuses: map[string]map[string]interface{}{},
+		messages: map[string][]fakeMessage{},
+	}
+	srv := httptest.NewServer(mq)
+	t.Cleanup(srv.Close)
+
+	return mq, &mqClient{
+		client:   srv.Client(),
+		endpoint: srv.URL,
+		username: testUsername,
+		password: testPassword,
+	}
+}
+
+func (mq *fakeMQ) addQueue(name string, curdepth int, maxdepth int, ipprocs int, opprocs int, msgage string) {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+
+	mq.queues[name] = map[string]interface{}{"queue": name, "curdepth": curdepth, "maxdepth": maxdepth}
+	mq.statuses[name] = map[string]interface{}{"queue": name, "ipprocs": ipprocs, "opprocs": opprocs, "msgage": msgage}
+}
+
+func (mq *fakeMQ) putMessages(queue string, messages ...fakeMessage) {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	mq.messages[queue] = append(mq.messages[queue], messages...)
+}
+
+func (mq *fakeMQ) getMessage(queue string) {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	mq.messages[queue] = mq.messages[queue][1:]
+}
+
+func (mq *fakeMQ) browsedMessages() []string {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+	return append([]string{}, mq.browsed...)
+}
+
+func (mq *fakeMQ) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+
+	user, password, ok := req.BasicAuth()
+	if !ok || user != testUsername || password != testPassword {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == fmt.Sprintf(mqscPath, testQueueManager):
+		assert.NotEmpty(mq.t, req.Header.Get(csrfTokenHeader))
+		mq.handleCommand(w, req)
+	case req.Method == http.MethodGet && req.URL.Path == fmt.Sprintf(messageListPath, testQueueManager, path.Base(path.Dir(req.URL.Path))):
+		queue := path.Base(path.Dir(req.URL.Path))
+		messages := []map[string]string{}
+		for _, m := range mq.messages[queue] {
+			messages = append(messages, map[string]string{"messageId": m.id, "format": m.format})
+		}
+		writeJSON(w, map[string]interface{}{"messages": messages})
+	case req.Method == http.MethodGet && req.URL.Path == fmt.Sprintf(messagePath, testQueueManager, path.Base(path.Dir(req.URL.Path))):
+		queue := path.Base(path.Dir(req.URL.Path))
+		id := req.URL.Query().Get("messageId")
+		for _, m := range mq.messages[queue] {
+			if m.id == id {
+				mq.browsed = append(mq.browsed, id)
+				w.Header().Set("Content-Type", m.contentType)
+				_, _ = w.Write([]byte(m.body))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (mq *fakeMQ) handleCommand(w http.ResponseWriter, req *http.Request) {
+	var command mqscCommand
+	require.NoError(mq.t, json.NewDecoder(req.Body).Decode(&command))
+	assert.Equal(mq.t, "runCommandJSON", command.Type)
+	assert.Equal(mq.t, "display", command.Command)
+
+	objects := mq.queues
+	if command.Qualifier == "qstatus" {
+		objects = mq.statuses
+	}
+
+	responses := []map[string]interface{}{}
+	for name, params := range objects {
+		if name == command.Name ||
+			(strings.HasSuffix(command.Name, "*") && strings.HasPrefix(name, strings.TrimSuffix(command.Name, "*"))) {
+			responses = append(responses, map[string]interface{}{
+				"completionCode": 0,
+				"reasonCode":     0,
+				"parameters":     params,
+			})
+		}
+	}
+
+	if len(responses) == 0 {
+		writeJSON(w, map[string]interface{}{
+			"commandResponse": []map[string]interface{}{{
+				"completionCode": 2,
+				"reasonCode":     reasonUnknownObjectName,
+				"message":        []string{"AMQ8147E: IBM MQ object " + command.Name + " not found."},
+			}},
+			"overallCompletionCode": 2,
+			"overallReasonCode":     3008,
+		})
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"commandResponse":       responses,
+		"overallCompletionCode": 0,
+		"overallReasonCode":     0,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestQueueStatuses(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.addQueue("APP.ORDERS", 12, 5000, 1, 2, "37")
+	mq.addQueue("APP.PAYMENTS", 0, 5000, 0, 0, "")
+	mq.addQueue("OTHER", 1, 10, 0, 0, "")
+
+	statuses, err := client.queueStatuses(context.Background(), testQueueManager, "APP.*")
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	byName := map[string]queueStatus{}
+	for _, status := range statuses {
+		byName[status.name] = status
+	}
+	assert.Equal(t, queueStatus{
+		name:                "APP.ORDERS",
+		currentDepth:        12,
+		maxDepth:            5000,
+		openInputCount:      1,
+		openOutputCount:     2,
+		oldestMessageAge:    37,
+		hasOldestMessageAge: true,
+	}, byName["APP.ORDERS"])
+	assert.Equal(t, queueStatus{name: "APP.PAYMENTS", maxDepth: 5000}, byName["APP.PAYMENTS"])
+}
+
+func TestQueueStatusesNoMatchingQueue(t *testing.T) {
+	_, client := newFakeMQ(t)
+
+	statuses, err := client.queueStatuses(context.Background(), testQueueManager, "MISSING.*")
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+func TestQueueStatusesFailure(t *testing.T) {
+	_, client := newFakeMQ(t)
+	client.password = "wrong"
+
+	_, err := client.queueStatuses(context.Background(), testQueueManager, "*")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+
+	_, client = newFakeMQ(t)
+	_, err = client.queueStatuses(context.Background(), "UNKNOWN", "*")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+}
+
+func TestBrowseMessages(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.putMessages("APP.ORDERS",
+		fakeMessage{id: "414d5101", format: "MQSTR", contentType: "text/plain;charset=utf-8", body: "order 1"},
+		fakeMessage{id: "414d5102", format: "MQSTR", contentType: "text/plain;charset=utf-8", body: "order 2 with long body"},
+	)
+
+	messages, err := client.listMessages(context.Background(), testQueueManager, "APP.ORDERS")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, message{id: "414d5101", format: "MQSTR"}, messages[0])
+
+	found, err := client.browseMessage(context.Background(), testQueueManager, "APP.ORDERS", &messages[1], 7)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "order 2", string(messages[1].body))
+	assert.Equal(t, "text/plain;charset=utf-8", messages[1].contentType)
+
+	// the message is consumed before it's browsed
+	mq.getMessage("APP.ORDERS")
+	found, err = client.browseMessage(context.Background(), testQueueManager, "APP.ORDERS", &messages[0], 100)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestIntParameter(t *testing.T) {
+	params := map[string]interface{}{
+		"number":       json.Number("42"),
+		"string":       " 7 ",
+		"blank":        "",
+		"notanumber":   "abc",
+		"float":        json.Number("1.5"),
+		"unsupported":  true,
+		"negative":     json.Number("-1"),
+		"large_number": json.Number("9000000000"),
+	}
+
+	testcases := []struct {
+		name     string
+		expected int64
+		ok       bool
+	}{
+		{name: "number", expected: 42, ok: true},
+		{name: "string", expected: 7, ok: true},
+		{name: "blank"},
+		{name: "notanumber"},
+		{name: "float"},
+		{name: "unsupported"},
+		{name: "missing"},
+		{name: "negative", expected: -1, ok: true},
+		{name: "large_number", expected: 9000000000, ok: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := intParameter(params, tc.name)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+const (
+	defaultEndpoint       = "https://localhost:9443"
+	defaultQueuePattern   = "*"
+	defaultMaxMessages    = 10
+	defaultMaxMessageSize = 10 * 1024
+)
+
+// Config defines configuration for the receiver.
+type Config struct {
+	scraperhelper.ScraperControllerSettings `mapstructure:",squash"`
+	// HTTPClientSettings configure the connection to the mqweb server serving the IBM MQ REST API.
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+
+	// Username and Password are used for basic authentication to the REST API.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// QueueManagers are the names of the polled queue managers.
+	QueueManagers []string `mapstructure:"queue_managers"`
+	// Queues are the names of the local queues for which metrics are collected.
+	// A name ending with * matches queues by prefix.
+	Queues []string `mapstructure:"queues"`
+	// IncludeSystemQueues defines whether SYSTEM.* queues matching Queues are collected.
+	IncludeSystemQueues bool `mapstructure:"include_system_queues"`
+
+	// MessageSampling configures browsing of messages as logs, it's used by the logs receiver only.
+	MessageSampling MessageSamplingConfig `mapstructure:"message_sampling"`
+}
+
+// MessageSamplingConfig defines which messages are browsed.
+type MessageSamplingConfig struct {
+	// Queues are the names of the queues messages are browsed from.
+	Queues []string `mapstructure:"queues"`
+	// MaxMessages is the maximal number of messages browsed from a queue per collection interval.
+	MaxMessages int `mapstructure:"max_messages"`
+	// MaxMessageSize is the number of bytes message bodies are truncated to.
+	MaxMessageSize int `mapstructure:"max_message_size"`
+}
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ReceiverSettings.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.CollectionInterval <= 0 {
+		return errors.New("collection_interval must be positive")
+	}
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint cannot be empty")
+	}
+	if len(cfg.QueueManagers) == 0 {
+		return errors.New("at least one queue manager has to be configured")
+	}
+	for _, qmgr := range cfg.QueueManagers {
+		if qmgr == "" {
+			return errors.New("queue manager name cannot be empty")
+		}
+	}
+	if len(cfg.Queues) == 0 {
+		return errors.New("at least one queue has to be configured")
+	}
+	for _, queue := range cfg.Queues {
+		if queue == "" {
+			return errors.New("queue name cannot be empty")
+		}
+	}
+
+	for _, queue := range cfg.MessageSampling.Queues {
+		if queue == "" {
+			return errors.New("message_sampling queue name cannot be empty")
+		}
+	}
+	if cfg.MessageSampling.MaxMessages <= 0 {
+		return fmt.Errorf("message_sampling max_messages must be positive, got %d", cfg.MessageSampling.MaxMessages)
+	}
+	if cfg.MessageSampling.MaxMessageSize <= 0 {
+		return fmt.Errorf("message_sampling max_message_size must be positive, got %d", cfg.MessageSampling.MaxMessageSize)
+	}
+
+	return nil
+}
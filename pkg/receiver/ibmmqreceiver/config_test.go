@@ -0,0 +1,94 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	r0 := cfg.Receivers[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.QueueManagers = []string{"QM1"}
+	assert.Equal(t, r0, expected)
+
+	r1 := cfg.Receivers[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, r1,
+		&Config{
+			ScraperControllerSettings: scraperhelper.ScraperControllerSettings{
+				ReceiverSettings:   config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "custom")),
+				CollectionInterval: 30 * time.Second,
+			},
+			HTTPClientSettings: confighttp.HTTPClientSettings{
+				Endpoint: "https://mqweb.example.com:9443",
+			},
+			Username:            "monitoring",
+			Password:            "secret",
+			QueueManagers:       []string{"QM1", "QM2"},
+			Queues:              []string{"APP.*", "SYSTEM.DEAD.LETTER.QUEUE"},
+			IncludeSystemQueues: true,
+			MessageSampling: MessageSamplingConfig{
+				Queues:         []string{"APP.ORDERS"},
+				MaxMessages:    5,
+				MaxMessageSize: 1024,
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "zero collection interval", modify: func(cfg *Config) { cfg.CollectionInterval = 0 }},
+		{name: "no endpoint", modify: func(cfg *Config) { cfg.Endpoint = "" }},
+		{name: "no queue managers", modify: func(cfg *Config) { cfg.QueueManagers = nil }},
+		{name: "empty queue manager", modify: func(cfg *Config) { cfg.QueueManagers = []string{""} }},
+		{name: "no queues", modify: func(cfg *Config) { cfg.Queues = nil }},
+		{name: "empty queue", modify: func(cfg *Config) { cfg.Queues = []string{"APP.*", ""} }},
+		{name: "empty sampled queue", modify: func(cfg *Config) { cfg.MessageSampling.Queues = []string{""} }},
+		{name: "zero max messages", modify: func(cfg *Config) { cfg.MessageSampling.MaxMessages = 0 }},
+		{name: "zero max message size", modify: func(cfg *Config) { cfg.MessageSampling.MaxMessageSize = 0 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.QueueManagers = []string{"QM1"}
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
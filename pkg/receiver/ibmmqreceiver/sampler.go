@@ -0,0 +1,194 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	messageIDAttribute     = "ibmmq.message_id"
+	correlationIDAttribute = "ibmmq.correlation_id"
+	formatAttribute        = "ibmmq.format"
+	contentTypeAttribute   = "content_type"
+)
+
+// messageSampler periodically browses messages from the configured queues
+// and sends them as logs.
+type messageSampler struct {
+	cfg      *Config
+	client   *mqClient
+	consumer consumer.Logs
+	logger   *zap.Logger
+	set      component.TelemetrySettings
+
+	// seen holds IDs of sampled messages which were still on a queue during
+	// the last poll, so that messages staying on the queue are not sent repeatedly.
+	seen map[queueKey]map[string]struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	now func() time.Time
+}
+
+type queueKey struct {
+	qmgr  string
+	queue string
+}
+
+func newMessageSampler(cfg *Config, set component.ReceiverCreateSettings, consumer consumer.Logs) *messageSampler {
+	return &messageSampler{
+		cfg:      cfg,
+		consumer: consumer,
+		logger:   set.Logger,
+		set:      set.TelemetrySettings,
+		seen:     map[queueKey]map[string]struct{}{},
+		now:      time.Now,
+	}
+}
+
+// Start tells the receiver to start.
+func (s *messageSampler) Start(_ context.Context, host component.Host) error {
+	client, err := newMQClient(s.cfg, host, s.set)
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.wg.Add(1)
+	go s.run(ctx)
+	return nil
+}
+
+// Shutdown is invoked during service shutdown.
+func (s *messageSampler) Shutdown(context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+func (s *messageSampler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *messageSampler) poll(ctx context.Context) {
+	for _, qmgr := range s.cfg.QueueManagers {
+		logs := plog.NewLogs()
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().UpsertString(queueManagerAttribute, qmgr)
+		lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+		for _, queue := range s.cfg.MessageSampling.Queues {
+			messages, err := s.sample(ctx, qmgr, queue)
+			if err != nil {
+				s.logger.Error("Failed to sample messages",
+					zap.String("queue_manager", qmgr), zap.String("queue", queue), zap.Error(err))
+			}
+			appendMessageLogs(lrs, queue, messages, pcommon.NewTimestampFromTime(s.now()))
+		}
+
+		if lrs.Len() == 0 {
+			continue
+		}
+		if err := s.consumer.ConsumeLogs(ctx, logs); err != nil {
+			s.logger.Error("Failed to consume sampled messages", zap.String("queue_manager", qmgr), zap.Error(err))
+		}
+	}
+}
+
+// sample browses messages from the queue which were not sampled before.
+func (s *messageSampler) sample(ctx context.Context, qmgr string, queue string) ([]message, error) {
+	key := queueKey{qmgr: qmgr, queue: queue}
+	listed, err := s.client.listMessages(ctx, qmgr, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := s.seen[key]
+	// Only messages which were sent are remembered, messages skipped because
+	// of max_messages can be sampled in the next poll.
+	current := map[string]struct{}{}
+	defer func() { s.seen[key] = current }()
+
+	sampled := make([]message, 0, s.cfg.MessageSampling.MaxMessages)
+	for i := range listed {
+		msg := listed[i]
+		if _, ok := seen[msg.id]; ok {
+			current[msg.id] = struct{}{}
+			continue
+		}
+		if len(sampled) == s.cfg.MessageSampling.MaxMessages {
+			continue
+		}
+
+		found, err := s.client.browseMessage(ctx, qmgr, queue, &msg, s.cfg.MessageSampling.MaxMessageSize)
+		if err != nil {
+			return sampled, err
+		}
+		if found {
+			current[msg.id] = struct{}{}
+			sampled = append(sampled, msg)
+		}
+	}
+	return sampled, nil
+}
+
+func appendMessageLogs(lrs plog.LogRecordSlice, queue string, messages []message, now pcommon.Timestamp) {
+	for _, msg := range messages {
+		lr := lrs.AppendEmpty()
+		lr.SetObservedTimestamp(now)
+		lr.Body().SetStringVal(string(msg.body))
+
+		attrs := lr.Attributes()
+		attrs.UpsertString(queueAttribute, queue)
+		attrs.UpsertString(messageIDAttribute, msg.id)
+		if msg.correlationID != "" {
+			attrs.UpsertString(correlationIDAttribute, msg.correlationID)
+		}
+		if msg.format != "" {
+			attrs.UpsertString(formatAttribute, msg.format)
+		}
+		if msg.contentType != "" {
+			attrs.UpsertString(contentTypeAttribute, msg.contentType)
+		}
+	}
+}
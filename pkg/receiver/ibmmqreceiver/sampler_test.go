@@ -0,0 +1,145 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func newTestSampler(t *testing.T, client *mqClient, modify func(*Config)) (*messageSampler, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QueueManagers = []string{testQueueManager}
+	cfg.MessageSampling.Queues = []string{"APP.ORDERS"}
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.LogsSink)
+	sampler := newMessageSampler(cfg, componenttest.NewNopReceiverCreateSettings(), sink)
+	sampler.client = client
+	sampler.now = func() time.Time { return testNow }
+	return sampler, sink
+}
+
+func logBodies(sink *consumertest.LogsSink) []string {
+	bodies := []string{}
+	for _, logs := range sink.AllLogs() {
+		lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < lrs.Len(); i++ {
+			bodies = append(bodies, lrs.At(i).Body().StringVal())
+		}
+	}
+	return bodies
+}
+
+func TestSampleMessages(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.putMessages("APP.ORDERS",
+		fakeMessage{id: "414d5101", format: "MQSTR", contentType: "text/plain", body: "order 1"},
+		fakeMessage{id: "414d5102", format: "MQHRF2", body: "order 2"},
+	)
+
+	sampler, sink := newTestSampler(t, client, nil)
+	sampler.poll(context.Background())
+
+	require.Equal(t, 1, len(sink.AllLogs()))
+	logs := sink.AllLogs()[0]
+	qmgr, ok := logs.ResourceLogs().At(0).Resource().Attributes().Get(queueManagerAttribute)
+	require.True(t, ok)
+	assert.Equal(t, testQueueManager, qmgr.StringVal())
+
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, lrs.Len())
+
+	lr := lrs.At(0)
+	assert.Equal(t, "order 1", lr.Body().StringVal())
+	assert.Equal(t, testNow, lr.ObservedTimestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{
+		queueAttribute:       "APP.ORDERS",
+		messageIDAttribute:   "414d5101",
+		formatAttribute:      "MQSTR",
+		contentTypeAttribute: "text/plain",
+	}, lr.Attributes().AsRaw())
+}
+
+func TestSampleMessagesOnlyOnce(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.putMessages("APP.ORDERS",
+		fakeMessage{id: "1", body: "order 1"},
+		fakeMessage{id: "2", body: "order 2"},
+		fakeMessage{id: "3", body: "order 3"},
+	)
+
+	sampler, sink := newTestSampler(t, client, func(cfg *Config) {
+		cfg.MessageSampling.MaxMessages = 2
+	})
+
+	sampler.poll(context.Background())
+	assert.Equal(t, []string{"order 1", "order 2"}, logBodies(sink))
+
+	// messages skipped because of max_messages are sampled later
+	sampler.poll(context.Background())
+	assert.Equal(t, []string{"order 1", "order 2", "order 3"}, logBodies(sink))
+
+	// nothing new on the queue
+	sampler.poll(context.Background())
+	assert.Len(t, sink.AllLogs(), 2)
+	assert.Equal(t, []string{"1", "2", "3"}, mq.browsedMessages())
+
+	mq.getMessage("APP.ORDERS")
+	mq.putMessages("APP.ORDERS", fakeMessage{id: "4", body: "order 4"})
+	sampler.poll(context.Background())
+	assert.Equal(t, []string{"order 1", "order 2", "order 3", "order 4"}, logBodies(sink))
+}
+
+func TestSampleMessagesFailure(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.putMessages("APP.ORDERS", fakeMessage{id: "1", body: "order 1"})
+
+	sampler, sink := newTestSampler(t, client, func(cfg *Config) {
+		cfg.MessageSampling.Queues = []string{"APP.ORDERS"}
+		cfg.QueueManagers = []string{"UNKNOWN", testQueueManager}
+	})
+	sampler.poll(context.Background())
+
+	// the failing queue manager doesn't prevent sampling of the others
+	assert.Equal(t, []string{"order 1"}, logBodies(sink))
+}
+
+func TestSamplerStartAndShutdown(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.putMessages("APP.ORDERS", fakeMessage{id: "1", body: "order 1"})
+
+	sampler, sink := newTestSampler(t, client, func(cfg *Config) {
+		cfg.Endpoint = client.endpoint
+		cfg.Username = testUsername
+		cfg.Password = testPassword
+		cfg.CollectionInterval = 10 * time.Millisecond
+	})
+	require.NoError(t, sampler.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, sampler.Shutdown(context.Background()))
+}
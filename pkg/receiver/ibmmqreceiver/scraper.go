@@ -0,0 +1,143 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+	"go.uber.org/zap"
+)
+
+const (
+	queueManagerAttribute = "ibmmq.queue_manager"
+	queueAttribute        = "queue"
+	systemQueuePrefix     = "SYSTEM."
+)
+
+type mqScraper struct {
+	cfg    *Config
+	client *mqClient
+	logger *zap.Logger
+	set    component.TelemetrySettings
+
+	now func() time.Time
+}
+
+func newMQScraper(cfg *Config, set component.ReceiverCreateSettings) *mqScraper {
+	return &mqScraper{
+		cfg:    cfg,
+		logger: set.Logger,
+		set:    set.TelemetrySettings,
+		now:    time.Now,
+	}
+}
+
+func (s *mqScraper) start(_ context.Context, host component.Host) error {
+	client, err := newMQClient(s.cfg, host, s.set)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+func (s *mqScraper) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	now := pcommon.NewTimestampFromTime(s.now())
+	errs := &scrapererror.ScrapeErrors{}
+
+	for _, qmgr := range s.cfg.QueueManagers {
+		statuses := map[string]queueStatus{}
+		for _, pattern := range s.cfg.Queues {
+			queues, err := s.client.queueStatuses(ctx, qmgr, pattern)
+			if err != nil {
+				errs.AddPartial(1, err)
+				continue
+			}
+			for _, queue := range queues {
+				if !s.cfg.IncludeSystemQueues && strings.HasPrefix(queue.name, systemQueuePrefix) {
+					continue
+				}
+				statuses[queue.name] = queue
+			}
+		}
+
+		if len(statuses) == 0 {
+			continue
+		}
+		appendQueueMetrics(metrics.ResourceMetrics().AppendEmpty(), qmgr, statuses, now)
+	}
+
+	return metrics, errs.Combine()
+}
+
+func appendQueueMetrics(rm pmetric.ResourceMetrics, qmgr string, statuses map[string]queueStatus, now pcommon.Timestamp) {
+	rm.Resource().Attributes().UpsertString(queueManagerAttribute, qmgr)
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	depth := newGauge(ms, "ibmmq.queue.depth", "Current number of messages on the queue.", "{messages}")
+	maxDepth := newGauge(ms, "ibmmq.queue.max_depth", "Maximal number of messages allowed on the queue.", "{messages}")
+	openInput := newGauge(ms, "ibmmq.queue.open_input_count", "Number of handles open for input on the queue.", "{handles}")
+	openOutput := newGauge(ms, "ibmmq.queue.open_output_count", "Number of handles open for output on the queue.", "{handles}")
+	age := newGauge(ms, "ibmmq.queue.oldest_message_age", "Age of the oldest message on the queue.", "s")
+
+	for _, name := range sortedQueueNames(statuses) {
+		status := statuses[name]
+		appendDataPoint(depth, name, status.currentDepth, now)
+		appendDataPoint(maxDepth, name, status.maxDepth, now)
+		appendDataPoint(openInput, name, status.openInputCount, now)
+		appendDataPoint(openOutput, name, status.openOutputCount, now)
+		if status.hasOldestMessageAge {
+			appendDataPoint(age, name, status.oldestMessageAge, now)
+		}
+	}
+
+	// queue monitoring is not enabled for any of the queues
+	if age.Gauge().DataPoints().Len() == 0 {
+		ms.RemoveIf(func(m pmetric.Metric) bool { return m.Name() == age.Name() })
+	}
+}
+
+func newGauge(ms pmetric.MetricSlice, name string, description string, unit string) pmetric.Metric {
+	m := ms.AppendEmpty()
+	m.SetName(name)
+	m.SetDescription(description)
+	m.SetUnit(unit)
+	m.SetDataType(pmetric.MetricDataTypeGauge)
+	return m
+}
+
+func appendDataPoint(m pmetric.Metric, queue string, value int64, now pcommon.Timestamp) {
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetIntVal(value)
+	dp.Attributes().UpsertString(queueAttribute, queue)
+}
+
+func sortedQueueNames(statuses map[string]queueStatus) []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
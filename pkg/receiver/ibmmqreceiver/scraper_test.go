@@ -0,0 +1,125 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestScraper(t *testing.T, client *mqClient, modify func(*Config)) *mqScraper {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QueueManagers = []string{testQueueManager}
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	scraper := newMQScraper(cfg, componenttest.NewNopReceiverCreateSettings())
+	scraper.client = client
+	scraper.now = func() time.Time { return testNow }
+	return scraper
+}
+
+// gaugeValues returns values of the gauge data points by queue name.
+func gaugeValues(t *testing.T, ms pmetric.MetricSlice, name string) map[string]int64 {
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		if m.Name() != name {
+			continue
+		}
+
+		values := map[string]int64{}
+		dps := m.Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			queue, ok := dps.At(j).Attributes().Get(queueAttribute)
+			require.True(t, ok)
+			assert.Equal(t, pcommon.NewTimestampFromTime(testNow), dps.At(j).Timestamp())
+			values[queue.StringVal()] = dps.At(j).IntVal()
+		}
+		return values
+	}
+	return nil
+}
+
+func TestScrape(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.addQueue("APP.ORDERS", 12, 5000, 1, 2, "37")
+	mq.addQueue("APP.PAYMENTS", 0, 5000, 0, 3, "")
+	mq.addQueue("SYSTEM.DEAD.LETTER.QUEUE", 4, 5000, 0, 0, "")
+
+	scraper := newTestScraper(t, client, nil)
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	rm := metrics.ResourceMetrics().At(0)
+	qmgr, ok := rm.Resource().Attributes().Get(queueManagerAttribute)
+	require.True(t, ok)
+	assert.Equal(t, testQueueManager, qmgr.StringVal())
+
+	ms := rm.ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, 5, ms.Len())
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 12, "APP.PAYMENTS": 0}, gaugeValues(t, ms, "ibmmq.queue.depth"))
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 5000, "APP.PAYMENTS": 5000}, gaugeValues(t, ms, "ibmmq.queue.max_depth"))
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 1, "APP.PAYMENTS": 0}, gaugeValues(t, ms, "ibmmq.queue.open_input_count"))
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 2, "APP.PAYMENTS": 3}, gaugeValues(t, ms, "ibmmq.queue.open_output_count"))
+	// the age is not available for queues without monitoring
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 37}, gaugeValues(t, ms, "ibmmq.queue.oldest_message_age"))
+}
+
+func TestScrapeSystemQueues(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.addQueue("APP.ORDERS", 12, 5000, 1, 2, "")
+	mq.addQueue("SYSTEM.DEAD.LETTER.QUEUE", 4, 5000, 0, 0, "")
+
+	scraper := newTestScraper(t, client, func(cfg *Config) {
+		cfg.Queues = []string{"APP.ORDERS", "SYSTEM.DEAD.LETTER.QUEUE"}
+		cfg.IncludeSystemQueues = true
+	})
+	metrics, err := scraper.scrape(context.Background())
+	require.NoError(t, err)
+
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 12, "SYSTEM.DEAD.LETTER.QUEUE": 4}, gaugeValues(t, ms, "ibmmq.queue.depth"))
+	// queue monitoring is not enabled for any of the queues
+	assert.Nil(t, gaugeValues(t, ms, "ibmmq.queue.oldest_message_age"))
+}
+
+func TestScrapePartialFailure(t *testing.T) {
+	mq, client := newFakeMQ(t)
+	mq.addQueue("APP.ORDERS", 12, 5000, 1, 2, "")
+
+	scraper := newTestScraper(t, client, func(cfg *Config) {
+		cfg.QueueManagers = []string{testQueueManager, "UNKNOWN"}
+	})
+	metrics, err := scraper.scrape(context.Background())
+	require.Error(t, err)
+	assert.True(t, scrapererror.IsPartialScrapeError(err))
+
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, map[string]int64{"APP.ORDERS": 12}, gaugeValues(t, ms, "ibmmq.queue.depth"))
+}
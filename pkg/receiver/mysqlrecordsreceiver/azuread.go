@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"go.uber.org/zap"
+)
+
+const (
+	//azureADScope is the scope of the Azure AD access tokens accepted by Azure Database for MySQL
+	azureADScope = "https://ossrdbms-aad.database.windows.net/.default"
+	//azureADTokenRefreshMargin is the time before the expiry of the access token a new token is requested at
+	azureADTokenRefreshMargin = 5 * time.Minute
+)
+
+//azureADPassword uses the Azure AD access token of the managed identity or of the service principal as the password,
+//the token is cached and requested again shortly before it expires, so the new connections always get a valid token
+type azureADPassword struct {
+	newCredential func() (azcore.TokenCredential, error)
+	logger        *zap.Logger
+	now           func() time.Time
+
+	lock       sync.Mutex
+	credential azcore.TokenCredential
+	token      string
+	expiresOn  time.Time
+}
+
+func newAzureADPassword(conf *Config, logger *zap.Logger) *azureADPassword {
+	return &azureADPassword{
+		newCredential: func() (azcore.TokenCredential, error) {
+			return newAzureADCredential(conf)
+		},
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+//This function returns the credential of the service principal when azure_client_secret is set, the credential of the managed identity otherwise,
+//azure_client_id selects the user-assigned managed identity, the system-assigned one is used if it's not set
+func newAzureADCredential(conf *Config) (azcore.TokenCredential, error) {
+	if len(conf.AzureClientSecret) != 0 {
+		return azidentity.NewClientSecretCredential(conf.AzureTenantID, conf.AzureClientID, conf.AzureClientSecret, nil)
+	}
+	var options *azidentity.ManagedIdentityCredentialOptions
+	if len(conf.AzureClientID) != 0 {
+		options = &azidentity.ManagedIdentityCredentialOptions{ID: azidentity.ClientID(conf.AzureClientID)}
+	}
+	return azidentity.NewManagedIdentityCredential(options)
+}
+
+//This function returns the cached access token until it's about to expire, the cached token is used while it's still valid if a new one cannot be requested
+func (a *azureADPassword) get(ctx context.Context) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	now := a.now()
+	if len(a.token) != 0 && now.Add(azureADTokenRefreshMargin).Before(a.expiresOn) {
+		return a.token, nil
+	}
+	token, err := a.requestToken(ctx)
+	if err != nil {
+		if len(a.token) == 0 || !now.Before(a.expiresOn) {
+			return "", fmt.Errorf("failed to get Azure AD access token: %w", err)
+		}
+		a.logger.Warn("Failed to refresh Azure AD access token, using the cached token", zap.Time("expiresOn", a.expiresOn), zap.Error(err))
+		return a.token, nil
+	}
+	a.token = token.Token
+	a.expiresOn = token.ExpiresOn
+	return a.token, nil
+}
+
+func (a *azureADPassword) requestToken(ctx context.Context) (azcore.AccessToken, error) {
+	if a.credential == nil {
+		credential, err := a.newCredential()
+		if err != nil {
+			return azcore.AccessToken{}, err
+		}
+		a.credential = credential
+	}
+	return a.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureADScope}})
+}
+
+//This function validates the options of 'AzureADAuth', the password is replaced by the Azure AD access token
+func validateAzureADAuth(cfg *Config) error {
+	if cfg.AuthenticationMode != "AzureADAuth" {
+		if len(cfg.AzureTenantID) != 0 || len(cfg.AzureClientID) != 0 || len(cfg.AzureClientSecret) != 0 {
+			return errors.New("azure_tenant_id, azure_client_id and azure_client_secret can only be used with authentication_mode : 'AzureADAuth'")
+		}
+		return nil
+	}
+	var err error
+	if len(cfg.AzureClientSecret) != 0 && (len(cfg.AzureTenantID) == 0 || len(cfg.AzureClientID) == 0) {
+		err = errors.New("azure_tenant_id and azure_client_id are required with azure_client_secret for authentication_mode : 'AzureADAuth'")
+	}
+	if len(cfg.AzureClientSecret) == 0 && len(cfg.AzureTenantID) != 0 {
+		err = errors.New("azure_tenant_id can only be used with azure_client_secret for authentication_mode : 'AzureADAuth'")
+	}
+	if len(cfg.Password) != 0 || len(cfg.PasswordType) != 0 || len(cfg.EncryptSecretPath) != 0 || len(cfg.PasswordEnv) != 0 || len(cfg.PasswordFile) != 0 ||
+		(len(cfg.PasswordSource) != 0 && cfg.PasswordSource != passwordSourceConfig) {
+		err = errors.New("the password settings should be empty for authentication_mode : 'AzureADAuth'")
+	}
+	return err
+}
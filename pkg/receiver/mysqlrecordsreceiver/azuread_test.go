@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockAzureCredential struct {
+	tokens []azcore.AccessToken
+	err    error
+	scopes []string
+	calls  int
+}
+
+func (m *mockAzureCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	m.calls++
+	m.scopes = options.Scopes
+	if m.err != nil {
+		return azcore.AccessToken{}, m.err
+	}
+	token := m.tokens[0]
+	if len(m.tokens) > 1 {
+		m.tokens = m.tokens[1:]
+	}
+	return token, nil
+}
+
+func TestAzureADPasswordRefresh(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	credential := &mockAzureCredential{tokens: []azcore.AccessToken{
+		{Token: "first", ExpiresOn: now.Add(time.Hour)},
+		{Token: "second", ExpiresOn: now.Add(2 * time.Hour)},
+	}}
+	password := &azureADPassword{
+		newCredential: func() (azcore.TokenCredential, error) {
+			return credential, nil
+		},
+		logger: zap.NewNop(),
+		now: func() time.Time {
+			return now
+		},
+	}
+
+	token, err := password.get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "first", token)
+	require.Equal(t, []string{azureADScope}, credential.scopes)
+
+	// the token is cached until it's about to expire
+	now = now.Add(50 * time.Minute)
+	token, err = password.get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "first", token)
+	require.Equal(t, 1, credential.calls)
+
+	now = now.Add(6 * time.Minute)
+	token, err = password.get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "second", token)
+	require.Equal(t, 2, credential.calls)
+
+	// the cached token is used while it's still valid
+	now = now.Add(58 * time.Minute)
+	credential.err = errors.New("identity endpoint unavailable")
+	token, err = password.get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "second", token)
+
+	now = now.Add(10 * time.Minute)
+	_, err = password.get(context.Background())
+	require.Error(t, err)
+}
+
+func TestAzureADAuthConnectionString(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "AzureADAuth"
+	cfg.Username = "collector@orders"
+	cfg.DBHost = "orders.mysql.database.azure.com"
+	cfg.DBPort = "3306"
+	cfg.Transport = "tcp"
+	cfg.Database = "app"
+	cfg.Password = "access-token"
+
+	connStr, err := mySQLDriver{}.connectionString(cfg, nil, zap.NewNop())
+	require.NoError(t, err)
+	require.Contains(t, connStr, "collector@orders:access-token@tcp(orders.mysql.database.azure.com:3306)/app")
+	require.Contains(t, connStr, "allowCleartextPasswords=true")
+	require.Contains(t, connStr, "tls=true")
+}
+
+func TestValidateAzureADAuth(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "AzureADAuth"
+	cfg.Username = "collector@orders"
+	cfg.DBHost = "orders.mysql.database.azure.com"
+	cfg.Database = "app"
+	require.NoError(t, cfg.Validate())
+
+	// the user-assigned managed identity
+	cfg.AzureClientID = "00000000-0000-0000-0000-000000000001"
+	require.NoError(t, cfg.Validate())
+
+	// the service principal
+	cfg.AzureTenantID = "00000000-0000-0000-0000-000000000002"
+	cfg.AzureClientSecret = "client-secret"
+	require.NoError(t, cfg.Validate())
+
+	cfg.AzureClientID = ""
+	require.Error(t, cfg.Validate())
+
+	cfg.AzureClientID = "00000000-0000-0000-0000-000000000001"
+	cfg.AzureClientSecret = ""
+	require.Error(t, cfg.Validate())
+
+	cfg.AzureTenantID = ""
+	cfg.Password = "userpass"
+	require.Error(t, cfg.Validate())
+
+	cfg.Password = ""
+	cfg.DBDriver = dbDriverPostgres
+	require.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "app"
+	cfg.AzureClientID = "00000000-0000-0000-0000-000000000001"
+	require.Error(t, cfg.Validate())
+}
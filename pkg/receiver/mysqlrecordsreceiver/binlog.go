@@ -0,0 +1,377 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	//binlogOperationAttribute is the attribute with the operation of the captured row change, i.e. insert, update or delete
+	binlogOperationAttribute = "mysql.cdc.operation"
+	//binlogTableAttribute is the attribute with the qualified name of the table of the captured row change, i.e. schema.table
+	binlogTableAttribute = "mysql.cdc.table"
+	//binlogPositionAttribute is the attribute with the binlog position of the captured row change, i.e. file:position
+	binlogPositionAttribute = "mysql.cdc.position"
+
+	binlogOperationInsert = "insert"
+	binlogOperationUpdate = "update"
+	binlogOperationDelete = "delete"
+
+	//binlogHeartbeatPeriod is the period the server sends heartbeats at while there are no events, so a broken connection is detected by the read timeout
+	binlogHeartbeatPeriod = 30 * time.Second
+	binlogReadTimeout     = 90 * time.Second
+)
+
+// BinlogCDC enables capturing the row changes of the tables from the binlog of the MySQL server, as a replica does
+type BinlogCDC struct {
+	//ServerID is the server id the receiver connects to the server with as a replica, it has to differ from the server ids of the server and its other replicas
+	ServerID uint32 `mapstructure:"server_id"`
+	//Tables are the tables whose row changes are captured, either schema.table or the name of a table of the database, all the tables of the database if it's not set
+	Tables []string `mapstructure:"tables,omitempty"`
+}
+
+// This function validates binlog_cdc, the binlog is read from dbhost directly with the 'BasicAuth' credentials
+func validateBinlogCDC(cfg *Config) error {
+	if cfg.BinlogCDC == nil {
+		return nil
+	}
+	var err error
+	if cfg.DBDriver != dbDriverMySQL {
+		err = multierr.Append(err, fmt.Errorf("binlog_cdc cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if cfg.BinlogCDC.ServerID == 0 {
+		err = multierr.Append(err, errors.New("binlog_cdc server_id has to be set to a server id which isn't used by the server or its replicas"))
+	}
+	if cfg.AuthenticationMode != "BasicAuth" {
+		err = multierr.Append(err, errors.New("binlog_cdc can only be used with authentication_mode : 'BasicAuth'"))
+	}
+	if len(cfg.DBHosts) != 0 || len(cfg.ProxyURL) != 0 || cfg.ProxyFromEnvironment || cfg.SSHTunnel != nil || (len(cfg.Transport) != 0 && cfg.Transport != "tcp") {
+		err = multierr.Append(err, errors.New("binlog_cdc cannot be used with dbhosts, proxy_url, proxy_from_environment, ssh_tunnel or the 'unix' transport, the binlog is read from dbhost"))
+	}
+	//The replicas of the targets would connect to the same server with the same server id
+	if len(cfg.Targets) != 0 {
+		err = multierr.Append(err, errors.New("binlog_cdc cannot be used with targets"))
+	}
+	for _, table := range cfg.BinlogCDC.Tables {
+		if len(strings.TrimSpace(table)) == 0 {
+			err = multierr.Append(err, errors.New("binlog_cdc tables cannot contain an empty table"))
+		}
+	}
+	return err
+}
+
+// This function returns the qualified names of the captured tables, or nil if all the tables of the database are captured
+func binlogTables(cfg *Config) map[string]bool {
+	if len(cfg.BinlogCDC.Tables) == 0 {
+		return nil
+	}
+	tables := make(map[string]bool)
+	for _, table := range cfg.BinlogCDC.Tables {
+		if !strings.Contains(table, ".") {
+			table = cfg.Database + "." + table
+		}
+		tables[table] = true
+	}
+	return tables
+}
+
+// This function returns whether the row changes of the table are captured
+func isBinlogTable(cfg *Config, tables map[string]bool, schema string, table string) bool {
+	if tables == nil {
+		return schema == cfg.Database
+	}
+	return tables[schema+"."+table]
+}
+
+// This function starts capturing the row changes from the binlog until the receiver is shut down
+func (m *mySQLReceiver) startBinlogCDC(loadAWSConfig awsConfigLoader) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopBinlogCDC = cancel
+	m.collectors.Add(1)
+	go m.tailBinlog(ctx, loadAWSConfig)
+}
+
+// This function reads the binlog from the saved position, the reading is resumed with the reconnect backoff once the connection fails
+func (m *mySQLReceiver) tailBinlog(ctx context.Context, loadAWSConfig awsConfigLoader) {
+	defer m.collectors.Done()
+
+	b := m.config.Reconnect.newBackOff()
+	//The binlog is read until the receiver is shut down
+	b.MaxElapsedTime = 0
+	m.logger.Info("Capturing row changes from the binlog", zap.Uint32("serverId", m.config.BinlogCDC.ServerID))
+	for {
+		received, err := m.streamBinlog(ctx, loadAWSConfig)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			b.Reset()
+		}
+		wait := b.NextBackOff()
+		m.logger.Error("Failed to read the binlog, resuming from the saved position", zap.Duration("retryIn", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// This function reads the binlog from the saved position and passes the row changes of the captured tables to the consumer
+// The position is saved after every transaction and binlog rotation, it returns whether any event was received and the error stopping the reading
+func (m *mySQLReceiver) streamBinlog(ctx context.Context, loadAWSConfig awsConfigLoader) (bool, error) {
+	position, err := m.binlogStartPosition()
+	if err != nil {
+		return false, fmt.Errorf("failed to get the binlog position: %w", err)
+	}
+	syncerConfig, err := m.binlogSyncerConfig(ctx, loadAWSConfig)
+	if err != nil {
+		return false, err
+	}
+	syncer := replication.NewBinlogSyncer(syncerConfig)
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(position)
+	if err != nil {
+		return false, fmt.Errorf("failed to start reading the binlog at %s:%d: %w", position.Name, position.Pos, err)
+	}
+
+	tables := binlogTables(m.config)
+	var received bool
+	for {
+		event, err := streamer.GetEvent(ctx)
+		if err != nil {
+			return received, err
+		}
+		received = true
+		switch e := event.Event.(type) {
+		case *replication.RotateEvent:
+			position = mysql.Position{Name: string(e.NextLogName), Pos: uint32(e.Position)}
+			m.saveBinlogPosition(position)
+		case *replication.XIDEvent:
+			position.Pos = event.Header.LogPos
+			m.saveBinlogPosition(position)
+		case *replication.RowsEvent:
+			if !isBinlogTable(m.config, tables, string(e.Table.Schema), string(e.Table.Table)) {
+				continue
+			}
+			operation, ok := binlogOperation(event.Header.EventType)
+			if !ok {
+				continue
+			}
+			changePosition := mysql.Position{Name: position.Name, Pos: event.Header.LogPos}
+			if err := m.consumeRowChanges(ctx, e, operation, changePosition, time.Unix(int64(event.Header.Timestamp), 0)); err != nil {
+				m.logger.Error("Failed to consume row changes", zap.String("table", string(e.Table.Schema)+"."+string(e.Table.Table)), zap.Error(err))
+			}
+		}
+	}
+}
+
+// This function returns the position the binlog is read from, the saved position or the current position of the server if there's none
+func (m *mySQLReceiver) binlogStartPosition() (mysql.Position, error) {
+	if position, ok := GetBinlogPosition(m.config, m.logger); ok {
+		return position, nil
+	}
+	name, pos, err := m.sqlclient.getBinlogPosition()
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	m.logger.Info("No saved binlog position, capturing the row changes from the current position", zap.String("file", name), zap.Uint32("position", pos))
+	return mysql.Position{Name: name, Pos: pos}, nil
+}
+
+func (m *mySQLReceiver) saveBinlogPosition(position mysql.Position) {
+	if err := SaveBinlogPosition(m.config, position, m.logger); err != nil {
+		m.logger.Error("Failed to save the binlog position", zap.String("file", position.Name), zap.Uint32("position", position.Pos), zap.Error(err))
+	}
+}
+
+// This function returns the config of the binlog reader, the replica connection uses the credentials and the tls settings of the receiver
+func (m *mySQLReceiver) binlogSyncerConfig(ctx context.Context, loadAWSConfig awsConfigLoader) (replication.BinlogSyncerConfig, error) {
+	port := defaultMySQLPort
+	if len(m.config.DBPort) != 0 {
+		port = m.config.DBPort
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return replication.BinlogSyncerConfig{}, fmt.Errorf("invalid dbport '%s': %w", port, err)
+	}
+	password, err := m.binlogPassword(ctx, loadAWSConfig)
+	if err != nil {
+		return replication.BinlogSyncerConfig{}, fmt.Errorf("failed to get the password: %w", err)
+	}
+	syncerConfig := replication.BinlogSyncerConfig{
+		ServerID:        m.config.BinlogCDC.ServerID,
+		Flavor:          "mysql",
+		Host:            m.config.DBHost,
+		Port:            uint16(portNumber),
+		User:            m.config.Username,
+		Password:        password,
+		HeartbeatPeriod: binlogHeartbeatPeriod,
+		ReadTimeout:     binlogReadTimeout,
+	}
+	if m.config.TLS != nil {
+		tlsConf, err := m.config.TLS.loadTLSConfig()
+		if err != nil {
+			return replication.BinlogSyncerConfig{}, err
+		}
+		//The certificate of the server is verified against dbhost, unless the server name is set
+		if len(tlsConf.ServerName) == 0 {
+			tlsConf.ServerName = m.config.DBHost
+		}
+		syncerConfig.TLSConfig = tlsConf
+	}
+	return syncerConfig, nil
+}
+
+// This function returns the current password of 'BasicAuth', the binlog reader connects with it every time the reading is resumed
+func (m *mySQLReceiver) binlogPassword(ctx context.Context, loadAWSConfig awsConfigLoader) (string, error) {
+	if password := newPasswordProvider(m.config, loadAWSConfig, m.logger); password != nil {
+		return password.get(ctx)
+	}
+	if len(m.config.PasswordEnv) != 0 {
+		return envPassword(m.config)
+	}
+	return basicAuthPassword(m.config, m.logger), nil
+}
+
+// This function returns the operation of the rows event type, ok is false for the other events
+func binlogOperation(eventType replication.EventType) (string, bool) {
+	switch eventType {
+	case replication.WRITE_ROWS_EVENTv0, replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2:
+		return binlogOperationInsert, true
+	case replication.UPDATE_ROWS_EVENTv0, replication.UPDATE_ROWS_EVENTv1, replication.UPDATE_ROWS_EVENTv2:
+		return binlogOperationUpdate, true
+	case replication.DELETE_ROWS_EVENTv0, replication.DELETE_ROWS_EVENTv1, replication.DELETE_ROWS_EVENTv2:
+		return binlogOperationDelete, true
+	}
+	return "", false
+}
+
+// This function passes the row changes of the rows event to the consumer, as a log record for each changed row
+func (m *mySQLReceiver) consumeRowChanges(ctx context.Context, e *replication.RowsEvent, operation string, position mysql.Position, timestamp time.Time) error {
+	changes, err := binlogRowChanges(e, operation, newNullFormat(m.config), m.config.TypedValues)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	ld, lrs := m.newLogs()
+	observedTimestamp := pcommon.NewTimestampFromTime(time.Now())
+	for _, change := range changes {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStringVal(change)
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		lr.SetObservedTimestamp(observedTimestamp)
+		setBinlogAttributes(lr, e, operation, position)
+	}
+	return m.consumeLogs(ctx, ld)
+}
+
+func setBinlogAttributes(lr plog.LogRecord, e *replication.RowsEvent, operation string, position mysql.Position) {
+	lr.Attributes().UpsertString(binlogOperationAttribute, operation)
+	lr.Attributes().UpsertString(binlogTableAttribute, string(e.Table.Schema)+"."+string(e.Table.Table))
+	lr.Attributes().UpsertString(binlogPositionAttribute, position.Name+":"+strconv.FormatUint(uint64(position.Pos), 10))
+}
+
+// This function converts the rows of the rows event into json records of the row changes, with the row before and after the change
+// The rows of update events are pairs of the row before and after the change
+func binlogRowChanges(e *replication.RowsEvent, operation string, nulls nullFormat, typedValues bool) ([]string, error) {
+	columns := binlogColumns(e)
+	step := 1
+	if operation == binlogOperationUpdate {
+		step = 2
+	}
+	var changes []string
+	for i := 0; i+step <= len(e.Rows); i += step {
+		change := map[string]interface{}{
+			"operation": operation,
+			"database":  string(e.Table.Schema),
+			"table":     string(e.Table.Table),
+		}
+		switch operation {
+		case binlogOperationInsert:
+			change["after"] = binlogRow(columns, e.Rows[i], nulls, typedValues)
+		case binlogOperationUpdate:
+			change["before"] = binlogRow(columns, e.Rows[i], nulls, typedValues)
+			change["after"] = binlogRow(columns, e.Rows[i+1], nulls, typedValues)
+		case binlogOperationDelete:
+			change["before"] = binlogRow(columns, e.Rows[i], nulls, typedValues)
+		}
+		record, err := json.Marshal(change)
+		if err != nil {
+			return nil, fmt.Errorf("error converting row change into json format: %w", err)
+		}
+		changes = append(changes, string(record))
+	}
+	return changes, nil
+}
+
+// This function returns the names of the columns of the table of the rows event
+// The names are only written to the binlog with binlog_row_metadata=FULL, the columns are named by their position otherwise, e.g. column1
+func binlogColumns(e *replication.RowsEvent) []string {
+	names := e.Table.ColumnNameString()
+	columns := make([]string, e.ColumnCount)
+	for i := range columns {
+		if i < len(names) && len(names[i]) != 0 {
+			columns[i] = names[i]
+		} else {
+			columns[i] = "column" + strconv.Itoa(i+1)
+		}
+	}
+	return columns
+}
+
+// This function converts the values of the row into a json object, the values are strings unless typed_values is enabled
+func binlogRow(columns []string, row []interface{}, nulls nullFormat, typedValues bool) map[string]interface{} {
+	jsonObject := make(map[string]interface{})
+	for i, value := range row {
+		column := "column" + strconv.Itoa(i+1)
+		if i < len(columns) {
+			column = columns[i]
+		}
+		switch v := value.(type) {
+		case nil:
+			nulls.setNull(jsonObject, column)
+		case []byte:
+			jsonObject[column] = string(v)
+		case string:
+			jsonObject[column] = v
+		case time.Time:
+			jsonObject[column] = v.UTC().Format(time.RFC3339Nano)
+		default:
+			if typedValues {
+				jsonObject[column] = v
+			} else {
+				jsonObject[column] = fmt.Sprint(v)
+			}
+		}
+	}
+	return jsonObject
+}
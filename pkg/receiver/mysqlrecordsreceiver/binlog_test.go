@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func testBinlogConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "replicator"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.BinlogCDC = &BinlogCDC{ServerID: 1001}
+	return cfg
+}
+
+func testRowsEvent(rows ...[]interface{}) *replication.RowsEvent {
+	return &replication.RowsEvent{
+		Table: &replication.TableMapEvent{
+			Schema:      []byte("sales"),
+			Table:       []byte("orders"),
+			ColumnCount: 3,
+			ColumnName:  [][]byte{[]byte("OrderID"), []byte("Status"), []byte("Total")},
+		},
+		ColumnCount: 3,
+		Rows:        rows,
+	}
+}
+
+func TestValidConfigWBinlogCDC(t *testing.T) {
+	cfg := testBinlogConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.BinlogCDC.Tables = []string{"orders", "billing.invoices"}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateBinlogCDC(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "postgres",
+			modify: func(cfg *Config) { cfg.DBDriver = dbDriverPostgres },
+			err:    "binlog_cdc cannot be used with db_driver : 'postgres'",
+		},
+		{
+			name:   "no server id",
+			modify: func(cfg *Config) { cfg.BinlogCDC.ServerID = 0 },
+			err:    "binlog_cdc server_id has to be set to a server id which isn't used by the server or its replicas",
+		},
+		{
+			name:   "IAM authentication",
+			modify: func(cfg *Config) { cfg.AuthenticationMode = "IAMRDSAuth" },
+			err:    "binlog_cdc can only be used with authentication_mode : 'BasicAuth'",
+		},
+		{
+			name:   "dbhosts",
+			modify: func(cfg *Config) { cfg.DBHosts = []string{"primary", "replica"} },
+			err:    "binlog_cdc cannot be used with dbhosts, proxy_url, proxy_from_environment, ssh_tunnel or the 'unix' transport, the binlog is read from dbhost",
+		},
+		{
+			name:   "targets",
+			modify: func(cfg *Config) { cfg.Targets = []Target{{Name: "eu"}} },
+			err:    "binlog_cdc cannot be used with targets",
+		},
+		{
+			name:   "empty table",
+			modify: func(cfg *Config) { cfg.BinlogCDC.Tables = []string{"orders", " "} },
+			err:    "binlog_cdc tables cannot contain an empty table",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testBinlogConfig()
+			tc.modify(cfg)
+			require.EqualError(t, validateBinlogCDC(cfg), tc.err)
+		})
+	}
+}
+
+func TestIsBinlogTable(t *testing.T) {
+	cfg := testBinlogConfig()
+	require.True(t, isBinlogTable(cfg, binlogTables(cfg), "sales", "orders"))
+	require.False(t, isBinlogTable(cfg, binlogTables(cfg), "billing", "invoices"))
+
+	cfg.BinlogCDC.Tables = []string{"orders", "billing.invoices"}
+	tables := binlogTables(cfg)
+	require.True(t, isBinlogTable(cfg, tables, "sales", "orders"))
+	require.True(t, isBinlogTable(cfg, tables, "billing", "invoices"))
+	require.False(t, isBinlogTable(cfg, tables, "sales", "refunds"))
+}
+
+func TestBinlogOperation(t *testing.T) {
+	operation, ok := binlogOperation(replication.WRITE_ROWS_EVENTv2)
+	require.True(t, ok)
+	require.Equal(t, binlogOperationInsert, operation)
+	operation, ok = binlogOperation(replication.UPDATE_ROWS_EVENTv1)
+	require.True(t, ok)
+	require.Equal(t, binlogOperationUpdate, operation)
+	operation, ok = binlogOperation(replication.DELETE_ROWS_EVENTv2)
+	require.True(t, ok)
+	require.Equal(t, binlogOperationDelete, operation)
+	_, ok = binlogOperation(replication.QUERY_EVENT)
+	require.False(t, ok)
+}
+
+func TestBinlogRowChanges(t *testing.T) {
+	nulls := newNullFormat(&Config{})
+
+	changes, err := binlogRowChanges(testRowsEvent([]interface{}{int32(1), []byte("new"), 12.5}), binlogOperationInsert, nulls, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"after":{"OrderID":"1","Status":"new","Total":"12.5"},"database":"sales","operation":"insert","table":"orders"}`}, changes)
+
+	changes, err = binlogRowChanges(testRowsEvent(
+		[]interface{}{int32(1), "new", nil},
+		[]interface{}{int32(1), "paid", 12.5},
+		[]interface{}{int32(2), "new", nil},
+		[]interface{}{int32(2), "paid", 20.0},
+	), binlogOperationUpdate, nulls, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`{"after":{"OrderID":1,"Status":"paid","Total":12.5},"before":{"OrderID":1,"Status":"new","Total":"NULL"},"database":"sales","operation":"update","table":"orders"}`,
+		`{"after":{"OrderID":2,"Status":"paid","Total":20},"before":{"OrderID":2,"Status":"new","Total":"NULL"},"database":"sales","operation":"update","table":"orders"}`,
+	}, changes)
+
+	changes, err = binlogRowChanges(testRowsEvent([]interface{}{int32(1), "paid", nil}), binlogOperationDelete, newNullFormat(&Config{NullHandling: nullHandlingOmit}), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"before":{"OrderID":"1","Status":"paid"},"database":"sales","operation":"delete","table":"orders"}`}, changes)
+}
+
+func TestBinlogColumnsWOMetadata(t *testing.T) {
+	e := testRowsEvent()
+	e.Table.ColumnName = nil
+	require.Equal(t, []string{"column1", "column2", "column3"}, binlogColumns(e))
+}
+
+func TestBinlogPosition(t *testing.T) {
+	cfg := testBinlogConfig()
+	cfg.SetIDName("binlog_test")
+	defer os.Remove(getBinlogPositionFilename(cfg))
+	require.Equal(t, "mysqlrecords_binlog_test_binlog_position.csv", getBinlogPositionFilename(cfg))
+
+	_, ok := GetBinlogPosition(cfg, zap.NewNop())
+	require.False(t, ok)
+
+	// the current position of the server is used without a saved position
+	receiver := &mySQLReceiver{config: cfg, logger: zap.NewNop(), sqlclient: &mockClient{binlogFile: "binlog.000003", binlogPos: 157}}
+	position, err := receiver.binlogStartPosition()
+	require.NoError(t, err)
+	require.Equal(t, mysql.Position{Name: "binlog.000003", Pos: 157}, position)
+
+	require.NoError(t, SaveBinlogPosition(cfg, mysql.Position{Name: "binlog.000004", Pos: 4096}, zap.NewNop()))
+	position, err = receiver.binlogStartPosition()
+	require.NoError(t, err)
+	require.Equal(t, mysql.Position{Name: "binlog.000004", Pos: 4096}, position)
+}
+
+func TestBinlogSyncerConfig(t *testing.T) {
+	cfg := testBinlogConfig()
+	receiver := &mySQLReceiver{config: cfg, logger: zap.NewNop()}
+	syncerConfig, err := receiver.binlogSyncerConfig(context.Background(), loadDefaultAWSConfig)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1001), syncerConfig.ServerID)
+	require.Equal(t, "localhost", syncerConfig.Host)
+	require.Equal(t, uint16(3306), syncerConfig.Port)
+	require.Equal(t, "replicator", syncerConfig.User)
+	require.Equal(t, "userpass", syncerConfig.Password)
+	require.Nil(t, syncerConfig.TLSConfig)
+
+	cfg.DBPort = "3307"
+	cfg.TLS = &TLSConfig{}
+	syncerConfig, err = receiver.binlogSyncerConfig(context.Background(), loadDefaultAWSConfig)
+	require.NoError(t, err)
+	require.Equal(t, uint16(3307), syncerConfig.Port)
+	require.Equal(t, "localhost", syncerConfig.TLSConfig.ServerName)
+}
+
+func TestConsumeRowChanges(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	cfg := testBinlogConfig()
+	receiver := &mySQLReceiver{config: cfg, logger: zap.NewNop(), consumer: sink}
+	timestamp := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	e := testRowsEvent([]interface{}{int32(1), "new", 12.5}, []interface{}{int32(2), "new", 20.0})
+	require.NoError(t, receiver.consumeRowChanges(context.Background(), e, binlogOperationInsert, mysql.Position{Name: "binlog.000003", Pos: 4096}, timestamp))
+
+	require.Equal(t, 2, sink.LogRecordCount())
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	dbName, ok := rl.Resource().Attributes().Get(dbNameAttribute)
+	require.True(t, ok)
+	require.Equal(t, "sales", dbName.StringVal())
+	lr := rl.ScopeLogs().At(0).LogRecords().At(1)
+	require.Equal(t, `{"after":{"OrderID":"2","Status":"new","Total":"20"},"database":"sales","operation":"insert","table":"orders"}`, lr.Body().StringVal())
+	require.Equal(t, timestamp, lr.Timestamp().AsTime())
+	require.NotZero(t, lr.ObservedTimestamp())
+	require.Equal(t, map[string]interface{}{
+		binlogOperationAttribute: binlogOperationInsert,
+		binlogTableAttribute:     "sales.orders",
+		binlogPositionAttribute:  "binlog.000003:4096",
+	}, lr.Attributes().AsRaw())
+}
@@ -15,77 +15,139 @@ package mysqlrecordsreceiver
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"fmt"
 	"time"
 
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
-	"github.com/go-sql-driver/mysql"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
 type client interface {
-	Connect() error
-	getRecords(dbquery *DBQueries) (map[string]string, error)
+	Connect(ctx context.Context) error
+	Ping(ctx context.Context) error
+	ExecuteQueryandFetchRecords(ctx context.Context, query string, queryid string, args ...interface{}) (map[string]string, string, error)
+	StreamQueryRecords(ctx context.Context, query string, queryid string, opts fetchOptions, handle func(records map[string]string, lastIndex string) error, args ...interface{}) error
+	getInnoDBStatus() (string, error)
+	getBinlogPosition() (string, uint32, error)
+	getQuerySchema(queryid string) ([]columnSchema, bool)
+	getDriver() driver
 	Close() error
 }
 
-type mySQLClient struct {
-	connStr string
-	client  *sql.DB
-	logger  *zap.Logger
-	conf    *Config
+//fetchOptions define how the records of a query are fetched
+type fetchOptions struct {
+	//batchSize is the maximal number of records passed to the handler at once, all the records are passed at once if it's 0
+	batchSize int
+	//location is the time zone of the date and time values without a time zone, UTC if it's nil
+	//The session time zone is set to it for the drivers implementing sessionTimeZoneDriver
+	location *time.Location
 }
 
-var _ client = (*mySQLClient)(nil)
-
-//This function is used for reading certificates from .pem file for different AWS regions and passing them on as a tls config for authentication.
-//Details : https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/UsingWithRDS.SSL.html
-func createIAMRDSTLSConf(pempath string, logger *zap.Logger) tls.Config {
-	rootCertPool := x509.NewCertPool()
-	globalpem, err := ioutil.ReadFile(pempath)
-	if err != nil {
-		logger.Error("error in reading pem file", zap.Error(err))
-	}
-	if ok := rootCertPool.AppendCertsFromPEM(globalpem); !ok {
-		logger.Error("error in loading certificates from pem file", zap.Error(err))
-	}
-	return tls.Config{
-		RootCAs: rootCertPool,
-	}
+//sqlClient implements the client with database/sql, the parts depending on the database are implemented by the driver
+type sqlClient struct {
+	driverName string
+	driver     driver
+	connStr    string
+	client     *sql.DB
+	logger     *zap.Logger
+	conf       *Config
+	nulls      nullFormat
+	// passwordConnStr builds the connection string of every new connection, with the current password of the password provider
+	// or with a new IAM authentication token, it's used instead of connStr if it's set
+	passwordConnStr func(ctx context.Context) (string, error)
+	// typedValues sets the column values of the records by their database type, instead of as strings
+	typedValues bool
+	// schemas are the columns of the last successful result of each query, keyed by the queryid
+	schemas     map[string][]columnSchema
+	schemasLock sync.Mutex
 }
 
+var _ client = (*sqlClient)(nil)
+
 //This function calls for the AWS packaged API which will generate an authentication token that can be used for accessing a AWS RDS instance instead of a password.
 //Details : https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/UsingWithRDS.IAMDBAuth.html
-func generateIAMAuthToken(endpoint string, conf *Config, logger *zap.Logger) (token string) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+func generateIAMAuthToken(ctx context.Context, endpoint string, conf *Config, loadAWSConfig awsConfigLoader) (string, error) {
+	cfg, err := loadAWSConfig(ctx)
 	if err != nil {
-		logger.Error("configuration error:", zap.Error(err))
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
 	}
-	authenticationToken, err := auth.BuildAuthToken(
-		context.TODO(), endpoint, conf.Region, conf.Username, cfg.Credentials)
+	authenticationToken, err := auth.BuildAuthToken(ctx, endpoint, conf.Region, conf.Username, cfg.Credentials)
 	if err != nil {
-		logger.Error("failed to create authentication token:", zap.Error(err))
+		return "", fmt.Errorf("failed to create authentication token: %w", err)
+	}
+	return authenticationToken, nil
+}
+
+//awsConfigLoader loads the AWS config with the credentials used for generating the IAM authentication token
+type awsConfigLoader func(ctx context.Context) (aws.Config, error)
+
+//loadDefaultAWSConfig loads the AWS config using the default AWS credential chain
+func loadDefaultAWSConfig(ctx context.Context) (aws.Config, error) {
+	return config.LoadDefaultConfig(ctx)
+}
+
+//This function creates the client of the driver registered under the name configured with db_driver
+func newClient(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) (client, error) {
+	d, ok := getDriver(conf.DBDriver)
+	if !ok {
+		return nil, fmt.Errorf("unknown db_driver : '%s'", conf.DBDriver)
+	}
+	c := &sqlClient{
+		driverName:  conf.DBDriver,
+		driver:      d,
+		conf:        conf,
+		nulls:       newNullFormat(conf),
+		typedValues: conf.TypedValues,
+		logger:      logger,
+		schemas:     make(map[string][]columnSchema),
+	}
+	if password := newPasswordProvider(conf, loadAWSConfig, logger); password != nil {
+		c.passwordConnStr = passwordConnectionString(d, conf, password, loadAWSConfig, logger)
+	} else if iamDriver, ok := d.(iamAuthDriver); ok && conf.AuthenticationMode == "IAMRDSAuth" {
+		//The IAM authentication tokens expire after 15 minutes, so the connections recycled by the pool cannot reuse the token the client was created with,
+		//the connection string, registering its TLS config and dialers, is built once and every new connection gets it with a new token instead
+		connStr, err := d.connectionString(conf, loadAWSConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+		c.passwordConnStr = func(ctx context.Context) (string, error) {
+			return iamDriver.withIAMAuthToken(ctx, connStr, conf, loadAWSConfig)
+		}
+	} else if len(conf.PasswordEnv) != 0 {
+		password, err := envPassword(conf)
+		if err != nil {
+			return nil, err
+		}
+		envConf := *conf
+		envConf.Password = password
+		if c.connStr, err = d.connectionString(&envConf, loadAWSConfig, logger); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if c.connStr, err = d.connectionString(conf, loadAWSConfig, logger); err != nil {
+			return nil, err
+		}
 	}
-	return authenticationToken
+	return c, nil
 }
 
-//There are 3 scenarios here for creating connection strings for a database connection
-//1. With a plaintext password
-//2. With an encrypted plaintext password
-//3. With an AWS Authentication token to be used as a password
-func newMySQLClient(conf *Config, logger *zap.Logger) client {
-	var basicauthpassword string
-	var connStr string
-	var driverConf mysql.Config
-	basicauthpassword = conf.Password
+//This function returns the password used for 'BasicAuth', decrypting it if it's encrypted
+func basicAuthPassword(conf *Config, logger *zap.Logger) string {
+	basicauthpassword := conf.Password
 	//Encrypting a plaintext password if a 24 character secret string is provided by the user from an external file
 	if (len(conf.PasswordType) == 0 || conf.PasswordType == "plaintext") && len(conf.EncryptSecretPath) != 0 {
 		secret, err := readMySecret(conf)
@@ -110,44 +172,11 @@ func newMySQLClient(conf *Config, logger *zap.Logger) client {
 		}
 		basicauthpassword = decText
 	}
-	endpoint := conf.DBHost + ":" + conf.DBPort
-	if conf.AuthenticationMode == "IAMRDSAuth" {
-		authenticationToken := generateIAMAuthToken(endpoint, conf, logger)
-		tlsConf := createIAMRDSTLSConf(conf.AWSCertificatePath, logger)
-		tlserr := mysql.RegisterTLSConfig("custom", &tlsConf)
-		if tlserr != nil {
-			logger.Error("Error %s when RegisterTLSConfig\n", zap.Error(tlserr))
-		}
-		driverConf = mysql.Config{
-			User:                    conf.Username,
-			Passwd:                  authenticationToken,
-			Net:                     conf.Transport,
-			Addr:                    endpoint,
-			DBName:                  conf.Database,
-			AllowNativePasswords:    conf.AllowNativePasswords,
-			TLSConfig:               "custom",
-			AllowCleartextPasswords: true,
-		}
-	} else {
-		driverConf = mysql.Config{
-			User:                 conf.Username,
-			Passwd:               basicauthpassword,
-			Net:                  conf.Transport,
-			Addr:                 endpoint,
-			DBName:               conf.Database,
-			AllowNativePasswords: conf.AllowNativePasswords,
-		}
-	}
-	connStr = driverConf.FormatDSN()
-	return &mySQLClient{
-		connStr: connStr,
-		conf:    conf,
-		logger:  logger,
-	}
+	return basicauthpassword
 }
 
-func (c *mySQLClient) Connect() error {
-	clientDB, err := sql.Open("mysql", c.connStr)
+func (c *sqlClient) Connect(ctx context.Context) error {
+	clientDB, err := c.open(ctx)
 	if err != nil {
 		c.logger.Error("Unable to connect to database", zap.Error(err))
 		return err
@@ -172,164 +201,521 @@ func (c *mySQLClient) Connect() error {
 	return nil
 }
 
-//This function is used for querying the db for records
-func (c *mySQLClient) getRecords(dbquery *DBQueries) (map[string]string, error) {
-	myEntireRecords := make(map[string]string)
-	if len(strings.TrimSpace(dbquery.Query)) == 0 {
-		c.logger.Error("Query is empty, check collector config file for:", zap.String("queryId", dbquery.QueryId))
-		return nil, nil
-	} else if len(strings.TrimSpace(dbquery.IndexColumnName)) == 0 {
-		c.logger.Info("IndexColumnName missing from collector config file, so fetching all records for:", zap.String("queryId", dbquery.QueryId))
-	} else if len(strings.TrimSpace(dbquery.IndexColumnName)) != 0 && len(strings.TrimSpace(dbquery.IndexColumnType)) == 0 {
-		c.logger.Error("IndexColummType should be specified with a IndexColumnName for a query.", zap.String("queryId", dbquery.QueryId))
-		c.logger.Error("Supported values are TIMESTAMP or NUMBER.", zap.String("queryId", dbquery.QueryId))
-		return nil, nil
-	} else if dbquery.IndexColumnType != "TIMESTAMP" && dbquery.IndexColumnType != "NUMBER" {
-		c.logger.Error("Configured non supported Indexcolummtype, supported values are TIMESTAMP or NUMBER.", zap.String("queryId", dbquery.QueryId))
-		c.logger.Error("Check collector configuration file for:", zap.String("queryId", dbquery.QueryId))
-		return nil, nil
-	} else if len(strings.TrimSpace(dbquery.IndexColumnName)) != 0 {
-		if dbquery.IndexColumnType == "TIMESTAMP" {
-			if strings.Contains(dbquery.Query, "where") {
-				dbquery.Query += " and INDEXCOLUMNNAME > \"STATEVALUE\" order by INDEXCOLUMNNAME asc;"
-			} else {
-				dbquery.Query += " where INDEXCOLUMNNAME > \"STATEVALUE\" order by INDEXCOLUMNNAME asc;"
-			}
-		} else if dbquery.IndexColumnType == "NUMBER" {
-			if strings.Contains(dbquery.Query, "where") {
-				dbquery.Query += " and INDEXCOLUMNNAME > STATEVALUE order by INDEXCOLUMNNAME asc;"
-			} else {
-				dbquery.Query += " where INDEXCOLUMNNAME > STATEVALUE order by INDEXCOLUMNNAME asc;"
-			}
-		}
-		c.logger.Info("IndexColumnName specified, fetching records incrementally for:", zap.String("queryId", dbquery.QueryId))
+//This function checks that the database is reachable, establishing a connection if there's no idle one
+func (c *sqlClient) Ping(ctx context.Context) error {
+	return c.client.PingContext(ctx)
+}
+
+//This function opens the database, the connections of a password provider are opened with its current password
+//and the connections of 'IAMRDSAuth' with a new authentication token
+func (c *sqlClient) open(ctx context.Context) (*sql.DB, error) {
+	if c.passwordConnStr == nil {
+		return sql.Open(c.driverName, c.connStr)
+	}
+	//The password is fetched before the database is opened, so a password which cannot be fetched fails the start of the receiver
+	connStr, err := c.passwordConnStr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(c.driverName, connStr)
+	if err != nil {
+		return nil, err
 	}
-	if len(strings.TrimSpace(dbquery.IndexColumnName)) == 0 {
-		queryFetchResult, _, err := ExecuteQueryandFetchRecords(*c, dbquery.Query, dbquery.QueryId)
-		for key, element := range queryFetchResult {
+	d := db.Driver()
+	db.Close()
+	return sql.OpenDB(&passwordConnector{driver: d, connStr: c.passwordConnStr}), nil
+}
+
+//This function is used for querying the db for records, it advances the saved state of queries with an index column
+//The query is cancelled once its query_timeout is exceeded, it fails with context.DeadlineExceeded then
+func getRecords(ctx context.Context, sqlclient client, dbquery *DBQueries, logger *zap.Logger) (map[string]string, error) {
+	//The records are nil if the query is not run
+	var myEntireRecords map[string]string
+	_, err := streamRecords(ctx, sqlclient, dbquery, 0, logger, func(records map[string]string) {
+		if myEntireRecords == nil {
+			myEntireRecords = make(map[string]string, len(records))
+		}
+		for key, element := range records {
 			myEntireRecords[key] = element
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return myEntireRecords, nil
+}
+
+//This function passes the records of the query to handle in batches of at most batchSize records while the rows are scanned, all the records are passed at once if batchSize is 0
+//The state of a query with an index column is saved after every batch, it returns the number of fetched records
+func streamRecords(ctx context.Context, sqlclient client, dbquery *DBQueries, batchSize int, logger *zap.Logger, handle func(records map[string]string)) (int, error) {
+	//The configured query is kept unchanged, its hash is saved with the state
+	//The query and its index columns are validated with the config
+	query := dbquery.Query
+	if !isIncrementalQuery(dbquery) {
+		logger.Info("IndexColumnName missing from collector config file, so fetching all records for:", zap.String("queryId", dbquery.QueryId))
+	} else {
+		//The state value is bound to the query, so it cannot change the query whatever the fetched records contain
+		d := sqlclient.getDriver()
+		columns := indexColumns(dbquery)
+		condition := indexCondition(d, columns)
+		order := indexOrder(d, columns)
+		//A query with max_rows_per_fetch fetches the next chunk of its records after the saved state
+		if dbquery.MaxRowsPerFetch > 0 {
+			order += d.limitClause(dbquery.MaxRowsPerFetch)
+		}
+		query = appendIndexCondition(query, condition) + order + d.statementTerminator()
+		logger.Info("IndexColumnName specified, fetching records incrementally for:", zap.String("queryId", dbquery.QueryId))
+	}
+	if len(dbquery.QueryTimeout) != 0 {
+		timeout, _ := time.ParseDuration(dbquery.QueryTimeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var fetched int
+	if !isIncrementalQuery(dbquery) {
+		if dbquery.EmitOnChangeOnly {
+			unlock := lockContentHash(dbquery)
+			defer unlock()
+			//The hash is computed from the entire result set, so it's not passed in batches
+			batchSize = 0
+		}
+		if dbquery.SnapshotDiff {
+			unlock := lockSnapshot(dbquery)
+			defer unlock()
+			//The deleted rows are only known once the entire result set is compared with the snapshot
+			batchSize = 0
+		}
+		err := sqlclient.StreamQueryRecords(ctx, query, dbquery.QueryId, fetchOptions{batchSize: batchSize}, func(queryFetchResult map[string]string, lastIndex string) error {
+			if dbquery.SnapshotDiff {
+				previous, _ := GetSnapshot(dbquery, logger)
+				changes, snapshot, err := diffSnapshot(dbquery, queryFetchResult, previous)
+				if err != nil {
+					return err
+				}
+				if err := SaveSnapshot(dbquery, snapshot, logger); err != nil {
+					logger.Warn("Snapshot was not saved, the changes will be emitted again in the next collection", zap.String("queryId", dbquery.QueryId))
+				}
+				logger.Info("Query result compared with the snapshot of the previous result for:", zap.String("queryId", dbquery.QueryId), zap.Int("changes", len(changes)))
+				fetched += len(queryFetchResult)
+				handle(changes)
+				return nil
+			}
+			if dbquery.EmitOnChangeOnly {
+				contentHash := resultSetHash(queryFetchResult)
+				if contentHash == GetContentHash(dbquery, logger) {
+					logger.Info("Query result didn't change since it was last emitted, skipping records for:", zap.String("queryId", dbquery.QueryId))
+					handle(map[string]string{})
+					return nil
+				}
+				if err := SaveContentHash(dbquery, contentHash, logger); err != nil {
+					logger.Warn("Content hash was not saved, the records will be emitted again in the next collection", zap.String("queryId", dbquery.QueryId))
+				}
+			}
+			fetched += len(queryFetchResult)
+			handle(queryFetchResult)
+			return nil
+		})
 		if err != nil {
-			c.logger.Error("Error in executing query and fetching records for:", zap.String("queryId", dbquery.QueryId), zap.Error(err))
-			return nil, nil
+			return fetched, queryError(ctx, dbquery, err)
 		}
-		if len(queryFetchResult) == 0 {
-			c.logger.Info("No database records found for query with:", zap.String("queryId", dbquery.QueryId))
+		if fetched == 0 {
+			logger.Info("No database records found for query with:", zap.String("queryId", dbquery.QueryId))
 		} else {
-			c.logger.Info("Database records found for query with:", zap.String("queryId", dbquery.QueryId))
+			logger.Info("Database records found for query with:", zap.String("queryId", dbquery.QueryId))
 		}
 	} else {
-		var currentState = GetState(dbquery, c.logger)
-		dbquery.Query = strings.Replace(dbquery.Query, "STATEVALUE", currentState, -1)
-		dbquery.Query = strings.Replace(dbquery.Query, "INDEXCOLUMNNAME", dbquery.IndexColumnName, -1)
-		queryFetchResult, lastIndex, err := ExecuteQueryandFetchRecords(*c, dbquery.Query, dbquery.QueryId)
-		for key, element := range queryFetchResult {
-			myEntireRecords[key] = element
-		}
+		unlock := lockState(dbquery)
+		defer unlock()
+		var currentState = GetState(dbquery, logger)
+		args, err := stateArgs(sqlclient.getDriver(), dbquery, currentState)
 		if err != nil {
-			c.logger.Error("Error in executing query and fetching records", zap.String("queryId", dbquery.QueryId), zap.Error(err))
-			return nil, nil
+			return 0, err
 		}
-		if len(queryFetchResult) == 0 {
-			c.logger.Info("No new records found for query with : ", zap.String("queryId", dbquery.QueryId))
-		} else {
-			c.logger.Info("New database records found for query with : ", zap.String("queryId", dbquery.QueryId))
-			lastRecordFetched := myEntireRecords[lastIndex]
-			var lastRecordFetchedVal map[string]interface{}
-			err := json.Unmarshal([]byte(lastRecordFetched), &lastRecordFetchedVal)
+		opts := fetchOptions{batchSize: batchSize}
+		if len(dbquery.IndexColumnTimezone) != 0 {
+			opts.location = indexColumnLocation(dbquery)
+		}
+		err = sqlclient.StreamQueryRecords(ctx, query, dbquery.QueryId, opts, func(queryFetchResult map[string]string, lastIndex string) error {
+			if len(queryFetchResult) == 0 {
+				handle(queryFetchResult)
+				return nil
+			}
+			lastRecordStateNumber, err := recordState(dbquery, queryFetchResult[lastIndex])
 			if err != nil {
-				c.logger.Error("Problem converting sql query resultset into json format for:", zap.String("queryId", dbquery.QueryId), zap.Error(err))
-				return nil, nil
+				return err
+			}
+			fetched += len(queryFetchResult)
+			handle(queryFetchResult)
+			if err := SaveState(dbquery, lastRecordStateNumber, logger); err != nil {
+				logger.Warn("State was not saved, the records will be fetched again in the next collection", zap.String("queryId", dbquery.QueryId))
 			}
-			var lastRecordStateNumber = lastRecordFetchedVal[dbquery.IndexColumnName].(string)
-			SaveState(dbquery, lastRecordStateNumber, c.logger)
+			return nil
+		}, args...)
+		if err != nil {
+			return fetched, queryError(ctx, dbquery, err)
+		}
+		if fetched == 0 {
+			logger.Info("No new records found for query with : ", zap.String("queryId", dbquery.QueryId))
+		} else {
+			logger.Info("New database records found for query with : ", zap.String("queryId", dbquery.QueryId))
 		}
 	}
-	return myEntireRecords, nil
+	return fetched, nil
 }
 
-func ExecuteQueryandFetchRecords(c mySQLClient, query string, queryid string) (map[string]string, string, error) {
-	rows, err := c.client.Query(query)
+func validateQueryTimeout(dbquery DBQueries) error {
+	timeout, err := time.ParseDuration(dbquery.QueryTimeout)
 	if err != nil {
-		c.logger.Error("Error in executing sql query", zap.String("queryId", queryid), zap.Error(err))
-		return nil, "", nil
+		return err
 	}
-	defer rows.Close()
+	if timeout <= 0 {
+		return errors.New("it has to be positive")
+	}
+	return nil
+}
 
-	// Get column names
-	columns, err := rows.Columns()
-	if err != nil {
-		c.logger.Error("Error getting column names from table", zap.String("queryId", queryid), zap.Error(err))
-		return nil, "", nil
+//This function returns the error of a failed query, the drivers report a cancelled query differently,
+//so a query which exceeded its query_timeout fails with context.DeadlineExceeded whatever the driver returned
+func queryError(ctx context.Context, dbquery *DBQueries, err error) error {
+	if len(dbquery.QueryTimeout) != 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query exceeded query_timeout of %s: %w", dbquery.QueryTimeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+//This function appends the predicate of the index columns to the where clause of the query, or adds the where clause
+//The where clause is found with the tokenizer, so the where keywords of comments, string literals and subqueries are not taken into account
+//The existing predicate is parenthesized, so the predicate of the index columns applies to all its terms, e.g. to both of 'a = 1 or b = 2'
+func appendIndexCondition(query string, condition string) string {
+	query = strings.TrimSpace(query)
+	statements, err := scanQuery(query)
+	if err != nil || len(statements) == 0 {
+		return query + " where " + condition
+	}
+	where := -1
+	depth := 0
+	for _, token := range statements[0] {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case token.text == "WHERE" && depth == 0:
+			where = token.end
+		}
+	}
+	if where < 0 {
+		return query + " where " + condition
+	}
+	return query[:where] + " (" + strings.TrimSpace(query[where:]) + ") and " + condition
+}
+
+//stateTimestampLayouts are the layouts of the TIMESTAMP state values, i.e. the default initial state value,
+//the configured initial_index_value and the values of DATE, DATETIME and TIMESTAMP columns returned by the drivers
+var stateTimestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+//This function converts the saved state value into the parameter bound to the query with an index column,
+//an integer or a float for 'NUMBER', e.g. large NUMBER values may be returned in the exponent notation, and a time for 'TIMESTAMP'
+//The value is bound as a string if it cannot be parsed, so the query fails with the error of the database
+//The 'TIMESTAMP' values without a time zone are in loc, the time is converted into the parameter by the driver
+func stateArg(d driver, indexColumnType string, value string, loc *time.Location) interface{} {
+	switch indexColumnType {
+	case "NUMBER":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "TIMESTAMP":
+		if t, ok := parseStateTimestamp(value, loc); ok {
+			return d.timestampArg(t.In(loc))
+		}
+	}
+	return value
+}
+
+//This function parses the state value of a 'TIMESTAMP' index column, the values without a time zone are in loc
+func parseStateTimestamp(value string, loc *time.Location) (time.Time, bool) {
+	//the monotonic clock reading of the default initial state value is not a part of the timestamp
+	if i := strings.Index(value, " m="); i >= 0 {
+		value = value[:i]
+	}
+	for _, layout := range stateTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
 	}
+	return time.Time{}, false
+}
+
+//This function returns the hex encoded SHA-256 hash of the records of a query result
+//The records are sorted first, so the hash doesn't depend on the order of the rows returned by the database
+func resultSetHash(records map[string]string) string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record)
+	}
+	sort.Strings(values)
 
-	values := make([]sql.RawBytes, len(columns))
+	hash := sha256.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
 
-	// rows.Scan wants '[]interface{}' as an argument, so we must copy the references into such a slice
-	// See http://code.google.com/p/go-wiki/wiki/InterfaceSlice for details
-	scanArgs := make([]interface{}, len(values))
-	for i := range values {
-		scanArgs[i] = &values[i]
+//This function executes the query and converts each fetched database record into a json object
+//The records are keyed by <queryid>_record<number>, the key of the last record is returned as well
+//The args are bound to the placeholders of the query
+func (c *sqlClient) ExecuteQueryandFetchRecords(ctx context.Context, query string, queryid string, args ...interface{}) (map[string]string, string, error) {
+	var records map[string]string
+	var lastIndex string
+	err := c.StreamQueryRecords(ctx, query, queryid, fetchOptions{}, func(batch map[string]string, batchLastIndex string) error {
+		records, lastIndex = batch, batchLastIndex
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, "", err
 	}
+	return records, lastIndex, nil
+}
 
-	lines := make([][]string, 0)
+//This function passes the records to handle in batches of at most opts.batchSize records while the rows are scanned, so only a batch of the result set is held in memory
+//All the records are passed at once if opts.batchSize is 0, handle is called once even if the query returned no rows then
+//The records are numbered across the batches in the order of the query result
+func (c *sqlClient) StreamQueryRecords(ctx context.Context, query string, queryid string, opts fetchOptions, handle func(records map[string]string, lastIndex string) error, args ...interface{}) error {
+	batchSize := opts.batchSize
+	var queryer interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	} = c.client
+	//The session time zone is set on a dedicated connection, which gets its previous time zone back before it's returned to the pool
+	if d, ok := c.driver.(sessionTimeZoneDriver); ok && opts.location != nil {
+		conn, err := c.client.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("error in getting a connection: %w", err)
+		}
+		defer conn.Close()
+		restore, err := d.setSessionTimeZone(ctx, conn, opts.location)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := restore(); err != nil {
+				c.logger.Warn("Failed to restore the session time zone", zap.String("queryId", queryid), zap.Error(err))
+			}
+		}()
+		queryer = conn
+	}
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error in executing sql query: %w", err)
+	}
+	defer rows.Close()
 
-	// now let's loop through the table lines and append them to the slice declared above
-	for rows.Next() {
-		// read the row on the table
-		// each column value will be stored in the slice
-		err = rows.Scan(scanArgs...)
+	//The records of the result sets of a multi statement query are passed together, a batch is passed once it contains batchSize records
+	batch := make(map[string]string)
+	var lastIndex string
+	addLines := func(columns []string, types []string, lines [][]sql.NullString, resultSet int, offset int) error {
+		records, last, err := buildJSONRecords(columns, types, lines, resultSetRecordPrefix(queryid, resultSet), offset, c.nulls, opts.location)
+		if err != nil {
+			return err
+		}
+		for index, record := range records {
+			batch[index] = record
+		}
+		if len(last) != 0 {
+			lastIndex = last
+		}
+		return nil
+	}
+	for resultSet := 1; ; resultSet++ {
+		// Get column names
+		columns, err := rows.Columns()
+		if err != nil {
+			return fmt.Errorf("error getting column names from table: %w", err)
+		}
+		columnTypes, err := rows.ColumnTypes()
 		if err != nil {
-			c.logger.Error("Error scanning rows from table", zap.String("queryId", queryid), zap.Error(err))
-			return nil, "", nil
+			return fmt.Errorf("error getting column types from table: %w", err)
+		}
+		//The schema is saved before the rows are scanned, so it's available while the batches are handled
+		if resultSet == 1 {
+			c.saveQuerySchema(queryid, columnTypes)
+		}
+		var types []string
+		if c.typedValues {
+			types = make([]string, len(columnTypes))
+			for i, columnType := range columnTypes {
+				types[i] = columnType.DatabaseTypeName()
+			}
 		}
 
-		var value string
-		var line []string
+		values := make([]sql.RawBytes, len(columns))
 
-		for _, col := range values {
-			// Here we can check if the value is nil (NULL value)
-			if col == nil {
-				value = "NULL"
-			} else {
-				value = string(col)
-				line = append(line, value)
+		// rows.Scan wants '[]interface{}' as an argument, so we must copy the references into such a slice
+		// See http://code.google.com/p/go-wiki/wiki/InterfaceSlice for details
+		scanArgs := make([]interface{}, len(values))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		lines := make([][]sql.NullString, 0)
+		var scanned int
+
+		// now let's loop through the table lines and append them to the slice declared above
+		for rows.Next() {
+			// read the row on the table
+			// each column value will be stored in the slice
+			err = rows.Scan(scanArgs...)
+			if err != nil {
+				return fmt.Errorf("error scanning rows from table: %w", err)
+			}
+			lines = append(lines, rawBytesToStrings(c.driver, values))
+			if batchSize > 0 && len(batch)+len(lines) == batchSize {
+				if err := addLines(columns, types, lines, resultSet, scanned); err != nil {
+					return err
+				}
+				scanned += len(lines)
+				lines = lines[:0]
+				if err := handle(batch, lastIndex); err != nil {
+					return err
+				}
+				batch, lastIndex = make(map[string]string), ""
 			}
 		}
-		lines = append(lines, line)
+		err = rows.Err()
+		if err != nil {
+			return fmt.Errorf("error found in rows: %w", err)
+		}
+		if err := addLines(columns, types, lines, resultSet, scanned); err != nil {
+			return err
+		}
+		if !rows.NextResultSet() {
+			break
+		}
 	}
-	err = rows.Err()
-	if err != nil {
-		c.logger.Error("Error found in rows", zap.String("queryId", queryid), zap.Error(err))
-		return nil, "", nil
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error found in rows: %w", err)
+	}
+	if batchSize > 0 && len(batch) == 0 {
+		return nil
+	}
+	return handle(batch, lastIndex)
+}
+
+//This function returns the prefix of the record keys of a result set, the records of the result sets after the first one
+//of a multi statement query are numbered separately, e.g. <queryid>_result2_record1
+func resultSetRecordPrefix(queryid string, resultSet int) string {
+	if resultSet == 1 {
+		return queryid
+	}
+	return queryid + "_result" + strconv.Itoa(resultSet)
+}
+
+func (c *sqlClient) saveQuerySchema(queryid string, columnTypes []*sql.ColumnType) {
+	columns := make([]columnSchema, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = columnSchema{
+			Name: columnType.Name(),
+			Type: columnType.DatabaseTypeName(),
+		}
+		if nullable, ok := columnType.Nullable(); ok {
+			columns[i].Nullable = &nullable
+		}
 	}
-	myjsonobject := make(map[string]string)
+	c.schemasLock.Lock()
+	defer c.schemasLock.Unlock()
+	c.schemas[queryid] = columns
+}
+
+//This function returns the columns of the last successful result of the query
+func (c *sqlClient) getQuerySchema(queryid string) ([]columnSchema, bool) {
+	c.schemasLock.Lock()
+	defer c.schemasLock.Unlock()
+	columns, ok := c.schemas[queryid]
+	return columns, ok
+}
+
+//This function converts the column values of a scanned row into strings with the driver
+func rawBytesToStrings(d driver, values []sql.RawBytes) []sql.NullString {
+	line := make([]sql.NullString, len(values))
+	for i, col := range values {
+		line[i] = sql.NullString{String: d.scanValue(col), Valid: col != nil}
+	}
+	return line
+}
+
+//This function converts rows of column values into json objects keyed by <queryid>_record<number>, the rows are numbered after offset
+//The values are set by the position of their column, so NULL values don't shift the following columns whatever the null handling is
+//The values are typed by the database types of the columns if types is set, they are strings otherwise
+//The date and time values without a time zone of typed values are in loc, or in UTC if it's nil
+func buildJSONRecords(columns []string, types []string, lines [][]sql.NullString, queryid string, offset int, nulls nullFormat, loc *time.Location) (map[string]string, string, error) {
 	myEntireRecord := make(map[string]string)
 	var lastIndex string = ""
 	for j, value := range lines {
+		myjsonobject := make(map[string]interface{}, len(columns))
 		for i, v := range value {
-			myjsonobject[columns[i]] = v
+			if !v.Valid {
+				nulls.setNull(myjsonobject, columns[i])
+			} else if types != nil && len(types[i]) != 0 {
+				myjsonobject[columns[i]] = jsonTypedValue(v.String, types[i], loc)
+			} else {
+				myjsonobject[columns[i]] = v.String
+			}
 		}
 		jsonObjRecord, err := json.Marshal(myjsonobject)
 		if err != nil {
-			c.logger.Error("Error in marshalling json object", zap.String("queryId", queryid), zap.Error(err))
-			return nil, "", nil
+			return nil, "", fmt.Errorf("error in marshalling json object: %w", err)
 		}
-		jsonStr := string(jsonObjRecord)
-		index := queryid + "_record" + strconv.Itoa(j+1)
-		myEntireRecord[index] = jsonStr
+		index := queryid + "_record" + strconv.Itoa(offset+j+1)
+		myEntireRecord[index] = string(jsonObjRecord)
 		lastIndex = index
-		if err != nil {
-			c.logger.Error("Error in converting records into json object", zap.String("queryId", queryid), zap.Error(err))
-			return nil, "", nil
-		}
 	}
 	return myEntireRecord, lastIndex, nil
 }
 
-func (c *mySQLClient) Close() error {
+//This function returns the InnoDB monitor output, it's only available with the drivers of databases with the InnoDB storage engine
+func (c *sqlClient) getInnoDBStatus() (string, error) {
+	d, ok := c.driver.(innoDBDriver)
+	if !ok {
+		return "", fmt.Errorf("InnoDB status is not available with db_driver : '%s'", c.driverName)
+	}
+	return d.innoDBStatus(c.client)
+}
+
+func (c *sqlClient) getBinlogPosition() (string, uint32, error) {
+	d, ok := c.driver.(binlogDriver)
+	if !ok {
+		return "", 0, fmt.Errorf("binlog position is not available with db_driver : '%s'", c.driverName)
+	}
+	return d.binlogPosition(c.client)
+}
+
+func (c *sqlClient) getDriver() driver {
+	return c.driver
+}
+
+func (c *sqlClient) Close() error {
+	var err error
 	if c.client != nil {
-		return c.client.Close()
+		err = c.client.Close()
 	}
-	return nil
+	if c.conf != nil && c.conf.AuthenticationMode == "GCPCloudSQLIAM" {
+		err = multierr.Append(err, closeCloudSQLDialer(c.conf))
+	}
+	if c.conf != nil && c.conf.SSHTunnel != nil {
+		err = multierr.Append(err, closeSSHTunnel(c.conf))
+	}
+	if c.conf != nil && len(c.conf.DBHosts) != 0 {
+		closeFailoverDialer(c.conf)
+	}
+	return err
 }
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/cloudsqlconn"
+	"github.com/go-sql-driver/mysql"
+)
+
+//cloudSQLNetworkPrefix prefixes the names of the networks the Cloud SQL dialers are registered under with the driver
+const cloudSQLNetworkPrefix = "cloudsql_"
+
+//cloudSQLInstancePattern matches the instance connection names, i.e. project:region:instance, the project of a domain scoped project contains a colon as well
+var cloudSQLInstancePattern = regexp.MustCompile(`^([^:]+:)?[^:]+:[^:]+:[^:]+$`)
+
+//cloudSQLDialers are the Cloud SQL dialers of the receivers, keyed by the network they are registered under
+//The logs and the metrics receivers with the same id share the dialer, so it's closed when both of them are
+var (
+	cloudSQLDialers     = make(map[string]*cloudSQLDialer)
+	cloudSQLDialersLock sync.Mutex
+)
+
+//cloudSQLDialer is a Cloud SQL dialer with the number of the clients it's registered for
+type cloudSQLDialer struct {
+	dialer *cloudsqlconn.Dialer
+	refs   int
+}
+
+//This function validates the options of 'GCPCloudSQLIAM', the password is replaced by the OAuth2 token of the IAM principal
+func validateCloudSQLIAM(cfg *Config) error {
+	if cfg.AuthenticationMode != "GCPCloudSQLIAM" {
+		if len(cfg.CloudSQLInstance) != 0 {
+			return errors.New("cloudsql_instance can only be used with authentication_mode : 'GCPCloudSQLIAM'")
+		}
+		return nil
+	}
+	var err error
+	if !cloudSQLInstancePattern.MatchString(cfg.CloudSQLInstance) {
+		err = errors.New("cloudsql_instance should be the instance connection name in the 'project:region:instance' format for authentication_mode : 'GCPCloudSQLIAM'")
+	}
+	if len(cfg.Password) != 0 || len(cfg.PasswordType) != 0 || len(cfg.EncryptSecretPath) != 0 || len(cfg.PasswordEnv) != 0 || len(cfg.PasswordFile) != 0 ||
+		(len(cfg.PasswordSource) != 0 && cfg.PasswordSource != passwordSourceConfig) {
+		err = errors.New("the password settings should be empty for authentication_mode : 'GCPCloudSQLIAM'")
+	}
+	if len(cfg.ProxyURL) != 0 {
+		err = errors.New("proxy_url cannot be used with authentication_mode : 'GCPCloudSQLIAM'")
+	}
+	return err
+}
+
+//This function registers the dial function of a Cloud SQL dialer with automatic IAM authentication with the driver
+//The dialer creates the ephemeral certificates of the TLS connections to the instance and refreshes them before they expire,
+//the address of the connection string is the instance connection name
+//It returns the name of the network the dial function is registered under, which is used as the network of the connection string
+func registerCloudSQLDialer(conf *Config) (string, error) {
+	network := cloudSQLNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	cloudSQLDialersLock.Lock()
+	defer cloudSQLDialersLock.Unlock()
+	if registered, ok := cloudSQLDialers[network]; ok {
+		registered.refs++
+		return network, nil
+	}
+	dialer, err := cloudsqlconn.NewDialer(context.Background(), cloudsqlconn.WithIAMAuthN())
+	if err != nil {
+		return "", fmt.Errorf("error in creating Cloud SQL dialer: %w", err)
+	}
+	cloudSQLDialers[network] = &cloudSQLDialer{dialer: dialer, refs: 1}
+	mysql.RegisterDialContext(network, func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.Dial(ctx, addr)
+	})
+	return network, nil
+}
+
+//This function closes the Cloud SQL dialer of the receiver once no other client uses it, so its certificate refreshes are stopped
+func closeCloudSQLDialer(conf *Config) error {
+	network := cloudSQLNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	cloudSQLDialersLock.Lock()
+	defer cloudSQLDialersLock.Unlock()
+	registered, ok := cloudSQLDialers[network]
+	if !ok {
+		return nil
+	}
+	if registered.refs--; registered.refs > 0 {
+		return nil
+	}
+	delete(cloudSQLDialers, network)
+	return registered.dialer.Close()
+}
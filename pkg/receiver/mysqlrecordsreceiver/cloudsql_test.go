@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestValidateCloudSQLIAM(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "GCPCloudSQLIAM"
+	cfg.Username = "collector@my-project.iam"
+	cfg.Database = "app"
+	cfg.CloudSQLInstance = "my-project:europe-west1:orders"
+	require.NoError(t, cfg.Validate())
+
+	// the project of a domain scoped project contains a colon
+	cfg.CloudSQLInstance = "example.com:my-project:europe-west1:orders"
+	require.NoError(t, cfg.Validate())
+
+	cfg.CloudSQLInstance = "orders"
+	require.Error(t, cfg.Validate())
+
+	cfg.CloudSQLInstance = "my-project:europe-west1:orders"
+	cfg.Password = "userpass"
+	require.Error(t, cfg.Validate())
+
+	cfg.Password = ""
+	cfg.ProxyURL = "socks5://jumphost:1080"
+	require.Error(t, cfg.Validate())
+
+	// the Cloud SQL dialer is registered with the MySQL driver
+	cfg.ProxyURL = ""
+	cfg.DBDriver = dbDriverPostgres
+	require.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "app"
+	cfg.CloudSQLInstance = "my-project:europe-west1:orders"
+	require.Error(t, cfg.Validate())
+}
+
+func TestNewMySQLClientWithCloudSQLIAMWOCredentials(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "GCPCloudSQLIAM"
+	cfg.Username = "collector@my-project.iam"
+	cfg.Database = "app"
+	cfg.CloudSQLInstance = "my-project:europe-west1:orders"
+	defer closeCloudSQLDialer(cfg)
+
+	// the connections are not established without the Cloud SQL dialer when it cannot be created
+	_, err := mySQLDriver{}.connectionString(cfg, nil, zap.NewNop())
+	require.Error(t, err)
+}
@@ -15,12 +15,34 @@ package mysqlrecordsreceiver
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.uber.org/multierr"
 )
 
+const (
+	//emitModePerRow emits a log record for each database record
+	emitModePerRow = "per_row"
+	//emitModePerScrapeArray emits a log record containing a JSON array of all database records fetched by a query
+	emitModePerScrapeArray = "per_scrape_array"
+)
+
+//authenticationModes are the values of authentication_mode, the drivers restrict them to the modes supported by their database
+var authenticationModes = []string{"IAMRDSAuth", "BasicAuth", "SocketAuth", "WindowsAuth", "GCPCloudSQLIAM", "AzureADAuth"}
+
+func isKnownAuthenticationMode(mode string) bool {
+	for _, m := range authenticationModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
 type Config struct {
 	config.ReceiverSettings `mapstructure:",squash"`
 	AuthenticationMode      string `mapstructure:"authentication_mode"`
@@ -32,16 +54,109 @@ type Config struct {
 	DBHost                  string `mapstructure:"dbhost"`
 	DBPort                  string `mapstructure:"dbport,omitempty"`
 	Transport               string `mapstructure:"transport,omitempty"`
+	ProxyURL                string `mapstructure:"proxy_url,omitempty"`
+	ProxyFromEnvironment    bool   `mapstructure:"proxy_from_environment,omitempty"`
 	AllowNativePasswords    bool   `mapstructure:"allow_native_passwords,omitempty"`
 	Region                  string `mapstructure:"region,omitempty"`
 	AWSCertificatePath      string `mapstructure:"aws_certificate_path,omitempty"`
+	//DBHosts are the endpoints connected to instead of dbhost, the first reachable one in their order is used, e.g. the primary followed by the read replicas
+	DBHosts []string `mapstructure:"dbhosts,omitempty"`
+	//PasswordSource is where the password of 'BasicAuth' comes from, either 'config', i.e. the password option, or 'aws_secrets_manager'
+	PasswordSource string `mapstructure:"password_source,omitempty"`
+	//SecretARN is the ARN of the AWS Secrets Manager secret with the password, either the password or a JSON object with the password in the password key
+	SecretARN string `mapstructure:"secret_arn,omitempty"`
+	//PasswordEnv is the environment variable the password of 'BasicAuth' is read from
+	PasswordEnv string `mapstructure:"password_env,omitempty"`
+	//PasswordFile is the file the password of 'BasicAuth' is read from, e.g. a mounted Kubernetes secret, the file is read again when it changed
+	PasswordFile string `mapstructure:"password_file,omitempty"`
+	//SecretRefreshInterval is the interval the secret is fetched again at, e.g. "15m", so the rotated password is used by the new connections, 1h if it's not set
+	SecretRefreshInterval string `mapstructure:"secret_refresh_interval,omitempty"`
+	//CloudSQLInstance is the instance connection name of the Cloud SQL instance used by 'GCPCloudSQLIAM', i.e. project:region:instance
+	CloudSQLInstance string `mapstructure:"cloudsql_instance,omitempty"`
+	//AzureTenantID is the tenant of the service principal used by 'AzureADAuth'
+	AzureTenantID string `mapstructure:"azure_tenant_id,omitempty"`
+	//AzureClientID is the client id of the service principal or of the user-assigned managed identity used by 'AzureADAuth'
+	AzureClientID string `mapstructure:"azure_client_id,omitempty"`
+	//AzureClientSecret is the client secret of the service principal used by 'AzureADAuth', the managed identity is used if it's not set
+	AzureClientSecret string `mapstructure:"azure_client_secret,omitempty"`
+	//TLS is the TLS configuration of the connections, the connections are not encrypted if it's not set, except for 'IAMRDSAuth' and 'AzureADAuth'
+	TLS *TLSConfig `mapstructure:"tls,omitempty"`
+	//SSHTunnel is the bastion host the connections to the database are established through
+	SSHTunnel *SSHTunnelConfig `mapstructure:"ssh_tunnel,omitempty"`
+	//SocketPath is the path of the unix socket of the local MySQL server used by 'SocketAuth' and the 'unix' transport
+	SocketPath string `mapstructure:"socket_path,omitempty"`
+	//WorkloadIdentity is the id of the workload identity extension providing the AWS credentials for 'IAMRDSAuth', the default AWS credential chain is used if it's not set
+	WorkloadIdentity        *config.ComponentID `mapstructure:"workload_identity,omitempty"`
 	confignet.NetAddr       `mapstructure:",squash"`
-	CollectionInterval      string      `mapstructure:"collection_interval,omitempty"`
-	DBQueries               []DBQueries `mapstructure:"db_queries,omitempty"`
-	SetConnMaxLifetime      int         `mapstructure:"setconnmaxlifetimemins,omitempty"`
-	SetMaxOpenConns         int         `mapstructure:"setmaxopenconns,omitempty"`
-	SetMaxIdleConns         int         `mapstructure:"setmaxidleconns,omitempty"`
-	SetMaxNoDatabaseWorkers int         `mapstructure:"setmaxnodatabaseworkers,omitempty"`
+	CollectionInterval      string        `mapstructure:"collection_interval,omitempty"`
+	DBQueries               []DBQueries   `mapstructure:"db_queries,omitempty"`
+	SetConnMaxLifetime      int           `mapstructure:"setconnmaxlifetimemins,omitempty"`
+	SetMaxOpenConns         int           `mapstructure:"setmaxopenconns,omitempty"`
+	SetMaxIdleConns         int           `mapstructure:"setmaxidleconns,omitempty"`
+	SetMaxNoDatabaseWorkers int           `mapstructure:"setmaxnodatabaseworkers,omitempty"`
+	ReadOnlySession         bool          `mapstructure:"read_only_session,omitempty"`
+	EmitMode                string        `mapstructure:"emit_mode,omitempty"`
+	MaxArrayRecordSize      int           `mapstructure:"max_array_record_size,omitempty"`
+	Diagnostics             Diagnostics   `mapstructure:"diagnostics,omitempty"`
+	SchemaRecords           SchemaRecords `mapstructure:"schema_records,omitempty"`
+	Reconnect               Reconnect     `mapstructure:"reconnect,omitempty"`
+	//QueryMetadata attaches the query execution metadata, i.e. scrape start time, query duration and batch sequence, to each log record
+	QueryMetadata bool `mapstructure:"query_metadata,omitempty"`
+	//Fields are set as resource attributes of all log records of the database target, which the Sumo Logic exporter sends as fields
+	Fields map[string]string `mapstructure:"fields,omitempty"`
+	//ReloadOrchestrator is the id of the reload orchestrator extension, the in-memory state of the receiver is handed off to the new instance of the receiver on config reload
+	ReloadOrchestrator *config.ComponentID `mapstructure:"reload_orchestrator,omitempty"`
+	//FreshnessMetricsExporter is the id of the metrics exporter the freshness metrics of the queries with expected_freshness are sent to
+	FreshnessMetricsExporter *config.ComponentID `mapstructure:"freshness_metrics_exporter,omitempty"`
+	//DBDriver is the database driver used for connecting to the database, the name of a registered driver, i.e. 'mysql', 'postgres', 'sqlserver' or 'oracle', the records are collected the same way with all of them
+	DBDriver string `mapstructure:"db_driver,omitempty"`
+	//RecordFormat is the format of the log records of the database records, either 'json', 'map' or 'attributes', the column values are typed by the column types with 'map' and 'attributes'
+	RecordFormat string `mapstructure:"record_format,omitempty"`
+	//RecordPerRow emits the records fetched by a run of a query, or by a batch of fetch_batch_size rows, as log records of a single batch, in the order of the query result and with the observed timestamp of the fetch
+	RecordPerRow bool `mapstructure:"record_per_row,omitempty"`
+	//FetchBatchSize is the number of rows converted and passed to the consumer at once while the rows of a query are scanned in 'per_row' emit mode,
+	//so the result set of a query isn't held in memory entirely, the entire result set is fetched before it's passed to the consumer if it's 0
+	FetchBatchSize int `mapstructure:"fetch_batch_size,omitempty"`
+	//TypedValues sets the column values in the records by the database types of the columns, numbers and booleans as JSON numbers and booleans
+	//and dates and times as RFC 3339 strings in UTC, the values are strings otherwise
+	TypedValues bool `mapstructure:"typed_values,omitempty"`
+	//NullHandling defines how NULL column values are set in the records, either 'sentinel', 'null' or 'omit', i.e. as the null_sentinel string, as JSON null or by omitting the column
+	NullHandling string `mapstructure:"null_handling,omitempty"`
+	//NullSentinel is the string NULL column values are set as with null_handling: 'sentinel', "NULL" if it's not set
+	NullSentinel string `mapstructure:"null_sentinel,omitempty"`
+	//ValidateQueries runs every query with a dry run returning no rows when the receiver starts, so the receiver fails to start
+	//if a query is invalid or its index columns don't exist, instead of collecting no records
+	ValidateQueries bool `mapstructure:"validate_queries,omitempty"`
+	//Targets are the databases the queries are run against, instead of the database of the receiver, e.g. the databases of several tenants or hosts
+	//The records are fetched from each target separately and have the name of their target in the mysql.target resource attribute
+	Targets []Target `mapstructure:"targets,omitempty"`
+	//BinlogCDC captures the inserts, updates and deletes of the tables from the binlog of the MySQL server as they happen, next to the db_queries
+	BinlogCDC *BinlogCDC `mapstructure:"binlog_cdc,omitempty"`
+}
+
+//SchemaRecords enables emitting a record describing the columns of a query result, on the first successful run of the query and on every schema change
+type SchemaRecords struct {
+	Enabled bool `mapstructure:"enabled,omitempty"`
+	//SourceCategory is set as the source category of schema records, so they can be routed separately from the database records
+	SourceCategory string `mapstructure:"source_category,omitempty"`
+}
+
+//Diagnostics enables collecting operational diagnostics of the database server next to the database records
+type Diagnostics struct {
+	//InnoDBStatus captures the latest detected deadlock from SHOW ENGINE INNODB STATUS
+	InnoDBStatus bool `mapstructure:"innodb_status,omitempty"`
+	//ErrorLog fetches new entries of the performance_schema.error_log table
+	ErrorLog bool `mapstructure:"error_log,omitempty"`
+}
+
+//Reconnect is the exponential backoff of the retries while the database is unavailable, when the receiver starts and when a query fails with a connection error
+type Reconnect struct {
+	//InitialInterval is the wait before the first retry, e.g. "1s"
+	InitialInterval string `mapstructure:"initial_interval,omitempty"`
+	//MaxInterval is the upper bound of the wait between the retries, e.g. "30s"
+	MaxInterval string `mapstructure:"max_interval,omitempty"`
+	//MaxElapsedTime is the time after which the retries are given up, e.g. "5m", the retries of the queries are only stopped by the shutdown if it's "0", the wait on start is limited to the default then
+	MaxElapsedTime string `mapstructure:"max_elapsed_time,omitempty"`
 }
 
 type DBQueries struct {
@@ -49,7 +164,62 @@ type DBQueries struct {
 	Query                        string `mapstructure:"query"`
 	IndexColumnName              string `mapstructure:"index_column_name,omitempty"`
 	InitialIndexColumnStartValue string `mapstructure:"initial_index_column_start_value,omitempty"`
-	IndexColumnType              string `mapstructure:"index_column_type,omitempty"`
+	//InitialIndexValue is the value of the index column the records are fetched from while there's no saved state, an integer for 'NUMBER' and a timestamp for 'TIMESTAMP'
+	//It replaces initial_index_column_start_value, which falls back to the default silently when the value cannot be parsed
+	InitialIndexValue string `mapstructure:"initial_index_value,omitempty"`
+	IndexColumnType   string `mapstructure:"index_column_type,omitempty"`
+	//EmitOnChangeOnly emits the records of a query without an index column only when the query result differs from the last emitted one
+	EmitOnChangeOnly bool `mapstructure:"emit_on_change_only,omitempty"`
+	//ExpectedFreshness is the maximal time between new records of a query with an index column, e.g. "15m", a freshness violation is emitted when no new records were fetched for longer
+	ExpectedFreshness string `mapstructure:"expected_freshness,omitempty"`
+	//CollectionInterval is the interval the query is collected at, e.g. "1h", the collection_interval of the receiver is used if it's not set
+	CollectionInterval string `mapstructure:"collection_interval,omitempty"`
+	//Schedule is the cron expression of the times the query is collected at, e.g. "0 2 * * *", the query isn't collected when the receiver starts if it's set
+	Schedule string `mapstructure:"schedule,omitempty"`
+	//IndexColumns are the columns of a composite index the records are fetched incrementally by, e.g. [updated_at, id], for tables without a single monotonic column
+	//The records after the saved values of all the columns are fetched in their order, it cannot be used with index_column_name
+	IndexColumns []string `mapstructure:"index_columns,omitempty"`
+	//IndexColumnTypes are the types of the index_columns, 'NUMBER' or 'TIMESTAMP'
+	IndexColumnTypes []string `mapstructure:"index_column_types,omitempty"`
+	//IndexColumnTimezone is the IANA time zone of the values of a 'TIMESTAMP' index column without a time zone, e.g. Europe/Warsaw, UTC if it's not set
+	//The state value is compared as a time in this time zone and the session time zone is set to it for MySQL
+	IndexColumnTimezone string `mapstructure:"index_column_timezone,omitempty"`
+	//MaxRowsPerFetch is the maximal number of records fetched by a run of a query with index_column_name, the records are fetched in chunks
+	//of this size with the state saved after every chunk, the query isn't limited if it's not set
+	MaxRowsPerFetch int `mapstructure:"max_rows_per_fetch,omitempty"`
+	//QueryTimeout is the maximal time of a run of the query, e.g. "30s", the query is cancelled and skipped until the next collection when it takes longer
+	QueryTimeout string `mapstructure:"query_timeout,omitempty"`
+	//MetricName emits the records of the query as data points of a gauge with this name in a metrics pipeline, instead of log records in a logs pipeline
+	MetricName string `mapstructure:"metric_name,omitempty"`
+	//ValueColumn is the column with the numeric value of the data points, it's required with metric_name
+	ValueColumn string `mapstructure:"value_column,omitempty"`
+	//AttributeColumns are the columns set as attributes of the data points
+	AttributeColumns []string `mapstructure:"attribute_columns,omitempty"`
+	//Attributes are static key-value pairs set as attributes of every log record or data point of the query, e.g. the application name or the environment
+	//The attributes set by the receiver, e.g. mysql.query_id, and the column values of record_format : 'attributes' take precedence
+	Attributes map[string]string `mapstructure:"attributes,omitempty"`
+	//SeverityColumn is the column whose value is set as the severity of the log records of the query, e.g. a level or status column
+	SeverityColumn string `mapstructure:"severity_column,omitempty"`
+	//SeverityMapping maps the severities, i.e. 'trace', 'debug', 'info', 'warn', 'error' or 'fatal', to the values of severity_column,
+	//on top of the mapping of the common level names, e.g. 'warning' to 'warn'
+	SeverityMapping map[string][]string `mapstructure:"severity_mapping,omitempty"`
+	//TimestampColumn is the column whose value is set as the timestamp of the log records of the query, i.e. the time the row event happened
+	TimestampColumn string `mapstructure:"timestamp_column,omitempty"`
+	//TimestampFormat is the Go time layout of the values of timestamp_column, e.g. "02/01/2006 15:04:05", or 'unix', 'unix_ms', 'unix_us' or 'unix_ns'
+	//for numeric timestamps since the Unix epoch, the date and time formats of the database drivers are parsed if it's not set
+	TimestampFormat string `mapstructure:"timestamp_format,omitempty"`
+	//TimestampTimezone is the IANA time zone of the values of timestamp_column without a time zone, e.g. Europe/Warsaw, UTC if it's not set
+	TimestampTimezone string `mapstructure:"timestamp_timezone,omitempty"`
+	//MultiStatement allows the query to consist of several read-only statements, e.g. statements setting session variables with SELECT @var := ...
+	//followed by the statements using them, the records of each result set are emitted with their own record index
+	MultiStatement bool `mapstructure:"multi_statement,omitempty"`
+	//SnapshotDiff compares the result of every run of the query with the previous result by the primary_key_columns,
+	//only the records of the inserted, updated and deleted rows are emitted
+	SnapshotDiff bool `mapstructure:"snapshot_diff,omitempty"`
+	//PrimaryKeyColumns are the columns identifying the rows of the query result compared by snapshot_diff
+	PrimaryKeyColumns []string `mapstructure:"primary_key_columns,omitempty"`
+	//target is the name of the target the query is run against, it prefixes the names of the state files of the query
+	target string
 }
 
 //Validation function for various config entry validation options
@@ -57,8 +227,15 @@ func (cfg *Config) Validate() error {
 
 	var err error
 
-	if cfg.AuthenticationMode != "IAMRDSAuth" && cfg.AuthenticationMode != "BasicAuth" {
-		err = multierr.Append(err, errors.New("authentication_mode should be either of 'IAMRDSAuth' or 'BasicAuth'"))
+	if !isKnownAuthenticationMode(cfg.AuthenticationMode) {
+		err = multierr.Append(err, errors.New("authentication_mode should be either of 'IAMRDSAuth', 'BasicAuth', 'SocketAuth', 'WindowsAuth', 'GCPCloudSQLIAM' or 'AzureADAuth'"))
+	}
+
+	//The options which are not supported by the database of the driver are validated by the driver
+	if d, ok := getDriver(cfg.DBDriver); !ok {
+		err = multierr.Append(err, fmt.Errorf("db_driver should be either of %s", quotedDriverNames()))
+	} else {
+		err = multierr.Append(err, d.validate(cfg))
 	}
 
 	if len(cfg.PasswordType) != 0 && cfg.PasswordType != "plaintext" && cfg.PasswordType != "encrypted" {
@@ -71,36 +248,159 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if passwordErr := validatePasswordSource(cfg); passwordErr != nil {
+		err = multierr.Append(err, passwordErr)
+	}
+
+	if passwordErr := validatePasswordEnvAndFile(cfg); passwordErr != nil {
+		err = multierr.Append(err, passwordErr)
+	}
+
 	if cfg.AuthenticationMode == "IAMRDSAuth" {
 		if len(cfg.EncryptSecretPath) != 0 {
 			err = multierr.Append(err, errors.New("encrypt_secret_path should be empty"))
 		}
 	}
 
+	if cloudSQLErr := validateCloudSQLIAM(cfg); cloudSQLErr != nil {
+		err = multierr.Append(err, cloudSQLErr)
+	}
+
+	if dbHostsErr := validateDBHosts(cfg); dbHostsErr != nil {
+		err = multierr.Append(err, dbHostsErr)
+	}
+
+	if reconnectErr := validateReconnect(cfg.Reconnect); reconnectErr != nil {
+		err = multierr.Append(err, reconnectErr)
+	}
+
+	if tlsErr := validateTLS(cfg); tlsErr != nil {
+		err = multierr.Append(err, tlsErr)
+	}
+
+	if sshTunnelErr := validateSSHTunnel(cfg); sshTunnelErr != nil {
+		err = multierr.Append(err, sshTunnelErr)
+	}
+
+	if azureADErr := validateAzureADAuth(cfg); azureADErr != nil {
+		err = multierr.Append(err, azureADErr)
+	}
+
 	if cfg.AuthenticationMode == "IAMRDSAuth" && len(cfg.Region) == 0 && len(cfg.AWSCertificatePath) == 0 {
 		err = multierr.Append(err, errors.New("require aws region and aws certificate path for authentication_mode : 'IAMRDSAuth'"))
 	}
 
-	if len(cfg.DBHost) == 0 {
+	//'SocketAuth' relies on the auth_socket/unix_socket plugin of the server, which identifies the user by the OS user of the process connected to the unix socket
+	if cfg.AuthenticationMode == "SocketAuth" {
+		if len(cfg.Password) != 0 || len(cfg.PasswordType) != 0 || len(cfg.EncryptSecretPath) != 0 {
+			err = multierr.Append(err, errors.New("password, password_type and encrypt_secret_path should be empty for authentication_mode : 'SocketAuth'"))
+		}
+		if len(cfg.Transport) != 0 && cfg.Transport != "unix" {
+			err = multierr.Append(err, errors.New("authentication_mode : 'SocketAuth' can only be used with the 'unix' transport"))
+		}
+		if len(cfg.ProxyURL) != 0 {
+			err = multierr.Append(err, errors.New("proxy_url cannot be used with authentication_mode : 'SocketAuth'"))
+		}
+	} else if len(cfg.DBHost) == 0 && len(cfg.DBHosts) == 0 && cfg.AuthenticationMode != "GCPCloudSQLIAM" && cfg.Transport != "unix" && !targetsSet(cfg, func(target Target) string { return target.DBHost }) {
 		err = multierr.Append(err, errors.New("dbhost cannot be empty"))
 	}
 
-	if len(cfg.Database) == 0 {
+	//The 'unix' transport connects to the unix socket of socket_path with the password of 'BasicAuth'
+	if cfg.Transport == "unix" && cfg.AuthenticationMode != "BasicAuth" && cfg.AuthenticationMode != "SocketAuth" {
+		err = multierr.Append(err, errors.New("the 'unix' transport can only be used with authentication_mode : 'BasicAuth' or 'SocketAuth'"))
+	}
+	if len(cfg.SocketPath) != 0 {
+		if cfg.Transport != "unix" && cfg.AuthenticationMode != "SocketAuth" {
+			err = multierr.Append(err, errors.New("socket_path can only be used with the 'unix' transport or authentication_mode : 'SocketAuth'"))
+		}
+		if !filepath.IsAbs(cfg.SocketPath) {
+			err = multierr.Append(err, errors.New("socket_path should be an absolute path"))
+		}
+	}
+
+	if len(cfg.Database) == 0 && !targetsSet(cfg, func(target Target) string { return target.Database }) {
 		err = multierr.Append(err, errors.New("database cannot be empty"))
 	}
 
+	if targetsErr := validateTargets(cfg); targetsErr != nil {
+		err = multierr.Append(err, targetsErr)
+	}
+
+	if binlogErr := validateBinlogCDC(cfg); binlogErr != nil {
+		err = multierr.Append(err, binlogErr)
+	}
+
+	if len(cfg.ProxyURL) != 0 {
+		if proxyErr := validateProxyURL(cfg.ProxyURL); proxyErr != nil {
+			err = multierr.Append(err, proxyErr)
+		}
+		if len(cfg.Transport) != 0 && cfg.Transport != "tcp" {
+			err = multierr.Append(err, errors.New("proxy_url can only be used with the 'tcp' transport"))
+		}
+	}
+
+	//The proxy of the environment variables is only used for the connections dialed over tcp by the receiver
+	if cfg.ProxyFromEnvironment {
+		if cfg.AuthenticationMode == "SocketAuth" || cfg.AuthenticationMode == "GCPCloudSQLIAM" {
+			err = multierr.Append(err, fmt.Errorf("proxy_from_environment cannot be used with authentication_mode : '%s'", cfg.AuthenticationMode))
+		}
+		if cfg.SSHTunnel != nil {
+			err = multierr.Append(err, errors.New("proxy_from_environment and ssh_tunnel cannot be used together"))
+		}
+		if len(cfg.Transport) != 0 && cfg.Transport != "tcp" {
+			err = multierr.Append(err, errors.New("proxy_from_environment can only be used with the 'tcp' transport"))
+		}
+	}
+
 	if cfg.SetMaxNoDatabaseWorkers != 0 {
 		if cfg.SetMaxNoDatabaseWorkers <= 0 || cfg.SetMaxNoDatabaseWorkers > 10 {
 			err = multierr.Append(err, errors.New("database workers should be 1 to 10"))
 		}
 	}
 
+	if len(cfg.EmitMode) != 0 && cfg.EmitMode != emitModePerRow && cfg.EmitMode != emitModePerScrapeArray {
+		err = multierr.Append(err, errors.New("emit_mode should be either of 'per_row' or 'per_scrape_array'"))
+	}
+
+	if len(cfg.RecordFormat) != 0 && cfg.RecordFormat != recordFormatJSON && cfg.RecordFormat != recordFormatMap && cfg.RecordFormat != recordFormatAttributes {
+		err = multierr.Append(err, errors.New("record_format should be either of 'json', 'map' or 'attributes'"))
+	}
+
+	//The arrays of records of 'per_scrape_array' are JSON strings
+	if cfg.EmitMode == emitModePerScrapeArray && len(cfg.RecordFormat) != 0 && cfg.RecordFormat != recordFormatJSON {
+		err = multierr.Append(err, errors.New("record_format : 'json' is required with emit_mode : 'per_scrape_array'"))
+	}
+
+	if cfg.EmitMode == emitModePerScrapeArray && cfg.RecordPerRow {
+		err = multierr.Append(err, errors.New("record_per_row cannot be used with emit_mode : 'per_scrape_array'"))
+	}
+
+	if cfg.MaxArrayRecordSize < 0 {
+		err = multierr.Append(err, errors.New("max_array_record_size cannot be negative"))
+	}
+
+	if len(cfg.NullHandling) != 0 && cfg.NullHandling != nullHandlingSentinel && cfg.NullHandling != nullHandlingNull && cfg.NullHandling != nullHandlingOmit {
+		err = multierr.Append(err, errors.New("null_handling should be either of 'sentinel', 'null' or 'omit'"))
+	}
+
+	if len(cfg.NullSentinel) != 0 && len(cfg.NullHandling) != 0 && cfg.NullHandling != nullHandlingSentinel {
+		err = multierr.Append(err, errors.New("null_sentinel can only be used with null_handling : 'sentinel'"))
+	}
+
+	if cfg.FetchBatchSize < 0 {
+		err = multierr.Append(err, errors.New("fetch_batch_size cannot be negative"))
+	}
+
+	for key := range cfg.Fields {
+		if len(key) == 0 {
+			err = multierr.Append(err, errors.New("fields cannot contain an empty key"))
+		}
+	}
+
 	var queryIds []string
-	var queryIndexColumnTypes []string
 	var size = len(cfg.DBQueries)
 	for i := 0; i < size; i++ {
 		queryIds = append(queryIds, cfg.DBQueries[i].QueryId)
-		queryIndexColumnTypes = append(queryIndexColumnTypes, cfg.DBQueries[i].IndexColumnType)
 	}
 	queryIdCount := make(map[string]int)
 	for _, item := range queryIds {
@@ -116,12 +416,90 @@ func (cfg *Config) Validate() error {
 			err = multierr.Append(err, errors.New("multiple queries have the same queryId which is not allowed"))
 		}
 	}
-	for _, item := range queryIndexColumnTypes {
-		if len(item) != 0 {
-			if item != "NUMBER" && item != "TIMESTAMP" {
-				err = multierr.Append(err, errors.New("indexcolumtype in queries can only be 'NUMBER' or 'TIMESTAMP'"))
+	if len(cfg.CollectionInterval) != 0 {
+		if _, intervalErr := parseCollectionInterval(cfg.CollectionInterval); intervalErr != nil {
+			err = multierr.Append(err, fmt.Errorf("invalid collection_interval: %w", intervalErr))
+		}
+	}
+	for _, dbquery := range cfg.DBQueries {
+		if len(strings.TrimSpace(dbquery.Query)) == 0 {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' is empty, the query to run has to be set in query", dbquery.QueryId))
+		} else if readOnlyErr := validateReadOnlyStatements(dbquery.Query, dbquery.MultiStatement, cfg.DBDriver); readOnlyErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' is not a read-only query: %w", dbquery.QueryId, readOnlyErr))
+		}
+		if len(dbquery.IndexColumns) != 0 {
+			if indexErr := validateIndexColumns(dbquery); indexErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid index_columns: %w", dbquery.QueryId, indexErr))
+			}
+		} else if len(strings.TrimSpace(dbquery.IndexColumnName)) != 0 {
+			if indexErr := validateIndexColumn(dbquery); indexErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid index_column_type: %w", dbquery.QueryId, indexErr))
+			}
+		} else if len(dbquery.IndexColumnType) != 0 && dbquery.IndexColumnType != "NUMBER" && dbquery.IndexColumnType != "TIMESTAMP" {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid index_column_type: it can only be 'NUMBER' or 'TIMESTAMP'", dbquery.QueryId))
+		}
+		if dbquery.MultiStatement && isIncrementalQuery(&dbquery) {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' cannot use multi_statement with index_column_name or index_columns", dbquery.QueryId))
+		}
+		if dbquery.EmitOnChangeOnly && isIncrementalQuery(&dbquery) {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' cannot use emit_on_change_only with index_column_name or index_columns", dbquery.QueryId))
+		}
+		if len(dbquery.ExpectedFreshness) != 0 {
+			if freshnessErr := validateExpectedFreshness(dbquery); freshnessErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid expected_freshness: %w", dbquery.QueryId, freshnessErr))
+			}
+		}
+		if len(dbquery.CollectionInterval) != 0 {
+			if intervalErr := validateCollectionInterval(dbquery); intervalErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid collection_interval: %w", dbquery.QueryId, intervalErr))
 			}
 		}
+		if len(dbquery.Schedule) != 0 {
+			if _, scheduleErr := parseCronSchedule(dbquery.Schedule); scheduleErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid schedule: %w", dbquery.QueryId, scheduleErr))
+			}
+		}
+		if len(dbquery.IndexColumnTimezone) != 0 {
+			if timezoneErr := validateIndexColumnTimezone(dbquery); timezoneErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid index_column_timezone: %w", dbquery.QueryId, timezoneErr))
+			}
+		}
+		if dbquery.MaxRowsPerFetch < 0 {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid max_rows_per_fetch, it has to be positive", dbquery.QueryId))
+		} else if dbquery.MaxRowsPerFetch > 0 && !isIncrementalQuery(&dbquery) {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' can only use max_rows_per_fetch with index_column_name or index_columns", dbquery.QueryId))
+		}
+		if len(dbquery.QueryTimeout) != 0 {
+			if timeoutErr := validateQueryTimeout(dbquery); timeoutErr != nil {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid query_timeout: %w", dbquery.QueryId, timeoutErr))
+			}
+		}
+		if initialErr := validateInitialIndexValue(dbquery); initialErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid initial_index_value: %w", dbquery.QueryId, initialErr))
+		}
+		for key := range dbquery.Attributes {
+			if len(key) == 0 {
+				err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid attributes: it cannot contain an empty key", dbquery.QueryId))
+			}
+		}
+		if severityErr := validateSeverityMapping(dbquery); severityErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid severity settings: %w", dbquery.QueryId, severityErr))
+		} else if len(dbquery.SeverityColumn) != 0 && cfg.EmitMode == emitModePerScrapeArray {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' cannot use severity_column with emit_mode : 'per_scrape_array'", dbquery.QueryId))
+		}
+		if snapshotErr := validateSnapshotDiff(dbquery); snapshotErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid snapshot_diff settings: %w", dbquery.QueryId, snapshotErr))
+		} else if dbquery.SnapshotDiff && (cfg.EmitMode == emitModePerScrapeArray || cfg.RecordPerRow) {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' cannot use snapshot_diff with emit_mode : 'per_scrape_array' or record_per_row", dbquery.QueryId))
+		}
+		if timestampErr := validateTimestampColumn(dbquery); timestampErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid timestamp settings: %w", dbquery.QueryId, timestampErr))
+		} else if len(dbquery.TimestampColumn) != 0 && cfg.EmitMode == emitModePerScrapeArray {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' cannot use timestamp_column with emit_mode : 'per_scrape_array'", dbquery.QueryId))
+		}
+		if metricErr := validateQueryMetric(dbquery); metricErr != nil {
+			err = multierr.Append(err, fmt.Errorf("query with queryid '%s' has invalid metric settings: %w", dbquery.QueryId, metricErr))
+		}
 	}
 
 	return err
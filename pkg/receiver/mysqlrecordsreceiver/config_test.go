@@ -219,3 +219,819 @@ func TestInValidConfigforBasicAuthWDBQueriesWInValidIndexColumnType(t *testing.T
 	cfg.Database = "information_schema"
 	require.Error(t, cfg.Validate())
 }
+
+func TestConfigWDBQueries(t *testing.T) {
+	for _, tc := range []struct {
+		dbquery DBQueries
+		err     string
+	}{
+		{dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"}},
+		{dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", Attributes: map[string]string{"app": "billing", "environment": "production"}}},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", Attributes: map[string]string{"": "billing"}},
+			err:     "query with queryid 'Q1' has invalid attributes: it cannot contain an empty key",
+		},
+		{dbquery: DBQueries{QueryId: "Q1", Query: "select * from events", SeverityColumn: "level", SeverityMapping: map[string][]string{"error": {"E"}}}},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from events", SeverityMapping: map[string][]string{"error": {"E"}}},
+			err:     "query with queryid 'Q1' has invalid severity settings: severity_mapping can only be used with severity_column",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: " "},
+			err:     "query with queryid 'Q1' is empty, the query to run has to be set in query",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID"},
+			err:     "query with queryid 'Q1' has invalid index_column_type: index_column_type has to be set with index_column_name, either 'NUMBER' or 'TIMESTAMP'",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "DATE"},
+			err:     "query with queryid 'Q1' has invalid index_column_type: index_column_type 'DATE' is not supported, it can only be 'NUMBER' or 'TIMESTAMP'",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnType: "DATE"},
+			err:     "query with queryid 'Q1' has invalid index_column_type: it can only be 'NUMBER' or 'TIMESTAMP'",
+		},
+		{dbquery: DBQueries{QueryId: "Q1", Query: "select @total := count(*) from orders; select * from orders where total > @total", MultiStatement: true}},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders; select * from refunds"},
+			err:     "query with queryid 'Q1' is not a read-only query: query contains multiple statements",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "set @total = 1; select * from orders", MultiStatement: true},
+			err:     "query with queryid 'Q1' is not a read-only query: statement SET is not allowed, only SELECT, WITH, SHOW, DESCRIBE and EXPLAIN statements are allowed",
+		},
+		{
+			dbquery: DBQueries{QueryId: "Q1", Query: "select * from orders; select * from refunds", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", MultiStatement: true},
+			err:     "query with queryid 'Q1' cannot use multi_statement with index_column_name or index_columns",
+		},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{tc.dbquery}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if len(tc.err) == 0 {
+			require.NoError(t, cfg.Validate())
+		} else {
+			require.EqualError(t, cfg.Validate(), tc.err)
+		}
+	}
+}
+
+func TestInValidConfigforBasicAuthWDBQueriesWWriteQuery(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBQueries = make([]DBQueries, 1)
+	cfg.DBQueries[0].QueryId = "Q1"
+	cfg.DBQueries[0].Query = "delete from persons"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWInValidEmitMode(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.EmitMode = "garbage"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWPerScrapeArrayEmitMode(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.EmitMode = "per_scrape_array"
+	cfg.MaxArrayRecordSize = 65536
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWProxyURL(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ProxyURL = "socks5://jump.example.com:1080"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWInValidProxyURL(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ProxyURL = "ftp://jump.example.com:21"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWProxyURLWUnixTransport(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.ProxyURL = "socks5://jump.example.com:1080"
+	cfg.Transport = "unix"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWFields(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Fields = map[string]string{"app": "billing"}
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWEmptyFieldKey(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Fields = map[string]string{"": "billing"}
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWDBQueriesWEmitOnChangeOnly(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBQueries = make([]DBQueries, 1)
+	cfg.DBQueries[0].QueryId = "Q1"
+	cfg.DBQueries[0].Query = "select * from settings"
+	cfg.DBQueries[0].EmitOnChangeOnly = true
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWDBQueriesWEmitOnChangeOnlyWIndexColumn(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBQueries = make([]DBQueries, 1)
+	cfg.DBQueries[0].QueryId = "Q1"
+	cfg.DBQueries[0].Query = "select * from persons"
+	cfg.DBQueries[0].IndexColumnName = "PersonID"
+	cfg.DBQueries[0].IndexColumnType = "NUMBER"
+	cfg.DBQueries[0].EmitOnChangeOnly = true
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWDBQueriesWExpectedFreshness(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBQueries = make([]DBQueries, 1)
+	cfg.DBQueries[0].QueryId = "Q1"
+	cfg.DBQueries[0].Query = "select * from orders"
+	cfg.DBQueries[0].IndexColumnName = "OrderID"
+	cfg.DBQueries[0].IndexColumnType = "NUMBER"
+	cfg.DBQueries[0].ExpectedFreshness = "15m"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWDBQueriesWExpectedFreshness(t *testing.T) {
+	for _, dbquery := range []DBQueries{
+		{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", ExpectedFreshness: "15 minutes"},
+		{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", ExpectedFreshness: "-15m"},
+		{QueryId: "Q1", Query: "select * from orders", ExpectedFreshness: "15m"},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{dbquery}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		require.Error(t, cfg.Validate())
+	}
+}
+
+func TestConfigWQueryTimeout(t *testing.T) {
+	for timeout, valid := range map[string]bool{"30s": true, "2m": true, "30 seconds": false, "0s": false, "-30s": false} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders", QueryTimeout: timeout}}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if valid {
+			require.NoError(t, cfg.Validate(), timeout)
+		} else {
+			require.Error(t, cfg.Validate(), timeout)
+		}
+	}
+}
+
+func TestConfigWQueryCollectionInterval(t *testing.T) {
+	for interval, valid := range map[string]bool{"1m": true, "1h": true, "hourly": false, "0s": false, "-1m": false} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders", CollectionInterval: interval}}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if valid {
+			require.NoError(t, cfg.Validate(), interval)
+		} else {
+			require.Error(t, cfg.Validate(), interval)
+		}
+	}
+}
+
+func TestConfigWReceiverCollectionInterval(t *testing.T) {
+	for interval, valid := range map[string]bool{"": true, "10s": true, "hourly": false, "0s": false} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.CollectionInterval = interval
+		cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders"}}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if valid {
+			require.NoError(t, cfg.Validate(), interval)
+		} else {
+			require.Error(t, cfg.Validate(), interval)
+		}
+	}
+}
+
+func TestConfigWQuerySchedule(t *testing.T) {
+	for _, dbquery := range []struct {
+		schedule           string
+		collectionInterval string
+		valid              bool
+	}{
+		{schedule: "0 2 * * *", valid: true},
+		{schedule: "@daily", valid: true},
+		{schedule: "0 2 * *", valid: false},
+		{schedule: "0 25 * * *", valid: false},
+		{schedule: "0 2 * * *", collectionInterval: "1h", valid: false},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from report", Schedule: dbquery.schedule, CollectionInterval: dbquery.collectionInterval}}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if dbquery.valid {
+			require.NoError(t, cfg.Validate(), dbquery.schedule)
+		} else {
+			require.Error(t, cfg.Validate(), dbquery.schedule)
+		}
+	}
+}
+
+func TestConfigWMaxRowsPerFetch(t *testing.T) {
+	for _, dbquery := range []struct {
+		query DBQueries
+		valid bool
+	}{
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", MaxRowsPerFetch: 1000}, valid: true},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", MaxRowsPerFetch: -1}, valid: false},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", MaxRowsPerFetch: 1000}, valid: false},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{dbquery.query}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if dbquery.valid {
+			require.NoError(t, cfg.Validate())
+		} else {
+			require.Error(t, cfg.Validate())
+		}
+	}
+}
+
+func TestConfigWPasswordSource(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(cfg *Config)
+		valid  bool
+	}{
+		{name: "password from config", modify: func(cfg *Config) { cfg.PasswordSource = passwordSourceConfig }, valid: true},
+		{name: "password from secret", modify: func(cfg *Config) {
+			cfg.Password = ""
+			cfg.PasswordSource = passwordSourceAWSSecretsManager
+			cfg.SecretARN = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:prod/mysql-AbCdEf"
+			cfg.SecretRefreshInterval = "15m"
+		}, valid: true},
+		{name: "secret with password", modify: func(cfg *Config) {
+			cfg.PasswordSource = passwordSourceAWSSecretsManager
+			cfg.SecretARN = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:prod/mysql-AbCdEf"
+		}},
+		{name: "missing secret_arn", modify: func(cfg *Config) {
+			cfg.Password = ""
+			cfg.PasswordSource = passwordSourceAWSSecretsManager
+		}},
+		{name: "invalid secret_refresh_interval", modify: func(cfg *Config) {
+			cfg.Password = ""
+			cfg.PasswordSource = passwordSourceAWSSecretsManager
+			cfg.SecretARN = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:prod/mysql-AbCdEf"
+			cfg.SecretRefreshInterval = "-1m"
+		}},
+		{name: "secret_arn without password_source", modify: func(cfg *Config) {
+			cfg.SecretARN = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:prod/mysql-AbCdEf"
+		}},
+		{name: "unknown password_source", modify: func(cfg *Config) { cfg.PasswordSource = "vault" }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.AuthenticationMode = "BasicAuth"
+			cfg.Username = "mysqluser"
+			cfg.Password = "userpass"
+			cfg.DBPort = "3306"
+			cfg.DBHost = "localhost"
+			cfg.Database = "information_schema"
+			tc.modify(cfg)
+			if tc.valid {
+				require.NoError(t, cfg.Validate())
+			} else {
+				require.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestConfigWIndexColumns(t *testing.T) {
+	for _, dbquery := range []struct {
+		query DBQueries
+		valid bool
+	}{
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}, InitialIndexValue: "2022-08-01 10:00:00", MaxRowsPerFetch: 1000, IndexColumnTimezone: "Europe/Warsaw"}, valid: true},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}, InitialIndexValue: "100"}, valid: false},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP"}}, valid: false},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}, EmitOnChangeOnly: true}, valid: false},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", IndexColumns: []string{"UpdatedAt"}, IndexColumnTypes: []string{"TIMESTAMP"}}, valid: false},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{dbquery.query}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if dbquery.valid {
+			require.NoError(t, cfg.Validate())
+		} else {
+			require.Error(t, cfg.Validate())
+		}
+	}
+}
+
+func TestConfigWIndexColumnTimezone(t *testing.T) {
+	for _, dbquery := range []struct {
+		query DBQueries
+		valid bool
+	}{
+		{query: DBQueries{QueryId: "Q1", Query: "select * from logins", IndexColumnName: "LoginTime", IndexColumnType: "TIMESTAMP", IndexColumnTimezone: "Europe/Warsaw"}, valid: true},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from logins", IndexColumnName: "LoginTime", IndexColumnType: "TIMESTAMP", IndexColumnTimezone: "Mars/Olympus_Mons"}, valid: false},
+		{query: DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", IndexColumnTimezone: "UTC"}, valid: false},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.DBQueries = []DBQueries{dbquery.query}
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if dbquery.valid {
+			require.NoError(t, cfg.Validate())
+		} else {
+			require.Error(t, cfg.Validate())
+		}
+	}
+}
+
+func TestInValidConfigWNegativeFetchBatchSize(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.FetchBatchSize = -1
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "3306"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.EqualError(t, cfg.Validate(), "fetch_batch_size cannot be negative")
+}
+
+func TestConfigWNullHandling(t *testing.T) {
+	for _, tc := range []struct {
+		handling string
+		sentinel string
+		err      string
+	}{
+		{handling: nullHandlingSentinel, sentinel: "N/A"},
+		{handling: nullHandlingNull},
+		{handling: nullHandlingOmit},
+		{sentinel: "N/A"},
+		{handling: "skip", err: "null_handling should be either of 'sentinel', 'null' or 'omit'"},
+		{handling: nullHandlingOmit, sentinel: "N/A", err: "null_sentinel can only be used with null_handling : 'sentinel'"},
+	} {
+		factory := NewFactory()
+		cfg := factory.CreateDefaultConfig().(*Config)
+		cfg.NullHandling = tc.handling
+		cfg.NullSentinel = tc.sentinel
+		cfg.AuthenticationMode = "BasicAuth"
+		cfg.Username = "mysqluser"
+		cfg.Password = "userpass"
+		cfg.DBPort = "3306"
+		cfg.DBHost = "localhost"
+		cfg.Database = "information_schema"
+		if len(tc.err) == 0 {
+			require.NoError(t, cfg.Validate())
+		} else {
+			require.EqualError(t, cfg.Validate(), tc.err)
+		}
+	}
+}
+
+func TestValidConfigforSocketAuth(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Username = "mysql"
+	cfg.SocketPath = "/var/lib/mysql/mysql.sock"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidConfigforBasicAuthWUnixTransport(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Transport = "unix"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.SocketPath = "/var/lib/mysql/mysql.sock"
+	cfg.Database = "information_schema"
+	require.NoError(t, cfg.Validate())
+
+	cfg.SocketPath = "mysql.sock"
+	require.Error(t, cfg.Validate())
+
+	cfg.SocketPath = ""
+	require.NoError(t, cfg.Validate())
+
+	cfg.AuthenticationMode = "IAMRDSAuth"
+	cfg.Region = "us-east-1"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforBasicAuthWSocketPathWTCPTransport(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Transport = "tcp"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.SocketPath = "/var/lib/mysql/mysql.sock"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforSocketAuthWPassword(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Username = "mysql"
+	cfg.Password = "userpass"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforSocketAuthWTCPTransport(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Username = "mysql"
+	cfg.Transport = "tcp"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforSocketAuthWProxyURL(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Username = "mysql"
+	cfg.ProxyURL = "socks5://jump.example.com:1080"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforPostgresDriver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "postgres"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "postgresuser"
+	cfg.Password = "userpass"
+	cfg.DBPort = "5432"
+	cfg.DBHost = "localhost"
+	cfg.Database = "postgres"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforInValidDriver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "db2"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforPostgresDriverWSocketAuth(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "postgres"
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Username = "postgres"
+	cfg.Database = "postgres"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforPostgresDriverWDiagnostics(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "postgres"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "postgresuser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "postgres"
+	cfg.Diagnostics.InnoDBStatus = true
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforSQLServerDriver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "sqlserver"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "sa"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "master"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidConfigforSQLServerDriverWWindowsAuth(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "sqlserver"
+	cfg.AuthenticationMode = "WindowsAuth"
+	cfg.Username = `CORP\collector`
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "master"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforSQLServerDriverWWindowsAuthWODomain(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "sqlserver"
+	cfg.AuthenticationMode = "WindowsAuth"
+	cfg.Username = "collector"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "master"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforMySQLDriverWWindowsAuth(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "WindowsAuth"
+	cfg.Username = `CORP\collector`
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "information_schema"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforSQLServerDriverWIAMRDSAuth(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "sqlserver"
+	cfg.AuthenticationMode = "IAMRDSAuth"
+	cfg.Username = "sa"
+	cfg.Region = "us-east-1"
+	cfg.AWSCertificatePath = "/etc/ssl/rds.pem"
+	cfg.DBHost = "localhost"
+	cfg.Database = "master"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforSQLServerDriverWReadOnlySession(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "sqlserver"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "sa"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "master"
+	cfg.ReadOnlySession = true
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforOracleDriver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "oracle"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "collector"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "ORCLPDB1"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforOracleDriverWReadOnlySession(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "oracle"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "collector"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "ORCLPDB1"
+	cfg.ReadOnlySession = true
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforOracleDriverWMultiStatement(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DBDriver = "oracle"
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "collector"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.Database = "ORCLPDB1"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders; select * from refunds", MultiStatement: true}}
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforMetricQuery(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select Status, count(*) as Total from Orders group by Status", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Status"}}}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforMetricQueryWOValueColumn(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select count(*) as Total from Orders", MetricName: "orders.count"}}
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforRecordFormat(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.RecordFormat = "attributes"
+	require.NoError(t, cfg.Validate())
+
+	cfg.EmitMode = emitModePerScrapeArray
+	require.Error(t, cfg.Validate())
+
+	cfg.EmitMode = emitModePerRow
+	cfg.RecordFormat = "xml"
+	require.Error(t, cfg.Validate())
+}
+
+func TestInValidConfigforRecordPerRowWPerScrapeArray(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.RecordPerRow = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.EmitMode = emitModePerScrapeArray
+	require.Error(t, cfg.Validate())
+}
+
+func TestValidConfigforSnapshotDiff(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from persons", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}}}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestInValidConfigforSnapshotDiff(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from persons", SnapshotDiff: true}}
+	require.Error(t, cfg.Validate())
+
+	cfg.DBQueries[0].PrimaryKeyColumns = []string{"PersonID"}
+	cfg.EmitMode = emitModePerScrapeArray
+	require.Error(t, cfg.Validate())
+
+	cfg.EmitMode = emitModePerRow
+	cfg.RecordPerRow = true
+	require.Error(t, cfg.Validate())
+}
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// 2022-08-01 is a Monday
+	now := time.Date(2022, 8, 1, 10, 17, 30, 0, time.UTC)
+	for spec, expected := range map[string]time.Time{
+		"* * * * *":          time.Date(2022, 8, 1, 10, 18, 0, 0, time.UTC),
+		"*/15 * * * *":       time.Date(2022, 8, 1, 10, 30, 0, 0, time.UTC),
+		"0 2 * * *":          time.Date(2022, 8, 2, 2, 0, 0, 0, time.UTC),
+		"30 1-3 * * 6,0":     time.Date(2022, 8, 6, 1, 30, 0, 0, time.UTC),
+		"0 0 * * 7":          time.Date(2022, 8, 7, 0, 0, 0, 0, time.UTC),
+		"0 0 15 * 3":         time.Date(2022, 8, 3, 0, 0, 0, 0, time.UTC),
+		"0 12 29 2 *":        time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+		"5/20 22-23/1 * * *": time.Date(2022, 8, 1, 22, 5, 0, 0, time.UTC),
+		"@hourly":            time.Date(2022, 8, 1, 11, 0, 0, 0, time.UTC),
+		"@monthly":           time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := parseCronSchedule(spec)
+		require.NoError(t, err, spec)
+		require.Equal(t, expected, schedule.next(now), spec)
+	}
+}
+
+func TestCronScheduleNextInLocation(t *testing.T) {
+	location := time.FixedZone("IST", 5*3600+1800)
+	schedule, err := parseCronSchedule("0 * * * *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 8, 1, 11, 0, 0, 0, location), schedule.next(time.Date(2022, 8, 1, 10, 17, 0, 0, location)))
+}
+
+func TestParseInvalidCronSchedule(t *testing.T) {
+	for spec, expected := range map[string]string{
+		"* * * *":      "expected 5 fields, found 4",
+		"60 * * * *":   "invalid minute: '60' is out of the range 0-59",
+		"* 5-1 * * *":  "invalid hour: '5-1' is out of the range 0-23",
+		"* * 0 * *":    "invalid day of month: '0' is out of the range 1-31",
+		"* * * jan *":  "invalid month: invalid value 'jan'",
+		"* * * * */0":  "invalid day of week: invalid step '0'",
+		"0 0 30 2 *":   "it never matches",
+		"@fortnightly": "expected 5 fields, found 1",
+	} {
+		_, err := parseCronSchedule(spec)
+		require.EqualError(t, err, expected, spec)
+	}
+}
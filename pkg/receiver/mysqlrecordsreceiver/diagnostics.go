@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	//diagnosticTypeAttribute is the log attribute which marks the kind of diagnostics a log record carries
+	diagnosticTypeAttribute = "mysql.diagnostic_type"
+	diagnosticTypeDeadlock  = "deadlock"
+	diagnosticTypeErrorLog  = "error_log"
+
+	//errorLogQueryId identifies the error log query in logs and in the state file keeping the last fetched LOGGED value
+	errorLogQueryId = "mysql_diagnostics_error_log"
+	errorLogQuery   = "select LOGGED, THREAD_ID, PRIO, ERROR_CODE, SUBSYSTEM, DATA from performance_schema.error_log"
+
+	innoDBDeadlockSection = "LATEST DETECTED DEADLOCK"
+)
+
+// deadlock is the structured representation of the latest detected deadlock section of SHOW ENGINE INNODB STATUS.
+type deadlock struct {
+	Timestamp             string                `json:"timestamp"`
+	Transactions          []deadlockTransaction `json:"transactions"`
+	RolledBackTransaction string                `json:"rolled_back_transaction,omitempty"`
+}
+
+type deadlockTransaction struct {
+	Number        string   `json:"number"`
+	ID            string   `json:"id,omitempty"`
+	ThreadID      string   `json:"thread_id,omitempty"`
+	Query         string   `json:"query,omitempty"`
+	HoldsLocks    []string `json:"holds_locks,omitempty"`
+	WaitsForLocks []string `json:"waits_for_locks,omitempty"`
+}
+
+// parseLatestDeadlock extracts the LATEST DETECTED DEADLOCK section from the output of SHOW ENGINE INNODB STATUS.
+// It returns nil when no deadlock was detected since the server started.
+func parseLatestDeadlock(status string) *deadlock {
+	start := strings.Index(status, innoDBDeadlockSection)
+	if start < 0 {
+		return nil
+	}
+
+	const (
+		partInfo = iota
+		partQuery
+		partHolds
+		partWaits
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(status[start+len(innoDBDeadlockSection):]))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(status)+1)
+
+	result := &deadlock{}
+	var trx *deadlockTransaction
+	part := partInfo
+	// the section header is followed by a line of dashes, the next line of dashes starts the next section
+	headerSkipped := false
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if isSectionBorder(line) {
+			if !headerSkipped {
+				headerSkipped = true
+				continue
+			}
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "*** WE ROLL BACK TRANSACTION"):
+			result.RolledBackTransaction = transactionNumber(line)
+			trx = nil
+		case strings.HasPrefix(line, "*** (") && strings.HasSuffix(line, "TRANSACTION:"):
+			result.Transactions = append(result.Transactions, deadlockTransaction{Number: transactionNumber(line)})
+			trx = &result.Transactions[len(result.Transactions)-1]
+			part = partInfo
+		case strings.HasPrefix(line, "*** (") && strings.Contains(line, "HOLDS THE LOCK"):
+			part = partHolds
+		case strings.HasPrefix(line, "*** (") && strings.Contains(line, "WAITING FOR THIS LOCK"):
+			part = partWaits
+		case trx == nil:
+			if result.Timestamp == "" && len(result.Transactions) == 0 {
+				if fields := strings.Fields(line); len(fields) >= 2 {
+					result.Timestamp = fields[0] + " " + fields[1]
+				}
+			}
+		case part == partInfo:
+			if strings.HasPrefix(line, "TRANSACTION ") {
+				trx.ID = strings.TrimSuffix(strings.Fields(line)[1], ",")
+			} else if strings.HasPrefix(line, "MySQL thread id ") {
+				trx.ThreadID = strings.TrimSuffix(strings.Fields(line)[3], ",")
+				part = partQuery
+			}
+		case part == partQuery:
+			if trx.Query != "" {
+				trx.Query += "\n"
+			}
+			trx.Query += line
+		case part == partHolds, part == partWaits:
+			if !strings.HasPrefix(line, "RECORD LOCKS") && !strings.HasPrefix(line, "TABLE LOCK") {
+				// record dumps following a lock description are skipped
+				continue
+			}
+			if part == partHolds {
+				trx.HoldsLocks = append(trx.HoldsLocks, line)
+			} else {
+				trx.WaitsForLocks = append(trx.WaitsForLocks, line)
+			}
+		}
+	}
+
+	if len(result.Transactions) == 0 {
+		return nil
+	}
+	return result
+}
+
+// isSectionBorder reports whether the line is one of the dashed lines surrounding InnoDB status section titles.
+func isSectionBorder(line string) bool {
+	return len(line) > 0 && strings.Trim(line, "-") == ""
+}
+
+// transactionNumber returns the number between parentheses in lines like '*** (1) TRANSACTION:'.
+func transactionNumber(line string) string {
+	open := strings.Index(line, "(")
+	end := strings.Index(line, ")")
+	if open < 0 || end < open {
+		return ""
+	}
+	return line[open+1 : end]
+}
+
+// collectDiagnostics captures the latest deadlock and new error log entries and passes them to the consumer.
+func (m *mySQLReceiver) collectDiagnostics(ctx context.Context) {
+	if m.config.Diagnostics.InnoDBStatus {
+		m.collectDeadlock(ctx)
+	}
+	if m.config.Diagnostics.ErrorLog {
+		m.collectErrorLog(ctx)
+	}
+}
+
+func (m *mySQLReceiver) collectDeadlock(ctx context.Context) {
+	status, err := m.sqlclient.getInnoDBStatus()
+	if err != nil {
+		m.logger.Error("Failed to fetch InnoDB status", zap.Error(err))
+		return
+	}
+	latest := parseLatestDeadlock(status)
+	// the status keeps reporting the same deadlock until a new one occurs
+	if latest == nil || latest.Timestamp == m.lastDeadlockTimestamp {
+		return
+	}
+	record, err := json.Marshal(latest)
+	if err != nil {
+		m.logger.Error("Failed to convert deadlock into json format", zap.Error(err))
+		return
+	}
+	m.lastDeadlockTimestamp = latest.Timestamp
+	m.consumeDiagnostic(ctx, string(record), diagnosticTypeDeadlock)
+}
+
+func (m *mySQLReceiver) collectErrorLog(ctx context.Context) {
+	errorLogQuery := DBQueries{
+		QueryId:         errorLogQueryId,
+		Query:           errorLogQuery,
+		IndexColumnName: "LOGGED",
+		IndexColumnType: "TIMESTAMP",
+	}
+	records, err := getRecords(ctx, m.sqlclient, &errorLogQuery, m.logger)
+	if err != nil {
+		m.logger.Error("Failed to fetch error log", zap.Error(err))
+		return
+	}
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sortRecordKeys(keys)
+	for _, key := range keys {
+		m.consumeDiagnostic(ctx, records[key], diagnosticTypeErrorLog)
+	}
+}
+
+func (m *mySQLReceiver) consumeDiagnostic(ctx context.Context, record string, diagnosticType string) {
+	// the receiver of a metrics pipeline has no logs consumer
+	if m.consumer == nil {
+		return
+	}
+	logs := m.convertToLog(record)
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().UpsertString(diagnosticTypeAttribute, diagnosticType)
+	if err := m.consumeLogs(ctx, logs); err != nil {
+		m.logger.Error("Failed to consume diagnostics", zap.String("type", diagnosticType), zap.Error(err))
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const innoDBStatusWithDeadlock = `
+=====================================
+2022-08-01 10:25:01 0x7f2c5c0f8700 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 18 seconds
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2022-08-01 10:22:33 0x7f2c5c1b9700
+*** (1) TRANSACTION:
+TRANSACTION 12345, ACTIVE 5 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 2 lock struct(s), heap size 1136, 1 row lock(s)
+MySQL thread id 8, OS thread handle 139828, query id 100 localhost root updating
+UPDATE accounts SET balance = balance - 10
+WHERE id = 2
+*** (1) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12345 lock_mode X locks rec but not gap
+Record lock, heap no 2 PHYSICAL RECORD: n_fields 4; compact format; info bits 0
+ 0: len 4; hex 80000001; asc     ;;
+*** (1) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12345 lock_mode X locks rec but not gap waiting
+Record lock, heap no 3 PHYSICAL RECORD: n_fields 4; compact format; info bits 0
+*** (2) TRANSACTION:
+TRANSACTION 12346, ACTIVE 3 sec starting index read
+MySQL thread id 9, OS thread handle 139829, query id 101 localhost root updating
+UPDATE accounts SET balance = balance + 10 WHERE id = 1
+*** (2) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12346 lock_mode X locks rec but not gap
+*** (2) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12346 lock_mode X locks rec but not gap waiting
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 12350
+`
+
+func TestParseLatestDeadlock(t *testing.T) {
+	lock := "RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table `bank`.`accounts` trx id %s lock_mode X locks rec but not gap"
+
+	result := parseLatestDeadlock(innoDBStatusWithDeadlock)
+	require.Equal(t, &deadlock{
+		Timestamp: "2022-08-01 10:22:33",
+		Transactions: []deadlockTransaction{
+			{
+				Number:        "1",
+				ID:            "12345",
+				ThreadID:      "8",
+				Query:         "UPDATE accounts SET balance = balance - 10\nWHERE id = 2",
+				HoldsLocks:    []string{fmt.Sprintf(lock, "12345")},
+				WaitsForLocks: []string{fmt.Sprintf(lock, "12345") + " waiting"},
+			},
+			{
+				Number:        "2",
+				ID:            "12346",
+				ThreadID:      "9",
+				Query:         "UPDATE accounts SET balance = balance + 10 WHERE id = 1",
+				HoldsLocks:    []string{fmt.Sprintf(lock, "12346")},
+				WaitsForLocks: []string{fmt.Sprintf(lock, "12346") + " waiting"},
+			},
+		},
+		RolledBackTransaction: "2",
+	}, result)
+}
+
+func TestParseLatestDeadlockNoDeadlock(t *testing.T) {
+	status := `
+------------
+TRANSACTIONS
+------------
+Trx id counter 12350
+`
+	require.Nil(t, parseLatestDeadlock(status))
+	require.Nil(t, parseLatestDeadlock(""))
+}
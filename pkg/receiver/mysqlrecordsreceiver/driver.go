@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+//driver implements the parts of the client which depend on the database, i.e. building the connection string,
+//the identifier quoting and parameter placeholders of the predicate of the index column and converting the scanned column values
+//The drivers register themselves with registerDriver, so new databases can be added without changing the client and the scraper
+type driver interface {
+	//connectionString returns the connection string passed to sql.Open, it fails when the connection cannot be established as configured, e.g. with its TLS settings
+	connectionString(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) (string, error)
+	//quoteIdentifier quotes the name of the index column, the parts of a qualified name, e.g. table.column, are quoted separately
+	quoteIdentifier(name string) string
+	//placeholder returns the placeholder of the n-th parameter bound to a query, starting at 1
+	placeholder(n int) string
+	//statementTerminator is appended to the queries with an index column
+	statementTerminator() string
+	//limitClause limits the rows of a query ordered by its index column, it's appended after the order by clause
+	limitClause(rows int) string
+	//timestampArg converts the state value of a 'TIMESTAMP' index column, in the time zone of the index column, into the parameter bound to the query
+	timestampArg(t time.Time) interface{}
+	//scanValue converts a scanned column value into its string representation in the records
+	scanValue(value sql.RawBytes) string
+	//validate returns the errors of the config options which are not supported by the driver
+	validate(cfg *Config) error
+	//dbSystem is the database management system set as the db.system resource attribute of the records
+	dbSystem() string
+}
+
+//innoDBDriver is implemented by the drivers of databases with the InnoDB storage engine, which support the diagnostics
+type innoDBDriver interface {
+	innoDBStatus(db *sql.DB) (string, error)
+}
+
+//binlogDriver is implemented by the drivers of databases with a binlog the row changes are captured from with binlog_cdc
+type binlogDriver interface {
+	//binlogPosition returns the current binlog file and position of the server
+	binlogPosition(db *sql.DB) (string, uint32, error)
+}
+
+//sessionTimeZoneDriver is implemented by the drivers of databases which convert the values of time zone aware columns into the time zone of the session
+//The time zone of the session running a query with index_column_timezone is set to the time zone of the index column
+type sessionTimeZoneDriver interface {
+	//setSessionTimeZone sets the time zone of the session of the connection and returns the function restoring the previous time zone
+	setSessionTimeZone(ctx context.Context, conn *sql.Conn, loc *time.Location) (func() error, error)
+}
+
+//iamAuthDriver is implemented by the drivers of databases supporting 'IAMRDSAuth'
+//The connection string is built once, and only its authentication token is replaced for every new connection
+type iamAuthDriver interface {
+	//withIAMAuthToken returns the connection string with a new IAM authentication token for its endpoint as the password
+	withIAMAuthToken(ctx context.Context, connStr string, conf *Config, loadAWSConfig awsConfigLoader) (string, error)
+}
+
+var drivers = make(map[string]driver)
+
+//registerDriver registers the driver under the name used in db_driver, which is the name of the database/sql driver as well
+//It's called from the init functions of the drivers and panics if the name is already registered
+func registerDriver(name string, d driver) {
+	if _, dup := drivers[name]; dup {
+		panic("mysqlrecordsreceiver: driver registered twice: " + name)
+	}
+	drivers[name] = d
+}
+
+//getDriver returns the driver registered under the name
+func getDriver(name string) (driver, bool) {
+	d, ok := drivers[name]
+	return d, ok
+}
+
+//driverNames returns the sorted names of the registered drivers
+func driverNames() []string {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//baseDriver implements the defaults of the driver methods, the drivers embed it and override the methods where their database differs
+type baseDriver struct{}
+
+//Identifiers are quoted with double quotes in standard SQL
+func (baseDriver) quoteIdentifier(name string) string {
+	return quoteIdentifier(name, `"`, `"`)
+}
+
+func (baseDriver) placeholder(n int) string {
+	return "?"
+}
+
+func (baseDriver) statementTerminator() string {
+	return ";"
+}
+
+func (baseDriver) limitClause(rows int) string {
+	return " limit " + strconv.Itoa(rows)
+}
+
+//The time is bound with its time zone offset, the databases compare it with columns without a time zone by its wall clock in the time zone
+func (baseDriver) timestampArg(t time.Time) interface{} {
+	return t
+}
+
+//NULL values are represented as "NULL"
+func (baseDriver) scanValue(value sql.RawBytes) string {
+	if value == nil {
+		return "NULL"
+	}
+	return string(value)
+}
+
+//This function returns an error if the configured authentication mode is not one of the modes supported by the driver
+//Unknown authentication modes are reported by Config.Validate
+func validateAuthenticationMode(cfg *Config, modes ...string) error {
+	if !isKnownAuthenticationMode(cfg.AuthenticationMode) {
+		return nil
+	}
+	for _, mode := range modes {
+		if cfg.AuthenticationMode == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("authentication_mode : '%s' cannot be used with db_driver : '%s'", cfg.AuthenticationMode, cfg.DBDriver)
+}
+
+//This function returns the errors of the options relying on features of the MySQL server and driver, for the drivers of other databases
+func validateMySQLOnlyOptions(cfg *Config) error {
+	var err error
+	if len(cfg.ProxyURL) != 0 || cfg.ProxyFromEnvironment {
+		err = multierr.Append(err, fmt.Errorf("proxy_url and proxy_from_environment cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if cfg.TLS != nil {
+		err = multierr.Append(err, fmt.Errorf("tls cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if cfg.SSHTunnel != nil {
+		err = multierr.Append(err, fmt.Errorf("ssh_tunnel cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if len(cfg.DBHosts) != 0 {
+		err = multierr.Append(err, fmt.Errorf("dbhosts cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if cfg.Transport == "unix" || len(cfg.SocketPath) != 0 {
+		err = multierr.Append(err, fmt.Errorf("the 'unix' transport and socket_path cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	if cfg.Diagnostics.InnoDBStatus || cfg.Diagnostics.ErrorLog {
+		err = multierr.Append(err, fmt.Errorf("diagnostics cannot be used with db_driver : '%s'", cfg.DBDriver))
+	}
+	return err
+}
+
+//This function returns an error if read_only_session is enabled, for the drivers of databases without read only sessions
+func validateNoReadOnlySession(cfg *Config) error {
+	if cfg.ReadOnlySession {
+		return fmt.Errorf("read_only_session cannot be used with db_driver : '%s'", cfg.DBDriver)
+	}
+	return nil
+}
+
+//This function returns an error if a query uses multi_statement, for the drivers that cannot run several statements in one query
+func validateNoMultiStatementQueries(cfg *Config) error {
+	for _, dbquery := range cfg.DBQueries {
+		if dbquery.MultiStatement {
+			return fmt.Errorf("multi_statement cannot be used with db_driver : '%s'", cfg.DBDriver)
+		}
+	}
+	return nil
+}
+
+//This function quotes each part of the qualified name with the quote characters, the closing quote character is escaped by doubling it
+func quoteIdentifier(name string, open string, close string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = open + strings.ReplaceAll(part, close, close+close) + close
+	}
+	return strings.Join(parts, ".")
+}
+
+//This function quotes the names of the registered drivers for the error of an unknown db_driver
+func quotedDriverNames() string {
+	names := driverNames()
+	for i, name := range names {
+		names[i] = "'" + name + "'"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredDrivers(t *testing.T) {
+	require.Equal(t, []string{dbDriverMySQL, dbDriverOracle, dbDriverPostgres, dbDriverSQLServer}, driverNames())
+	require.Equal(t, "'mysql', 'oracle', 'postgres', 'sqlserver'", quotedDriverNames())
+
+	d, ok := getDriver(dbDriverPostgres)
+	require.True(t, ok)
+	require.Equal(t, postgreSQLDriver{}, d)
+
+	_, ok = getDriver("db2")
+	require.False(t, ok)
+
+	require.Panics(t, func() { registerDriver(dbDriverMySQL, mySQLDriver{}) })
+}
+
+func TestDriverQuoteIdentifier(t *testing.T) {
+	require.Equal(t, "`PersonID`", mySQLDriver{}.quoteIdentifier("PersonID"))
+	require.Equal(t, "`persons`.`PersonID`", mySQLDriver{}.quoteIdentifier("persons.PersonID"))
+	require.Equal(t, "`Person``ID`", mySQLDriver{}.quoteIdentifier("Person`ID"))
+	require.Equal(t, `"login_time"`, postgreSQLDriver{}.quoteIdentifier("login_time"))
+	require.Equal(t, `"login""time"`, postgreSQLDriver{}.quoteIdentifier(`login"time`))
+	require.Equal(t, "[dbo].[LoginTime]", sqlServerDriver{}.quoteIdentifier("dbo.LoginTime"))
+	require.Equal(t, "[Login]]Time]", sqlServerDriver{}.quoteIdentifier("Login]Time"))
+	require.Equal(t, `"LOGIN_TIME"`, oracleDriver{}.quoteIdentifier("LOGIN_TIME"))
+}
+
+func TestDriverPlaceholder(t *testing.T) {
+	require.Equal(t, "?", mySQLDriver{}.placeholder(1))
+	require.Equal(t, "$2", postgreSQLDriver{}.placeholder(2))
+	require.Equal(t, "@p1", sqlServerDriver{}.placeholder(1))
+	require.Equal(t, ":1", oracleDriver{}.placeholder(1))
+}
+
+func TestDriverLimitClause(t *testing.T) {
+	require.Equal(t, " limit 500", mySQLDriver{}.limitClause(500))
+	require.Equal(t, " limit 500", postgreSQLDriver{}.limitClause(500))
+	require.Equal(t, " offset 0 rows fetch next 500 rows only", sqlServerDriver{}.limitClause(500))
+	require.Equal(t, " fetch first 500 rows only", oracleDriver{}.limitClause(500))
+}
+
+func TestDriverTimestampArg(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	require.NoError(t, err)
+	ts := time.Date(2022, 8, 1, 10, 5, 0, 500000000, warsaw)
+	require.Equal(t, "2022-08-01 10:05:00.5", mySQLDriver{}.timestampArg(ts))
+	require.Equal(t, ts, postgreSQLDriver{}.timestampArg(ts))
+	require.Equal(t, ts, oracleDriver{}.timestampArg(ts))
+}
+
+func TestBaseDriver(t *testing.T) {
+	d := baseDriver{}
+	require.Equal(t, ";", d.statementTerminator())
+	require.Equal(t, "NULL", d.scanValue(nil))
+	require.Equal(t, "", d.scanValue(sql.RawBytes("")))
+	require.Equal(t, "", oracleDriver{}.statementTerminator())
+}
+
+func TestInnoDBDriver(t *testing.T) {
+	for _, name := range driverNames() {
+		d, _ := getDriver(name)
+		_, ok := d.(innoDBDriver)
+		require.Equal(t, name == dbDriverMySQL, ok, name)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+//This function returns the dry run of the query, which is the query with the predicate of its index columns selected from as a derived table
+//with a predicate matching no rows, so the database validates the query and the index columns without fetching any record
+//The statement terminator of the query is removed, as the query is nested in the dry run
+func dryRunQuery(d driver, dbquery *DBQueries, state string) (string, []interface{}, error) {
+	query := strings.TrimRight(strings.TrimSpace(dbquery.Query), "; ")
+	var args []interface{}
+	if isIncrementalQuery(dbquery) {
+		var err error
+		if args, err = stateArgs(d, dbquery, state); err != nil {
+			return "", nil, err
+		}
+		query = appendIndexCondition(query, indexCondition(d, indexColumns(dbquery)))
+	}
+	return "select * from (" + query + ") dry_run where 1 = 0", args, nil
+}
+
+//This function returns whether the query can be nested in its dry run, i.e. it's a single SELECT or WITH statement
+//SQL Server doesn't allow common table expressions and order by clauses without top or offset in derived tables, so these queries are not dry run
+func isDryRunQuery(dbDriver string, query string) bool {
+	statements, err := tokenizeQuery(query)
+	if err != nil || len(statements) == 0 || len(statements[0]) == 0 {
+		return false
+	}
+	tokens := statements[0]
+	if dbDriver == dbDriverSQLServer {
+		for _, token := range tokens {
+			if token == "ORDER" {
+				return false
+			}
+		}
+		return tokens[0] == "SELECT"
+	}
+	return tokens[0] == "SELECT" || tokens[0] == "WITH"
+}
+
+//This function runs the dry runs of the queries and returns the errors of the invalid queries
+//The dry runs are bounded by the query_timeout of the queries, the state of the queries is not changed
+func (m *mySQLReceiver) validateQueries(ctx context.Context, dbqueries []DBQueries) error {
+	var errs error
+	for i := range dbqueries {
+		dbquery := &dbqueries[i]
+		if len(strings.TrimSpace(dbquery.Query)) == 0 {
+			continue
+		}
+		if dbquery.MultiStatement || !isDryRunQuery(m.config.DBDriver, dbquery.Query) {
+			m.logger.Info("Query cannot be validated with a dry run, skipping its validation", zap.String("queryId", dbquery.QueryId))
+			continue
+		}
+		if err := m.dryRun(ctx, dbquery); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("query %s is invalid: %w", dbquery.QueryId, err))
+		}
+	}
+	return errs
+}
+
+func (m *mySQLReceiver) dryRun(ctx context.Context, dbquery *DBQueries) error {
+	var state string
+	if isIncrementalQuery(dbquery) {
+		state = GetState(dbquery, m.logger)
+	}
+	query, args, err := dryRunQuery(m.sqlclient.getDriver(), dbquery, state)
+	if err != nil {
+		return err
+	}
+	if len(dbquery.QueryTimeout) != 0 {
+		timeout, _ := time.ParseDuration(dbquery.QueryTimeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	_, _, err = m.sqlclient.ExecuteQueryandFetchRecords(ctx, query, dbquery.QueryId, args...)
+	return queryError(ctx, dbquery, err)
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDryRunQuery(t *testing.T) {
+	testcases := []struct {
+		driverName    string
+		dbquery       DBQueries
+		state         string
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			driverName:    dbDriverMySQL,
+			dbquery:       DBQueries{Query: "select * from orders;"},
+			expectedQuery: "select * from (select * from orders) dry_run where 1 = 0",
+		},
+		{
+			driverName:    dbDriverMySQL,
+			dbquery:       DBQueries{Query: "select * from orders where status = 'paid'", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"},
+			state:         "7",
+			expectedQuery: "select * from (select * from orders where (status = 'paid') and `OrderID` > ?) dry_run where 1 = 0",
+			expectedArgs:  []interface{}{int64(7)},
+		},
+		{
+			driverName:    dbDriverPostgres,
+			dbquery:       DBQueries{Query: "select * from orders", IndexColumns: []string{"created_at", "id"}, IndexColumnTypes: []string{"NUMBER", "NUMBER"}},
+			state:         `["3","7"]`,
+			expectedQuery: `select * from (select * from orders where ("created_at" > $1 or ("created_at" = $2 and "id" > $3))) dry_run where 1 = 0`,
+			expectedArgs:  []interface{}{int64(3), int64(3), int64(7)},
+		},
+		{
+			driverName:    dbDriverOracle,
+			dbquery:       DBQueries{Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"},
+			state:         "7",
+			expectedQuery: `select * from (select * from orders where "OrderID" > :1) dry_run where 1 = 0`,
+			expectedArgs:  []interface{}{int64(7)},
+		},
+	}
+	for _, tc := range testcases {
+		d, _ := getDriver(tc.driverName)
+		query, args, err := dryRunQuery(d, &tc.dbquery, tc.state)
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedQuery, query)
+		require.Equal(t, tc.expectedArgs, args)
+	}
+
+	d, _ := getDriver(dbDriverMySQL)
+	_, _, err := dryRunQuery(d, &DBQueries{Query: "select * from orders", IndexColumns: []string{"created_at", "id"}, IndexColumnTypes: []string{"NUMBER", "NUMBER"}}, "7")
+	require.Error(t, err)
+}
+
+func TestValidateQueries(t *testing.T) {
+	dbqueries := []DBQueries{
+		{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"},
+		{QueryId: "Q2", Query: "select * from order_items", IndexColumnName: "ItemID", IndexColumnType: "NUMBER"},
+		{QueryId: "Q3", Query: "select * from customers"},
+		{QueryId: "Q4"},
+		{QueryId: "Q5", Query: "show global status"},
+	}
+	sqlclient := &mockClient{
+		records:   []string{`{"OrderID":"1"}`},
+		queryErrs: []error{nil, &mysql.MySQLError{Number: 1054, Message: "Unknown column 'ItemID' in 'where clause'"}},
+	}
+	receiver := &mySQLReceiver{config: &Config{}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	err := receiver.validateQueries(context.Background(), dbqueries)
+	require.EqualError(t, err, "query Q2 is invalid: Error 1054: Unknown column 'ItemID' in 'where clause'")
+	require.Equal(t, []string{
+		"select * from (select * from orders where `OrderID` > ?) dry_run where 1 = 0",
+		"select * from (select * from order_items where `ItemID` > ?) dry_run where 1 = 0",
+		"select * from (select * from customers) dry_run where 1 = 0",
+	}, sqlclient.queries)
+	//the dry runs don't save the state of the queries
+	_, statErr := os.Stat(getStateStoreFilename(&dbqueries[0]))
+	require.True(t, os.IsNotExist(statErr))
+
+	require.NoError(t, receiver.validateQueries(context.Background(), dbqueries[:1]))
+}
+
+func TestIsDryRunQuery(t *testing.T) {
+	require.True(t, isDryRunQuery(dbDriverMySQL, "select * from orders"))
+	require.True(t, isDryRunQuery(dbDriverMySQL, "/* orders */ with paid as (select * from orders) select * from paid;"))
+	require.True(t, isDryRunQuery(dbDriverMySQL, "select * from orders order by OrderID"))
+	require.False(t, isDryRunQuery(dbDriverMySQL, "show global status"))
+	require.False(t, isDryRunQuery(dbDriverMySQL, "explain select * from orders"))
+	require.False(t, isDryRunQuery(dbDriverMySQL, ""))
+	require.True(t, isDryRunQuery(dbDriverSQLServer, "select * from orders"))
+	require.False(t, isDryRunQuery(dbDriverSQLServer, "with paid as (select * from orders) select * from paid"))
+	require.False(t, isDryRunQuery(dbDriverSQLServer, "select * from orders order by OrderID"))
+}
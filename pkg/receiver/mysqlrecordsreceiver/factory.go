@@ -24,13 +24,18 @@ import (
 
 const (
 	typeStr = "mysqlrecords"
+	//defaultMaxArrayRecordSize is the default maximum size in bytes of a log record in 'per_scrape_array' emit mode
+	defaultMaxArrayRecordSize = 1024 * 1024
+	//defaultFetchBatchSize is the default number of rows converted and passed to the consumer at once in 'per_row' emit mode
+	defaultFetchBatchSize = 1000
 )
 
 func NewFactory() component.ReceiverFactory {
 	return component.NewReceiverFactory(
 		typeStr,
 		createDefaultConfig,
-		component.WithLogsReceiver(CreateLogsReceiver))
+		component.WithLogsReceiver(CreateLogsReceiver),
+		component.WithMetricsReceiver(CreateMetricsReceiver))
 }
 
 func createDefaultConfig() config.Receiver {
@@ -38,9 +43,13 @@ func createDefaultConfig() config.Receiver {
 
 	return &Config{
 		ReceiverSettings:     rs,
-		CollectionInterval:   "10s",
+		DBDriver:             dbDriverMySQL,
 		AllowNativePasswords: true,
 		Username:             "Username",
+		EmitMode:             emitModePerRow,
+		RecordFormat:         recordFormatJSON,
+		MaxArrayRecordSize:   defaultMaxArrayRecordSize,
+		FetchBatchSize:       defaultFetchBatchSize,
 		NetAddr: confignet.NetAddr{
 			Endpoint:  "localhost:3306",
 			Transport: "tcp",
@@ -56,5 +65,17 @@ func CreateLogsReceiver(
 ) (component.LogsReceiver, error) {
 
 	cfg := rConf.(*Config)
-	return newMySQLReceiver(params.Logger, cfg, consumer)
+	return newMySQLReceiver(params, cfg, consumer)
+}
+
+//The metrics receiver collects the queries with metric_name, the logs receiver collects the other queries
+func CreateMetricsReceiver(
+	_ context.Context,
+	params component.ReceiverCreateSettings,
+	rConf config.Receiver,
+	consumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+
+	cfg := rConf.(*Config)
+	return newMySQLMetricsReceiver(params, cfg, consumer)
 }
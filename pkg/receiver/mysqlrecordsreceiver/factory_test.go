@@ -57,3 +57,16 @@ func TestCreateLogsReceiver(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, logsReceiver)
 }
+
+func TestCreateMetricsReceiver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	metricsReceiver, err := factory.CreateMetricsReceiver(
+		context.Background(),
+		componenttest.NewNopReceiverCreateSettings(),
+		cfg,
+		consumertest.NewNop(),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, metricsReceiver)
+}
@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	//failoverNetworkPrefix prefixes the names of the networks the failover dialers are registered under with the driver
+	failoverNetworkPrefix = "failover_"
+	//defaultMySQLPort is the port of the endpoints of dbhosts without a port when dbport is not set
+	defaultMySQLPort = "3306"
+	//endpointDownInterval is the time an unreachable endpoint is skipped for, the endpoints before it are preferred again afterwards
+	endpointDownInterval = 30 * time.Second
+)
+
+//failoverDialers are the failover dialers of the receivers, keyed by the network they are registered under
+//The logs and the metrics receivers with the same id share the dialer, so it's removed when both of them are closed
+var (
+	failoverDialers     = make(map[string]*failoverDialer)
+	failoverDialersLock sync.Mutex
+)
+
+//failoverDialer connects to the first reachable endpoint of dbhosts, in their order, e.g. the primary followed by the read replicas
+//An endpoint which cannot be connected to is marked as down and skipped by the next connections, until it's checked again after endpointDownInterval
+type failoverDialer struct {
+	endpoints []string
+	dial      func(ctx context.Context, endpoint string) (net.Conn, error)
+	logger    *zap.Logger
+	now       func() time.Time
+
+	lock      sync.Mutex
+	downUntil map[string]time.Time
+	current   string
+
+	//refs is the number of the clients the dialer is registered for, it's guarded by failoverDialersLock
+	refs int
+}
+
+//This function validates dbhosts, each endpoint is a host with an optional port
+func validateDBHosts(cfg *Config) error {
+	if len(cfg.DBHosts) == 0 {
+		return nil
+	}
+	var err error
+	if len(cfg.DBHost) != 0 {
+		err = multierr.Append(err, errors.New("dbhost and dbhosts cannot be used together"))
+	}
+	//The IAM authentication tokens are signed for a single endpoint, and the other modes don't connect over tcp
+	if cfg.AuthenticationMode != "BasicAuth" && cfg.AuthenticationMode != "AzureADAuth" {
+		err = multierr.Append(err, errors.New("dbhosts can only be used with authentication_mode : 'BasicAuth' or 'AzureADAuth'"))
+	}
+	if len(cfg.Transport) != 0 && cfg.Transport != "tcp" {
+		err = multierr.Append(err, errors.New("dbhosts can only be used with the 'tcp' transport"))
+	}
+	for _, host := range cfg.DBHosts {
+		if len(strings.TrimSpace(host)) == 0 {
+			err = multierr.Append(err, errors.New("dbhosts cannot contain an empty host"))
+		} else if strings.Contains(host, ":") {
+			if _, _, splitErr := net.SplitHostPort(host); splitErr != nil {
+				err = multierr.Append(err, fmt.Errorf("dbhosts contains an invalid endpoint '%s': %w", host, splitErr))
+			}
+		}
+	}
+	return err
+}
+
+//This function returns the endpoints of dbhosts, dbport or the default MySQL port is used for the hosts without a port
+func failoverEndpoints(conf *Config) []string {
+	port := conf.DBPort
+	if len(port) == 0 {
+		port = defaultMySQLPort
+	}
+	endpoints := make([]string, 0, len(conf.DBHosts))
+	for _, host := range conf.DBHosts {
+		host = strings.TrimSpace(host)
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, port)
+		}
+		endpoints = append(endpoints, host)
+	}
+	return endpoints
+}
+
+//This function registers the dial function of the failover dialer of the receiver with the driver, the endpoints are connected to
+//through the proxy or the SSH tunnel of the receiver
+//It returns the name of the network the dial function is registered under, which is used as the network of the connection string
+func registerFailoverDialer(conf *Config, logger *zap.Logger) (string, error) {
+	//The network name is a part of the connection string, so it cannot contain the '/' of the receiver id
+	network := failoverNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	failoverDialersLock.Lock()
+	defer failoverDialersLock.Unlock()
+	if registered, ok := failoverDialers[network]; ok {
+		registered.refs++
+		return network, nil
+	}
+	endpoints := failoverEndpoints(conf)
+	dials := make(map[string]mysql.DialContextFunc, len(endpoints))
+	for _, endpoint := range endpoints {
+		dial, err := endpointDialContext(conf, endpoint)
+		if err != nil {
+			return "", err
+		}
+		dials[endpoint] = dial
+	}
+	dialer := &failoverDialer{
+		endpoints: endpoints,
+		dial: func(ctx context.Context, endpoint string) (net.Conn, error) {
+			return dials[endpoint](ctx, endpoint)
+		},
+		logger:    logger,
+		now:       time.Now,
+		downUntil: make(map[string]time.Time),
+		refs:      1,
+	}
+	failoverDialers[network] = dialer
+	mysql.RegisterDialContext(network, func(ctx context.Context, _ string) (net.Conn, error) {
+		return dialer.dialContext(ctx)
+	})
+	return network, nil
+}
+
+//This function removes the failover dialer of the receiver once no other client uses it, so the dialer of the next receiver with the same id starts with the first endpoint
+func closeFailoverDialer(conf *Config) {
+	network := failoverNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	failoverDialersLock.Lock()
+	defer failoverDialersLock.Unlock()
+	registered, ok := failoverDialers[network]
+	if !ok {
+		return
+	}
+	if registered.refs--; registered.refs > 0 {
+		return
+	}
+	delete(failoverDialers, network)
+}
+
+//This function returns the dial function connecting to the endpoint, through the SSH tunnel or the proxy of the receiver if they are set
+func endpointDialContext(conf *Config, endpoint string) (mysql.DialContextFunc, error) {
+	if conf.SSHTunnel != nil {
+		return sshTunnelDialContext(conf)
+	}
+	proxyURL := conf.ProxyURL
+	if len(proxyURL) == 0 && conf.ProxyFromEnvironment {
+		var err error
+		if proxyURL, err = environmentProxyURL(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	if len(proxyURL) != 0 {
+		return newProxyDialContext(proxyURL)
+	}
+	var dialer net.Dialer
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}, nil
+}
+
+//This function connects to the first endpoint which isn't down, the endpoints which are down are only tried when all of them are down
+func (f *failoverDialer) dialContext(ctx context.Context) (net.Conn, error) {
+	var errs error
+	for _, endpoint := range f.candidates() {
+		conn, err := f.dial(ctx, endpoint)
+		if err == nil {
+			f.markUp(endpoint)
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		f.logger.Warn("Failed to connect to endpoint of dbhosts, trying the next one", zap.String("endpoint", endpoint), zap.Error(err))
+		f.markDown(endpoint)
+		errs = multierr.Append(errs, err)
+	}
+	return nil, fmt.Errorf("failed to connect to any endpoint of dbhosts: %w", errs)
+}
+
+//This function returns the endpoints which are up followed by the endpoints which are down, both in the order of dbhosts
+func (f *failoverDialer) candidates() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	now := f.now()
+	var up, down []string
+	for _, endpoint := range f.endpoints {
+		if until, ok := f.downUntil[endpoint]; ok && now.Before(until) {
+			down = append(down, endpoint)
+		} else {
+			up = append(up, endpoint)
+		}
+	}
+	return append(up, down...)
+}
+
+func (f *failoverDialer) markDown(endpoint string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.downUntil[endpoint] = f.now().Add(endpointDownInterval)
+}
+
+func (f *failoverDialer) markUp(endpoint string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.downUntil, endpoint)
+	if f.current != endpoint {
+		f.logger.Info("Connected to endpoint of dbhosts", zap.String("endpoint", endpoint), zap.String("previousEndpoint", f.current))
+		f.current = endpoint
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+//This function returns an address nothing listens on
+func closedAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func newTestFailoverDialer(endpoints []string, now *time.Time) (*failoverDialer, *[]string) {
+	var dialed []string
+	var dialer net.Dialer
+	return &failoverDialer{
+		endpoints: endpoints,
+		dial: func(ctx context.Context, endpoint string) (net.Conn, error) {
+			dialed = append(dialed, endpoint)
+			return dialer.DialContext(ctx, "tcp", endpoint)
+		},
+		logger: zap.NewNop(),
+		now: func() time.Time {
+			return *now
+		},
+		downUntil: make(map[string]time.Time),
+	}, &dialed
+}
+
+func TestFailoverDialer(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	primary := closedAddr(t)
+	replica := startTestGreeter(t)
+	dialer, dialed := newTestFailoverDialer([]string{primary, replica}, &now)
+
+	conn, err := dialer.dialContext(context.Background())
+	require.NoError(t, err)
+	greeting, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(greeting))
+	require.Equal(t, []string{primary, replica}, *dialed)
+
+	// the primary is skipped while it's down
+	*dialed = nil
+	conn, err = dialer.dialContext(context.Background())
+	require.NoError(t, err)
+	conn.Close()
+	require.Equal(t, []string{replica}, *dialed)
+
+	// the primary is tried again once endpointDownInterval passed
+	now = now.Add(endpointDownInterval)
+	*dialed = nil
+	conn, err = dialer.dialContext(context.Background())
+	require.NoError(t, err)
+	conn.Close()
+	require.Equal(t, []string{primary, replica}, *dialed)
+}
+
+func TestFailoverDialerAllEndpointsDown(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	primary, replica := closedAddr(t), closedAddr(t)
+	dialer, dialed := newTestFailoverDialer([]string{primary, replica}, &now)
+
+	_, err := dialer.dialContext(context.Background())
+	require.Error(t, err)
+
+	// the endpoints which are down are still tried when all of them are down
+	*dialed = nil
+	_, err = dialer.dialContext(context.Background())
+	require.Error(t, err)
+	require.Equal(t, []string{primary, replica}, *dialed)
+}
+
+func TestFailoverEndpoints(t *testing.T) {
+	cfg := &Config{DBHosts: []string{"primary.internal", "replica.internal:3307", " replica2.internal "}}
+	require.Equal(t, []string{"primary.internal:3306", "replica.internal:3307", "replica2.internal:3306"}, failoverEndpoints(cfg))
+
+	cfg.DBPort = "3308"
+	require.Equal(t, []string{"primary.internal:3308", "replica.internal:3307", "replica2.internal:3308"}, failoverEndpoints(cfg))
+}
+
+func TestNewMySQLClientWithDBHosts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings = config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "replicas"))
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHosts = []string{"primary.internal", "replica.internal"}
+	cfg.Database = "app"
+	defer closeFailoverDialer(cfg)
+
+	connStr, err := mySQLDriver{}.connectionString(cfg, loadDefaultAWSConfig, zap.NewNop())
+	require.NoError(t, err)
+	require.Contains(t, connStr, "mysqluser:userpass@failover_mysqlrecords_replicas(primary.internal:3306)/app")
+	require.Contains(t, failoverDialers, "failover_mysqlrecords_replicas")
+}
+
+func TestNewMySQLClientWithDBHostsWInvalidProxy(t *testing.T) {
+	t.Setenv("ALL_PROXY", "")
+	t.Setenv("all_proxy", "")
+	t.Setenv("HTTPS_PROXY", "http://")
+	t.Setenv("NO_PROXY", "primary.internal")
+	t.Setenv("no_proxy", "")
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings = config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "invalidproxy"))
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHosts = []string{"primary.internal", "replica.internal"}
+	cfg.Database = "app"
+	cfg.ProxyFromEnvironment = true
+	defer closeFailoverDialer(cfg)
+
+	// the connections are not established without the failover when the dialer of an endpoint cannot be created
+	_, err := mySQLDriver{}.connectionString(cfg, loadDefaultAWSConfig, zap.NewNop())
+	require.Error(t, err)
+	require.NotContains(t, failoverDialers, "failover_mysqlrecords_invalidproxy")
+}
+
+func TestValidateDBHosts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHosts = []string{"primary.internal", "replica.internal:3307"}
+	cfg.Database = "app"
+	require.NoError(t, cfg.Validate())
+
+	cfg.DBHost = "primary.internal"
+	require.Error(t, cfg.Validate())
+
+	cfg.DBHost = ""
+	cfg.DBHosts = []string{"primary.internal", ""}
+	require.Error(t, cfg.Validate())
+
+	cfg.DBHosts = []string{"primary.internal:port:3306"}
+	require.Error(t, cfg.Validate())
+
+	cfg.DBHosts = []string{"primary.internal", "replica.internal"}
+	cfg.AuthenticationMode = "IAMRDSAuth"
+	cfg.Password = ""
+	cfg.Region = "us-east-1"
+	require.Error(t, cfg.Validate())
+
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Password = "userpass"
+	cfg.Region = ""
+	cfg.DBDriver = dbDriverPostgres
+	require.Error(t, cfg.Validate())
+}
+
+func TestCloseClientWSharedFailoverDialer(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings = config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "shared"))
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHosts = []string{"primary.internal", "replica.internal"}
+	cfg.Database = "app"
+
+	// the logs and the metrics receivers with the same id share the dialer
+	logsClient, err := newClient(cfg, loadDefaultAWSConfig, zap.NewNop())
+	require.NoError(t, err)
+	metricsClient, err := newClient(cfg, loadDefaultAWSConfig, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, logsClient.Close())
+	require.Contains(t, failoverDialers, "failover_mysqlrecords_shared")
+	require.NoError(t, metricsClient.Close())
+	require.NotContains(t, failoverDialers, "failover_mysqlrecords_shared")
+}
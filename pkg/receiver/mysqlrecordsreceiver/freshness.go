@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	diagnosticTypeFreshnessViolation = "freshness_violation"
+
+	//stalenessMetric is the time since the query last fetched new records, in seconds
+	stalenessMetric = "mysql.query.staleness"
+	//freshnessViolationMetric is 1 while the staleness of the query exceeds its expected freshness, 0 otherwise
+	freshnessViolationMetric = "mysql.query.freshness_violation"
+)
+
+// freshnessViolation is the body of the log record emitted when a query didn't fetch new records within its expected freshness.
+type freshnessViolation struct {
+	QueryId           string  `json:"queryid"`
+	ExpectedFreshness string  `json:"expected_freshness"`
+	LastNewRecordsAt  string  `json:"last_new_records_at"`
+	StalenessSeconds  float64 `json:"staleness_seconds"`
+}
+
+func validateExpectedFreshness(dbquery DBQueries) error {
+	expected, err := time.ParseDuration(dbquery.ExpectedFreshness)
+	if err != nil {
+		return err
+	}
+	if expected <= 0 {
+		return errors.New("it has to be positive")
+	}
+	// queries without an index column fetch all records on every collection, so there's no notion of new records
+	if !isIncrementalQuery(&dbquery) {
+		return errors.New("it can only be used with index_column_name or index_columns")
+	}
+	return nil
+}
+
+// lookUpFreshnessMetricsExporter returns the metrics exporter configured in freshness_metrics_exporter.
+func lookUpFreshnessMetricsExporter(host component.Host, id config.ComponentID) (consumer.Metrics, error) {
+	exporter, ok := host.GetExporters()[config.MetricsDataType][id]
+	if !ok {
+		return nil, fmt.Errorf("metrics exporter %q not found, it has to be used in a metrics pipeline", id)
+	}
+	metricsConsumer, ok := exporter.(consumer.Metrics)
+	if !ok {
+		return nil, fmt.Errorf("exporter %q is not a metrics exporter", id)
+	}
+	return metricsConsumer, nil
+}
+
+// checkFreshness compares the time since the query last fetched new records with its expected freshness.
+// A violation log record is emitted on every collection while the query stays stale, so stalls of the upstream
+// pipeline writing into the database are detected. Failed queries aren't checked, as they are faults of the collection.
+func (m *mySQLReceiver) checkFreshness(ctx context.Context, dbquery *DBQueries, newRecords int, now time.Time) {
+	expected, err := time.ParseDuration(dbquery.ExpectedFreshness)
+	if err != nil {
+		return
+	}
+
+	unlock := lockFreshness(dbquery)
+	defer unlock()
+	lastNewRecords, ok := GetLastNewRecordsTime(dbquery, m.logger)
+	// without a saved time, the staleness is measured from the first collection of the query
+	if newRecords > 0 || !ok {
+		lastNewRecords = now
+		if err := SaveLastNewRecordsTime(dbquery, now, m.logger); err != nil {
+			m.logger.Warn("Freshness state was not saved", zap.String("queryId", dbquery.QueryId))
+		}
+	}
+
+	staleness := now.Sub(lastNewRecords)
+	violated := staleness > expected
+	if violated {
+		m.logger.Warn("Query didn't fetch new records within the expected freshness",
+			zap.String("queryId", dbquery.QueryId), zap.Duration("staleness", staleness), zap.Duration("expectedFreshness", expected))
+		record, err := json.Marshal(freshnessViolation{
+			QueryId:           dbquery.QueryId,
+			ExpectedFreshness: dbquery.ExpectedFreshness,
+			LastNewRecordsAt:  lastNewRecords.UTC().Format(time.RFC3339Nano),
+			StalenessSeconds:  staleness.Seconds(),
+		})
+		if err != nil {
+			m.logger.Error("Failed to convert freshness violation into json format", zap.Error(err))
+		} else {
+			m.consumeDiagnostic(ctx, string(record), diagnosticTypeFreshnessViolation)
+		}
+	}
+
+	if m.metricsConsumer != nil {
+		if err := m.metricsConsumer.ConsumeMetrics(ctx, m.freshnessMetrics(dbquery.QueryId, staleness, violated, now)); err != nil {
+			m.logger.Error("Failed to consume freshness metrics", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		}
+	}
+}
+
+func (m *mySQLReceiver) freshnessMetrics(queryid string, staleness time.Duration, violated bool, now time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	m.setResourceAttributes(rm.Resource().Attributes())
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	timestamp := pcommon.NewTimestampFromTime(now)
+
+	metric := metrics.AppendEmpty()
+	metric.SetName(stalenessMetric)
+	metric.SetDescription("Time since the query last fetched new records.")
+	metric.SetUnit("s")
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	dp := metric.Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(timestamp)
+	dp.SetDoubleVal(staleness.Seconds())
+	dp.Attributes().UpsertString(queryIdAttribute, queryid)
+
+	metric = metrics.AppendEmpty()
+	metric.SetName(freshnessViolationMetric)
+	metric.SetDescription("Whether the query didn't fetch new records within its expected freshness.")
+	metric.SetUnit("1")
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	dp = metric.Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(timestamp)
+	if violated {
+		dp.SetIntVal(1)
+	} else {
+		dp.SetIntVal(0)
+	}
+	dp.Attributes().UpsertString(queryIdAttribute, queryid)
+	return md
+}
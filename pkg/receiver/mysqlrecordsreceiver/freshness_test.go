@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestSaveLastNewRecordsTime(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", ExpectedFreshness: "15m"}
+	defer os.Remove("Q1_freshness.csv")
+
+	_, ok := GetLastNewRecordsTime(&dbquery, logger)
+	require.False(t, ok)
+
+	lastNewRecords := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, SaveLastNewRecordsTime(&dbquery, lastNewRecords, logger))
+	saved, ok := GetLastNewRecordsTime(&dbquery, logger)
+	require.True(t, ok)
+	require.Equal(t, lastNewRecords, saved)
+}
+
+func TestCheckFreshness(t *testing.T) {
+	logs := &consumertest.LogsSink{}
+	metrics := &consumertest.MetricsSink{}
+	receiver := &mySQLReceiver{
+		config:          &Config{Fields: map[string]string{"app": "billing"}},
+		consumer:        logs,
+		metricsConsumer: metrics,
+		logger:          zap.NewNop(),
+	}
+	dbquery := DBQueries{QueryId: "Q1", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", ExpectedFreshness: "15m"}
+	defer os.Remove("Q1_freshness.csv")
+	start := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	// the staleness is measured from the first collection
+	receiver.checkFreshness(context.Background(), &dbquery, 0, start)
+	receiver.checkFreshness(context.Background(), &dbquery, 0, start.Add(10*time.Minute))
+	require.Empty(t, logs.AllLogs())
+
+	receiver.checkFreshness(context.Background(), &dbquery, 0, start.Add(20*time.Minute))
+	require.Len(t, logs.AllLogs(), 1)
+	record := logs.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]interface{}{diagnosticTypeAttribute: diagnosticTypeFreshnessViolation}, record.Attributes().AsRaw())
+	var violation freshnessViolation
+	require.NoError(t, json.Unmarshal([]byte(record.Body().StringVal()), &violation))
+	require.Equal(t, freshnessViolation{
+		QueryId:           "Q1",
+		ExpectedFreshness: "15m",
+		LastNewRecordsAt:  "2022-08-01T10:00:00Z",
+		StalenessSeconds:  1200,
+	}, violation)
+
+	// new records reset the staleness
+	receiver.checkFreshness(context.Background(), &dbquery, 3, start.Add(30*time.Minute))
+	require.Len(t, logs.AllLogs(), 1)
+
+	require.Len(t, metrics.AllMetrics(), 4)
+	violated := metrics.AllMetrics()[2]
+	require.Equal(t, map[string]interface{}{"app": "billing"}, violated.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	gauges := violated.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, stalenessMetric, gauges.At(0).Name())
+	require.Equal(t, 1200.0, gauges.At(0).Gauge().DataPoints().At(0).DoubleVal())
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1"}, gauges.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+	require.Equal(t, freshnessViolationMetric, gauges.At(1).Name())
+	require.Equal(t, int64(1), gauges.At(1).Gauge().DataPoints().At(0).IntVal())
+
+	fresh := metrics.AllMetrics()[3].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 0.0, fresh.At(0).Gauge().DataPoints().At(0).DoubleVal())
+	require.Equal(t, int64(0), fresh.At(1).Gauge().DataPoints().At(0).IntVal())
+}
+
+type exportersHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h exportersHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+type metricsExporter struct {
+	component.Exporter
+	*consumertest.MetricsSink
+}
+
+func TestLookUpFreshnessMetricsExporter(t *testing.T) {
+	id := config.NewComponentID("sumologic")
+	host := exportersHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.MetricsDataType: {id: metricsExporter{MetricsSink: &consumertest.MetricsSink{}}},
+		},
+	}
+
+	_, err := lookUpFreshnessMetricsExporter(host, id)
+	require.NoError(t, err)
+	_, err = lookUpFreshnessMetricsExporter(host, config.NewComponentID("otlp"))
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// indexColumn is a column the records of an incremental query are fetched in the order of.
+type indexColumn struct {
+	name       string
+	columnType string
+}
+
+// indexColumns returns the index columns of the query, the composite index_columns or the single index_column_name.
+func indexColumns(dbquery *DBQueries) []indexColumn {
+	if len(dbquery.IndexColumns) == 0 {
+		return []indexColumn{{name: dbquery.IndexColumnName, columnType: dbquery.IndexColumnType}}
+	}
+	columns := make([]indexColumn, len(dbquery.IndexColumns))
+	for i, name := range dbquery.IndexColumns {
+		columns[i].name = name
+		if i < len(dbquery.IndexColumnTypes) {
+			columns[i].columnType = dbquery.IndexColumnTypes[i]
+		}
+	}
+	return columns
+}
+
+// isIncrementalQuery returns whether the records of the query are fetched incrementally after the saved state.
+func isIncrementalQuery(dbquery *DBQueries) bool {
+	return len(strings.TrimSpace(dbquery.IndexColumnName)) != 0 || len(dbquery.IndexColumns) != 0
+}
+
+// isCompositeIndex returns whether the state of the query is a composite watermark of its index_columns.
+func isCompositeIndex(dbquery *DBQueries) bool {
+	return len(dbquery.IndexColumns) != 0
+}
+
+// hasTimestampIndexColumn returns whether any index column of the query is a 'TIMESTAMP' column.
+func hasTimestampIndexColumn(dbquery *DBQueries) bool {
+	for _, column := range indexColumns(dbquery) {
+		if column.columnType == "TIMESTAMP" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIndexColumn returns the error of the index_column_type of a query with index_column_name.
+func validateIndexColumn(dbquery DBQueries) error {
+	switch dbquery.IndexColumnType {
+	case "NUMBER", "TIMESTAMP":
+		return nil
+	case "":
+		return errors.New("index_column_type has to be set with index_column_name, either 'NUMBER' or 'TIMESTAMP'")
+	default:
+		return fmt.Errorf("index_column_type '%s' is not supported, it can only be 'NUMBER' or 'TIMESTAMP'", dbquery.IndexColumnType)
+	}
+}
+
+func validateIndexColumns(dbquery DBQueries) error {
+	if len(dbquery.IndexColumnName) != 0 || len(dbquery.IndexColumnType) != 0 {
+		return errors.New("it cannot be used with index_column_name and index_column_type")
+	}
+	if len(dbquery.IndexColumnTypes) != len(dbquery.IndexColumns) {
+		return errors.New("index_column_types has to contain the type of every index column")
+	}
+	seen := make(map[string]bool, len(dbquery.IndexColumns))
+	for i, name := range dbquery.IndexColumns {
+		if len(strings.TrimSpace(name)) == 0 {
+			return errors.New("it cannot contain an empty column name")
+		}
+		if seen[name] {
+			return fmt.Errorf("column %s is used more than once", name)
+		}
+		seen[name] = true
+		if columnType := dbquery.IndexColumnTypes[i]; columnType != "NUMBER" && columnType != "TIMESTAMP" {
+			return fmt.Errorf("the type of column %s can only be 'NUMBER' or 'TIMESTAMP'", name)
+		}
+	}
+	return nil
+}
+
+// indexCondition returns the predicate selecting the records after the state, the tuple of the index columns has to be greater than the state.
+// The tuple comparison is expanded, e.g. (a > ? or (a = ? and b > ?)), as SQL Server and Oracle don't support row value comparisons,
+// the parameters are bound in the order returned by stateArgs.
+func indexCondition(d driver, columns []indexColumn) string {
+	if len(columns) == 1 {
+		return d.quoteIdentifier(columns[0].name) + " > " + d.placeholder(1)
+	}
+	var n int
+	terms := make([]string, len(columns))
+	for i := range columns {
+		comparisons := make([]string, i+1)
+		for j := 0; j <= i; j++ {
+			n++
+			operator := " = "
+			if j == i {
+				operator = " > "
+			}
+			comparisons[j] = d.quoteIdentifier(columns[j].name) + operator + d.placeholder(n)
+		}
+		terms[i] = strings.Join(comparisons, " and ")
+		if i > 0 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return "(" + strings.Join(terms, " or ") + ")"
+}
+
+// indexOrder returns the order by clause of the records of an incremental query.
+func indexOrder(d driver, columns []indexColumn) string {
+	order := make([]string, len(columns))
+	for i, column := range columns {
+		order[i] = d.quoteIdentifier(column.name) + " asc"
+	}
+	return " order by " + strings.Join(order, ", ")
+}
+
+// stateArgs converts the saved state into the parameters bound to the predicate returned by indexCondition.
+// The state of a composite index is a JSON array with the value of every index column.
+func stateArgs(d driver, dbquery *DBQueries, state string) ([]interface{}, error) {
+	loc := indexColumnLocation(dbquery)
+	columns := indexColumns(dbquery)
+	if !isCompositeIndex(dbquery) {
+		return []interface{}{stateArg(d, columns[0].columnType, state, loc)}, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(state), &values); err != nil || len(values) != len(columns) {
+		return nil, fmt.Errorf("invalid state %s of index columns %s", state, strings.Join(dbquery.IndexColumns, ", "))
+	}
+	var args []interface{}
+	for i := range columns {
+		for j := 0; j <= i; j++ {
+			args = append(args, stateArg(d, columns[j].columnType, values[j], loc))
+		}
+	}
+	return args, nil
+}
+
+// recordState returns the state of the query after the record, i.e. the value of its index column or a JSON array of the values of its index columns.
+func recordState(dbquery *DBQueries, record string) (string, error) {
+	values, err := readRecord(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to read index column value from the last record: %w", err)
+	}
+	columns := indexColumns(dbquery)
+	state := make([]string, len(columns))
+	for i, column := range columns {
+		value, ok := values[column.name]
+		if !ok || value == nil {
+			return "", fmt.Errorf("index column %s is missing in the query result", column.name)
+		}
+		state[i] = *value
+	}
+	if !isCompositeIndex(dbquery) {
+		return state[0], nil
+	}
+	composite, err := json.Marshal(state)
+	return string(composite), err
+}
+
+// getCompositeInitialStateValue returns the initial state of a composite index, the initial_index_value is the value of the first index column,
+// the other columns start at their defaults, which doesn't matter as the first column of the records is greater than its initial state.
+func getCompositeInitialStateValue(dbquery *DBQueries, logger *zap.Logger) string {
+	columns := indexColumns(dbquery)
+	state := make([]string, len(columns))
+	for i, column := range columns {
+		columnQuery := DBQueries{
+			QueryId:             dbquery.QueryId,
+			IndexColumnName:     column.name,
+			IndexColumnType:     column.columnType,
+			IndexColumnTimezone: dbquery.IndexColumnTimezone,
+		}
+		// the defaults of the other columns would be logged as missing initial_index_value
+		if i == 0 {
+			columnQuery.InitialIndexValue = dbquery.InitialIndexValue
+		} else if column.columnType == "TIMESTAMP" {
+			columnQuery.InitialIndexValue = time.Unix(0, 0).In(indexColumnLocation(dbquery)).Format("2006-01-02 15:04:05")
+		} else {
+			columnQuery.InitialIndexValue = "0"
+		}
+		state[i] = getInitialStateValue(&columnQuery, logger)
+	}
+	composite, _ := json.Marshal(state)
+	return string(composite)
+}
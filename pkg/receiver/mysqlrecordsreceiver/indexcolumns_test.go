@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIndexCondition(t *testing.T) {
+	columns := []indexColumn{{name: "UpdatedAt", columnType: "TIMESTAMP"}, {name: "OrderID", columnType: "NUMBER"}, {name: "LineNo", columnType: "NUMBER"}}
+	require.Equal(t, "`UpdatedAt` > ?", indexCondition(mySQLDriver{}, columns[:1]))
+	require.Equal(t, "(`UpdatedAt` > ? or (`UpdatedAt` = ? and `OrderID` > ?))", indexCondition(mySQLDriver{}, columns[:2]))
+	require.Equal(t, `("UpdatedAt" > $1 or ("UpdatedAt" = $2 and "OrderID" > $3) or ("UpdatedAt" = $4 and "OrderID" = $5 and "LineNo" > $6))`, indexCondition(postgreSQLDriver{}, columns))
+	require.Equal(t, " order by [UpdatedAt] asc, [OrderID] asc", indexOrder(sqlServerDriver{}, columns[:2]))
+}
+
+func TestStateArgs(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}}
+	args, err := stateArgs(postgreSQLDriver{}, &dbquery, `["2022-08-01 10:00:00","42"]`)
+	require.NoError(t, err)
+	updatedAt := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	require.Equal(t, []interface{}{updatedAt, updatedAt, int64(42)}, args)
+
+	_, err = stateArgs(postgreSQLDriver{}, &dbquery, "42")
+	require.Error(t, err)
+	_, err = stateArgs(postgreSQLDriver{}, &dbquery, `["2022-08-01 10:00:00"]`)
+	require.Error(t, err)
+
+	dbquery = DBQueries{QueryId: "Q1", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"}
+	args, err = stateArgs(postgreSQLDriver{}, &dbquery, "42")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(42)}, args)
+}
+
+func TestRecordState(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}}
+	state, err := recordState(&dbquery, `{"OrderID":42,"UpdatedAt":"2022-08-01 10:00:00","Total":"2.5"}`)
+	require.NoError(t, err)
+	require.Equal(t, `["2022-08-01 10:00:00","42"]`, state)
+
+	_, err = recordState(&dbquery, `{"UpdatedAt":"2022-08-01 10:00:00","OrderID":null}`)
+	require.EqualError(t, err, "index column OrderID is missing in the query result")
+
+	dbquery = DBQueries{QueryId: "Q1", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"}
+	state, err = recordState(&dbquery, `{"OrderID":"42"}`)
+	require.NoError(t, err)
+	require.Equal(t, "42", state)
+}
+
+func TestCompositeInitialStateValue(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}, InitialIndexValue: "2022-08-01T10:00:00Z"}
+	require.Equal(t, `["2022-08-01 09:59:59 +0000 UTC","0"]`, getInitialStateValue(&dbquery, zap.NewNop()))
+
+	dbquery = DBQueries{QueryId: "Q1", IndexColumns: []string{"OrderID", "UpdatedAt"}, IndexColumnTypes: []string{"NUMBER", "TIMESTAMP"}, InitialIndexValue: "100"}
+	require.Equal(t, `["99","1969-12-31 23:59:59 +0000 UTC"]`, getInitialStateValue(&dbquery, zap.NewNop()))
+}
+
+func TestValidateIndexColumns(t *testing.T) {
+	require.NoError(t, validateIndexColumns(DBQueries{IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}}))
+	require.Error(t, validateIndexColumns(DBQueries{IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP"}}))
+	require.Error(t, validateIndexColumns(DBQueries{IndexColumns: []string{"UpdatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "STRING"}}))
+	require.Error(t, validateIndexColumns(DBQueries{IndexColumns: []string{"OrderID", "OrderID"}, IndexColumnTypes: []string{"NUMBER", "NUMBER"}}))
+	require.Error(t, validateIndexColumns(DBQueries{IndexColumns: []string{"UpdatedAt", " "}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}}))
+	require.Error(t, validateIndexColumns(DBQueries{IndexColumnName: "OrderID", IndexColumns: []string{"UpdatedAt"}, IndexColumnTypes: []string{"TIMESTAMP"}}))
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func validateQueryMetric(dbquery DBQueries) error {
+	if len(dbquery.MetricName) == 0 {
+		if len(dbquery.ValueColumn) != 0 || len(dbquery.AttributeColumns) != 0 {
+			return errors.New("value_column and attribute_columns can only be used with metric_name")
+		}
+		return nil
+	}
+	if len(dbquery.ValueColumn) == 0 {
+		return errors.New("value_column is required with metric_name")
+	}
+	for _, column := range dbquery.AttributeColumns {
+		if len(column) == 0 {
+			return errors.New("attribute_columns cannot contain an empty column")
+		}
+		if column == dbquery.ValueColumn {
+			return errors.New("value_column cannot be one of attribute_columns")
+		}
+	}
+	return nil
+}
+
+// isPipelineQuery returns whether the query is collected by this receiver. The queries with metric_name are collected
+// by the receiver of a metrics pipeline and the other queries by the receiver of a logs pipeline, so each query is run once.
+func (m *mySQLReceiver) isPipelineQuery(dbquery DBQueries) bool {
+	return (len(dbquery.MetricName) != 0) == (m.nextMetrics != nil)
+}
+
+// consumeQueryMetrics converts the records of the query into data points of its metric and passes them to the metrics consumer.
+func (m *mySQLReceiver) consumeQueryMetrics(ctx context.Context, dbquery *DBQueries, records map[string]string, metadata *queryMetadata) {
+	md := m.buildQueryMetrics(dbquery, records, metadata, time.Now())
+	if md.DataPointCount() == 0 {
+		return
+	}
+	if err := m.consumeMetrics(ctx, md); err != nil {
+		m.logger.Error("Failed to consume query metrics", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+	}
+}
+
+// buildQueryMetrics converts each record of the query into a data point of a gauge named metric_name, in the order of the query result.
+// The value of value_column is an int data point if it's an integer and a double data point otherwise,
+// records with a value which is not a number, e.g. NULL, are skipped.
+func (m *mySQLReceiver) buildQueryMetrics(dbquery *DBQueries, records map[string]string, metadata *queryMetadata, now time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	m.setResourceAttributes(rm.Resource().Attributes())
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(dbquery.MetricName)
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	dataPoints := metric.Gauge().DataPoints()
+	timestamp := pcommon.NewTimestampFromTime(now)
+
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sortRecordKeys(keys)
+	for _, key := range keys {
+		columns, err := readRecord(records[key])
+		if err != nil {
+			m.logger.Error("Failed to read record of query", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+			continue
+		}
+		columnValue, ok := columns[dbquery.ValueColumn]
+		if !ok {
+			m.logger.Warn("Value column is missing in the query result", zap.String("queryId", dbquery.QueryId), zap.String("valueColumn", dbquery.ValueColumn))
+			continue
+		}
+		var value string
+		if columnValue != nil {
+			value = *columnValue
+		}
+		intValue, intErr := strconv.ParseInt(value, 10, 64)
+		doubleValue, doubleErr := strconv.ParseFloat(value, 64)
+		if intErr != nil && doubleErr != nil {
+			m.logger.Warn("Value of the record is not a number, skipping the record", zap.String("queryId", dbquery.QueryId), zap.String("value", value))
+			continue
+		}
+
+		dp := dataPoints.AppendEmpty()
+		dp.SetTimestamp(timestamp)
+		if intErr == nil {
+			dp.SetIntVal(intValue)
+		} else {
+			dp.SetDoubleVal(doubleValue)
+		}
+		for key, value := range dbquery.Attributes {
+			dp.Attributes().UpsertString(key, value)
+		}
+		if metadata != nil {
+			metadata.setAttributes(dp.Attributes())
+		}
+		dp.Attributes().UpsertString(queryIdAttribute, dbquery.QueryId)
+		for _, column := range dbquery.AttributeColumns {
+			if attribute, ok := columns[column]; ok && attribute != nil {
+				dp.Attributes().UpsertString(column, *attribute)
+			}
+		}
+	}
+	return md
+}
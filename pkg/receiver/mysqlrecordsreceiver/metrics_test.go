@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestValidateQueryMetric(t *testing.T) {
+	require.NoError(t, validateQueryMetric(DBQueries{QueryId: "Q1"}))
+	require.NoError(t, validateQueryMetric(DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Status"}}))
+	require.Error(t, validateQueryMetric(DBQueries{QueryId: "Q1", ValueColumn: "Total"}))
+	require.Error(t, validateQueryMetric(DBQueries{QueryId: "Q1", AttributeColumns: []string{"Status"}}))
+	require.Error(t, validateQueryMetric(DBQueries{QueryId: "Q1", MetricName: "orders.count"}))
+	require.Error(t, validateQueryMetric(DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Total"}}))
+	require.Error(t, validateQueryMetric(DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{""}}))
+}
+
+func TestIsPipelineQuery(t *testing.T) {
+	metricQuery := DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total"}
+	logQuery := DBQueries{QueryId: "Q2"}
+
+	logsReceiver := &mySQLReceiver{consumer: consumertest.NewNop()}
+	require.False(t, logsReceiver.isPipelineQuery(metricQuery))
+	require.True(t, logsReceiver.isPipelineQuery(logQuery))
+
+	metricsReceiver := &mySQLReceiver{nextMetrics: consumertest.NewNop()}
+	require.True(t, metricsReceiver.isPipelineQuery(metricQuery))
+	require.False(t, metricsReceiver.isPipelineQuery(logQuery))
+}
+
+func TestBuildQueryMetrics(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{Fields: map[string]string{"app": "billing"}}, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Status"}}
+	records := map[string]string{
+		"Q1_record1":  `{"Status":"open","Total":"12"}`,
+		"Q1_record2":  `{"Status":"closed","Total":"2.5"}`,
+		"Q1_record3":  `{"Status":"unknown","Total":"NULL"}`,
+		"Q1_record10": `{"Total":"7"}`,
+	}
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	md := receiver.buildQueryMetrics(&dbquery, records, nil, now)
+	require.Equal(t, map[string]interface{}{"app": "billing"}, md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	metric := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "orders.count", metric.Name())
+	dataPoints := metric.Gauge().DataPoints()
+	// the record with a NULL value is skipped
+	require.Equal(t, 3, dataPoints.Len())
+	require.Equal(t, int64(12), dataPoints.At(0).IntVal())
+	require.Equal(t, now, dataPoints.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1", "Status": "open"}, dataPoints.At(0).Attributes().AsRaw())
+	require.Equal(t, 2.5, dataPoints.At(1).DoubleVal())
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1", "Status": "closed"}, dataPoints.At(1).Attributes().AsRaw())
+	require.Equal(t, int64(7), dataPoints.At(2).IntVal())
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1"}, dataPoints.At(2).Attributes().AsRaw())
+}
+
+func TestBuildQueryMetricsWAttributes(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{}, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Status"}, Attributes: map[string]string{"app": "billing", "Status": "static"}}
+	records := map[string]string{"Q1_record1": `{"Status":"open","Total":"12"}`}
+
+	md := receiver.buildQueryMetrics(&dbquery, records, nil, time.Now())
+	dataPoints := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	// the attribute columns take precedence over the static attributes
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1", "Status": "open", "app": "billing"}, dataPoints.At(0).Attributes().AsRaw())
+}
+
+func TestBuildQueryMetricsWTypedValues(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{}, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total", AttributeColumns: []string{"Status"}}
+	records := map[string]string{
+		"Q1_record1": `{"Status":"open","Total":12}`,
+		"Q1_record2": `{"Status":null,"Total":2.5}`,
+		"Q1_record3": `{"Status":"unknown","Total":null}`,
+	}
+
+	md := receiver.buildQueryMetrics(&dbquery, records, nil, time.Now())
+	dataPoints := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 2, dataPoints.Len())
+	require.Equal(t, int64(12), dataPoints.At(0).IntVal())
+	require.Equal(t, 2.5, dataPoints.At(1).DoubleVal())
+	// a NULL attribute column is not set
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1"}, dataPoints.At(1).Attributes().AsRaw())
+}
+
+func TestConsumeQueryMetrics(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	receiver := &mySQLReceiver{config: &Config{}, nextMetrics: sink, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", MetricName: "orders.count", ValueColumn: "Total"}
+	metadata := &queryMetadata{queryId: "Q1", scrapeStartTime: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC), batchSequence: 3}
+
+	receiver.consumeQueryMetrics(context.Background(), &dbquery, map[string]string{"Q1_record1": `{"Total":"NULL"}`}, metadata)
+	require.Empty(t, sink.AllMetrics())
+
+	receiver.consumeQueryMetrics(context.Background(), &dbquery, map[string]string{"Q1_record1": `{"Total":"42"}`}, metadata)
+	require.Len(t, sink.AllMetrics(), 1)
+	dp := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	require.Equal(t, int64(42), dp.IntVal())
+	require.Equal(t, map[string]interface{}{
+		"mysql.query_id":          "Q1",
+		"mysql.scrape_start_time": "2022-08-01T10:00:00Z",
+		"mysql.query_duration_ms": int64(0),
+		"mysql.batch_sequence":    int64(3),
+	}, dp.Attributes().AsRaw())
+}
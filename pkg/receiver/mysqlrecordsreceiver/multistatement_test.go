@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// resultSet is a result set of the fake database, with the columns and the rows of the result
+type resultSet struct {
+	columns []string
+	rows    [][]sqldriver.Value
+}
+
+// resultSetsConnector is a fake database returning the configured result sets for every query.
+type resultSetsConnector struct {
+	resultSets []resultSet
+}
+
+func (c *resultSetsConnector) Connect(context.Context) (sqldriver.Conn, error) {
+	return &resultSetsConn{resultSets: c.resultSets}, nil
+}
+
+func (c *resultSetsConnector) Driver() sqldriver.Driver {
+	return nil
+}
+
+type resultSetsConn struct {
+	resultSets []resultSet
+}
+
+func (c *resultSetsConn) Prepare(query string) (sqldriver.Stmt, error) {
+	return nil, sqldriver.ErrSkip
+}
+
+func (c *resultSetsConn) Close() error {
+	return nil
+}
+
+func (c *resultSetsConn) Begin() (sqldriver.Tx, error) {
+	return nil, sqldriver.ErrSkip
+}
+
+func (c *resultSetsConn) QueryContext(ctx context.Context, query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	return &resultSetsRows{resultSets: c.resultSets}, nil
+}
+
+type resultSetsRows struct {
+	resultSets []resultSet
+	current    int
+	row        int
+}
+
+func (r *resultSetsRows) Columns() []string {
+	return r.resultSets[r.current].columns
+}
+
+func (r *resultSetsRows) Close() error {
+	return nil
+}
+
+func (r *resultSetsRows) Next(dest []sqldriver.Value) error {
+	rows := r.resultSets[r.current].rows
+	if r.row == len(rows) {
+		return io.EOF
+	}
+	copy(dest, rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *resultSetsRows) HasNextResultSet() bool {
+	return r.current+1 < len(r.resultSets)
+}
+
+func (r *resultSetsRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.current++
+	r.row = 0
+	return nil
+}
+
+func newResultSetsClient(resultSets ...resultSet) *sqlClient {
+	return &sqlClient{
+		driver:  mySQLDriver{},
+		client:  sql.OpenDB(&resultSetsConnector{resultSets: resultSets}),
+		logger:  zap.NewNop(),
+		schemas: make(map[string][]columnSchema),
+	}
+}
+
+var testResultSets = []resultSet{
+	{columns: []string{"name"}, rows: [][]sqldriver.Value{{[]byte("a")}, {[]byte("b")}}},
+	{columns: []string{"total"}, rows: [][]sqldriver.Value{{[]byte("2")}}},
+	{columns: []string{"name", "total"}, rows: [][]sqldriver.Value{{[]byte("c"), []byte("3")}, {[]byte("d"), []byte("4")}}},
+}
+
+func TestStreamQueryRecordsWMultipleResultSets(t *testing.T) {
+	sqlclient := newResultSetsClient(testResultSets...)
+	defer sqlclient.client.Close()
+
+	var batches []map[string]string
+	err := sqlclient.StreamQueryRecords(context.Background(), "select name from a; select count(*) as total from b", "Q1", fetchOptions{}, func(records map[string]string, lastIndex string) error {
+		batches = append(batches, records)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{{
+		"Q1_record1":         `{"name":"a"}`,
+		"Q1_record2":         `{"name":"b"}`,
+		"Q1_result2_record1": `{"total":"2"}`,
+		"Q1_result3_record1": `{"name":"c","total":"3"}`,
+		"Q1_result3_record2": `{"name":"d","total":"4"}`,
+	}}, batches)
+	// the schema of the first result set is saved
+	schema, ok := sqlclient.getQuerySchema("Q1")
+	require.True(t, ok)
+	require.Len(t, schema, 1)
+	require.Equal(t, "name", schema[0].Name)
+}
+
+func TestStreamQueryRecordsWMultipleResultSetsInBatches(t *testing.T) {
+	sqlclient := newResultSetsClient(testResultSets...)
+	defer sqlclient.client.Close()
+
+	var batches []map[string]string
+	err := sqlclient.StreamQueryRecords(context.Background(), "select name from a; select count(*) as total from b", "Q1", fetchOptions{batchSize: 2}, func(records map[string]string, lastIndex string) error {
+		batches = append(batches, records)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"Q1_record1": `{"name":"a"}`, "Q1_record2": `{"name":"b"}`},
+		{"Q1_result2_record1": `{"total":"2"}`, "Q1_result3_record1": `{"name":"c","total":"3"}`},
+		{"Q1_result3_record2": `{"name":"d","total":"4"}`},
+	}, batches)
+}
+
+func TestSortRecordKeysWResultSets(t *testing.T) {
+	keys := []string{"Q1_result10_record1", "Q1_result2_record2", "Q1_record10", "Q1_result2_record1", "Q1_record2"}
+	sortRecordKeys(keys)
+	require.Equal(t, []string{"Q1_record2", "Q1_record10", "Q1_result2_record1", "Q1_result2_record2", "Q1_result10_record1"}, keys)
+}
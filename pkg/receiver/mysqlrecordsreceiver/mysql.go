@@ -0,0 +1,300 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+const (
+	//dbDriverMySQL connects to MySQL and compatible servers, e.g. MariaDB and Amazon Aurora MySQL
+	dbDriverMySQL = "mysql"
+	//defaultSocketPath is the default location of the unix socket of the MySQL server on most Linux distributions
+	defaultSocketPath = "/var/run/mysqld/mysqld.sock"
+)
+
+func init() {
+	registerDriver(dbDriverMySQL, mySQLDriver{})
+}
+
+type mySQLDriver struct {
+	baseDriver
+}
+
+var _ innoDBDriver = mySQLDriver{}
+var _ iamAuthDriver = mySQLDriver{}
+
+//This function is used for reading certificates from .pem file for different AWS regions and passing them on as a tls config for authentication.
+//Details : https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/UsingWithRDS.SSL.html
+func createIAMRDSTLSConf(pempath string, logger *zap.Logger) tls.Config {
+	rootCertPool := x509.NewCertPool()
+	globalpem, err := ioutil.ReadFile(pempath)
+	if err != nil {
+		logger.Error("error in reading pem file", zap.Error(err))
+	}
+	if ok := rootCertPool.AppendCertsFromPEM(globalpem); !ok {
+		logger.Error("error in loading certificates from pem file", zap.Error(err))
+	}
+	return tls.Config{
+		RootCAs: rootCertPool,
+	}
+}
+
+func socketPath(conf *Config) string {
+	if len(conf.SocketPath) == 0 {
+		return defaultSocketPath
+	}
+	return conf.SocketPath
+}
+
+//There are 6 scenarios here for creating connection strings for a database connection
+//1. With a plaintext password, through tcp or the unix socket of a local server
+//2. With an encrypted plaintext password
+//3. With an AWS Authentication token to be used as a password
+//4. Without a password, through the unix socket of a local server with the auth_socket plugin
+//5. Without a password, through the Cloud SQL dialer with automatic IAM authentication
+//6. With an Azure AD access token to be used as a password
+func (mySQLDriver) connectionString(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) (string, error) {
+	var driverConf mysql.Config
+	basicauthpassword := basicAuthPassword(conf, logger)
+	endpoint := conf.DBHost + ":" + conf.DBPort
+	//The first endpoint of dbhosts is the address of the connection string, e.g. the host name the TLS certificate of the server is verified against
+	if len(conf.DBHosts) != 0 {
+		endpoint = failoverEndpoints(conf)[0]
+	}
+	if conf.AuthenticationMode == "IAMRDSAuth" {
+		//The authentication token is set by withIAMAuthToken for every new connection
+		//The tls settings replace the certificates of aws_certificate_path
+		if conf.TLS == nil {
+			tlsConf := createIAMRDSTLSConf(conf.AWSCertificatePath, logger)
+			tlserr := mysql.RegisterTLSConfig("custom", &tlsConf)
+			if tlserr != nil {
+				logger.Error("Error %s when RegisterTLSConfig\n", zap.Error(tlserr))
+			}
+		}
+		driverConf = mysql.Config{
+			User:                    conf.Username,
+			Net:                     conf.Transport,
+			Addr:                    endpoint,
+			DBName:                  conf.Database,
+			AllowNativePasswords:    conf.AllowNativePasswords,
+			TLSConfig:               "custom",
+			AllowCleartextPasswords: true,
+		}
+	} else if conf.AuthenticationMode == "GCPCloudSQLIAM" {
+		//The dialer establishes the TLS connection with the OAuth2 token of the IAM principal, which the server accepts as a cleartext password
+		network, err := registerCloudSQLDialer(conf)
+		if err != nil {
+			return "", fmt.Errorf("error in registering Cloud SQL dialer: %w", err)
+		}
+		driverConf = mysql.Config{
+			User:                    conf.Username,
+			Net:                     network,
+			Addr:                    conf.CloudSQLInstance,
+			DBName:                  conf.Database,
+			AllowNativePasswords:    conf.AllowNativePasswords,
+			AllowCleartextPasswords: true,
+		}
+	} else if conf.AuthenticationMode == "AzureADAuth" {
+		//The password is the Azure AD access token set by the password provider, the server requires it to be sent in cleartext over TLS
+		driverConf = mysql.Config{
+			User:                    conf.Username,
+			Passwd:                  conf.Password,
+			Net:                     conf.Transport,
+			Addr:                    endpoint,
+			DBName:                  conf.Database,
+			AllowNativePasswords:    conf.AllowNativePasswords,
+			TLSConfig:               "true",
+			AllowCleartextPasswords: true,
+		}
+	} else if conf.AuthenticationMode == "SocketAuth" {
+		//No password is sent, the server authenticates the OS user of the collector process connected to its unix socket
+		driverConf = mysql.Config{
+			User:                 conf.Username,
+			Net:                  "unix",
+			Addr:                 socketPath(conf),
+			DBName:               conf.Database,
+			AllowNativePasswords: conf.AllowNativePasswords,
+		}
+	} else {
+		//The 'unix' transport connects to the unix socket of the local server with the password, dbhost and dbport are not used then
+		addr := endpoint
+		if conf.Transport == "unix" {
+			addr = socketPath(conf)
+		}
+		driverConf = mysql.Config{
+			User:                 conf.Username,
+			Passwd:               basicauthpassword,
+			Net:                  conf.Transport,
+			Addr:                 addr,
+			DBName:               conf.Database,
+			AllowNativePasswords: conf.AllowNativePasswords,
+		}
+	}
+	//Establishing the TLS connection with the CA and the client certificate of the tls settings
+	if conf.TLS != nil {
+		name, err := registerTLSConfig(conf)
+		if err != nil {
+			return "", fmt.Errorf("error in registering TLS config: %w", err)
+		}
+		driverConf.TLSConfig = name
+	}
+	//Establishing the connection through the SOCKS5 or HTTP proxy, the TLS connection to the database is still established end to end
+	proxyURL := conf.ProxyURL
+	if len(proxyURL) == 0 && conf.ProxyFromEnvironment {
+		var err error
+		if proxyURL, err = environmentProxyURL(endpoint); err != nil {
+			return "", fmt.Errorf("error in reading proxy environment variables: %w", err)
+		}
+	}
+	if len(proxyURL) != 0 {
+		network, err := registerProxyDialer(conf, proxyURL)
+		if err != nil {
+			return "", fmt.Errorf("error in registering proxy dialer: %w", err)
+		}
+		driverConf.Net = network
+	}
+	//Establishing the connection through the SSH tunnel to the bastion host, the address of the connection string is resolved by the bastion host
+	if conf.SSHTunnel != nil {
+		network, err := registerSSHTunnelDialer(conf)
+		if err != nil {
+			return "", fmt.Errorf("error in registering SSH tunnel dialer: %w", err)
+		}
+		driverConf.Net = network
+	}
+	//Connecting to the first reachable endpoint of dbhosts, the failover dialer uses the proxy or the SSH tunnel itself
+	if len(conf.DBHosts) != 0 {
+		network, err := registerFailoverDialer(conf, logger)
+		if err != nil {
+			return "", fmt.Errorf("error in registering failover dialer: %w", err)
+		}
+		driverConf.Net = network
+	}
+	//The queries with multiple statements are sent in a single round trip, the driver rejects them unless multi statements are enabled
+	for _, dbquery := range conf.DBQueries {
+		if dbquery.MultiStatement {
+			driverConf.MultiStatements = true
+		}
+	}
+	//Setting the session to read only on every new connection, so that the configured queries cannot modify any data
+	if conf.ReadOnlySession {
+		driverConf.Params = map[string]string{"transaction_read_only": "1"}
+	}
+	return driverConf.FormatDSN(), nil
+}
+
+func (mySQLDriver) withIAMAuthToken(ctx context.Context, connStr string, conf *Config, loadAWSConfig awsConfigLoader) (string, error) {
+	driverConf, err := mysql.ParseDSN(connStr)
+	if err != nil {
+		return "", err
+	}
+	if driverConf.Passwd, err = generateIAMAuthToken(ctx, driverConf.Addr, conf, loadAWSConfig); err != nil {
+		return "", err
+	}
+	return driverConf.FormatDSN(), nil
+}
+
+func (mySQLDriver) dbSystem() string {
+	return "mysql"
+}
+
+//MySQL treats double quoted strings as string literals unless the ANSI_QUOTES mode is enabled, so identifiers are quoted with backticks
+func (mySQLDriver) quoteIdentifier(name string) string {
+	return quoteIdentifier(name, "`", "`")
+}
+
+//The MySQL driver converts bound times into the time zone of the connection string, so the wall clock in the time zone of the index column is bound as a string,
+//which MySQL compares with DATETIME columns as it is and converts from the session time zone for TIMESTAMP columns
+func (mySQLDriver) timestampArg(t time.Time) interface{} {
+	return t.Format("2006-01-02 15:04:05.999999")
+}
+
+//The named time zones require the time zone tables of the MySQL server, UTC is set as an offset so it works without them
+func (mySQLDriver) setSessionTimeZone(ctx context.Context, conn *sql.Conn, loc *time.Location) (func() error, error) {
+	var previous string
+	if err := conn.QueryRowContext(ctx, "SELECT @@session.time_zone").Scan(&previous); err != nil {
+		return nil, fmt.Errorf("failed to read session time zone: %w", err)
+	}
+	timeZone := loc.String()
+	if loc == time.UTC {
+		timeZone = "+00:00"
+	}
+	if _, err := conn.ExecContext(ctx, "SET time_zone = ?", timeZone); err != nil {
+		return nil, fmt.Errorf("failed to set session time zone to %s: %w", timeZone, err)
+	}
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SET time_zone = ?", previous)
+		return err
+	}, nil
+}
+
+//'WindowsAuth' is specific to SQL Server
+func (mySQLDriver) validate(cfg *Config) error {
+	return validateAuthenticationMode(cfg, "BasicAuth", "IAMRDSAuth", "SocketAuth", "GCPCloudSQLIAM", "AzureADAuth")
+}
+
+//This function returns the current binlog file and position of SHOW MASTER STATUS, it requires the REPLICATION CLIENT privilege
+func (mySQLDriver) binlogPosition(db *sql.DB) (string, uint32, error) {
+	rows, err := db.Query("SHOW MASTER STATUS")
+	if err != nil {
+		return "", 0, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", 0, err
+	}
+	if len(columns) < 2 {
+		return "", 0, fmt.Errorf("unexpected columns of SHOW MASTER STATUS: %v", columns)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", 0, err
+		}
+		return "", 0, errors.New("binary logging is not enabled on the server")
+	}
+	values := make([]sql.RawBytes, len(columns))
+	scanArgs := make([]interface{}, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return "", 0, err
+	}
+	pos, err := strconv.ParseUint(string(values[1]), 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid binlog position '%s': %w", values[1], err)
+	}
+	return string(values[0]), uint32(pos), nil
+}
+
+//This function returns the InnoDB monitor output of SHOW ENGINE INNODB STATUS, it requires the PROCESS privilege
+func (mySQLDriver) innoDBStatus(db *sql.DB) (string, error) {
+	var engineType, name, status string
+	if err := db.QueryRow("SHOW ENGINE INNODB STATUS").Scan(&engineType, &name, &status); err != nil {
+		return "", err
+	}
+	return status, nil
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+const (
+	//nullHandlingSentinel sets NULL column values as the null_sentinel string in the records
+	nullHandlingSentinel = "sentinel"
+	//nullHandlingNull sets NULL column values as JSON null in the records
+	nullHandlingNull = "null"
+	//nullHandlingOmit omits the columns with NULL values from the records
+	nullHandlingOmit = "omit"
+	//defaultNullSentinel is the default string NULL column values are set as with null_handling: 'sentinel'
+	defaultNullSentinel = "NULL"
+)
+
+//nullFormat defines how the NULL column values are set in the records
+type nullFormat struct {
+	handling string
+	sentinel string
+}
+
+//This function returns the null format of the config, the sentinel string is "NULL" if null_sentinel is not set
+func newNullFormat(cfg *Config) nullFormat {
+	f := nullFormat{handling: cfg.NullHandling, sentinel: cfg.NullSentinel}
+	if len(f.handling) == 0 {
+		f.handling = nullHandlingSentinel
+	}
+	if len(f.sentinel) == 0 {
+		f.sentinel = defaultNullSentinel
+	}
+	return f
+}
+
+//This function sets a NULL value of the column in the json object of a record by the null handling
+func (f nullFormat) setNull(jsonObject map[string]interface{}, column string) {
+	switch f.handling {
+	case nullHandlingNull:
+		jsonObject[column] = nil
+	case nullHandlingOmit:
+	default:
+		jsonObject[column] = f.sentinel
+	}
+}
+
+//This function returns whether the value of a column read from a record is NULL, i.e. JSON null or the sentinel string
+func (f nullFormat) isNull(value *string) bool {
+	return value == nil || (f.handling == nullHandlingSentinel && *value == f.sentinel)
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	// registers the oracle driver
+	_ "github.com/sijms/go-ora/v2"
+)
+
+const (
+	//dbDriverOracle connects to Oracle Database, the database is the service name of the database
+	dbDriverOracle = "oracle"
+	//defaultOraclePort is the port of the Oracle listener used when dbport is not set
+	defaultOraclePort = "1521"
+)
+
+func init() {
+	registerDriver(dbDriverOracle, oracleDriver{})
+}
+
+type oracleDriver struct {
+	baseDriver
+}
+
+//The connection string of an Oracle database connection uses a plaintext or an encrypted password with 'BasicAuth'
+//The database is the service name of the database, e.g. ORCLPDB1
+func (oracleDriver) connectionString(conf *Config, _ awsConfigLoader, logger *zap.Logger) (string, error) {
+	port := conf.DBPort
+	if len(port) == 0 {
+		port = defaultOraclePort
+	}
+	u := url.URL{
+		Scheme: "oracle",
+		User:   url.UserPassword(conf.Username, basicAuthPassword(conf, logger)),
+		Host:   net.JoinHostPort(conf.DBHost, port),
+		Path:   "/" + conf.Database,
+	}
+	return u.String(), nil
+}
+
+func (oracleDriver) dbSystem() string {
+	return "oracle"
+}
+
+func (oracleDriver) placeholder(n int) string {
+	return ":" + strconv.Itoa(n)
+}
+
+//Oracle rejects statements terminated with a semicolon
+func (oracleDriver) statementTerminator() string {
+	return ""
+}
+
+//Oracle has no limit clause, the row limiting clause requires Oracle 12c or later
+func (oracleDriver) limitClause(rows int) string {
+	return " fetch first " + strconv.Itoa(rows) + " rows only"
+}
+
+//Oracle has no IAM database authentication, no read only sessions and no multi statement queries
+func (oracleDriver) validate(cfg *Config) error {
+	return multierr.Combine(
+		validateAuthenticationMode(cfg, "BasicAuth"),
+		validateMySQLOnlyOptions(cfg),
+		validateNoReadOnlySession(cfg),
+		validateNoMultiStatementQueries(cfg),
+	)
+}
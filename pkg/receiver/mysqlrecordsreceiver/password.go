@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//passwordProvider provides the password of the connections which isn't set in the collector config, it may change while the receiver runs
+type passwordProvider interface {
+	get(ctx context.Context) (string, error)
+}
+
+//This function returns the provider of the password fetched from AWS Secrets Manager, read from password_file or of the Azure AD access token,
+//nil if the password is set in the config
+func newPasswordProvider(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) passwordProvider {
+	if conf.AuthenticationMode == "AzureADAuth" {
+		return newAzureADPassword(conf, logger)
+	}
+	if conf.PasswordSource == passwordSourceAWSSecretsManager {
+		return newSecretPassword(conf, loadAWSConfig, logger)
+	}
+	if len(conf.PasswordFile) != 0 {
+		return &filePassword{path: conf.PasswordFile, logger: logger}
+	}
+	return nil
+}
+
+//filePassword reads the password from a file, e.g. a Kubernetes secret mounted as a file
+//The file is read again once its modification time changed, so the new connections use the rotated password
+type filePassword struct {
+	path   string
+	logger *zap.Logger
+
+	lock     sync.Mutex
+	password string
+	modTime  time.Time
+}
+
+//This function returns the password of the file, the cached password is used while the changed file cannot be read
+func (f *filePassword) get(_ context.Context) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	info, err := os.Stat(f.path)
+	if err == nil && !f.modTime.IsZero() && info.ModTime().Equal(f.modTime) {
+		return f.password, nil
+	}
+	var content []byte
+	if err == nil {
+		content, err = os.ReadFile(f.path)
+	}
+	if err != nil {
+		if f.modTime.IsZero() {
+			return "", fmt.Errorf("failed to read password_file: %w", err)
+		}
+		f.logger.Warn("Failed to read the changed password_file, using the cached password", zap.String("passwordFile", f.path), zap.Error(err))
+		return f.password, nil
+	}
+	//The files written by editors and by kubectl end with a newline, which isn't a part of the password
+	f.password = strings.TrimRight(string(content), "\r\n")
+	f.modTime = info.ModTime()
+	return f.password, nil
+}
+
+//This function returns the password of the environment variable of password_env
+func envPassword(conf *Config) (string, error) {
+	password, ok := os.LookupEnv(conf.PasswordEnv)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s of password_env is not set", conf.PasswordEnv)
+	}
+	return password, nil
+}
+
+func validatePasswordEnvAndFile(cfg *Config) error {
+	if len(cfg.PasswordEnv) == 0 && len(cfg.PasswordFile) == 0 {
+		return nil
+	}
+	if len(cfg.PasswordEnv) != 0 && len(cfg.PasswordFile) != 0 {
+		return errors.New("password_env and password_file cannot be used together")
+	}
+	if cfg.AuthenticationMode != "BasicAuth" {
+		return errors.New("password_env and password_file can only be used with authentication_mode : 'BasicAuth'")
+	}
+	if len(cfg.PasswordSource) != 0 && cfg.PasswordSource != passwordSourceConfig {
+		return errors.New("password_env and password_file can only be used with password_source : 'config'")
+	}
+	if len(cfg.Password) != 0 || len(cfg.PasswordType) != 0 || len(cfg.EncryptSecretPath) != 0 {
+		return errors.New("password, password_type and encrypt_secret_path should be empty with password_env or password_file")
+	}
+	return nil
+}
+
+//passwordConnector opens every connection of the database with a new connection string, which is built with the current password of the password provider
+//or a new IAM authentication token
+type passwordConnector struct {
+	driver  sqldriver.Driver
+	connStr func(ctx context.Context) (string, error)
+}
+
+var _ sqldriver.Connector = (*passwordConnector)(nil)
+
+func (c *passwordConnector) Connect(ctx context.Context) (sqldriver.Conn, error) {
+	connStr, err := c.connStr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if driverCtx, ok := c.driver.(sqldriver.DriverContext); ok {
+		connector, err := driverCtx.OpenConnector(connStr)
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return c.driver.Open(connStr)
+}
+
+func (c *passwordConnector) Driver() sqldriver.Driver {
+	return c.driver
+}
+
+//This function returns the connection string built by the driver with the current password of the password provider
+func passwordConnectionString(d driver, conf *Config, password passwordProvider, loadAWSConfig awsConfigLoader, logger *zap.Logger) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		current, err := password.get(ctx)
+		if err != nil {
+			return "", err
+		}
+		//The provided password is plaintext, it's neither encrypted nor logged in the encrypted form
+		passwordConf := *conf
+		passwordConf.Password = current
+		passwordConf.PasswordType = ""
+		passwordConf.EncryptSecretPath = ""
+		return d.connectionString(&passwordConf, loadAWSConfig, logger)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	// registers the postgres driver
+	_ "github.com/lib/pq"
+)
+
+const (
+	//dbDriverPostgres connects to PostgreSQL and compatible servers, e.g. Amazon Aurora PostgreSQL
+	dbDriverPostgres = "postgres"
+	//defaultPostgresPort is the port of the PostgreSQL server used when dbport is not set
+	defaultPostgresPort = "5432"
+)
+
+func init() {
+	registerDriver(dbDriverPostgres, postgreSQLDriver{})
+}
+
+type postgreSQLDriver struct {
+	baseDriver
+}
+
+var _ iamAuthDriver = postgreSQLDriver{}
+
+//There are 3 scenarios here for creating connection strings for a PostgreSQL database connection
+//1. With a plaintext password
+//2. With an encrypted plaintext password
+//3. With an AWS Authentication token to be used as a password, the server certificate is verified with the AWS certificates
+func (postgreSQLDriver) connectionString(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) (string, error) {
+	port := conf.DBPort
+	if len(port) == 0 {
+		port = defaultPostgresPort
+	}
+	endpoint := net.JoinHostPort(conf.DBHost, port)
+	query := url.Values{}
+	var password string
+	if conf.AuthenticationMode == "IAMRDSAuth" {
+		//The authentication token is set by withIAMAuthToken for every new connection
+		query.Set("sslmode", "verify-full")
+		query.Set("sslrootcert", conf.AWSCertificatePath)
+	} else {
+		password = basicAuthPassword(conf, logger)
+	}
+	//Setting the session to read only on every new connection, so that the configured queries cannot modify any data
+	if conf.ReadOnlySession {
+		query.Set("default_transaction_read_only", "on")
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(conf.Username, password),
+		Host:     endpoint,
+		Path:     "/" + conf.Database,
+		RawQuery: query.Encode(),
+	}
+	return u.String(), nil
+}
+
+func (postgreSQLDriver) withIAMAuthToken(ctx context.Context, connStr string, conf *Config, loadAWSConfig awsConfigLoader) (string, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return "", err
+	}
+	token, err := generateIAMAuthToken(ctx, u.Host, conf, loadAWSConfig)
+	if err != nil {
+		return "", err
+	}
+	u.User = url.UserPassword(conf.Username, token)
+	return u.String(), nil
+}
+
+func (postgreSQLDriver) dbSystem() string {
+	return "postgresql"
+}
+
+func (postgreSQLDriver) placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+//The unix socket authentication, the proxy dialer and the diagnostics rely on features of the MySQL server and driver
+func (postgreSQLDriver) validate(cfg *Config) error {
+	return multierr.Combine(
+		validateAuthenticationMode(cfg, "BasicAuth", "IAMRDSAuth"),
+		validateMySQLOnlyOptions(cfg),
+	)
+}
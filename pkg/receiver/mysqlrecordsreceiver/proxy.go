@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+//proxyNetworkPrefix prefixes the names of the networks the proxy dialers are registered under with the driver
+const proxyNetworkPrefix = "socks5proxy_"
+
+//This function validates the proxy_url, SOCKS5 proxies and HTTP proxies supporting the CONNECT method are supported
+func validateProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("proxy_url is not a valid url: %w", err)
+	}
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" && u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("proxy_url should have either of 'socks5', 'socks5h', 'http' or 'https' scheme")
+	}
+	if len(u.Host) == 0 {
+		return errors.New("proxy_url should contain the proxy host")
+	}
+	return nil
+}
+
+//This function creates a dial function establishing the database connections through the SOCKS5 or HTTP proxy
+func newProxyDialContext(proxyURL string) (mysql.DialContextFunc, error) {
+	if err := validateProxyURL(proxyURL); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return newHTTPConnectDialContext(u), nil
+	}
+	dialer, err := proxy.FromURL(u, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("error in creating proxy dialer: %w", err)
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, errors.New("proxy dialer does not support contexts")
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return contextDialer.DialContext(ctx, "tcp", addr)
+	}, nil
+}
+
+//This function creates a dial function establishing the database connections through the tunnels of the HTTP proxy opened with the CONNECT method,
+//the connection to an 'https' proxy is encrypted, the credentials of the url are sent with the basic authentication
+func newHTTPConnectDialContext(u *url.URL) mysql.DialContextFunc {
+	proxyAddr := u.Host
+	if len(u.Port()) == 0 {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(u.Hostname(), port)
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "https" {
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("error in establishing TLS connection to proxy: %w", err)
+			}
+			conn = tlsConn
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+		connectReq := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: addr},
+			Host:   addr,
+			Header: make(http.Header),
+		}
+		if u.User != nil {
+			password, _ := u.User.Password()
+			credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+			connectReq.Header.Set("Proxy-Authorization", "Basic "+credentials)
+		}
+		if err := connectReq.Write(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error in sending CONNECT request to proxy: %w", err)
+		}
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, connectReq)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error in reading CONNECT response of proxy: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			conn.Close()
+			return nil, fmt.Errorf("proxy refused the connection to %s: %s", addr, resp.Status)
+		}
+		//The MySQL server sends its greeting first, which may already be buffered with the response of the proxy
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+}
+
+//bufferedConn reads the data buffered while reading the response of the proxy before the data of the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+//This function returns the proxy of the standard environment variables for the database address, or an empty string if it's connected directly
+//ALL_PROXY takes precedence over HTTPS_PROXY, the addresses matching NO_PROXY and the loopback addresses are connected directly
+func environmentProxyURL(addr string) (string, error) {
+	proxyConf := httpproxy.Config{
+		HTTPSProxy: getEnvAny("ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy"),
+		NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+	}
+	proxyURL, err := proxyConf.ProxyFunc()(&url.URL{Scheme: "https", Host: addr})
+	if err != nil || proxyURL == nil {
+		return "", err
+	}
+	return proxyURL.String(), nil
+}
+
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); len(value) != 0 {
+			return value
+		}
+	}
+	return ""
+}
+
+//This function registers the proxy dial function with the driver
+//It returns the name of the network the dial function is registered under, which is used as the network of the connection string
+func registerProxyDialer(conf *Config, proxyURL string) (string, error) {
+	dial, err := newProxyDialContext(proxyURL)
+	if err != nil {
+		return "", err
+	}
+	//The network name is a part of the connection string, so it cannot contain the '/' of the receiver id
+	network := proxyNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	mysql.RegisterDialContext(network, dial)
+	return network, nil
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const (
+	//queryIdAttribute is the id of the query which fetched the record
+	queryIdAttribute = "mysql.query_id"
+	//scrapeStartTimeAttribute is the time the collection of the records started, in RFC 3339 format
+	scrapeStartTimeAttribute = "mysql.scrape_start_time"
+	//queryDurationAttribute is the time it took to run the query and fetch its records, in milliseconds
+	queryDurationAttribute = "mysql.query_duration_ms"
+	//batchSequenceAttribute identifies the records fetched by a single run of a query
+	batchSequenceAttribute = "mysql.batch_sequence"
+)
+
+// queryRecord is a record fetched by a query, passed from the producers to the consumers.
+type queryRecord struct {
+	body string
+	//rows are the records of a run of a query in the order of the query result, they're emitted as log records of a single plog.Logs
+	//with record_per_row, the body is empty then
+	rows []string
+	//observedTime is the time the rows were fetched, it's zero without record_per_row
+	observedTime time.Time
+	//metadata is nil when query_metadata is disabled
+	metadata *queryMetadata
+	//columnTypes are the database types of the columns keyed by the column name, they're nil with record_format : 'json'
+	columnTypes map[string]string
+	//queryId is the id of the query which fetched the record, set as an attribute of the log records
+	queryId string
+	//attributes are the static attributes of the query which fetched the record
+	attributes map[string]string
+	//severity sets the severity of the log records by the severity_column of the query, it's nil if the query has no severity_column
+	severity *severityMapping
+	//timestamp sets the timestamp of the log records by the timestamp_column of the query, it's nil if the query has no timestamp_column
+	timestamp *timestampColumn
+	//snapshotOperation is the change of the row found by the snapshot_diff of the query, it's empty for the queries without snapshot_diff
+	snapshotOperation string
+}
+
+// queryMetadata describes the query execution which fetched a batch of records.
+type queryMetadata struct {
+	queryId         string
+	scrapeStartTime time.Time
+	queryDuration   time.Duration
+	batchSequence   int64
+}
+
+// setAttributes sets the query execution metadata as log record attributes.
+func (q *queryMetadata) setAttributes(attrs pcommon.Map) {
+	attrs.UpsertString(queryIdAttribute, q.queryId)
+	attrs.UpsertString(scrapeStartTimeAttribute, q.scrapeStartTime.UTC().Format(time.RFC3339Nano))
+	attrs.UpsertInt(queryDurationAttribute, q.queryDuration.Milliseconds())
+	attrs.UpsertInt(batchSequenceAttribute, q.batchSequence)
+}
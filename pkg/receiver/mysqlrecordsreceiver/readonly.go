@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// readOnlyStatements is the allowlist of statements which can be used in db_queries.
+var readOnlyStatements = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+}
+
+// forbiddenKeywords are keywords which modify data, schema, privileges or files, or take locks.
+// They are rejected anywhere in a query, e.g. in 'WITH ... UPDATE' or 'SELECT ... INTO OUTFILE', apart from the function calls of functionKeywords.
+var forbiddenKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"REPLACE":  true,
+	"MERGE":    true,
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+	"GRANT":    true,
+	"REVOKE":   true,
+	"LOCK":     true,
+	"UNLOCK":   true,
+	"CALL":     true,
+	"LOAD":     true,
+	"HANDLER":  true,
+	"OUTFILE":  true,
+	"DUMPFILE": true,
+}
+
+// functionKeywords are forbidden keywords which are also names of functions, e.g. REPLACE(str, from, to).
+// They are rejected only in statement or clause position, i.e. when they are not followed by an opening parenthesis.
+var functionKeywords = map[string]bool{
+	"INSERT":  true,
+	"REPLACE": true,
+	"LOAD":    true,
+	"LOCK":    true,
+}
+
+// queryToken is a word or a parenthesis of a query with its position in the query.
+type queryToken struct {
+	text       string
+	start, end int
+}
+
+// validateReadOnlyQuery parses the query and checks that it consists of a single read-only statement.
+// Comments, string literals and quoted identifiers are skipped, so keywords inside them are not taken into account.
+func validateReadOnlyQuery(query string) error {
+	return validateReadOnlyStatements(query, false, dbDriverMySQL)
+}
+
+// validateReadOnlyStatements parses the query and checks that all of its statements are read-only,
+// the query can consist of several statements only if multiStatement is set.
+// A top-level INTO clause is allowed only with MySQL, where it assigns user variables ('SELECT ... INTO @var'),
+// as 'SELECT ... INTO new_table' creates a table with the other databases.
+func validateReadOnlyStatements(query string, multiStatement bool, dbDriver string) error {
+	statements, err := scanQuery(query)
+	if err != nil {
+		return err
+	}
+
+	var nonEmpty [][]queryToken
+	for _, statement := range statements {
+		if len(queryWords(statement)) > 0 {
+			nonEmpty = append(nonEmpty, statement)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return errors.New("query is empty")
+	}
+	if len(nonEmpty) > 1 && !multiStatement {
+		return errors.New("query contains multiple statements")
+	}
+
+	for _, tokens := range nonEmpty {
+		if first := queryWords(tokens)[0]; !readOnlyStatements[first] {
+			return fmt.Errorf("statement %s is not allowed, only SELECT, WITH, SHOW, DESCRIBE and EXPLAIN statements are allowed", first)
+		}
+		depth := 0
+		for i, token := range tokens {
+			switch token.text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case "INTO":
+				if depth == 0 && dbDriver != dbDriverMySQL {
+					return fmt.Errorf("keyword INTO is not allowed in a read-only query with db_driver : '%s'", dbDriver)
+				}
+			}
+			if !forbiddenKeywords[token.text] {
+				continue
+			}
+			if functionKeywords[token.text] && i+1 < len(tokens) && tokens[i+1].text == "(" {
+				continue
+			}
+			return fmt.Errorf("keyword %s is not allowed in a read-only query", token.text)
+		}
+	}
+	return nil
+}
+
+// tokenizeQuery splits the query into statements, each being a list of upper-cased words.
+func tokenizeQuery(query string) ([][]string, error) {
+	statements, err := scanQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	words := make([][]string, 0, len(statements))
+	for _, statement := range statements {
+		words = append(words, queryWords(statement))
+	}
+	return words, nil
+}
+
+// queryWords returns the words of the tokens of a statement.
+func queryWords(tokens []queryToken) []string {
+	var words []string
+	for _, token := range tokens {
+		if token.text != "(" && token.text != ")" {
+			words = append(words, token.text)
+		}
+	}
+	return words
+}
+
+// scanQuery splits the query into statements, each being a list of upper-cased words and parentheses.
+func scanQuery(query string) ([][]queryToken, error) {
+	statements := [][]queryToken{nil}
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ';':
+			statements = append(statements, nil)
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "--") && (i+2 == len(query) || query[i+2] <= ' ')):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return statements, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*!"):
+			// MySQL executable comments are executed by the server, so their content is parsed as part of the query.
+			i += 3
+			for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+		case strings.HasPrefix(query[i:], "*/"):
+			// End of an executable comment.
+			i += 2
+		case c == '\'' || c == '"' || c == '`':
+			end, err := skipQuoted(query, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case isWordChar(c):
+			start := i
+			for i < len(query) && isWordChar(query[i]) {
+				i++
+			}
+			last := len(statements) - 1
+			statements[last] = append(statements[last], queryToken{text: strings.ToUpper(query[start:i]), start: start, end: i})
+		case c == '(' || c == ')':
+			last := len(statements) - 1
+			statements[last] = append(statements[last], queryToken{text: string(c), start: i, end: i + 1})
+			i++
+		default:
+			i++
+		}
+	}
+	return statements, nil
+}
+
+// skipQuoted returns the position right after the quoted literal or identifier starting at start.
+// Quotes are escaped by doubling them, backslash escapes are supported in string literals.
+func skipQuoted(query string, start int) (int, error) {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string starting at position %d", start)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
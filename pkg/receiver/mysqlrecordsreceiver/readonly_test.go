@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReadOnlyQueryAllowed(t *testing.T) {
+	queries := []string{
+		"select * from persons",
+		"SELECT * FROM persons;",
+		"Show tables",
+		"describe persons",
+		"EXPLAIN SELECT * FROM persons",
+		"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent",
+		"(SELECT id FROM a) UNION (SELECT id FROM b)",
+		"select * from persons where name = 'DROP TABLE persons; --'",
+		"select * from persons where name = \"it\\'s; update\"",
+		"select `update`, created_at from persons",
+		"select * from persons -- delete everything\nwhere id > 5",
+		"select * from persons # insert\nwhere id > 5",
+		"select /* truncate; */ * from persons",
+		"select 5--3 from dual",
+		"SELECT REPLACE(name, 'a', 'b') FROM persons",
+		"select insert(name, 1, 2, 'x') from persons",
+		"SELECT id FROM persons WHERE REPLACE(name, ' ', '') = 'ab'",
+	}
+	for _, query := range queries {
+		require.NoError(t, validateReadOnlyQuery(query), query)
+	}
+}
+
+func TestValidateReadOnlyQueryRejected(t *testing.T) {
+	queries := []string{
+		"",
+		"  ;  ",
+		"insert into persons values (1)",
+		"UPDATE persons SET name = 'x'",
+		"delete from persons",
+		"drop table persons",
+		"truncate persons",
+		"call cleanup()",
+		"select * from persons; delete from persons",
+		"select * from persons for update",
+		"select * from persons lock in share mode",
+		"select * into outfile '/tmp/persons' from persons",
+		"WITH ids AS (SELECT id FROM persons) DELETE FROM persons WHERE id IN (SELECT id FROM ids)",
+		"select * from persons /*!50000 ; delete from persons */",
+		"/* comment */ set global read_only = 0",
+		"select * from persons where name = 'unterminated",
+		"select * from persons /* unterminated",
+		"replace into persons values (1)",
+		"insert into persons (id) values (1)",
+		"WITH ids AS (SELECT id FROM persons) REPLACE INTO archive SELECT id FROM ids",
+		"load data infile '/tmp/persons' into table persons",
+		"lock tables persons read",
+	}
+	for _, query := range queries {
+		require.Error(t, validateReadOnlyQuery(query), query)
+	}
+}
+
+func TestValidateReadOnlyStatements(t *testing.T) {
+	require.NoError(t, validateReadOnlyStatements("select @total := count(*) from persons; select * from persons where age > @total", true, dbDriverMySQL))
+	require.NoError(t, validateReadOnlyStatements("select * from persons; show tables;", true, dbDriverMySQL))
+	require.Error(t, validateReadOnlyStatements("select * from persons; select * from orders", false, dbDriverMySQL))
+	require.Error(t, validateReadOnlyStatements("select * from persons; delete from persons", true, dbDriverMySQL))
+	require.Error(t, validateReadOnlyStatements("set @total = 1; select * from persons", true, dbDriverMySQL))
+	require.Error(t, validateReadOnlyStatements("select * from persons; select * from orders for update", true, dbDriverMySQL))
+}
+
+func TestValidateReadOnlyStatementsWInto(t *testing.T) {
+	require.NoError(t, validateReadOnlyStatements("select count(*) into @total from persons; select * from persons where age > @total", true, dbDriverMySQL))
+	require.NoError(t, validateReadOnlyStatements("select id, name from persons into @id, @name", false, dbDriverMySQL))
+	require.Error(t, validateReadOnlyStatements("select * into outfile '/tmp/persons' from persons", false, dbDriverMySQL))
+
+	for _, dbDriver := range []string{dbDriverPostgres, dbDriverSQLServer, dbDriverOracle} {
+		require.Error(t, validateReadOnlyStatements("select * into archive from persons", false, dbDriver), dbDriver)
+		require.Error(t, validateReadOnlyStatements("SELECT id INTO TEMP recent FROM orders", false, dbDriver), dbDriver)
+		require.Error(t, validateReadOnlyStatements("WITH recent AS (SELECT * FROM orders) SELECT * INTO archive FROM recent", false, dbDriver), dbDriver)
+		require.NoError(t, validateReadOnlyStatements("select * from persons where name = 'into'", false, dbDriver), dbDriver)
+		require.NoError(t, validateReadOnlyStatements("select \"into\" from persons", false, dbDriver), dbDriver)
+	}
+}
@@ -15,10 +15,23 @@ package mysqlrecordsreceiver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/workloadidentityextension"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 )
@@ -28,44 +41,202 @@ type mySQLReceiver struct {
 	logger    *zap.Logger
 	config    *Config
 	consumer  consumer.Logs
+	// nextMetrics receives the metrics of the queries with metric_name, it's only set for the receiver of a metrics pipeline,
+	// which has no logs consumer
+	nextMetrics consumer.Metrics
+	// metricsConsumer receives the freshness metrics, it's nil when freshness_metrics_exporter is not set
+	metricsConsumer consumer.Metrics
+	// lastDeadlockTimestamp is the timestamp of the last deadlock passed to the consumer
+	lastDeadlockTimestamp string
+	// batchSequence is incremented for every run of a query
+	batchSequence int64
+	// lease hands the in-memory state off to the next instance of the receiver on config reload
+	lease *reloadorchestratorextension.Lease
+	// stopCollectors stops the periodic collection of the queries with collection_interval
+	stopCollectors context.CancelFunc
+	// collectors are the goroutines collecting the queries with collection_interval and reading the binlog
+	collectors sync.WaitGroup
+	// stopBinlogCDC stops reading the binlog, it's nil when binlog_cdc is not set
+	stopBinlogCDC context.CancelFunc
+	// obsrecv reports the received records with the receiver telemetry, it's nil for the receivers which are not created by the factory
+	obsrecv *obsreport.Receiver
+	// createSettings are the settings the receiver was created with, used for the telemetry of the scrapes
+	createSettings component.ReceiverCreateSettings
+	// scrapers report the runs of the queries of the metrics receiver, keyed by the query id
+	scrapers     map[string]*obsreport.Scraper
+	scrapersLock sync.Mutex
+	// target is the name of the target the receiver runs the queries against, it's empty when the receiver has no targets
+	target string
 }
 
-func newMySQLReceiver(logger *zap.Logger, conf *Config, next consumer.Logs) (component.LogsReceiver, error) {
+// handoffState is the state handed off on config reload.
+// The states of the queries aren't part of it, as they are saved in the state files right away.
+type handoffState struct {
+	LastDeadlockTimestamp string `json:"last_deadlock_timestamp"`
+	BatchSequence         int64  `json:"batch_sequence"`
+}
 
-	return &mySQLReceiver{
-		consumer: next,
-		logger:   logger,
-		config:   conf,
-	}, nil
+func newMySQLReceiver(params component.ReceiverCreateSettings, conf *Config, next consumer.Logs) (component.LogsReceiver, error) {
+	newReceiver := func(conf *Config) *mySQLReceiver {
+		return &mySQLReceiver{
+			consumer:       next,
+			logger:         params.Logger,
+			config:         conf,
+			obsrecv:        newObsReceiver(params, conf),
+			createSettings: params,
+		}
+	}
+	if len(conf.Targets) != 0 {
+		return newTargetsReceiver(params, conf, newReceiver), nil
+	}
+	return newReceiver(conf), nil
+}
+
+func newMySQLMetricsReceiver(params component.ReceiverCreateSettings, conf *Config, next consumer.Metrics) (component.MetricsReceiver, error) {
+	newReceiver := func(conf *Config) *mySQLReceiver {
+		return &mySQLReceiver{
+			nextMetrics:    next,
+			logger:         params.Logger,
+			config:         conf,
+			obsrecv:        newObsReceiver(params, conf),
+			createSettings: params,
+		}
+	}
+	if len(conf.Targets) != 0 {
+		return newTargetsReceiver(params, conf, newReceiver), nil
+	}
+	return newReceiver(conf), nil
 }
 
 //Produce is used for fetching queries from a channel of queries, using them for extrtacting records for those queries and then pushing those records in channel of records
-func (m *mySQLReceiver) produce(records chan<- string, id int, wg *sync.WaitGroup, queryChan <-chan DBQueries) {
+func (m *mySQLReceiver) produce(records chan<- queryRecord, id int, wg *sync.WaitGroup, queryChan <-chan DBQueries, scrapeStartTime time.Time, ctx context.Context) {
 	defer wg.Done()
 	var recordcount int
 	for query := range queryChan {
-		channelData, err := m.sqlclient.getRecords(&query)
-		if err != nil {
-			m.logger.Error("Failed to fetch records", zap.Error(err))
-		} else {
-			for _, msg := range channelData {
-				recordcount++
-				records <- msg
+		var state string
+		for {
+			fetched := m.produceQuery(records, &query, scrapeStartTime, ctx)
+			recordcount += fetched
+			//A full chunk of a query with max_rows_per_fetch is followed by the next chunk, as its state was saved in between
+			if query.MaxRowsPerFetch == 0 || fetched < query.MaxRowsPerFetch || ctx.Err() != nil {
+				break
+			}
+			//The next chunk would contain the same records again if the state was not saved
+			if nextState := GetState(&query, m.logger); nextState != state {
+				state = nextState
+			} else {
+				break
 			}
 		}
 	}
 	m.logger.Info("Total records extracted and produced:", zap.Int("count", recordcount))
 }
 
+//This function runs a query once and pushes its records in the channel of records, it returns the number of fetched records
+//The records of a streamed query are pushed in batches while its rows are scanned, each batch with its own query metadata
+func (m *mySQLReceiver) produceQuery(records chan<- queryRecord, query *DBQueries, scrapeStartTime time.Time, ctx context.Context) int {
+	ctx, endScrape := m.startScrape(ctx, query.QueryId)
+	queryStartTime := time.Now()
+	channelData, metadata, fetched, err := m.fetchQuery(records, query, scrapeStartTime, ctx)
+	//The query is run again once the database is reachable, unless records of the streamed query were already produced
+	if isConnectionError(err) && fetched == 0 && ctx.Err() == nil {
+		m.logger.Warn("Lost connection to database, reconnecting", zap.String("queryId", query.QueryId), zap.Error(err))
+		if reconnectErr := m.waitForDatabase(ctx); reconnectErr != nil {
+			m.logger.Error("Failed to reconnect to database", zap.String("queryId", query.QueryId), zap.Error(reconnectErr))
+		} else {
+			queryStartTime = time.Now()
+			channelData, metadata, fetched, err = m.fetchQuery(records, query, scrapeStartTime, ctx)
+		}
+	}
+	endScrape(fetched, err)
+	if err == nil {
+		m.recordQueryStats(query, time.Since(queryStartTime), fetched, time.Now())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		m.logger.Warn("Query exceeded its query_timeout, skipping it until the next collection", zap.String("queryId", query.QueryId), zap.Error(err))
+		return 0
+	} else if err != nil {
+		m.logger.Error("Failed to fetch records", zap.String("queryId", query.QueryId), zap.Error(err))
+		return 0
+	}
+	if m.config.SchemaRecords.Enabled && m.consumer != nil {
+		m.collectSchema(ctx, query.QueryId)
+	}
+	if len(query.ExpectedFreshness) != 0 {
+		m.checkFreshness(ctx, query, fetched, time.Now())
+	}
+	if !m.isStreamedQuery() {
+		m.produceRecords(records, query, channelData, metadata, ctx)
+	}
+	return fetched
+}
+
+// fetchQuery runs a query once, the records of a streamed query are produced in batches while the others are returned with their query metadata.
+func (m *mySQLReceiver) fetchQuery(records chan<- queryRecord, query *DBQueries, scrapeStartTime time.Time, ctx context.Context) (map[string]string, *queryMetadata, int, error) {
+	queryStartTime := time.Now()
+	if m.isStreamedQuery() {
+		fetched, err := streamRecords(ctx, m.sqlclient, query, m.config.FetchBatchSize, m.logger, func(batch map[string]string) {
+			m.produceRecords(records, query, batch, m.queryMetadata(query.QueryId, scrapeStartTime, time.Since(queryStartTime)), ctx)
+		})
+		return nil, nil, fetched, err
+	}
+	channelData, err := getRecords(ctx, m.sqlclient, query, m.logger)
+	return channelData, m.queryMetadata(query.QueryId, scrapeStartTime, time.Since(queryStartTime)), len(channelData), err
+}
+
+//This function returns whether the records of the queries are pushed in batches while their rows are scanned, the metrics
+//and the records of 'per_scrape_array' emit mode are built from the entire result set
+func (m *mySQLReceiver) isStreamedQuery() bool {
+	return m.config.FetchBatchSize > 0 && m.nextMetrics == nil && m.config.EmitMode != emitModePerScrapeArray
+}
+
+//This function pushes the records fetched by a query in the channel of records, or passes them to the metrics consumer
+func (m *mySQLReceiver) produceRecords(records chan<- queryRecord, query *DBQueries, channelData map[string]string, metadata *queryMetadata, ctx context.Context) {
+	if m.nextMetrics != nil {
+		m.consumeQueryMetrics(ctx, query, channelData, metadata)
+	} else if m.config.EmitMode == emitModePerScrapeArray {
+		for _, msg := range buildRecordArrays(channelData, m.config.MaxArrayRecordSize) {
+			records <- queryRecord{body: msg, metadata: metadata, queryId: query.QueryId, attributes: query.Attributes}
+		}
+	} else {
+		var columnTypes map[string]string
+		if m.isStructuredRecordFormat() {
+			columnTypes = m.queryColumnTypes(query.QueryId)
+		}
+		severity := newSeverityMapping(query)
+		timestamp := newTimestampColumn(query)
+		if m.config.RecordPerRow {
+			if len(channelData) != 0 {
+				records <- queryRecord{rows: sortedRecords(channelData), observedTime: time.Now(), metadata: metadata, columnTypes: columnTypes, queryId: query.QueryId, attributes: query.Attributes, severity: severity, timestamp: timestamp}
+			}
+		} else {
+			for key, msg := range channelData {
+				record := queryRecord{body: msg, metadata: metadata, columnTypes: columnTypes, queryId: query.QueryId, attributes: query.Attributes, severity: severity, timestamp: timestamp}
+				if query.SnapshotDiff {
+					record.snapshotOperation = snapshotOperation(key)
+				}
+				records <- record
+			}
+		}
+	}
+}
+
 //Consume is used for fetching each record from the records channel, converting them into plog.Logs type
 //The record is passed into the body tag and then the comsumer of the LogsReceiver consumes them
-func (m *mySQLReceiver) consume(records <-chan string, id int, wg *sync.WaitGroup, ctx context.Context) {
+func (m *mySQLReceiver) consume(records <-chan queryRecord, id int, wg *sync.WaitGroup, ctx context.Context) {
 	defer wg.Done()
 	var recordcount int
-	for msg := range records {
-		recordcount++
-		logs := m.convertToLog(msg)
-		err := m.consumer.ConsumeLogs(ctx, logs)
+	for record := range records {
+		logs, lrs := m.newLogs()
+		if record.rows == nil {
+			m.appendLogRecord(lrs, record.body, record)
+		} else {
+			for _, row := range record.rows {
+				m.appendLogRecord(lrs, row, record)
+			}
+		}
+		recordcount += lrs.Len()
+		err := m.consumeLogs(ctx, logs)
 		if err != nil {
 			m.logger.Error("Failed to consume records", zap.Error(err))
 		}
@@ -75,22 +246,79 @@ func (m *mySQLReceiver) consume(records <-chan string, id int, wg *sync.WaitGrou
 
 // start starts the receiver by initializing the db client connection.
 func (m *mySQLReceiver) Start(ctx context.Context, host component.Host) error {
-	sqlclient := newMySQLClient(m.config, m.logger)
-	err := sqlclient.Connect()
+	loadAWSConfig := awsConfigLoader(loadDefaultAWSConfig)
+	if m.config.WorkloadIdentity != nil {
+		provider, err := workloadidentityextension.GetCredentialsProvider(host, *m.config.WorkloadIdentity)
+		if err != nil {
+			return err
+		}
+		loadAWSConfig = provider.AWSConfig
+	}
+	//The handed off state belongs to the receiver of the logs pipeline
+	if m.config.ReloadOrchestrator != nil && m.consumer != nil {
+		if err := m.takeOverState(ctx, host, *m.config.ReloadOrchestrator); err != nil {
+			return err
+		}
+	}
+	if m.config.FreshnessMetricsExporter != nil {
+		metricsConsumer, err := lookUpFreshnessMetricsExporter(host, *m.config.FreshnessMetricsExporter)
+		if err != nil {
+			return err
+		}
+		m.metricsConsumer = metricsConsumer
+	}
+	sqlclient, err := newClient(m.config, loadAWSConfig, m.logger)
 	if err != nil {
 		return err
 	}
-	m.logger.Info("DB Connection successful")
+	//The client is set before connecting, so its dialers are closed by Shutdown when the connection fails
 	m.sqlclient = sqlclient
-	records := make(chan string)
+	err = sqlclient.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	//The database may still be starting, e.g. when it's deployed together with the collector
+	if err := m.waitForDatabaseOnStart(ctx); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	m.logger.Info("DB Connection successful")
+	var dbqueries []DBQueries
+	for _, dbquery := range m.config.DBQueries {
+		if m.isPipelineQuery(dbquery) {
+			dbqueries = append(dbqueries, dbquery)
+		}
+	}
+	if m.config.ValidateQueries {
+		if err := m.validateQueries(ctx, dbqueries); err != nil {
+			return err
+		}
+	}
+	m.collect(ctx, initialQueries(dbqueries))
+	m.startCollectors(dbqueries)
+	if m.nextMetrics != nil {
+		m.logger.Info("Records extracted, converted to metrics and consumed")
+		return nil
+	}
+	m.logger.Info("Records extracted, converted to logs and consumed")
+	m.collectDiagnostics(ctx)
+	if m.config.BinlogCDC != nil {
+		m.startBinlogCDC(loadAWSConfig)
+	}
+	return nil
+}
+
+//This function runs the queries with the database workers and passes their records to the consumer, it returns once all the records are consumed
+func (m *mySQLReceiver) collect(ctx context.Context, dbqueries []DBQueries) {
+	scrapeStartTime := time.Now()
+	records := make(chan queryRecord)
 	queryChan := make(chan DBQueries)
 	wp := &sync.WaitGroup{}
 	wc := &sync.WaitGroup{}
 	maxDBWorkers := 0
 	//Considering an ultimate maximum of 10 database workers
 	if m.config.SetMaxNoDatabaseWorkers == 0 {
-		if len(m.config.DBQueries) < 10 {
-			maxDBWorkers = len(m.config.DBQueries)
+		if len(dbqueries) < 10 {
+			maxDBWorkers = len(dbqueries)
 		} else {
 			maxDBWorkers = 10
 		}
@@ -104,35 +332,210 @@ func (m *mySQLReceiver) Start(ctx context.Context, host component.Host) error {
 	wp.Add(maxDBWorkers)
 	wc.Add(maxDBWorkers)
 	for i := 0; i < maxDBWorkers; i++ {
-		go m.produce(records, i, wp, queryChan)
+		go m.produce(records, i, wp, queryChan, scrapeStartTime, ctx)
 		go m.consume(records, i, wc, ctx)
 	}
-	for _, dbquery := range m.config.DBQueries {
+	for _, dbquery := range dbqueries {
 		queryChan <- dbquery
 	}
 	close(queryChan)
 	wp.Wait()
 	close(records)
 	wc.Wait()
-	m.logger.Info("Records extracted, converted to logs and consumed")
-	return nil
+}
+
+// queryMetadata returns the metadata of a query run, or nil when query_metadata is disabled.
+func (m *mySQLReceiver) queryMetadata(queryid string, scrapeStartTime time.Time, duration time.Duration) *queryMetadata {
+	if !m.config.QueryMetadata {
+		return nil
+	}
+	return &queryMetadata{
+		queryId:         queryid,
+		scrapeStartTime: scrapeStartTime,
+		queryDuration:   duration,
+		batchSequence:   atomic.AddInt64(&m.batchSequence, 1),
+	}
 }
 
 //This function closes the db connection
 func (m *mySQLReceiver) Shutdown(context.Context) error {
-	defer m.sqlclient.Close()
+	if m.stopCollectors != nil {
+		m.stopCollectors()
+	}
+	if m.stopBinlogCDC != nil {
+		m.stopBinlogCDC()
+	}
+	m.collectors.Wait()
+	if m.lease != nil {
+		m.releaseState()
+	}
 	if m.sqlclient == nil {
 		return nil
 	}
+	return m.sqlclient.Close()
+}
+
+// takeOverState acquires the lease of the receiver from the reload orchestrator
+// and takes over the state handed off by the previous instance of the receiver.
+func (m *mySQLReceiver) takeOverState(ctx context.Context, host component.Host, id config.ComponentID) error {
+	orchestrator, err := reloadorchestratorextension.GetReloadOrchestrator(host, id)
+	if err != nil {
+		return err
+	}
+	m.lease, err = orchestrator.Acquire(ctx, component.KindReceiver, m.config.ID())
+	if err != nil {
+		return err
+	}
+	if m.lease.State() == nil {
+		return nil
+	}
+
+	var state handoffState
+	if err := json.Unmarshal(m.lease.State(), &state); err != nil {
+		return fmt.Errorf("failed to parse handed off state: %w", err)
+	}
+	m.lastDeadlockTimestamp = state.LastDeadlockTimestamp
+	m.batchSequence = state.BatchSequence
+	m.logger.Info("Took over state from the previous instance", zap.Int64("batchSequence", state.BatchSequence))
 	return nil
 }
 
+func (m *mySQLReceiver) releaseState() {
+	state, err := json.Marshal(handoffState{
+		LastDeadlockTimestamp: m.lastDeadlockTimestamp,
+		BatchSequence:         atomic.LoadInt64(&m.batchSequence),
+	})
+	if err != nil {
+		m.logger.Error("Failed to convert state into json format", zap.Error(err))
+	}
+	m.lease.Release(state)
+}
+
 //This function generates a plog.Logs type log record for each record coming from a database query fetch
 func (m *mySQLReceiver) convertToLog(record string) plog.Logs {
+	ld, lrs := m.newLogs()
+	lrs.AppendEmpty().Body().SetStringVal(record)
+	return ld
+}
+
+// newLogs returns empty logs with the fields as resource attributes and the slice the log records are appended to.
+func (m *mySQLReceiver) newLogs() (plog.Logs, plog.LogRecordSlice) {
 	ld := plog.NewLogs()
 	rl := ld.ResourceLogs().AppendEmpty()
-	sl := rl.ScopeLogs().AppendEmpty()
-	lr := sl.LogRecords().AppendEmpty()
-	lr.Body().SetStringVal(record)
-	return ld
+	m.setResourceAttributes(rl.Resource().Attributes())
+	return ld, rl.ScopeLogs().AppendEmpty().LogRecords()
+}
+
+// appendLogRecord appends the log record of a database record fetched by a query.
+func (m *mySQLReceiver) appendLogRecord(lrs plog.LogRecordSlice, body string, record queryRecord) {
+	lr := lrs.AppendEmpty()
+	lr.Body().SetStringVal(body)
+	if !record.observedTime.IsZero() {
+		lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(record.observedTime))
+	}
+	if record.severity != nil {
+		m.setSeverity(lr, body, record.severity)
+	}
+	if record.timestamp != nil {
+		m.setTimestamp(lr, body, record.timestamp)
+	}
+	for key, value := range record.attributes {
+		lr.Attributes().UpsertString(key, value)
+	}
+	if record.columnTypes != nil {
+		m.setStructuredRecord(lr, body, record.columnTypes)
+	}
+	if len(record.queryId) != 0 {
+		lr.Attributes().UpsertString(queryIdAttribute, record.queryId)
+	}
+	if len(record.snapshotOperation) != 0 {
+		lr.Attributes().UpsertString(snapshotOperationAttribute, record.snapshotOperation)
+	}
+	if record.metadata != nil {
+		record.metadata.setAttributes(lr.Attributes())
+	}
+}
+
+// buildRecordArrays joins the database records fetched by a query into JSON arrays, keeping the order of the records.
+// An array is closed before it exceeds maxSize bytes, a record larger than maxSize is emitted in an array on its own.
+func buildRecordArrays(records map[string]string, maxSize int) []string {
+	if maxSize <= 0 {
+		maxSize = defaultMaxArrayRecordSize
+	}
+
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sortRecordKeys(keys)
+
+	var arrays []string
+	var array strings.Builder
+	for _, key := range keys {
+		record := records[key]
+		if array.Len() > 0 && array.Len()+len(record)+2 > maxSize {
+			array.WriteString("]")
+			arrays = append(arrays, array.String())
+			array.Reset()
+		}
+		if array.Len() == 0 {
+			array.WriteString("[")
+		} else {
+			array.WriteString(",")
+		}
+		array.WriteString(record)
+	}
+	if array.Len() > 0 {
+		array.WriteString("]")
+		arrays = append(arrays, array.String())
+	}
+	return arrays
+}
+
+// sortedRecords returns the records in the order of the records in the query result.
+func sortedRecords(records map[string]string) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sortRecordKeys(keys)
+	rows := make([]string, len(keys))
+	for i, key := range keys {
+		rows[i] = records[key]
+	}
+	return rows
+}
+
+// sortRecordKeys sorts the record keys in the order of the records in the query result.
+func sortRecordKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		if resultSetI, resultSetJ := resultSetNumber(keys[i]), resultSetNumber(keys[j]); resultSetI != resultSetJ {
+			return resultSetI < resultSetJ
+		}
+		return recordNumber(keys[i]) < recordNumber(keys[j])
+	})
+}
+
+// resultSetNumber returns the position of the result set of the record in the results of a multi statement query from its key,
+// i.e. <queryid>_result<number>_record<number>, the records of the first result set have no result set number in their keys.
+func resultSetNumber(key string) int {
+	end := strings.LastIndex(key, "_record")
+	start := strings.LastIndex(key[:end+1], "_result")
+	if start < 0 {
+		return 1
+	}
+	number, err := strconv.Atoi(key[start+len("_result") : end])
+	if err != nil {
+		return 1
+	}
+	return number
+}
+
+// recordNumber returns the position of the record in the query result from its key, i.e. <queryid>_record<number>.
+func recordNumber(key string) int {
+	number, err := strconv.Atoi(key[strings.LastIndex(key, "_record")+len("_record"):])
+	if err != nil {
+		return 0
+	}
+	return number
 }
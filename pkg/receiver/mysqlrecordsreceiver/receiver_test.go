@@ -0,0 +1,304 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestBuildRecordArrays(t *testing.T) {
+	records := map[string]string{
+		"Q1_record1":  `{"id":"1"}`,
+		"Q1_record2":  `{"id":"2"}`,
+		"Q1_record10": `{"id":"10"}`,
+		"Q1_record3":  `{"id":"3"}`,
+	}
+
+	arrays := buildRecordArrays(records, 1024)
+	require.Equal(t, []string{`[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"10"}]`}, arrays)
+
+	var rows []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(arrays[0]), &rows))
+	require.Len(t, rows, 4)
+}
+
+func TestBuildRecordArraysBoundedBySize(t *testing.T) {
+	records := map[string]string{
+		"Q1_record1": `{"id":"1"}`,
+		"Q1_record2": `{"id":"2"}`,
+		"Q1_record3": `{"id":"3"}`,
+	}
+
+	arrays := buildRecordArrays(records, 23)
+	require.Equal(t, []string{`[{"id":"1"},{"id":"2"}]`, `[{"id":"3"}]`}, arrays)
+	for _, array := range arrays {
+		require.LessOrEqual(t, len(array), 23)
+	}
+
+	// a record larger than the limit is emitted on its own
+	arrays = buildRecordArrays(records, 5)
+	require.Equal(t, []string{`[{"id":"1"}]`, `[{"id":"2"}]`, `[{"id":"3"}]`}, arrays)
+}
+
+func TestBuildRecordArraysNoRecords(t *testing.T) {
+	require.Empty(t, buildRecordArrays(map[string]string{}, 1024))
+}
+
+func TestConvertToLogWFields(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{Fields: map[string]string{"app": "billing", "team": "payments"}}}
+
+	logs := receiver.convertToLog(`{"id":"1"}`)
+	require.Equal(t, 1, logs.LogRecordCount())
+	attrs := logs.ResourceLogs().At(0).Resource().Attributes()
+	require.Equal(t, map[string]interface{}{"app": "billing", "team": "payments"}, attrs.AsRaw())
+	require.Equal(t, `{"id":"1"}`, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal())
+}
+
+func TestQueryMetadata(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{}}
+	scrapeStartTime := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	require.Nil(t, receiver.queryMetadata("Q1", scrapeStartTime, time.Second))
+
+	receiver.config.QueryMetadata = true
+	first := receiver.queryMetadata("Q1", scrapeStartTime, 1500*time.Millisecond)
+	second := receiver.queryMetadata("Q2", scrapeStartTime, 20*time.Millisecond)
+	require.Equal(t, &queryMetadata{queryId: "Q1", scrapeStartTime: scrapeStartTime, queryDuration: 1500 * time.Millisecond, batchSequence: 1}, first)
+	require.Equal(t, int64(2), second.batchSequence)
+}
+
+func TestProduceWMaxRowsPerFetch(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", MaxRowsPerFetch: 2}
+	defer os.Remove(getStateStoreFilename(&dbquery))
+	sqlclient := &mockClient{pages: [][]string{
+		{`{"OrderID":"1"}`, `{"OrderID":"2"}`},
+		{`{"OrderID":"3"}`, `{"OrderID":"4"}`},
+		{`{"OrderID":"5"}`},
+	}}
+	receiver := &mySQLReceiver{config: &Config{}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	records := make(chan queryRecord, 10)
+	queryChan := make(chan DBQueries, 1)
+	queryChan <- dbquery
+	close(queryChan)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.produce(records, 0, wg, queryChan, time.Now(), context.Background())
+	close(records)
+
+	var bodies []string
+	for record := range records {
+		bodies = append(bodies, record.body)
+	}
+	require.ElementsMatch(t, []string{`{"OrderID":"1"}`, `{"OrderID":"2"}`, `{"OrderID":"3"}`, `{"OrderID":"4"}`, `{"OrderID":"5"}`}, bodies)
+	require.Equal(t, [][]interface{}{{int64(0)}, {int64(2)}, {int64(4)}}, sqlclient.args)
+	require.Equal(t, "5", GetState(&dbquery, zap.NewNop()))
+}
+
+func TestProduceWMaxRowsPerFetchWUnchangedState(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders", IndexColumnName: "OrderID", IndexColumnType: "NUMBER", MaxRowsPerFetch: 2}
+	defer os.Remove(getStateStoreFilename(&dbquery))
+	sqlclient := &mockClient{records: []string{`{"OrderID":"1"}`, `{"OrderID":"2"}`}}
+	receiver := &mySQLReceiver{config: &Config{}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	records := make(chan queryRecord, 10)
+	queryChan := make(chan DBQueries, 1)
+	queryChan <- dbquery
+	close(queryChan)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.produce(records, 0, wg, queryChan, time.Now(), context.Background())
+	require.Len(t, sqlclient.queries, 2)
+}
+
+func TestProduceWFetchBatchSize(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders"}
+	sqlclient := &mockClient{records: []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`}}
+	receiver := &mySQLReceiver{config: &Config{FetchBatchSize: 2, RecordPerRow: true, QueryMetadata: true}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	records := make(chan queryRecord, 10)
+	queryChan := make(chan DBQueries, 1)
+	queryChan <- dbquery
+	close(queryChan)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.produce(records, 0, wg, queryChan, time.Now(), context.Background())
+	close(records)
+
+	var batches []queryRecord
+	for record := range records {
+		batches = append(batches, record)
+	}
+	require.Equal(t, []int{2}, sqlclient.batchSizes)
+	require.Len(t, batches, 2)
+	require.Equal(t, []string{`{"id":"1"}`, `{"id":"2"}`}, batches[0].rows)
+	require.Equal(t, []string{`{"id":"3"}`}, batches[1].rows)
+	require.Equal(t, int64(1), batches[0].metadata.batchSequence)
+	require.Equal(t, int64(2), batches[1].metadata.batchSequence)
+}
+
+func TestProduceWFetchBatchSizeInPerScrapeArrayMode(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders"}
+	sqlclient := &mockClient{records: []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`}}
+	receiver := &mySQLReceiver{config: &Config{FetchBatchSize: 2, EmitMode: emitModePerScrapeArray}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	records := make(chan queryRecord, 10)
+	queryChan := make(chan DBQueries, 1)
+	queryChan <- dbquery
+	close(queryChan)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.produce(records, 0, wg, queryChan, time.Now(), context.Background())
+	close(records)
+
+	require.Equal(t, []int{0}, sqlclient.batchSizes)
+	require.Equal(t, `[{"id":"1"},{"id":"2"},{"id":"3"}]`, (<-records).body)
+}
+
+func TestConsumeWQueryMetadata(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	receiver := &mySQLReceiver{config: &Config{}, consumer: sink, logger: zap.NewNop()}
+	metadata := &queryMetadata{
+		queryId:         "Q1",
+		scrapeStartTime: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC),
+		queryDuration:   1500 * time.Millisecond,
+		batchSequence:   7,
+	}
+
+	records := make(chan queryRecord, 2)
+	records <- queryRecord{body: `{"id":"1"}`, metadata: metadata}
+	records <- queryRecord{body: `{"id":"2"}`}
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	logs := sink.AllLogs()
+	require.Len(t, logs, 2)
+	require.Equal(t, map[string]interface{}{
+		"mysql.query_id":          "Q1",
+		"mysql.scrape_start_time": "2022-08-01T10:00:00Z",
+		"mysql.query_duration_ms": int64(1500),
+		"mysql.batch_sequence":    int64(7),
+	}, logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+	require.Equal(t, 0, logs[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Len())
+}
+
+func TestConsumeWQueryAttributes(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	receiver := &mySQLReceiver{config: &Config{}, consumer: sink, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", Attributes: map[string]string{"app": "billing", queryIdAttribute: "orders"}}
+
+	records := make(chan queryRecord, 2)
+	receiver.produceRecords(records, &dbquery, map[string]string{"Q1_record1": `{"id":"1"}`, "Q1_record2": `{"id":"2"}`}, nil, context.Background())
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	logs := sink.AllLogs()
+	require.Len(t, logs, 2)
+	for _, ld := range logs {
+		// the query id set by the receiver takes precedence over the static attributes
+		require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1", "app": "billing"}, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+	}
+}
+
+func TestSortedRecords(t *testing.T) {
+	records := map[string]string{
+		"Q1_record10": `{"id":"10"}`,
+		"Q1_record2":  `{"id":"2"}`,
+		"Q1_record1":  `{"id":"1"}`,
+	}
+	require.Equal(t, []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"10"}`}, sortedRecords(records))
+}
+
+func TestConsumeWRecordPerRow(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	receiver := &mySQLReceiver{config: &Config{RecordPerRow: true}, consumer: sink, logger: zap.NewNop()}
+	observedTime := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	records := make(chan queryRecord, 1)
+	records <- queryRecord{
+		rows:         []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`},
+		observedTime: observedTime,
+		metadata:     &queryMetadata{queryId: "Q1", batchSequence: 4},
+	}
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	// the rows of a run of a query are emitted together, in the order of the query result
+	logs := sink.AllLogs()
+	require.Len(t, logs, 1)
+	lrs := logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, lrs.Len())
+	for i := 0; i < lrs.Len(); i++ {
+		require.Equal(t, `{"id":"`+strconv.Itoa(i+1)+`"}`, lrs.At(i).Body().StringVal())
+		require.Equal(t, observedTime, lrs.At(i).ObservedTimestamp().AsTime())
+		require.Equal(t, int64(4), lrs.At(i).Attributes().AsRaw()[batchSequenceAttribute])
+	}
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestHandoffStateOnReload(t *testing.T) {
+	orchestratorID := config.NewComponentID("reload_orchestrator")
+	newHost := func() component.Host {
+		factory := reloadorchestratorextension.NewFactory()
+		orchestrator, err := factory.CreateExtension(context.Background(), componenttest.NewNopExtensionCreateSettings(), factory.CreateDefaultConfig())
+		require.NoError(t, err)
+		return extensionsHost{
+			Host:       componenttest.NewNopHost(),
+			extensions: map[config.ComponentID]component.Extension{orchestratorID: orchestrator},
+		}
+	}
+	cfg := &Config{ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "handoff"))}
+
+	before := &mySQLReceiver{config: cfg, logger: zap.NewNop()}
+	require.NoError(t, before.takeOverState(context.Background(), newHost(), orchestratorID))
+	require.Empty(t, before.lastDeadlockTimestamp)
+	before.lastDeadlockTimestamp = "2022-08-01 10:00:00 0x7f"
+	before.batchSequence = 12
+	before.releaseState()
+
+	after := &mySQLReceiver{config: cfg, logger: zap.NewNop()}
+	require.NoError(t, after.takeOverState(context.Background(), newHost(), orchestratorID))
+	require.Equal(t, "2022-08-01 10:00:00 0x7f", after.lastDeadlockTimestamp)
+	require.Equal(t, int64(12), after.batchSequence)
+
+	// the orchestrator has to be present in the host's extensions
+	require.Error(t, after.takeOverState(context.Background(), componenttest.NewNopHost(), orchestratorID))
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultReconnectInitialInterval is the wait before the first retry when initial_interval is not set
+	defaultReconnectInitialInterval = time.Second
+	// defaultReconnectMaxInterval is the upper bound of the wait between the retries when max_interval is not set
+	defaultReconnectMaxInterval = 30 * time.Second
+	// defaultReconnectMaxElapsedTime is the time after which the retries are given up when max_elapsed_time is not set
+	defaultReconnectMaxElapsedTime = 5 * time.Minute
+)
+
+// validateReconnect validates the durations of the reconnect settings, max_elapsed_time can be 0 for the queries to retry until the receiver is shut down.
+func validateReconnect(reconnect Reconnect) error {
+	var err error
+	for name, value := range map[string]string{
+		"initial_interval": reconnect.InitialInterval,
+		"max_interval":     reconnect.MaxInterval,
+		"max_elapsed_time": reconnect.MaxElapsedTime,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		duration, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			err = fmt.Errorf("reconnect %s is invalid: %w", name, parseErr)
+		} else if duration < 0 || (duration == 0 && name != "max_elapsed_time") {
+			err = fmt.Errorf("reconnect %s should be positive", name)
+		}
+	}
+	return err
+}
+
+// newBackOff returns the exponential backoff of the reconnect settings, the defaults are used for the settings which are not set.
+func (r Reconnect) newBackOff() *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = parseDurationOr(r.InitialInterval, defaultReconnectInitialInterval)
+	b.MaxInterval = parseDurationOr(r.MaxInterval, defaultReconnectMaxInterval)
+	b.MaxElapsedTime = parseDurationOr(r.MaxElapsedTime, defaultReconnectMaxElapsedTime)
+	b.Reset()
+	return b
+}
+
+// newStartBackOff returns the backoff of the wait for the database when the receiver starts, it's the backoff of the reconnect settings
+// with the default max_elapsed_time when max_elapsed_time is 0, so that the receiver doesn't block the startup of the collector forever.
+func (r Reconnect) newStartBackOff() *backoff.ExponentialBackOff {
+	b := r.newBackOff()
+	if b.MaxElapsedTime == 0 {
+		b.MaxElapsedTime = defaultReconnectMaxElapsedTime
+	}
+	return b
+}
+
+func parseDurationOr(value string, defaultDuration time.Duration) time.Duration {
+	if len(value) == 0 {
+		return defaultDuration
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultDuration
+	}
+	return duration
+}
+
+// isConnectionError returns whether the error is caused by the connection to the database, e.g. a database which is unreachable or restarting,
+// the errors reported by the database, e.g. of a denied access or an invalid query, are not.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, sqldriver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// waitForDatabase pings the database until it's reachable, with the exponential backoff of the reconnect settings between the pings.
+// It gives up on the errors which are not connection errors right away, and on the connection errors once max_elapsed_time passed.
+func (m *mySQLReceiver) waitForDatabase(ctx context.Context) error {
+	return m.pingWithBackOff(ctx, m.config.Reconnect.newBackOff())
+}
+
+// waitForDatabaseOnStart waits for the database like waitForDatabase when the receiver starts, with the backoff of newStartBackOff.
+func (m *mySQLReceiver) waitForDatabaseOnStart(ctx context.Context) error {
+	return m.pingWithBackOff(ctx, m.config.Reconnect.newStartBackOff())
+}
+
+func (m *mySQLReceiver) pingWithBackOff(ctx context.Context, exponential *backoff.ExponentialBackOff) error {
+	b := backoff.WithContext(exponential, ctx)
+	return backoff.RetryNotify(func() error {
+		err := m.sqlclient.Ping(ctx)
+		if err != nil && !isConnectionError(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, b, func(err error, wait time.Duration) {
+		m.logger.Warn("Database is unavailable, retrying", zap.Duration("retryIn", wait), zap.Error(err))
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testReconnect = Reconnect{InitialInterval: "1ms", MaxInterval: "5ms", MaxElapsedTime: "1s"}
+
+func TestWaitForDatabase(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	sqlclient := &mockClient{pingErrs: []error{refused, sqldriver.ErrBadConn, nil}}
+	receiver := &mySQLReceiver{config: &Config{Reconnect: testReconnect}, logger: zap.NewNop(), sqlclient: sqlclient}
+	require.NoError(t, receiver.waitForDatabase(context.Background()))
+	require.Equal(t, 3, sqlclient.pings)
+
+	// the errors reported by the database are not retried
+	sqlclient = &mockClient{pingErrs: []error{&mysql.MySQLError{Number: 1045, Message: "Access denied for user 'mysqluser'"}}}
+	receiver.sqlclient = sqlclient
+	require.Error(t, receiver.waitForDatabase(context.Background()))
+	require.Equal(t, 1, sqlclient.pings)
+
+	// the retries are given up once max_elapsed_time passed
+	sqlclient = &mockClient{pingErrs: []error{refused}}
+	receiver.sqlclient = sqlclient
+	receiver.config.Reconnect.MaxElapsedTime = "20ms"
+	require.ErrorIs(t, receiver.waitForDatabase(context.Background()), refused)
+	require.Greater(t, sqlclient.pings, 1)
+}
+
+func TestNewStartBackOff(t *testing.T) {
+	// the wait when the receiver starts is limited even if the retries of the queries don't stop
+	b := Reconnect{MaxElapsedTime: "0"}.newStartBackOff()
+	require.Equal(t, defaultReconnectMaxElapsedTime, b.MaxElapsedTime)
+	require.Equal(t, time.Duration(0), Reconnect{MaxElapsedTime: "0"}.newBackOff().MaxElapsedTime)
+
+	b = Reconnect{MaxElapsedTime: "1m"}.newStartBackOff()
+	require.Equal(t, time.Minute, b.MaxElapsedTime)
+}
+
+func TestProduceWReconnect(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders"}
+	sqlclient := &mockClient{
+		records:   []string{`{"OrderID":"1"}`},
+		queryErrs: []error{mysql.ErrInvalidConn},
+		pingErrs:  []error{sqldriver.ErrBadConn, nil},
+	}
+	receiver := &mySQLReceiver{config: &Config{Reconnect: testReconnect}, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	records := make(chan queryRecord, 10)
+	queryChan := make(chan DBQueries, 1)
+	queryChan <- dbquery
+	close(queryChan)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.produce(records, 0, wg, queryChan, time.Now(), context.Background())
+	close(records)
+
+	var bodies []string
+	for record := range records {
+		bodies = append(bodies, record.body)
+	}
+	require.Equal(t, []string{`{"OrderID":"1"}`}, bodies)
+	require.Len(t, sqlclient.queries, 2)
+	require.Equal(t, 2, sqlclient.pings)
+}
+
+func TestProduceWQueryErrorWOReconnect(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from orders"}
+	sqlclient := &mockClient{err: &mysql.MySQLError{Number: 1146, Message: "Table 'app.orders' doesn't exist"}}
+	receiver := &mySQLReceiver{config: &Config{Reconnect: testReconnect}, logger: zap.NewNop(), sqlclient: sqlclient}
+	require.Zero(t, receiver.produceQuery(make(chan queryRecord, 10), &dbquery, time.Now(), context.Background()))
+	require.Len(t, sqlclient.queries, 1)
+	require.Zero(t, sqlclient.pings)
+}
+
+func TestValidateReconnect(t *testing.T) {
+	require.NoError(t, validateReconnect(Reconnect{}))
+	require.NoError(t, validateReconnect(Reconnect{InitialInterval: "500ms", MaxInterval: "1m", MaxElapsedTime: "0"}))
+	require.Error(t, validateReconnect(Reconnect{InitialInterval: "0s"}))
+	require.Error(t, validateReconnect(Reconnect{MaxInterval: "-1s"}))
+	require.Error(t, validateReconnect(Reconnect{MaxElapsedTime: "five minutes"}))
+}
+
+func TestReconnectBackOff(t *testing.T) {
+	b := Reconnect{}.newBackOff()
+	require.Equal(t, defaultReconnectInitialInterval, b.InitialInterval)
+	require.Equal(t, defaultReconnectMaxInterval, b.MaxInterval)
+	require.Equal(t, defaultReconnectMaxElapsedTime, b.MaxElapsedTime)
+
+	b = Reconnect{InitialInterval: "2s", MaxInterval: "1m", MaxElapsedTime: "0"}.newBackOff()
+	require.Equal(t, 2*time.Second, b.InitialInterval)
+	require.Equal(t, time.Minute, b.MaxInterval)
+	require.Zero(t, b.MaxElapsedTime)
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	//recordFormatJSON emits the record as a JSON string body
+	recordFormatJSON = "json"
+	//recordFormatMap emits the record as a map body with a typed value for each column
+	recordFormatMap = "map"
+	//recordFormatAttributes emits the record as log record attributes with a typed value for each column, the body is empty
+	recordFormatAttributes = "attributes"
+)
+
+func (m *mySQLReceiver) isStructuredRecordFormat() bool {
+	return m.config.RecordFormat == recordFormatMap || m.config.RecordFormat == recordFormatAttributes
+}
+
+// queryColumnTypes returns the database types of the columns of the last result of the query, keyed by the column name.
+func (m *mySQLReceiver) queryColumnTypes(queryid string) map[string]string {
+	columns, _ := m.sqlclient.getQuerySchema(queryid)
+	columnTypes := make(map[string]string, len(columns))
+	for _, column := range columns {
+		columnTypes[column.Name] = column.Type
+	}
+	return columnTypes
+}
+
+// setStructuredRecord replaces the JSON string body of the log record with the typed column values of the record,
+// either as a map body or as attributes depending on record_format.
+func (m *mySQLReceiver) setStructuredRecord(lr plog.LogRecord, body string, columnTypes map[string]string) {
+	columns, err := readRecord(body)
+	if err != nil {
+		m.logger.Error("Failed to read record, emitting it as a JSON string", zap.Error(err))
+		return
+	}
+	nulls := newNullFormat(m.config)
+	for name, value := range columns {
+		if nulls.isNull(value) {
+			columns[name] = nil
+		}
+	}
+	switch m.config.RecordFormat {
+	case recordFormatMap:
+		value := pcommon.NewValueMap()
+		putTypedValues(value.MapVal(), columns, columnTypes)
+		value.CopyTo(lr.Body())
+	case recordFormatAttributes:
+		pcommon.NewValueEmpty().CopyTo(lr.Body())
+		putTypedValues(lr.Attributes(), columns, columnTypes)
+	}
+}
+
+// putTypedValues puts the column values into the map in the order of the column names, converted by the database type of the column.
+// Integer columns are int values, floating point and decimal columns are double values and boolean columns are bool values,
+// NULL values, i.e. nil values, are empty values. Values which cannot be converted and columns of other or unknown types are string values.
+func putTypedValues(dest pcommon.Map, columns map[string]*string, columnTypes map[string]string) {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if columns[name] == nil {
+			dest.Upsert(name, pcommon.NewValueEmpty())
+			continue
+		}
+		dest.Upsert(name, typedValue(*columns[name], columnTypes[name]))
+	}
+}
+
+func typedValue(value string, columnType string) pcommon.Value {
+	if value == "NULL" {
+		return pcommon.NewValueEmpty()
+	}
+	columnType = strings.ToUpper(columnType)
+	switch {
+	case isIntegerColumn(columnType):
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return pcommon.NewValueInt(i)
+		}
+	case isFloatColumn(columnType):
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return pcommon.NewValueDouble(f)
+		}
+	case isBoolColumn(columnType):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return pcommon.NewValueBool(b)
+		}
+	}
+	return pcommon.NewValueString(value)
+}
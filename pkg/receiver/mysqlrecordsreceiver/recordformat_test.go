@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestTypedValue(t *testing.T) {
+	require.Equal(t, pcommon.NewValueInt(42), typedValue("42", "BIGINT"))
+	require.Equal(t, pcommon.NewValueInt(7), typedValue("7", "UNSIGNED INT"))
+	require.Equal(t, pcommon.NewValueInt(7), typedValue("7", "int4"))
+	require.Equal(t, pcommon.NewValueDouble(2.5), typedValue("2.5", "DECIMAL"))
+	require.Equal(t, pcommon.NewValueDouble(1000000), typedValue("1e+06", "NUMBER"))
+	require.Equal(t, pcommon.NewValueBool(true), typedValue("true", "BOOL"))
+	require.Equal(t, pcommon.NewValueEmpty(), typedValue("NULL", "VARCHAR"))
+	require.Equal(t, pcommon.NewValueString("POINT(1 2)"), typedValue("POINT(1 2)", "POINT"))
+	require.Equal(t, pcommon.NewValueString("1 day"), typedValue("1 day", "INTERVAL"))
+	// values which cannot be converted and unknown columns are strings
+	require.Equal(t, pcommon.NewValueString("n/a"), typedValue("n/a", "INT"))
+	require.Equal(t, pcommon.NewValueString("12"), typedValue("12", ""))
+}
+
+func TestQueryColumnTypes(t *testing.T) {
+	receiver := &mySQLReceiver{sqlclient: &mockClient{columns: []columnSchema{{Name: "PersonID", Type: "INT"}, {Name: "Name", Type: "VARCHAR"}}}}
+	require.Equal(t, map[string]string{"PersonID": "INT", "Name": "VARCHAR"}, receiver.queryColumnTypes("Q1"))
+
+	receiver.sqlclient = &mockClient{}
+	require.Equal(t, map[string]string{}, receiver.queryColumnTypes("Q1"))
+}
+
+func TestConsumeWRecordFormat(t *testing.T) {
+	columnTypes := map[string]string{"PersonID": "INT", "Name": "VARCHAR", "Balance": "DECIMAL"}
+	body := `{"Balance":"12.5","Name":"John","PersonID":"1"}`
+	metadata := &queryMetadata{queryId: "Q1", batchSequence: 1}
+
+	for _, format := range []string{recordFormatMap, recordFormatAttributes} {
+		sink := &consumertest.LogsSink{}
+		receiver := &mySQLReceiver{config: &Config{RecordFormat: format}, consumer: sink, logger: zap.NewNop()}
+		records := make(chan queryRecord, 2)
+		records <- queryRecord{body: body, columnTypes: columnTypes, metadata: metadata}
+		records <- queryRecord{body: "not json", columnTypes: columnTypes}
+		close(records)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		receiver.consume(records, 0, wg, context.Background())
+
+		logs := sink.AllLogs()
+		require.Len(t, logs, 2)
+		lr := logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		values := map[string]interface{}{"Balance": 12.5, "Name": "John", "PersonID": int64(1)}
+		if format == recordFormatMap {
+			require.Equal(t, pcommon.ValueTypeMap, lr.Body().Type())
+			require.Equal(t, values, lr.Body().MapVal().AsRaw())
+			require.Equal(t, "Q1", lr.Attributes().AsRaw()[queryIdAttribute])
+		} else {
+			require.Equal(t, pcommon.ValueTypeEmpty, lr.Body().Type())
+			attributes := lr.Attributes().AsRaw()
+			require.Equal(t, "Q1", attributes[queryIdAttribute])
+			for name, value := range values {
+				require.Equal(t, value, attributes[name])
+			}
+		}
+		// a record which cannot be read is emitted as it is
+		require.Equal(t, "not json", logs[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal())
+	}
+}
+
+func TestConsumeWRecordFormatWNullHandling(t *testing.T) {
+	columnTypes := map[string]string{"PersonID": "INT", "Name": "VARCHAR", "City": "VARCHAR"}
+	for _, tc := range []struct {
+		config Config
+		body   string
+	}{
+		{config: Config{RecordFormat: recordFormatMap}, body: `{"City":"NULL","Name":"John","PersonID":"1"}`},
+		{config: Config{RecordFormat: recordFormatMap, NullHandling: nullHandlingSentinel, NullSentinel: "N/A"}, body: `{"City":"N/A","Name":"John","PersonID":"1"}`},
+		{config: Config{RecordFormat: recordFormatMap, NullHandling: nullHandlingNull}, body: `{"City":null,"Name":"John","PersonID":"1"}`},
+	} {
+		sink := &consumertest.LogsSink{}
+		config := tc.config
+		receiver := &mySQLReceiver{config: &config, consumer: sink, logger: zap.NewNop()}
+		records := make(chan queryRecord, 1)
+		records <- queryRecord{body: tc.body, columnTypes: columnTypes}
+		close(records)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		receiver.consume(records, 0, wg, context.Background())
+
+		body := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().MapVal()
+		city, ok := body.Get("City")
+		require.True(t, ok, tc.body)
+		require.Equal(t, pcommon.ValueTypeEmpty, city.Type(), tc.body)
+		require.Equal(t, map[string]interface{}{"City": nil, "Name": "John", "PersonID": int64(1)}, body.AsRaw(), tc.body)
+	}
+}
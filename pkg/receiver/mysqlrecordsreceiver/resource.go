@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const (
+	//dbSystemAttribute is the database management system of the database target, e.g. mysql or postgresql
+	dbSystemAttribute = "db.system"
+	//dbNameAttribute is the name of the database the queries are run in
+	dbNameAttribute = "db.name"
+	//netPeerNameAttribute is the host of the database server
+	netPeerNameAttribute = "net.peer.name"
+	//netPeerPortAttribute is the port of the database server
+	netPeerPortAttribute = "net.peer.port"
+)
+
+//This function sets the attributes of the database target, the name of the target of the receiver and the fields as resource attributes, the fields take precedence
+//The host and the port are left out when the database is reached through a unix socket, or through one of dbhosts
+func (m *mySQLReceiver) setResourceAttributes(attrs pcommon.Map) {
+	if d, ok := getDriver(m.config.DBDriver); ok {
+		attrs.UpsertString(dbSystemAttribute, d.dbSystem())
+	}
+	if len(m.config.Database) != 0 {
+		attrs.UpsertString(dbNameAttribute, m.config.Database)
+	}
+	if len(m.config.DBHost) != 0 && m.config.Transport != "unix" && m.config.AuthenticationMode != "SocketAuth" {
+		attrs.UpsertString(netPeerNameAttribute, m.config.DBHost)
+		if port, err := strconv.Atoi(m.config.DBPort); err == nil {
+			attrs.UpsertInt(netPeerPortAttribute, int64(port))
+		}
+	}
+	if len(m.target) != 0 {
+		attrs.UpsertString(targetAttribute, m.target)
+	}
+	for key, value := range m.config.Fields {
+		attrs.UpsertString(key, value)
+	}
+}
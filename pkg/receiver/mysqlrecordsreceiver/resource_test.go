@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestSetResourceAttributes(t *testing.T) {
+	testcases := []struct {
+		name     string
+		config   Config
+		expected map[string]interface{}
+	}{
+		{
+			name:   "tcp",
+			config: Config{DBDriver: dbDriverMySQL, DBHost: "db.example.com", DBPort: "3306", Database: "sales", Fields: map[string]string{"app": "billing"}},
+			expected: map[string]interface{}{
+				dbSystemAttribute:    "mysql",
+				dbNameAttribute:      "sales",
+				netPeerNameAttribute: "db.example.com",
+				netPeerPortAttribute: int64(3306),
+				"app":                "billing",
+			},
+		},
+		{
+			name:   "fields take precedence",
+			config: Config{DBDriver: dbDriverPostgres, DBHost: "db.example.com", Database: "sales", Fields: map[string]string{dbNameAttribute: "billing"}},
+			expected: map[string]interface{}{
+				dbSystemAttribute:    "postgresql",
+				dbNameAttribute:      "billing",
+				netPeerNameAttribute: "db.example.com",
+			},
+		},
+		{
+			name:   "unix socket",
+			config: Config{DBDriver: dbDriverMySQL, AuthenticationMode: "SocketAuth", DBHost: "localhost", Database: "sales"},
+			expected: map[string]interface{}{
+				dbSystemAttribute: "mysql",
+				dbNameAttribute:   "sales",
+			},
+		},
+		{
+			name:   "dbhosts",
+			config: Config{DBDriver: dbDriverMySQL, DBHosts: []string{"primary:3306", "replica:3306"}, Database: "sales"},
+			expected: map[string]interface{}{
+				dbSystemAttribute: "mysql",
+				dbNameAttribute:   "sales",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := &mySQLReceiver{config: &tc.config}
+			attrs := pcommon.NewMap()
+			receiver.setResourceAttributes(attrs)
+			require.Equal(t, tc.expected, attrs.AsRaw())
+		})
+	}
+}
+
+func TestConsumeWDatabaseAttributes(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	config := &Config{DBDriver: dbDriverSQLServer, DBHost: "db.example.com", DBPort: "1433", Database: "sales"}
+	receiver := &mySQLReceiver{config: config, consumer: sink, logger: zap.NewNop()}
+
+	records := make(chan queryRecord, 1)
+	receiver.produceRecords(records, &DBQueries{QueryId: "Q1"}, map[string]string{"1": `{"id":"1"}`}, nil, context.Background())
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	logs := sink.AllLogs()
+	require.Len(t, logs, 1)
+	require.Equal(t, map[string]interface{}{
+		dbSystemAttribute:    "mssql",
+		dbNameAttribute:      "sales",
+		netPeerNameAttribute: "db.example.com",
+		netPeerPortAttribute: int64(1433),
+	}, logs[0].ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	require.Equal(t, map[string]interface{}{queryIdAttribute: "Q1"}, logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
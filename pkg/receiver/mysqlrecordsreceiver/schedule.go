@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startCollectors starts collecting the queries with collection_interval again on every tick of their own ticker
+// and the queries with schedule at the times of their schedule, until the receiver is shut down.
+// The other queries are collected at the collection_interval of the receiver, or only when the receiver starts if it's not set.
+func (m *mySQLReceiver) startCollectors(dbqueries []DBQueries) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopCollectors = cancel
+	for _, dbquery := range dbqueries {
+		collectionInterval := dbquery.CollectionInterval
+		if len(collectionInterval) == 0 {
+			collectionInterval = m.config.CollectionInterval
+		}
+		if len(dbquery.Schedule) != 0 {
+			schedule, _ := parseCronSchedule(dbquery.Schedule)
+			m.collectors.Add(1)
+			go m.collectOnSchedule(ctx, dbquery, schedule)
+		} else if len(collectionInterval) != 0 {
+			interval, _ := time.ParseDuration(collectionInterval)
+			m.collectors.Add(1)
+			go m.collectPeriodically(ctx, dbquery, interval)
+		}
+	}
+}
+
+// collectPeriodically collects the query on every tick, a collection still running when the next tick is due delays the next collection.
+func (m *mySQLReceiver) collectPeriodically(ctx context.Context, dbquery DBQueries, interval time.Duration) {
+	defer m.collectors.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.logger.Info("Collecting query periodically", zap.String("queryId", dbquery.QueryId), zap.Duration("collectionInterval", interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.collect(ctx, []DBQueries{dbquery})
+		}
+	}
+}
+
+// collectOnSchedule collects the query at the times of its schedule, in the local time zone.
+// A time of the schedule passing while a collection is still running is skipped.
+func (m *mySQLReceiver) collectOnSchedule(ctx context.Context, dbquery DBQueries, schedule *cronSchedule) {
+	defer m.collectors.Done()
+
+	m.logger.Info("Collecting query on schedule", zap.String("queryId", dbquery.QueryId), zap.String("schedule", dbquery.Schedule))
+	for {
+		next := schedule.next(time.Now())
+		if next.IsZero() {
+			m.logger.Warn("Schedule of the query doesn't match any time anymore", zap.String("queryId", dbquery.QueryId))
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			m.collect(ctx, []DBQueries{dbquery})
+		}
+	}
+}
+
+// initialQueries returns the queries collected when the receiver starts, the queries with schedule only run at the times of their schedule.
+func initialQueries(dbqueries []DBQueries) []DBQueries {
+	var initial []DBQueries
+	for _, dbquery := range dbqueries {
+		if len(dbquery.Schedule) == 0 {
+			initial = append(initial, dbquery)
+		}
+	}
+	return initial
+}
+
+func validateCollectionInterval(dbquery DBQueries) error {
+	if len(dbquery.Schedule) != 0 {
+		return errors.New("it cannot be used with schedule")
+	}
+	_, err := parseCollectionInterval(dbquery.CollectionInterval)
+	return err
+}
+
+// parseCollectionInterval parses the collection_interval of the receiver or of a query, which has to be a positive duration.
+func parseCollectionInterval(collectionInterval string) (time.Duration, error) {
+	interval, err := time.ParseDuration(collectionInterval)
+	if err != nil {
+		return 0, err
+	}
+	if interval <= 0 {
+		return 0, errors.New("it has to be positive")
+	}
+	return interval, nil
+}
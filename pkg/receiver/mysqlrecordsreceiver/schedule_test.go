@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestStartCollectors(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	sqlclient := &mockClient{records: []string{`{"id":"1"}`}}
+	receiver := &mySQLReceiver{config: &Config{}, consumer: sink, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	receiver.startCollectors([]DBQueries{
+		{QueryId: "Q1", Query: "select * from orders", CollectionInterval: "10ms"},
+		{QueryId: "Q2", Query: "select * from settings"},
+	})
+	require.Eventually(t, func() bool { return sink.LogRecordCount() >= 2 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+
+	count := len(sqlclient.queries)
+	for _, query := range sqlclient.queries {
+		require.Equal(t, "select * from orders", query)
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, sqlclient.queries, count)
+}
+
+func TestInitialQueries(t *testing.T) {
+	dbqueries := []DBQueries{
+		{QueryId: "Q1", Query: "select * from orders", CollectionInterval: "1m"},
+		{QueryId: "Q2", Query: "select * from report", Schedule: "0 2 * * *"},
+		{QueryId: "Q3", Query: "select * from settings"},
+	}
+	require.Equal(t, []DBQueries{dbqueries[0], dbqueries[2]}, initialQueries(dbqueries))
+}
+
+func TestStartCollectorsWReceiverCollectionInterval(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	sqlclient := &mockClient{records: []string{`{"id":"1"}`}}
+	receiver := &mySQLReceiver{config: &Config{CollectionInterval: "10ms"}, consumer: sink, logger: zap.NewNop(), sqlclient: sqlclient}
+
+	// the query without its own collection_interval is collected at the collection_interval of the receiver
+	receiver.startCollectors([]DBQueries{{QueryId: "Q1", Query: "select * from orders"}})
+	require.Eventually(t, func() bool { return sink.LogRecordCount() >= 2 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}
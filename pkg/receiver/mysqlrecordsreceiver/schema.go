@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+const (
+	//schemaRecordTypeAttribute marks log records describing the schema of a query result
+	schemaRecordTypeAttribute = "mysql.record_type"
+	schemaRecordType          = "schema"
+	//sourceCategoryAttribute is sent as the source category by the Sumo Logic exporter
+	sourceCategoryAttribute = "_sourceCategory"
+)
+
+// querySchema describes the columns of a query result.
+type querySchema struct {
+	QueryId string         `json:"query_id"`
+	Columns []columnSchema `json:"columns"`
+}
+
+type columnSchema struct {
+	Name string `json:"name"`
+	//Type is the database type name of the column, e.g. VARCHAR, INT or DATETIME
+	Type     string `json:"type"`
+	Nullable *bool  `json:"nullable,omitempty"`
+}
+
+func getSchemaStoreFilename(queryid string) string {
+	return queryid + "_schema.json"
+}
+
+// schemaChanged reports whether the schema is different from the last emitted schema of the query.
+// It's reported as changed when the last emitted schema cannot be read, e.g. on the first run.
+func schemaChanged(queryid string, schema []byte, logger *zap.Logger) bool {
+	previous, err := os.ReadFile(getSchemaStoreFilename(queryid))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error reading the last emitted schema, emitting the schema again.", zap.String("queryId", queryid), zap.Error(err))
+		}
+		return true
+	}
+	return !bytes.Equal(previous, schema)
+}
+
+// saveSchema writes the emitted schema of the query, so it's not emitted again until it changes.
+func saveSchema(queryid string, schema []byte) error {
+	storeFilename := getSchemaStoreFilename(queryid)
+	tmpFilename := storeFilename + ".tmp"
+	if err := os.WriteFile(tmpFilename, schema, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, storeFilename)
+}
+
+// collectSchema emits a log record describing the schema of the last result of the query,
+// on the first successful run of the query and every time the schema changes.
+func (m *mySQLReceiver) collectSchema(ctx context.Context, queryid string) {
+	columns, ok := m.sqlclient.getQuerySchema(queryid)
+	if !ok {
+		return
+	}
+	schema, err := json.Marshal(querySchema{QueryId: queryid, Columns: columns})
+	if err != nil {
+		m.logger.Error("Failed to convert query schema into json format", zap.String("queryId", queryid), zap.Error(err))
+		return
+	}
+	if !schemaChanged(queryid, schema, m.logger) {
+		return
+	}
+
+	logs := m.convertToLog(string(schema))
+	rl := logs.ResourceLogs().At(0)
+	if m.config.SchemaRecords.SourceCategory != "" {
+		rl.Resource().Attributes().UpsertString(sourceCategoryAttribute, m.config.SchemaRecords.SourceCategory)
+	}
+	rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().UpsertString(schemaRecordTypeAttribute, schemaRecordType)
+	if err := m.consumeLogs(ctx, logs); err != nil {
+		m.logger.Error("Failed to consume query schema", zap.String("queryId", queryid), zap.Error(err))
+		return
+	}
+	if err := saveSchema(queryid, schema); err != nil {
+		m.logger.Warn("Failed to save query schema, the schema will be emitted again in the next collection", zap.String("queryId", queryid), zap.Error(err))
+	}
+}
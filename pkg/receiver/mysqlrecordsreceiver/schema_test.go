@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestCollectSchema(t *testing.T) {
+	defer os.Remove(getSchemaStoreFilename("Q1"))
+
+	nullable := true
+	sqlclient := &mockClient{columns: []columnSchema{
+		{Name: "PersonID", Type: "INT"},
+		{Name: "Name", Type: "VARCHAR", Nullable: &nullable},
+	}}
+	sink := new(consumertest.LogsSink)
+	receiver := &mySQLReceiver{
+		sqlclient: sqlclient,
+		logger:    zap.NewNop(),
+		config:    &Config{SchemaRecords: SchemaRecords{Enabled: true, SourceCategory: "mysql/schema"}},
+		consumer:  sink,
+	}
+
+	// the schema is emitted on the first run
+	receiver.collectSchema(context.Background(), "Q1")
+	require.Len(t, sink.AllLogs(), 1)
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	category, ok := rl.Resource().Attributes().Get(sourceCategoryAttribute)
+	require.True(t, ok)
+	require.Equal(t, "mysql/schema", category.StringVal())
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	recordType, ok := lr.Attributes().Get(schemaRecordTypeAttribute)
+	require.True(t, ok)
+	require.Equal(t, schemaRecordType, recordType.StringVal())
+	require.JSONEq(t, `{"query_id":"Q1","columns":[{"name":"PersonID","type":"INT"},{"name":"Name","type":"VARCHAR","nullable":true}]}`, lr.Body().StringVal())
+
+	// the same schema is not emitted again, also after a restart
+	receiver.collectSchema(context.Background(), "Q1")
+	require.Len(t, sink.AllLogs(), 1)
+
+	// a changed schema is emitted
+	sqlclient.columns = append(sqlclient.columns, columnSchema{Name: "City", Type: "VARCHAR"})
+	receiver.collectSchema(context.Background(), "Q1")
+	require.Len(t, sink.AllLogs(), 2)
+	require.Contains(t, sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal(), `"name":"City"`)
+}
+
+func TestCollectSchemaWithoutCategory(t *testing.T) {
+	defer os.Remove(getSchemaStoreFilename("Q1"))
+
+	sink := new(consumertest.LogsSink)
+	receiver := &mySQLReceiver{
+		sqlclient: &mockClient{columns: []columnSchema{{Name: "PersonID", Type: "INT"}}},
+		logger:    zap.NewNop(),
+		config:    &Config{SchemaRecords: SchemaRecords{Enabled: true}},
+		consumer:  sink,
+	}
+
+	receiver.collectSchema(context.Background(), "Q1")
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, 0, sink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().Len())
+}
+
+func TestCollectSchemaNoResult(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	receiver := &mySQLReceiver{
+		sqlclient: &mockClient{},
+		logger:    zap.NewNop(),
+		config:    &Config{SchemaRecords: SchemaRecords{Enabled: true}},
+		consumer:  sink,
+	}
+
+	receiver.collectSchema(context.Background(), "Q1")
+	require.Empty(t, sink.AllLogs())
+	require.NoFileExists(t, getSchemaStoreFilename("Q1"))
+}
+
+func TestCollectSchemaConsumerError(t *testing.T) {
+	defer os.Remove(getSchemaStoreFilename("Q1"))
+
+	receiver := &mySQLReceiver{
+		sqlclient: &mockClient{columns: []columnSchema{{Name: "PersonID", Type: "INT"}}},
+		logger:    zap.NewNop(),
+		config:    &Config{SchemaRecords: SchemaRecords{Enabled: true}},
+		consumer:  consumertest.NewErr(errors.New("consumer error")),
+	}
+
+	// the schema is emitted again in the next collection
+	receiver.collectSchema(context.Background(), "Q1")
+	require.NoFileExists(t, getSchemaStoreFilename("Q1"))
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"go.uber.org/zap"
+)
+
+const (
+	//passwordSourceConfig uses the password of the collector config
+	passwordSourceConfig = "config"
+	//passwordSourceAWSSecretsManager fetches the password from the AWS Secrets Manager secret of secret_arn
+	passwordSourceAWSSecretsManager = "aws_secrets_manager"
+	//defaultSecretRefreshInterval is the interval the secret is fetched again at when secret_refresh_interval is not set
+	defaultSecretRefreshInterval = time.Hour
+)
+
+//secretsManagerAPI is the part of the AWS Secrets Manager client used for fetching the password
+type secretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+//secretPassword fetches the database password from AWS Secrets Manager, the password is cached and fetched again once the refresh interval passed,
+//so the new connections use the rotated password
+type secretPassword struct {
+	arn             string
+	refreshInterval time.Duration
+	newAPI          func(ctx context.Context) (secretsManagerAPI, error)
+	logger          *zap.Logger
+	now             func() time.Time
+
+	lock     sync.Mutex
+	api      secretsManagerAPI
+	password string
+	fetched  time.Time
+}
+
+func newSecretPassword(conf *Config, loadAWSConfig awsConfigLoader, logger *zap.Logger) *secretPassword {
+	refreshInterval := defaultSecretRefreshInterval
+	if len(conf.SecretRefreshInterval) != 0 {
+		refreshInterval, _ = time.ParseDuration(conf.SecretRefreshInterval)
+	}
+	region := conf.Region
+	if len(region) == 0 {
+		region = secretRegion(conf.SecretARN)
+	}
+	return &secretPassword{
+		arn:             conf.SecretARN,
+		refreshInterval: refreshInterval,
+		newAPI: func(ctx context.Context) (secretsManagerAPI, error) {
+			cfg, err := loadAWSConfig(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+				if len(region) != 0 {
+					o.Region = region
+				}
+			}), nil
+		},
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+//This function returns the region of the secret, i.e. the 4th field of its ARN arn:aws:secretsmanager:<region>:<account>:secret:<name>
+func secretRegion(arn string) string {
+	fields := strings.Split(arn, ":")
+	if len(fields) < 7 {
+		return ""
+	}
+	return fields[3]
+}
+
+func validateSecretARN(arn string) error {
+	fields := strings.Split(arn, ":")
+	if len(fields) < 7 || fields[0] != "arn" || fields[2] != "secretsmanager" || fields[5] != "secret" {
+		return fmt.Errorf("secret_arn '%s' is not the ARN of a Secrets Manager secret", arn)
+	}
+	return nil
+}
+
+//This function returns the cached password, it's fetched again once the refresh interval passed
+//The cached password is used while the secret cannot be fetched, so a Secrets Manager outage doesn't break the new connections
+func (s *secretPassword) get(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.fetched.IsZero() && s.now().Sub(s.fetched) < s.refreshInterval {
+		return s.password, nil
+	}
+	password, err := s.fetch(ctx)
+	if err != nil {
+		if s.fetched.IsZero() {
+			return "", err
+		}
+		s.logger.Warn("Failed to refresh the password from AWS Secrets Manager, using the cached password", zap.String("secretArn", s.arn), zap.Error(err))
+		return s.password, nil
+	}
+	s.password = password
+	s.fetched = s.now()
+	return password, nil
+}
+
+func (s *secretPassword) fetch(ctx context.Context) (string, error) {
+	if s.api == nil {
+		api, err := s.newAPI(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to load the AWS config for secret %s: %w", s.arn, err)
+		}
+		s.api = api
+	}
+	out, err := s.api.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(s.arn)})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s: %w", s.arn, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no secret string", s.arn)
+	}
+	return secretStringPassword(*out.SecretString), nil
+}
+
+//This function returns the password of a secret string, the secrets of RDS databases are JSON objects with the password in the password key,
+//other secret strings are the password
+func secretStringPassword(secret string) string {
+	var credentials struct {
+		Password *string `json:"password"`
+	}
+	if err := json.Unmarshal([]byte(secret), &credentials); err == nil && credentials.Password != nil {
+		return *credentials.Password
+	}
+	return secret
+}
+
+func validatePasswordSource(cfg *Config) error {
+	switch cfg.PasswordSource {
+	case "", passwordSourceConfig:
+		if len(cfg.SecretARN) != 0 || len(cfg.SecretRefreshInterval) != 0 {
+			return errors.New("secret_arn and secret_refresh_interval can only be used with password_source : 'aws_secrets_manager'")
+		}
+		return nil
+	case passwordSourceAWSSecretsManager:
+	default:
+		return errors.New("password_source should be either of 'config' or 'aws_secrets_manager'")
+	}
+	if cfg.AuthenticationMode != "BasicAuth" {
+		return errors.New("password_source : 'aws_secrets_manager' can only be used with authentication_mode : 'BasicAuth'")
+	}
+	if len(cfg.Password) != 0 || len(cfg.PasswordType) != 0 || len(cfg.EncryptSecretPath) != 0 {
+		return errors.New("password, password_type and encrypt_secret_path should be empty for password_source : 'aws_secrets_manager'")
+	}
+	if err := validateSecretARN(cfg.SecretARN); err != nil {
+		return err
+	}
+	if len(cfg.SecretRefreshInterval) != 0 {
+		interval, err := time.ParseDuration(cfg.SecretRefreshInterval)
+		if err != nil {
+			return fmt.Errorf("invalid secret_refresh_interval: %w", err)
+		}
+		if interval <= 0 {
+			return errors.New("secret_refresh_interval has to be positive")
+		}
+	}
+	return nil
+}
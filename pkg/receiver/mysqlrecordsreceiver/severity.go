@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// severityNumbers are the severity numbers of the severities of severity_mapping.
+var severityNumbers = map[string]plog.SeverityNumber{
+	"trace": plog.SeverityNumberTRACE,
+	"debug": plog.SeverityNumberDEBUG,
+	"info":  plog.SeverityNumberINFO,
+	"warn":  plog.SeverityNumberWARN,
+	"error": plog.SeverityNumberERROR,
+	"fatal": plog.SeverityNumberFATAL,
+}
+
+// defaultSeverityMapping maps the common level names to the severities, severity_mapping is applied on top of it.
+var defaultSeverityMapping = map[string][]string{
+	"trace": {"trace"},
+	"debug": {"debug"},
+	"info":  {"info", "information", "notice"},
+	"warn":  {"warn", "warning"},
+	"error": {"error", "err"},
+	"fatal": {"fatal", "critical", "crit", "alert", "emergency", "emerg", "panic"},
+}
+
+// severityMapping sets the severity of the log records of a query by the value of its severity_column.
+type severityMapping struct {
+	column string
+	// numbers are the severity numbers keyed by the lower-cased column values
+	numbers map[string]plog.SeverityNumber
+}
+
+// newSeverityMapping returns the severity mapping of the query, or nil when severity_column is not set.
+func newSeverityMapping(dbquery *DBQueries) *severityMapping {
+	if len(dbquery.SeverityColumn) == 0 {
+		return nil
+	}
+	mapping := &severityMapping{column: dbquery.SeverityColumn, numbers: make(map[string]plog.SeverityNumber)}
+	for _, severities := range []map[string][]string{defaultSeverityMapping, dbquery.SeverityMapping} {
+		for severity, values := range severities {
+			for _, value := range values {
+				mapping.numbers[strings.ToLower(value)] = severityNumbers[strings.ToLower(severity)]
+			}
+		}
+	}
+	return mapping
+}
+
+// setSeverity sets the value of the severity column of the record as the severity text of the log record and its mapped severity number.
+// The values are matched case-insensitively, the severity number is left undefined for values which are not mapped and for NULL values.
+func (m *mySQLReceiver) setSeverity(lr plog.LogRecord, body string, mapping *severityMapping) {
+	columns, err := readRecord(body)
+	if err != nil {
+		m.logger.Error("Failed to read record, emitting it without severity", zap.Error(err))
+		return
+	}
+	value, ok := columns[mapping.column]
+	if !ok || newNullFormat(m.config).isNull(value) {
+		return
+	}
+	lr.SetSeverityText(*value)
+	if number, ok := mapping.numbers[strings.ToLower(strings.TrimSpace(*value))]; ok {
+		lr.SetSeverityNumber(number)
+	}
+}
+
+func validateSeverityMapping(dbquery DBQueries) error {
+	if len(dbquery.SeverityColumn) == 0 {
+		if len(dbquery.SeverityMapping) != 0 {
+			return errors.New("severity_mapping can only be used with severity_column")
+		}
+		return nil
+	}
+	if len(dbquery.MetricName) != 0 {
+		return errors.New("severity_column cannot be used with metric_name")
+	}
+	for severity := range dbquery.SeverityMapping {
+		if _, ok := severityNumbers[strings.ToLower(severity)]; !ok {
+			return fmt.Errorf("severity %s is not supported, it can only be 'trace', 'debug', 'info', 'warn', 'error' or 'fatal'", severity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestConsumeWSeverityColumn(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	receiver := &mySQLReceiver{config: &Config{RecordPerRow: true}, consumer: sink, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", SeverityColumn: "level", SeverityMapping: map[string][]string{"ERROR": {"E", "failed"}, "debug": {"verbose"}}}
+	records := map[string]string{
+		"Q1_record1": `{"id":"1","level":"WARNING"}`,
+		"Q1_record2": `{"id":"2","level":"e"}`,
+		"Q1_record3": `{"id":"3","level":"Failed"}`,
+		"Q1_record4": `{"id":"4","level":"verbose"}`,
+		"Q1_record5": `{"id":"5","level":"unknown"}`,
+		"Q1_record6": `{"id":"6","level":"NULL"}`,
+		"Q1_record7": `{"id":"7"}`,
+		"Q1_record8": `{"id":"8","level":" info "}`,
+	}
+
+	ch := make(chan queryRecord, 1)
+	receiver.produceRecords(ch, &dbquery, records, nil, context.Background())
+	close(ch)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(ch, 0, wg, context.Background())
+
+	lrs := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 8, lrs.Len())
+	expected := []struct {
+		text   string
+		number plog.SeverityNumber
+	}{
+		{"WARNING", plog.SeverityNumberWARN},
+		{"e", plog.SeverityNumberERROR},
+		{"Failed", plog.SeverityNumberERROR},
+		{"verbose", plog.SeverityNumberDEBUG},
+		// the values which are not mapped are only set as the severity text
+		{"unknown", plog.SeverityNumberUNDEFINED},
+		// NULL values and missing columns don't set the severity
+		{"", plog.SeverityNumberUNDEFINED},
+		{"", plog.SeverityNumberUNDEFINED},
+		{" info ", plog.SeverityNumberINFO},
+	}
+	for i, severity := range expected {
+		require.Equal(t, severity.text, lrs.At(i).SeverityText(), i)
+		require.Equal(t, severity.number, lrs.At(i).SeverityNumber(), i)
+	}
+}
+
+func TestValidateSeverityMapping(t *testing.T) {
+	require.NoError(t, validateSeverityMapping(DBQueries{}))
+	require.NoError(t, validateSeverityMapping(DBQueries{SeverityColumn: "level"}))
+	require.NoError(t, validateSeverityMapping(DBQueries{SeverityColumn: "level", SeverityMapping: map[string][]string{"Warn": {"W"}}}))
+	require.EqualError(t, validateSeverityMapping(DBQueries{SeverityMapping: map[string][]string{"warn": {"W"}}}), "severity_mapping can only be used with severity_column")
+	require.EqualError(t, validateSeverityMapping(DBQueries{SeverityColumn: "level", SeverityMapping: map[string][]string{"critical": {"C"}}}), "severity critical is not supported, it can only be 'trace', 'debug', 'info', 'warn', 'error' or 'fatal'")
+	require.EqualError(t, validateSeverityMapping(DBQueries{SeverityColumn: "level", MetricName: "orders.count", ValueColumn: "Total"}), "severity_column cannot be used with metric_name")
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	//snapshotOperationAttribute is the attribute with the change of the row found by snapshot_diff, i.e. insert, update or delete
+	snapshotOperationAttribute = "mysql.snapshot.operation"
+
+	snapshotOperationInsert = "insert"
+	snapshotOperationUpdate = "update"
+	snapshotOperationDelete = "delete"
+)
+
+//This function validates the snapshot_diff settings of the query, the whole result of the query is compared with the previous one
+func validateSnapshotDiff(dbquery DBQueries) error {
+	if !dbquery.SnapshotDiff {
+		if len(dbquery.PrimaryKeyColumns) != 0 {
+			return errors.New("primary_key_columns can only be used with snapshot_diff")
+		}
+		return nil
+	}
+	if len(dbquery.PrimaryKeyColumns) == 0 {
+		return errors.New("primary_key_columns has to be set with snapshot_diff")
+	}
+	for _, column := range dbquery.PrimaryKeyColumns {
+		if len(strings.TrimSpace(column)) == 0 {
+			return errors.New("primary_key_columns cannot contain an empty column")
+		}
+	}
+	if isIncrementalQuery(&dbquery) || dbquery.EmitOnChangeOnly {
+		return errors.New("snapshot_diff cannot be used with index_column_name, index_columns or emit_on_change_only")
+	}
+	if len(dbquery.MetricName) != 0 {
+		return errors.New("snapshot_diff cannot be used with metric_name")
+	}
+	return nil
+}
+
+//This function compares the records of the query with the snapshot of its previous result, i.e. the hashes of the records keyed by their primary key
+//It returns the records of the inserted, updated and deleted rows and the snapshot of the records, the deleted rows have only their primary key columns
+//The changed records are keyed by <queryid>_<operation>_record<number>, in the order of the query result followed by the deleted rows
+func diffSnapshot(dbquery *DBQueries, records map[string]string, previous map[string]string) (map[string]string, map[string]string, error) {
+	snapshot := make(map[string]string, len(records))
+	changes := make(map[string]string)
+	var number int
+	for _, key := range sortedRecordKeys(records) {
+		record := records[key]
+		primaryKey, err := snapshotPrimaryKey(dbquery, record)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := snapshot[primaryKey]; ok {
+			return nil, nil, fmt.Errorf("primary key %s is not unique in the result of the query", primaryKey)
+		}
+		hash := sha256.Sum256([]byte(record))
+		snapshot[primaryKey] = hex.EncodeToString(hash[:])
+
+		previousHash, ok := previous[primaryKey]
+		switch {
+		case !ok:
+			number++
+			changes[snapshotRecordKey(dbquery, snapshotOperationInsert, number)] = record
+		case previousHash != snapshot[primaryKey]:
+			number++
+			changes[snapshotRecordKey(dbquery, snapshotOperationUpdate, number)] = record
+		}
+	}
+
+	deleted := make([]string, 0)
+	for primaryKey := range previous {
+		if _, ok := snapshot[primaryKey]; !ok {
+			deleted = append(deleted, primaryKey)
+		}
+	}
+	sort.Strings(deleted)
+	for _, primaryKey := range deleted {
+		record, err := snapshotDeletedRecord(dbquery, primaryKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		number++
+		changes[snapshotRecordKey(dbquery, snapshotOperationDelete, number)] = record
+	}
+	return changes, snapshot, nil
+}
+
+//This function returns the record keys in the order of the query result
+func sortedRecordKeys(records map[string]string) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sortRecordKeys(keys)
+	return keys
+}
+
+//This function returns the primary key of the record, the json array of the values of the primary_key_columns
+func snapshotPrimaryKey(dbquery *DBQueries, record string) (string, error) {
+	var jsonObject map[string]interface{}
+	if err := json.Unmarshal([]byte(record), &jsonObject); err != nil {
+		return "", fmt.Errorf("error parsing record: %w", err)
+	}
+	values := make([]interface{}, len(dbquery.PrimaryKeyColumns))
+	for i, column := range dbquery.PrimaryKeyColumns {
+		value, ok := jsonObject[column]
+		if !ok {
+			return "", fmt.Errorf("primary key column %s is not in the result of the query", column)
+		}
+		values[i] = value
+	}
+	primaryKey, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("error converting primary key into json format: %w", err)
+	}
+	return string(primaryKey), nil
+}
+
+//This function returns the record of a deleted row, with the values of its primary key columns
+func snapshotDeletedRecord(dbquery *DBQueries, primaryKey string) (string, error) {
+	var values []interface{}
+	if err := json.Unmarshal([]byte(primaryKey), &values); err != nil || len(values) != len(dbquery.PrimaryKeyColumns) {
+		return "", fmt.Errorf("invalid primary key %s in the snapshot", primaryKey)
+	}
+	jsonObject := make(map[string]interface{}, len(values))
+	for i, column := range dbquery.PrimaryKeyColumns {
+		jsonObject[column] = values[i]
+	}
+	record, err := json.Marshal(jsonObject)
+	if err != nil {
+		return "", fmt.Errorf("error converting record into json format: %w", err)
+	}
+	return string(record), nil
+}
+
+func snapshotRecordKey(dbquery *DBQueries, operation string, number int) string {
+	return dbquery.QueryId + "_" + operation + "_record" + strconv.Itoa(number)
+}
+
+//This function returns the operation of a record of a query with snapshot_diff from its key, i.e. <queryid>_<operation>_record<number>
+func snapshotOperation(key string) string {
+	end := strings.LastIndex(key, "_record")
+	if end < 0 {
+		return ""
+	}
+	for _, operation := range []string{snapshotOperationInsert, snapshotOperationUpdate, snapshotOperationDelete} {
+		if strings.HasSuffix(key[:end], "_"+operation) {
+			return operation
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestValidateSnapshotDiff(t *testing.T) {
+	require.NoError(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}}))
+	require.NoError(t, validateSnapshotDiff(DBQueries{QueryId: "Q1"}))
+	require.EqualError(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", PrimaryKeyColumns: []string{"PersonID"}}), "primary_key_columns can only be used with snapshot_diff")
+	require.EqualError(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true}), "primary_key_columns has to be set with snapshot_diff")
+	require.EqualError(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{" "}}), "primary_key_columns cannot contain an empty column")
+	require.Error(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}, EmitOnChangeOnly: true}))
+	require.Error(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}, IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}))
+	require.Error(t, validateSnapshotDiff(DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}, MetricName: "persons.count"}))
+}
+
+func TestDiffSnapshot(t *testing.T) {
+	dbquery := &DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"Region", "PersonID"}}
+	// all the rows are inserted without a previous snapshot
+	changes, snapshot, err := diffSnapshot(dbquery, map[string]string{
+		"Q1_record1": `{"PersonID":"1","Region":"eu","Name":"Ann"}`,
+		"Q1_record2": `{"PersonID":"2","Region":"eu","Name":"Bob"}`,
+		"Q1_record3": `{"PersonID":"1","Region":"us","Name":"Cid"}`,
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, snapshot, 3)
+	require.Equal(t, map[string]string{
+		"Q1_insert_record1": `{"PersonID":"1","Region":"eu","Name":"Ann"}`,
+		"Q1_insert_record2": `{"PersonID":"2","Region":"eu","Name":"Bob"}`,
+		"Q1_insert_record3": `{"PersonID":"1","Region":"us","Name":"Cid"}`,
+	}, changes)
+
+	changes, next, err := diffSnapshot(dbquery, map[string]string{
+		"Q1_record1": `{"PersonID":"1","Region":"eu","Name":"Ann"}`,
+		"Q1_record2": `{"PersonID":"1","Region":"us","Name":"Cyd"}`,
+		"Q1_record3": `{"PersonID":"3","Region":"eu","Name":"Dan"}`,
+	}, snapshot)
+	require.NoError(t, err)
+	require.Len(t, next, 3)
+	require.Equal(t, snapshot[`["eu","1"]`], next[`["eu","1"]`])
+	require.Equal(t, map[string]string{
+		"Q1_update_record1": `{"PersonID":"1","Region":"us","Name":"Cyd"}`,
+		"Q1_insert_record2": `{"PersonID":"3","Region":"eu","Name":"Dan"}`,
+		"Q1_delete_record3": `{"PersonID":"2","Region":"eu"}`,
+	}, changes)
+
+	// unchanged results have no changes
+	changes, _, err = diffSnapshot(dbquery, map[string]string{
+		"Q1_record1": `{"PersonID":"1","Region":"eu","Name":"Ann"}`,
+		"Q1_record2": `{"PersonID":"1","Region":"us","Name":"Cyd"}`,
+		"Q1_record3": `{"PersonID":"3","Region":"eu","Name":"Dan"}`,
+	}, next)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, _, err = diffSnapshot(dbquery, map[string]string{"Q1_record1": `{"PersonID":"1"}`}, nil)
+	require.EqualError(t, err, "primary key column Region is not in the result of the query")
+	_, _, err = diffSnapshot(dbquery, map[string]string{
+		"Q1_record1": `{"PersonID":"1","Region":"eu","Name":"Ann"}`,
+		"Q1_record2": `{"PersonID":"1","Region":"eu","Name":"Bob"}`,
+	}, nil)
+	require.EqualError(t, err, `primary key ["eu","1"] is not unique in the result of the query`)
+}
+
+func TestSnapshotOperation(t *testing.T) {
+	require.Equal(t, snapshotOperationInsert, snapshotOperation("Q1_insert_record1"))
+	require.Equal(t, snapshotOperationUpdate, snapshotOperation("Q1_update_record12"))
+	require.Equal(t, snapshotOperationDelete, snapshotOperation("my_delete_query_delete_record3"))
+	require.Equal(t, "", snapshotOperation("Q1_record1"))
+}
+
+func TestSnapshot(t *testing.T) {
+	dbquery := &DBQueries{QueryId: "Q1", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}}
+	defer os.Remove(getSnapshotFilename(dbquery))
+	require.Equal(t, "Q1_snapshot.csv", getSnapshotFilename(dbquery))
+
+	_, ok := GetSnapshot(dbquery, zap.NewNop())
+	require.False(t, ok)
+
+	snapshot := map[string]string{`["2"]`: "b", `["1"]`: "a"}
+	require.NoError(t, SaveSnapshot(dbquery, snapshot, zap.NewNop()))
+	saved, ok := GetSnapshot(dbquery, zap.NewNop())
+	require.True(t, ok)
+	require.Equal(t, snapshot, saved)
+}
+
+func TestStreamRecordsWSnapshotDiff(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from persons", SnapshotDiff: true, PrimaryKeyColumns: []string{"PersonID"}}
+	defer os.Remove(getSnapshotFilename(&dbquery))
+	sqlclient := &mockClient{records: []string{`{"PersonID":"1","Name":"Ann"}`, `{"PersonID":"2","Name":"Bob"}`}}
+
+	var batches []map[string]string
+	fetched, err := streamRecords(context.Background(), sqlclient, &dbquery, 1, zap.NewNop(), func(records map[string]string) {
+		batches = append(batches, records)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, fetched)
+	require.Equal(t, []int{0}, sqlclient.batchSizes)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+
+	// the changes since the saved snapshot are handled
+	sqlclient.records = []string{`{"PersonID":"1","Name":"Amy"}`}
+	batches = nil
+	fetched, err = streamRecords(context.Background(), sqlclient, &dbquery, 1, zap.NewNop(), func(records map[string]string) {
+		batches = append(batches, records)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, fetched)
+	require.Equal(t, []map[string]string{{
+		"Q1_update_record1": `{"PersonID":"1","Name":"Amy"}`,
+		"Q1_delete_record2": `{"PersonID":"2"}`,
+	}}, batches)
+}
+
+func TestAppendLogRecordWSnapshotOperation(t *testing.T) {
+	receiver := &mySQLReceiver{config: createDefaultConfig().(*Config), logger: zap.NewNop()}
+	lrs := plog.NewLogRecordSlice()
+	receiver.appendLogRecord(lrs, `{"PersonID":"2"}`, queryRecord{queryId: "Q1", snapshotOperation: snapshotOperationDelete})
+	operation, ok := lrs.At(0).Attributes().Get(snapshotOperationAttribute)
+	require.True(t, ok)
+	require.Equal(t, snapshotOperationDelete, operation.StringVal())
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	// registers the sqlserver driver
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+const (
+	//dbDriverSQLServer connects to Microsoft SQL Server and Azure SQL Database
+	dbDriverSQLServer = "sqlserver"
+	//defaultSQLServerPort is the port of the SQL Server instance used when dbport is not set
+	defaultSQLServerPort = "1433"
+)
+
+func init() {
+	registerDriver(dbDriverSQLServer, sqlServerDriver{})
+}
+
+type sqlServerDriver struct {
+	baseDriver
+}
+
+//There are 2 scenarios here for creating connection strings for a SQL Server database connection
+//1. SQL Server authentication with a plaintext or an encrypted password, with 'BasicAuth'
+//2. Windows authentication of a domain account with NTLM, with 'WindowsAuth', the username is in the 'DOMAIN\user' format
+//The driver chooses the authentication based on the format of the username, so the connection strings are the same
+func (sqlServerDriver) connectionString(conf *Config, _ awsConfigLoader, logger *zap.Logger) (string, error) {
+	port := conf.DBPort
+	if len(port) == 0 {
+		port = defaultSQLServerPort
+	}
+	query := url.Values{}
+	query.Set("database", conf.Database)
+	u := url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(conf.Username, basicAuthPassword(conf, logger)),
+		Host:     net.JoinHostPort(conf.DBHost, port),
+		RawQuery: query.Encode(),
+	}
+	return u.String(), nil
+}
+
+func (sqlServerDriver) dbSystem() string {
+	return "mssql"
+}
+
+func (sqlServerDriver) quoteIdentifier(name string) string {
+	return quoteIdentifier(name, "[", "]")
+}
+
+func (sqlServerDriver) placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+//SQL Server has no limit clause, the rows are limited with the offset and fetch options of the order by clause
+func (sqlServerDriver) limitClause(rows int) string {
+	return " offset 0 rows fetch next " + strconv.Itoa(rows) + " rows only"
+}
+
+//SQL Server has no IAM database authentication and no read only sessions
+func (sqlServerDriver) validate(cfg *Config) error {
+	err := multierr.Combine(
+		validateAuthenticationMode(cfg, "BasicAuth", "WindowsAuth"),
+		validateMySQLOnlyOptions(cfg),
+		validateNoReadOnlySession(cfg),
+	)
+	//'WindowsAuth' authenticates the domain account with NTLM, the username identifies the domain of the account
+	if cfg.AuthenticationMode == "WindowsAuth" && !strings.Contains(cfg.Username, `\`) {
+		err = multierr.Append(err, errors.New(`username should be in the 'DOMAIN\user' format for authentication_mode : 'WindowsAuth'`))
+	}
+	return err
+}
@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	//sshTunnelNetworkPrefix prefixes the names of the networks the SSH tunnel dialers are registered under with the driver
+	sshTunnelNetworkPrefix = "sshtunnel_"
+	//defaultSSHPort is the port of the bastion host when its host doesn't contain one
+	defaultSSHPort = "22"
+	//sshTunnelTimeout limits the time of establishing the SSH connection to the bastion host
+	sshTunnelTimeout = 30 * time.Second
+)
+
+//SSHTunnelConfig is the bastion host the connections to the database are established through
+type SSHTunnelConfig struct {
+	//Host is the bastion host, with the port if it's not 22
+	Host string `mapstructure:"host"`
+	//User is the user of the bastion host
+	User string `mapstructure:"user"`
+	//KeyFile is the unencrypted private key of the user
+	KeyFile string `mapstructure:"key_file"`
+	//KnownHostsFile is the known_hosts file the host key of the bastion host is verified with
+	KnownHostsFile string `mapstructure:"known_hosts_file,omitempty"`
+	//InsecureIgnoreHostKey disables the verification of the host key of the bastion host
+	InsecureIgnoreHostKey bool `mapstructure:"insecure_ignore_host_key,omitempty"`
+}
+
+//sshTunnels are the SSH tunnels of the receivers, keyed by the network they are registered under
+//The logs and the metrics receivers with the same id share the tunnel, so it's closed when both of them are
+var (
+	sshTunnels     = make(map[string]*sshTunnel)
+	sshTunnelsLock sync.Mutex
+)
+
+//sshTunnel forwards the connections to the database through the SSH connection to the bastion host,
+//the SSH connection is shared by the connections of the receiver and established again once it's broken
+type sshTunnel struct {
+	addr   string
+	config *ssh.ClientConfig
+
+	lock   sync.Mutex
+	client *ssh.Client
+
+	//refs is the number of the clients the tunnel is registered for, it's guarded by sshTunnelsLock
+	refs int
+}
+
+//This function validates the ssh_tunnel settings, the key and known_hosts files are loaded so the invalid ones are rejected on startup
+func validateSSHTunnel(cfg *Config) error {
+	if cfg.SSHTunnel == nil {
+		return nil
+	}
+	if cfg.AuthenticationMode == "SocketAuth" || cfg.AuthenticationMode == "GCPCloudSQLIAM" {
+		return fmt.Errorf("ssh_tunnel cannot be used with authentication_mode : '%s'", cfg.AuthenticationMode)
+	}
+	if len(cfg.ProxyURL) != 0 {
+		return errors.New("ssh_tunnel and proxy_url cannot be used together")
+	}
+	if cfg.Transport == "unix" {
+		return errors.New("ssh_tunnel cannot be used with the 'unix' transport")
+	}
+	if len(cfg.SSHTunnel.Host) == 0 || len(cfg.SSHTunnel.User) == 0 || len(cfg.SSHTunnel.KeyFile) == 0 {
+		return errors.New("ssh_tunnel host, user and key_file cannot be empty")
+	}
+	if len(cfg.SSHTunnel.KnownHostsFile) == 0 && !cfg.SSHTunnel.InsecureIgnoreHostKey {
+		return errors.New("ssh_tunnel known_hosts_file cannot be empty unless insecure_ignore_host_key is enabled")
+	}
+	if _, err := sshClientConfig(cfg.SSHTunnel); err != nil {
+		return fmt.Errorf("invalid ssh_tunnel settings: %w", err)
+	}
+	return nil
+}
+
+//This function returns the SSH client config authenticating the user with the private key of key_file
+func sshClientConfig(conf *SSHTunnelConfig) (*ssh.ClientConfig, error) {
+	key, err := os.ReadFile(conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key_file: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key_file: %w", err)
+	}
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !conf.InsecureIgnoreHostKey {
+		hostKeyCallback, err = knownhosts.New(conf.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known_hosts_file: %w", err)
+		}
+	}
+	return &ssh.ClientConfig{
+		User:            conf.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshTunnelTimeout,
+	}, nil
+}
+
+//This function returns the address of the bastion host, with the default SSH port if the host doesn't contain one
+func sshTunnelAddr(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, defaultSSHPort)
+}
+
+//This function registers the dial function forwarding the connections through the SSH tunnel of the receiver with the driver
+//It returns the name of the network the dial function is registered under, which is used as the network of the connection string
+func registerSSHTunnelDialer(conf *Config) (string, error) {
+	//The network name is a part of the connection string, so it cannot contain the '/' of the receiver id
+	network := sshTunnelNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	sshTunnelsLock.Lock()
+	defer sshTunnelsLock.Unlock()
+	tunnel, err := loadSSHTunnel(conf, network)
+	if err != nil {
+		return "", err
+	}
+	tunnel.refs++
+	return network, nil
+}
+
+//This function returns the dial function forwarding the connections through the SSH tunnel of the receiver, which is created if it's not yet
+//The tunnel is closed with the client which registered it
+func sshTunnelDialContext(conf *Config) (mysql.DialContextFunc, error) {
+	network := sshTunnelNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	sshTunnelsLock.Lock()
+	defer sshTunnelsLock.Unlock()
+	tunnel, err := loadSSHTunnel(conf, network)
+	if err != nil {
+		return nil, err
+	}
+	return tunnel.dial, nil
+}
+
+//This function returns the SSH tunnel registered under the network, the tunnel is created and registered with the driver if it's not yet
+//sshTunnelsLock has to be held by the caller
+func loadSSHTunnel(conf *Config, network string) (*sshTunnel, error) {
+	if tunnel, ok := sshTunnels[network]; ok {
+		return tunnel, nil
+	}
+	config, err := sshClientConfig(conf.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+	tunnel := &sshTunnel{addr: sshTunnelAddr(conf.SSHTunnel.Host), config: config}
+	sshTunnels[network] = tunnel
+	mysql.RegisterDialContext(network, tunnel.dial)
+	return tunnel, nil
+}
+
+//This function closes the SSH connection of the tunnel of the receiver once no other client uses it
+func closeSSHTunnel(conf *Config) error {
+	network := sshTunnelNetworkPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	sshTunnelsLock.Lock()
+	defer sshTunnelsLock.Unlock()
+	tunnel, ok := sshTunnels[network]
+	if !ok {
+		return nil
+	}
+	if tunnel.refs--; tunnel.refs > 0 {
+		return nil
+	}
+	delete(sshTunnels, network)
+	return tunnel.close()
+}
+
+//This function opens the connection to the database address through the bastion host,
+//the SSH connection is established again if the forwarding fails on a broken one
+func (s *sshTunnel) dial(ctx context.Context, addr string) (net.Conn, error) {
+	client, err := s.sshClient(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial("tcp", addr)
+	if err == nil {
+		return conn, nil
+	}
+	if client, err = s.sshClient(ctx, client); err != nil {
+		return nil, err
+	}
+	return client.Dial("tcp", addr)
+}
+
+//This function returns the SSH connection to the bastion host, the broken connection is closed and replaced by a new one
+func (s *sshTunnel) sshClient(ctx context.Context, broken *ssh.Client) (*ssh.Client, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client != nil && s.client != broken {
+		return s.client, nil
+	}
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh_tunnel host: %w", err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, s.addr, s.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to establish ssh_tunnel: %w", err)
+	}
+	s.client = ssh.NewClient(sshConn, chans, reqs)
+	return s.client, nil
+}
+
+func (s *sshTunnel) close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
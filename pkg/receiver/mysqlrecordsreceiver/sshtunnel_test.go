@@ -0,0 +1,253 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+//This function generates a key, which is written to the file in the PEM format if the path is set
+func newTestSSHSigner(t *testing.T, path string) ssh.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	if len(path) != 0 {
+		keyBytes, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
+
+//This function starts a bastion host accepting the user key and forwarding the direct-tcpip channels, it returns its address
+func startTestBastion(t *testing.T, hostKey ssh.Signer, userKey ssh.PublicKey) string {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() != "collector" || string(key.Marshal()) != string(userKey.Marshal()) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestBastion(conn, config)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveTestBastion(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if newChannel.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChannel.ExtraData(), &target) != nil {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel")
+			continue
+		}
+		targetConn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			targetConn.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			defer targetConn.Close()
+			go io.Copy(targetConn, channel)
+			io.Copy(channel, targetConn)
+		}()
+	}
+}
+
+//This function starts the database stand-in, which greets every connection
+func startTestGreeter(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("hello"))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func newTestSSHTunnelConfig(t *testing.T) (*Config, ssh.Signer) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "id_ecdsa")
+	userKey := newTestSSHSigner(t, keyFile)
+	hostKey := newTestSSHSigner(t, "")
+	bastion := startTestBastion(t, hostKey, userKey.PublicKey())
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{bastion}, hostKey.PublicKey())+"\n"), 0600))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "10.0.1.15"
+	cfg.Database = "app"
+	cfg.SSHTunnel = &SSHTunnelConfig{
+		Host:           bastion,
+		User:           "collector",
+		KeyFile:        keyFile,
+		KnownHostsFile: knownHostsFile,
+	}
+	return cfg, hostKey
+}
+
+func TestSSHTunnelDial(t *testing.T) {
+	cfg, _ := newTestSSHTunnelConfig(t)
+	greeter := startTestGreeter(t)
+
+	network, err := registerSSHTunnelDialer(cfg)
+	require.NoError(t, err)
+	require.Equal(t, sshTunnelNetworkPrefix+"mysqlrecords", network)
+	defer closeSSHTunnel(cfg)
+
+	// the connections share the SSH connection to the bastion host
+	for i := 0; i < 2; i++ {
+		conn, err := sshTunnels[network].dial(context.Background(), greeter)
+		require.NoError(t, err)
+		greeting, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(greeting))
+		conn.Close()
+	}
+
+	// the SSH connection is established again once it's broken
+	sshTunnels[network].client.Close()
+	conn, err := sshTunnels[network].dial(context.Background(), greeter)
+	require.NoError(t, err)
+	conn.Close()
+
+	require.NoError(t, closeSSHTunnel(cfg))
+	require.NotContains(t, sshTunnels, network)
+}
+
+func TestSSHTunnelShared(t *testing.T) {
+	cfg, _ := newTestSSHTunnelConfig(t)
+	greeter := startTestGreeter(t)
+
+	// the logs and the metrics receivers with the same id share the tunnel, it's closed with the last of them
+	network, err := registerSSHTunnelDialer(cfg)
+	require.NoError(t, err)
+	_, err = registerSSHTunnelDialer(cfg)
+	require.NoError(t, err)
+	defer closeSSHTunnel(cfg)
+
+	require.NoError(t, closeSSHTunnel(cfg))
+	require.Contains(t, sshTunnels, network)
+	conn, err := sshTunnels[network].dial(context.Background(), greeter)
+	require.NoError(t, err)
+	conn.Close()
+
+	require.NoError(t, closeSSHTunnel(cfg))
+	require.NotContains(t, sshTunnels, network)
+}
+
+func TestSSHTunnelUnknownHostKey(t *testing.T) {
+	cfg, _ := newTestSSHTunnelConfig(t)
+	otherHostKey := newTestSSHSigner(t, "")
+	require.NoError(t, os.WriteFile(cfg.SSHTunnel.KnownHostsFile, []byte(knownhosts.Line([]string{cfg.SSHTunnel.Host}, otherHostKey.PublicKey())+"\n"), 0600))
+	config, err := sshClientConfig(cfg.SSHTunnel)
+	require.NoError(t, err)
+
+	tunnel := &sshTunnel{addr: cfg.SSHTunnel.Host, config: config}
+	_, err = tunnel.dial(context.Background(), startTestGreeter(t))
+	require.Error(t, err)
+}
+
+func TestNewMySQLClientWithSSHTunnelWInvalidKey(t *testing.T) {
+	cfg, _ := newTestSSHTunnelConfig(t)
+	cfg.ReceiverSettings = config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "invalidkey"))
+	cfg.SSHTunnel.KeyFile = filepath.Join(t.TempDir(), "missing")
+
+	// the connections don't bypass the bastion host when the tunnel cannot be set up
+	_, err := mySQLDriver{}.connectionString(cfg, loadDefaultAWSConfig, zap.NewNop())
+	require.Error(t, err)
+}
+
+func TestValidateSSHTunnel(t *testing.T) {
+	cfg, _ := newTestSSHTunnelConfig(t)
+	require.NoError(t, cfg.Validate())
+
+	cfg.SSHTunnel.KnownHostsFile = ""
+	require.Error(t, cfg.Validate())
+
+	cfg.SSHTunnel.InsecureIgnoreHostKey = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.SSHTunnel.KeyFile = filepath.Join(t.TempDir(), "missing")
+	require.Error(t, cfg.Validate())
+
+	cfg, _ = newTestSSHTunnelConfig(t)
+	cfg.ProxyURL = "socks5://jumphost:1080"
+	require.Error(t, cfg.Validate())
+
+	cfg, _ = newTestSSHTunnelConfig(t)
+	cfg.DBDriver = dbDriverPostgres
+	require.Error(t, cfg.Validate())
+}
+
+func TestSSHTunnelAddr(t *testing.T) {
+	require.Equal(t, "bastion.example.com:22", sshTunnelAddr("bastion.example.com"))
+	require.Equal(t, "bastion.example.com:2222", sshTunnelAddr("bastion.example.com:2222"))
+}
@@ -14,34 +14,124 @@
 package mysqlrecordsreceiver
 
 import (
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"errors"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-mysql-org/go-mysql/mysql"
 	"go.uber.org/zap"
 )
 
+//This function returns the prefix of the names of the files of the query, the queries of a target are prefixed with the name of the target
+func queryFilePrefix(dbquery *DBQueries) string {
+	if len(dbquery.target) != 0 {
+		return dbquery.target + "_" + dbquery.QueryId
+	}
+	return dbquery.QueryId
+}
+
 func getStateStoreFilename(dbquery *DBQueries) string {
 	var fileextension = ".csv"
-	storeFilename := dbquery.QueryId + "_" + dbquery.IndexColumnName + "_" + dbquery.IndexColumnType + fileextension
+	storeFilename := queryFilePrefix(dbquery) + "_" + dbquery.IndexColumnName + "_" + dbquery.IndexColumnType + fileextension
+	if isCompositeIndex(dbquery) {
+		storeFilename = queryFilePrefix(dbquery) + "_" + strings.Join(dbquery.IndexColumns, "_") + "_" + strings.Join(dbquery.IndexColumnTypes, "_") + fileextension
+	}
 	return storeFilename
 }
 
+//initialIndexValueLayouts are the layouts of the initial index value of 'TIMESTAMP' index columns
+var initialIndexValueLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+//This function returns the time zone of the values of a 'TIMESTAMP' index column, UTC if index_column_timezone is not set
+//The time zone is validated with the config, so UTC is returned if it cannot be loaded
+func indexColumnLocation(dbquery *DBQueries) *time.Location {
+	if len(dbquery.IndexColumnTimezone) == 0 {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(dbquery.IndexColumnTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func validateIndexColumnTimezone(dbquery DBQueries) error {
+	if !hasTimestampIndexColumn(&dbquery) {
+		return errors.New("it can only be used with a 'TIMESTAMP' index column")
+	}
+	_, err := time.LoadLocation(dbquery.IndexColumnTimezone)
+	return err
+}
+
+//This function returns the configured initial index value, initial_index_value takes precedence over the deprecated initial_index_column_start_value
+func initialIndexValue(dbquery *DBQueries) string {
+	if len(dbquery.InitialIndexValue) != 0 {
+		return dbquery.InitialIndexValue
+	}
+	return dbquery.InitialIndexColumnStartValue
+}
+
+//This function parses the initial index value of a 'TIMESTAMP' index column, the values without a time zone are in loc
+func parseInitialIndexTimestamp(value string, loc *time.Location) (time.Time, error) {
+	var err error
+	for _, layout := range initialIndexValueLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func validateInitialIndexValue(dbquery DBQueries) error {
+	if len(dbquery.InitialIndexValue) == 0 {
+		return nil
+	}
+	if len(dbquery.InitialIndexColumnStartValue) != 0 {
+		return errors.New("initial_index_value and initial_index_column_start_value cannot be used together")
+	}
+	if !isIncrementalQuery(&dbquery) {
+		return errors.New("initial_index_value can only be used with index_column_name or index_columns")
+	}
+	//The initial_index_value of a composite index is the value of its first column
+	switch indexColumns(&dbquery)[0].columnType {
+	case "NUMBER":
+		if _, err := strconv.Atoi(dbquery.InitialIndexValue); err != nil {
+			return errors.New("initial_index_value should be an integer for index_column_type : 'NUMBER'")
+		}
+	case "TIMESTAMP":
+		if _, err := parseInitialIndexTimestamp(dbquery.InitialIndexValue, time.UTC); err != nil {
+			return errors.New("initial_index_value should be a timestamp in the 'YYYY-MM-DD hh:mm:ss' or RFC 3339 format for index_column_type : 'TIMESTAMP'")
+		}
+	}
+	return nil
+}
+
 func getStateValueNUMBER(dbquery *DBQueries, logger *zap.Logger) string {
 	var startval int = 0
 	var stateValue string
-	if dbquery.InitialIndexColumnStartValue == "" {
-		logger.Info("initial_index_column_start_value int not specified, considering default as 0 for:", zap.String("queryId", dbquery.QueryId))
+	startValue := initialIndexValue(dbquery)
+	if startValue == "" {
+		logger.Info("initial_index_value int not specified, considering default as 0 for:", zap.String("queryId", dbquery.QueryId))
 		stateValue = strconv.Itoa(startval)
-	} else if dbquery.InitialIndexColumnStartValue == "0" {
-		stateValue = dbquery.InitialIndexColumnStartValue
+	} else if startValue == "0" {
+		stateValue = startValue
 	} else {
-		startval, err := strconv.Atoi(dbquery.InitialIndexColumnStartValue)
+		startval, err := strconv.Atoi(startValue)
 		if err != nil {
 			stateValue = strconv.Itoa(startval)
-			logger.Info("Problem parsing initial_index_column_start_value int", zap.String("queryId", dbquery.QueryId))
+			logger.Info("Problem parsing initial_index_value int", zap.String("queryId", dbquery.QueryId))
 			logger.Info("Check collector config file. Considering default 0 for:", zap.String("queryId", dbquery.QueryId))
 		} else {
 			stateValue = strconv.Itoa(startval - 1)
@@ -53,16 +143,17 @@ func getStateValueNUMBER(dbquery *DBQueries, logger *zap.Logger) string {
 func getStateValueTIMESTAMP(dbquery *DBQueries, logger *zap.Logger) string {
 	var startDate time.Time = time.Now()
 	var stateValue string
-	if dbquery.InitialIndexColumnStartValue == "" {
-		logger.Info("initial_index_column_start_value date not specified, considering default as now - 48hrs for:", zap.String("queryId", dbquery.QueryId))
+	startValue := initialIndexValue(dbquery)
+	if startValue == "" {
+		logger.Info("initial_index_value date not specified, considering default as now - 48hrs for:", zap.String("queryId", dbquery.QueryId))
 		startDate = startDate.Add(-48 * time.Hour)
 		stateValue = startDate.String()
-	} else if dbquery.InitialIndexColumnStartValue != "" {
-		startDate, err := time.Parse("2006-01-02 15:04:05", dbquery.InitialIndexColumnStartValue)
+	} else if startValue != "" {
+		startDate, err := parseInitialIndexTimestamp(startValue, indexColumnLocation(dbquery))
 		if err != nil {
 			startDate = startDate.Add(-48 * time.Hour)
 			stateValue = startDate.String()
-			logger.Info("Problem parsing initial_index_column_start_value date", zap.String("queryId", dbquery.QueryId))
+			logger.Info("Problem parsing initial_index_value date", zap.String("queryId", dbquery.QueryId))
 			logger.Info("Check collector config file. Considering default now - 48hrs for:", zap.String("queryId", dbquery.QueryId))
 		} else {
 			startDate = startDate.Add(-1 * time.Second)
@@ -72,84 +163,303 @@ func getStateValueTIMESTAMP(dbquery *DBQueries, logger *zap.Logger) string {
 	return stateValue
 }
 
+// GetState returns the saved state of the query.
+// The initial_index_value (or its default) is only used when there's no saved state yet,
+// otherwise the records fetched since it was configured would be fetched again on every collection.
 func GetState(dbquery *DBQueries, logger *zap.Logger) string {
 	var storeFilename = getStateStoreFilename(dbquery)
-	var stateValue = ""
 
-	_, err := os.Stat(storeFilename)
-	if errors.Is(err, os.ErrNotExist) {
-		// State File does not exists, so use start value as mentioned in YAML configuration.
+	csvFile, err := os.Open(storeFilename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error opening state file, using start value as mentioned in collector config file.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		}
+		// State file does not exist, so use start value as mentioned in YAML configuration.
 		// If start value is not configured, we set some default value to it
-		if dbquery.IndexColumnType == "NUMBER" {
-			return getStateValueNUMBER(dbquery, logger)
-		} else if dbquery.IndexColumnType == "TIMESTAMP" {
-			return getStateValueTIMESTAMP(dbquery, logger)
+		return getInitialStateValue(dbquery, logger)
+	}
+	defer csvFile.Close()
+
+	// Able to read state file, so extract state value.
+	// State is maintained in 4th column in csv file of now
+	reader := csv.NewReader(csvFile)
+	// state files saved before the query hash was added have only 4 columns
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil || len(records) < 2 || len(records[1]) < 4 {
+		logger.Error("Failed to read stateFile, using start value as mentioned in collector config file.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return getInitialStateValue(dbquery, logger)
+	}
+	// The state is a watermark of the saved query, which may not apply to the records of the changed query
+	if len(records[1]) >= 5 && records[1][4] != queryHash(dbquery) {
+		logger.Warn("Query changed since its state was saved, resetting the state to the start value as mentioned in collector config file.", zap.String("queryId", dbquery.QueryId))
+		return getInitialStateValue(dbquery, logger)
+	}
+	return records[1][3]
+}
+
+// queryHash returns the hex encoded SHA-256 hash of the query text saved with the state of the query.
+// The whitespace is normalized, so reformatting the query in the config doesn't reset the state.
+func queryHash(dbquery *DBQueries) string {
+	hash := sha256.Sum256([]byte(strings.Join(strings.Fields(dbquery.Query), " ")))
+	return hex.EncodeToString(hash[:])
+}
+
+func getInitialStateValue(dbquery *DBQueries, logger *zap.Logger) string {
+	if isCompositeIndex(dbquery) {
+		return getCompositeInitialStateValue(dbquery, logger)
+	} else if dbquery.IndexColumnType == "NUMBER" {
+		return getStateValueNUMBER(dbquery, logger)
+	} else if dbquery.IndexColumnType == "TIMESTAMP" {
+		return getStateValueTIMESTAMP(dbquery, logger)
+	}
+	return ""
+}
+
+// SaveState writes the state of the query atomically, the state file is replaced only once the new state is fully
+// written to disk, so a crash in the middle of a write cannot leave a torn state file behind.
+func SaveState(dbquery *DBQueries, stateValue string, logger *zap.Logger) error {
+	stateData := [][]string{
+		{"queryid", "indexcolumnname", "indexcolumntype", "statevalue", "queryhash"},
+		{dbquery.QueryId, dbquery.IndexColumnName, dbquery.IndexColumnType, stateValue, queryHash(dbquery)},
+	}
+	if isCompositeIndex(dbquery) {
+		stateData[1][1] = strings.Join(dbquery.IndexColumns, ",")
+		stateData[1][2] = strings.Join(dbquery.IndexColumnTypes, ",")
+	}
+	return writeStateFile(getStateStoreFilename(dbquery), stateData, dbquery, logger)
+}
+
+// writeStateFile replaces the state file with the data atomically.
+func writeStateFile(storeFilename string, stateData [][]string, dbquery *DBQueries, logger *zap.Logger) error {
+	dir := filepath.Dir(storeFilename)
+	csvFile, err := os.CreateTemp(dir, filepath.Base(storeFilename)+".tmp*")
+	if err != nil {
+		logger.Error("Failed in creating state file.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return err
+	}
+	tmpFilename := csvFile.Name()
+	// removing the temporary file fails once it's renamed, which is expected
+	defer os.Remove(tmpFilename)
+
+	csvwriter := csv.NewWriter(csvFile)
+	err = csvwriter.WriteAll(stateData)
+	if err == nil {
+		err = csvFile.Sync()
+	}
+	if closeErr := csvFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Error("Failed in writing in state file.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return err
+	}
+
+	if err := os.Rename(tmpFilename, storeFilename); err != nil {
+		logger.Error("Failed in replacing state file.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return err
+	}
+	syncDir(dir, logger)
+	return nil
+}
+
+// syncDir flushes the directory entry of a renamed state file, so the rename survives a crash as well.
+func syncDir(dir string, logger *zap.Logger) {
+	d, err := os.Open(dir)
+	if err != nil {
+		logger.Debug("Failed to open state file directory for syncing", zap.Error(err))
+		return
+	}
+	defer d.Close()
+	// syncing a directory is not supported on every platform, e.g. on Windows, so it's best effort
+	if err := d.Sync(); err != nil {
+		logger.Debug("Failed to sync state file directory", zap.Error(err))
+	}
+}
+
+// stateLocks holds a mutex for each state file.
+var stateLocks sync.Map
+
+// lockState locks the state of the query and returns the function unlocking it.
+// The state has to be locked for the whole read, query and save cycle, so concurrent collections of the same query
+// don't fetch the same records twice or move the state backwards.
+func lockState(dbquery *DBQueries) func() {
+	return lockStateFile(getStateStoreFilename(dbquery))
+}
+
+func lockStateFile(storeFilename string) func() {
+	lock, _ := stateLocks.LoadOrStore(storeFilename, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+func getContentHashFilename(dbquery *DBQueries) string {
+	return queryFilePrefix(dbquery) + "_content_hash.csv"
+}
+
+// GetContentHash returns the saved hash of the last emitted result of the query, or an empty string if there's none.
+func GetContentHash(dbquery *DBQueries, logger *zap.Logger) string {
+	csvFile, err := os.Open(getContentHashFilename(dbquery))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error opening content hash file, the query result is emitted.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
 		}
-	} else {
-		// State file exists.
-		csvFile, err := os.Open(storeFilename)
-		if err != nil {
-			logger.Info("Error opening state file, using start value as mentioned in collector config file.")
-			if dbquery.IndexColumnType == "NUMBER" {
-				return getStateValueNUMBER(dbquery, logger)
-			} else if dbquery.IndexColumnType == "TIMESTAMP" {
-				return getStateValueTIMESTAMP(dbquery, logger)
-			}
-		} else {
-			// Able to read state file, so extract state value.
-			// State is maintained in 4th column in csv file of now
-			if dbquery.IndexColumnType == "NUMBER" {
-				configFileStateValue := getStateValueNUMBER(dbquery, logger)
-				reader := csv.NewReader(csvFile)
-				records, err := reader.ReadAll()
-				if err != nil {
-					logger.Error("Failed to read stateFile", zap.Error(err))
-				}
-				stateFileStateValue := records[1][3]
-				if configFileStateValue != "" && configFileStateValue != stateFileStateValue {
-					stateValue = configFileStateValue
-				} else {
-					stateValue = stateFileStateValue
-				}
-			} else if dbquery.IndexColumnType == "TIMESTAMP" {
-				configFileStateValue := getStateValueTIMESTAMP(dbquery, logger)
-				reader := csv.NewReader(csvFile)
-				records, err := reader.ReadAll()
-				if err != nil {
-					logger.Error("Failed to read stateFile", zap.Error(err))
-				}
-				stateFileStateValue := records[1][3]
-				if configFileStateValue != "" && configFileStateValue != stateFileStateValue {
-					stateValue = configFileStateValue
-				} else {
-					stateValue = stateFileStateValue
-				}
-			}
+		return ""
+	}
+	defer csvFile.Close()
+
+	records, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil || len(records) < 2 || len(records[1]) < 2 {
+		logger.Error("Failed to read content hash file, the query result is emitted.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return ""
+	}
+	return records[1][1]
+}
+
+// SaveContentHash writes the hash of the emitted result of the query atomically, like SaveState.
+func SaveContentHash(dbquery *DBQueries, contentHash string, logger *zap.Logger) error {
+	stateData := [][]string{
+		{"queryid", "contenthash"},
+		{dbquery.QueryId, contentHash},
+	}
+	return writeStateFile(getContentHashFilename(dbquery), stateData, dbquery, logger)
+}
+
+// lockContentHash locks the content hash of the query for the whole read, query and save cycle, like lockState.
+func lockContentHash(dbquery *DBQueries) func() {
+	return lockStateFile(getContentHashFilename(dbquery))
+}
+
+func getFreshnessFilename(dbquery *DBQueries) string {
+	return queryFilePrefix(dbquery) + "_freshness.csv"
+}
+
+// GetLastNewRecordsTime returns the saved time the query last fetched new records, ok is false if there's none.
+func GetLastNewRecordsTime(dbquery *DBQueries, logger *zap.Logger) (lastNewRecords time.Time, ok bool) {
+	csvFile, err := os.Open(getFreshnessFilename(dbquery))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error opening freshness file, the freshness is measured from now.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
 		}
+		return time.Time{}, false
 	}
-	return stateValue
+	defer csvFile.Close()
+
+	records, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil || len(records) < 2 || len(records[1]) < 2 {
+		logger.Error("Failed to read freshness file, the freshness is measured from now.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return time.Time{}, false
+	}
+	lastNewRecords, err = time.Parse(time.RFC3339Nano, records[1][1])
+	if err != nil {
+		logger.Error("Failed to parse freshness file, the freshness is measured from now.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return time.Time{}, false
+	}
+	return lastNewRecords, true
 }
 
-func SaveState(dbquery *DBQueries, stateValue string, logger *zap.Logger) {
-	var storeFilename = getStateStoreFilename(dbquery)
+// SaveLastNewRecordsTime writes the time the query last fetched new records atomically, like SaveState.
+func SaveLastNewRecordsTime(dbquery *DBQueries, lastNewRecords time.Time, logger *zap.Logger) error {
 	stateData := [][]string{
-		{"queryid", "indexcolumnname", "indexcolumntype", "statevalue"},
-		{dbquery.QueryId, dbquery.IndexColumnName, dbquery.IndexColumnType, stateValue},
+		{"queryid", "lastnewrecords"},
+		{dbquery.QueryId, lastNewRecords.UTC().Format(time.RFC3339Nano)},
 	}
+	return writeStateFile(getFreshnessFilename(dbquery), stateData, dbquery, logger)
+}
+
+// lockFreshness locks the freshness state of the query for the whole read, check and save cycle, like lockState.
+func lockFreshness(dbquery *DBQueries) func() {
+	return lockStateFile(getFreshnessFilename(dbquery))
+}
 
-	csvFile, err := os.Create(storeFilename)
+func getSnapshotFilename(dbquery *DBQueries) string {
+	return queryFilePrefix(dbquery) + "_snapshot.csv"
+}
+
+// GetSnapshot returns the saved hashes of the records of the last result of the query keyed by their primary key, ok is false if there's none.
+func GetSnapshot(dbquery *DBQueries, logger *zap.Logger) (snapshot map[string]string, ok bool) {
+	csvFile, err := os.Open(getSnapshotFilename(dbquery))
 	if err != nil {
-		logger.Error("Failed in creating state file.", zap.Error(err))
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error opening snapshot file, all the records are emitted.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		}
+		return nil, false
 	}
+	defer csvFile.Close()
 
-	csvwriter := csv.NewWriter(csvFile)
+	records, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil || len(records) < 1 {
+		logger.Error("Failed to read snapshot file, all the records are emitted.", zap.String("queryId", dbquery.QueryId), zap.Error(err))
+		return nil, false
+	}
+	snapshot = make(map[string]string, len(records)-1)
+	for _, record := range records[1:] {
+		if len(record) < 2 {
+			logger.Error("Failed to parse snapshot file, all the records are emitted.", zap.String("queryId", dbquery.QueryId))
+			return nil, false
+		}
+		snapshot[record[0]] = record[1]
+	}
+	return snapshot, true
+}
 
-	for _, empRow := range stateData {
-		err = csvwriter.Write(empRow)
-		if err != nil {
-			logger.Error("Failed in writing in state file.", zap.Error(err))
+// SaveSnapshot writes the hashes of the records of the last result of the query atomically, like SaveState.
+func SaveSnapshot(dbquery *DBQueries, snapshot map[string]string, logger *zap.Logger) error {
+	primaryKeys := make([]string, 0, len(snapshot))
+	for primaryKey := range snapshot {
+		primaryKeys = append(primaryKeys, primaryKey)
+	}
+	sort.Strings(primaryKeys)
+	stateData := make([][]string, 0, len(snapshot)+1)
+	stateData = append(stateData, []string{"primarykey", "hash"})
+	for _, primaryKey := range primaryKeys {
+		stateData = append(stateData, []string{primaryKey, snapshot[primaryKey]})
+	}
+	return writeStateFile(getSnapshotFilename(dbquery), stateData, dbquery, logger)
+}
+
+// lockSnapshot locks the snapshot of the query for the whole read, query and save cycle, like lockState.
+func lockSnapshot(dbquery *DBQueries) func() {
+	return lockStateFile(getSnapshotFilename(dbquery))
+}
+
+// binlogStateQuery identifies the binlog position in the logs of the state files.
+var binlogStateQuery = DBQueries{QueryId: "binlog_cdc"}
+
+func getBinlogPositionFilename(cfg *Config) string {
+	return strings.ReplaceAll(cfg.ID().String(), "/", "_") + "_binlog_position.csv"
+}
+
+// GetBinlogPosition returns the saved position the binlog of the receiver was read up to, ok is false if there's none.
+func GetBinlogPosition(cfg *Config, logger *zap.Logger) (position mysql.Position, ok bool) {
+	csvFile, err := os.Open(getBinlogPositionFilename(cfg))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Info("Error opening binlog position file, the binlog is read from the current position.", zap.Error(err))
 		}
+		return mysql.Position{}, false
+	}
+	defer csvFile.Close()
+
+	records, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil || len(records) < 2 || len(records[1]) < 2 {
+		logger.Error("Failed to read binlog position file, the binlog is read from the current position.", zap.Error(err))
+		return mysql.Position{}, false
+	}
+	pos, err := strconv.ParseUint(records[1][1], 10, 32)
+	if err != nil {
+		logger.Error("Failed to parse binlog position file, the binlog is read from the current position.", zap.Error(err))
+		return mysql.Position{}, false
+	}
+	return mysql.Position{Name: records[1][0], Pos: uint32(pos)}, true
+}
+
+// SaveBinlogPosition writes the position the binlog of the receiver was read up to atomically, like SaveState.
+func SaveBinlogPosition(cfg *Config, position mysql.Position, logger *zap.Logger) error {
+	stateData := [][]string{
+		{"file", "position"},
+		{position.Name, strconv.FormatUint(uint64(position.Pos), 10)},
 	}
-	csvwriter.Flush()
-	csvFile.Close()
+	return writeStateFile(getBinlogPositionFilename(cfg), stateData, &binlogStateQuery, logger)
 }
@@ -15,6 +15,9 @@ package mysqlrecordsreceiver
 
 import (
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -214,3 +217,124 @@ func TestValidSaveStateTIMESTAMP(t *testing.T) {
 	require.FileExists(t, "Q1_DateTime_TIMESTAMP.csv")
 	require.NoError(t, os.Remove("Q1_DateTime_TIMESTAMP.csv"))
 }
+
+func TestSaveStateReplacesStateFile(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", Query: "Select * from Persons", IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}
+	defer os.Remove("Q1_PersonID_NUMBER.csv")
+
+	require.NoError(t, SaveState(&dbquery, "5", logger))
+	require.NoError(t, SaveState(&dbquery, "7", logger))
+	require.EqualValues(t, "7", GetState(&dbquery, logger))
+
+	// no temporary files are left behind
+	tmpFiles, err := filepath.Glob("Q1_PersonID_NUMBER.csv.tmp*")
+	require.NoError(t, err)
+	require.Empty(t, tmpFiles)
+}
+
+func TestSaveStateConcurrently(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", Query: "Select * from Persons", IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}
+	defer os.Remove("Q1_PersonID_NUMBER.csv")
+
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(value int) {
+			defer wg.Done()
+			if err := SaveState(&dbquery, strconv.Itoa(value), logger); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// the state file is always one of the complete writes
+	state, err := strconv.Atoi(GetState(&dbquery, logger))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, state, 1)
+	require.LessOrEqual(t, state, 10)
+}
+
+func TestLockState(t *testing.T) {
+	q1 := DBQueries{QueryId: "Q1", IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}
+	q2 := DBQueries{QueryId: "Q2", IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}
+
+	unlock := lockState(&q1)
+
+	// the state of another query can be locked in the meantime
+	lockState(&q2)()
+
+	locked := make(chan struct{})
+	go func() {
+		lockState(&q1)()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("state of the same query was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("state wasn't unlocked")
+	}
+}
+
+func TestInitialIndexValue(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", IndexColumnName: "PersonID", IndexColumnType: "NUMBER", InitialIndexValue: "100"}
+	require.EqualValues(t, "99", getStateValueNUMBER(&dbquery, logger))
+
+	dbquery = DBQueries{QueryId: "Q1", IndexColumnName: "LoginTime", IndexColumnType: "TIMESTAMP", InitialIndexValue: "2022-08-01T10:00:00Z"}
+	require.EqualValues(t, "2022-08-01 09:59:59 +0000 UTC", getStateValueTIMESTAMP(&dbquery, logger))
+
+	dbquery.InitialIndexValue = "2022-08-01 10:00:00"
+	require.EqualValues(t, "2022-08-01 09:59:59 +0000 UTC", getStateValueTIMESTAMP(&dbquery, logger))
+	// the initial index value is in the time zone of the index column
+	dbquery.IndexColumnTimezone = "Europe/Warsaw"
+	require.EqualValues(t, "2022-08-01 09:59:59 +0200 CEST", getStateValueTIMESTAMP(&dbquery, logger))
+}
+
+func TestValidateInitialIndexValue(t *testing.T) {
+	require.NoError(t, validateInitialIndexValue(DBQueries{QueryId: "Q1"}))
+	require.NoError(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", IndexColumnName: "PersonID", IndexColumnType: "NUMBER", InitialIndexValue: "100"}))
+	require.NoError(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", IndexColumnName: "LoginTime", IndexColumnType: "TIMESTAMP", InitialIndexValue: "2022-08-01T10:00:00+02:00"}))
+	require.Error(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", IndexColumnName: "PersonID", IndexColumnType: "NUMBER", InitialIndexValue: "yesterday"}))
+	require.Error(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", IndexColumnName: "LoginTime", IndexColumnType: "TIMESTAMP", InitialIndexValue: "01/08/2022"}))
+	require.Error(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", InitialIndexValue: "100"}))
+	require.Error(t, validateInitialIndexValue(DBQueries{QueryId: "Q1", IndexColumnName: "PersonID", IndexColumnType: "NUMBER", InitialIndexValue: "100", InitialIndexColumnStartValue: "5"}))
+}
+
+func TestGetStateWChangedQuery(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from persons", IndexColumnName: "PersonID", IndexColumnType: "NUMBER", InitialIndexValue: "10"}
+	defer os.Remove(getStateStoreFilename(&dbquery))
+	require.NoError(t, SaveState(&dbquery, "42", logger))
+	require.EqualValues(t, "42", GetState(&dbquery, logger))
+
+	// reformatting the query keeps the state
+	dbquery.Query = "select *\n  from persons"
+	require.EqualValues(t, "42", GetState(&dbquery, logger))
+
+	// the state of a changed query is reset to the start value
+	dbquery.Query = "select * from persons where active = 1"
+	require.EqualValues(t, "9", GetState(&dbquery, logger))
+	require.NoError(t, SaveState(&dbquery, "50", logger))
+	require.EqualValues(t, "50", GetState(&dbquery, logger))
+}
+
+func TestGetStateWOQueryHash(t *testing.T) {
+	logger := zap.NewNop()
+	dbquery := DBQueries{QueryId: "Q1", Query: "select * from persons", IndexColumnName: "PersonID", IndexColumnType: "NUMBER"}
+	stateFile := getStateStoreFilename(&dbquery)
+	defer os.Remove(stateFile)
+	// state files saved before the query hash was added are still used
+	require.NoError(t, os.WriteFile(stateFile, []byte("queryid,indexcolumnname,indexcolumntype,statevalue\nQ1,PersonID,NUMBER,42\n"), 0600))
+	require.EqualValues(t, "42", GetState(&dbquery, logger))
+}
@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+//targetAttribute is the resource attribute with the name of the target the records were fetched from
+const targetAttribute = "mysql.target"
+
+//Target is one of the databases the queries of the receiver are run against, the options which are not set are the options of the receiver
+type Target struct {
+	//Name identifies the target, it's set as the mysql.target resource attribute of its records and prefixes the names of its state files
+	Name string `mapstructure:"name"`
+	//DBHost is the host of the target, it replaces dbhost and dbhosts of the receiver
+	DBHost string `mapstructure:"dbhost,omitempty"`
+	DBPort string `mapstructure:"dbport,omitempty"`
+	//Database is the database of the target the queries are run in
+	Database string `mapstructure:"database,omitempty"`
+}
+
+//This function validates the targets, their names have to be unique as they're part of the names of the state files
+func validateTargets(cfg *Config) error {
+	var err error
+	names := make(map[string]bool)
+	for _, target := range cfg.Targets {
+		switch {
+		case len(strings.TrimSpace(target.Name)) == 0:
+			err = multierr.Append(err, errors.New("targets cannot contain a target without a name"))
+		case strings.ContainsAny(target.Name, `/\`):
+			err = multierr.Append(err, fmt.Errorf("target name '%s' cannot contain a path separator", target.Name))
+		case names[target.Name]:
+			err = multierr.Append(err, fmt.Errorf("target name '%s' is not unique", target.Name))
+		}
+		names[target.Name] = true
+		if len(target.DBHost) != 0 && (cfg.Transport == "unix" || cfg.AuthenticationMode == "SocketAuth" || cfg.AuthenticationMode == "GCPCloudSQLIAM") {
+			err = multierr.Append(err, fmt.Errorf("dbhost of target '%s' cannot be used with the 'unix' transport or authentication_mode : '%s'", target.Name, cfg.AuthenticationMode))
+		}
+	}
+	return err
+}
+
+//This function returns whether all the targets set the option, so the option of the receiver isn't required
+func targetsSet(cfg *Config, option func(target Target) string) bool {
+	if len(cfg.Targets) == 0 {
+		return false
+	}
+	for _, target := range cfg.Targets {
+		if len(option(target)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//This function returns the config of the receiver of the target, with the options of the target and the name of the target in its id
+//The id keeps the dialers, the TLS configs and the handed off state of the targets apart
+func targetConfig(cfg *Config, target Target) *Config {
+	targetCfg := *cfg
+	targetCfg.Targets = nil
+	name := target.Name
+	if len(cfg.ID().Name()) != 0 {
+		name = cfg.ID().Name() + "/" + target.Name
+	}
+	targetCfg.SetIDName(name)
+	if len(target.DBHost) != 0 {
+		targetCfg.DBHost = target.DBHost
+		targetCfg.DBHosts = nil
+	}
+	if len(target.DBPort) != 0 {
+		targetCfg.DBPort = target.DBPort
+	}
+	if len(target.Database) != 0 {
+		targetCfg.Database = target.Database
+	}
+	targetCfg.DBQueries = make([]DBQueries, len(cfg.DBQueries))
+	for i, dbquery := range cfg.DBQueries {
+		dbquery.target = target.Name
+		targetCfg.DBQueries[i] = dbquery
+	}
+	return &targetCfg
+}
+
+//targetsReceiver runs the queries against each of the targets with a receiver per target
+type targetsReceiver struct {
+	receivers []*mySQLReceiver
+	logger    *zap.Logger
+}
+
+//This function creates a receiver for each target with newReceiver
+func newTargetsReceiver(params component.ReceiverCreateSettings, conf *Config, newReceiver func(conf *Config) *mySQLReceiver) *targetsReceiver {
+	r := &targetsReceiver{logger: params.Logger}
+	for _, target := range conf.Targets {
+		receiver := newReceiver(targetConfig(conf, target))
+		receiver.logger = params.Logger.With(zap.String("target", target.Name))
+		receiver.target = target.Name
+		r.receivers = append(r.receivers, receiver)
+	}
+	return r
+}
+
+//The targets are started one after another, the started targets and the failed one are shut down when a target fails to start
+func (r *targetsReceiver) Start(ctx context.Context, host component.Host) error {
+	for i, receiver := range r.receivers {
+		if err := receiver.Start(ctx, host); err != nil {
+			for _, started := range r.receivers[:i+1] {
+				if shutdownErr := started.Shutdown(ctx); shutdownErr != nil {
+					r.logger.Error("Failed to shut down target", zap.String("target", started.target), zap.Error(shutdownErr))
+				}
+			}
+			return fmt.Errorf("failed to start target %s: %w", receiver.target, err)
+		}
+	}
+	return nil
+}
+
+func (r *targetsReceiver) Shutdown(ctx context.Context) error {
+	var err error
+	for _, receiver := range r.receivers {
+		err = multierr.Append(err, receiver.Shutdown(ctx))
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestTargetConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.DBHosts = []string{"primary:3306", "replica:3306"}
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders"}}
+	cfg.Targets = []Target{{Name: "eu", DBHost: "eu.example.com", DBPort: "3307"}, {Name: "us", Database: "sales_us"}}
+
+	eu := targetConfig(cfg, cfg.Targets[0])
+	require.Equal(t, config.NewComponentIDWithName(typeStr, "eu"), eu.ID())
+	require.Equal(t, "eu.example.com", eu.DBHost)
+	require.Equal(t, "3307", eu.DBPort)
+	require.Empty(t, eu.DBHosts)
+	require.Equal(t, "sales", eu.Database)
+	require.Empty(t, eu.Targets)
+	require.Equal(t, "eu", eu.DBQueries[0].target)
+
+	us := targetConfig(cfg, cfg.Targets[1])
+	require.Equal(t, []string{"primary:3306", "replica:3306"}, us.DBHosts)
+	require.Equal(t, "sales_us", us.Database)
+	require.Equal(t, "us", us.DBQueries[0].target)
+
+	// the config of the receiver isn't changed
+	require.Equal(t, config.NewComponentID(typeStr), cfg.ID())
+	require.Empty(t, cfg.DBQueries[0].target)
+
+	cfg.SetIDName("orders")
+	require.Equal(t, config.NewComponentIDWithName(typeStr, "orders/eu"), targetConfig(cfg, cfg.Targets[0]).ID())
+}
+
+func TestValidConfigWTargets(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Password = "userpass"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders"}}
+	cfg.Targets = []Target{{Name: "eu", DBHost: "eu.example.com", Database: "sales"}, {Name: "us", DBHost: "us.example.com", Database: "sales"}}
+	require.NoError(t, cfg.Validate())
+
+	// the options of the receiver are required when a target doesn't set them
+	cfg.Targets = append(cfg.Targets, Target{Name: "apac"})
+	require.Error(t, cfg.Validate())
+	cfg.DBHost = "localhost"
+	cfg.Database = "sales"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateTargets(t *testing.T) {
+	cfg := &Config{AuthenticationMode: "BasicAuth", Targets: []Target{{Name: "eu"}, {Name: "us"}}}
+	require.NoError(t, validateTargets(cfg))
+
+	cfg.Targets = []Target{{Name: "eu"}, {Name: "eu"}}
+	require.EqualError(t, validateTargets(cfg), "target name 'eu' is not unique")
+
+	cfg.Targets = []Target{{Name: " "}}
+	require.EqualError(t, validateTargets(cfg), "targets cannot contain a target without a name")
+
+	cfg.Targets = []Target{{Name: "eu/west"}}
+	require.EqualError(t, validateTargets(cfg), "target name 'eu/west' cannot contain a path separator")
+
+	cfg = &Config{AuthenticationMode: "SocketAuth", Targets: []Target{{Name: "eu", DBHost: "eu.example.com"}}}
+	require.EqualError(t, validateTargets(cfg), "dbhost of target 'eu' cannot be used with the 'unix' transport or authentication_mode : 'SocketAuth'")
+}
+
+func TestNewReceiverWTargets(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Database = "sales"
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders"}}
+	cfg.Targets = []Target{{Name: "eu", DBHost: "eu.example.com"}, {Name: "us", DBHost: "us.example.com"}}
+
+	receiver, err := newMySQLReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	targets, ok := receiver.(*targetsReceiver)
+	require.True(t, ok)
+	require.Len(t, targets.receivers, 2)
+	for i, name := range []string{"eu", "us"} {
+		require.Equal(t, name, targets.receivers[i].target)
+		require.Equal(t, name+".example.com", targets.receivers[i].config.DBHost)
+		require.NotNil(t, targets.receivers[i].consumer)
+	}
+
+	metricsReceiver, err := newMySQLMetricsReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.IsType(t, &targetsReceiver{}, metricsReceiver)
+}
+
+func TestSetResourceAttributesWTarget(t *testing.T) {
+	receiver := &mySQLReceiver{config: &Config{DBDriver: dbDriverMySQL, DBHost: "eu.example.com", Database: "sales"}, target: "eu"}
+	attrs := pcommon.NewMap()
+	receiver.setResourceAttributes(attrs)
+	require.Equal(t, map[string]interface{}{
+		dbSystemAttribute:    "mysql",
+		dbNameAttribute:      "sales",
+		netPeerNameAttribute: "eu.example.com",
+		targetAttribute:      "eu",
+	}, attrs.AsRaw())
+}
+
+func TestTargetStateFilenames(t *testing.T) {
+	dbquery := DBQueries{QueryId: "Q1", IndexColumnName: "OrderID", IndexColumnType: "NUMBER"}
+	require.Equal(t, "Q1_OrderID_NUMBER.csv", getStateStoreFilename(&dbquery))
+
+	dbquery.target = "eu"
+	require.Equal(t, "eu_Q1_OrderID_NUMBER.csv", getStateStoreFilename(&dbquery))
+	require.Equal(t, "eu_Q1_content_hash.csv", getContentHashFilename(&dbquery))
+	require.Equal(t, "eu_Q1_freshness.csv", getFreshnessFilename(&dbquery))
+}
+
+func TestStartTargetsWFailedTarget(t *testing.T) {
+	cfg := newTestTLSConfig(t)
+	require.NoError(t, os.Remove(cfg.TLS.CAFile))
+	cfg.DBQueries = []DBQueries{{QueryId: "Q1", Query: "select * from orders"}}
+	cfg.Targets = []Target{{Name: "eu", DBHost: "eu.example.com"}, {Name: "us", DBHost: "us.example.com"}}
+
+	receiver, err := newMySQLReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	// the receivers which failed to start are shut down without their client
+	require.Error(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}
@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/scrapererror"
+)
+
+// telemetryDataFormat is the format of the received data reported with the receiver telemetry
+const telemetryDataFormat = "sql"
+
+func init() {
+	err := view.Register(viewQueryDuration, viewQueryRowsFetched, viewWatermarkAge)
+	if err != nil {
+		fmt.Printf("Error registering mysql records receiver's views: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var (
+	tagReceiverKey, _ = tag.NewKey("receiver")
+	tagQueryIDKey, _  = tag.NewKey("query_id")
+
+	mQueryDuration    = stats.Int64("mysqlrecords_query_duration", "Execution time of a query, including the fetching of its records", stats.UnitMilliseconds)
+	mQueryRowsFetched = stats.Int64("mysqlrecords_query_rows_fetched", "Number of records fetched by the last run of a query", stats.UnitDimensionless)
+	mWatermarkAge     = stats.Int64("mysqlrecords_watermark_age", "Time between now and the saved state of a query with a 'TIMESTAMP' index column", stats.UnitSeconds)
+)
+
+var viewQueryDuration = &view.View{
+	Name:        mQueryDuration.Name(),
+	Description: mQueryDuration.Description(),
+	Measure:     mQueryDuration,
+	TagKeys:     []tag.Key{tagReceiverKey, tagQueryIDKey},
+	// from 10ms to 10m
+	Aggregation: view.Distribution(10, 25, 50, 100, 250, 500, 1_000, 2_500, 5_000, 10_000, 30_000, 60_000, 120_000, 300_000, 600_000),
+}
+
+var viewQueryRowsFetched = &view.View{
+	Name:        mQueryRowsFetched.Name(),
+	Description: mQueryRowsFetched.Description(),
+	Measure:     mQueryRowsFetched,
+	TagKeys:     []tag.Key{tagReceiverKey, tagQueryIDKey},
+	Aggregation: view.LastValue(),
+}
+
+var viewWatermarkAge = &view.View{
+	Name:        mWatermarkAge.Name(),
+	Description: mWatermarkAge.Description(),
+	Measure:     mWatermarkAge,
+	TagKeys:     []tag.Key{tagReceiverKey, tagQueryIDKey},
+	Aggregation: view.LastValue(),
+}
+
+// recordQueryStats records the execution time and the number of fetched records of a successful run of the query,
+// and the age of its state when its first index column is a 'TIMESTAMP' column, so ingestion falling behind can be alerted on.
+func (m *mySQLReceiver) recordQueryStats(query *DBQueries, duration time.Duration, fetched int, now time.Time) {
+	tags := []tag.Mutator{tag.Upsert(tagReceiverKey, m.config.ID().String()), tag.Upsert(tagQueryIDKey, query.QueryId)}
+	measurements := []stats.Measurement{mQueryDuration.M(duration.Milliseconds()), mQueryRowsFetched.M(int64(fetched))}
+	if age, ok := watermarkAge(query, GetState(query, m.logger), now); ok {
+		measurements = append(measurements, mWatermarkAge.M(int64(age.Seconds())))
+	}
+	_ = stats.RecordWithTags(context.Background(), tags, measurements...)
+}
+
+// watermarkAge returns the time between now and the state of the query, the state of a composite index is aged by its first column.
+// It's only known for the incremental queries whose first index column is a 'TIMESTAMP' column.
+func watermarkAge(query *DBQueries, state string, now time.Time) (time.Duration, bool) {
+	if !isIncrementalQuery(query) || indexColumns(query)[0].columnType != "TIMESTAMP" {
+		return 0, false
+	}
+	if isCompositeIndex(query) {
+		var values []string
+		if err := json.Unmarshal([]byte(state), &values); err != nil || len(values) == 0 {
+			return 0, false
+		}
+		state = values[0]
+	}
+	watermark, ok := parseStateTimestamp(state, indexColumnLocation(query))
+	if !ok {
+		return 0, false
+	}
+	age := now.Sub(watermark)
+	if age < 0 {
+		// the clocks of the database and the collector are not in sync
+		age = 0
+	}
+	return age, true
+}
+
+// newObsReceiver creates the receiver telemetry of the receiver, reporting the accepted and refused records.
+func newObsReceiver(params component.ReceiverCreateSettings, conf *Config) *obsreport.Receiver {
+	return obsreport.NewReceiver(obsreport.ReceiverSettings{
+		ReceiverID:             conf.ID(),
+		Transport:              conf.DBDriver,
+		ReceiverCreateSettings: params,
+	})
+}
+
+// consumeLogs passes the logs to the logs consumer, the log records are reported as accepted or refused by the consumer.
+func (m *mySQLReceiver) consumeLogs(ctx context.Context, logs plog.Logs) error {
+	if m.obsrecv == nil {
+		return m.consumer.ConsumeLogs(ctx, logs)
+	}
+	obsCtx := m.obsrecv.StartLogsOp(ctx)
+	err := m.consumer.ConsumeLogs(obsCtx, logs)
+	m.obsrecv.EndLogsOp(obsCtx, telemetryDataFormat, logs.LogRecordCount(), err)
+	return err
+}
+
+// consumeMetrics passes the metrics to the metrics consumer, the data points are reported as accepted or refused by the consumer.
+func (m *mySQLReceiver) consumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if m.obsrecv == nil {
+		return m.nextMetrics.ConsumeMetrics(ctx, md)
+	}
+	obsCtx := m.obsrecv.StartMetricsOp(ctx)
+	err := m.nextMetrics.ConsumeMetrics(obsCtx, md)
+	m.obsrecv.EndMetricsOp(obsCtx, telemetryDataFormat, md.DataPointCount(), err)
+	return err
+}
+
+// startScrape starts reporting a run of the query as a scrape of the scraper named after the query id.
+// It returns the context of the scrape and the function ending it with the number of fetched records and the error of the run.
+// Only the runs of the metrics receiver are reported as scrapes, the log records are reported by consumeLogs.
+func (m *mySQLReceiver) startScrape(ctx context.Context, queryid string) (context.Context, func(fetched int, err error)) {
+	if m.obsrecv == nil || m.nextMetrics == nil {
+		return ctx, func(int, error) {}
+	}
+	scraper := m.scraper(queryid)
+	scrapeCtx := scraper.StartMetricsOp(ctx)
+	return scrapeCtx, func(fetched int, err error) {
+		//A failed run of the query is reported as one errored point, as it fetches no records
+		if err != nil {
+			err = scrapererror.NewPartialScrapeError(err, 1)
+		}
+		scraper.EndMetricsOp(scrapeCtx, fetched, err)
+	}
+}
+
+// scraper returns the scraper of the query, it's created on the first run of the query.
+func (m *mySQLReceiver) scraper(queryid string) *obsreport.Scraper {
+	m.scrapersLock.Lock()
+	defer m.scrapersLock.Unlock()
+	if scraper, ok := m.scrapers[queryid]; ok {
+		return scraper
+	}
+	if m.scrapers == nil {
+		m.scrapers = make(map[string]*obsreport.Scraper)
+	}
+	scraper := obsreport.NewScraper(obsreport.ScraperSettings{
+		ReceiverID:             m.config.ID(),
+		Scraper:                config.NewComponentID(config.Type(queryid)),
+		ReceiverCreateSettings: m.createSettings,
+	})
+	m.scrapers[queryid] = scraper
+	return scraper
+}
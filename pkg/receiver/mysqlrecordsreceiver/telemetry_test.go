@@ -0,0 +1,220 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+// telemetryViews registers sum views over the obsreport measures, the measures are looked up by their names
+func telemetryViews(t *testing.T, names ...string) func(name string, tags map[string]string) int64 {
+	views := make(map[string]*view.View, len(names))
+	for _, name := range names {
+		views[name] = &view.View{
+			Name:        "test_" + name,
+			Measure:     stats.Int64(name, "", stats.UnitDimensionless),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tag.MustNewKey("receiver"), tag.MustNewKey("scraper")},
+		}
+		require.NoError(t, view.Register(views[name]))
+	}
+	t.Cleanup(func() {
+		for _, v := range views {
+			view.Unregister(v)
+		}
+	})
+	return func(name string, tags map[string]string) int64 {
+		rows, err := view.RetrieveData(views[name].Name)
+		require.NoError(t, err)
+		for _, row := range rows {
+			matched := 0
+			for _, rowTag := range row.Tags {
+				if tags[rowTag.Key.Name()] == rowTag.Value {
+					matched++
+				}
+			}
+			if matched == len(tags) {
+				return int64(row.Data.(*view.SumData).Value)
+			}
+		}
+		return 0
+	}
+}
+
+func TestReceiverTelemetry(t *testing.T) {
+	measured := telemetryViews(t, "receiver/accepted_log_records", "scraper/scraped_metric_points", "scraper/errored_metric_points")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Reconnect = testReconnect
+	params := componenttest.NewNopReceiverCreateSettings()
+	sink := &consumertest.LogsSink{}
+	sqlclient := &mockClient{
+		records:   []string{`{"OrderID":"1"}`, `{"OrderID":"2"}`},
+		queryErrs: []error{&mysql.MySQLError{Number: 1146, Message: "Table 'app.orders' doesn't exist"}},
+	}
+	receiver := &mySQLReceiver{
+		config:         cfg,
+		consumer:       sink,
+		logger:         zap.NewNop(),
+		sqlclient:      sqlclient,
+		obsrecv:        newObsReceiver(params, cfg),
+		createSettings: params,
+	}
+
+	records := make(chan queryRecord, 10)
+	require.Zero(t, receiver.produceQuery(records, &DBQueries{QueryId: "Q1", Query: "select * from orders"}, time.Now(), context.Background()))
+	require.Equal(t, 2, receiver.produceQuery(records, &DBQueries{QueryId: "Q2", Query: "select * from orders"}, time.Now(), context.Background()))
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	require.Equal(t, 2, sink.LogRecordCount())
+	receiverTags := map[string]string{"receiver": "mysqlrecords"}
+	require.Equal(t, int64(2), measured("receiver/accepted_log_records", receiverTags))
+	// the log records are not reported as scraped metric points
+	require.Equal(t, int64(0), measured("scraper/errored_metric_points", map[string]string{"receiver": "mysqlrecords", "scraper": "Q1"}))
+	require.Equal(t, int64(0), measured("scraper/scraped_metric_points", map[string]string{"receiver": "mysqlrecords", "scraper": "Q2"}))
+}
+
+func TestMetricsReceiverScrapeTelemetry(t *testing.T) {
+	measured := telemetryViews(t, "scraper/scraped_metric_points", "scraper/errored_metric_points")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Reconnect = testReconnect
+	params := componenttest.NewNopReceiverCreateSettings()
+	sqlclient := &mockClient{
+		records:   []string{`{"OrderID":"1"}`, `{"OrderID":"2"}`},
+		queryErrs: []error{&mysql.MySQLError{Number: 1146, Message: "Table 'app.orders' doesn't exist"}},
+	}
+	receiver := &mySQLReceiver{
+		config:         cfg,
+		nextMetrics:    consumertest.NewNop(),
+		logger:         zap.NewNop(),
+		sqlclient:      sqlclient,
+		obsrecv:        newObsReceiver(params, cfg),
+		createSettings: params,
+	}
+
+	records := make(chan queryRecord, 10)
+	require.Zero(t, receiver.produceQuery(records, &DBQueries{QueryId: "Q1", Query: "select * from orders"}, time.Now(), context.Background()))
+	require.Equal(t, 2, receiver.produceQuery(records, &DBQueries{QueryId: "Q2", Query: "select * from orders"}, time.Now(), context.Background()))
+	require.Equal(t, 2, receiver.produceQuery(records, &DBQueries{QueryId: "Q2", Query: "select * from orders"}, time.Now(), context.Background()))
+
+	// the scraper of a query is created once and reports all of its runs
+	require.Len(t, receiver.scrapers, 2)
+	require.Equal(t, int64(1), measured("scraper/errored_metric_points", map[string]string{"receiver": "mysqlrecords", "scraper": "Q1"}))
+	require.Equal(t, int64(0), measured("scraper/scraped_metric_points", map[string]string{"receiver": "mysqlrecords", "scraper": "Q1"}))
+	require.Equal(t, int64(4), measured("scraper/scraped_metric_points", map[string]string{"receiver": "mysqlrecords", "scraper": "Q2"}))
+}
+
+func TestReceiverTelemetryWRefusedLogs(t *testing.T) {
+	measured := telemetryViews(t, "receiver/accepted_log_records", "receiver/refused_log_records")
+	cfg := createDefaultConfig().(*Config)
+	receiver := &mySQLReceiver{
+		config:   cfg,
+		consumer: consumertest.NewErr(mysql.ErrInvalidConn),
+		logger:   zap.NewNop(),
+		obsrecv:  newObsReceiver(componenttest.NewNopReceiverCreateSettings(), cfg),
+	}
+
+	records := make(chan queryRecord, 1)
+	records <- queryRecord{body: `{"OrderID":"1"}`}
+	close(records)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(records, 0, wg, context.Background())
+
+	receiverTags := map[string]string{"receiver": "mysqlrecords"}
+	require.Equal(t, int64(0), measured("receiver/accepted_log_records", receiverTags))
+	require.Equal(t, int64(1), measured("receiver/refused_log_records", receiverTags))
+}
+
+func queryStatsRow(t *testing.T, viewName string, queryid string) *view.Row {
+	rows, err := view.RetrieveData(viewName)
+	require.NoError(t, err)
+	for _, row := range rows {
+		for _, rowTag := range row.Tags {
+			if rowTag.Key == tagQueryIDKey && rowTag.Value == queryid {
+				return row
+			}
+		}
+	}
+	return nil
+}
+
+func TestRecordQueryStats(t *testing.T) {
+	dbquery := DBQueries{QueryId: "QStats", Query: "select * from orders", IndexColumnName: "CreatedAt", IndexColumnType: "TIMESTAMP"}
+	require.NoError(t, SaveState(&dbquery, "2022-08-01 10:00:00", zap.NewNop()))
+	defer os.Remove(getStateStoreFilename(&dbquery))
+	receiver := &mySQLReceiver{config: createDefaultConfig().(*Config), logger: zap.NewNop()}
+
+	receiver.recordQueryStats(&dbquery, 1500*time.Millisecond, 3, time.Date(2022, 8, 1, 10, 5, 0, 0, time.UTC))
+
+	duration := queryStatsRow(t, viewQueryDuration.Name, "QStats")
+	require.NotNil(t, duration)
+	require.Contains(t, duration.Tags, tag.Tag{Key: tagReceiverKey, Value: "mysqlrecords"})
+	require.EqualValues(t, 1, duration.Data.(*view.DistributionData).Count)
+	require.EqualValues(t, 1500, duration.Data.(*view.DistributionData).Max)
+	rows := queryStatsRow(t, viewQueryRowsFetched.Name, "QStats")
+	require.NotNil(t, rows)
+	require.EqualValues(t, 3, rows.Data.(*view.LastValueData).Value)
+	age := queryStatsRow(t, viewWatermarkAge.Name, "QStats")
+	require.NotNil(t, age)
+	require.EqualValues(t, 300, age.Data.(*view.LastValueData).Value)
+}
+
+func TestWatermarkAge(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 5, 0, 0, time.UTC)
+	timestampQuery := &DBQueries{IndexColumnName: "CreatedAt", IndexColumnType: "TIMESTAMP"}
+
+	age, ok := watermarkAge(timestampQuery, "2022-08-01 10:00:00", now)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Minute, age)
+	age, ok = watermarkAge(timestampQuery, "2022-07-30 10:05:00.123 +0000 UTC m=+0.001", now)
+	require.True(t, ok)
+	require.Equal(t, 48*time.Hour-123*time.Millisecond, age)
+	// a state ahead of the collector's clock is aged zero
+	age, ok = watermarkAge(timestampQuery, "2022-08-01 10:06:00", now)
+	require.True(t, ok)
+	require.Zero(t, age)
+
+	age, ok = watermarkAge(&DBQueries{IndexColumnName: "CreatedAt", IndexColumnType: "TIMESTAMP", IndexColumnTimezone: "Europe/Warsaw"}, "2022-08-01 12:00:00", now)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Minute, age)
+
+	age, ok = watermarkAge(&DBQueries{IndexColumns: []string{"CreatedAt", "OrderID"}, IndexColumnTypes: []string{"TIMESTAMP", "NUMBER"}}, `["2022-08-01 09:05:00","7"]`, now)
+	require.True(t, ok)
+	require.Equal(t, time.Hour, age)
+
+	_, ok = watermarkAge(&DBQueries{IndexColumns: []string{"OrderID", "CreatedAt"}, IndexColumnTypes: []string{"NUMBER", "TIMESTAMP"}}, `["7","2022-08-01 09:05:00"]`, now)
+	require.False(t, ok)
+	_, ok = watermarkAge(&DBQueries{IndexColumnName: "OrderID", IndexColumnType: "NUMBER"}, "7", now)
+	require.False(t, ok)
+	_, ok = watermarkAge(&DBQueries{}, "", now)
+	require.False(t, ok)
+	_, ok = watermarkAge(timestampQuery, "not a timestamp", now)
+	require.False(t, ok)
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// epochTimestampUnits are the units of the timestamp_format values of numeric timestamps since the Unix epoch.
+var epochTimestampUnits = map[string]time.Duration{
+	"unix":    time.Second,
+	"unix_ms": time.Millisecond,
+	"unix_us": time.Microsecond,
+	"unix_ns": time.Nanosecond,
+}
+
+// timestampColumn sets the timestamp of the log records of a query by the value of its timestamp_column.
+type timestampColumn struct {
+	column string
+	format string
+	loc    *time.Location
+}
+
+// newTimestampColumn returns the timestamp column of the query, or nil when timestamp_column is not set.
+// The time zone is validated with the config, so UTC is used if it cannot be loaded.
+func newTimestampColumn(dbquery *DBQueries) *timestampColumn {
+	if len(dbquery.TimestampColumn) == 0 {
+		return nil
+	}
+	loc := time.UTC
+	if len(dbquery.TimestampTimezone) != 0 {
+		if l, err := time.LoadLocation(dbquery.TimestampTimezone); err == nil {
+			loc = l
+		}
+	}
+	return &timestampColumn{column: dbquery.TimestampColumn, format: dbquery.TimestampFormat, loc: loc}
+}
+
+// parse parses the value of the timestamp column, the values without a time zone are in the time zone of the column.
+// Without timestamp_format, the values are parsed in the formats of the DATE, DATETIME and TIMESTAMP columns returned by the drivers.
+func (c *timestampColumn) parse(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if unit, ok := epochTimestampUnits[c.format]; ok {
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, epoch*int64(unit)), nil
+	}
+	if len(c.format) != 0 {
+		return time.ParseInLocation(c.format, value, c.loc)
+	}
+	if t, ok := parseStateTimestamp(value, c.loc); ok {
+		return t, nil
+	}
+	return time.Time{}, errors.New("the value is not a date and time")
+}
+
+// setTimestamp sets the value of the timestamp column of the record as the timestamp of the log record.
+// The timestamp is left unset for NULL values and values which cannot be parsed.
+func (m *mySQLReceiver) setTimestamp(lr plog.LogRecord, body string, column *timestampColumn) {
+	columns, err := readRecord(body)
+	if err != nil {
+		m.logger.Error("Failed to read record, emitting it without timestamp", zap.Error(err))
+		return
+	}
+	value, ok := columns[column.column]
+	if !ok || newNullFormat(m.config).isNull(value) {
+		return
+	}
+	timestamp, err := column.parse(*value)
+	if err != nil {
+		m.logger.Warn("Failed to parse the timestamp column, emitting the record without timestamp", zap.String("column", column.column), zap.String("value", *value), zap.Error(err))
+		return
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+}
+
+func validateTimestampColumn(dbquery DBQueries) error {
+	if len(dbquery.TimestampColumn) == 0 {
+		if len(dbquery.TimestampFormat) != 0 || len(dbquery.TimestampTimezone) != 0 {
+			return errors.New("timestamp_format and timestamp_timezone can only be used with timestamp_column")
+		}
+		return nil
+	}
+	if len(dbquery.MetricName) != 0 {
+		return errors.New("timestamp_column cannot be used with metric_name")
+	}
+	if _, epoch := epochTimestampUnits[dbquery.TimestampFormat]; epoch && len(dbquery.TimestampTimezone) != 0 {
+		return errors.New("timestamp_timezone cannot be used with the Unix epoch timestamp_format")
+	}
+	if len(dbquery.TimestampTimezone) != 0 {
+		if _, err := time.LoadLocation(dbquery.TimestampTimezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestParseTimestampColumn(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	require.NoError(t, err)
+	testcases := []struct {
+		dbquery  DBQueries
+		value    string
+		expected time.Time
+	}{
+		{dbquery: DBQueries{}, value: "2022-08-01 10:00:00", expected: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)},
+		{dbquery: DBQueries{}, value: "2022-08-01T10:00:00.5+02:00", expected: time.Date(2022, 8, 1, 8, 0, 0, 500000000, time.UTC)},
+		{dbquery: DBQueries{TimestampTimezone: "Europe/Warsaw"}, value: "2022-08-01 10:00:00", expected: time.Date(2022, 8, 1, 10, 0, 0, 0, warsaw)},
+		{dbquery: DBQueries{TimestampFormat: "02/01/2006 15:04"}, value: "01/08/2022 10:00", expected: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)},
+		{dbquery: DBQueries{TimestampFormat: "unix"}, value: "1659348000", expected: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)},
+		{dbquery: DBQueries{TimestampFormat: "unix_ms"}, value: "1659348000123", expected: time.Date(2022, 8, 1, 10, 0, 0, 123000000, time.UTC)},
+	}
+	for _, tc := range testcases {
+		tc.dbquery.TimestampColumn = "created_at"
+		timestamp, err := newTimestampColumn(&tc.dbquery).parse(tc.value)
+		require.NoError(t, err)
+		require.True(t, tc.expected.Equal(timestamp), "%s: %s", tc.value, timestamp)
+	}
+
+	_, err = newTimestampColumn(&DBQueries{TimestampColumn: "created_at"}).parse("yesterday")
+	require.Error(t, err)
+	_, err = newTimestampColumn(&DBQueries{TimestampColumn: "created_at", TimestampFormat: "unix"}).parse("2022-08-01")
+	require.Error(t, err)
+}
+
+func TestConsumeWTimestampColumn(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	receiver := &mySQLReceiver{config: &Config{RecordPerRow: true}, consumer: sink, logger: zap.NewNop()}
+	dbquery := DBQueries{QueryId: "Q1", TimestampColumn: "created_at"}
+	records := map[string]string{
+		"Q1_record1": `{"id":"1","created_at":"2022-08-01 10:00:00"}`,
+		"Q1_record2": `{"id":"2","created_at":"NULL"}`,
+		"Q1_record3": `{"id":"3","created_at":"not a time"}`,
+		"Q1_record4": `{"id":"4"}`,
+	}
+
+	ch := make(chan queryRecord, 1)
+	receiver.produceRecords(ch, &dbquery, records, nil, context.Background())
+	close(ch)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	receiver.consume(ch, 0, wg, context.Background())
+
+	lrs := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, lrs.Len())
+	require.Equal(t, time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC), lrs.At(0).Timestamp().AsTime())
+	// the records with NULL, invalid or missing values have no timestamp
+	for i := 1; i < lrs.Len(); i++ {
+		require.Zero(t, lrs.At(i).Timestamp())
+		require.NotZero(t, lrs.At(i).ObservedTimestamp())
+	}
+}
+
+func TestValidateTimestampColumn(t *testing.T) {
+	require.NoError(t, validateTimestampColumn(DBQueries{}))
+	require.NoError(t, validateTimestampColumn(DBQueries{TimestampColumn: "created_at", TimestampFormat: "2006-01-02", TimestampTimezone: "Europe/Warsaw"}))
+	require.NoError(t, validateTimestampColumn(DBQueries{TimestampColumn: "created_at", TimestampFormat: "unix_ms"}))
+	require.EqualError(t, validateTimestampColumn(DBQueries{TimestampFormat: "unix"}), "timestamp_format and timestamp_timezone can only be used with timestamp_column")
+	require.EqualError(t, validateTimestampColumn(DBQueries{TimestampColumn: "created_at", MetricName: "orders.count", ValueColumn: "Total"}), "timestamp_column cannot be used with metric_name")
+	require.EqualError(t, validateTimestampColumn(DBQueries{TimestampColumn: "created_at", TimestampFormat: "unix", TimestampTimezone: "Europe/Warsaw"}), "timestamp_timezone cannot be used with the Unix epoch timestamp_format")
+	require.Error(t, validateTimestampColumn(DBQueries{TimestampColumn: "created_at", TimestampTimezone: "Mars/Olympus"}))
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+//tlsConfigPrefix prefixes the names the TLS configs of the receivers are registered under with the driver
+const tlsConfigPrefix = "tls_"
+
+//TLSConfig is the TLS configuration of the connections to the database, the client certificate and key are used for mutual TLS
+type TLSConfig struct {
+	configtls.TLSSetting `mapstructure:",squash"`
+	//InsecureSkipVerify disables the verification of the certificate chain and the host name of the server
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify,omitempty"`
+	//ServerName is the host name the certificate of the server is verified against, the host of dbhost is used if it's not set
+	ServerName string `mapstructure:"server_name,omitempty"`
+}
+
+//This function validates the tls settings, the certificate files are loaded so the invalid ones are rejected on startup
+func validateTLS(cfg *Config) error {
+	if cfg.TLS == nil {
+		return nil
+	}
+	if cfg.AuthenticationMode == "SocketAuth" || cfg.AuthenticationMode == "GCPCloudSQLIAM" {
+		return fmt.Errorf("tls cannot be used with authentication_mode : '%s'", cfg.AuthenticationMode)
+	}
+	if (len(cfg.TLS.CertFile) == 0) != (len(cfg.TLS.KeyFile) == 0) {
+		return errors.New("tls cert_file and key_file should be set together")
+	}
+	if _, err := cfg.TLS.loadTLSConfig(); err != nil {
+		return fmt.Errorf("invalid tls settings: %w", err)
+	}
+	return nil
+}
+
+//This function loads the CA and the client certificate of the TLS configuration
+func (t *TLSConfig) loadTLSConfig() (*tls.Config, error) {
+	return configtls.TLSClientSetting{
+		TLSSetting:         t.TLSSetting,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}.LoadTLSConfig()
+}
+
+//This function registers the TLS configuration of the receiver with the driver
+//It returns the name the configuration is registered under, which is used as the tls parameter of the connection string
+func registerTLSConfig(conf *Config) (string, error) {
+	tlsConf, err := conf.TLS.loadTLSConfig()
+	if err != nil {
+		return "", err
+	}
+	//The name is a part of the connection string, so it cannot contain the '/' of the receiver id
+	name := tlsConfigPrefix + strings.ReplaceAll(conf.ID().String(), "/", "_")
+	if err := mysql.RegisterTLSConfig(name, tlsConf); err != nil {
+		return "", err
+	}
+	return name, nil
+}
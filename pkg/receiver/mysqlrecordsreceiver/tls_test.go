@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+//This function writes a self-signed certificate and its key, which are used both as the CA and as the client certificate
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "collector"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	return certFile, keyFile
+}
+
+func newTestTLSConfig(t *testing.T) *Config {
+	certFile, keyFile := writeTestCertificate(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.AuthenticationMode = "BasicAuth"
+	cfg.Username = "mysqluser"
+	cfg.Password = "userpass"
+	cfg.DBHost = "localhost"
+	cfg.DBPort = "3306"
+	cfg.Transport = "tcp"
+	cfg.Database = "app"
+	cfg.TLS = &TLSConfig{
+		TLSSetting: configtls.TLSSetting{CAFile: certFile, CertFile: certFile, KeyFile: keyFile},
+		ServerName: "mysql.internal",
+	}
+	return cfg
+}
+
+func TestValidateTLS(t *testing.T) {
+	cfg := newTestTLSConfig(t)
+	require.NoError(t, cfg.Validate())
+
+	keyFile := cfg.TLS.KeyFile
+	cfg.TLS.KeyFile = ""
+	require.Error(t, cfg.Validate())
+
+	cfg.TLS.KeyFile = keyFile
+	cfg.TLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	require.Error(t, cfg.Validate())
+
+	cfg = newTestTLSConfig(t)
+	cfg.AuthenticationMode = "SocketAuth"
+	cfg.Password = ""
+	require.Error(t, cfg.Validate())
+
+	cfg = newTestTLSConfig(t)
+	cfg.DBDriver = dbDriverPostgres
+	require.Error(t, cfg.Validate())
+}
+
+func TestTLSConnectionString(t *testing.T) {
+	cfg := newTestTLSConfig(t)
+	connStr, err := mySQLDriver{}.connectionString(cfg, nil, zap.NewNop())
+	require.NoError(t, err)
+	require.Contains(t, connStr, "mysqluser:userpass@tcp(localhost:3306)/app")
+	require.Contains(t, connStr, "tls="+tlsConfigPrefix+"mysqlrecords")
+
+	tlsConf, err := cfg.TLS.loadTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, "mysql.internal", tlsConf.ServerName)
+	require.NotNil(t, tlsConf.GetClientCertificate)
+	require.NotNil(t, tlsConf.RootCAs)
+}
+
+func TestTLSConnectionStringWInvalidCertificate(t *testing.T) {
+	// the connections are not established without TLS when the TLS settings cannot be loaded
+	cfg := newTestTLSConfig(t)
+	require.NoError(t, os.Remove(cfg.TLS.CAFile))
+	_, err := mySQLDriver{}.connectionString(cfg, nil, zap.NewNop())
+	require.Error(t, err)
+
+	_, err = newClient(cfg, nil, zap.NewNop())
+	require.Error(t, err)
+}
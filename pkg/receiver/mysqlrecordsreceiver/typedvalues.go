@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//jsonNumber matches the numbers which are valid in JSON, the values of numeric columns are only set as JSON numbers if they match
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+//timestampColumnLayouts are the layouts of the values of date and time columns returned by the drivers, the values without a time zone are in UTC
+var timestampColumnLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func isIntegerColumn(columnType string) bool {
+	return strings.HasSuffix(columnType, "INT") || columnType == "INTEGER" || columnType == "INT2" || columnType == "INT4" || columnType == "INT8"
+}
+
+func isFloatColumn(columnType string) bool {
+	return strings.Contains(columnType, "FLOAT") || strings.Contains(columnType, "DOUBLE") || strings.Contains(columnType, "DECIMAL") ||
+		columnType == "NUMERIC" || columnType == "NUMBER" || columnType == "REAL" || columnType == "MONEY"
+}
+
+func isBoolColumn(columnType string) bool {
+	return columnType == "BOOL" || columnType == "BOOLEAN"
+}
+
+func isTimestampColumn(columnType string) bool {
+	return columnType == "DATE" || strings.HasPrefix(columnType, "DATETIME") || strings.HasPrefix(columnType, "TIMESTAMP") || columnType == "SMALLDATETIME"
+}
+
+//This function returns the value of a column in a record with typed values, converted by the database type of the column
+//Numeric columns are JSON numbers, boolean columns are JSON booleans and date and time columns are RFC 3339 strings in UTC,
+//values which cannot be converted and columns of other or unknown types are strings
+//The date and time values without a time zone are in loc, or in UTC if it's nil
+func jsonTypedValue(value string, columnType string, loc *time.Location) interface{} {
+	if loc == nil {
+		loc = time.UTC
+	}
+	columnType = strings.ToUpper(columnType)
+	switch {
+	case isIntegerColumn(columnType) || isFloatColumn(columnType):
+		if jsonNumber.MatchString(value) {
+			return json.Number(value)
+		}
+	case isBoolColumn(columnType):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case isTimestampColumn(columnType):
+		for _, layout := range timestampColumnLayouts {
+			if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+				return t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return value
+}
+
+//This function reads the column values of a record, the values of a record with typed values are converted back into their string representation
+//NULL values set as JSON null are nil
+func readRecord(record string) (map[string]*string, error) {
+	decoder := json.NewDecoder(strings.NewReader(record))
+	decoder.UseNumber()
+	var columns map[string]interface{}
+	if err := decoder.Decode(&columns); err != nil {
+		return nil, err
+	}
+	values := make(map[string]*string, len(columns))
+	for name, column := range columns {
+		var value string
+		switch v := column.(type) {
+		case nil:
+			values[name] = nil
+			continue
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			encoded, _ := json.Marshal(v)
+			value = string(encoded)
+		}
+		values[name] = &value
+	}
+	return values, nil
+}
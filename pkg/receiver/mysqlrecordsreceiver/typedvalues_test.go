@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mysqlrecordsreceiver
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTypedValue(t *testing.T) {
+	require.Equal(t, json.Number("42"), jsonTypedValue("42", "BIGINT", nil))
+	require.Equal(t, json.Number("-7"), jsonTypedValue("-7", "int4", nil))
+	require.Equal(t, json.Number("12345678901234567890.12"), jsonTypedValue("12345678901234567890.12", "DECIMAL", nil))
+	require.Equal(t, json.Number("1e+06"), jsonTypedValue("1e+06", "NUMBER", nil))
+	require.Equal(t, true, jsonTypedValue("t", "BOOL", nil))
+	require.Equal(t, "2022-08-01T10:00:00Z", jsonTypedValue("2022-08-01 10:00:00", "DATETIME", nil))
+	require.Equal(t, "2022-08-01T08:00:00.5Z", jsonTypedValue("2022-08-01 10:00:00.5+02", "TIMESTAMPTZ", nil))
+	require.Equal(t, "2022-08-01T10:00:00Z", jsonTypedValue("2022-08-01T10:00:00Z", "DATE", nil))
+	require.Equal(t, "2022-08-01T00:00:00Z", jsonTypedValue("2022-08-01", "DATE", nil))
+	// values which are not valid in JSON or cannot be parsed are strings
+	require.Equal(t, "+5", jsonTypedValue("+5", "INT", nil))
+	require.Equal(t, "NaN", jsonTypedValue("NaN", "DOUBLE", nil))
+	require.Equal(t, "yes", jsonTypedValue("yes", "BOOLEAN", nil))
+	require.Equal(t, "0000-00-00", jsonTypedValue("0000-00-00", "DATE", nil))
+	require.Equal(t, "John", jsonTypedValue("John", "VARCHAR", nil))
+
+	// the values without a time zone are in the given time zone
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	require.NoError(t, err)
+	require.Equal(t, "2022-08-01T08:00:00Z", jsonTypedValue("2022-08-01 10:00:00", "DATETIME", warsaw))
+	require.Equal(t, "2022-08-01T08:00:00.5Z", jsonTypedValue("2022-08-01 10:00:00.5+02", "TIMESTAMPTZ", warsaw))
+}
+
+func TestReadRecord(t *testing.T) {
+	columns, err := readRecord(`{"Balance":12345678901234567890.12,"Active":true,"City":null,"Name":"John","PersonID":1}`)
+	require.NoError(t, err)
+	require.Len(t, columns, 5)
+	require.Nil(t, columns["City"])
+	require.Equal(t, "12345678901234567890.12", *columns["Balance"])
+	require.Equal(t, "true", *columns["Active"])
+	require.Equal(t, "John", *columns["Name"])
+	require.Equal(t, "1", *columns["PersonID"])
+
+	_, err = readRecord("not json")
+	require.Error(t, err)
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	// registers the postgres driver
+	_ "github.com/lib/pq"
+)
+
+// rawChange is a single row returned by the logical decoding functions.
+type rawChange struct {
+	lsn  lsn
+	data []byte
+}
+
+type replicationClient interface {
+	// ensureSlot checks that the replication slot exists, creating it if it's missing and create is set.
+	ensureSlot(ctx context.Context, create bool) error
+	// peekChanges reads the changes from the replication slot without consuming them.
+	peekChanges(ctx context.Context, maxChanges int) ([]rawChange, error)
+	// advance consumes the changes up to the given position, so the server can recycle the WAL.
+	advance(ctx context.Context, position lsn) error
+	close() error
+}
+
+type sqlReplicationClient struct {
+	db           *sql.DB
+	slotName     string
+	plugin       PluginType
+	publications string
+}
+
+var _ replicationClient = (*sqlReplicationClient)(nil)
+
+func newSQLReplicationClient(cfg *Config) (replicationClient, error) {
+	db, err := sql.Open("postgres", connectionString(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return &sqlReplicationClient{
+		db:           db,
+		slotName:     cfg.SlotName,
+		plugin:       cfg.Plugin,
+		publications: strings.Join(cfg.Publications, ","),
+	}, nil
+}
+
+func connectionString(cfg *Config) string {
+	query := url.Values{}
+	if cfg.SSLMode != "" {
+		query.Set("sslmode", cfg.SSLMode)
+	}
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     cfg.Endpoint,
+		Path:     "/" + cfg.Database,
+		RawQuery: query.Encode(),
+	}
+	if _, _, err := net.SplitHostPort(cfg.Endpoint); err != nil {
+		u.Host = net.JoinHostPort(cfg.Endpoint, "5432")
+	}
+	return u.String()
+}
+
+func (c *sqlReplicationClient) ensureSlot(ctx context.Context, create bool) error {
+	var plugin string
+	err := c.db.QueryRowContext(ctx,
+		"SELECT plugin FROM pg_replication_slots WHERE slot_name = $1 AND slot_type = 'logical'",
+		c.slotName,
+	).Scan(&plugin)
+	switch {
+	case err == sql.ErrNoRows && create:
+		_, err = c.db.ExecContext(ctx, "SELECT pg_create_logical_replication_slot($1, $2)", c.slotName, string(c.plugin))
+		if err != nil {
+			return fmt.Errorf("failed to create replication slot %q: %w", c.slotName, err)
+		}
+		return nil
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("logical replication slot %q does not exist", c.slotName)
+	case err != nil:
+		return fmt.Errorf("failed to look up replication slot %q: %w", c.slotName, err)
+	case plugin != string(c.plugin):
+		return fmt.Errorf("replication slot %q uses the %s plugin, expected %s", c.slotName, plugin, c.plugin)
+	}
+	return nil
+}
+
+func (c *sqlReplicationClient) peekChanges(ctx context.Context, maxChanges int) ([]rawChange, error) {
+	var rows *sql.Rows
+	var err error
+	switch c.plugin {
+	case PluginWal2JSON:
+		rows, err = c.db.QueryContext(ctx,
+			"SELECT lsn::text, data FROM pg_logical_slot_peek_changes($1, NULL, $2, "+
+				"'format-version', '2', 'include-xids', '1', 'include-timestamp', '1', 'include-transaction', '1')",
+			c.slotName, maxChanges,
+		)
+	default:
+		rows, err = c.db.QueryContext(ctx,
+			"SELECT lsn::text, data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2, "+
+				"'proto_version', '1', 'publication_names', $3)",
+			c.slotName, maxChanges, c.publications,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes from replication slot %q: %w", c.slotName, err)
+	}
+	defer rows.Close()
+
+	var changes []rawChange
+	for rows.Next() {
+		var position string
+		var data []byte
+		if err := rows.Scan(&position, &data); err != nil {
+			return nil, err
+		}
+		l, err := parseLSN(position)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, rawChange{lsn: l, data: data})
+	}
+	return changes, rows.Err()
+}
+
+func (c *sqlReplicationClient) advance(ctx context.Context, position lsn) error {
+	_, err := c.db.ExecContext(ctx, "SELECT pg_replication_slot_advance($1, $2::pg_lsn)", c.slotName, position.String())
+	if err != nil {
+		return fmt.Errorf("failed to advance replication slot %q to %s: %w", c.slotName, position, err)
+	}
+	return nil
+}
+
+func (c *sqlReplicationClient) close() error {
+	return c.db.Close()
+}
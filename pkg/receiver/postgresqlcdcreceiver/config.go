@@ -0,0 +1,99 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+
+	// Endpoint is the host:port of the PostgreSQL server.
+	Endpoint string `mapstructure:"endpoint"`
+	// Username of the role used to read changes, it needs the REPLICATION attribute.
+	Username string `mapstructure:"username"`
+	// Password of the role used to read changes.
+	Password string `mapstructure:"password"`
+	// Database the replication slot belongs to.
+	Database string `mapstructure:"database"`
+	// SSLMode is passed to the driver as the sslmode connection parameter.
+	SSLMode string `mapstructure:"ssl_mode"`
+	// SSLRootCert is the path of the CA certificate used to verify the server.
+	SSLRootCert string `mapstructure:"ssl_root_cert"`
+
+	// SlotName is the name of the logical replication slot changes are read from.
+	SlotName string `mapstructure:"slot_name"`
+	// CreateSlot makes the receiver create the replication slot if it doesn't exist.
+	CreateSlot bool `mapstructure:"create_slot"`
+	// Plugin is the output plugin of the replication slot.
+	Plugin PluginType `mapstructure:"plugin"`
+	// Publications are the names of the publications streamed by the pgoutput plugin.
+	Publications []string `mapstructure:"publications"`
+
+	// PollInterval is the time between reads of the replication slot.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// MaxChangesPerPoll limits the number of changes read at once,
+	// whole transactions are always read so the limit can be exceeded.
+	MaxChangesPerPoll int `mapstructure:"max_changes_per_poll"`
+
+	// ReloadOrchestrator is the id of the reload orchestrator extension. When set, the checkpoint
+	// is handed off to the new instance of the receiver on config reload.
+	ReloadOrchestrator *config.ComponentID `mapstructure:"reload_orchestrator"`
+}
+
+type PluginType string
+
+const (
+	PluginPgoutput PluginType = "pgoutput"
+	PluginWal2JSON PluginType = "wal2json"
+)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.ReceiverSettings.Validate(); err != nil {
+		return err
+	}
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint cannot be empty")
+	}
+	if cfg.Database == "" {
+		return errors.New("database cannot be empty")
+	}
+	if cfg.SlotName == "" {
+		return errors.New("slot_name cannot be empty")
+	}
+	switch cfg.Plugin {
+	case PluginPgoutput:
+		if len(cfg.Publications) == 0 {
+			return errors.New("publications cannot be empty for the pgoutput plugin")
+		}
+	case PluginWal2JSON:
+	default:
+		return fmt.Errorf("unknown plugin %q, must be one of: %s, %s", cfg.Plugin, PluginPgoutput, PluginWal2JSON)
+	}
+	if cfg.PollInterval <= 0 {
+		return errors.New("poll_interval must be positive")
+	}
+	if cfg.MaxChangesPerPoll <= 0 {
+		return errors.New("max_changes_per_poll must be positive")
+	}
+	return nil
+}
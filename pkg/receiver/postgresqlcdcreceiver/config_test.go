@@ -0,0 +1,90 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Len(t, cfg.Receivers, 2)
+
+	defaultSettings := factory.CreateDefaultConfig().(*Config)
+	defaultSettings.Database = "bank"
+	defaultSettings.SlotName = "collector"
+	defaultSettings.Publications = []string{"accounts"}
+	assert.Equal(t, defaultSettings, cfg.Receivers[config.NewComponentID(typeStr)])
+
+	assert.Equal(t, &Config{
+		ReceiverSettings:  config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "all_settings")),
+		Endpoint:          "db.example.com:5433",
+		Username:          "replicator",
+		Password:          "secret",
+		Database:          "bank",
+		SSLMode:           "verify-full",
+		SSLRootCert:       "/etc/ssl/certs/db.pem",
+		SlotName:          "audit",
+		CreateSlot:        true,
+		Plugin:            PluginWal2JSON,
+		PollInterval:      time.Minute,
+		MaxChangesPerPoll: 100,
+	}, cfg.Receivers[config.NewComponentIDWithName(typeStr, "all_settings")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	testcases := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no endpoint", modify: func(cfg *Config) { cfg.Endpoint = "" }},
+		{name: "no database", modify: func(cfg *Config) { cfg.Database = "" }},
+		{name: "no slot", modify: func(cfg *Config) { cfg.SlotName = "" }},
+		{name: "no publications", modify: func(cfg *Config) { cfg.Publications = nil }},
+		{name: "unknown plugin", modify: func(cfg *Config) { cfg.Plugin = "decoderbufs" }},
+		{name: "zero poll interval", modify: func(cfg *Config) { cfg.PollInterval = 0 }},
+		{name: "zero max changes", modify: func(cfg *Config) { cfg.MaxChangesPerPoll = 0 }},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Database = "bank"
+			cfg.SlotName = "collector"
+			cfg.Publications = []string{"accounts"}
+			require.NoError(t, cfg.Validate())
+
+			tc.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	operationInsert = "insert"
+	operationUpdate = "update"
+	operationDelete = "delete"
+)
+
+// change is a single row change decoded from the output of a logical decoding plugin.
+type change struct {
+	operation string
+	schema    string
+	table     string
+	// columns are the new values of the row, empty for deletes
+	columns map[string]interface{}
+	// identity are the old values of the replica identity columns, or of all the columns with REPLICA IDENTITY FULL
+	identity   map[string]interface{}
+	xid        uint32
+	commitTime time.Time
+}
+
+// decodedChange is the result of decoding a single row returned by the logical decoding functions.
+type decodedChange struct {
+	// change is nil for rows which don't describe a row change, e.g. transaction boundaries
+	change *change
+	// commit is set for the row ending a transaction
+	commit bool
+}
+
+type decoder interface {
+	decode(data []byte) (decodedChange, error)
+}
+
+func newDecoder(plugin PluginType) decoder {
+	if plugin == PluginWal2JSON {
+		return &wal2JSONDecoder{}
+	}
+	return &pgoutputDecoder{relations: map[uint32]relation{}}
+}
+
+// wal2JSONDecoder decodes the output of wal2json in format version 2, where every row holds a single change.
+type wal2JSONDecoder struct {
+	xid        uint32
+	commitTime time.Time
+}
+
+type wal2JSONColumn struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type wal2JSONMessage struct {
+	Action    string           `json:"action"`
+	XID       uint32           `json:"xid"`
+	Timestamp string           `json:"timestamp"`
+	Schema    string           `json:"schema"`
+	Table     string           `json:"table"`
+	Columns   []wal2JSONColumn `json:"columns"`
+	Identity  []wal2JSONColumn `json:"identity"`
+}
+
+// wal2JSONTimestampLayout is the layout of the transaction timestamps, e.g. "2022-08-01 10:00:00.123456+00".
+const wal2JSONTimestampLayout = "2006-01-02 15:04:05.999999-07"
+
+func (d *wal2JSONDecoder) decode(data []byte) (decodedChange, error) {
+	var message wal2JSONMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&message); err != nil {
+		return decodedChange{}, fmt.Errorf("failed to decode wal2json message: %w", err)
+	}
+
+	var operation string
+	switch message.Action {
+	case "B":
+		d.xid = message.XID
+		d.commitTime = time.Time{}
+		if message.Timestamp != "" {
+			commitTime, err := time.Parse(wal2JSONTimestampLayout, message.Timestamp)
+			if err != nil {
+				return decodedChange{}, fmt.Errorf("invalid wal2json transaction timestamp: %w", err)
+			}
+			d.commitTime = commitTime
+		}
+		return decodedChange{}, nil
+	case "C":
+		return decodedChange{commit: true}, nil
+	case "I":
+		operation = operationInsert
+	case "U":
+		operation = operationUpdate
+	case "D":
+		operation = operationDelete
+	default:
+		// truncates and messages are not row changes
+		return decodedChange{}, nil
+	}
+
+	return decodedChange{change: &change{
+		operation:  operation,
+		schema:     message.Schema,
+		table:      message.Table,
+		columns:    wal2JSONColumnValues(message.Columns),
+		identity:   wal2JSONColumnValues(message.Identity),
+		xid:        d.xid,
+		commitTime: d.commitTime,
+	}}, nil
+}
+
+func wal2JSONColumnValues(columns []wal2JSONColumn) map[string]interface{} {
+	if len(columns) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		values[column.Name] = normalizeJSONValue(column.Value)
+	}
+	return values
+}
+
+// normalizeJSONValue converts the numbers decoded by a json.Decoder using UseNumber to int64 or float64.
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeJSONValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = normalizeJSONValue(v[k])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// pgoutputDecoder decodes the messages of version 1 of the pgoutput logical replication protocol,
+// see https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+type pgoutputDecoder struct {
+	relations  map[uint32]relation
+	xid        uint32
+	commitTime time.Time
+}
+
+type relation struct {
+	schema  string
+	table   string
+	columns []relationColumn
+}
+
+type relationColumn struct {
+	name    string
+	typeOID uint32
+}
+
+// postgresEpoch is the reference point of the timestamps in the replication protocol.
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Type OIDs of the types with a native representation in log records.
+const (
+	oidBool   = 16
+	oidInt8   = 20
+	oidInt2   = 21
+	oidInt4   = 23
+	oidFloat4 = 700
+	oidFloat8 = 701
+	oidOID    = 26
+)
+
+var errMessageTooShort = errors.New("pgoutput message too short")
+
+func (d *pgoutputDecoder) decode(data []byte) (decodedChange, error) {
+	if len(data) == 0 {
+		return decodedChange{}, errMessageTooShort
+	}
+	r := &messageReader{data: data[1:]}
+
+	switch data[0] {
+	case 'B':
+		r.uint64() // final LSN of the transaction
+		commitTime := r.uint64()
+		d.xid = r.uint32()
+		d.commitTime = postgresEpoch.Add(time.Duration(int64(commitTime)) * time.Microsecond)
+		return decodedChange{}, r.err
+	case 'C':
+		return decodedChange{commit: true}, nil
+	case 'R':
+		id := r.uint32()
+		rel := relation{schema: r.string(), table: r.string()}
+		r.uint8() // replica identity setting
+		count := int(r.uint16())
+		for i := 0; i < count && r.err == nil; i++ {
+			r.uint8() // flags
+			name := r.string()
+			typeOID := r.uint32()
+			r.uint32() // type modifier
+			rel.columns = append(rel.columns, relationColumn{name: name, typeOID: typeOID})
+		}
+		if r.err != nil {
+			return decodedChange{}, r.err
+		}
+		d.relations[id] = rel
+		return decodedChange{}, nil
+	case 'I':
+		return d.decodeRowChange(r, operationInsert)
+	case 'U':
+		return d.decodeRowChange(r, operationUpdate)
+	case 'D':
+		return d.decodeRowChange(r, operationDelete)
+	default:
+		// types, origins, truncates and messages are not row changes
+		return decodedChange{}, nil
+	}
+}
+
+func (d *pgoutputDecoder) decodeRowChange(r *messageReader, operation string) (decodedChange, error) {
+	id := r.uint32()
+	if r.err != nil {
+		return decodedChange{}, r.err
+	}
+	rel, ok := d.relations[id]
+	if !ok {
+		return decodedChange{}, fmt.Errorf("pgoutput %s message for unknown relation %d", operation, id)
+	}
+
+	c := &change{
+		operation:  operation,
+		schema:     rel.schema,
+		table:      rel.table,
+		xid:        d.xid,
+		commitTime: d.commitTime,
+	}
+	for r.err == nil && len(r.data) > 0 {
+		switch kind := r.uint8(); kind {
+		case 'K', 'O':
+			c.identity = d.decodeTuple(r, rel)
+		case 'N':
+			c.columns = d.decodeTuple(r, rel)
+		default:
+			return decodedChange{}, fmt.Errorf("unexpected pgoutput tuple kind %q", kind)
+		}
+	}
+	if r.err != nil {
+		return decodedChange{}, r.err
+	}
+	return decodedChange{change: c}, nil
+}
+
+func (d *pgoutputDecoder) decodeTuple(r *messageReader, rel relation) map[string]interface{} {
+	count := int(r.uint16())
+	values := make(map[string]interface{}, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		kind := r.uint8()
+		if i >= len(rel.columns) {
+			r.err = fmt.Errorf("pgoutput tuple has more columns than relation %s.%s", rel.schema, rel.table)
+			break
+		}
+		column := rel.columns[i]
+		switch kind {
+		case 'n':
+			values[column.name] = nil
+		case 'u':
+			// unchanged TOASTed values are not sent
+		case 't':
+			values[column.name] = convertTextValue(string(r.bytes(int(r.uint32()))), column.typeOID)
+		default:
+			r.err = fmt.Errorf("unexpected pgoutput column kind %q", kind)
+		}
+	}
+	return values
+}
+
+// convertTextValue converts the text representation of a value to its native type, when it has one.
+func convertTextValue(value string, typeOID uint32) interface{} {
+	switch typeOID {
+	case oidBool:
+		return value == "t"
+	case oidInt2, oidInt4, oidInt8, oidOID:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case oidFloat4, oidFloat8:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// messageReader reads the fields of a pgoutput message, the first error is kept in err
+// and all the subsequent reads return zero values.
+type messageReader struct {
+	data []byte
+	err  error
+}
+
+func (r *messageReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = errMessageTooShort
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *messageReader) uint8() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *messageReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *messageReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *messageReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// string reads a null-terminated string.
+func (r *messageReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.data, 0)
+	if end < 0 {
+		r.err = errMessageTooShort
+		return ""
+	}
+	s := string(r.data[:end])
+	r.data = r.data[end+1:]
+	return s
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLSN(t *testing.T) {
+	l, err := parseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, lsn(0x16B374D848), l)
+	assert.Equal(t, "16/B374D848", l.String())
+
+	_, err = parseLSN("B374D848")
+	assert.Error(t, err)
+}
+
+func TestWal2JSONDecoder(t *testing.T) {
+	d := newDecoder(PluginWal2JSON)
+
+	decoded, err := d.decode([]byte(`{"action":"B","xid":529,"timestamp":"2022-08-01 10:00:00.123456+02"}`))
+	require.NoError(t, err)
+	assert.Equal(t, decodedChange{}, decoded)
+
+	decoded, err = d.decode([]byte(`{"action":"I","schema":"public","table":"accounts","columns":[
+		{"name":"id","type":"integer","value":1},
+		{"name":"balance","type":"numeric","value":10.5},
+		{"name":"owner","type":"text","value":"jane"},
+		{"name":"closed","type":"boolean","value":false},
+		{"name":"note","type":"text","value":null}
+	]}`))
+	require.NoError(t, err)
+	require.NotNil(t, decoded.change)
+	assert.Equal(t, &change{
+		operation: operationInsert,
+		schema:    "public",
+		table:     "accounts",
+		columns: map[string]interface{}{
+			"id":      int64(1),
+			"balance": 10.5,
+			"owner":   "jane",
+			"closed":  false,
+			"note":    nil,
+		},
+		xid:        529,
+		commitTime: time.Date(2022, 8, 1, 8, 0, 0, 123456000, time.UTC),
+	}, withUTCCommitTime(decoded.change))
+
+	decoded, err = d.decode([]byte(`{"action":"D","schema":"public","table":"accounts","identity":[{"name":"id","type":"integer","value":1}]}`))
+	require.NoError(t, err)
+	require.NotNil(t, decoded.change)
+	assert.Equal(t, operationDelete, decoded.change.operation)
+	assert.Nil(t, decoded.change.columns)
+	assert.Equal(t, map[string]interface{}{"id": int64(1)}, decoded.change.identity)
+
+	decoded, err = d.decode([]byte(`{"action":"C","xid":529}`))
+	require.NoError(t, err)
+	assert.Equal(t, decodedChange{commit: true}, decoded)
+
+	_, err = d.decode([]byte(`{"action":`))
+	assert.Error(t, err)
+}
+
+func withUTCCommitTime(c *change) *change {
+	c.commitTime = c.commitTime.UTC()
+	return c
+}
+
+// pgoutputMessage builds pgoutput protocol messages.
+type pgoutputMessage struct {
+	bytes.Buffer
+}
+
+func (m *pgoutputMessage) byte(b byte) *pgoutputMessage {
+	m.WriteByte(b)
+	return m
+}
+
+func (m *pgoutputMessage) uint16(v uint16) *pgoutputMessage {
+	_ = binary.Write(m, binary.BigEndian, v)
+	return m
+}
+
+func (m *pgoutputMessage) uint32(v uint32) *pgoutputMessage {
+	_ = binary.Write(m, binary.BigEndian, v)
+	return m
+}
+
+func (m *pgoutputMessage) uint64(v uint64) *pgoutputMessage {
+	_ = binary.Write(m, binary.BigEndian, v)
+	return m
+}
+
+func (m *pgoutputMessage) string(s string) *pgoutputMessage {
+	m.WriteString(s)
+	return m.byte(0)
+}
+
+func (m *pgoutputMessage) text(s string) *pgoutputMessage {
+	m.byte('t').uint32(uint32(len(s)))
+	m.WriteString(s)
+	return m
+}
+
+func relationMessage() []byte {
+	m := &pgoutputMessage{}
+	m.byte('R').uint32(16384).string("public").string("accounts").byte('d').uint16(4)
+	m.byte(1).string("id").uint32(oidInt4).uint32(0xFFFFFFFF)
+	m.byte(0).string("balance").uint32(1700).uint32(0xFFFFFFFF)
+	m.byte(0).string("closed").uint32(oidBool).uint32(0xFFFFFFFF)
+	m.byte(0).string("document").uint32(25).uint32(0xFFFFFFFF)
+	return m.Bytes()
+}
+
+func TestPgoutputDecoder(t *testing.T) {
+	d := newDecoder(PluginPgoutput)
+	commitTime := time.Date(2022, 8, 1, 10, 0, 0, 123456000, time.UTC)
+
+	begin := (&pgoutputMessage{}).byte('B').uint64(0x16B374D848).
+		uint64(uint64(commitTime.Sub(postgresEpoch).Microseconds())).uint32(529)
+	decoded, err := d.decode(begin.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, decodedChange{}, decoded)
+
+	decoded, err = d.decode(relationMessage())
+	require.NoError(t, err)
+	assert.Equal(t, decodedChange{}, decoded)
+
+	insert := (&pgoutputMessage{}).byte('I').uint32(16384).byte('N').uint16(4).
+		text("1").text("10.50").text("f").byte('n')
+	decoded, err = d.decode(insert.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, &change{
+		operation: operationInsert,
+		schema:    "public",
+		table:     "accounts",
+		columns: map[string]interface{}{
+			"id":       int64(1),
+			"balance":  "10.50",
+			"closed":   false,
+			"document": nil,
+		},
+		xid:        529,
+		commitTime: commitTime,
+	}, decoded.change)
+
+	update := (&pgoutputMessage{}).byte('U').uint32(16384).
+		byte('K').uint16(4).text("1").byte('n').byte('n').byte('n').
+		byte('N').uint16(4).text("2").text("0").text("t").byte('u')
+	decoded, err = d.decode(update.Bytes())
+	require.NoError(t, err)
+	require.NotNil(t, decoded.change)
+	assert.Equal(t, operationUpdate, decoded.change.operation)
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "balance": nil, "closed": nil, "document": nil}, decoded.change.identity)
+	// unchanged TOASTed values are left out
+	assert.Equal(t, map[string]interface{}{"id": int64(2), "balance": "0", "closed": true}, decoded.change.columns)
+
+	del := (&pgoutputMessage{}).byte('D').uint32(16384).byte('K').uint16(1).text("2")
+	decoded, err = d.decode(del.Bytes())
+	require.NoError(t, err)
+	require.NotNil(t, decoded.change)
+	assert.Equal(t, operationDelete, decoded.change.operation)
+	assert.Nil(t, decoded.change.columns)
+	assert.Equal(t, map[string]interface{}{"id": int64(2)}, decoded.change.identity)
+
+	commit := (&pgoutputMessage{}).byte('C').byte(0).uint64(0x16B374D848).uint64(0x16B374D878).uint64(0)
+	decoded, err = d.decode(commit.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, decodedChange{commit: true}, decoded)
+}
+
+func TestPgoutputDecoderErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		message []byte
+	}{
+		{name: "empty", message: nil},
+		{name: "unknown relation", message: (&pgoutputMessage{}).byte('I').uint32(1).byte('N').uint16(0).Bytes()},
+		{name: "truncated tuple", message: (&pgoutputMessage{}).byte('I').uint32(16384).byte('N').uint16(4).text("1").Bytes()},
+		{name: "truncated relation", message: relationMessage()[:20]},
+		{name: "unknown tuple kind", message: (&pgoutputMessage{}).byte('I').uint32(16384).byte('X').Bytes()},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDecoder(PluginPgoutput)
+			_, err := d.decode(relationMessage())
+			require.NoError(t, err)
+
+			_, err = d.decode(tc.message)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"fmt"
+)
+
+// lsn is a PostgreSQL log sequence number, a position in the write-ahead log.
+type lsn uint64
+
+// parseLSN parses the textual representation of an LSN, e.g. "16/B374D848".
+func parseLSN(s string) (lsn, error) {
+	var upper, lower uint32
+	if _, err := fmt.Sscanf(s, "%X/%X", &upper, &lower); err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	return lsn(uint64(upper)<<32 | uint64(lower)), nil
+}
+
+func (l lsn) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
@@ -0,0 +1,328 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const checkpointStorageKey = "lsn"
+
+type clientFactory func(cfg *Config) (replicationClient, error)
+
+type postgreSQLCDCReceiver struct {
+	cfg           *Config
+	clientFactory clientFactory
+	client        replicationClient
+	decoder       decoder
+	storage       storage.Client
+	// checkpoint is the commit LSN of the last transaction passed to the consumer,
+	// transactions committed before it are skipped when the replication slot wasn't advanced
+	checkpoint lsn
+	// lease hands the checkpoint off to the next instance of the receiver on config reload
+	lease *reloadorchestratorextension.Lease
+
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	consumer consumer.Logs
+	logger   *zap.Logger
+}
+
+func newPostgreSQLCDCReceiver(
+	params component.ReceiverCreateSettings,
+	cfg *Config,
+	consumer consumer.Logs,
+	clientFactory clientFactory,
+) *postgreSQLCDCReceiver {
+	return &postgreSQLCDCReceiver{
+		cfg:           cfg,
+		clientFactory: clientFactory,
+		decoder:       newDecoder(cfg.Plugin),
+		consumer:      consumer,
+		logger:        params.Logger,
+	}
+}
+
+// Start tells the receiver to start.
+func (r *postgreSQLCDCReceiver) Start(ctx context.Context, host component.Host) error {
+	var err error
+	r.storage, err = r.getStorage(ctx, host)
+	if err != nil {
+		return fmt.Errorf("error when getting storage: %s", err)
+	}
+
+	r.checkpoint, err = r.getCheckpoint(ctx)
+	if err != nil {
+		return fmt.Errorf("error when getting checkpoint: %s", err)
+	}
+
+	if r.cfg.ReloadOrchestrator != nil {
+		if err = r.takeOverCheckpoint(ctx, host, *r.cfg.ReloadOrchestrator); err != nil {
+			return fmt.Errorf("error when taking over checkpoint: %s", err)
+		}
+	}
+
+	r.client, err = r.clientFactory(r.cfg)
+	if err != nil {
+		return fmt.Errorf("error when creating database client: %s", err)
+	}
+	if err = r.client.ensureSlot(ctx, r.cfg.CreateSlot); err != nil {
+		return err
+	}
+
+	pollCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go r.pollLoop(pollCtx)
+	return nil
+}
+
+// Shutdown is invoked during service shutdown.
+func (r *postgreSQLCDCReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+
+	if r.lease != nil {
+		r.lease.Release([]byte(r.checkpoint.String()))
+	}
+
+	var err error
+	if r.client != nil {
+		err = r.client.close()
+	}
+	if r.storage != nil {
+		if storageErr := r.storage.Close(ctx); err == nil {
+			err = storageErr
+		}
+	}
+	return err
+}
+
+func (r *postgreSQLCDCReceiver) pollLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to process changes", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the pending changes from the replication slot and passes them to the consumer.
+// The slot is advanced only after the consumer accepted the changes, so they are read again after a failure.
+func (r *postgreSQLCDCReceiver) poll(ctx context.Context) error {
+	rawChanges, err := r.client.peekChanges(ctx, r.cfg.MaxChangesPerPoll)
+	if err != nil {
+		return err
+	}
+	if len(rawChanges) == 0 {
+		return nil
+	}
+
+	logs, lastCommit, err := r.convertToLogs(rawChanges)
+	if err != nil {
+		return err
+	}
+	if lastCommit == 0 {
+		return nil
+	}
+
+	if logs.LogRecordCount() > 0 {
+		if err := r.consumer.ConsumeLogs(ctx, logs); err != nil {
+			return fmt.Errorf("failed to consume changes: %w", err)
+		}
+	}
+
+	r.checkpoint = lastCommit
+	if err := r.saveCheckpoint(ctx); err != nil {
+		return err
+	}
+	return r.client.advance(ctx, lastCommit)
+}
+
+// convertToLogs decodes the changes of the complete transactions, skipping the ones committed before the checkpoint.
+// It returns the commit LSN of the last complete transaction, or 0 when there's none.
+func (r *postgreSQLCDCReceiver) convertToLogs(rawChanges []rawChange) (plog.Logs, lsn, error) {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().UpsertString("db.system", "postgresql")
+	rl.Resource().Attributes().UpsertString("db.name", r.cfg.Database)
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+	var pending []*change
+	var lastCommit lsn
+	observedTime := pcommon.NewTimestampFromTime(time.Now())
+	for _, rawChange := range rawChanges {
+		decoded, err := r.decoder.decode(rawChange.data)
+		if err != nil {
+			return plog.Logs{}, 0, fmt.Errorf("failed to decode change at %s: %w", rawChange.lsn, err)
+		}
+		if decoded.change != nil {
+			pending = append(pending, decoded.change)
+		}
+		if !decoded.commit {
+			continue
+		}
+
+		if rawChange.lsn > r.checkpoint {
+			for _, c := range pending {
+				appendChange(records.AppendEmpty(), c, rawChange.lsn, observedTime)
+			}
+		}
+		pending = nil
+		lastCommit = rawChange.lsn
+	}
+	return logs, lastCommit, nil
+}
+
+func appendChange(record plog.LogRecord, c *change, commitLSN lsn, observedTime pcommon.Timestamp) {
+	if !c.commitTime.IsZero() {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(c.commitTime))
+	}
+	record.SetObservedTimestamp(observedTime)
+
+	body := map[string]interface{}{
+		"operation": c.operation,
+		"schema":    c.schema,
+		"table":     c.table,
+		"xid":       int64(c.xid),
+		"lsn":       commitLSN.String(),
+	}
+	if c.columns != nil {
+		body["columns"] = c.columns
+	}
+	if c.identity != nil {
+		body["identity"] = c.identity
+	}
+	pcommon.NewValueMap().CopyTo(record.Body())
+	pcommon.NewMapFromRaw(body).CopyTo(record.Body().MapVal())
+}
+
+func (r *postgreSQLCDCReceiver) getStorage(ctx context.Context, host component.Host) (storage.Client, error) {
+	if host == nil {
+		r.logger.Debug("Storage not initialized: host is not available")
+		return nil, nil
+	}
+
+	var storageExtension storage.Extension
+	var storageExtensionId config.ComponentID
+	for extentionId, extension := range host.GetExtensions() {
+		if se, ok := extension.(storage.Extension); ok {
+			if storageExtension != nil {
+				return nil, fmt.Errorf("multiple storage extensions found: '%s', '%s'", storageExtensionId, extentionId)
+			}
+			storageExtension = se
+			storageExtensionId = extentionId
+		}
+	}
+
+	if storageExtension == nil {
+		r.logger.Debug("Storage not initialized: no storage extension found")
+		return nil, nil
+	}
+
+	storageClient, err := storageExtension.GetClient(ctx, component.KindReceiver, r.cfg.ID(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage client for extension '%s': %s", storageExtensionId, err)
+	}
+
+	r.logger.Info("Initialized storage", zap.Any("storage_extension_id", storageExtensionId))
+	return storageClient, nil
+}
+
+func (r *postgreSQLCDCReceiver) getCheckpoint(ctx context.Context) (lsn, error) {
+	if r.storage == nil {
+		r.logger.Info("Did not find checkpoint, as there is no storage.")
+		return 0, nil
+	}
+
+	checkpointBytes, err := r.storage.Get(ctx, checkpointStorageKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve checkpoint from storage: %s", err)
+	}
+
+	if checkpointBytes == nil {
+		r.logger.Info("Checkpoint not found in storage")
+		return 0, nil
+	}
+
+	checkpoint, err := parseLSN(string(checkpointBytes))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse checkpoint: %s", err)
+	}
+
+	r.logger.Info("Found checkpoint in storage", zap.Stringer("lsn", checkpoint))
+	return checkpoint, nil
+}
+
+func (r *postgreSQLCDCReceiver) saveCheckpoint(ctx context.Context) error {
+	if r.storage == nil {
+		return nil
+	}
+	if err := r.storage.Set(ctx, checkpointStorageKey, []byte(r.checkpoint.String())); err != nil {
+		return fmt.Errorf("failed to save checkpoint %s to storage: %w", r.checkpoint, err)
+	}
+	return nil
+}
+
+// takeOverCheckpoint acquires the lease of the receiver from the reload orchestrator and takes over the checkpoint
+// handed off by the previous instance of the receiver, unless the checkpoint in storage is newer.
+func (r *postgreSQLCDCReceiver) takeOverCheckpoint(ctx context.Context, host component.Host, id config.ComponentID) error {
+	orchestrator, err := reloadorchestratorextension.GetReloadOrchestrator(host, id)
+	if err != nil {
+		return err
+	}
+	r.lease, err = orchestrator.Acquire(ctx, component.KindReceiver, r.cfg.ID())
+	if err != nil {
+		return err
+	}
+
+	state := r.lease.State()
+	if state == nil {
+		return nil
+	}
+	checkpoint, err := parseLSN(string(state))
+	if err != nil {
+		return fmt.Errorf("failed to parse handed off checkpoint: %s", err)
+	}
+	if checkpoint > r.checkpoint {
+		r.logger.Info("Took over checkpoint from the previous instance", zap.Stringer("lsn", checkpoint))
+		r.checkpoint = checkpoint
+	}
+	return nil
+}
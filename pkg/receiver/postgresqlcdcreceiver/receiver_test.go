@@ -0,0 +1,328 @@
+// Copyright 2022 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresqlcdcreceiver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+type fakeReplicationClient struct {
+	mu       sync.Mutex
+	changes  []rawChange
+	advanced []lsn
+	closed   bool
+}
+
+func (c *fakeReplicationClient) ensureSlot(context.Context, bool) error {
+	return nil
+}
+
+func (c *fakeReplicationClient) peekChanges(_ context.Context, maxChanges int) ([]rawChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changes := c.changes
+	if len(changes) > maxChanges {
+		changes = changes[:maxChanges]
+	}
+	return changes, nil
+}
+
+func (c *fakeReplicationClient) advance(_ context.Context, position lsn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanced = append(c.advanced, position)
+	remaining := c.changes[:0]
+	for _, change := range c.changes {
+		if change.lsn > position {
+			remaining = append(remaining, change)
+		}
+	}
+	c.changes = remaining
+	return nil
+}
+
+func (c *fakeReplicationClient) close() error {
+	c.closed = true
+	return nil
+}
+
+type mapStorageClient struct {
+	storage.Client
+	data map[string][]byte
+}
+
+func (c *mapStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *mapStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *mapStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type mapStorageExtension struct {
+	component.Extension
+	client *mapStorageClient
+}
+
+func (e *mapStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func wal2JSONTransaction(xid string, commitLSN lsn, rows ...string) []rawChange {
+	changes := []rawChange{{lsn: commitLSN - 2, data: []byte(`{"action":"B","xid":` + xid + `}`)}}
+	for _, row := range rows {
+		changes = append(changes, rawChange{lsn: commitLSN - 1, data: []byte(row)})
+	}
+	return append(changes, rawChange{lsn: commitLSN, data: []byte(`{"action":"C"}`)})
+}
+
+func createTestReceiver(client replicationClient, sink *consumertest.LogsSink) *postgreSQLCDCReceiver {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Database = "bank"
+	cfg.SlotName = "collector"
+	cfg.Plugin = PluginWal2JSON
+	cfg.PollInterval = 10 * time.Millisecond
+
+	return newPostgreSQLCDCReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		cfg,
+		sink,
+		func(*Config) (replicationClient, error) { return client, nil },
+	)
+}
+
+func TestReceiverEmitsChanges(t *testing.T) {
+	client := &fakeReplicationClient{}
+	client.changes = append(client.changes, wal2JSONTransaction("1", 0x100,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":1}]}`,
+		`{"action":"U","schema":"public","table":"accounts","columns":[{"name":"id","value":1}],"identity":[{"name":"id","value":1}]}`,
+	)...)
+	client.changes = append(client.changes, wal2JSONTransaction("2", 0x200,
+		`{"action":"D","schema":"public","table":"accounts","identity":[{"name":"id","value":1}]}`,
+	)...)
+
+	storageClient := &mapStorageClient{data: map[string][]byte{}}
+	host := &extensionsHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID("file_storage"): &mapStorageExtension{client: storageClient},
+		},
+	}
+
+	sink := new(consumertest.LogsSink)
+	r := createTestReceiver(client, sink)
+	require.NoError(t, r.Start(context.Background(), host))
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 3
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.True(t, client.closed)
+
+	logs := sink.AllLogs()
+	require.Len(t, logs, 1)
+	resource := logs[0].ResourceLogs().At(0).Resource().Attributes().AsRaw()
+	assert.Equal(t, map[string]interface{}{"db.system": "postgresql", "db.name": "bank"}, resource)
+
+	records := logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, map[string]interface{}{
+		"operation": "insert",
+		"schema":    "public",
+		"table":     "accounts",
+		"xid":       int64(1),
+		"lsn":       "0/100",
+		"columns":   map[string]interface{}{"id": int64(1)},
+	}, records.At(0).Body().MapVal().AsRaw())
+	assert.Equal(t, map[string]interface{}{
+		"operation": "update",
+		"schema":    "public",
+		"table":     "accounts",
+		"xid":       int64(1),
+		"lsn":       "0/100",
+		"columns":   map[string]interface{}{"id": int64(1)},
+		"identity":  map[string]interface{}{"id": int64(1)},
+	}, records.At(1).Body().MapVal().AsRaw())
+	assert.Equal(t, map[string]interface{}{
+		"operation": "delete",
+		"schema":    "public",
+		"table":     "accounts",
+		"xid":       int64(2),
+		"lsn":       "0/200",
+		"identity":  map[string]interface{}{"id": int64(1)},
+	}, records.At(2).Body().MapVal().AsRaw())
+
+	assert.Equal(t, []lsn{0x200}, client.advanced)
+	assert.Equal(t, []byte("0/200"), storageClient.data[checkpointStorageKey])
+}
+
+func TestReceiverSkipsCheckpointedTransactions(t *testing.T) {
+	client := &fakeReplicationClient{}
+	client.changes = append(client.changes, wal2JSONTransaction("1", 0x100,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":1}]}`,
+	)...)
+	client.changes = append(client.changes, wal2JSONTransaction("2", 0x200,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":2}]}`,
+	)...)
+
+	// the first transaction was consumed, but the collector stopped before advancing the slot
+	storageClient := &mapStorageClient{data: map[string][]byte{checkpointStorageKey: []byte("0/100")}}
+	host := &extensionsHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			config.NewComponentID("file_storage"): &mapStorageExtension{client: storageClient},
+		},
+	}
+
+	sink := new(consumertest.LogsSink)
+	r := createTestReceiver(client, sink)
+	require.NoError(t, r.Start(context.Background(), host))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	record := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "0/200", record.Body().MapVal().AsRaw()["lsn"])
+}
+
+func TestReceiverTakesOverCheckpointOnReload(t *testing.T) {
+	first := wal2JSONTransaction("1", 0x100,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":1}]}`,
+	)
+	second := wal2JSONTransaction("2", 0x200,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":2}]}`,
+	)
+
+	orchestratorID := config.NewComponentID("reload_orchestrator")
+	newHost := func() component.Host {
+		factory := reloadorchestratorextension.NewFactory()
+		orchestrator, err := factory.CreateExtension(
+			context.Background(),
+			componenttest.NewNopExtensionCreateSettings(),
+			factory.CreateDefaultConfig(),
+		)
+		require.NoError(t, err)
+		return &extensionsHost{
+			Host:       componenttest.NewNopHost(),
+			extensions: map[config.ComponentID]component.Extension{orchestratorID: orchestrator},
+		}
+	}
+
+	client := &fakeReplicationClient{changes: append([]rawChange{}, first...)}
+	sink := new(consumertest.LogsSink)
+	r := createTestReceiver(client, sink)
+	r.cfg.SetIDName("reload")
+	r.cfg.ReloadOrchestrator = &orchestratorID
+	require.NoError(t, r.Start(context.Background(), newHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// there's no storage and the slot wasn't advanced, only the handed off checkpoint prevents the duplicate
+	client = &fakeReplicationClient{changes: append(append([]rawChange{}, first...), second...)}
+	sink = new(consumertest.LogsSink)
+	r = createTestReceiver(client, sink)
+	r.cfg.SetIDName("reload")
+	r.cfg.ReloadOrchestrator = &orchestratorID
+	require.NoError(t, r.Start(context.Background(), newHost()))
+	require.Eventually(t, func() bool {
+		return len(client.advanced) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	require.Equal(t, 1, sink.LogRecordCount())
+	record := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "0/200", record.Body().MapVal().AsRaw()["lsn"])
+}
+
+func TestReceiverPollKeepsIncompleteTransactions(t *testing.T) {
+	client := &fakeReplicationClient{}
+	client.changes = wal2JSONTransaction("1", 0x100,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":1}]}`,
+	)
+	// the commit of the transaction wasn't read yet
+	client.changes = client.changes[:len(client.changes)-1]
+
+	sink := new(consumertest.LogsSink)
+	r := createTestReceiver(client, sink)
+	r.client = client
+
+	require.NoError(t, r.poll(context.Background()))
+	assert.Zero(t, sink.LogRecordCount())
+	assert.Empty(t, client.advanced)
+}
+
+func TestReceiverPollDoesNotAdvanceOnConsumerError(t *testing.T) {
+	client := &fakeReplicationClient{}
+	client.changes = wal2JSONTransaction("1", 0x100,
+		`{"action":"I","schema":"public","table":"accounts","columns":[{"name":"id","value":1}]}`,
+	)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Plugin = PluginWal2JSON
+	r := newPostgreSQLCDCReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		cfg,
+		consumertest.NewErr(errors.New("pipeline is full")),
+		nil,
+	)
+	r.client = client
+
+	assert.Error(t, r.poll(context.Background()))
+	assert.Empty(t, client.advanced)
+	assert.Zero(t, r.checkpoint)
+}
+
+// Make sure the body of the emitted records is a map even when no columns are present.
+func TestAppendChangeWithoutValues(t *testing.T) {
+	record := plog.NewLogRecord()
+	appendChange(record, &change{operation: operationInsert, schema: "public", table: "empty"}, 0x10, 0)
+	assert.Equal(t, map[string]interface{}{
+		"operation": "insert",
+		"schema":    "public",
+		"table":     "empty",
+		"xid":       int64(0),
+		"lsn":       "0/10",
+	}, record.Body().MapVal().AsRaw())
+}
@@ -0,0 +1,156 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	eventsv1beta1 "k8s.io/api/events/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Events API group versions in the order of preference.
+// core/v1 is preferred, as it's the format the receiver's output is compatible with.
+var eventsAPIGroupVersions = []string{
+	corev1.SchemeGroupVersion.String(),
+	eventsv1.SchemeGroupVersion.String(),
+	eventsv1beta1.SchemeGroupVersion.String(),
+}
+
+// discoverEventsAPI uses the discovery API to find the first events API group version
+// which allows listing and watching events. If none can be found, core/v1 is assumed.
+func (r *rawK8sEventsReceiver) discoverEventsAPI() string {
+	for _, groupVersion := range eventsAPIGroupVersions {
+		resources, err := r.client.Discovery().ServerResourcesForGroupVersion(groupVersion)
+		if err != nil || resources == nil {
+			r.logger.Debug("Events API group version not available",
+				zap.String("group_version", groupVersion), zap.Error(err),
+			)
+			continue
+		}
+
+		for _, resource := range resources.APIResources {
+			if resource.Name == "events" && sets.NewString(resource.Verbs...).HasAll("list", "watch") {
+				r.logger.Info("Discovered events API", zap.String("group_version", groupVersion))
+				return groupVersion
+			}
+		}
+	}
+
+	groupVersion := corev1.SchemeGroupVersion.String()
+	r.logger.Warn("Failed to discover events API, falling back to the default",
+		zap.String("group_version", groupVersion),
+	)
+	return groupVersion
+}
+
+// eventsV1ToCoreEvent converts an events.k8s.io/v1 event into a core/v1 event.
+func eventsV1ToCoreEvent(event *eventsv1.Event) *corev1.Event {
+	coreEvent := &corev1.Event{
+		ObjectMeta:          event.ObjectMeta,
+		InvolvedObject:      event.Regarding,
+		Reason:              event.Reason,
+		Message:             event.Note,
+		Source:              event.DeprecatedSource,
+		FirstTimestamp:      event.DeprecatedFirstTimestamp,
+		LastTimestamp:       event.DeprecatedLastTimestamp,
+		Count:               event.DeprecatedCount,
+		Type:                event.Type,
+		EventTime:           event.EventTime,
+		Action:              event.Action,
+		Related:             event.Related,
+		ReportingController: event.ReportingController,
+		ReportingInstance:   event.ReportingInstance,
+	}
+	if event.Series != nil {
+		coreEvent.Series = &corev1.EventSeries{
+			Count:            event.Series.Count,
+			LastObservedTime: event.Series.LastObservedTime,
+		}
+	}
+	return coreEvent
+}
+
+// eventsV1beta1ToCoreEvent converts an events.k8s.io/v1beta1 event into a core/v1 event.
+func eventsV1beta1ToCoreEvent(event *eventsv1beta1.Event) *corev1.Event {
+	coreEvent := &corev1.Event{
+		ObjectMeta:          event.ObjectMeta,
+		InvolvedObject:      event.Regarding,
+		Reason:              event.Reason,
+		Message:             event.Note,
+		Source:              event.DeprecatedSource,
+		FirstTimestamp:      event.DeprecatedFirstTimestamp,
+		LastTimestamp:       event.DeprecatedLastTimestamp,
+		Count:               event.DeprecatedCount,
+		Type:                event.Type,
+		EventTime:           event.EventTime,
+		Action:              event.Action,
+		Related:             event.Related,
+		ReportingController: event.ReportingController,
+		ReportingInstance:   event.ReportingInstance,
+	}
+	if event.Series != nil {
+		coreEvent.Series = &corev1.EventSeries{
+			Count:            event.Series.Count,
+			LastObservedTime: event.Series.LastObservedTime,
+		}
+	}
+	return coreEvent
+}
+
+// fillMissingEventFields returns a copy of the event with the fields which weren't set by the event's
+// reporter filled in from their counterparts. Events created using the events.k8s.io API only set
+// the new fields (eventTime, series, reportingController), while events created by older components
+// only set the deprecated ones (firstTimestamp, lastTimestamp, count, source).
+func fillMissingEventFields(event *corev1.Event) *corev1.Event {
+	event = event.DeepCopy()
+
+	if event.Series != nil {
+		if event.Count == 0 {
+			event.Count = event.Series.Count
+		}
+		if event.LastTimestamp.IsZero() && !event.Series.LastObservedTime.IsZero() {
+			event.LastTimestamp = metav1.NewTime(event.Series.LastObservedTime.Time)
+		}
+	}
+
+	if event.FirstTimestamp.IsZero() {
+		if !event.EventTime.IsZero() {
+			event.FirstTimestamp = metav1.NewTime(event.EventTime.Time)
+		} else {
+			event.FirstTimestamp = event.CreationTimestamp
+		}
+	}
+	if event.LastTimestamp.IsZero() {
+		event.LastTimestamp = event.FirstTimestamp
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
+
+	if event.Source.Component == "" {
+		event.Source.Component = event.ReportingController
+	}
+	if event.ReportingController == "" {
+		event.ReportingController = event.Source.Component
+	}
+	if event.ReportingInstance == "" {
+		event.ReportingInstance = event.Source.Host
+	}
+
+	return event
+}
@@ -0,0 +1,254 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	eventsv1beta1 "k8s.io/api/events/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	cachetest "k8s.io/client-go/tools/cache/testing"
+)
+
+func eventsResourceList(groupVersion string, verbs ...string) *v1.APIResourceList {
+	return &v1.APIResourceList{
+		GroupVersion: groupVersion,
+		APIResources: []v1.APIResource{
+			{Name: "events", Namespaced: true, Kind: "Event", Verbs: verbs},
+		},
+	}
+}
+
+func TestDiscoverEventsAPI(t *testing.T) {
+	testCases := []struct {
+		name      string
+		resources []*v1.APIResourceList
+		expected  string
+	}{
+		{
+			name: "core events preferred",
+			resources: []*v1.APIResourceList{
+				eventsResourceList("events.k8s.io/v1", "list", "watch"),
+				eventsResourceList("v1", "get", "list", "watch"),
+			},
+			expected: "v1",
+		},
+		{
+			name: "core events not watchable",
+			resources: []*v1.APIResourceList{
+				eventsResourceList("v1", "get", "list"),
+				eventsResourceList("events.k8s.io/v1", "list", "watch"),
+			},
+			expected: "events.k8s.io/v1",
+		},
+		{
+			name: "only v1beta1 events",
+			resources: []*v1.APIResourceList{
+				eventsResourceList("events.k8s.io/v1beta1", "list", "watch"),
+			},
+			expected: "events.k8s.io/v1beta1",
+		},
+		{
+			name:      "nothing discovered",
+			resources: nil,
+			expected:  "v1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rCfg := createDefaultConfig().(*Config)
+			rCfg.CompatibilityMode = true
+			client := fake.NewSimpleClientset()
+			client.Resources = tc.resources
+
+			r, err := newRawK8sEventsReceiver(
+				componenttest.NewNopReceiverCreateSettings(),
+				rCfg,
+				consumertest.NewNop(),
+				client,
+				fakeListWatchFactory,
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, r.discoverEventsAPI())
+		})
+	}
+}
+
+func TestProcessEventsV1EventE2E(t *testing.T) {
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.CompatibilityMode = true
+	client := fake.NewSimpleClientset()
+	client.Resources = []*v1.APIResourceList{
+		eventsResourceList("events.k8s.io/v1", "list", "watch"),
+	}
+	sink := new(consumertest.LogsSink)
+	listWatch := cachetest.NewFakeControllerSource()
+	listWatchFactory := func(
+		c cache.Getter,
+		resource string,
+		namespace string,
+		fieldSelector fields.Selector,
+	) cache.ListerWatcher {
+		return listWatch
+	}
+
+	r, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		rCfg,
+		sink,
+		client,
+		listWatchFactory,
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, r.Start(ctx, componenttest.NewNopHost()))
+	listWatch.Add(&eventsv1.Event{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-event",
+			Namespace: "default",
+		},
+		EventTime:           v1.NewMicroTime(time.Now()),
+		ReportingController: "k3s.io/controller",
+		Reason:              "Started",
+		Note:                "Started container",
+		Type:                "Normal",
+	})
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, r.Shutdown(ctx))
+
+	logRecord := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "Started container", logRecord.Body().AsString())
+
+	object, ok := logRecord.Attributes().Get("object")
+	require.True(t, ok)
+	kind, ok := object.MapVal().Get("kind")
+	require.True(t, ok)
+	assert.Equal(t, "Event", kind.AsString())
+	source, ok := object.MapVal().Get("source")
+	require.True(t, ok)
+	component, ok := source.MapVal().Get("component")
+	require.True(t, ok)
+	assert.Equal(t, "k3s.io/controller", component.AsString())
+}
+
+func TestEventsAPIConversion(t *testing.T) {
+	eventTime := v1.NewMicroTime(time.Now())
+	regarding := corev1.ObjectReference{Kind: "Pod", Name: "test-pod"}
+
+	v1Event := eventsv1.Event{
+		ObjectMeta:          v1.ObjectMeta{Name: "test-event"},
+		EventTime:           eventTime,
+		Series:              &eventsv1.EventSeries{Count: 3, LastObservedTime: eventTime},
+		ReportingController: "controller",
+		ReportingInstance:   "instance",
+		Action:              "Pulling",
+		Reason:              "Pulling",
+		Regarding:           regarding,
+		Note:                "Pulling image",
+		Type:                "Normal",
+		DeprecatedCount:     3,
+	}
+	expected := &corev1.Event{
+		ObjectMeta:          v1.ObjectMeta{Name: "test-event"},
+		InvolvedObject:      regarding,
+		Reason:              "Pulling",
+		Message:             "Pulling image",
+		Count:               3,
+		Type:                "Normal",
+		EventTime:           eventTime,
+		Series:              &corev1.EventSeries{Count: 3, LastObservedTime: eventTime},
+		Action:              "Pulling",
+		ReportingController: "controller",
+		ReportingInstance:   "instance",
+	}
+	assert.Equal(t, expected, eventsV1ToCoreEvent(&v1Event))
+
+	v1beta1Event := eventsv1beta1.Event{
+		ObjectMeta:          v1Event.ObjectMeta,
+		EventTime:           v1Event.EventTime,
+		Series:              &eventsv1beta1.EventSeries{Count: 3, LastObservedTime: eventTime},
+		ReportingController: v1Event.ReportingController,
+		ReportingInstance:   v1Event.ReportingInstance,
+		Action:              v1Event.Action,
+		Reason:              v1Event.Reason,
+		Regarding:           v1Event.Regarding,
+		Note:                v1Event.Note,
+		Type:                v1Event.Type,
+		DeprecatedCount:     v1Event.DeprecatedCount,
+	}
+	assert.Equal(t, expected, eventsV1beta1ToCoreEvent(&v1beta1Event))
+}
+
+func TestFillMissingEventFields(t *testing.T) {
+	t.Run("new fields only", func(t *testing.T) {
+		eventTime := time.Now().Add(-time.Minute)
+		lastObserved := time.Now()
+		event := &corev1.Event{
+			EventTime:           v1.NewMicroTime(eventTime),
+			Series:              &corev1.EventSeries{Count: 5, LastObservedTime: v1.NewMicroTime(lastObserved)},
+			ReportingController: "controller",
+			ReportingInstance:   "instance",
+		}
+
+		filled := fillMissingEventFields(event)
+		assert.Equal(t, eventTime, filled.FirstTimestamp.Time)
+		assert.Equal(t, lastObserved, filled.LastTimestamp.Time)
+		assert.EqualValues(t, 5, filled.Count)
+		assert.Equal(t, "controller", filled.Source.Component)
+
+		// the original event must not be modified
+		assert.True(t, event.FirstTimestamp.IsZero())
+	})
+
+	t.Run("deprecated fields only", func(t *testing.T) {
+		event := getEvent()
+		event.ReportingController = ""
+		event.ReportingInstance = ""
+
+		filled := fillMissingEventFields(event)
+		assert.Equal(t, event.FirstTimestamp, filled.FirstTimestamp)
+		assert.Equal(t, event.Count, filled.Count)
+		assert.Equal(t, "testComponent", filled.ReportingController)
+		assert.Equal(t, "testHost", filled.ReportingInstance)
+	})
+
+	t.Run("no timestamps", func(t *testing.T) {
+		creationTimestamp := v1.NewTime(time.Now())
+		event := &corev1.Event{
+			ObjectMeta: v1.ObjectMeta{CreationTimestamp: creationTimestamp},
+		}
+
+		filled := fillMissingEventFields(event)
+		assert.Equal(t, creationTimestamp, filled.FirstTimestamp)
+		assert.Equal(t, creationTimestamp, filled.LastTimestamp)
+		assert.EqualValues(t, 1, filled.Count)
+	})
+}
@@ -15,6 +15,7 @@
 package rawk8seventsreceiver
 
 import (
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -38,6 +39,26 @@ type Config struct {
 
 	// ConsumeMaxRetries is the maximum number of retries for recoverable pipeline errors
 	ConsumeMaxRetries uint64 `mapstructure:"consume_max_retries"`
+
+	// CompatibilityMode makes the receiver discover which events API is served by the cluster
+	// and fill in event fields which are missing on some distributions, e.g. OpenShift or k3s
+	CompatibilityMode bool `mapstructure:"compatibility_mode"`
+
+	// Schema is the format of the log records, either "sumo", compatible with Sumo Logic's FluentD plugin,
+	// or "otel", following the OpenTelemetry semantic conventions of the upstream k8sevents receiver.
+	Schema string `mapstructure:"schema"`
+
+	// SchemaVersion is the version of the log records format, each version has a stable set of attributes.
+	// Version 0 is the format from before the schema was versioned. It only applies to the "sumo" schema.
+	SchemaVersion int `mapstructure:"schema_version"`
+
+	// PodEventsBus is the id of the pod events bus extension, events about pods are published to it
+	// so that e.g. the k8s processor can update its pod metadata cache right after pods change
+	PodEventsBus *config.ComponentID `mapstructure:"pod_events_bus"`
+
+	// ReloadOrchestrator is the id of the reload orchestrator extension, the latest resource version
+	// is handed off to it on config reload, so the new instance of the receiver doesn't emit the same events again
+	ReloadOrchestrator *config.ComponentID `mapstructure:"reload_orchestrator"`
 }
 
 // Validate checks if the receiver configuration is valid
@@ -45,5 +66,11 @@ func (cfg *Config) Validate() error {
 	if err := cfg.ReceiverSettings.Validate(); err != nil {
 		return err
 	}
+	if cfg.Schema != schemaSumo && cfg.Schema != schemaOTel {
+		return fmt.Errorf("schema must be either %q or %q", schemaSumo, schemaOTel)
+	}
+	if cfg.SchemaVersion < schemaVersionUnversioned || cfg.SchemaVersion > latestSchemaVersion {
+		return fmt.Errorf("schema_version must be between %d and %d", schemaVersionUnversioned, latestSchemaVersion)
+	}
 	return cfg.APIConfig.Validate()
 }
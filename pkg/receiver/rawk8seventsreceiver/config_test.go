@@ -40,4 +40,30 @@ func TestLoadConfig(t *testing.T) {
 	assert.Len(t, cfg.Receivers, 2)
 
 	assert.Equal(t, cfg.Receivers[config.NewComponentID(typeStr)], factory.CreateDefaultConfig())
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "all_settings")].(*Config)
+	assert.Equal(t, schemaSumo, allSettings.Schema)
+	assert.Equal(t, schemaVersionUnversioned, allSettings.SchemaVersion)
+}
+
+func TestValidateSchemaVersion(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	for _, version := range []int{schemaVersionUnversioned, schemaVersion1} {
+		cfg.SchemaVersion = version
+		assert.NoError(t, cfg.Validate())
+	}
+	for _, version := range []int{-1, latestSchemaVersion + 1} {
+		cfg.SchemaVersion = version
+		assert.Error(t, cfg.Validate())
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	for _, schema := range []string{schemaSumo, schemaOTel} {
+		cfg.Schema = schema
+		assert.NoError(t, cfg.Validate())
+	}
+	cfg.Schema = "fluentd"
+	assert.Error(t, cfg.Validate())
 }
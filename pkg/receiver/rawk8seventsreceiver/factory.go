@@ -49,6 +49,8 @@ func createDefaultConfig() config.Receiver {
 		MaxEventAge:       time.Minute,
 		ConsumeMaxRetries: 20,
 		ConsumeRetryDelay: time.Millisecond * 500,
+		Schema:            schemaSumo,
+		SchemaVersion:     latestSchemaVersion,
 	}
 }
 
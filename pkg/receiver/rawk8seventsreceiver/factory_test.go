@@ -42,6 +42,8 @@ func TestDefaultConfig(t *testing.T) {
 		MaxEventAge:       time.Minute,
 		ConsumeMaxRetries: 20,
 		ConsumeRetryDelay: time.Millisecond * 500,
+		SchemaVersion:     latestSchemaVersion,
+		Schema:            schemaSumo,
 	}, rCfg)
 }
 
@@ -3,10 +3,13 @@ module github.com/SumoLogic/sumologic-otel-collector/pkg/receiver/rawk8seventsre
 go 1.18
 
 require (
+	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension v0.0.54-beta.0
+	github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension v0.0.54-beta.0
 	github.com/cenkalti/backoff/v4 v4.1.3
 	github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage v0.54.0
 	github.com/openshift/client-go v0.0.0-20210521082421-73d9475a9142
 	github.com/stretchr/testify v1.7.4
+	go.opencensus.io v0.23.0
 	go.opentelemetry.io/collector v0.54.0
 	go.opentelemetry.io/collector/pdata v0.54.0
 	go.uber.org/zap v1.21.0
@@ -21,6 +24,7 @@ require (
 	github.com/evanphx/json-patch v4.12.0+incompatible // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/go-cmp v0.5.7 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
@@ -64,3 +68,7 @@ require (
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.1 // indirect
 	sigs.k8s.io/yaml v1.2.0 // indirect
 )
+
+replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension => ./../../extension/podeventsbusextension
+
+replace github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension => ./../../extension/reloadorchestratorextension
@@ -0,0 +1,63 @@
+// Copyright  OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func init() {
+	err := view.Register(viewWatchLag)
+	if err != nil {
+		fmt.Printf("Error registering raw k8s events receiver's views: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var (
+	tagReceiverKey, _ = tag.NewKey("receiver")
+
+	mWatchLag = stats.Int64("raw_k8s_events_watch_lag", "Time between the last occurrence of an event and its receipt by the receiver", stats.UnitMilliseconds)
+)
+
+var viewWatchLag = &view.View{
+	Name:        mWatchLag.Name(),
+	Description: mWatchLag.Description(),
+	Measure:     mWatchLag,
+	TagKeys:     []tag.Key{tagReceiverKey},
+	// from 100ms to 10m
+	Aggregation: view.Distribution(100, 250, 500, 1_000, 2_500, 5_000, 10_000, 30_000, 60_000, 120_000, 300_000, 600_000),
+}
+
+// recordWatchLag records the time between the event timestamp and the time the event was received.
+func recordWatchLag(receiver string, eventTime time.Time, receivedTime time.Time) {
+	lag := receivedTime.Sub(eventTime)
+	if lag < 0 {
+		// the clocks of the API server and the collector are not in sync
+		lag = 0
+	}
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagReceiverKey, receiver)},
+		mWatchLag.M(lag.Milliseconds()),
+	)
+}
@@ -0,0 +1,48 @@
+// Copyright  OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+)
+
+func watchLagData(t *testing.T, receiver string) *view.DistributionData {
+	rows, err := view.RetrieveData(viewWatchLag.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		if len(row.Tags) == 1 && row.Tags[0].Value == receiver {
+			return row.Data.(*view.DistributionData)
+		}
+	}
+	return nil
+}
+
+func TestRecordWatchLag(t *testing.T) {
+	received := time.Now()
+	recordWatchLag("raw_k8s_events/lag", received.Add(-3*time.Second), received)
+	// events from the future are recorded with zero lag
+	recordWatchLag("raw_k8s_events/lag", received.Add(time.Second), received)
+
+	data := watchLagData(t, "raw_k8s_events/lag")
+	require.NotNil(t, data)
+	assert.EqualValues(t, 2, data.Count)
+	assert.EqualValues(t, 0, data.Min)
+	assert.EqualValues(t, 3000, data.Max)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// The schema determines the format of the log records.
+const (
+	// schemaSumo is the format compatible with Sumo Logic's FluentD plugin,
+	// with the event under the "object" attribute.
+	schemaSumo = "sumo"
+	// schemaOTel is the format of the upstream k8sevents receiver, following the OpenTelemetry
+	// semantic conventions, so the events can be sent to other backends from the same collector.
+	schemaOTel = "otel"
+)
+
+// Attributes of the upstream k8sevents receiver format.
+const (
+	otelNodeNameAttribute              = "k8s.node.name"
+	otelNamespaceNameAttribute         = "k8s.namespace.name"
+	otelObjectKindAttribute            = "k8s.object.kind"
+	otelObjectNameAttribute            = "k8s.object.name"
+	otelObjectUIDAttribute             = "k8s.object.uid"
+	otelObjectFieldPathAttribute       = "k8s.object.fieldpath"
+	otelObjectAPIVersionAttribute      = "k8s.object.api_version"
+	otelObjectResourceVersionAttribute = "k8s.object.resource_version"
+	otelEventReasonAttribute           = "k8s.event.reason"
+	otelEventActionAttribute           = "k8s.event.action"
+	otelEventStartTimeAttribute        = "k8s.event.start_time"
+	otelEventNameAttribute             = "k8s.event.name"
+	otelEventUIDAttribute              = "k8s.event.uid"
+	otelEventCountAttribute            = "k8s.event.count"
+)
+
+// convertToOTelLog converts an eventChange record to an opentelemetry Logs record in the format
+// of the upstream k8sevents receiver: the message is the body, the involved object is described
+// by the resource attributes and the event by the log record attributes.
+func (r *rawK8sEventsReceiver) convertToOTelLog(eventChange *eventChange) plog.Logs {
+	event := eventChange.event
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	sl := rl.ScopeLogs().AppendEmpty()
+	lr := sl.LogRecords().AppendEmpty()
+
+	resourceAttrs := rl.Resource().Attributes()
+	resourceAttrs.InsertString(otelNodeNameAttribute, event.Source.Host)
+	resourceAttrs.InsertString(otelObjectKindAttribute, event.InvolvedObject.Kind)
+	resourceAttrs.InsertString(otelObjectNameAttribute, event.InvolvedObject.Name)
+	resourceAttrs.InsertString(otelObjectUIDAttribute, string(event.InvolvedObject.UID))
+	resourceAttrs.InsertString(otelObjectFieldPathAttribute, event.InvolvedObject.FieldPath)
+	resourceAttrs.InsertString(otelObjectAPIVersionAttribute, event.InvolvedObject.APIVersion)
+	resourceAttrs.InsertString(otelObjectResourceVersionAttribute, event.InvolvedObject.ResourceVersion)
+
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(getEventTimestamp(event)))
+	lr.Body().SetStringVal(event.Message)
+
+	if severityNumber, ok := severityMap[strings.ToLower(event.Type)]; ok {
+		lr.SetSeverityNumber(severityNumber)
+		lr.SetSeverityText(event.Type)
+	} else {
+		r.logger.Debug("unknown severity type", zap.String("type", event.Type))
+	}
+
+	attrs := lr.Attributes()
+	attrs.InsertString(otelEventReasonAttribute, event.Reason)
+	attrs.InsertString(otelEventActionAttribute, event.Action)
+	attrs.InsertString(otelEventStartTimeAttribute, event.CreationTimestamp.String())
+	attrs.InsertString(otelEventNameAttribute, event.Name)
+	attrs.InsertString(otelEventUIDAttribute, string(event.UID))
+	attrs.InsertString(otelNamespaceNameAttribute, event.InvolvedObject.Namespace)
+	// The count is only set by components reporting events with the core v1 API.
+	if event.Count != 0 {
+		attrs.InsertInt(otelEventCountAttribute, int64(event.Count))
+	}
+
+	return ld
+}
@@ -0,0 +1,95 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func convertWithOTelSchema(t *testing.T, event *corev1.Event) plog.Logs {
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.Schema = schemaOTel
+	r, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		rCfg,
+		new(consumertest.LogsSink),
+		fake.NewSimpleClientset(),
+		fakeListWatchFactory,
+	)
+	require.NoError(t, err)
+
+	logs, err := r.convertToLog(&eventChange{event, eventChangeTypeAdded})
+	require.NoError(t, err)
+	return logs
+}
+
+func TestConvertEventToOTelLog(t *testing.T) {
+	event := getEvent()
+	event.InvolvedObject.FieldPath = "spec.containers{app}"
+	event.InvolvedObject.ResourceVersion = "1234"
+	event.Action = "Binding"
+
+	logs := convertWithOTelSchema(t, event)
+	require.Equal(t, 1, logs.LogRecordCount())
+
+	assert.Equal(t, map[string]interface{}{
+		"k8s.node.name":               "testHost",
+		"k8s.object.kind":             "Pod",
+		"k8s.object.name":             "test-34bcd-rn54",
+		"k8s.object.uid":              "059f3edc-b5a9",
+		"k8s.object.fieldpath":        "spec.containers{app}",
+		"k8s.object.api_version":      "v1",
+		"k8s.object.resource_version": "1234",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+
+	logRecord := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "testing event message", logRecord.Body().StringVal())
+	assert.Equal(t, plog.SeverityNumberINFO, logRecord.SeverityNumber())
+	assert.Equal(t, "Normal", logRecord.SeverityText())
+	assert.Equal(t, event.FirstTimestamp.Time.UTC(), logRecord.Timestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{
+		"k8s.event.reason":     "testing_event_1",
+		"k8s.event.action":     "Binding",
+		"k8s.event.start_time": event.CreationTimestamp.String(),
+		"k8s.event.name":       "1",
+		"k8s.event.uid":        "289686f9-a5c0",
+		"k8s.namespace.name":   "test",
+		"k8s.event.count":      int64(2),
+	}, logRecord.Attributes().AsRaw())
+}
+
+func TestConvertEventToOTelLogWithoutCount(t *testing.T) {
+	event := &corev1.Event{
+		ObjectMeta: v1.ObjectMeta{Name: "minimal", Namespace: "test"},
+		Message:    "minimal event",
+		Type:       "Warning",
+	}
+
+	logRecord := convertWithOTelSchema(t, event).ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, plog.SeverityNumberWARN, logRecord.SeverityNumber())
+	_, ok := logRecord.Attributes().Get("k8s.event.count")
+	assert.False(t, ok)
+	_, ok = logRecord.Attributes().Get("object")
+	assert.False(t, ok)
+}
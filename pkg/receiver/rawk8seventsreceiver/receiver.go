@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
@@ -32,11 +33,17 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	eventsv1beta1 "k8s.io/api/events/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8s "k8s.io/client-go/kubernetes"
 	k8s_scheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/cache"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
 )
 
 // Only two types of events are created as of now.
@@ -51,6 +58,8 @@ const latestResourceVersionStorageKey string = "latestResourceVersion"
 type rawK8sEventsReceiver struct {
 	cfg                   *Config
 	client                k8s.Interface
+	namespaces            []string
+	listerWatcherFactory  ListerWatcherFactory
 	eventControllers      []cache.Controller
 	eventCh               chan *eventChange
 	ctx                   context.Context
@@ -58,6 +67,11 @@ type rawK8sEventsReceiver struct {
 	startTime             time.Time
 	storage               storage.Client
 	latestResourceVersion uint64
+	podEventsBus          podeventsbusextension.PodEventsBus
+	// lease hands the resource version of the last received event off to the next instance of the receiver
+	lease *reloadorchestratorextension.Lease
+	// receivedResourceVersion holds the resource version of the last received event as a string
+	receivedResourceVersion atomic.Value
 
 	consumer consumer.Logs
 	logger   *zap.Logger
@@ -86,7 +100,6 @@ func newRawK8sEventsReceiver(
 	client k8s.Interface,
 	listerWatcherFactory ListerWatcherFactory,
 ) (*rawK8sEventsReceiver, error) {
-	var namespaceController cache.Controller
 	var namespaces []string
 
 	// if no namespaces are specified, watch all of them
@@ -96,40 +109,78 @@ func newRawK8sEventsReceiver(
 		namespaces = cfg.Namespaces
 	}
 
-	eventCh := make(chan *eventChange)
-	eventControllers := []cache.Controller{}
+	receiver := &rawK8sEventsReceiver{
+		cfg:                  cfg,
+		client:               client,
+		namespaces:           namespaces,
+		listerWatcherFactory: listerWatcherFactory,
+		eventCh:              make(chan *eventChange),
+		consumer:             consumer,
+		logger:               params.Logger,
+		startTime:            time.Now(),
+	}
+	return receiver, nil
+}
 
-	restClient := client.CoreV1().RESTClient()
-	for _, namespace := range namespaces {
-		namespaceListWatch := listerWatcherFactory(restClient, "events", namespace, fields.Everything())
-		_, namespaceController = cache.NewInformer(namespaceListWatch, &corev1.Event{}, 0, cache.ResourceEventHandlerFuncs{
+// Create an informer for every watched namespace, using the given events API group version.
+// Events from APIs other than core/v1 are converted to core/v1 events, so the output format doesn't change.
+func (r *rawK8sEventsReceiver) createEventControllers(groupVersion string) []cache.Controller {
+	var getter cache.Getter
+	var objType runtime.Object
+	var toCoreEvent func(obj interface{}) *corev1.Event
+
+	switch groupVersion {
+	case eventsv1.SchemeGroupVersion.String():
+		getter = r.client.EventsV1().RESTClient()
+		objType = &eventsv1.Event{}
+		toCoreEvent = func(obj interface{}) *corev1.Event {
+			return eventsV1ToCoreEvent(obj.(*eventsv1.Event))
+		}
+	case eventsv1beta1.SchemeGroupVersion.String():
+		getter = r.client.EventsV1beta1().RESTClient()
+		objType = &eventsv1beta1.Event{}
+		toCoreEvent = func(obj interface{}) *corev1.Event {
+			return eventsV1beta1ToCoreEvent(obj.(*eventsv1beta1.Event))
+		}
+	default:
+		getter = r.client.CoreV1().RESTClient()
+		objType = &corev1.Event{}
+		toCoreEvent = func(obj interface{}) *corev1.Event {
+			return obj.(*corev1.Event)
+		}
+	}
+
+	eventControllers := []cache.Controller{}
+	for _, namespace := range r.namespaces {
+		namespaceListWatch := r.listerWatcherFactory(getter, "events", namespace, fields.Everything())
+		_, namespaceController := cache.NewInformer(namespaceListWatch, objType, 0, cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
-				event := obj.(*corev1.Event)
-				eventCh <- &eventChange{
+				r.eventCh <- &eventChange{
 					changeType: eventChangeTypeAdded,
-					event:      event,
+					event:      toCoreEvent(obj),
 				}
 			},
-			UpdateFunc: func(_, obj interface{}) {
-				event := obj.(*corev1.Event)
-				eventCh <- &eventChange{
+			UpdateFunc: func(oldObj, obj interface{}) {
+				if isResync(oldObj, obj) {
+					return
+				}
+				r.eventCh <- &eventChange{
 					changeType: eventChangeTypeModified,
-					event:      event,
+					event:      toCoreEvent(obj),
 				}
 			},
 		})
 		eventControllers = append(eventControllers, namespaceController)
 	}
-	receiver := &rawK8sEventsReceiver{
-		cfg:              cfg,
-		client:           client,
-		eventControllers: eventControllers,
-		eventCh:          eventCh,
-		consumer:         consumer,
-		logger:           params.Logger,
-		startTime:        time.Now(),
-	}
-	return receiver, nil
+	return eventControllers
+}
+
+// isResync tells if the update is a resync of an unchanged event, e.g. after the informer relisted
+// the events because its watch expired. These events were already sent, so they are skipped.
+func isResync(oldObj, newObj interface{}) bool {
+	oldMeta, oldOk := oldObj.(metav1.Object)
+	newMeta, newOk := newObj.(metav1.Object)
+	return oldOk && newOk && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
 }
 
 // Start tells the receiver to start.
@@ -145,6 +196,25 @@ func (r *rawK8sEventsReceiver) Start(ctx context.Context, host component.Host) e
 		return fmt.Errorf("error when getting latest resource version: %s", err)
 	}
 
+	if r.cfg.ReloadOrchestrator != nil {
+		if err = r.takeOverLatestResourceVersion(ctx, host, *r.cfg.ReloadOrchestrator); err != nil {
+			return fmt.Errorf("error when taking over latest resource version: %s", err)
+		}
+	}
+
+	if r.cfg.PodEventsBus != nil {
+		r.podEventsBus, err = podeventsbusextension.GetPodEventsBus(host, *r.cfg.PodEventsBus)
+		if err != nil {
+			return fmt.Errorf("error when getting pod events bus: %s", err)
+		}
+	}
+
+	groupVersion := corev1.SchemeGroupVersion.String()
+	if r.cfg.CompatibilityMode {
+		groupVersion = r.discoverEventsAPI()
+	}
+	r.eventControllers = r.createEventControllers(groupVersion)
+
 	r.ctx, r.cancel = context.WithCancel(ctx)
 
 	go r.processEventChangeLoop()
@@ -159,6 +229,9 @@ func (r *rawK8sEventsReceiver) Start(ctx context.Context, host component.Host) e
 // Shutdown is invoked during service shutdown.
 func (r *rawK8sEventsReceiver) Shutdown(ctx context.Context) error {
 	r.cancel()
+	if r.lease != nil {
+		r.lease.Release(r.handoffState())
+	}
 	var err error
 	if r.storage != nil {
 		err = r.storage.Close(ctx)
@@ -224,6 +297,45 @@ func (r *rawK8sEventsReceiver) getLatestResourceVersion(ctx context.Context) (ui
 	return latestResourceVersion, nil
 }
 
+// takeOverLatestResourceVersion acquires the lease of the receiver from the reload orchestrator and takes over
+// the latest resource version handed off by the previous instance of the receiver, unless the one in storage is newer.
+func (r *rawK8sEventsReceiver) takeOverLatestResourceVersion(ctx context.Context, host component.Host, id config.ComponentID) error {
+	orchestrator, err := reloadorchestratorextension.GetReloadOrchestrator(host, id)
+	if err != nil {
+		return err
+	}
+	r.lease, err = orchestrator.Acquire(ctx, component.KindReceiver, r.cfg.ID())
+	if err != nil {
+		return err
+	}
+
+	state := r.lease.State()
+	if state == nil {
+		return nil
+	}
+	latestResourceVersion, err := strconv.ParseUint(string(state), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse handed off resource version '%s' to number: %s", state, err)
+	}
+	if latestResourceVersion > r.latestResourceVersion {
+		r.logger.Info("Took over latest resource version from the previous instance", zap.Any("latest_resource_version", latestResourceVersion))
+		r.latestResourceVersion = latestResourceVersion
+	}
+	return nil
+}
+
+// handoffState returns the resource version of the last received event,
+// or the one the receiver started with if it didn't receive any events.
+func (r *rawK8sEventsReceiver) handoffState() []byte {
+	if received, ok := r.receivedResourceVersion.Load().(string); ok && received != "" {
+		return []byte(received)
+	}
+	if r.latestResourceVersion > 0 {
+		return []byte(strconv.FormatUint(r.latestResourceVersion, 10))
+	}
+	return nil
+}
+
 // Consume metrics and retry on recoverable errors
 func (r *rawK8sEventsReceiver) consumeWithRetry(ctx context.Context, logs plog.Logs) error {
 	constantBackoff := backoff.WithMaxRetries(backoff.NewConstantBackOff(r.cfg.ConsumeRetryDelay), r.cfg.ConsumeMaxRetries)
@@ -268,12 +380,17 @@ func (r *rawK8sEventsReceiver) processEventChangeLoop() {
 // this includes: checking if we should process the event, converting it into a plog.Logs
 // and sending it to the next consumer in the pipeline
 func (r *rawK8sEventsReceiver) processEventChange(ctx context.Context, eventChange *eventChange) {
+	if r.cfg.CompatibilityMode {
+		eventChange.event = fillMissingEventFields(eventChange.event)
+	}
 	r.recordEventReceived(eventChange.event)
 	if !r.isEventAccepted(eventChange.event) {
 		r.logger.Debug("skipping event, too old", zap.Any("event", eventChange.event))
 		return
 	}
 	r.logger.Debug("processing event", zap.Any("event", eventChange.event), zap.String("type", string(eventChange.changeType)))
+	recordWatchLag(r.cfg.ID().String(), getEventTimestamp(eventChange.event), time.Now())
+	r.publishPodEvent(eventChange.event)
 
 	logs, err := r.convertToLog(eventChange)
 	if err != nil {
@@ -289,6 +406,7 @@ func (r *rawK8sEventsReceiver) processEventChange(ctx context.Context, eventChan
 }
 
 func (r *rawK8sEventsReceiver) recordEventReceived(event *corev1.Event) {
+	r.receivedResourceVersion.Store(event.ResourceVersion)
 	if r.storage == nil {
 		return
 	}
@@ -299,6 +417,21 @@ func (r *rawK8sEventsReceiver) recordEventReceived(event *corev1.Event) {
 	}
 }
 
+// Publish events about pods to the pod events bus, if there is one.
+// Old events are not published, as they don't tell anything about the current state of pods.
+func (r *rawK8sEventsReceiver) publishPodEvent(event *corev1.Event) {
+	if r.podEventsBus == nil || event.InvolvedObject.Kind != "Pod" {
+		return
+	}
+
+	r.podEventsBus.Publish(podeventsbusextension.PodEvent{
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+		UID:       string(event.InvolvedObject.UID),
+		Reason:    event.Reason,
+	})
+}
+
 // Check if we should process the event.
 // If a latest resource version was retrieved from storage, compare that to the incoming event's resource version.
 // Otherwise, check event time and compare it to collector's start time.
@@ -336,41 +469,31 @@ func (r *rawK8sEventsReceiver) isEventAccepted(event *corev1.Event) bool {
 }
 
 // Convert an eventChange record to an opentelemetry Logs record in a format compatible
-// with Sumo Logic's FluentD plugin
+// with Sumo Logic's FluentD plugin, or in the OpenTelemetry format with the "otel" schema
 func (r *rawK8sEventsReceiver) convertToLog(eventChange *eventChange) (plog.Logs, error) {
+	if r.cfg.Schema == schemaOTel {
+		return r.convertToOTelLog(eventChange), nil
+	}
+
 	event := eventChange.event
 	ld := plog.NewLogs()
 	rl := ld.ResourceLogs().AppendEmpty()
 	sl := rl.ScopeLogs().AppendEmpty()
 	lr := sl.LogRecords().AppendEmpty()
 
-	// Convert the event into a map[string][interface{}]
-	// informers return objects without Kind information, add it
-	// see: https://github.com/kubernetes/client-go/issues/308
-	gvks, _, err := k8s_scheme.Scheme.ObjectKinds(event)
-	if err != nil {
-		return ld, fmt.Errorf("missing apiVersion or kind and cannot assign it; %w", err)
-	}
-
-	for _, gvk := range gvks {
-		if len(gvk.Kind) == 0 {
-			continue
-		}
-		if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-			continue
+	var object map[string]interface{}
+	if r.cfg.SchemaVersion == schemaVersionUnversioned {
+		var err error
+		object, err = unstructuredEvent(event)
+		if err != nil {
+			return ld, err
 		}
-		event.GetObjectKind().SetGroupVersionKind(gvk)
-		break
-	}
-
-	// Convert the event into a map[string]interface{}
-	eventMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event)
-	if err != nil {
-		return ld, err
+	} else {
+		object = eventObjectV1(event)
 	}
 
 	// for compatibility with the FluentD plugin's data format, we need to put the event data under the "object" key
-	pdataObjectMap := pcommon.NewMapFromRaw(map[string]interface{}{"object": eventMap})
+	pdataObjectMap := pcommon.NewMapFromRaw(map[string]interface{}{"object": object})
 
 	lr.SetTimestamp(pcommon.NewTimestampFromTime(getEventTimestamp(event)))
 
@@ -390,9 +513,36 @@ func (r *rawK8sEventsReceiver) convertToLog(eventChange *eventChange) (plog.Logs
 
 	// for compatibility with the FluentD plugin's data format, we need to put the change type under "type"
 	lr.Attributes().InsertString("type", string(eventChange.changeType))
+	if r.cfg.SchemaVersion != schemaVersionUnversioned {
+		lr.Attributes().InsertInt(schemaVersionAttribute, int64(r.cfg.SchemaVersion))
+	}
+	sortAttributes(lr.Attributes())
 	return ld, nil
 }
 
+// unstructuredEvent converts the event into a map[string]interface{}, exactly as the Kubernetes API returns it.
+func unstructuredEvent(event *corev1.Event) (map[string]interface{}, error) {
+	// informers return objects without Kind information, add it
+	// see: https://github.com/kubernetes/client-go/issues/308
+	gvks, _, err := k8s_scheme.Scheme.ObjectKinds(event)
+	if err != nil {
+		return nil, fmt.Errorf("missing apiVersion or kind and cannot assign it; %w", err)
+	}
+
+	for _, gvk := range gvks {
+		if len(gvk.Kind) == 0 {
+			continue
+		}
+		if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		event.GetObjectKind().SetGroupVersionKind(gvk)
+		break
+	}
+
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(event)
+}
+
 // Return the EventTimestamp based on the populated k8s event timestamps.
 // Priority: EventTime > LastTimestamp > FirstTimestamp.
 func getEventTimestamp(ev *corev1.Event) time.Time {
@@ -23,7 +23,9 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
@@ -38,6 +40,9 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 	cachetest "k8s.io/client-go/tools/cache/testing"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/podeventsbusextension"
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/extension/reloadorchestratorextension"
 )
 
 type countingErrorConsumer struct {
@@ -141,10 +146,83 @@ func TestProcessEvent(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, r)
 	r.ctx = context.Background()
+	var lagCount int64
+	if data := watchLagData(t, rCfg.ID().String()); data != nil {
+		lagCount = data.Count
+	}
 	eventChange := eventChange{getEvent(), eventChangeTypeAdded}
 	r.processEventChange(context.Background(), &eventChange)
 
 	assert.Equal(t, 1, sink.LogRecordCount())
+	data := watchLagData(t, rCfg.ID().String())
+	require.NotNil(t, data)
+	assert.Equal(t, lagCount+1, data.Count)
+}
+
+type extensionsHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h extensionsHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestPublishPodEvents(t *testing.T) {
+	busID := config.NewComponentID("pod_events_bus")
+	bus, err := podeventsbusextension.NewFactory().CreateExtension(
+		context.Background(),
+		componenttest.NewNopExtensionCreateSettings(),
+		podeventsbusextension.NewFactory().CreateDefaultConfig(),
+	)
+	require.NoError(t, err)
+	var published []podeventsbusextension.PodEvent
+	bus.(podeventsbusextension.PodEventsBus).Subscribe(func(e podeventsbusextension.PodEvent) {
+		published = append(published, e)
+	})
+
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.PodEventsBus = &busID
+	sink := new(consumertest.LogsSink)
+	r, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		rCfg,
+		sink,
+		fake.NewSimpleClientset(),
+		fakeListWatchFactory,
+	)
+	require.NoError(t, err)
+
+	// the bus has to be present in the host's extensions
+	require.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	host := extensionsHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{busID: bus},
+	}
+	require.NoError(t, r.Start(context.Background(), host))
+	defer func() {
+		assert.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	podEvent := getEvent()
+	r.processEventChange(context.Background(), &eventChange{podEvent, eventChangeTypeAdded})
+
+	nodeEvent := getEvent()
+	nodeEvent.InvolvedObject = corev1.ObjectReference{Kind: "Node", Name: "node-1"}
+	r.processEventChange(context.Background(), &eventChange{nodeEvent, eventChangeTypeAdded})
+
+	oldEvent := getEvent()
+	oldEvent.FirstTimestamp = v1.NewTime(time.Now().Add(-time.Hour))
+	r.processEventChange(context.Background(), &eventChange{oldEvent, eventChangeTypeAdded})
+
+	assert.Equal(t, 2, sink.LogRecordCount())
+	assert.Equal(t, []podeventsbusextension.PodEvent{{
+		Namespace: "test",
+		Name:      "test-34bcd-rn54",
+		UID:       "059f3edc-b5a9",
+		Reason:    "testing_event_1",
+	}}, published)
 }
 
 func TestConsumeRetryOnRecoverableError(t *testing.T) {
@@ -303,6 +381,17 @@ func TestGetEventTimestamp(t *testing.T) {
 	assert.Equal(t, k8sEvent.EventTime.Time, eventTimestamp)
 }
 
+func TestIsResync(t *testing.T) {
+	event := getEvent()
+	event.ResourceVersion = "1"
+	resyncedEvent := event.DeepCopy()
+	modifiedEvent := event.DeepCopy()
+	modifiedEvent.ResourceVersion = "2"
+
+	assert.True(t, isResync(event, resyncedEvent))
+	assert.False(t, isResync(event, modifiedEvent))
+}
+
 func TestNoStorage(t *testing.T) {
 	receiverConfig := createDefaultConfig().(*Config)
 	logsSink := new(consumertest.LogsSink)
@@ -452,6 +541,86 @@ func TestStorage(t *testing.T) {
 	}, 100*time.Millisecond, 10*time.Millisecond)
 }
 
+func TestReloadOrchestrator(t *testing.T) {
+	orchestratorID := config.NewComponentID("reload_orchestrator")
+	receiverConfig := createDefaultConfig().(*Config)
+	receiverConfig.ReloadOrchestrator = &orchestratorID
+	logsSink := new(consumertest.LogsSink)
+	listWatch := cachetest.NewFakeControllerSource()
+	listWatchFactory := func(
+		c cache.Getter,
+		resource string,
+		namespace string,
+		fieldSelector fields.Selector,
+	) cache.ListerWatcher {
+		return listWatch
+	}
+	newHost := func() component.Host {
+		orchestrator, err := reloadorchestratorextension.NewFactory().CreateExtension(
+			context.Background(),
+			componenttest.NewNopExtensionCreateSettings(),
+			reloadorchestratorextension.NewFactory().CreateDefaultConfig(),
+		)
+		require.NoError(t, err)
+		return extensionsHost{
+			Host:       componenttest.NewNopHost(),
+			extensions: map[config.ComponentID]component.Extension{orchestratorID: orchestrator},
+		}
+	}
+
+	receiver, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		receiverConfig,
+		logsSink,
+		fake.NewSimpleClientset(),
+		listWatchFactory,
+	)
+	require.NoError(t, err)
+
+	// Create the first k8s event.
+	firstEvent := getEvent()
+	firstEvent.UID = types.UID("ec279341-e2d8-4b2a-b17d-6e0566481001")
+	listWatch.Add(firstEvent)
+
+	// Start the receiver without storage extension, but with the reload orchestrator.
+	ctx := context.Background()
+	require.NoError(t, receiver.Start(ctx, newHost()))
+
+	require.Eventually(t, func() bool {
+		return logsSink.LogRecordCount() == 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+
+	// Reload the configuration, which shuts down the receiver and creates it again.
+	require.NoError(t, receiver.Shutdown(ctx))
+	logsSink.Reset()
+
+	// Create the second k8s event.
+	secondEvent := getEvent()
+	secondEvent.UID = types.UID("ec279341-e2d8-4b2a-b17d-6e0566481002")
+	listWatch.Add(secondEvent)
+
+	receiver, err = newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		receiverConfig,
+		logsSink,
+		fake.NewSimpleClientset(),
+		listWatchFactory,
+	)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Start(ctx, newHost()))
+	defer func() {
+		assert.NoError(t, receiver.Shutdown(ctx))
+	}()
+
+	// The receiver should only pick up the second event,
+	// as the resource version of the first one was handed off on reload.
+	require.Eventually(t, func() bool {
+		return logsSink.LogRecordCount() > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 1, logsSink.LogRecordCount())
+}
+
 func getEvent() *corev1.Event {
 	time := v1.Now()
 	return &corev1.Event{
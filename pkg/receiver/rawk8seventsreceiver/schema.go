@@ -0,0 +1,143 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The schema version determines the set of attributes of the log records.
+// Within a schema version, the attribute key set of every log record is the same,
+// apart from the keys of the labels and annotations maps of the event,
+// and the attributes are sorted by key, so downstream parsers don't break when the receiver evolves
+// and records compress well.
+const (
+	schemaVersionAttribute = "schema_version"
+
+	// schemaVersionUnversioned is the output of the receiver from before the schema was versioned.
+	// The event object is the event exactly as the Kubernetes API returns it,
+	// so its key set depends on which event fields are set.
+	schemaVersionUnversioned = 0
+	// schemaVersion1 has a fixed set of event object keys, fields which are not set have empty values.
+	schemaVersion1 = 1
+
+	latestSchemaVersion = schemaVersion1
+)
+
+// eventObjectV1 converts the event into the event object of schema version 1.
+func eventObjectV1(event *corev1.Event) map[string]interface{} {
+	series := map[string]interface{}{
+		"count":            int64(0),
+		"lastObservedTime": "",
+	}
+	if event.Series != nil {
+		series["count"] = int64(event.Series.Count)
+		series["lastObservedTime"] = formatMicroTime(event.Series.LastObservedTime)
+	}
+
+	related := objectReference(corev1.ObjectReference{})
+	if event.Related != nil {
+		related = objectReference(*event.Related)
+	}
+
+	return map[string]interface{}{
+		"apiVersion": corev1.SchemeGroupVersion.String(),
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"name":              event.Name,
+			"namespace":         event.Namespace,
+			"uid":               string(event.UID),
+			"resourceVersion":   event.ResourceVersion,
+			"creationTimestamp": formatTime(event.CreationTimestamp),
+			"labels":            stringMap(event.Labels),
+			"annotations":       stringMap(event.Annotations),
+		},
+		"involvedObject":     objectReference(event.InvolvedObject),
+		"reason":             event.Reason,
+		"message":            event.Message,
+		"type":               event.Type,
+		"action":             event.Action,
+		"source":             map[string]interface{}{"component": event.Source.Component, "host": event.Source.Host},
+		"count":              int64(event.Count),
+		"firstTimestamp":     formatTime(event.FirstTimestamp),
+		"lastTimestamp":      formatTime(event.LastTimestamp),
+		"eventTime":          formatMicroTime(event.EventTime),
+		"series":             series,
+		"related":            related,
+		"reportingComponent": event.ReportingController,
+		"reportingInstance":  event.ReportingInstance,
+	}
+}
+
+func objectReference(ref corev1.ObjectReference) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion":      ref.APIVersion,
+		"kind":            ref.Kind,
+		"name":            ref.Name,
+		"namespace":       ref.Namespace,
+		"uid":             string(ref.UID),
+		"resourceVersion": ref.ResourceVersion,
+		"fieldPath":       ref.FieldPath,
+	}
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// formatTime formats the time the same way as the Kubernetes API, an unset time is an empty string.
+func formatTime(t metav1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatMicroTime formats the time the same way as the Kubernetes API, an unset time is an empty string.
+func formatMicroTime(t metav1.MicroTime) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(metav1.RFC3339Micro)
+}
+
+// sortAttributes sorts the attributes by key, including the nested ones.
+func sortAttributes(attributes pcommon.Map) {
+	attributes.Sort()
+	attributes.Range(func(_ string, v pcommon.Value) bool {
+		sortValue(v)
+		return true
+	})
+}
+
+func sortValue(v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		sortAttributes(v.MapVal())
+	case pcommon.ValueTypeSlice:
+		slice := v.SliceVal()
+		for i := 0; i < slice.Len(); i++ {
+			sortValue(slice.At(i))
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawk8seventsreceiver
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func convertWithSchemaVersion(t *testing.T, version int, event *corev1.Event) pcommon.Map {
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.SchemaVersion = version
+	r, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		rCfg,
+		new(consumertest.LogsSink),
+		fake.NewSimpleClientset(),
+		fakeListWatchFactory,
+	)
+	require.NoError(t, err)
+
+	logs, err := r.convertToLog(&eventChange{event, eventChangeTypeAdded})
+	require.NoError(t, err)
+	return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+}
+
+// freeFormKeys are the keys of the maps whose keys are set by the users, i.e. the labels and annotations of the event.
+var freeFormKeys = map[string]bool{
+	"object.metadata.labels":      true,
+	"object.metadata.annotations": true,
+}
+
+// keys returns the keys of the map in their order, nested keys are prefixed with the parent keys.
+// The keys of the free form maps are not part of the schema, so they are not returned.
+func keys(m pcommon.Map, prefix string) []string {
+	var result []string
+	m.Range(func(k string, v pcommon.Value) bool {
+		result = append(result, prefix+k)
+		if v.Type() == pcommon.ValueTypeMap && !freeFormKeys[prefix+k] {
+			result = append(result, keys(v.MapVal(), prefix+k+".")...)
+		}
+		return true
+	})
+	return result
+}
+
+func TestSchemaVersion1StableKeys(t *testing.T) {
+	minimalEvent := &corev1.Event{
+		ObjectMeta: v1.ObjectMeta{Name: "minimal", Namespace: "test"},
+		Message:    "minimal event",
+	}
+
+	eventTime := v1.NewMicroTime(time.Date(2022, 8, 1, 10, 0, 0, 123456000, time.UTC))
+	fullEvent := getEvent()
+	fullEvent.ObjectMeta.Labels = map[string]string{"app": "test"}
+	fullEvent.ObjectMeta.ResourceVersion = "1234"
+	fullEvent.LastTimestamp = v1.Now()
+	fullEvent.EventTime = eventTime
+	fullEvent.Action = "Binding"
+	fullEvent.Series = &corev1.EventSeries{Count: 3, LastObservedTime: eventTime}
+	fullEvent.Related = &corev1.ObjectReference{Kind: "Node", Name: "node-1"}
+	fullEvent.ReportingController = "kubelet"
+
+	minimal := convertWithSchemaVersion(t, schemaVersion1, minimalEvent)
+	full := convertWithSchemaVersion(t, schemaVersion1, fullEvent)
+
+	assert.Equal(t, keys(minimal, ""), keys(full, ""))
+
+	var topLevelKeys []string
+	full.Range(func(k string, _ pcommon.Value) bool {
+		topLevelKeys = append(topLevelKeys, k)
+		return true
+	})
+	assert.Equal(t, []string{"object", "schema_version", "type"}, topLevelKeys)
+
+	version, ok := full.Get(schemaVersionAttribute)
+	require.True(t, ok)
+	assert.Equal(t, int64(schemaVersion1), version.IntVal())
+
+	object, ok := full.Get("object")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"name":              "1",
+			"namespace":         "test",
+			"uid":               "289686f9-a5c0",
+			"resourceVersion":   "1234",
+			"creationTimestamp": fullEvent.CreationTimestamp.UTC().Format(time.RFC3339),
+			"labels":            map[string]interface{}{"app": "test"},
+			"annotations":       map[string]interface{}{},
+		},
+		"involvedObject": map[string]interface{}{
+			"apiVersion":      "v1",
+			"kind":            "Pod",
+			"name":            "test-34bcd-rn54",
+			"namespace":       "test",
+			"uid":             "059f3edc-b5a9",
+			"resourceVersion": "",
+			"fieldPath":       "",
+		},
+		"reason":         "testing_event_1",
+		"message":        "testing event message",
+		"type":           "Normal",
+		"action":         "Binding",
+		"source":         map[string]interface{}{"component": "testComponent", "host": "testHost"},
+		"count":          int64(2),
+		"firstTimestamp": fullEvent.FirstTimestamp.UTC().Format(time.RFC3339),
+		"lastTimestamp":  fullEvent.LastTimestamp.UTC().Format(time.RFC3339),
+		"eventTime":      "2022-08-01T10:00:00.123456Z",
+		"series":         map[string]interface{}{"count": int64(3), "lastObservedTime": "2022-08-01T10:00:00.123456Z"},
+		"related": map[string]interface{}{
+			"apiVersion":      "",
+			"kind":            "Node",
+			"name":            "node-1",
+			"namespace":       "",
+			"uid":             "",
+			"resourceVersion": "",
+			"fieldPath":       "",
+		},
+		"reportingComponent": "kubelet",
+		"reportingInstance":  "",
+	}, object.MapVal().AsRaw())
+}
+
+func TestSchemaVersionsSortedAttributes(t *testing.T) {
+	for _, version := range []int{schemaVersionUnversioned, schemaVersion1} {
+		attributes := convertWithSchemaVersion(t, version, getEvent())
+
+		var check func(m pcommon.Map)
+		check = func(m pcommon.Map) {
+			var mapKeys []string
+			m.Range(func(k string, v pcommon.Value) bool {
+				mapKeys = append(mapKeys, k)
+				if v.Type() == pcommon.ValueTypeMap {
+					check(v.MapVal())
+				}
+				return true
+			})
+			assert.True(t, sort.StringsAreSorted(mapKeys), "schema version %d: %v", version, mapKeys)
+		}
+		check(attributes)
+	}
+}
+
+func TestSchemaVersionUnversioned(t *testing.T) {
+	event := getEvent()
+	attributes := convertWithSchemaVersion(t, schemaVersionUnversioned, event)
+
+	_, ok := attributes.Get(schemaVersionAttribute)
+	assert.False(t, ok)
+	assert.Equal(t, 2, attributes.Len())
+
+	// the event is sent exactly as the Kubernetes API returns it
+	object, ok := attributes.Get("object")
+	require.True(t, ok)
+	metadata, ok := object.MapVal().Get("metadata")
+	require.True(t, ok)
+	_, ok = metadata.MapVal().Get("managedFields")
+	assert.True(t, ok)
+	_, ok = object.MapVal().Get("series")
+	assert.False(t, ok)
+}
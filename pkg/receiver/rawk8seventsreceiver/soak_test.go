@@ -0,0 +1,510 @@
+// Copyright 2022, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build soak
+
+package rawk8seventsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	soakEvents        = flag.Int("soak.events", 50000, "number of event changes replayed in every soak scenario")
+	soakRecording     = flag.String("soak.recording", "testdata/soak/events.json", "recorded event stream, as written by kubectl get events --all-namespaces --watch --output json")
+	soakMinRate       = flag.Float64("soak.min-rate", 1000, "minimum number of events per second the receiver has to send")
+	soakMaxHeapGrowth = flag.Int("soak.max-heap-growth", 32, "maximum heap growth in MiB over the second half of a soak scenario")
+)
+
+const (
+	// soakLiveEvents is the number of events kept in the cluster, older ones are deleted like the API server deletes them after their ttl
+	soakLiveEvents = 1000
+	// soakTimeout is the maximum time for the receiver to send a burst of events, or to watch the events again after a reset
+	soakTimeout = time.Minute
+)
+
+type soakScenario struct {
+	name string
+	// burstSize is the number of changes replayed at once, the next burst is replayed once the receiver sent them all
+	burstSize int
+	// resetEvery closes the watches after every n-th burst, the informer watches the events again from the latest resource version
+	resetEvery int
+	// expireEvery expires the watches after every n-th burst, the informer lists all the events again
+	expireEvery int
+	// failEvery makes every n-th call of the next consumer fail with a recoverable error
+	failEvery int
+}
+
+// TestSoak replays a recorded event stream against the receiver until the number of event changes set by -soak.events
+// is reached, and checks that the receiver sends every change exactly once and in order, that its memory usage
+// doesn't grow with the number of events and that it keeps up with the rate set by -soak.min-rate.
+//
+// It's excluded from the regular tests, run it with: make soak
+func TestSoak(t *testing.T) {
+	recording := loadRecording(t, *soakRecording)
+
+	scenarios := []soakScenario{
+		{name: "steady", burstSize: 100},
+		{name: "bursts", burstSize: 2000},
+		{name: "watch resets", burstSize: 500, resetEvery: 5},
+		{name: "watch expirations", burstSize: 2000, expireEvery: 5},
+		{name: "recoverable consumer errors", burstSize: 500, failEvery: 7},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.name, func(t *testing.T) {
+			runSoakScenario(t, scenario, recording)
+		})
+	}
+}
+
+func runSoakScenario(t *testing.T, scenario soakScenario, recording []*corev1.Event) {
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.ConsumeRetryDelay = time.Millisecond
+
+	// deletions are in the history too, so it has to hold a couple of bursts for the watches not to expire on their own
+	server := newReplayServer(4 * scenario.burstSize)
+	sink := &soakConsumer{failEvery: scenario.failEvery}
+	listWatchFactory := func(
+		c cache.Getter,
+		resource string,
+		namespace string,
+		fieldSelector fields.Selector,
+	) cache.ListerWatcher {
+		return server
+	}
+
+	r, err := newRawK8sEventsReceiver(
+		componenttest.NewNopReceiverCreateSettings(),
+		rCfg,
+		sink,
+		fake.NewSimpleClientset(),
+		listWatchFactory,
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, r.Start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.Shutdown(ctx))
+	}()
+	require.Eventually(t, func() bool {
+		return server.openedWatches() > 0
+	}, soakTimeout, time.Millisecond, "the informer didn't watch the events")
+
+	replay := &replayer{server: server, recording: recording, liveEvents: soakLiveEvents}
+	bursts := (*soakEvents + scenario.burstSize - 1) / scenario.burstSize
+	var heapAtHalf uint64
+	start := time.Now()
+
+	for burst := 1; burst <= bursts; burst++ {
+		replay.replay(scenario.burstSize)
+		require.Eventually(t, func() bool {
+			return sink.sentCount() >= replay.replayed
+		}, soakTimeout, time.Millisecond, "the receiver didn't send burst %d", burst)
+
+		resetWatches := scenario.resetEvery > 0 && burst%scenario.resetEvery == 0
+		expireWatches := scenario.expireEvery > 0 && burst%scenario.expireEvery == 0
+		if resetWatches || expireWatches {
+			opened := server.openedWatches()
+			server.closeWatches(expireWatches)
+			// changes replayed before the informer lists the events again would only be seen in their latest version
+			require.Eventually(t, func() bool {
+				return server.openedWatches() > opened
+			}, soakTimeout, time.Millisecond, "the informer didn't watch the events again after burst %d", burst)
+		}
+
+		if burst == (bursts+1)/2 {
+			heapAtHalf = heapAlloc()
+		}
+	}
+
+	elapsed := time.Since(start)
+	heapGrowth := int64(heapAlloc()) - int64(heapAtHalf)
+	sent, outOfOrder, failures := sink.stats()
+	rate := float64(sent) / elapsed.Seconds()
+	t.Logf("sent %d events in %s (%.0f events/s), %d consumer failures, %d watches, heap growth %d KiB",
+		sent, elapsed, rate, failures, server.openedWatches(), heapGrowth>>10)
+
+	// the resource versions are sent in increasing order, so sending every change means it's sent exactly once
+	assert.Equal(t, replay.replayed, sent, "the receiver didn't send every change exactly once")
+	assert.Zero(t, outOfOrder, "the receiver sent changes out of order")
+	if scenario.failEvery > 0 {
+		assert.NotZero(t, failures, "the consumer didn't fail")
+	}
+	assert.Less(t, heapGrowth, int64(*soakMaxHeapGrowth)<<20, "the heap grew with the number of events")
+	assert.GreaterOrEqual(t, rate, *soakMinRate, "the receiver didn't keep up with the events")
+}
+
+// loadRecording reads a recorded event stream. kubectl get events --watch --output json writes the events
+// one after another as JSON objects, the same event appears again every time it's modified.
+func loadRecording(t *testing.T, path string) []*corev1.Event {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []*corev1.Event
+	decoder := json.NewDecoder(file)
+	for {
+		event := &corev1.Event{}
+		err := decoder.Decode(event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+	require.NotEmpty(t, events, "the recording has no events")
+	return events
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// replayer replays a recorded event stream in a loop. Every loop creates new events, named after the recorded ones,
+// and the oldest events are deleted once there are more than liveEvents of them.
+type replayer struct {
+	server     *replayServer
+	recording  []*corev1.Event
+	liveEvents int
+	// replayed is the number of replayed changes, every one of them should be sent by the receiver
+	replayed int
+	// live has the keys of live events, the oldest first
+	live []string
+}
+
+func (r *replayer) replay(changes int) {
+	for i := 0; i < changes; i++ {
+		loop := r.replayed / len(r.recording)
+		event := r.recording[r.replayed%len(r.recording)].DeepCopy()
+		event.Name = fmt.Sprintf("%s-%d", event.Name, loop)
+		event.UID = types.UID(fmt.Sprintf("%s-%d", event.UID, loop))
+		// the receiver skips old events, so the recorded events happen again now
+		if event.EventTime.IsZero() {
+			event.LastTimestamp = v1.Now()
+		} else {
+			event.EventTime = v1.NowMicro()
+		}
+
+		if r.server.upsert(event) {
+			r.live = append(r.live, eventKey(event))
+		}
+		r.replayed++
+
+		for len(r.live) > r.liveEvents {
+			r.server.delete(r.live[0])
+			r.live = r.live[1:]
+		}
+	}
+}
+
+func eventKey(event *corev1.Event) string {
+	return event.Namespace + "/" + event.Name
+}
+
+type replayChange struct {
+	resourceVersion uint64
+	event           watch.Event
+}
+
+// replayServer serves the replayed events to the informers like the API server does: every change gets the next
+// resource version and watches get the changes since the resource version they start from,
+// as long as these are in the history of latest changes. Otherwise the watch expires and the informer lists the events again.
+type replayServer struct {
+	mutex           sync.Mutex
+	changed         *sync.Cond
+	resourceVersion uint64
+	events          map[string]*corev1.Event
+	history         []replayChange
+	historySize     int
+	watches         map[*replayWatch]struct{}
+	watchesOpened   int
+}
+
+var _ cache.ListerWatcher = (*replayServer)(nil)
+
+func newReplayServer(historySize int) *replayServer {
+	server := &replayServer{
+		events:      map[string]*corev1.Event{},
+		historySize: historySize,
+		watches:     map[*replayWatch]struct{}{},
+	}
+	server.changed = sync.NewCond(&server.mutex)
+	return server
+}
+
+// upsert creates the event or modifies it if it exists, it returns true if the event was created.
+func (s *replayServer) upsert(event *corev1.Event) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := eventKey(event)
+	_, exists := s.events[key]
+	changeType := watch.Added
+	if exists {
+		changeType = watch.Modified
+	}
+	s.events[key] = s.record(changeType, event)
+	return !exists
+}
+
+func (s *replayServer) delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event, ok := s.events[key]
+	if !ok {
+		return
+	}
+	delete(s.events, key)
+	s.record(watch.Deleted, event.DeepCopy())
+}
+
+// record gives the event the next resource version and adds the change to the history.
+func (s *replayServer) record(changeType watch.EventType, event *corev1.Event) *corev1.Event {
+	s.resourceVersion++
+	event.ResourceVersion = strconv.FormatUint(s.resourceVersion, 10)
+	s.history = append(s.history, replayChange{
+		resourceVersion: s.resourceVersion,
+		event:           watch.Event{Type: changeType, Object: event},
+	})
+	if len(s.history) > s.historySize {
+		s.history = s.history[len(s.history)-s.historySize:]
+	}
+	s.changed.Broadcast()
+	return event
+}
+
+func (s *replayServer) List(_ v1.ListOptions) (k8sruntime.Object, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := &corev1.EventList{
+		ListMeta: v1.ListMeta{ResourceVersion: strconv.FormatUint(s.resourceVersion, 10)},
+		Items:    make([]corev1.Event, 0, len(s.events)),
+	}
+	for _, event := range s.events {
+		list.Items = append(list.Items, *event)
+	}
+	return list, nil
+}
+
+func (s *replayServer) Watch(options v1.ListOptions) (watch.Interface, error) {
+	from, err := strconv.ParseUint(options.ResourceVersion, 10, 64)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource version %q", options.ResourceVersion))
+	}
+
+	w := &replayWatch{
+		server: s,
+		result: make(chan watch.Event),
+		done:   make(chan struct{}),
+	}
+	s.mutex.Lock()
+	s.watches[w] = struct{}{}
+	s.watchesOpened++
+	s.mutex.Unlock()
+
+	go s.serve(w, from)
+	return w, nil
+}
+
+// serve sends the changes since the resource version to the watch, until it's stopped, closed or expired.
+func (s *replayServer) serve(w *replayWatch, from uint64) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.watches, w)
+		s.mutex.Unlock()
+		close(w.result)
+	}()
+
+	for {
+		s.mutex.Lock()
+		for !w.stopped && !w.closed && !w.expired && s.resourceVersion <= from {
+			s.changed.Wait()
+		}
+		if w.stopped || w.closed {
+			s.mutex.Unlock()
+			return
+		}
+		if w.expired || s.history[0].resourceVersion > from+1 {
+			s.mutex.Unlock()
+			w.send(watch.Event{Type: watch.Error, Object: &apierrors.NewResourceExpired("too old resource version").ErrStatus})
+			return
+		}
+		// every change is in the history, so the resource versions in it are consecutive
+		start := int(from + 1 - s.history[0].resourceVersion)
+		changes := append([]replayChange(nil), s.history[start:]...)
+		s.mutex.Unlock()
+
+		for _, change := range changes {
+			if !w.send(change.event) {
+				return
+			}
+			from = change.resourceVersion
+		}
+	}
+}
+
+// closeWatches closes all the open watches, expired watches make the informers list the events again.
+func (s *replayServer) closeWatches(expire bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for w := range s.watches {
+		if expire {
+			w.expired = true
+		} else {
+			w.closed = true
+		}
+	}
+	s.changed.Broadcast()
+}
+
+func (s *replayServer) openedWatches() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.watchesOpened
+}
+
+type replayWatch struct {
+	server   *replayServer
+	result   chan watch.Event
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// stopped is set when the informer stops the watch, closed and expired when the server closes it.
+	// They are guarded by the server's mutex.
+	stopped bool
+	closed  bool
+	expired bool
+}
+
+func (w *replayWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.server.mutex.Lock()
+		w.stopped = true
+		w.server.changed.Broadcast()
+		w.server.mutex.Unlock()
+	})
+}
+
+func (w *replayWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *replayWatch) send(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// soakConsumer checks the resource versions of the events sent by the receiver,
+// failing every n-th call with a recoverable error.
+type soakConsumer struct {
+	mutex      sync.Mutex
+	failEvery  int
+	calls      int
+	failures   int
+	sent       int
+	outOfOrder int
+	// latestResourceVersion is the resource version of the latest event sent
+	latestResourceVersion uint64
+}
+
+func (c *soakConsumer) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.calls++
+	if c.failEvery > 0 && c.calls%c.failEvery == 0 {
+		c.failures++
+		return errors.New("recoverable error")
+	}
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		scopeLogs := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			logRecords := scopeLogs.At(j).LogRecords()
+			for k := 0; k < logRecords.Len(); k++ {
+				resourceVersion := eventResourceVersion(logRecords.At(k))
+				if resourceVersion <= c.latestResourceVersion {
+					c.outOfOrder++
+				}
+				c.latestResourceVersion = resourceVersion
+				c.sent++
+			}
+		}
+	}
+	return nil
+}
+
+func (c *soakConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (c *soakConsumer) sentCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.sent
+}
+
+func (c *soakConsumer) stats() (sent int, outOfOrder int, failures int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.sent, c.outOfOrder, c.failures
+}
+
+func eventResourceVersion(lr plog.LogRecord) uint64 {
+	object, _ := lr.Attributes().Get("object")
+	metadata, _ := object.MapVal().Get("metadata")
+	resourceVersion, _ := metadata.MapVal().Get("resourceVersion")
+	version, _ := strconv.ParseUint(resourceVersion.StringVal(), 10, 64)
+	return version
+}